@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 
-	"apimgr/config"
 	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +36,24 @@ func parseModelsList(modelsStr string) []string {
 	return result
 }
 
+// parseBetaFeatures parses the comma-separated --beta value like a models
+// list, nil when it is empty
+func parseBetaFeatures(betas string) []string {
+	if betas == "" {
+		return nil
+	}
+	return parseModelsList(betas)
+}
+
+// changedFloat returns the value of a float flag, nil when it was not given
+func changedFloat(cmd *cobra.Command, name string) *float64 {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	value, _ := cmd.Flags().GetFloat64(name)
+	return &value
+}
+
 // APIConfigBuilder is responsible for building and validating APIConfig
 type APIConfigBuilder struct {
 	config *models.APIConfig
@@ -80,6 +102,104 @@ func (b *APIConfigBuilder) SetModels(models []string) *APIConfigBuilder {
 	return b
 }
 
+// SetProvider sets the provider
+func (b *APIConfigBuilder) SetProvider(provider string) *APIConfigBuilder {
+	b.config.Provider = provider
+	return b
+}
+
+// SetEnvironment sets the deployment environment
+func (b *APIConfigBuilder) SetEnvironment(environment string) *APIConfigBuilder {
+	b.config.Environment = environment
+	return b
+}
+
+// SetRegion sets the region of a built-in regional endpoint
+func (b *APIConfigBuilder) SetRegion(region string) *APIConfigBuilder {
+	b.config.Region = region
+	return b
+}
+
+// SetInsecureSkipVerify sets whether tests skip TLS certificate verification
+func (b *APIConfigBuilder) SetInsecureSkipVerify(insecure bool) *APIConfigBuilder {
+	b.config.InsecureSkipVerify = insecure
+	return b
+}
+
+// SetCABundle sets the CA bundle path used to verify the endpoint
+func (b *APIConfigBuilder) SetCABundle(caBundle string) *APIConfigBuilder {
+	b.config.CABundle = caBundle
+	return b
+}
+
+// SetClientCert sets the client certificate and key paths for mutual TLS
+func (b *APIConfigBuilder) SetClientCert(certPath, keyPath string) *APIConfigBuilder {
+	b.config.ClientCert = certPath
+	b.config.ClientKey = keyPath
+	return b
+}
+
+// SetChatPath sets the chat endpoint path of tests
+func (b *APIConfigBuilder) SetChatPath(path string) *APIConfigBuilder {
+	b.config.ChatPath = path
+	return b
+}
+
+// SetAPIVersion sets the anthropic-version header value
+func (b *APIConfigBuilder) SetAPIVersion(version string) *APIConfigBuilder {
+	b.config.APIVersion = version
+	return b
+}
+
+// SetBetaFeatures sets the anthropic-beta header values
+func (b *APIConfigBuilder) SetBetaFeatures(betas []string) *APIConfigBuilder {
+	b.config.BetaFeatures = betas
+	return b
+}
+
+// SetScope sets the organization and project of an org- or project-scoped key
+func (b *APIConfigBuilder) SetScope(orgID, projectID string) *APIConfigBuilder {
+	b.config.OrgID = orgID
+	b.config.ProjectID = projectID
+	return b
+}
+
+// SetGeneration sets the generation parameters of test requests, nil
+// leaving the API's default
+func (b *APIConfigBuilder) SetGeneration(temperature *float64, maxTokens int, topP *float64) *APIConfigBuilder {
+	b.config.Temperature = temperature
+	b.config.MaxTokens = maxTokens
+	b.config.TopP = topP
+	return b
+}
+
+// SetKeyPool sets the key pool and how switch picks from it. The first key
+// is used until the first switch unless an API key is set.
+func (b *APIConfigBuilder) SetKeyPool(keys []string, strategy string) *APIConfigBuilder {
+	if len(keys) == 0 {
+		return b
+	}
+	b.config.APIKeys = keys
+	b.config.KeyStrategy = strategy
+	if b.config.APIKey == "" {
+		b.config.APIKey = keys[0]
+	}
+	return b
+}
+
+// SetRequestSettings sets the request timeout and retries of Claude Code
+func (b *APIConfigBuilder) SetRequestSettings(timeout string, retries *int) *APIConfigBuilder {
+	b.config.APITimeout = timeout
+	b.config.MaxRetries = retries
+	return b
+}
+
+// SetExtends sets the alias of the config to inherit unset fields from
+func (b *APIConfigBuilder) SetExtends(base string) *APIConfigBuilder {
+	b.config.Extends = base
+	return b
+}
+
 // Build builds the config
 func (b *APIConfigBuilder) Build() (*models.APIConfig, error) {
 	if err := b.validate(); err != nil {
@@ -93,11 +213,12 @@ func (b *APIConfigBuilder) validate() error {
 	if b.config.Alias == "" {
 		return fmt.Errorf("alias cannot be empty")
 	}
-	if b.config.APIKey == "" && b.config.AuthToken == "" {
+	// A config extending another one may inherit its credentials
+	if b.config.APIKey == "" && b.config.AuthToken == "" && b.config.Extends == "" {
 		return fmt.Errorf("API key and auth token cannot both be empty")
 	}
 	if b.config.BaseURL != "" {
-		if _, err := url.ParseRequestURI(b.config.BaseURL); err != nil {
+		if _, err := url.ParseRequestURI(utils.SamplePlaceholders(b.config.BaseURL)); err != nil {
 			return fmt.Errorf("invalid URL format: %s", b.config.BaseURL)
 		}
 	}
@@ -107,9 +228,14 @@ func (b *APIConfigBuilder) validate() error {
 // InputCollector is responsible for collecting user input
 type InputCollector struct{}
 
-// isTerminal checks if running in a real terminal
-func isTerminal() bool {
-	stat, err := os.Stdin.Stat()
+// isTerminal reports whether stream, such as stdin or stdout, is a
+// terminal rather than a pipe, file or buffer
+func isTerminal(stream any) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
 	if err != nil {
 		return false
 	}
@@ -118,9 +244,9 @@ func isTerminal() bool {
 
 // CollectInteractively collects input interactively
 func (ic *InputCollector) CollectInteractively(presetType string) (*models.APIConfig, error) {
-	reader := bufio.NewReader(os.Stdin)
+	reader := bufio.NewReader(stdin)
 
-	fmt.Print("Enter config alias: ")
+	fmt.Fprint(stdout, "Enter config alias: ")
 	alias, _ := reader.ReadString('\n')
 	alias = strings.TrimSpace(alias)
 
@@ -130,21 +256,21 @@ func (ic *InputCollector) CollectInteractively(presetType string) (*models.APICo
 	switch presetType {
 	case "api_key":
 		// API key was provided via command line
-		fmt.Print("Enter auth token (optional): ")
+		fmt.Fprint(stdout, "Enter auth token (optional): ")
 		authToken, _ = reader.ReadString('\n')
 		authToken = strings.TrimSpace(authToken)
 	case "auth_token":
 		// Auth token was provided via command line
-		fmt.Print("Enter API key (optional): ")
+		fmt.Fprint(stdout, "Enter API key (optional): ")
 		apiKey, _ = reader.ReadString('\n')
 		apiKey = strings.TrimSpace(apiKey)
 	default:
 		// Fully interactive
-		fmt.Print("Enter API key (optional, either api_key or auth_token is required): ")
+		fmt.Fprint(stdout, "Enter API key (optional, either api_key or auth_token is required): ")
 		apiKey, _ = reader.ReadString('\n')
 		apiKey = strings.TrimSpace(apiKey)
 
-		fmt.Print("Enter auth token (optional, either api_key or auth_token is required): ")
+		fmt.Fprint(stdout, "Enter auth token (optional, either api_key or auth_token is required): ")
 		authToken, _ = reader.ReadString('\n')
 		authToken = strings.TrimSpace(authToken)
 	}
@@ -154,16 +280,25 @@ func (ic *InputCollector) CollectInteractively(presetType string) (*models.APICo
 		return nil, fmt.Errorf("must provide either API key or auth token")
 	}
 
-	fmt.Print("Enter API base URL (optional, default https://api.anthropic.com): ")
+	fmt.Fprint(stdout, "Enter API base URL (optional, default https://api.anthropic.com): ")
 	url, _ = reader.ReadString('\n')
 	url = strings.TrimSpace(url)
 	if url == "" {
 		url = "https://api.anthropic.com"
 	}
 
-	fmt.Print("Enter model name (optional): ")
+	// Known hosted APIs pre-select the provider and models
+	provider, service := detectURLDefaults(url)
+	var suggested []string
+	if service != nil {
+		suggested = service.Models
+		fmt.Fprintf(stdout, "Detected %s (%s API)\n", service.Name, provider)
+		fmt.Fprintf(stdout, "Enter model name (optional, default %s): ", suggested[0])
+	} else {
+		fmt.Fprint(stdout, "Enter model name (optional): ")
+	}
 	model, _ = reader.ReadString('\n')
-	model = strings.TrimSpace(model)
+	model, models := withSuggestedModels(strings.TrimSpace(model), suggested)
 
 	// Use builder to create config
 	builder := NewAPIConfigBuilder().
@@ -171,14 +306,43 @@ func (ic *InputCollector) CollectInteractively(presetType string) (*models.APICo
 		SetAPIKey(apiKey).
 		SetAuthToken(authToken).
 		SetBaseURL(url).
-		SetModel(model)
+		SetProvider(provider).
+		SetModel(model).
+		SetModels(models)
 
 	return builder.Build()
 }
 
+// detectURLDefaults returns the provider detected from a base URL and, for
+// a known hosted API, the service with its suggested models
+func detectURLDefaults(baseURL string) (string, *compatibility.Service) {
+	if service, ok := compatibility.DetectService(baseURL); ok {
+		return service.Provider, &service
+	}
+	provider, _ := compatibility.DetectProviderFromURL(baseURL)
+	return provider, nil
+}
+
+// withSuggestedModels returns the model, the first suggestion when empty,
+// and the suggested models list with the model first when it is not one of
+// them. Without suggestions the list is left empty.
+func withSuggestedModels(model string, suggested []string) (string, []string) {
+	if len(suggested) == 0 {
+		return model, nil
+	}
+	if model == "" {
+		model = suggested[0]
+	}
+	if !slices.Contains(suggested, model) {
+		return model, append([]string{model}, suggested...)
+	}
+	return model, suggested
+}
+
 var addCmd = &cobra.Command{
-	Use:   "add [alias]",
-	Short: "Add a new API configuration",
+	Use:         "add [alias]",
+	Annotations: mutates,
+	Short:       "Add a new API configuration",
 	Long: `Add a new API configuration - supports multiple modes:
 
 1. Fully interactive:
@@ -192,12 +356,15 @@ var addCmd = &cobra.Command{
    apimgr add my-config --sk sk-xxx --models "claude-3-opus,claude-3-sonnet,gpt-4"
    apimgr add my-config --sk sk-xxx --model claude-3-opus --models "claude-3-opus,claude-3-sonnet"
 
-4. Preset mode (has preset but missing alias):
+4. Inherit shared settings from a base config, overriding only the key:
+   apimgr add alice --extends team-relay --sk sk-alice
+
+5. Preset mode (has preset but missing alias):
    apimgr add --sk sk-xxx -u https://api.anthropic.com -m claude-3
    apimgr add --ak bearer-token`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewConfigManager()
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
@@ -219,19 +386,40 @@ var addCmd = &cobra.Command{
 			url, _ := cmd.Flags().GetString("url")
 			model, _ := cmd.Flags().GetString("model")
 			modelsStr, _ := cmd.Flags().GetString("models")
+			environment, _ := cmd.Flags().GetString("env")
+			insecure, _ := cmd.Flags().GetBool("insecure-skip-verify")
+			caBundle, _ := cmd.Flags().GetString("ca-bundle")
+			clientCert, _ := cmd.Flags().GetString("client-cert")
+			clientKey, _ := cmd.Flags().GetString("client-key")
+			extends, _ := cmd.Flags().GetString("extends")
+			chatPath, _ := cmd.Flags().GetString("chat-path")
+			apiVersion, _ := cmd.Flags().GetString("api-version")
+			betas, _ := cmd.Flags().GetString("beta")
+			provider, _ := cmd.Flags().GetString("provider")
+			orgID, _ := cmd.Flags().GetString("org-id")
+			projectID, _ := cmd.Flags().GetString("project-id")
+			keys, _ := cmd.Flags().GetString("keys")
+			keyStrategy, _ := cmd.Flags().GetString("key-strategy")
+			region, _ := cmd.Flags().GetString("region")
+			apiTimeout, _ := cmd.Flags().GetString("api-timeout")
+			var maxRetries *int
+			if cmd.Flags().Changed("max-retries") {
+				retries, _ := cmd.Flags().GetInt("max-retries")
+				maxRetries = &retries
+			}
+			maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+			temperature := changedFloat(cmd, "temperature")
+			topP := changedFloat(cmd, "top-p")
 
-			// Set default value
-			if url == "" {
+			// Set default value, a config extending another one inherits it
+			// and a region picks the host of the provider's regional endpoint
+			if url == "" && extends == "" && region == "" {
 				url = "https://api.anthropic.com"
 			}
 
 			// Validate at least one authentication method
-			if apiKey == "" && authToken == "" {
-				fmt.Println("❌ Error: Must provide either --sk or --ak parameter")
-				fmt.Println("\n💡 Usage examples:")
-				fmt.Println("  apimgr add my-config --sk sk-xxx")
-				fmt.Println("  apimgr add my-config --ak token-xxx")
-				os.Exit(1)
+			if apiKey == "" && authToken == "" && keys == "" && extends == "" {
+				return exitcode.New(exitcode.Validation, "must provide either --sk, --keys or --ak, e.g. 'apimgr add my-config --sk sk-xxx'")
 			}
 
 			// Process models list and model/models flag interaction
@@ -244,8 +432,7 @@ var addCmd = &cobra.Command{
 				models = parseModelsList(modelsStr)
 				// Validate models list is not empty
 				if len(models) == 0 {
-					fmt.Println("❌ Error: --models list cannot be empty")
-					os.Exit(1)
+					return exitcode.New(exitcode.Validation, "--models list cannot be empty")
 				}
 			}
 
@@ -269,6 +456,9 @@ var addCmd = &cobra.Command{
 			case hasModels && !hasModel:
 				// When only --models: use first as active
 				model = models[0]
+			case hasModel && !hasModels && extends != "":
+				// Keep inheriting the base config's models list
+				models = nil
 			case hasModel && !hasModels:
 				// When only --model: create single-item models list
 				models = []string{model}
@@ -282,13 +472,26 @@ var addCmd = &cobra.Command{
 				SetAPIKey(apiKey).
 				SetAuthToken(authToken).
 				SetBaseURL(url).
+				SetRegion(region).
 				SetModel(model).
-				SetModels(models)
+				SetModels(models).
+				SetEnvironment(environment).
+				SetInsecureSkipVerify(insecure).
+				SetCABundle(caBundle).
+				SetClientCert(clientCert, clientKey).
+				SetChatPath(chatPath).
+				SetAPIVersion(apiVersion).
+				SetBetaFeatures(parseBetaFeatures(betas)).
+				SetProvider(provider).
+				SetScope(orgID, projectID).
+				SetKeyPool(parseModelsList(keys), keyStrategy).
+				SetGeneration(temperature, maxTokens, topP).
+				SetRequestSettings(apiTimeout, maxRetries).
+				SetExtends(extends)
 
 			cfg, err = builder.Build()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
-				os.Exit(1)
+				return exitcode.Wrap(exitcode.Validation, err)
 			}
 
 		case hasSK || hasAK:
@@ -300,48 +503,41 @@ var addCmd = &cobra.Command{
 				presetType = "auth_token"
 			}
 
-			if !isTerminal() {
-				fmt.Println("❌ Interactive input is not supported in the current environment, please provide an alias:")
-				fmt.Printf("  apimgr add <alias> --%s <value> [--url <url>] [--model <model>]\n",
+			if !isTerminal(stdin) {
+				return exitcode.New(exitcode.Usage, "interactive input is not supported in the current environment, provide an alias: apimgr add <alias> --%s <value> [--url <url>] [--model <model>]",
 					map[bool]string{true: "sk", false: "ak"}[hasSK])
-				os.Exit(1)
 			}
 
 			cfg, err = collector.CollectInteractively(presetType)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				return exitcode.Wrap(exitcode.Validation, err)
 			}
 
 		default:
 			// Fully interactive mode
-			if !isTerminal() {
-				fmt.Println("❌ Interactive input is not supported in the current environment")
-				fmt.Printf("  apimgr add <alias> --sk <key> [--url <url>] [--model <model>]\n")
-				os.Exit(1)
+			if !isTerminal(stdin) {
+				return exitcode.New(exitcode.Usage, "interactive input is not supported in the current environment: apimgr add <alias> --sk <key> [--url <url>] [--model <model>]")
 			}
 
 			cfg, err = collector.CollectInteractively("")
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				return exitcode.Wrap(exitcode.Validation, err)
 			}
 		}
 
 		// Save the configuration
 		err = configManager.Add(*cfg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Failed to save configuration: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to save configuration: %w", err)
 		}
 
 		// Generate active script
 		if err := configManager.GenerateActiveScript(); err != nil {
-			fmt.Fprintf(os.Stderr, "⚠️  Warning: Failed to generate activation script: %v\n", err)
+			notice.Printf("⚠️  Warning: Failed to generate activation script: %v\n", err)
 		}
 
-		fmt.Printf("✅ Configuration added: %s\n", cfg.Alias)
-		fmt.Println("\n💡 Tip: Run 'apimgr switch <alias>' to switch to this configuration")
+		fmt.Fprintf(stdout, "✅ Configuration added: %s\n", cfg.Alias)
+		fmt.Fprintln(stdout, "\n💡 Tip: Run 'apimgr switch <alias>' to switch to this configuration")
 		return nil
 	},
 }
@@ -353,4 +549,24 @@ func init() {
 	addCmd.Flags().String("models", "", "Comma-separated list of supported models")
 	addCmd.Flags().String("sk", "", "API key (ANTHROPIC_API_KEY)")
 	addCmd.Flags().String("ak", "", "Auth token (ANTHROPIC_AUTH_TOKEN)")
+	addCmd.Flags().String("env", "", "Deployment environment (e.g. dev, staging, prod)")
+	addCmd.Flags().Bool("insecure-skip-verify", false, "Skip TLS certificate verification in ping/compatibility tests")
+	addCmd.Flags().String("ca-bundle", "", "PEM CA bundle path for endpoints signed by a private CA")
+	addCmd.Flags().String("client-cert", "", "PEM client certificate path for mutual TLS (use with --client-key)")
+	addCmd.Flags().String("client-key", "", "PEM client key path for mutual TLS (use with --client-cert)")
+	addCmd.Flags().String("chat-path", "", "Chat endpoint path of tests for relays with non-standard routes (e.g. /api/claude/v1/messages)")
+	addCmd.Flags().String("api-version", "", "anthropic-version header for tests and Claude Code (e.g. 2023-06-01)")
+	addCmd.Flags().String("beta", "", "Comma-separated anthropic-beta header values for tests and Claude Code")
+	addCmd.Flags().String("provider", "", "API provider: anthropic, openai, gemini (default anthropic or defaults.provider)")
+	addCmd.Flags().String("org-id", "", "Organization of an org-scoped key (OPENAI_ORG_ID)")
+	addCmd.Flags().String("project-id", "", "Project of a project-scoped key (OPENAI_PROJECT_ID, GOOGLE_CLOUD_PROJECT)")
+	addCmd.Flags().Float64("temperature", 0, "Sampling temperature of test requests, exported as APIMGR_TEMPERATURE")
+	addCmd.Flags().Int("max-tokens", 0, "Output token limit of test requests and Claude Code (CLAUDE_CODE_MAX_OUTPUT_TOKENS)")
+	addCmd.Flags().Float64("top-p", 0, "Nucleus sampling of test requests, exported as APIMGR_TOP_P")
+	addCmd.Flags().String("keys", "", "Comma-separated API key pool, switch picks one key from it")
+	addCmd.Flags().String("key-strategy", "", "How switch picks from --keys: round-robin (default) or lru")
+	addCmd.Flags().String("extends", "", "Inherit unset fields (URL, key, models, TLS) from this config")
+	addCmd.Flags().String("api-timeout", "", "Request timeout of Claude Code for slow relays, e.g. 10m (API_TIMEOUT_MS)")
+	addCmd.Flags().Int("max-retries", 0, "Retries of Claude Code after a failed request (CLAUDE_CODE_MAX_RETRIES)")
+	addCmd.Flags().String("region", "", "Region of a regional endpoint, sets the base URL (e.g. eu for openai)")
 }
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
+
+	"apimgr/internal/exitcode"
 )
 
 func TestAddCmd(t *testing.T) {
@@ -55,6 +60,40 @@ func TestAddCmd(t *testing.T) {
 	})
 }
 
+func TestAddReturnsErrors(t *testing.T) {
+	_, _, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+
+	// The errors are printed by ExitWithError as --error-format selects, so
+	// add does not print them itself
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantCode int
+	}{
+		{"no key", []string{"add", "x"}, "must provide either --sk", exitcode.Validation},
+		{"empty models", []string{"add", "x", "--sk", "sk-x", "--models", ","}, "--models list cannot be empty", exitcode.Validation},
+		{"invalid config", []string{"add", "x", "--sk", "sk-x", "--url", "not a url"}, "invalid URL format", exitcode.Validation},
+		{"not interactive", []string{"add", "--sk", "sk-x"}, "interactive input is not supported", exitcode.Usage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			err := Run(tt.args, IO{In: strings.NewReader(""), Out: &out, Err: &errOut}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Run(%v) error = %v, want it to contain %q", tt.args, err, tt.want)
+			}
+			if code := exitcode.Of(err); code != tt.wantCode {
+				t.Errorf("Run(%v) code = %d, want %d", tt.args, code, tt.wantCode)
+			}
+			if out.Len() > 0 || strings.Contains(errOut.String(), tt.want) {
+				t.Errorf("Run(%v) printed %q and %q, want the error left to ExitWithError", tt.args, out.String(), errOut.String())
+			}
+		})
+	}
+}
+
 func TestAPIConfigBuilder(t *testing.T) {
 	t.Run("Build with valid config", func(t *testing.T) {
 		builder := NewAPIConfigBuilder().
@@ -216,3 +255,37 @@ func TestParseModelsList(t *testing.T) {
 		}
 	})
 }
+
+func TestWithSuggestedModels(t *testing.T) {
+	tests := []struct {
+		model      string
+		suggested  []string
+		wantModel  string
+		wantModels []string
+	}{
+		{"", nil, "", nil},
+		{"m", nil, "m", nil},
+		{"", []string{"a", "b"}, "a", []string{"a", "b"}},
+		{"b", []string{"a", "b"}, "b", []string{"a", "b"}},
+		{"c", []string{"a", "b"}, "c", []string{"c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		model, models := withSuggestedModels(tt.model, tt.suggested)
+		if model != tt.wantModel || !reflect.DeepEqual(models, tt.wantModels) {
+			t.Errorf("withSuggestedModels(%q, %v) = %q, %v, want %q, %v", tt.model, tt.suggested, model, models, tt.wantModel, tt.wantModels)
+		}
+	}
+}
+
+func TestDetectURLDefaults(t *testing.T) {
+	if provider, service := detectURLDefaults("https://api.moonshot.ai/anthropic"); provider != "anthropic" || service == nil || service.Name != "Moonshot" {
+		t.Errorf("detectURLDefaults(moonshot) = %q, %+v", provider, service)
+	}
+	if provider, service := detectURLDefaults("https://api.anthropic.com"); provider != "anthropic" || service != nil {
+		t.Errorf("detectURLDefaults(anthropic) = %q, %+v, want no service", provider, service)
+	}
+	if provider, service := detectURLDefaults("https://relay.example.com"); provider != "" || service != nil {
+		t.Errorf("detectURLDefaults(relay) = %q, %+v, want nothing", provider, service)
+	}
+}
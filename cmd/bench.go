@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"apimgr/internal/probe"
+	"apimgr/internal/providers"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchRegions bool          // Compare the regions of the configuration's regional endpoint
+	benchCount   int           // Requests sent to each endpoint
+	benchTimeout time.Duration // Timeout of each request
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().BoolVar(&benchRegions, "regions", false, "Compare the regions of the configuration's regional endpoint and recommend the fastest")
+	benchCmd.Flags().IntVarP(&benchCount, "count", "c", probe.DefaultSamples, "Requests sent to each endpoint")
+	benchCmd.Flags().DurationVarP(&benchTimeout, "timeout", "t", 10*time.Second, "Timeout of each request")
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [alias...]",
+	Short: "Compare the latency of endpoints",
+	Long: `Send a few requests to the base URLs of configurations, all of them when
+none is given, and list them from fastest to slowest. Any HTTP response
+counts, so no key is sent.
+
+With --regions, the hosts of every region of the configuration's regional
+endpoint (the active one when no alias is given) are compared instead, and
+the fastest is recommended. The region field switches to it.
+
+Examples:
+  apimgr bench                   # Every configuration
+  apimgr bench relay-a relay-b   # Only these
+  apimgr bench kimi --regions    # Which region of kimi's endpoint is fastest`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		if benchCount < 1 {
+			return exitcode.New(exitcode.Usage, "--count must be at least 1")
+		}
+
+		var cfgs []models.APIConfig
+		switch {
+		case benchRegions && len(args) > 1:
+			return exitcode.New(exitcode.Usage, "--regions compares the regions of one configuration")
+		case benchRegions:
+			cfg, err := configManager.GetActive()
+			if len(args) == 1 {
+				var alias string
+				if alias, err = configManager.ResolveAlias(args[0]); err == nil {
+					cfg, err = configManager.Get(alias)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			cfgs = []models.APIConfig{*cfg}
+		case len(args) > 0:
+			for _, arg := range args {
+				alias, err := configManager.ResolveAlias(arg)
+				if err != nil {
+					return err
+				}
+				cfg, err := configManager.Get(alias)
+				if err != nil {
+					return err
+				}
+				cfgs = append(cfgs, *cfg)
+			}
+		default:
+			if cfgs, err = configManager.List(); err != nil {
+				return err
+			}
+		}
+
+		var targets []benchTarget
+		current := ""
+		if benchRegions {
+			cfg := cfgs[0]
+			endpoint, ok := providers.EndpointOf(cfg.Provider, cfg.BaseURL)
+			if !ok {
+				return exitcode.New(exitcode.Validation, "configuration '%s' is not on a regional endpoint (base URL %s)", cfg.Alias, cfg.BaseURL)
+			}
+			current = endpoint.RegionOf(cfg.BaseURL)
+			for _, region := range endpoint.RegionNames() {
+				targets = append(targets, benchTarget{Name: region, URL: endpoint.Regions[region], Config: &cfg})
+			}
+		} else {
+			for i := range cfgs {
+				targets = append(targets, benchTarget{Name: cfgs[i].Alias, URL: cfgs[i].BaseURL, Config: &cfgs[i]})
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Fprintln(stdout, "No configurations to benchmark")
+			return nil
+		}
+
+		notice.Println("Benchmarking...")
+		results := runBench(targets, benchCount, benchTimeout)
+		writeBenchResults(results, current)
+
+		if benchRegions {
+			fastest := results[0]
+			switch {
+			case fastest.Err != nil:
+				return exitcode.New(exitcode.Network, "no region of '%s' could be reached", cfgs[0].Alias)
+			case fastest.Name == current:
+				fmt.Fprintf(stdout, "\n%s is already on the fastest region, %s\n", cfgs[0].Alias, current)
+			default:
+				fmt.Fprintf(stdout, "\nFastest region: %s (avg %dms)\n", fastest.Name, fastest.Stats.Avg.Milliseconds())
+				fmt.Fprintf(stdout, "Switch to it with: apimgr set %s region=%s\n", cfgs[0].Alias, fastest.Name)
+			}
+		}
+		return nil
+	},
+}
+
+// benchTarget is an endpoint measured by bench
+type benchTarget struct {
+	Name   string            // Alias or region
+	URL    string            // Base URL requested
+	Config *models.APIConfig // TLS settings of the requests
+}
+
+// benchResult is the latency of a bench target
+type benchResult struct {
+	benchTarget
+	Stats probe.Stats
+	Err   error // Last failure, nil when a sample succeeded
+}
+
+// runBench sends samples requests to each target and returns the results
+// from fastest to slowest, unreachable targets last
+func runBench(targets []benchTarget, samples int, timeout time.Duration) []benchResult {
+	results := make([]benchResult, 0, len(targets))
+	for _, target := range targets {
+		result := benchResult{benchTarget: target}
+		var durations []time.Duration
+		failures := 0
+		for i := 0; i < samples; i++ {
+			d, err := benchRequest(target, timeout)
+			if err != nil {
+				failures++
+				result.Err = err
+				continue
+			}
+			durations = append(durations, d)
+		}
+		if len(durations) > 0 {
+			result.Err = nil
+		}
+		result.Stats = probe.Summarize(durations, failures)
+		results = append(results, result)
+	}
+
+	slices.SortStableFunc(results, func(a, b benchResult) int {
+		if aUp, bUp := a.Err == nil, b.Err == nil; aUp != bUp {
+			if aUp {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Stats.Avg, b.Stats.Avg)
+	})
+	return results
+}
+
+// benchRequest times one unauthenticated GET of the target's base URL
+func benchRequest(target benchTarget, timeout time.Duration) (time.Duration, error) {
+	if err := utils.CheckPlaceholders(target.URL); err != nil {
+		return 0, err
+	}
+	tlsConfig, err := utils.NewTLSConfig(target.Config)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	// A new transport per request, so every sample includes the handshakes
+	client := &http.Client{Timeout: timeout, Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, timings, err := probe.Do(client, req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return timings.Total, nil
+}
+
+// writeBenchResults prints the results as a table, marking the current
+// region
+func writeBenchResults(results []benchResult, current string) {
+	width := len("NAME")
+	for _, r := range results {
+		width = max(width, len(r.Name)+2)
+	}
+	fmt.Fprintf(stdout, "%-*s  %7s  %7s  %8s  %s\n", width, "NAME", "AVG", "P95", "FAILURES", "URL")
+	for _, r := range results {
+		name := r.Name
+		if current != "" && name == current {
+			name += " *"
+		}
+		if r.Err != nil {
+			fmt.Fprintf(stdout, "%-*s  %7s  %7s  %8s  %s (%s)\n", width, name, "-", "-", fmt.Sprintf("%d/%d", r.Stats.Failures, r.Stats.Samples), r.URL, describePingError(r.Err))
+			continue
+		}
+		fmt.Fprintf(stdout, "%-*s  %5dms  %5dms  %8s  %s\n", width, name, r.Stats.Avg.Milliseconds(), r.Stats.P95.Milliseconds(), fmt.Sprintf("%d/%d", r.Stats.Failures, r.Stats.Samples), r.URL)
+	}
+}
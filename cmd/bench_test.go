@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestRunBench(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer fast.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer slow.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	results := runBench([]benchTarget{
+		{Name: "down", URL: down.URL},
+		{Name: "slow", URL: slow.URL},
+		{Name: "fast", URL: fast.URL},
+	}, 2, 5*time.Second)
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	if strings.Join(names, ",") != "fast,slow,down" {
+		t.Errorf("runBench() order = %v, want fast, slow, down", names)
+	}
+	if results[0].Err != nil || results[2].Err == nil || results[2].Stats.Failures != 2 {
+		t.Errorf("runBench() = %+v, want the error response counted and the closed server failed", results)
+	}
+}
+
+func TestBenchRegionsNotRegional(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"},
+	}, "relay")
+
+	err := Run([]string{"bench", "relay", "--regions"}, IO{In: strings.NewReader(""), Out: &strings.Builder{}, Err: &strings.Builder{}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "not on a regional endpoint") {
+		t.Errorf("bench --regions error = %v, want the config refused", err)
+	}
+}
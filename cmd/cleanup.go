@@ -1,11 +1,10 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
-	"apimgr/config"
 	"apimgr/config/session"
+	"apimgr/internal/notice"
 
 	"github.com/spf13/cobra"
 )
@@ -20,22 +19,44 @@ var cleanupSessionCmd = &cobra.Command{
 	Long: `This command is used internally by the shell trap mechanism to cleanup session markers when a shell exits.
 
 It is automatically called by the trap command output by 'apimgr switch -l'.
-Users typically do not need to call this command directly.`,
+When the last local session ends, Claude Code settings are restored to the
+global configuration. Users typically do not need to call this command
+directly. Calling it again, or from a subshell of a shell that is still
+running, does nothing.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pid := args[0]
-		configManager, err := config.NewConfigManager()
+		configManager, err := newConfigManager()
 		if err != nil {
 			// Silently exit - this is called during shell exit
-			os.Exit(0)
+			return
 		}
 
-		if err := session.CleanupSession(configManager.GetConfigPath(), pid); err != nil {
+		// A subshell that inherited the trap must not end the parent's session
+		if !session.OwnsSession(pid, os.Getppid()) {
+			return
+		}
+
+		marker, _ := session.ReadSessionMarker(configManager.SessionDir(), pid)
+		if err := session.CleanupSession(configManager.SessionDir(), pid); err != nil {
 			// Log error but don't fail - this is called during shell exit
 			// and we don't want to prevent the shell from exiting
-			fmt.Fprintf(os.Stderr, "Warning: Failed to cleanup session: %v\n", err)
+			notice.Printf("Warning: Failed to cleanup session: %v\n", err)
 			// Exit with 0 to not interfere with shell exit
 			return
 		}
+
+		// Claude Code still points at the ended session's configuration.
+		// With no other local session left, switch it back to the global one.
+		if marker == nil {
+			return
+		}
+		hasActiveSessions, err := session.HasActiveLocalSessions(configManager.SessionDir())
+		if err != nil || hasActiveSessions {
+			return
+		}
+		if err := configManager.RestoreClaudeToGlobal(); err != nil {
+			notice.Printf("Warning: Failed to restore Claude Code to global: %v\n", err)
+		}
 	},
 }
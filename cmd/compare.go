@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareAll  bool // Show every field, not only the differing ones
+	compareTest bool // Run the basic compatibility test on both configurations
+)
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().BoolVar(&compareAll, "all", false, "Show every field, not only the differing ones")
+	compareCmd.Flags().BoolVarP(&compareTest, "test", "T", false, "Also test both configurations and compare latency and compatibility")
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <alias> <alias>",
+	Short: "Compare two configurations side by side",
+	Long: `Show the fields two configurations differ in, with inherited values filled
+in and keys masked. With --test, the basic compatibility test runs on both
+at once and their latency and compatibility are compared.
+
+Examples:
+  apimgr compare relay-a relay-b           # Differing fields
+  apimgr compare relay-a relay-b --all     # Every field
+  apimgr compare relay-a relay-b --test    # Fields, then test both`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		var cfgs [2]*models.APIConfig
+		for i, arg := range args {
+			alias, err := configManager.ResolveAlias(arg)
+			if err != nil {
+				return err
+			}
+			if cfgs[i], err = configManager.Get(alias); err != nil {
+				return err
+			}
+		}
+		a, b := cfgs[0], cfgs[1]
+		if a.Alias == b.Alias {
+			return fmt.Errorf("both arguments resolve to '%s', give two different configurations", a.Alias)
+		}
+
+		fmt.Fprint(stdout, formatFieldDiffs(a.Alias, b.Alias, config.CompareFields(a, b), compareAll))
+
+		if compareTest {
+			results, err := compareTests(configManager, a, b)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(stdout, formatTestComparison([2]string{a.Alias, b.Alias}, results))
+		}
+		return nil
+	},
+}
+
+// formatFieldDiffs formats compared fields as a table headed by the aliases,
+// differing rows marked with *. Only differing rows are listed unless all is
+// set.
+func formatFieldDiffs(aliasA, aliasB string, diffs []config.FieldDiff, all bool) string {
+	var rows []config.FieldDiff
+	nameWidth, valueWidth := len("FIELD"), len(aliasA)
+	for _, d := range diffs {
+		// The header names the aliases
+		if d.Name == "alias" || (!all && !d.Differs()) {
+			continue
+		}
+		d.A, d.B = formatField(d.A, d.Secret, false), formatField(d.B, d.Secret, false)
+		rows = append(rows, d)
+		nameWidth = max(nameWidth, len(d.Name))
+		valueWidth = max(valueWidth, len(d.A))
+	}
+	if len(rows) == 0 {
+		return fmt.Sprintf("'%s' and '%s' have the same fields\n", aliasA, aliasB)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-*s  %-*s  %s\n", nameWidth, "FIELD", valueWidth, aliasA, aliasB)
+	for _, d := range rows {
+		marker := " "
+		if d.Differs() {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %-*s  %-*s  %s\n", marker, nameWidth, d.Name, valueWidth, d.A, d.B)
+	}
+	return b.String()
+}
+
+// compareTests runs the basic compatibility test on two configurations
+// concurrently, recording both results in their health history
+func compareTests(configManager *config.Manager, a, b *models.APIConfig) ([2]modelResult, error) {
+	var results [2]modelResult
+	settings, err := configManager.GetTestSettings()
+	if err != nil {
+		return results, err
+	}
+	opts, err := compatibility.OptionsFromSettings(settings)
+	if err != nil {
+		return results, err
+	}
+
+	var wg sync.WaitGroup
+	for i, cfg := range []*models.APIConfig{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = testModel(cfg, cfg.Model, opts)
+		}()
+	}
+	wg.Wait()
+
+	// Recorded once both finished, not from the test goroutines
+	for i, cfg := range []*models.APIConfig{a, b} {
+		recordHistory(configManager, cfg.Alias, history.Entry{
+			Kind:      history.KindTest,
+			Success:   results[i].Success,
+			LatencyMs: results[i].ResponseTimeMs,
+			Detail:    results[i].CompatibilityLevel,
+		})
+	}
+	return results, nil
+}
+
+// formatTestComparison formats the test results of two configurations,
+// naming the faster one when both passed
+func formatTestComparison(aliases [2]string, results [2]modelResult) string {
+	width := max(len(aliases[0]), len(aliases[1]))
+	var b strings.Builder
+	b.WriteString("\nTest results:\n")
+	for i, r := range results {
+		row := fmt.Sprintf("  %s %-*s %-7s %6dms  %s", compatibilityMark(r.CompatibilityLevel), width, aliases[i], r.CompatibilityLevel, r.ResponseTimeMs, r.Error)
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+
+	a, c := results[0], results[1]
+	switch {
+	case a.Success && c.Success && a.ResponseTimeMs != c.ResponseTimeMs:
+		fast, slow := 0, 1
+		if c.ResponseTimeMs < a.ResponseTimeMs {
+			fast, slow = 1, 0
+		}
+		fmt.Fprintf(&b, "'%s' is faster by %dms\n", aliases[fast], results[slow].ResponseTimeMs-results[fast].ResponseTimeMs)
+	case a.Success != c.Success:
+		passed := 0
+		if c.Success {
+			passed = 1
+		}
+		fmt.Fprintf(&b, "Only '%s' passed\n", aliases[passed])
+	}
+	return b.String()
+}
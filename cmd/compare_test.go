@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/models"
+)
+
+func TestFormatFieldDiffs(t *testing.T) {
+	diffs := []config.FieldDiff{
+		{Name: "alias", A: "a", B: "b"},
+		{Name: "api_key", A: "sk-aaaaaaaaaaaa", B: "sk-bbbbbbbbbbbb", Secret: true},
+		{Name: "base_url", A: "https://relay.example.com", B: "https://relay.example.com"},
+		{Name: "model", A: "m1", B: ""},
+	}
+
+	got := formatFieldDiffs("a", "b", diffs, false)
+	want := "  FIELD    a             b\n" +
+		"* api_key  sk-a****aaaa  sk-b****bbbb\n" +
+		"* model    m1            (unset)\n"
+	if got != want {
+		t.Errorf("formatFieldDiffs() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := formatFieldDiffs("a", "b", diffs, true); !strings.Contains(got, "  base_url ") {
+		t.Errorf("formatFieldDiffs(all) left out an equal field:\n%s", got)
+	}
+	if got := formatFieldDiffs("a", "b", diffs[2:3], false); got != "'a' and 'b' have the same fields\n" {
+		t.Errorf("formatFieldDiffs() of equal configs = %q", got)
+	}
+}
+
+func TestFormatTestComparison(t *testing.T) {
+	aliases := [2]string{"a", "bb"}
+	tests := []struct {
+		name    string
+		results [2]modelResult
+		want    string
+	}{
+		{
+			name:    "both passed",
+			results: [2]modelResult{{Success: true, ResponseTimeMs: 300}, {Success: true, ResponseTimeMs: 100}},
+			want:    "'bb' is faster by 200ms\n",
+		},
+		{
+			name:    "one passed",
+			results: [2]modelResult{{Success: true, ResponseTimeMs: 300}, {Error: "Model not found"}},
+			want:    "Only 'a' passed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTestComparison(aliases, tt.results); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("formatTestComparison() = %q, want suffix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareTestsRecordsHistory(t *testing.T) {
+	_, _, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	a := &models.APIConfig{Alias: "a", APIKey: "sk-a", BaseURL: server.URL, Model: "m"}
+	b := &models.APIConfig{Alias: "b", APIKey: "sk-b", BaseURL: server.URL, Model: "m"}
+	if _, err := compareTests(cm, a, b); err != nil {
+		t.Fatalf("compareTests() unexpected error: %v", err)
+	}
+
+	for _, alias := range []string{"a", "b"} {
+		entries, err := history.Load(cm.StateDir(), alias)
+		if err != nil || len(entries) != 1 || entries[0].Kind != history.KindTest {
+			t.Errorf("history of %s = %+v (%v), want one test result", alias, entries, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	syncpkg "apimgr/config/sync"
+
+	"github.com/spf13/cobra"
+)
+
+var completionShell string // Shell to install completion for, detected from $SHELL when empty
+
+// completionMarker ends the rc file line loading the completion script
+const completionMarker = "# apimgr completion"
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the autocompletion script for your shell",
+	Long: `Write the autocompletion script for bash, zsh or fish to the usual location
+and load it from your shell configuration. The shell is detected from $SHELL
+unless --shell is given. Running it again refreshes the script without
+adding the rc line twice.
+
+Locations:
+  bash  ~/.local/share/bash-completion/completions/apimgr, sourced from ~/.bashrc
+  zsh   ~/.local/share/zsh/site-functions/_apimgr, sourced from ~/.zshrc
+  fish  ~/.config/fish/completions/apimgr.fish, loaded by fish automatically`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := completionShell
+		if shell == "" {
+			shell = filepath.Base(os.Getenv("SHELL"))
+		}
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get user home directory: %w", err)
+		}
+
+		target, err := completionTarget(shell, homeDir, os.Getenv("XDG_DATA_HOME"), os.Getenv("XDG_CONFIG_HOME"))
+		if err != nil {
+			return err
+		}
+
+		var script bytes.Buffer
+		if err := generateCompletion(cmd.Root(), target.shell, &script); err != nil {
+			return fmt.Errorf("failed to generate completion script: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target.scriptPath), 0755); err != nil {
+			return fmt.Errorf("failed to create completion directory: %w", err)
+		}
+		if err := os.WriteFile(target.scriptPath, script.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write completion script: %w", err)
+		}
+		fmt.Fprintf(stdout, "✓ Wrote %s completion to %s\n", target.shell, target.scriptPath)
+
+		if target.rcFile == "" {
+			fmt.Fprintln(stdout, "Open a new shell to use it")
+			return nil
+		}
+		added, err := appendLineOnce(target.rcFile, target.rcLine)
+		if err != nil {
+			return err
+		}
+		if added {
+			fmt.Fprintf(stdout, "✓ Added completion loading to %s\n", target.rcFile)
+		} else {
+			fmt.Fprintf(stdout, "✓ %s already loads the completion\n", target.rcFile)
+		}
+		fmt.Fprintf(stdout, "Run 'source %s' or open a new shell to use it\n", target.rcFile)
+		return nil
+	},
+}
+
+// addCompletionInstall adds the install subcommand to cobra's default
+// completion command, which only exists once every command is registered
+func addCompletionInstall() {
+	if completionInstallCmd.HasParent() {
+		return
+	}
+	rootCmd.InitDefaultCompletionCmd()
+	completion, _, err := rootCmd.Find([]string{"completion"})
+	if err != nil || completion == rootCmd {
+		return
+	}
+	completionInstallCmd.Flags().StringVar(&completionShell, "shell", "", "Shell to install for: bash, zsh or fish (default from $SHELL)")
+	completion.AddCommand(completionInstallCmd)
+}
+
+// completionInstall describes where a shell's completion script goes
+type completionInstall struct {
+	shell      string
+	scriptPath string // Completion script
+	rcFile     string // Shell config loading the script, empty when the shell loads it itself
+	rcLine     string // Line added to rcFile
+}
+
+// completionTarget returns the install locations for a shell
+func completionTarget(shell, homeDir, xdgDataHome, xdgConfigHome string) (completionInstall, error) {
+	if xdgDataHome == "" {
+		xdgDataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(homeDir, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		path := filepath.Join(xdgDataHome, "bash-completion", "completions", "apimgr")
+		return completionInstall{
+			shell:      shell,
+			scriptPath: path,
+			rcFile:     filepath.Join(homeDir, ".bashrc"),
+			rcLine:     fmt.Sprintf("[[ -f %s ]] && source %s %s", syncpkg.ShellQuote(path), syncpkg.ShellQuote(path), completionMarker),
+		}, nil
+	case "zsh":
+		path := filepath.Join(xdgDataHome, "zsh", "site-functions", "_apimgr")
+		return completionInstall{
+			shell:      shell,
+			scriptPath: path,
+			rcFile:     filepath.Join(homeDir, ".zshrc"),
+			rcLine: fmt.Sprintf("[[ -f %s ]] && { (( $+functions[compdef] )) || { autoload -Uz compinit && compinit }; source %s } %s",
+				syncpkg.ShellQuote(path), syncpkg.ShellQuote(path), completionMarker),
+		}, nil
+	case "fish":
+		return completionInstall{
+			shell:      shell,
+			scriptPath: filepath.Join(xdgConfigHome, "fish", "completions", "apimgr.fish"),
+		}, nil
+	default:
+		return completionInstall{}, fmt.Errorf("unsupported shell '%s', use --shell bash, zsh or fish, or 'apimgr completion <shell>' to print the script", shell)
+	}
+}
+
+// generateCompletion writes the completion script of a shell
+func generateCompletion(root *cobra.Command, shell string, w *bytes.Buffer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	default:
+		return fmt.Errorf("unsupported shell '%s'", shell)
+	}
+}
+
+// appendLineOnce appends line to file unless the file already contains it,
+// reporting whether it was added. A line ending with the same marker comment,
+// written by an earlier version, is replaced instead.
+func appendLineOnce(path, line string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	marker := ""
+	if i := strings.LastIndex(line, " #"); i >= 0 {
+		marker = line[i+1:]
+	}
+	lines := strings.Split(string(content), "\n")
+	for i, existing := range lines {
+		if strings.TrimSpace(existing) == line {
+			return false, nil
+		}
+		if marker != "" && strings.HasSuffix(strings.TrimSpace(existing), marker) {
+			lines[i] = line
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+				return false, fmt.Errorf("failed to write to %s: %w", path, err)
+			}
+			return true, nil
+		}
+	}
+
+	prefix := ""
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		prefix = "\n"
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(prefix + line + "\n"); err != nil {
+		return false, fmt.Errorf("failed to write to %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletionTarget(t *testing.T) {
+	home := "/home/u"
+	tests := []struct {
+		shell      string
+		xdgData    string
+		wantScript string
+		wantRC     string
+		wantErr    bool
+	}{
+		{"bash", "", "/home/u/.local/share/bash-completion/completions/apimgr", "/home/u/.bashrc", false},
+		{"zsh", "/data", "/data/zsh/site-functions/_apimgr", "/home/u/.zshrc", false},
+		{"fish", "", "/home/u/.config/fish/completions/apimgr.fish", "", false},
+		{"tcsh", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got, err := completionTarget(tt.shell, home, tt.xdgData, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("completionTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.scriptPath != tt.wantScript || got.rcFile != tt.wantRC {
+				t.Errorf("completionTarget() = %s, %s; want %s, %s", got.scriptPath, got.rcFile, tt.wantScript, tt.wantRC)
+			}
+			if got.rcFile != "" && !strings.Contains(got.rcLine, got.scriptPath) {
+				t.Errorf("rc line %q does not load %s", got.rcLine, got.scriptPath)
+			}
+		})
+	}
+}
+
+func TestCompletionRCLineQuotesPath(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "my home")
+	for _, shell := range []string{"bash", "zsh"} {
+		t.Run(shell, func(t *testing.T) {
+			path, err := exec.LookPath(shell)
+			if err != nil {
+				t.Skipf("%s not found", shell)
+			}
+			target, err := completionTarget(shell, home, "", "")
+			if err != nil {
+				t.Fatalf("completionTarget() unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(target.rcLine, completionMarker) {
+				t.Errorf("rc line %q does not end with %q", target.rcLine, completionMarker)
+			}
+			if err := os.MkdirAll(filepath.Dir(target.scriptPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(target.scriptPath, []byte("echo loaded\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			// zsh starts without compinit, which the line loads itself
+			out, err := exec.Command(path, "-c", "compinit() { :; }; "+target.rcLine).CombinedOutput()
+			if err != nil || strings.TrimSpace(string(out)) != "loaded" {
+				t.Errorf("%s -c %q = %q, %v; want the script loaded", shell, target.rcLine, out, err)
+			}
+		})
+	}
+}
+
+func TestAppendLineOnceReplacesMarkedLine(t *testing.T) {
+	rcFile := filepath.Join(t.TempDir(), ".bashrc")
+	old := "[[ -f /home/my home/c ]] && source /home/my home/c # apimgr completion"
+	if err := os.WriteFile(rcFile, []byte("export EDITOR=vim\n"+old+"\nalias ll='ls -l'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	line := "[[ -f '/home/my home/c' ]] && source '/home/my home/c' # apimgr completion"
+	added, err := appendLineOnce(rcFile, line)
+	if err != nil || !added {
+		t.Fatalf("appendLineOnce() = %v, %v; want the old line replaced", added, err)
+	}
+	content, _ := os.ReadFile(rcFile)
+	if want := "export EDITOR=vim\n" + line + "\nalias ll='ls -l'\n"; string(content) != want {
+		t.Errorf("rc file = %q, want %q", content, want)
+	}
+}
+
+func TestAppendLineOnce(t *testing.T) {
+	rcFile := filepath.Join(t.TempDir(), ".bashrc")
+	if err := os.WriteFile(rcFile, []byte("export EDITOR=vim"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	line := "source ~/completion # apimgr completion"
+	for i, wantAdded := range []bool{true, false} {
+		added, err := appendLineOnce(rcFile, line)
+		if err != nil {
+			t.Fatalf("appendLineOnce() unexpected error: %v", err)
+		}
+		if added != wantAdded {
+			t.Errorf("appendLineOnce() call %d added = %v, want %v", i+1, added, wantAdded)
+		}
+	}
+
+	content, _ := os.ReadFile(rcFile)
+	if want := "export EDITOR=vim\n" + line + "\n"; string(content) != want {
+		t.Errorf("rc file = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+
+	"apimgr/config"
+	"apimgr/config/storage"
+	"apimgr/internal/notice"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configConvertCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change config file settings",
+	Long: `Read and change settings stored in the config file outside individual
+configurations.
+
+Settings:
+  defaults.provider      Provider of new configurations (default anthropic)
+  defaults.model         Model of configurations that set none
+  defaults.sync_targets  Targets synced on switch: claude, none or names from
+                         the targets section (default all)
+  defaults.test_timeout  Compatibility test timeout when test_settings sets none
+  defaults.key_mode      How Claude Code gets the key: env writes it into
+                         settings, helper points apiKeyHelper at a script
+                         running apimgr get, shell leaves it to the shell
+                         environment (default env)
+  defaults.message_timeout
+                         How long TUI status messages stay, 0 keeps them
+                         (default 5s)
+  defaults.trash_days    Days removed configurations stay in the trash
+                         (default 30)
+  defaults.verify_switch Test the target of every switch and cancel the
+                         switch when the test fails (default false)
+
+Examples:
+  apimgr config get                              # Show every setting
+  apimgr config set defaults.model claude-sonnet-4
+  apimgr config set defaults.sync_targets none   # Stop writing Claude Code settings
+  apimgr config set defaults.model ""            # Clear a setting
+  apimgr config convert yaml                     # Switch the config file to config.yaml`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show a setting, or every setting when no key is given",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		if len(args) == 1 {
+			value, err := configManager.GetSetting(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, value)
+			return nil
+		}
+
+		for _, key := range config.SettingKeys() {
+			value, err := configManager.GetSetting(key)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				value = "(unset)"
+			}
+			fmt.Fprintf(stdout, "%-22s %s\n", key, value)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:         "set <key> <value>",
+	Annotations: mutates,
+	Short:       "Change a setting, an empty value clears it",
+	Args:        cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		if err := configManager.SetSetting(args[0], args[1]); err != nil {
+			return err
+		}
+
+		// Defaults may change the active configuration's effective values
+		if err := configManager.GenerateActiveScript(); err != nil {
+			notice.Printf("Warning: Failed to generate activation script: %v\n", err)
+		}
+
+		if args[1] == "" {
+			fmt.Fprintf(stdout, "✓ Cleared %s\n", args[0])
+		} else {
+			fmt.Fprintf(stdout, "✓ Set %s = %s\n", args[0], args[1])
+		}
+		return nil
+	},
+}
+
+var configConvertCmd = &cobra.Command{
+	Use:         "convert <json|yaml|toml>",
+	Annotations: mutates,
+	Short:       "Convert the config file to another format",
+	Long: `Rewrite the config file as config.json, config.yaml or config.toml. The
+old file is kept with a .bak suffix. apimgr detects the format from the file
+extension, so the converted file is used from then on.
+
+Comments in config.yaml are kept when apimgr updates the file. Comments in
+config.toml are not.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := storage.ParseFormat(args[0])
+		if err != nil {
+			return err
+		}
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		oldPath := configManager.GetConfigPath()
+		newPath, err := configManager.ConvertFormat(format)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✓ Converted config to %s\n", newPath)
+		fmt.Fprintf(stdout, "  Previous file kept at %s.bak\n", oldPath)
+		return nil
+	},
+}
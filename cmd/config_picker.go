@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"apimgr/config/models"
+)
+
+// maxPickerResults limits how many matches the picker lists at once
+const maxPickerResults = 10
+
+// ConfigPicker handles interactive fuzzy selection of a configuration
+type ConfigPicker struct{}
+
+// NewConfigPicker creates a new ConfigPicker instance
+func NewConfigPicker() *ConfigPicker {
+	return &ConfigPicker{}
+}
+
+// pickerMatch is a configuration matched by a fuzzy query
+type pickerMatch struct {
+	config models.APIConfig
+	score  int
+}
+
+// Pick prompts the user for a fuzzy query and returns the chosen alias.
+// Typing a query narrows the list, a number selects a listed entry, and
+// Enter selects the best match.
+func (cp *ConfigPicker) Pick(configs []models.APIConfig, activeAlias string) (string, error) {
+	if len(configs) == 0 {
+		return "", fmt.Errorf("no configurations available, add one with 'apimgr add'")
+	}
+
+	reader := bufio.NewReader(stdin)
+	query := ""
+
+	for {
+		matches := filterConfigs(configs, query)
+		if len(matches) == 0 {
+			fmt.Fprintf(stderr, "No configuration matches '%s'\n", query)
+			query = ""
+			matches = filterConfigs(configs, query)
+		}
+
+		fmt.Fprintln(stderr, "📋 Configurations:")
+		for i, match := range matches {
+			if i >= maxPickerResults {
+				fmt.Fprintf(stderr, "  ... %d more, type to narrow\n", len(matches)-maxPickerResults)
+				break
+			}
+			fmt.Fprintln(stderr, formatPickerLine(i+1, match.config, activeAlias))
+		}
+
+		fmt.Fprint(stderr, "\nSearch alias/model/url, or select number [Enter for 1]: ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("failed to read user input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+
+		// Enter picks the best match
+		if input == "" {
+			return matches[0].config.Alias, nil
+		}
+
+		// A number picks from the listed entries
+		if index, err := strconv.Atoi(input); err == nil {
+			if index >= 1 && index <= len(matches) && index <= maxPickerResults {
+				return matches[index-1].config.Alias, nil
+			}
+			fmt.Fprintf(stderr, "Invalid selection: %d\n\n", index)
+			continue
+		}
+
+		// A query with a single match is selected directly
+		query = input
+		if narrowed := filterConfigs(configs, query); len(narrowed) == 1 {
+			return narrowed[0].config.Alias, nil
+		}
+		fmt.Fprintln(stderr)
+	}
+}
+
+// formatPickerLine formats one picker entry with its model and base URL
+func formatPickerLine(index int, cfg models.APIConfig, activeAlias string) string {
+	marker := "  "
+	if cfg.Alias == activeAlias {
+		marker = "➤ "
+	}
+	line := fmt.Sprintf("  %s%2d. %s", marker, index, cfg.Alias)
+	if cfg.Model != "" {
+		line += fmt.Sprintf(" [%s]", cfg.Model)
+	}
+	if cfg.BaseURL != "" {
+		line += fmt.Sprintf(" (%s)", cfg.BaseURL)
+	}
+	return line
+}
+
+// filterConfigs returns the configurations matching the query, best first.
+// An empty query matches everything in the original order.
+func filterConfigs(configs []models.APIConfig, query string) []pickerMatch {
+	matches := make([]pickerMatch, 0, len(configs))
+	for _, cfg := range configs {
+		best, found := 0, false
+		// Alias matches rank above model and URL matches
+		fields := []struct {
+			values []string
+			bonus  int
+		}{
+			{[]string{cfg.Alias}, 20},
+			{append([]string{cfg.Model}, cfg.Models...), 10},
+			{[]string{cfg.BaseURL}, 0},
+		}
+		for _, field := range fields {
+			for _, value := range field.values {
+				if score, ok := fuzzyScore(query, value); ok && (!found || score+field.bonus > best) {
+					best, found = score+field.bonus, true
+				}
+			}
+		}
+		if found {
+			matches = append(matches, pickerMatch{config: cfg, score: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+// fuzzyScore reports whether every character of query appears in target in
+// order (case-insensitive) and scores the match. Consecutive characters and
+// matches at the start of the target score higher.
+func fuzzyScore(query, target string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	if target == "" {
+		return 0, false
+	}
+
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+
+	score := 0
+	qi := 0
+	prev := -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3
+		}
+		if ti == 0 {
+			score += 5
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+
+	// Exact matches always win
+	if string(q) == string(t) {
+		score += 100
+	}
+	return score, true
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+var pickerTestConfigs = []models.APIConfig{
+	{Alias: "anthropic-prod", BaseURL: "https://api.anthropic.com", Model: "claude-sonnet-4"},
+	{Alias: "openrouter", BaseURL: "https://openrouter.ai/api", Model: "gpt-4o", Models: []string{"gpt-4o", "llama-3"}},
+	{Alias: "local-dev", BaseURL: "http://localhost:8080"},
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query  string
+		target string
+		want   bool
+	}{
+		{"", "anything", true},
+		{"apd", "anthropic-prod", true},
+		{"PROD", "anthropic-prod", true},
+		{"dorp", "anthropic-prod", false},
+		{"x", "", false},
+	}
+
+	for _, tt := range tests {
+		if _, got := fuzzyScore(tt.query, tt.target); got != tt.want {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.query, tt.target, got, tt.want)
+		}
+	}
+
+	// Prefix and consecutive matches rank higher than scattered ones
+	prefix, _ := fuzzyScore("open", "openrouter")
+	scattered, _ := fuzzyScore("open", "o-p-e-n")
+	if prefix <= scattered {
+		t.Errorf("prefix score %d should be greater than scattered score %d", prefix, scattered)
+	}
+}
+
+func TestFilterConfigs(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantFirst string
+		wantCount int
+	}{
+		{name: "empty query keeps order", query: "", wantFirst: "anthropic-prod", wantCount: 3},
+		{name: "alias match", query: "dev", wantFirst: "local-dev", wantCount: 1},
+		{name: "model match", query: "llama", wantFirst: "openrouter", wantCount: 1},
+		{name: "url match", query: "localhost", wantFirst: "local-dev", wantCount: 1},
+		{name: "no match", query: "zzz", wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := filterConfigs(pickerTestConfigs, tt.query)
+			if len(matches) != tt.wantCount {
+				t.Fatalf("filterConfigs(%q) returned %d matches, want %d", tt.query, len(matches), tt.wantCount)
+			}
+			if tt.wantCount > 0 && matches[0].config.Alias != tt.wantFirst {
+				t.Errorf("filterConfigs(%q) first = %q, want %q", tt.query, matches[0].config.Alias, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestConfigPickerPick(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "enter picks first", input: "\n", want: "anthropic-prod"},
+		{name: "number picks entry", input: "2\n", want: "openrouter"},
+		{name: "unique query picks directly", input: "dev\n", want: "local-dev"},
+		{name: "query then number", input: "o\n1\n", want: "openrouter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStdin, oldStderr := stdin, stderr
+			stdin, stderr = strings.NewReader(tt.input), io.Discard
+			defer func() { stdin, stderr = oldStdin, oldStderr }()
+
+			got, err := NewConfigPicker().Pick(pickerTestConfigs, "")
+			if err != nil {
+				t.Fatalf("Pick() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Pick() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitchCommandOptionalAlias(t *testing.T) {
+	if err := switchCmd.Args(switchCmd, []string{}); err != nil {
+		t.Errorf("switch should accept no arguments, got: %v", err)
+	}
+	if err := switchCmd.Args(switchCmd, []string{"a", "b"}); err == nil {
+		t.Error("switch should reject more than one argument")
+	}
+}
+
+func TestFilterByEnvironment(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "a", Environment: "prod"},
+		{Alias: "b", Environment: "dev"},
+		{Alias: "c"},
+	}
+
+	filtered := filterByEnvironment(configs, "prod")
+	if len(filtered) != 1 || filtered[0].Alias != "a" {
+		t.Errorf("filterByEnvironment(prod) = %v, want only 'a'", filtered)
+	}
+	if got := filterByEnvironment(configs, "staging"); len(got) != 0 {
+		t.Errorf("filterByEnvironment(staging) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"github.com/spf13/cobra"
+)
+
+var copyFields []string // Fields to copy
+
+func init() {
+	rootCmd.AddCommand(copyFieldCmd)
+	copyFieldCmd.Flags().StringSliceVar(&copyFields, "fields", nil, "Comma-separated fields to copy")
+	_ = copyFieldCmd.MarkFlagRequired("fields")
+}
+
+var copyFieldCmd = &cobra.Command{
+	Use:         "copy-field <src> <dst>... --fields <field>,...",
+	Annotations: mutates,
+	Short:       "Copy fields from one configuration to others",
+	Long: `Copy fields of a configuration to one or more others, to propagate an updated
+relay URL or model list without retyping it. Values are copied as stored
+in the source, with {name} placeholders kept and without inherited or
+default values, and are validated like 'apimgr set' does. A field unset in
+the source is cleared.
+
+Fields:
+  Any field 'apimgr set' accepts except alias, and vars.<name>
+
+Examples:
+  apimgr copy-field relay-a relay-b --fields base_url
+  apimgr copy-field relay-a relay-b relay-c --fields base_url,models`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		srcAlias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		// Stored values, so placeholders are kept and inherited or default
+		// values stay where they come from
+		src, err := configManager.GetStored(srcAlias)
+		if err != nil {
+			return err
+		}
+		updates, err := copyFieldUpdates(src, copyFields)
+		if err != nil {
+			return err
+		}
+
+		var dsts []string
+		for _, arg := range args[1:] {
+			alias, err := configManager.ResolveAlias(arg)
+			if err != nil {
+				return err
+			}
+			if alias == srcAlias {
+				return exitcode.New(exitcode.Usage, "'%s' is the source, give other configurations to copy to", alias)
+			}
+			dsts = append(dsts, alias)
+		}
+
+		for _, dst := range dsts {
+			if err := applyUpdates(configManager, dst, updates); err != nil {
+				return fmt.Errorf("failed to update '%s': %w", dst, err)
+			}
+			for _, name := range copyFields {
+				fmt.Fprintf(stdout, "✓ %s: %s\n", dst, describeFieldUpdate(name, updates[name]))
+			}
+		}
+		if err := configManager.GenerateActiveScript(); err != nil {
+			notice.Printf("Warning: Failed to generate activation script: %v\n", err)
+		}
+		return nil
+	},
+}
+
+// copyFieldUpdates returns the updates setting the named fields to their
+// values in src
+func copyFieldUpdates(src *models.APIConfig, names []string) (map[string]string, error) {
+	updates := make(map[string]string, len(names))
+	for _, name := range names {
+		if name == "alias" {
+			return nil, exitcode.New(exitcode.Usage, "the alias cannot be copied")
+		}
+		if err := config.CheckSettableField(name); err != nil {
+			return nil, err
+		}
+		if _, ok := updates[name]; ok {
+			return nil, exitcode.New(exitcode.Usage, "field '%s' is given more than once", name)
+		}
+		value, _, err := config.FieldValue(src, name)
+		if err != nil {
+			return nil, err
+		}
+		updates[name] = value
+	}
+	return updates, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestCopyFieldUpdates(t *testing.T) {
+	src := &models.APIConfig{
+		Alias:   "relay-a",
+		BaseURL: "https://{region}.relay.example.com",
+		Model:   "claude-sonnet-4",
+		Models:  []string{"claude-sonnet-4", "claude-opus-4"},
+		Vars:    map[string]string{"region": "eu"},
+	}
+	tests := []struct {
+		name     string
+		fields   []string
+		want     map[string]string
+		wantCode int
+	}{
+		{
+			name:   "several fields",
+			fields: []string{"base_url", "models"},
+			want:   map[string]string{"base_url": "https://{region}.relay.example.com", "models": "claude-sonnet-4,claude-opus-4"},
+		},
+		{name: "unset field clears", fields: []string{"ca_bundle"}, want: map[string]string{"ca_bundle": ""}},
+		{name: "placeholder value", fields: []string{"vars.region"}, want: map[string]string{"vars.region": "eu"}},
+		{name: "alias", fields: []string{"alias"}, wantCode: exitcode.Usage},
+		{name: "repeated field", fields: []string{"model", "model"}, wantCode: exitcode.Usage},
+		{name: "read-only field", fields: []string{"provider"}, wantCode: exitcode.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := copyFieldUpdates(src, tt.fields)
+			if code := exitcode.Of(err); code != tt.wantCode {
+				t.Fatalf("copyFieldUpdates(%v) code = %d, want %d (err %v)", tt.fields, code, tt.wantCode, err)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("copyFieldUpdates(%v) = %v, want %v", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyFieldKeepsPlaceholders(t *testing.T) {
+	_, _, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	for _, cfg := range []models.APIConfig{
+		{Alias: "relay-a", APIKey: "sk-a", BaseURL: "https://{region}.relay.example.com", Vars: map[string]string{"region": "eu"}},
+		{Alias: "relay-b", APIKey: "sk-b", BaseURL: "https://old.example.com", Vars: map[string]string{"region": "us"}},
+	} {
+		if err := cm.Add(cfg); err != nil {
+			t.Fatalf("Add(%s) unexpected error: %v", cfg.Alias, err)
+		}
+	}
+
+	runIntegrationCommand(t, "copy-field", "relay-a", "relay-b", "--fields", "base_url")
+	dst, err := cm.Get("relay-b")
+	if err != nil {
+		t.Fatalf("Get(relay-b) unexpected error: %v", err)
+	}
+	if dst.BaseURL != "https://us.relay.example.com" {
+		t.Errorf("relay-b base_url = %q, want the template filled with its own region", dst.BaseURL)
+	}
+}
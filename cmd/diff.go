@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/config"
+	"github.com/spf13/cobra"
+)
+
+var diffAll bool // Show unchanged variables too
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Show unchanged variables too")
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <alias>",
+	Short: "Show what switching to a configuration would change",
+	Long: `Compare a configuration with what is currently in active.env and the Claude
+Code settings files it syncs to, marking the variables a global switch would
+change with *. Keys are masked and nothing is written.
+
+Examples:
+  apimgr diff relay-b          # Variables that would change
+  apimgr diff relay-b --all    # Unchanged variables too`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		diffs, err := configManager.DiffSwitch(alias)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(stdout, formatSwitchDiffs(alias, diffs, diffAll))
+		return nil
+	},
+}
+
+// formatSwitchDiffs formats how a switch would change each file, changed
+// variables marked with *. Unchanged variables are left out unless all is set.
+func formatSwitchDiffs(alias string, diffs []config.SwitchDiff, all bool) string {
+	var b strings.Builder
+	changed := 0
+	for i, d := range diffs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", d.Name, shortenHome(d.Path))
+
+		var rows []config.FieldDiff
+		nameWidth, valueWidth := len("VARIABLE"), len("CURRENT")
+		for _, f := range d.Fields {
+			if !all && !f.Differs() {
+				continue
+			}
+			if f.Differs() {
+				changed++
+			}
+			f.A, f.B = formatField(f.A, f.Secret, false), formatField(f.B, f.Secret, false)
+			rows = append(rows, f)
+			nameWidth = max(nameWidth, len(f.Name))
+			valueWidth = max(valueWidth, len(f.A))
+		}
+
+		switch {
+		case d.Missing && d.Name != config.ActiveEnvFileName:
+			b.WriteString("  Not found, skipped on switch\n")
+			continue
+		case len(rows) == 0:
+			b.WriteString("  No changes\n")
+			continue
+		case d.Missing:
+			b.WriteString("  Not found, created on switch\n")
+		}
+		fmt.Fprintf(&b, "    %-*s  %-*s  %s\n", nameWidth, "VARIABLE", valueWidth, "CURRENT", "ON SWITCH")
+		for _, f := range rows {
+			marker := " "
+			if f.Differs() {
+				marker = "*"
+			}
+			fmt.Fprintf(&b, "  %s %-*s  %-*s  %s\n", marker, nameWidth, f.Name, valueWidth, f.A, f.B)
+		}
+	}
+
+	if changed == 0 {
+		fmt.Fprintf(&b, "\nSwitching to '%s' would change nothing\n", alias)
+	} else {
+		fmt.Fprintf(&b, "\nSwitching to '%s' would change %d value(s), marked *\n", alias, changed)
+	}
+	return b.String()
+}
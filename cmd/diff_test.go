@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"apimgr/config"
+)
+
+func TestFormatSwitchDiffs(t *testing.T) {
+	diffs := []config.SwitchDiff{
+		{Name: "active.env", Path: "/state/active.env", Fields: []config.FieldDiff{
+			{Name: "ANTHROPIC_API_KEY", A: "sk-aaaaaaaaaaaa", B: "sk-bbbbbbbbbbbb", Secret: true},
+			{Name: "ANTHROPIC_BASE_URL", A: "https://relay.example.com", B: "https://relay.example.com"},
+		}},
+		{Name: "claude", Path: "/claude/settings.json", Fields: []config.FieldDiff{
+			{Name: "FOO", A: "1", B: "1"},
+		}},
+		{Name: "work", Path: "/work/settings.json", Missing: true},
+	}
+
+	got := formatSwitchDiffs("b", diffs, false)
+	want := "active.env (/state/active.env)\n" +
+		"    VARIABLE           CURRENT       ON SWITCH\n" +
+		"  * ANTHROPIC_API_KEY  sk-a****aaaa  sk-b****bbbb\n" +
+		"\n" +
+		"claude (/claude/settings.json)\n" +
+		"  No changes\n" +
+		"\n" +
+		"work (/work/settings.json)\n" +
+		"  Not found, skipped on switch\n" +
+		"\n" +
+		"Switching to 'b' would change 1 value(s), marked *\n"
+	if got != want {
+		t.Errorf("formatSwitchDiffs() =\n%s\nwant\n%s", got, want)
+	}
+}
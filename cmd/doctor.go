@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"apimgr/config"
+	"github.com/spf13/cobra"
+)
+
+var doctorFixPerms bool // Tighten loose permissions instead of reporting them
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFixPerms, "fix-perms", false, "Tighten loose file and directory permissions")
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check apimgr files for problems",
+	Long: `Check that the config file, active.env and backups, which hold plaintext
+API keys, are readable only by you (0600) and that their directories are not
+group or world writable. Also shows how the config file is locked: with a
+lock file on network filesystems such as NFS and SMB (or APIMGR_LOCK=lockfile),
+with the OS file lock otherwise, and warns about ANTHROPIC_* variables
+exported in this shell that Claude Code would use over the synced settings.
+
+Examples:
+  apimgr doctor              # Report problems
+  apimgr doctor --fix-perms  # Tighten loose permissions`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		strategy, reason := configManager.LockStrategy()
+		fmt.Fprintf(stdout, "✓ Locking: %s (%s)\n", strategy, reason)
+
+		// Exported variables Claude Code would use over the synced settings
+		if active, err := configManager.GetActive(); err == nil {
+			if overrides := config.EnvOverrides(active, os.LookupEnv); len(overrides) > 0 {
+				writeEnvOverrides(stdout, active.Alias, overrides)
+			} else {
+				fmt.Fprintln(stdout, "✓ Environment: no exported variables override the synced settings")
+			}
+		}
+
+		issues, err := configManager.CheckPermissions()
+		if err != nil {
+			return fmt.Errorf("failed to check permissions: %w", err)
+		}
+		if len(issues) == 0 {
+			fmt.Fprintln(stdout, "✓ Permissions OK")
+			return nil
+		}
+
+		if doctorFixPerms {
+			if err := config.FixPermissions(issues); err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				fmt.Fprintf(stdout, "✓ %s: %04o -> %04o\n", issue.Path, issue.Mode.Perm(), issue.Want.Perm())
+			}
+			return nil
+		}
+
+		fmt.Fprintln(stdout, "Loose permissions:")
+		for _, issue := range issues {
+			fmt.Fprintf(stdout, "  %s\n", issue)
+		}
+		return fmt.Errorf("found %d permission problem(s), run 'apimgr doctor --fix-perms' to fix them", len(issues))
+	},
+}
@@ -2,16 +2,21 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
 	"apimgr/config"
 	"apimgr/config/models"
 	"apimgr/config/validation"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
 	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // EditFlags represents the command line flags for edit command
@@ -34,17 +39,44 @@ func init() {
 	editCmd.Flags().String("url", "", "Change base URL")
 	editCmd.Flags().String("model", "", "Change model name")
 	editCmd.Flags().String("models", "", "Change supported models list (comma-separated)")
+	editCmd.Flags().String("env", "", "Change deployment environment")
+	editCmd.Flags().Bool("insecure-skip-verify", false, "Skip TLS certificate verification in tests (use =false to re-enable)")
+	editCmd.Flags().String("ca-bundle", "", "Change CA bundle path (empty to clear)")
+	editCmd.Flags().String("client-cert", "", "Change mutual TLS client certificate path (empty to clear)")
+	editCmd.Flags().String("client-key", "", "Change mutual TLS client key path (empty to clear)")
+	editCmd.Flags().String("chat-path", "", "Change the chat endpoint path of tests (empty to clear)")
+	editCmd.Flags().String("api-version", "", "Change the anthropic-version header (empty to clear)")
+	editCmd.Flags().String("beta", "", "Change the comma-separated anthropic-beta header values (empty to clear)")
+	editCmd.Flags().String("org-id", "", "Change the organization ID (empty to clear)")
+	editCmd.Flags().String("project-id", "", "Change the project ID (empty to clear)")
+	editCmd.Flags().String("temperature", "", "Change the sampling temperature of test requests (empty to clear)")
+	editCmd.Flags().String("max-tokens", "", "Change the output token limit of test requests and Claude Code (empty to clear)")
+	editCmd.Flags().String("top-p", "", "Change the nucleus sampling of test requests (empty to clear)")
+	editCmd.Flags().String("keys", "", "Change the comma-separated API key pool (empty to clear)")
+	editCmd.Flags().String("key-strategy", "", "Change how switch picks from the key pool: round-robin or lru")
+	editCmd.Flags().String("extends", "", "Change the config unset fields are inherited from (empty to stop inheriting)")
+	editCmd.Flags().String("api-timeout", "", "Change the request timeout of Claude Code, e.g. 10m (empty to clear)")
+	editCmd.Flags().String("max-retries", "", "Change the retries of Claude Code after a failed request (empty to clear)")
+	editCmd.Flags().String("region", "", "Move to another region of the regional endpoint, rewriting the base URL")
 }
 
 var editCmd = &cobra.Command{
-	Use:   "edit <alias>",
-	Short: "Edit configuration",
+	Use:         "edit [alias]",
+	Annotations: mutates,
+	Short:       "Edit configuration",
 	Long: `Edit a saved API configuration
 
 By default, this command will guide you through editing the various fields of the configuration in an interactive interface.
 If command line arguments are provided, the changes will be applied directly without entering interactive mode.
 
+Without an alias the whole config file is opened in $VISUAL or $EDITOR. It is
+saved only when every configuration in it is valid; otherwise the editor can be
+re-opened with your changes.
+
 Examples:
+  # Edit the config file in $EDITOR
+  apimgr edit
+
   # Interactive edit
   apimgr edit myconfig
 
@@ -56,8 +88,14 @@ Examples:
 
   # Edit supported models list
   apimgr edit myconfig --models "claude-3-opus,claude-3-sonnet,claude-3-haiku"`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if editFlagsChanged(cmd) {
+				return exitcode.New(exitcode.Usage, "flags require the alias of the configuration to edit")
+			}
+			return editConfigFile()
+		}
 		alias := args[0]
 
 		// Check if any flags are provided for non-interactive editing
@@ -67,6 +105,7 @@ Examples:
 		urlFlag, _ := cmd.Flags().GetString("url")
 		modelFlag, _ := cmd.Flags().GetString("model")
 		modelsFlag, _ := cmd.Flags().GetString("models")
+		envFlag, _ := cmd.Flags().GetString("env")
 
 		// Parse flags into updates map
 		updates := make(map[string]string)
@@ -88,8 +127,66 @@ Examples:
 		if modelsFlag != "" {
 			updates["models"] = modelsFlag
 		}
+		if envFlag != "" {
+			updates["environment"] = envFlag
+		}
+		if cmd.Flags().Changed("insecure-skip-verify") {
+			insecure, _ := cmd.Flags().GetBool("insecure-skip-verify")
+			updates["insecure_skip_verify"] = strconv.FormatBool(insecure)
+		}
+		if cmd.Flags().Changed("ca-bundle") {
+			updates["ca_bundle"], _ = cmd.Flags().GetString("ca-bundle")
+		}
+		if cmd.Flags().Changed("client-cert") {
+			updates["client_cert"], _ = cmd.Flags().GetString("client-cert")
+		}
+		if cmd.Flags().Changed("client-key") {
+			updates["client_key"], _ = cmd.Flags().GetString("client-key")
+		}
+		if cmd.Flags().Changed("chat-path") {
+			updates["chat_path"], _ = cmd.Flags().GetString("chat-path")
+		}
+		if cmd.Flags().Changed("api-version") {
+			updates["api_version"], _ = cmd.Flags().GetString("api-version")
+		}
+		if cmd.Flags().Changed("beta") {
+			updates["beta_features"], _ = cmd.Flags().GetString("beta")
+		}
+		if cmd.Flags().Changed("org-id") {
+			updates["org_id"], _ = cmd.Flags().GetString("org-id")
+		}
+		if cmd.Flags().Changed("project-id") {
+			updates["project_id"], _ = cmd.Flags().GetString("project-id")
+		}
+		if cmd.Flags().Changed("temperature") {
+			updates["temperature"], _ = cmd.Flags().GetString("temperature")
+		}
+		if cmd.Flags().Changed("max-tokens") {
+			updates["max_tokens"], _ = cmd.Flags().GetString("max-tokens")
+		}
+		if cmd.Flags().Changed("top-p") {
+			updates["top_p"], _ = cmd.Flags().GetString("top-p")
+		}
+		if cmd.Flags().Changed("keys") {
+			updates["api_keys"], _ = cmd.Flags().GetString("keys")
+		}
+		if cmd.Flags().Changed("key-strategy") {
+			updates["key_strategy"], _ = cmd.Flags().GetString("key-strategy")
+		}
+		if cmd.Flags().Changed("extends") {
+			updates["extends"], _ = cmd.Flags().GetString("extends")
+		}
+		if cmd.Flags().Changed("api-timeout") {
+			updates["api_timeout"], _ = cmd.Flags().GetString("api-timeout")
+		}
+		if cmd.Flags().Changed("max-retries") {
+			updates["max_retries"], _ = cmd.Flags().GetString("max-retries")
+		}
+		if cmd.Flags().Changed("region") {
+			updates["region"], _ = cmd.Flags().GetString("region")
+		}
 
-		configManager, err := config.NewConfigManager()
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
@@ -105,7 +202,7 @@ Examples:
 			if newAlias, ok := updates["alias"]; ok {
 				updatedAlias = newAlias
 			}
-			fmt.Printf("✅ Configuration '%s' updated\n", updatedAlias)
+			fmt.Fprintf(stdout, "✅ Configuration '%s' updated\n", updatedAlias)
 		} else {
 			// Interactive mode: guide user through editing
 			if err := editConfig(alias); err != nil {
@@ -116,6 +213,56 @@ Examples:
 	},
 }
 
+// editFlagsChanged reports whether any of edit's own flags was given
+func editFlagsChanged(cmd *cobra.Command) bool {
+	changed := false
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		changed = changed || flag.Changed
+	})
+	return changed
+}
+
+// editConfigFile opens the config file in the user's editor, saving it only
+// when it is valid and offering to re-open it otherwise
+func editConfigFile() error {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	path, err := configManager.EditableCopy()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	reader := bufio.NewReader(stdin)
+	for {
+		editor := utils.EditorCommand(path)
+		editor.Stdin, editor.Stdout, editor.Stderr = stdin, stdout, stderr
+		if err := editor.Run(); err != nil {
+			return fmt.Errorf("failed to run editor: %w", err)
+		}
+
+		err := configManager.ApplyEditedCopy(path)
+		if err == nil {
+			fmt.Fprintf(stdout, "✓ Saved %s\n", configManager.GetConfigPath())
+			return nil
+		}
+		if errors.Is(err, config.ErrConflict) {
+			fmt.Fprintf(stdout, "✗ %v\n", err)
+		} else {
+			fmt.Fprintf(stdout, "✗ Invalid config: %v\n", err)
+		}
+		fmt.Fprint(stdout, "Re-open the editor? (Y/n): ")
+		choice, readErr := reader.ReadString('\n')
+		choice = strings.TrimSpace(choice)
+		if readErr != nil || choice == "n" || choice == "N" {
+			return fmt.Errorf("edit discarded, config file unchanged: %w", err)
+		}
+	}
+}
+
 // FieldType represents the type of field being edited
 type FieldType int
 
@@ -135,7 +282,7 @@ const (
 )
 
 func editConfig(alias string) error {
-	configManager, err := config.NewConfigManager()
+	configManager, err := newConfigManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize config manager: %w", err)
 	}
@@ -161,13 +308,13 @@ func editConfig(alias string) error {
 	}
 
 	updatedAlias := getUpdatedAlias(alias, updates)
-	fmt.Printf("\n✅ Configuration '%s' updated\n", updatedAlias)
+	fmt.Fprintf(stdout, "\n✅ Configuration '%s' updated\n", updatedAlias)
 	return nil
 }
 
 // collectUserEdits handles the interactive editing loop and returns collected updates
 func collectUserEdits(currentConfig *models.APIConfig, configManager *config.Manager) (map[string]string, error) {
-	reader := bufio.NewReader(os.Stdin)
+	reader := bufio.NewReader(stdin)
 	updates := make(map[string]string)
 
 	for {
@@ -176,24 +323,24 @@ func collectUserEdits(currentConfig *models.APIConfig, configManager *config.Man
 
 		// Handle special cases: quit, preview, or save
 		if shouldQuit(choice) {
-			fmt.Println("\nEdit cancelled, no changes saved")
+			fmt.Fprintln(stdout, "\nEdit cancelled, no changes saved")
 			return nil, fmt.Errorf("Operation cancelled")
 		}
 
 		if shouldPreview(choice) {
 			if err := handlePreview(currentConfig, updates); err != nil {
-				fmt.Printf("\n❌ %v\n", err)
+				fmt.Fprintf(stdout, "\n❌ %v\n", err)
 			}
 			continue
 		}
 
 		if shouldSave(choice) {
 			if len(updates) == 0 {
-				fmt.Println("\nNo changes, skipping save")
+				fmt.Fprintln(stdout, "\nNo changes, skipping save")
 				return nil, fmt.Errorf("No changes")
 			}
 			if !confirmSave(reader) {
-				fmt.Println("\nSave cancelled")
+				fmt.Fprintln(stdout, "\nSave cancelled")
 				return nil, fmt.Errorf("Save cancelled")
 			}
 			break
@@ -201,7 +348,7 @@ func collectUserEdits(currentConfig *models.APIConfig, configManager *config.Man
 
 		// Process field selection
 		if err := handleFieldSelection(reader, currentConfig, updates, choice, configManager); err != nil {
-			fmt.Printf("\n❌ %v\n", err)
+			fmt.Fprintf(stdout, "\n❌ %v\n", err)
 		}
 	}
 
@@ -224,33 +371,33 @@ func shouldSave(choice string) bool {
 }
 
 func showMenu(updateCount int) {
-	fmt.Println("\n" + strings.Repeat("-", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("-", 60))
 	if updateCount > 0 {
-		fmt.Printf("%d fields changed\n", updateCount)
-	}
-	fmt.Println("Please select a field to modify (enter number):")
-	fmt.Println("1. Alias (alias)")
-	fmt.Println("2. API key (api_key)")
-	fmt.Println("3. Auth token (auth_token)")
-	fmt.Println("4. Base URL (base_url)")
-	fmt.Println("5. Model name (model)")
-	fmt.Println("6. Supported models (models)")
-	fmt.Println("p. Preview changes")
-	fmt.Println("0. Complete edit and save")
-	fmt.Println("q. Exit without saving")
-	fmt.Println(strings.Repeat("-", 60))
+		fmt.Fprintf(stdout, "%d fields changed\n", updateCount)
+	}
+	fmt.Fprintln(stdout, "Please select a field to modify (enter number):")
+	fmt.Fprintln(stdout, "1. Alias (alias)")
+	fmt.Fprintln(stdout, "2. API key (api_key)")
+	fmt.Fprintln(stdout, "3. Auth token (auth_token)")
+	fmt.Fprintln(stdout, "4. Base URL (base_url)")
+	fmt.Fprintln(stdout, "5. Model name (model)")
+	fmt.Fprintln(stdout, "6. Supported models (models)")
+	fmt.Fprintln(stdout, "p. Preview changes")
+	fmt.Fprintln(stdout, "0. Complete edit and save")
+	fmt.Fprintln(stdout, "q. Exit without saving")
+	fmt.Fprintln(stdout, strings.Repeat("-", 60))
 }
 
 func getUserChoice(reader *bufio.Reader) string {
-	fmt.Print("\nEnter your choice: ")
+	fmt.Fprint(stdout, "\nEnter your choice: ")
 	choice, _ := reader.ReadString('\n')
 	return strings.TrimSpace(choice)
 }
 
 func displayConfig(config models.APIConfig) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Printf("Current configuration: %s\n", config.Alias)
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintf(stdout, "Current configuration: %s\n", config.Alias)
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 
 	// Use helper function to display field
 	displayField("1. Alias", config.Alias, "")
@@ -260,30 +407,30 @@ func displayConfig(config models.APIConfig) {
 	displayField("5. Model name", config.Model, "(not set)")
 	displayModelsField("6. Supported models", config.Models)
 
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 }
 
 func displayModelsField(label string, models []string) {
 	if len(models) > 0 {
-		fmt.Printf("%s: %s\n", label, strings.Join(models, ", "))
+		fmt.Fprintf(stdout, "%s: %s\n", label, strings.Join(models, ", "))
 	} else {
-		fmt.Printf("%s: (not set)\n", label)
+		fmt.Fprintf(stdout, "%s: (not set)\n", label)
 	}
 }
 
 func displayField(label, value, defaultValue string) {
 	if value != "" {
-		fmt.Printf("%s: %s\n", label, value)
+		fmt.Fprintf(stdout, "%s: %s\n", label, value)
 	} else {
-		fmt.Printf("%s: %s\n", label, defaultValue)
+		fmt.Fprintf(stdout, "%s: %s\n", label, defaultValue)
 	}
 }
 
 func displayMaskedField(label, value, maskedValue string) {
 	if value != "" {
-		fmt.Printf("%s: %s\n", label, maskedValue)
+		fmt.Fprintf(stdout, "%s: %s\n", label, maskedValue)
 	} else {
-		fmt.Println(label + ": (not set)")
+		fmt.Fprintln(stdout, label+": (not set)")
 	}
 }
 
@@ -339,14 +486,14 @@ func editField(reader *bufio.Reader, currentConfig *models.APIConfig, updates ma
 	// Get current value (either from updates or currentConfig)
 	currentValue := getCurrentValue(currentConfig, updates, fieldType)
 	prompt := fmt.Sprintf("\nCurrent %s: %s\nEnter new %s (press Enter to keep unchanged): ", fieldName, currentValue, fieldName)
-	fmt.Print(prompt)
+	fmt.Fprint(stdout, prompt)
 
 	newValue, _ := reader.ReadString('\n')
 	newValue = strings.TrimSpace(newValue)
 
 	// No change
 	if newValue == "" {
-		fmt.Println("No change")
+		fmt.Fprintln(stdout, "No change")
 		return nil
 	}
 
@@ -361,9 +508,9 @@ func editField(reader *bufio.Reader, currentConfig *models.APIConfig, updates ma
 
 	// Show success message with masked value if sensitive
 	if isSensitiveField(fieldType) {
-		fmt.Printf("✓ %s will be updated to: %s\n", fieldName, utils.MaskAPIKey(newValue))
+		fmt.Fprintf(stdout, "✓ %s will be updated to: %s\n", fieldName, utils.MaskAPIKey(newValue))
 	} else {
-		fmt.Printf("✓ %s will be updated to: %s\n", fieldName, newValue)
+		fmt.Fprintf(stdout, "✓ %s will be updated to: %s\n", fieldName, newValue)
 	}
 
 	return nil
@@ -458,39 +605,39 @@ func getOtherAuthValue(fieldType FieldType, config *models.APIConfig, newValue s
 }
 
 func previewChanges(currentConfig models.APIConfig, updates map[string]string) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("Preview changes:")
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "Preview changes:")
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 
 	// Show each changed field
 	if newAlias, ok := updates["alias"]; ok {
-		fmt.Printf("Alias: %s → %s\n", currentConfig.Alias, newAlias)
+		fmt.Fprintf(stdout, "Alias: %s → %s\n", currentConfig.Alias, newAlias)
 	}
 	if newAPIKey, ok := updates["api_key"]; ok {
-		fmt.Printf("API Key: %s → %s\n", utils.MaskAPIKey(currentConfig.APIKey), utils.MaskAPIKey(newAPIKey))
+		fmt.Fprintf(stdout, "API Key: %s → %s\n", utils.MaskAPIKey(currentConfig.APIKey), utils.MaskAPIKey(newAPIKey))
 	}
 	if newAuthToken, ok := updates["auth_token"]; ok {
-		fmt.Printf("Authentication Token: %s → %s\n", utils.MaskAPIKey(currentConfig.AuthToken), utils.MaskAPIKey(newAuthToken))
+		fmt.Fprintf(stdout, "Authentication Token: %s → %s\n", utils.MaskAPIKey(currentConfig.AuthToken), utils.MaskAPIKey(newAuthToken))
 	}
 	if newBaseURL, ok := updates["base_url"]; ok {
-		fmt.Printf("Base URL: %s → %s\n", currentConfig.BaseURL, newBaseURL)
+		fmt.Fprintf(stdout, "Base URL: %s → %s\n", currentConfig.BaseURL, newBaseURL)
 	}
 	if newModel, ok := updates["model"]; ok {
-		fmt.Printf("Model Name: %s → %s\n", currentConfig.Model, newModel)
+		fmt.Fprintf(stdout, "Model Name: %s → %s\n", currentConfig.Model, newModel)
 	}
 	if newModels, ok := updates["models"]; ok {
 		currentModelsStr := strings.Join(currentConfig.Models, ", ")
 		if currentModelsStr == "" {
 			currentModelsStr = "(not set)"
 		}
-		fmt.Printf("Supported Models: %s → %s\n", currentModelsStr, newModels)
+		fmt.Fprintf(stdout, "Supported Models: %s → %s\n", currentModelsStr, newModels)
 	}
 
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 }
 
 func confirmSave(reader *bufio.Reader) bool {
-	fmt.Print("\nConfirm saving changes? (y/N): ")
+	fmt.Fprint(stdout, "\nConfirm saving changes? (y/N): ")
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(choice)
 	return choice == "y" || choice == "Y"
@@ -507,45 +654,22 @@ func getUpdatedAlias(originalAlias string, updates map[string]string) string {
 func saveAndApplyChanges(configManager *config.Manager, alias string, updates map[string]string) error {
 	// Apply field updates
 	if err := applyUpdates(configManager, alias, updates); err != nil {
-		return fmt.Errorf("Save failed: %v", err)
+		return fmt.Errorf("Save failed: %w", err)
 	}
 
 	// Generate active.env script
 	if err := configManager.GenerateActiveScript(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to generate activation script: %v\n", err)
+		notice.Printf("Warning: Failed to generate activation script: %v\n", err)
 	}
 
 	return nil
 }
 
+// applyUpdates applies the field updates of a configuration in a single
+// save, so a failure leaves it unchanged
 func applyUpdates(configManager *config.Manager, alias string, updates map[string]string) error {
-	// Handle alias update separately
-	if newAlias, ok := updates["alias"]; ok {
-		if err := configManager.RenameAlias(alias, newAlias); err != nil {
-			return fmt.Errorf("Failed to rename alias: %v", err)
-		}
-		alias = newAlias // Update alias for subsequent updates
-	}
-
-	// Remove alias from updates
-	delete(updates, "alias")
-
-	// Handle models update separately using SetModels method
-	// This handles active model preservation/fallback (Requirements: 4.2, 4.3)
-	if modelsStr, ok := updates["models"]; ok {
-		models := parseModelsList(modelsStr)
-		if err := configManager.SetModels(alias, models); err != nil {
-			return fmt.Errorf("Failed to update models: %v", err)
-		}
-		delete(updates, "models")
-	}
-
-	// Apply other field updates
-	if len(updates) > 0 {
-		if err := configManager.UpdatePartial(alias, updates); err != nil {
-			return err
-		}
+	if len(updates) == 0 {
+		return nil
 	}
-
-	return nil
+	return configManager.UpdatePartial(alias, updates)
 }
@@ -6,7 +6,7 @@ import (
 
 func TestEditCmd(t *testing.T) {
 	t.Run("Command definition", func(t *testing.T) {
-		expected := "edit <alias>"
+		expected := "edit [alias]"
 		if editCmd.Use != expected {
 			t.Errorf("editCmd.Use = %q, want %q", editCmd.Use, expected)
 		}
@@ -41,11 +41,11 @@ func TestEditCmd(t *testing.T) {
 		}
 	})
 
-	t.Run("Args requires exactly 1 argument", func(t *testing.T) {
-		// Test with no arguments
+	t.Run("Args accepts at most 1 argument", func(t *testing.T) {
+		// Test with no arguments, which opens the config file in an editor
 		err := editCmd.Args(editCmd, []string{})
-		if err == nil {
-			t.Error("Args should return error when no arguments provided")
+		if err != nil {
+			t.Errorf("Args should not return error without arguments, got: %v", err)
 		}
 
 		// Test with exactly 1 argument
@@ -7,12 +7,14 @@ import (
 	"strings"
 
 	"apimgr/config"
+	"apimgr/config/storage"
 	"github.com/spf13/cobra"
 )
 
 var enableCmd = &cobra.Command{
-	Use:   "enable",
-	Short: "Enable automatic configuration application",
+	Use:         "enable",
+	Annotations: mutates,
+	Short:       "Enable automatic configuration application",
 	Long: `Enable automatic configuration application by setting up:
 - XDG-compliant directory structure
 - Configuration file migration
@@ -33,11 +35,10 @@ func runEnable(cmd *cobra.Command, args []string) error {
 
 	configDir := filepath.Join(homeDir, ".config", "apimgr")
 	oldConfigPath := filepath.Join(homeDir, ".apimgr.json")
-	newConfigPath := filepath.Join(configDir, "config.json")
-	activeEnvPath := filepath.Join(configDir, "active.env")
+	newConfigPath := storage.FindConfigFile(configDir)
 
 	// Step 1: Create XDG directory structure
-	fmt.Println("📁 Creating XDG-compliant directory structure...")
+	fmt.Fprintln(stdout, "📁 Creating XDG-compliant directory structure...")
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
@@ -45,7 +46,7 @@ func runEnable(cmd *cobra.Command, args []string) error {
 	// Step 2: Migrate configuration if needed
 	if _, err := os.Stat(oldConfigPath); err == nil {
 		if _, err := os.Stat(newConfigPath); os.IsNotExist(err) {
-			fmt.Printf("📦 Migrating configuration from %s to %s...\n", oldConfigPath, newConfigPath)
+			fmt.Fprintf(stdout, "📦 Migrating configuration from %s to %s...\n", oldConfigPath, newConfigPath)
 
 			data, err := os.ReadFile(oldConfigPath)
 			if err != nil {
@@ -56,10 +57,10 @@ func runEnable(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("failed to write new config: %w", err)
 			}
 
-			fmt.Println("✅ Configuration migrated successfully")
-			fmt.Printf("   You can safely remove the old config file: rm %s\n", oldConfigPath)
+			fmt.Fprintln(stdout, "✅ Configuration migrated successfully")
+			fmt.Fprintf(stdout, "   You can safely remove the old config file: rm %s\n", oldConfigPath)
 		} else {
-			fmt.Println("ℹ️  Configuration already exists at new location")
+			fmt.Fprintln(stdout, "ℹ️  Configuration already exists at new location")
 		}
 	} else {
 		// Create empty config if neither exists
@@ -68,25 +69,27 @@ func runEnable(cmd *cobra.Command, args []string) error {
 			if err := os.WriteFile(newConfigPath, []byte(defaultConfig), 0600); err != nil {
 				return fmt.Errorf("failed to create config file: %w", err)
 			}
-			fmt.Println("✅ Created new configuration file")
+			fmt.Fprintln(stdout, "✅ Created new configuration file")
 		}
 	}
 
 	// Step 3: Create initial active.env if config exists
-	fmt.Println("🔧 Setting up configuration...")
+	fmt.Fprintln(stdout, "🔧 Setting up configuration...")
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	// active.env lives in the state directory, apart from the config file
+	activeEnvPath := filepath.Join(configManager.StateDir(), config.ActiveEnvFileName)
 	if _, err := os.Stat(newConfigPath); err == nil {
 		// Load config and generate active.env
-		configManager, err := config.NewConfigManager()
-		if err != nil {
-			return fmt.Errorf("failed to initialize config manager: %w", err)
-		}
 		if err := configManager.GenerateActiveScript(); err == nil {
-			fmt.Printf("✅ Configuration ready at %s\n", newConfigPath)
+			fmt.Fprintf(stdout, "✅ Configuration ready at %s\n", newConfigPath)
 		}
 	}
 
 	// Step 4: Check shell configuration
-	fmt.Println("\n📝 Checking shell configuration...")
+	fmt.Fprintln(stdout, "\n📝 Checking shell configuration...")
 	shellRcFiles := []string{
 		filepath.Join(homeDir, ".zshrc"),
 		filepath.Join(homeDir, ".bashrc"),
@@ -98,36 +101,39 @@ func runEnable(cmd *cobra.Command, args []string) error {
 
 	for _, rcFile := range shellRcFiles {
 		if data, err := os.ReadFile(rcFile); err == nil {
-			if strings.Contains(string(data), "apimgr/active.env") {
-				fmt.Printf("✅ Shell integration already configured in %s\n", rcFile)
+			if strings.Contains(string(data), activeEnvPath) || strings.Contains(string(data), "apimgr load-active") {
+				fmt.Fprintf(stdout, "✅ Shell integration already configured in %s\n", rcFile)
 				shellConfigured = true
 				break
 			}
+			if strings.Contains(string(data), "apimgr/active.env") {
+				fmt.Fprintf(stdout, "⚠️  %s sources active.env from its old location, it now lives in %s\n", rcFile, configManager.StateDir())
+			}
 		}
 	}
 
 	if !shellConfigured {
-		fmt.Println("\n⚠️  Shell integration not configured. Add this line to your shell config:")
-		fmt.Printf("\n    %s\n\n", integrationLine)
+		fmt.Fprintln(stdout, "\n⚠️  Shell integration not configured. Add this line to your shell config:")
+		fmt.Fprintf(stdout, "\n    %s\n\n", integrationLine)
 
 		// Detect current shell
 		shell := os.Getenv("SHELL")
 		if strings.Contains(shell, "zsh") {
-			fmt.Println("For Zsh, add to ~/.zshrc:")
-			fmt.Printf("    echo '%s' >> ~/.zshrc\n", integrationLine)
+			fmt.Fprintln(stdout, "For Zsh, add to ~/.zshrc:")
+			fmt.Fprintf(stdout, "    echo '%s' >> ~/.zshrc\n", integrationLine)
 		} else if strings.Contains(shell, "bash") {
-			fmt.Println("For Bash, add to ~/.bashrc:")
-			fmt.Printf("    echo '%s' >> ~/.bashrc\n", integrationLine)
+			fmt.Fprintln(stdout, "For Bash, add to ~/.bashrc:")
+			fmt.Fprintf(stdout, "    echo '%s' >> ~/.bashrc\n", integrationLine)
 		}
 	}
 
 	// Step 5: Instructions
-	fmt.Println("\n✨ Setup complete! Next steps:")
-	fmt.Println("1. If not done already, add the shell integration line to your shell config")
-	fmt.Println("2. Restart your terminal or run: source ~/.zshrc (or ~/.bashrc)")
-	fmt.Println("3. Use 'apimgr add' to add API configurations")
-	fmt.Println("4. Use 'apimgr switch' to switch between configurations")
-	fmt.Println("5. Configuration changes automatically apply to new terminal sessions")
-	fmt.Println("\nTo verify the setup, run: apimgr status")
+	fmt.Fprintln(stdout, "\n✨ Setup complete! Next steps:")
+	fmt.Fprintln(stdout, "1. If not done already, add the shell integration line to your shell config")
+	fmt.Fprintln(stdout, "2. Restart your terminal or run: source ~/.zshrc (or ~/.bashrc)")
+	fmt.Fprintln(stdout, "3. Use 'apimgr add' to add API configurations")
+	fmt.Fprintln(stdout, "4. Use 'apimgr switch' to switch between configurations")
+	fmt.Fprintln(stdout, "5. Configuration changes automatically apply to new terminal sessions")
+	fmt.Fprintln(stdout, "\nTo verify the setup, run: apimgr status")
 	return nil
 }
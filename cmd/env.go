@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"apimgr/config"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/notice"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var envCI string // CI system whose syntax is emitted
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.Flags().StringVar(&envCI, "ci", "", "Emit masking commands and exports for a CI: github, gitlab")
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env [alias]",
+	Short: "Print export lines for a configuration without switching",
+	Long: `Print the unset and export lines for a configuration, the active one when
+no alias is given, like 'switch -l' does. Nothing is changed: the config file,
+active.env, Claude Code settings and session markers are left alone.
+
+With --ci, print the variables for a CI pipeline after the commands that mask
+its secrets in job logs:
+
+  github  ::add-mask:: commands, then NAME=value lines appended to
+          $GITHUB_ENV so later steps see the variables (printed when
+          $GITHUB_ENV is not set)
+  gitlab  export lines to eval in the job script. GitLab has no runtime
+          masking command, mask the key in the project's CI/CD variables
+
+Examples:
+  eval "$(apimgr env relay)"                # Load 'relay' into this shell only
+  apimgr env relay > relay.sh               # Save it for scripts
+  apimgr env relay --ci github              # In a GitHub Actions step
+  eval "$(apimgr env relay --ci gitlab)"    # In a GitLab job script`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		alias, err := aliasOrActive(configManager, args)
+		if err != nil {
+			return err
+		}
+		cfg, err := configManager.Get(alias)
+		if err != nil {
+			return err
+		}
+		if err := utils.CheckPlaceholders(cfg.BaseURL); err != nil {
+			return err
+		}
+
+		if envCI == "" {
+			fmt.Fprint(stdout, syncpkg.GenerateEnvCommands(cfg))
+			return nil
+		}
+
+		masks, err := syncpkg.GenerateCIMasks(cfg, envCI)
+		if err != nil {
+			return err
+		}
+		exports, err := syncpkg.GenerateCIExports(cfg, envCI)
+		if err != nil {
+			return err
+		}
+
+		// Masks go first so the runner hides the values before they appear
+		fmt.Fprint(stdout, masks)
+		if githubEnv := os.Getenv("GITHUB_ENV"); envCI == syncpkg.CIGitHub && githubEnv != "" {
+			return appendGitHubEnv(githubEnv, exports)
+		}
+		fmt.Fprint(stdout, exports)
+		return nil
+	},
+}
+
+// aliasOrActive resolves the alias argument, or returns the active alias
+// when none was given
+func aliasOrActive(configManager *config.Manager, args []string) (string, error) {
+	if len(args) == 1 {
+		return configManager.ResolveAlias(args[0])
+	}
+	alias, err := configManager.GetActiveName(config.ScopeShell)
+	if err != nil || alias == "" {
+		return "", fmt.Errorf("no active configuration, specify an alias")
+	}
+	return alias, nil
+}
+
+// appendGitHubEnv appends NAME=value lines to the $GITHUB_ENV file
+func appendGitHubEnv(path, exports string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open $GITHUB_ENV: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(exports); err != nil {
+		return fmt.Errorf("failed to write $GITHUB_ENV: %w", err)
+	}
+	notice.Println("✓ Exported variables to $GITHUB_ENV")
+	return nil
+}
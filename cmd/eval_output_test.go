@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+)
+
+// shellLine matches the lines eval-able commands may print to stdout
+var shellLine = regexp.MustCompile(`^(unset \w+|export \w+=.*|trap '.*' EXIT|__apimgr_\w+=.*)$`)
+
+// runEvalCommand runs apimgr with args against a config in a temporary
+// home and returns what it printed to stdout
+func runEvalCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv(config.ConfigEnvVar, createTestConfig(t, home, []models.APIConfig{
+		{Alias: "relay", APIKey: "sk-relay-key", BaseURL: "https://relay.example.com", Model: "opus", Models: []string{"opus", "sonnet"}},
+	}, "relay"))
+
+	// An unreadable settings file makes the sync print a warning
+	claudeDir := filepath.Join(home, ".claude")
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, "settings.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := Run(args, IO{Out: &out}, nil)
+	return out.String(), err
+}
+
+func TestEvalCommandsStdoutIsShellCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		want    string // A line stdout must contain
+	}{
+		{"switch local", []string{"switch", "-l", "relay", "--no-prompt"}, false, "trap '"},
+		{"switch global", []string{"switch", "relay", "--no-prompt"}, false, "export ANTHROPIC_API_KEY="},
+		{"switch model", []string{"switch", "-l", "relay", "-m", "sonnet"}, false, "export ANTHROPIC_MODEL="},
+		{"switch missing alias", []string{"switch", "-l", "nope", "--no-prompt"}, true, ""},
+		{"load-active", []string{"load-active"}, false, "__apimgr_changed="},
+		{"load-active refresh", []string{"load-active", "--refresh"}, false, "export ANTHROPIC_API_KEY="},
+		{"env", []string{"env", "relay"}, false, `export ANTHROPIC_BASE_URL='https://relay.example.com'`},
+		{"env active", []string{"env"}, false, `export ANTHROPIC_MODEL='opus'`},
+		{"env missing alias", []string{"env", "nope"}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := runEvalCommand(t, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && stdout != "" {
+				t.Errorf("stdout = %q, want nothing on error", stdout)
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(stdout, "\n"), "\n") {
+				if line != "" && !shellLine.MatchString(line) {
+					t.Errorf("stdout line %q is not shell code", line)
+				}
+			}
+			if tt.want != "" && !strings.Contains(stdout, tt.want) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvChangesNothing(t *testing.T) {
+	tempDir, configPath, claudeSettingsPath, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "work", APIKey: "sk-work"},
+		{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"},
+	}, "work")
+	createClaudeSettings(t, claudeSettingsPath, map[string]string{"ANTHROPIC_API_KEY": "sk-work"})
+
+	snapshot := func() map[string]string {
+		files := map[string]string{}
+		filepath.WalkDir(tempDir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				data, _ := os.ReadFile(path)
+				files[path] = string(data)
+			}
+			return nil
+		})
+		return files
+	}
+	before := snapshot()
+
+	out := runIntegrationCommand(t, "env", "relay")
+	if !strings.Contains(out, `export ANTHROPIC_API_KEY='sk-relay'`) || !strings.Contains(out, "unset ANTHROPIC_AUTH_TOKEN") {
+		t.Errorf("env relay = %q, want the exports of relay", out)
+	}
+	if after := snapshot(); !reflect.DeepEqual(after, before) {
+		t.Errorf("files after env = %v, want them unchanged from %v", after, before)
+	}
+}
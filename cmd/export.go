@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/notice"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string // Output format: docker-env or k8s-secret
+	exportName   string // Kubernetes Secret name
+	exportOutput string // File to write instead of stdout
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "docker-env", "Output format: docker-env, k8s-secret")
+	exportCmd.Flags().StringVar(&exportName, "name", "", "Secret name for k8s-secret (default: apimgr-<alias>)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to a file (mode 0600) instead of stdout")
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [alias]",
+	Short: "Export a configuration for containers",
+	Long: `Export a configuration's environment variables so the same credentials can
+be injected into containers. Without an alias the active configuration is
+exported. The output contains the plaintext key.
+
+Formats:
+  docker-env  KEY=value lines for docker run --env-file
+  k8s-secret  Opaque Secret manifest with base64 values, for envFrom
+
+Examples:
+  apimgr export my-relay -o relay.env && docker run --env-file relay.env image
+  apimgr export my-relay --format k8s-secret | kubectl apply -f -
+  apimgr export --format k8s-secret --name claude-creds`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		alias, err := aliasOrActive(configManager, args)
+		if err != nil {
+			return err
+		}
+		cfg, err := configManager.Get(alias)
+		if err != nil {
+			return err
+		}
+		if err := utils.CheckPlaceholders(cfg.BaseURL); err != nil {
+			return err
+		}
+
+		var content string
+		switch exportFormat {
+		case "docker-env":
+			content, err = syncpkg.GenerateDockerEnv(cfg)
+		case "k8s-secret":
+			content, err = syncpkg.GenerateK8sSecret(cfg, exportName)
+		default:
+			return fmt.Errorf("unsupported format '%s' (supported: docker-env, k8s-secret)", exportFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		if exportOutput == "" {
+			fmt.Fprint(stdout, content)
+			return nil
+		}
+		if err := os.WriteFile(exportOutput, []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+		}
+		notice.Printf("✓ Exported %s to %s\n", alias, exportOutput)
+		return nil
+	},
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"text/template"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/utils"
+)
+
+// Values of the --format flag
+const (
+	formatText     = "text"
+	formatCSV      = "csv"
+	formatTSV      = "tsv"
+	formatTemplate = "template" // Followed by =<Go template>
+)
+
+// formatFlagUsage describes the --format flag
+const formatFlagUsage = "Output format: text, csv, tsv or template=<Go template>"
+
+// formatDelimiter returns the field delimiter of a --format value, 0 for text
+// and templates
+func formatDelimiter(format string) (rune, error) {
+	switch format {
+	case formatText:
+		return 0, nil
+	case formatCSV:
+		return ',', nil
+	case formatTSV:
+		return '\t', nil
+	}
+	if strings.HasPrefix(format, formatTemplate+"=") {
+		return 0, nil
+	}
+	return 0, exitcode.New(exitcode.Usage, "unknown format '%s' (available: %s, %s, %s, %s=<Go template>)", format, formatText, formatCSV, formatTSV, formatTemplate)
+}
+
+// templateFuncs are the functions available to --format templates
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseFormatTemplate parses the template of a --format template=... value,
+// nil for the other formats
+func parseFormatTemplate(format string) (*template.Template, error) {
+	text, ok := strings.CutPrefix(format, formatTemplate+"=")
+	if !ok {
+		return nil, nil
+	}
+	tmpl, err := template.New(formatTemplate).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Usage, "invalid --format template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate executes a --format template on data, followed by a newline
+func writeTemplate(w io.Writer, tmpl *template.Template, data any) error {
+	if err := tmpl.Execute(w, data); err != nil {
+		return exitcode.New(exitcode.Usage, "failed to execute --format template: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// templateConfig returns a copy of cfg with its keys masked, what --format
+// templates see of a configuration
+func templateConfig(cfg models.APIConfig) models.APIConfig {
+	cfg = cfg.Clone()
+	cfg.APIKey = delimitedField(cfg.APIKey, true)
+	cfg.AuthToken = delimitedField(cfg.AuthToken, true)
+	for i, key := range cfg.APIKeys {
+		cfg.APIKeys[i] = delimitedField(key, true)
+	}
+	return cfg
+}
+
+// writeDelimited writes a header row and records as CSV or TSV, quoting
+// fields that contain the delimiter, quotes or line breaks
+func writeDelimited(w io.Writer, comma rune, header []string, records [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	return cw.WriteAll(records)
+}
+
+// delimitedField formats a field value for CSV or TSV output: empty when
+// unset, masked when secret
+func delimitedField(value string, secret bool) string {
+	if secret && value != "" {
+		return utils.MaskAPIKey(value)
+	}
+	return value
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestFormatDelimiter(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    rune
+		wantErr bool
+	}{
+		{"text", 0, false},
+		{"csv", ',', false},
+		{"tsv", '\t', false},
+		{"template={{.Alias}}", 0, false},
+		{"json", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := formatDelimiter(tt.format)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("formatDelimiter(%q) = %q, %v, want %q, error %v", tt.format, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteDelimited(t *testing.T) {
+	header := []string{"alias", "models"}
+	records := [][]string{
+		{"plain", "m1"},
+		{"list", "m1,m2"},
+		{"quoted", `say "hi"`},
+		{"tab", "a\tb"},
+	}
+	tests := []struct {
+		name  string
+		comma rune
+		want  string
+	}{
+		{"csv", ',', "alias,models\nplain,m1\nlist,\"m1,m2\"\nquoted,\"say \"\"hi\"\"\"\ntab,a\tb\n"},
+		{"tsv", '\t', "alias\tmodels\nplain\tm1\nlist\tm1,m2\nquoted\t\"say \"\"hi\"\"\"\ntab\t\"a\tb\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeDelimited(&b, tt.comma, header, records); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeDelimited() =\n%q\nwant\n%q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDelimitedField(t *testing.T) {
+	if got := delimitedField("", true); got != "" {
+		t.Errorf("delimitedField() of an unset secret = %q, want empty", got)
+	}
+	if got := delimitedField("sk-ant-api03-abcdefgh", true); got != "sk-a****efgh" {
+		t.Errorf("delimitedField() of a secret = %q, want it masked", got)
+	}
+	if got := delimitedField("https://api.example.com", false); got != "https://api.example.com" {
+		t.Errorf("delimitedField() = %q, want the value", got)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	cfg := models.APIConfig{Alias: "work", APIKey: "sk-ant-api03-abcdefgh", Model: "m1", Models: []string{"m1", "m2"}}
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{name: "fields", format: "template={{.Alias}} {{.Model}}", want: "work m1\n"},
+		{name: "join", format: `template={{join .Models ","}}`, want: "m1,m2\n"},
+		{name: "masked key", format: "template={{.APIKey}}", want: "sk-a****efgh\n"},
+		{name: "unknown field", format: "template={{.Nope}}", wantErr: "failed to execute"},
+		{name: "invalid template", format: "template={{.Alias", wantErr: "invalid --format template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseFormatTemplate(tt.format)
+			var out bytes.Buffer
+			if err == nil {
+				err = writeTemplate(&out, tmpl, templateConfig(cfg))
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || out.String() != tt.want {
+				t.Errorf("output = %q, %v, want %q", out.String(), err, tt.want)
+			}
+		})
+	}
+
+	if tmpl, err := parseFormatTemplate("csv"); tmpl != nil || err != nil {
+		t.Errorf("parseFormatTemplate(csv) = %v, %v, want nil", tmpl, err)
+	}
+	if cfg.APIKey != "sk-ant-api03-abcdefgh" {
+		t.Errorf("templateConfig() changed the config's key to %q", cfg.APIKey)
+	}
+}
+
+func TestListFormatTemplate(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "work", APIKey: "sk-work", Model: "m1"},
+		{Alias: "home", APIKey: "sk-home", Model: "m2"},
+	}, "work")
+
+	var out, errOut bytes.Buffer
+	if err := Run([]string{"list", "--format", "template={{.Alias}}={{.Model}}"}, IO{Out: &out, Err: &errOut}, nil); err != nil {
+		t.Fatalf("list unexpected error: %v", err)
+	}
+	if out.String() != "work=m1\nhome=m2\n" {
+		t.Errorf("list output = %q, want one line per config", out.String())
+	}
+
+	out.Reset()
+	if err := Run([]string{"status", "--format", "template={{.Alias}} {{.Source}}"}, IO{Out: &out, Err: &errOut}, nil); err != nil {
+		t.Fatalf("status unexpected error: %v", err)
+	}
+	if out.String() != "work config file\n" {
+		t.Errorf("status output = %q, want the config in use", out.String())
+	}
+}
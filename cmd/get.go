@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getField string
+	getRaw   bool
+)
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+	getCmd.Flags().StringVar(&getField, "field", "", "Print only this field's value")
+	getCmd.Flags().BoolVar(&getRaw, "raw", false, "Print keys unmasked and empty values as empty lines")
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get <alias>",
+	Short: "Show fields of a configuration",
+	Long: `Show the fields of a configuration, with inherited values filled in, for
+scripts. Keys are masked unless --raw is given.
+
+Fields:
+  ` + strings.Join(config.FieldNames(), ", ") + `
+
+Examples:
+  apimgr get relay                             # Every field
+  apimgr get relay --field base_url            # Just the base URL
+  export KEY="$(apimgr get relay --field api_key --raw)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := configManager.Get(alias)
+		if err != nil {
+			return err
+		}
+
+		if getField != "" {
+			value, secret, err := config.FieldValue(cfg, getField)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, formatField(value, secret, getRaw))
+			return nil
+		}
+
+		for _, name := range config.FieldNames() {
+			value, secret, _ := config.FieldValue(cfg, name)
+			fmt.Fprintf(stdout, "%-22s %s\n", name, formatField(value, secret, getRaw))
+		}
+		return nil
+	},
+}
+
+// formatField formats a field value for get, masking secrets and marking
+// empty values unless raw
+func formatField(value string, secret, raw bool) string {
+	switch {
+	case raw:
+		return value
+	case value == "":
+		return "(unset)"
+	case secret:
+		return utils.MaskAPIKeys(value)
+	}
+	return value
+}
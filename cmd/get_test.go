@@ -0,0 +1,23 @@
+package cmd
+
+import "testing"
+
+func TestFormatField(t *testing.T) {
+	tests := []struct {
+		value  string
+		secret bool
+		raw    bool
+		want   string
+	}{
+		{"https://x.example.com", false, false, "https://x.example.com"},
+		{"", false, false, "(unset)"},
+		{"", false, true, ""},
+		{"sk-1234567890abcdef", true, false, "sk-1****cdef"},
+		{"sk-1234567890abcdef", true, true, "sk-1234567890abcdef"},
+	}
+	for _, tt := range tests {
+		if got := formatField(tt.value, tt.secret, tt.raw); got != tt.want {
+			t.Errorf("formatField(%q, %v, %v) = %q, want %q", tt.value, tt.secret, tt.raw, got, tt.want)
+		}
+	}
+}
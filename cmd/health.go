@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/keypool"
+	"apimgr/config/models"
+	"apimgr/internal/notify"
+	"apimgr/internal/probe"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthShowHistory bool          // List every recorded result
+	healthLimit       int           // Maximum number of results listed with --history
+	healthWatch       bool          // Check the endpoint periodically
+	healthInterval    time.Duration // Delay between checks in watch mode
+	healthNotify      bool          // Send desktop notifications in watch mode
+)
+
+func init() {
+	rootCmd.AddCommand(healthCmd)
+	healthCmd.Flags().BoolVar(&healthShowHistory, "history", false, "List recorded ping and test results")
+	healthCmd.Flags().IntVarP(&healthLimit, "limit", "n", 20, "Maximum number of results listed with --history (0 for all)")
+	healthCmd.Flags().BoolVarP(&healthWatch, "watch", "w", false, "Check the endpoint periodically and notify when it starts failing")
+	healthCmd.Flags().DurationVar(&healthInterval, "interval", time.Minute, "Delay between checks in watch mode")
+	healthCmd.Flags().BoolVar(&healthNotify, "notify", true, "Send desktop notifications in watch mode")
+}
+
+var healthCmd = &cobra.Command{
+	Use:   "health [alias]",
+	Short: "Show recorded health of a configuration",
+	Long: `Show the latency trend and success rate recorded by ping and compatibility
+tests (apimgr ping, apimgr ping -T and the TUI). Defaults to the active configuration.
+
+Examples:
+  apimgr health                 # Summary for the active configuration
+  apimgr health work --history  # List recorded results for 'work'
+  apimgr health --watch         # Check the active configuration every minute`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		if healthWatch {
+			pinned := ""
+			if len(args) == 1 {
+				if pinned, err = configManager.ResolveAlias(args[0]); err != nil {
+					return err
+				}
+			}
+			return runHealthWatch(configManager, pinned)
+		}
+
+		var alias string
+		if len(args) == 1 {
+			alias, err = configManager.ResolveAlias(args[0])
+			if err != nil {
+				return err
+			}
+		} else {
+			cfg, err := configManager.GetActive()
+			if err != nil {
+				return err
+			}
+			alias = cfg.Alias
+		}
+
+		entries, err := history.Load(configManager.StateDir(), alias)
+		if err != nil {
+			return err
+		}
+		keys, keyHealth, err := configManager.KeyHealth(alias)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Fprintf(stdout, "No health history for '%s'. Run 'apimgr ping %s' to record one.\n", alias, alias)
+			printKeyHealth(keys, keyHealth)
+			return nil
+		}
+
+		if healthShowHistory {
+			listed := entries
+			if healthLimit > 0 {
+				listed = history.Last(entries, healthLimit)
+			}
+			fmt.Fprintf(stdout, "Health history for '%s' (oldest first):\n", alias)
+			for _, e := range listed {
+				fmt.Fprintln(stdout, formatHealthEntry(e))
+			}
+			return nil
+		}
+
+		fmt.Fprintf(stdout, "Health of '%s':\n", alias)
+		fmt.Fprintf(stdout, "  Last result: %s\n", formatHealthEntry(entries[len(entries)-1]))
+		fmt.Fprintf(stdout, "  Trend:       %s\n", history.Sparkline(entries))
+		fmt.Fprintf(stdout, "  Success:     %.0f%% of %d recorded results\n", history.SuccessRate(entries)*100, len(entries))
+		printKeyHealth(keys, keyHealth)
+		return nil
+	},
+}
+
+// printKeyHealth lists the keys of a key pool, masked, with their health
+func printKeyHealth(keys []string, health []keypool.Health) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Fprintln(stdout, "  Keys:")
+	for i, key := range keys {
+		fmt.Fprintf(stdout, "    %s  %s\n", utils.MaskAPIKey(key), formatKeyHealth(health[i], time.Now()))
+	}
+}
+
+// formatKeyHealth describes the health of one key of a pool
+func formatKeyHealth(h keypool.Health, now time.Time) string {
+	var state string
+	switch {
+	case h.Disabled:
+		state = fmt.Sprintf("❌ rejected (HTTP %d)", h.LastStatus)
+	case !h.Healthy(now):
+		state = "⏳ rate limited until " + h.CooldownUntil.Local().Format("15:04:05")
+	case h.LastUsedAt.IsZero():
+		return "– never used"
+	default:
+		state = "✅ healthy"
+	}
+	if h.Failures > 0 {
+		state += fmt.Sprintf(", failed %d times in a row", h.Failures)
+	}
+	if !h.LastUsedAt.IsZero() {
+		state += ", last used " + h.LastUsedAt.Local().Format("2006-01-02 15:04:05")
+	}
+	return state
+}
+
+// formatHealthEntry formats one recorded result on a single line
+func formatHealthEntry(e history.Entry) string {
+	status := "✅"
+	if !e.Success {
+		status = "❌"
+	}
+	line := fmt.Sprintf("%s %s %-4s %5dms", e.Time.Local().Format("2006-01-02 15:04:05"), status, e.Kind, e.LatencyMs)
+	if e.Detail != "" {
+		line += "  " + e.Detail
+	}
+	return line
+}
+
+// runHealthWatch checks the pinned configuration, or the active one when
+// pinned is empty, every interval until interrupted. A desktop notification
+// is sent when the endpoint starts failing.
+func runHealthWatch(configManager *config.Manager, pinned string) error {
+	if healthInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	notifyEnabled, err := configManager.NotificationsEnabled()
+	if err != nil {
+		return err
+	}
+	notifyEnabled = notifyEnabled && healthNotify
+
+	fmt.Fprintf(stdout, "Watching health every %s, press Ctrl+C to stop\n", healthInterval)
+	watch := newWatchState(func(failing models.APIConfig) string {
+		return suggestFailover(configManager, failing)
+	})
+	for {
+		// Re-read each time so switching the active configuration is followed
+		var cfg *models.APIConfig
+		if pinned != "" {
+			cfg, err = configManager.Get(pinned)
+		} else {
+			cfg, err = configManager.GetActive()
+		}
+		if err != nil {
+			fmt.Fprintf(stdout, "⚠️  %v\n", err)
+		} else {
+			entry := checkEndpoint(cfg)
+			recordHistory(configManager, cfg.Alias, entry)
+			fmt.Fprintf(stdout, "%s  %s\n", formatHealthEntry(entry), cfg.Alias)
+
+			for _, alert := range watch.alerts(*cfg, entry, time.Now()) {
+				if alert.expiry {
+					fmt.Fprintf(stdout, "⚠️  %s\n", alert.title)
+				}
+				if !notifyEnabled {
+					continue
+				}
+				if err := notify.Send(alert.title, alert.message); err != nil {
+					fmt.Fprintf(stdout, "⚠️  %v\n", err)
+				}
+			}
+		}
+		time.Sleep(healthInterval)
+	}
+}
+
+// keyExpiryWarning is how long before its key_expires_at a key is reported
+// as expiring
+const keyExpiryWarning = 7 * 24 * time.Hour
+
+// watchAlert is a desktop notification due in watch mode
+type watchAlert struct {
+	title   string
+	message string
+	expiry  bool // Whether the key is expiring, rather than the endpoint failing
+}
+
+// watchState remembers what watch mode last saw of each configuration, so
+// a failing endpoint or an expiring key is notified once when it starts
+// rather than on every check
+type watchState struct {
+	healthy  map[string]bool
+	expiring map[string]bool
+	failover func(failing models.APIConfig) string // Configuration to suggest instead, or ""
+}
+
+// newWatchState creates the state of a watch, suggesting failovers with
+// failover
+func newWatchState(failover func(failing models.APIConfig) string) *watchState {
+	return &watchState{healthy: map[string]bool{}, expiring: map[string]bool{}, failover: failover}
+}
+
+// alerts records the check of cfg with its result entry and returns the
+// notifications it calls for
+func (w *watchState) alerts(cfg models.APIConfig, entry history.Entry, now time.Time) []watchAlert {
+	var alerts []watchAlert
+
+	wasHealthy, known := w.healthy[cfg.Alias]
+	if !entry.Success && (wasHealthy || !known) {
+		alerts = append(alerts, watchAlert{
+			title:   fmt.Sprintf("apimgr: '%s' is failing", cfg.Alias),
+			message: entry.Detail + w.suggestion(cfg),
+		})
+	}
+	w.healthy[cfg.Alias] = entry.Success
+
+	expiring := !cfg.KeyExpiresAt.IsZero() && now.Add(keyExpiryWarning).After(cfg.KeyExpiresAt)
+	if expiring && !w.expiring[cfg.Alias] {
+		title := fmt.Sprintf("apimgr: the key of '%s' expires on %s", cfg.Alias, cfg.KeyExpiresAt.Format("2006-01-02"))
+		if !now.Before(cfg.KeyExpiresAt) {
+			title = fmt.Sprintf("apimgr: the key of '%s' expired on %s", cfg.Alias, cfg.KeyExpiresAt.Format("2006-01-02"))
+		}
+		alerts = append(alerts, watchAlert{
+			title:   title,
+			message: fmt.Sprintf("Set a new key with 'apimgr set %s api_key=<key>'", cfg.Alias) + w.suggestion(cfg),
+			expiry:  true,
+		})
+	}
+	w.expiring[cfg.Alias] = expiring
+	return alerts
+}
+
+// suggestion returns the failover hint appended to notifications about cfg
+func (w *watchState) suggestion(cfg models.APIConfig) string {
+	if backup := w.failover(cfg); backup != "" {
+		return fmt.Sprintf(". Try 'apimgr switch %s'", backup)
+	}
+	return ""
+}
+
+// checkEndpoint sends a single HEAD request to a configuration's endpoint
+// and returns the result as a history entry
+func checkEndpoint(cfg *models.APIConfig) history.Entry {
+	entry := history.Entry{Time: time.Now(), Kind: history.KindPing}
+
+	tlsConfig, err := utils.NewTLSConfig(cfg)
+	if err != nil {
+		entry.Detail = utils.Redact(err.Error())
+		return entry
+	}
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	req, err := http.NewRequest("HEAD", baseURL, nil)
+	if err != nil {
+		entry.Detail = utils.Redact(err.Error())
+		return entry
+	}
+	if cfg.AuthToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.AuthToken))
+	} else if cfg.APIKey != "" {
+		req.Header.Set("x-api-key", cfg.APIKey)
+	}
+
+	resp, timings, err := probe.Do(client, req)
+	entry.LatencyMs = timings.Total.Milliseconds()
+	if err != nil {
+		entry.Detail = describePingError(err)
+		return entry
+	}
+	resp.Body.Close()
+
+	// Any response below 500 means the endpoint is reachable
+	entry.Success = resp.StatusCode < 500
+	entry.Detail = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	return entry
+}
+
+// suggestFailover returns a configuration to fall back to when failing is
+// down, or an empty string when none is known to be healthy
+func suggestFailover(configManager *config.Manager, failing models.APIConfig) string {
+	configs, err := configManager.List()
+	if err != nil {
+		return ""
+	}
+	return pickFailover(configs, failing, func(alias string) bool {
+		entries, err := history.Load(configManager.StateDir(), alias)
+		return err == nil && len(entries) > 0 && entries[len(entries)-1].Success
+	})
+}
+
+// pickFailover picks the first other configuration whose last recorded
+// result succeeded, preferring the failing configuration's environment
+func pickFailover(configs []models.APIConfig, failing models.APIConfig, lastHealthy func(alias string) bool) string {
+	fallback := ""
+	for _, cfg := range configs {
+		if cfg.Alias == failing.Alias || !lastHealthy(cfg.Alias) {
+			continue
+		}
+		if cfg.Environment == failing.Environment {
+			return cfg.Alias
+		}
+		if fallback == "" {
+			fallback = cfg.Alias
+		}
+	}
+	return fallback
+}
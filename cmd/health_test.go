@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/history"
+	"apimgr/config/models"
+)
+
+func TestPickFailover(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "work", Environment: "prod"},
+		{Alias: "relay", Environment: "dev"},
+		{Alias: "backup", Environment: "prod"},
+		{Alias: "down", Environment: "prod"},
+	}
+	healthy := map[string]bool{"work": true, "relay": true, "backup": true}
+	lastHealthy := func(alias string) bool { return healthy[alias] }
+
+	tests := []struct {
+		name    string
+		failing models.APIConfig
+		want    string
+	}{
+		{name: "prefers same environment", failing: models.APIConfig{Alias: "work", Environment: "prod"}, want: "backup"},
+		{name: "falls back to other environment", failing: models.APIConfig{Alias: "solo", Environment: "staging"}, want: "work"},
+		{name: "skips itself", failing: models.APIConfig{Alias: "relay", Environment: "dev"}, want: "work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickFailover(configs, tt.failing, lastHealthy); got != tt.want {
+				t.Errorf("pickFailover() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := pickFailover(configs, configs[0], func(string) bool { return false }); got != "" {
+		t.Errorf("pickFailover() with nothing healthy = %q, want empty", got)
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-test" {
+			t.Errorf("x-api-key header = %q, want sk-test", r.Header.Get("x-api-key"))
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := &models.APIConfig{Alias: "test", APIKey: "sk-test", BaseURL: server.URL}
+
+	entry := checkEndpoint(cfg)
+	if !entry.Success || entry.Detail != "HTTP 200" {
+		t.Errorf("checkEndpoint() = %+v, want success with HTTP 200", entry)
+	}
+
+	status = http.StatusBadGateway
+	if entry := checkEndpoint(cfg); entry.Success {
+		t.Errorf("checkEndpoint() on HTTP 502 = %+v, want failure", entry)
+	}
+
+	server.Close()
+	if entry := checkEndpoint(cfg); entry.Success || entry.Detail == "" {
+		t.Errorf("checkEndpoint() on a closed server = %+v, want failure with detail", entry)
+	}
+}
+
+func TestWatchAlerts(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	watch := newWatchState(func(failing models.APIConfig) string { return "backup" })
+	ok := history.Entry{Success: true, Detail: "HTTP 200"}
+	down := history.Entry{Detail: "Connection refused"}
+
+	steps := []struct {
+		name    string
+		cfg     models.APIConfig
+		entry   history.Entry
+		now     time.Time
+		want    []string // Titles of the alerts
+		wantMsg string   // Part of the first alert's message
+	}{
+		{name: "healthy", cfg: models.APIConfig{Alias: "work"}, entry: ok, now: now},
+		{name: "starts failing", cfg: models.APIConfig{Alias: "work"}, entry: down, now: now, want: []string{"apimgr: 'work' is failing"}, wantMsg: "Try 'apimgr switch backup'"},
+		{name: "still failing", cfg: models.APIConfig{Alias: "work"}, entry: down, now: now},
+		{name: "key far from expiry", cfg: models.APIConfig{Alias: "work", KeyExpiresAt: now.AddDate(0, 1, 0)}, entry: ok, now: now},
+		{name: "key enters warning window", cfg: models.APIConfig{Alias: "work", KeyExpiresAt: now.AddDate(0, 0, 3)}, entry: ok, now: now, want: []string{"apimgr: the key of 'work' expires on 2025-06-04"}, wantMsg: "apimgr set work api_key=<key>"},
+		{name: "key still expiring", cfg: models.APIConfig{Alias: "work", KeyExpiresAt: now.AddDate(0, 0, 3)}, entry: ok, now: now.Add(time.Hour)},
+		{name: "new key", cfg: models.APIConfig{Alias: "work"}, entry: ok, now: now},
+		{name: "expired key and failing", cfg: models.APIConfig{Alias: "work", KeyExpiresAt: now.AddDate(0, 0, -1)}, entry: down, now: now, want: []string{"apimgr: 'work' is failing", "apimgr: the key of 'work' expired on 2025-05-31"}},
+	}
+	for _, step := range steps {
+		alerts := watch.alerts(step.cfg, step.entry, step.now)
+		var titles []string
+		for _, alert := range alerts {
+			titles = append(titles, alert.title)
+		}
+		if !slices.Equal(titles, step.want) {
+			t.Fatalf("%s: alerts = %q, want %q", step.name, titles, step.want)
+		}
+		if step.wantMsg != "" && !strings.Contains(alerts[0].message, step.wantMsg) {
+			t.Errorf("%s: message = %q, want it to contain %q", step.name, alerts[0].message, step.wantMsg)
+		}
+	}
+}
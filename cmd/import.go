@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"apimgr/config/importer"
+	"apimgr/internal/notice"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFrom      string // Tool to import from
+	importFile      string // Config file to read instead of the tool's default
+	importOverwrite bool   // Replace configs whose alias already exists
+	importDryRun    bool   // Show what would be imported without saving
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFrom, "from", "", "Tool to import from: cc-switch, claude-code-router, llm-env")
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "", "Config file to read (default: the tool's config file)")
+	importCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "Replace configurations with the same alias")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without saving")
+	_ = importCmd.MarkFlagRequired("from")
+}
+
+var importCmd = &cobra.Command{
+	Use:         "import --from <tool>",
+	Annotations: mutates,
+	Short:       "Import configurations from another tool",
+	Long: `Import the providers configured in another tool as apimgr configurations.
+
+Supported tools and the files read by default:
+  cc-switch           ~/.cc-switch/config.json (Claude providers)
+  claude-code-router  ~/.claude-code-router/config.json ($VAR keys are resolved)
+  llm-env             ~/.config/llm-env/config.conf (keys read from api_key_var)
+
+Provider names become aliases. Existing aliases are skipped unless
+--overwrite is given.
+
+Examples:
+  apimgr import --from cc-switch --dry-run
+  apimgr import --from claude-code-router
+  apimgr import --from llm-env --file ./config.conf`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := importer.ParseSource(importFrom)
+		if err != nil {
+			return err
+		}
+
+		path := importFile
+		if path == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to get user home directory: %w", err)
+			}
+			path = source.DefaultPath(homeDir)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s config: %w", source, err)
+		}
+
+		result, err := importer.Parse(source, data, os.Getenv)
+		if err != nil {
+			return err
+		}
+
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		imported := 0
+		for _, cfg := range result.Configs {
+			if _, err := configManager.Get(cfg.Alias); err == nil && !importOverwrite {
+				fmt.Fprintf(stdout, "- %s: already exists, use --overwrite to replace it\n", cfg.Alias)
+				continue
+			}
+			if importDryRun {
+				fmt.Fprintf(stdout, "✓ %s (%s, %s)\n", cfg.Alias, cfg.Provider, cfg.BaseURL)
+				imported++
+				continue
+			}
+			if err := configManager.Add(cfg); err != nil {
+				fmt.Fprintf(stdout, "✗ %s: %v\n", cfg.Alias, err)
+				continue
+			}
+			fmt.Fprintf(stdout, "✓ %s (%s, %s)\n", cfg.Alias, cfg.Provider, cfg.BaseURL)
+			imported++
+		}
+		for _, skipped := range result.Skipped {
+			fmt.Fprintf(stdout, "- %s: skipped, %s\n", skipped.Name, skipped.Reason)
+		}
+
+		if importDryRun {
+			fmt.Fprintf(stdout, "\n%d configuration(s) would be imported from %s\n", imported, path)
+			return nil
+		}
+		if imported > 0 {
+			if err := configManager.GenerateActiveScript(); err != nil {
+				notice.Printf("⚠️  Warning: Failed to generate activation script: %v\n", err)
+			}
+		}
+		fmt.Fprintf(stdout, "\n%d configuration(s) imported from %s\n", imported, path)
+		return nil
+	},
+}
@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+
 	"github.com/spf13/cobra"
 )
 
@@ -18,17 +21,17 @@ var installCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Install shell initialization script",
 	Long:  "Add auto-load command to shell configuration file, so new terminals automatically load active configuration",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Skip shell integration if flag is set
 		if noShellIntegration {
-			fmt.Println("Shell integration skipped (--no-shell-integration flag set)")
-			return
+			fmt.Fprintln(stdout, "Shell integration skipped (--no-shell-integration flag set)")
+			return nil
 		}
 
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to get user home directory: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Failed to get user home directory: %v\n", err)
+			return exitcode.Silent(exitcode.Failure)
 		}
 
 		// Detect shell
@@ -40,12 +43,12 @@ var installCmd = &cobra.Command{
 		} else if strings.Contains(shell, "bash") {
 			rcFile = filepath.Join(homeDir, ".bashrc")
 		} else {
-			fmt.Fprintf(os.Stderr, "Error: Unsupported shell: %s\n", shell)
-			fmt.Fprintf(os.Stderr, "Please manually add the following to your shell configuration file:\n")
-			fmt.Fprintf(os.Stderr, "\nif command -v apimgr &> /dev/null; then\n")
-			fmt.Fprintf(os.Stderr, "  eval \"$(apimgr load-active)\"\n")
-			fmt.Fprintf(os.Stderr, "fi\n")
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Unsupported shell: %s\n", shell)
+			fmt.Fprintf(stderr, "Please manually add the following to your shell configuration file:\n")
+			fmt.Fprintf(stderr, "\nif command -v apimgr &> /dev/null; then\n")
+			fmt.Fprintf(stderr, "  eval \"$(apimgr load-active)\"\n")
+			fmt.Fprintf(stderr, "fi\n")
+			return exitcode.Silent(exitcode.Failure)
 		}
 
 		initScript := `
@@ -69,6 +72,18 @@ if command -v apimgr &> /dev/null; then
       command apimgr "$@"
     fi
   }
+
+  # Prompt hook: reload after 'apimgr switch' in another terminal
+  __apimgr_precmd() {
+    local __apimgr_stamp
+    __apimgr_stamp="$(cat "${__apimgr_changed-}" 2>/dev/null)"
+    if [ -n "$__apimgr_stamp" ] && [ "$__apimgr_stamp" != "${__apimgr_seen-}" ]; then
+      __apimgr_seen="$__apimgr_stamp"
+      eval "$(command apimgr load-active --refresh)"
+    fi
+  }
+  [ -n "${ZSH_VERSION-}" ] && autoload -Uz add-zsh-hook && add-zsh-hook precmd __apimgr_precmd
+  [ -n "${BASH_VERSION-}" ] && PROMPT_COMMAND="${PROMPT_COMMAND:+$PROMPT_COMMAND;}__apimgr_precmd"
 fi
 `
 
@@ -77,21 +92,21 @@ fi
 			if _, err := os.Stat(rcFile); err == nil {
 				content, err := os.ReadFile(rcFile)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: Failed to read %s: %v\n", rcFile, err)
-					os.Exit(1)
+					fmt.Fprintf(stderr, "Error: Failed to read %s: %v\n", rcFile, err)
+					return exitcode.Silent(exitcode.Failure)
 				}
 
-				// Check for new version (with apimgr() function wrapper)
+				// Check for new version (with apimgr() function wrapper and prompt hook)
 				if strings.Contains(string(content), "apimgr load-active") {
-					if strings.Contains(string(content), "apimgr() {") {
-						fmt.Printf("✓ Latest version already installed to %s\n", rcFile)
-						fmt.Printf("\nTip: Run 'source %s' to take effect\n", rcFile)
-						return
+					if strings.Contains(string(content), "apimgr() {") && strings.Contains(string(content), "__apimgr_precmd() {") {
+						fmt.Fprintf(stdout, "✓ Latest version already installed to %s\n", rcFile)
+						fmt.Fprintf(stdout, "\nTip: Run 'source %s' to take effect\n", rcFile)
+						return nil
 					}
-					fmt.Printf("⚠️  Detected old version installation\n")
-					fmt.Printf("Suggested to run 'apimgr install --force' to update to new version\n")
-					fmt.Printf("Or manually update apimgr configuration in %s\n", rcFile)
-					return
+					fmt.Fprintf(stdout, "⚠️  Detected old version installation\n")
+					fmt.Fprintf(stdout, "Suggested to run 'apimgr install --force' to update to new version\n")
+					fmt.Fprintf(stdout, "Or manually update apimgr configuration in %s\n", rcFile)
+					return nil
 				}
 			}
 		} else {
@@ -99,8 +114,8 @@ fi
 			if _, err := os.Stat(rcFile); err == nil {
 				content, err := os.ReadFile(rcFile)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: Failed to read %s: %v\n", rcFile, err)
-					os.Exit(1)
+					fmt.Fprintf(stderr, "Error: Failed to read %s: %v\n", rcFile, err)
+					return exitcode.Silent(exitcode.Failure)
 				}
 
 				// Remove old apimgr configuration
@@ -138,54 +153,55 @@ fi
 				// Write back the cleaned content
 				err = os.WriteFile(rcFile, []byte(strings.Join(newLines, "\n")), 0600)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: Failed to update %s: %v\n", rcFile, err)
-					os.Exit(1)
+					fmt.Fprintf(stderr, "Error: Failed to update %s: %v\n", rcFile, err)
+					return exitcode.Silent(exitcode.Failure)
 				}
 
-				fmt.Printf("✓ Old configuration cleared\n")
+				fmt.Fprintf(stdout, "✓ Old configuration cleared\n")
 			}
 		}
 
 		// Append to rc file
 		f, err := os.OpenFile(rcFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to open %s: %v\n", rcFile, err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Failed to open %s: %v\n", rcFile, err)
+			return exitcode.Silent(exitcode.Failure)
 		}
 		defer f.Close()
 
 		// Write the script to the file
 		bytesWritten, err := f.WriteString(initScript)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to write to %s: %v\n", rcFile, err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Failed to write to %s: %v\n", rcFile, err)
+			return exitcode.Silent(exitcode.Failure)
 		}
 
 		// Close file explicitly to ensure content is flushed to disk
 		err = f.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to close file %s: %v\n", rcFile, err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Failed to close file %s: %v\n", rcFile, err)
+			return exitcode.Silent(exitcode.Failure)
 		}
 
-		fmt.Printf("✓ Successfully installed to %s (%d bytes written)\n\n", rcFile, bytesWritten)
-		fmt.Printf("Please run the following command to take effect:\n")
-		fmt.Printf("  source %s\n\n", rcFile)
-		fmt.Printf("Or reopen the terminal\n\n")
-		fmt.Printf("After installation, you can directly use:\n")
-		fmt.Printf("  apimgr switch <config_alias>  # Automatically switch and apply environment variables\n")
-		fmt.Printf("  apimgr list               # List all configurations\n")
-		fmt.Printf("  apimgr status             # View current configuration status\n")
+		fmt.Fprintf(stdout, "✓ Successfully installed to %s (%d bytes written)\n\n", rcFile, bytesWritten)
+		fmt.Fprintf(stdout, "Please run the following command to take effect:\n")
+		fmt.Fprintf(stdout, "  source %s\n\n", rcFile)
+		fmt.Fprintf(stdout, "Or reopen the terminal\n\n")
+		fmt.Fprintf(stdout, "After installation, you can directly use:\n")
+		fmt.Fprintf(stdout, "  apimgr switch <config_alias>  # Automatically switch and apply environment variables\n")
+		fmt.Fprintf(stdout, "  apimgr list               # List all configurations\n")
+		fmt.Fprintf(stdout, "  apimgr status             # View current configuration status\n")
 
 		// Verify that the file was actually modified by checking if the script exists in the file
 		updatedContent, err := os.ReadFile(rcFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to verify if %s was updated: %v\n", rcFile, err)
+			notice.Printf("Warning: Failed to verify if %s was updated: %v\n", rcFile, err)
 		} else if strings.Contains(string(updatedContent), "apimgr() {") {
-			fmt.Printf("✓ Verification: Configuration successfully written to %s\n", rcFile)
+			fmt.Fprintf(stdout, "✓ Verification: Configuration successfully written to %s\n", rcFile)
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Verification failed, configuration may not be correctly written to %s\n", rcFile)
+			notice.Printf("Warning: Verification failed, configuration may not be correctly written to %s\n", rcFile)
 		}
+		return nil
 	},
 }
 
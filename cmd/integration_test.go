@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"apimgr/config"
 	"apimgr/config/models"
 	"apimgr/config/session"
 )
@@ -17,7 +18,9 @@ import (
 // These tests verify end-to-end workflows for local mode, multi-terminal isolation,
 // and global mode behavior.
 
-// setupIntegrationTestEnv creates a temporary environment for integration tests
+// setupIntegrationTestEnv creates a temporary home for integration tests and
+// points HOME and the XDG directories at it, so commands run with
+// Run find the config file and Claude settings there.
 // Returns: tempDir, configPath, claudeSettingsPath, cleanup function
 func setupIntegrationTestEnv(t *testing.T) (string, string, string, func()) {
 	t.Helper()
@@ -42,9 +45,24 @@ func setupIntegrationTestEnv(t *testing.T) (string, string, string, func()) {
 		t.Fatalf("Failed to create claude dir: %v", err)
 	}
 
+	// Session markers go to the runtime directory
+	if err := os.MkdirAll(filepath.Join(tempDir, "run"), 0700); err != nil {
+		os.RemoveAll(tempDir)
+		t.Fatalf("Failed to create runtime dir: %v", err)
+	}
+
 	configPath := filepath.Join(configDir, "config.json")
 	claudeSettingsPath := filepath.Join(claudeDir, "settings.json")
 
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tempDir, ".local", "state"))
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(tempDir, "run"))
+	t.Setenv(config.ConfigEnvVar, configPath)
+	t.Setenv(config.ClaudeConfigDirEnv, "")
+	t.Setenv(session.DepthEnvVar, "")
+	t.Setenv("SHELL", "/bin/bash")
+
 	cleanup := func() {
 		os.RemoveAll(tempDir)
 	}
@@ -52,6 +70,59 @@ func setupIntegrationTestEnv(t *testing.T) (string, string, string, func()) {
 	return tempDir, configPath, claudeSettingsPath, cleanup
 }
 
+// runIntegrationCommand runs apimgr with args in-process and returns what it
+// printed to stdout
+func runIntegrationCommand(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	if err := Run(args, IO{Out: &out, Err: &errOut}, nil); err != nil {
+		t.Fatalf("apimgr %s failed: %v\n%s", strings.Join(args, " "), err, errOut.String())
+	}
+	return out.String()
+}
+
+// integrationStateDir returns the state directory of the test config, which
+// holds active.env
+func integrationStateDir(t *testing.T) string {
+	t.Helper()
+
+	stateDir, err := config.ResolveStateDir()
+	if err != nil {
+		t.Fatalf("Failed to resolve state dir: %v", err)
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		t.Fatalf("Failed to create state dir: %v", err)
+	}
+	return stateDir
+}
+
+// integrationSessionDir returns the directory holding the session markers of
+// the test config
+func integrationSessionDir(t *testing.T) string {
+	t.Helper()
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		t.Fatalf("Failed to create config manager: %v", err)
+	}
+	if !strings.HasPrefix(cm.SessionDir(), os.Getenv("XDG_RUNTIME_DIR")) {
+		t.Fatalf("Session dir %s is not in XDG_RUNTIME_DIR", cm.SessionDir())
+	}
+	return cm.SessionDir()
+}
+
+// trapPID returns the PID in the trap command printed by switch -l
+func trapPID(t *testing.T, output string) string {
+	t.Helper()
+
+	match := regexp.MustCompile(`trap 'apimgr cleanup-session (\d+)' EXIT`).FindStringSubmatch(output)
+	if match == nil {
+		t.Fatalf("Output missing valid trap command:\n%s", output)
+	}
+	return match[1]
+}
+
 // createIntegrationTestConfig creates a test config file with multiple aliases
 func createIntegrationTestConfig(t *testing.T, configPath string, configs []models.APIConfig, active string) {
 	t.Helper()
@@ -116,8 +187,8 @@ func readConfigFile(t *testing.T, configPath string) models.File {
 }
 
 // sessionMarkerExists checks if a session marker file exists for the given PID
-func sessionMarkerExists(configDir string, pid string) bool {
-	markerPath := filepath.Join(configDir, "session-"+pid)
+func sessionMarkerExists(sessionDir string, pid string) bool {
+	markerPath := filepath.Join(sessionDir, "session-"+pid)
 	_, err := os.Stat(markerPath)
 	return err == nil
 }
@@ -128,8 +199,6 @@ func TestIntegrationEndToEndLocalMode(t *testing.T) {
 	_, configPath, claudeSettingsPath, cleanup := setupIntegrationTestEnv(t)
 	defer cleanup()
 
-	configDir := filepath.Dir(configPath)
-
 	// Create test config with multiple aliases
 	configs := []models.APIConfig{
 		{
@@ -160,38 +229,19 @@ func TestIntegrationEndToEndLocalMode(t *testing.T) {
 		"ANTHROPIC_AUTH_TOKEN": "global-token",
 		"ANTHROPIC_BASE_URL":   "https://global.example.com",
 	})
+	sessionDir := integrationSessionDir(t)
 
 	// Record initial state
 	initialConfig := readConfigFile(t, configPath)
 	initialActive := initialConfig.Active
 
-	// Step 1: Simulate switch -l command execution
-	// In local mode, the switch command should:
+	// Step 1: switch -l should:
 	// - Create session marker
 	// - Update Claude Code settings
 	// - NOT modify global active
 	// - Output trap command
-
-	testPID := "99999"
-	testAlias := "alias1"
-
-	// Create session marker (simulating what switch -l does)
-	markerPath := filepath.Join(configDir, "session-"+testPID)
-	marker := session.SessionMarker{
-		PID:   testPID,
-		Alias: testAlias,
-	}
-	markerData, _ := json.MarshalIndent(marker, "", "  ")
-	if err := os.WriteFile(markerPath, markerData, 0600); err != nil {
-		t.Fatalf("Failed to create session marker: %v", err)
-	}
-
-	// Update Claude settings (simulating SyncClaudeSettingsOnly)
-	createClaudeSettings(t, claudeSettingsPath, map[string]string{
-		"ANTHROPIC_API_KEY":  "sk-test-key-1",
-		"ANTHROPIC_BASE_URL": "https://api1.example.com",
-		"ANTHROPIC_MODEL":    "claude-3-opus",
-	})
+	output := runIntegrationCommand(t, "switch", "-l", "alias1", "--no-prompt")
+	pid := trapPID(t, output)
 
 	// Verify: Global active unchanged
 	afterConfig := readConfigFile(t, configPath)
@@ -210,29 +260,28 @@ func TestIntegrationEndToEndLocalMode(t *testing.T) {
 	}
 
 	// Verify: Session marker created
-	if !sessionMarkerExists(configDir, testPID) {
+	if !sessionMarkerExists(sessionDir, pid) {
 		t.Error("Session marker was not created")
 	}
 
-	// Verify: Trap command format (test the expected output format)
-	expectedTrapCmd := "trap 'apimgr cleanup-session " + testPID + "' EXIT"
-	var output bytes.Buffer
-	output.WriteString(expectedTrapCmd + "\n")
-	if !strings.Contains(output.String(), "trap 'apimgr cleanup-session") {
-		t.Error("Trap command format is incorrect")
-	}
-
-	// Step 2: Simulate shell exit with cleanup-session
-	// Remove the session marker (simulating cleanup-session command)
-	if err := os.Remove(markerPath); err != nil {
-		t.Fatalf("Failed to remove session marker: %v", err)
-	}
+	// Step 2: shell exit runs cleanup-session through the trap
+	runIntegrationCommand(t, "cleanup-session", pid)
 
 	// Verify: Session marker deleted
-	if sessionMarkerExists(configDir, testPID) {
+	if sessionMarkerExists(sessionDir, pid) {
 		t.Error("Session marker was not deleted after cleanup")
 	}
 
+	// Verify: Claude Code restored to global once the last session ended
+	claudeSettings = readClaudeSettings(t, claudeSettingsPath)
+	env = claudeSettings["env"].(map[string]interface{})
+	if env["ANTHROPIC_AUTH_TOKEN"] != "global-token" {
+		t.Errorf("Claude Code not restored to global after cleanup: expected global-token, got %v", env["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if _, hasAPIKey := env["ANTHROPIC_API_KEY"]; hasAPIKey {
+		t.Error("Claude Code should not keep the API key of the ended local session")
+	}
+
 	// Verify: Global active still unchanged
 	finalConfig := readConfigFile(t, configPath)
 	if finalConfig.Active != initialActive {
@@ -240,7 +289,6 @@ func TestIntegrationEndToEndLocalMode(t *testing.T) {
 	}
 }
 
-
 // TestIntegrationMultiTerminalIsolation tests that local mode in one terminal
 // doesn't affect other terminals
 // Task 8.2: Multi-terminal isolation test
@@ -248,8 +296,6 @@ func TestIntegrationMultiTerminalIsolation(t *testing.T) {
 	_, configPath, claudeSettingsPath, cleanup := setupIntegrationTestEnv(t)
 	defer cleanup()
 
-	configDir := filepath.Dir(configPath)
-
 	// Create test config with global active
 	configs := []models.APIConfig{
 		{
@@ -272,58 +318,27 @@ func TestIntegrationMultiTerminalIsolation(t *testing.T) {
 		"ANTHROPIC_AUTH_TOKEN": "global-token",
 		"ANTHROPIC_BASE_URL":   "https://global.example.com",
 	})
+	sessionDir := integrationSessionDir(t)
 
-	// Step 1: Terminal 1 executes switch -l
-	terminal1PID := "11111"
-	
-	// Create session marker for terminal 1
-	marker1Path := filepath.Join(configDir, "session-"+terminal1PID)
-	marker1 := session.SessionMarker{
-		PID:   terminal1PID,
-		Alias: "local-alias",
-	}
-	marker1Data, _ := json.MarshalIndent(marker1, "", "  ")
-	if err := os.WriteFile(marker1Path, marker1Data, 0600); err != nil {
-		t.Fatalf("Failed to create session marker for terminal 1: %v", err)
-	}
-
-	// Update Claude settings to local config (terminal 1's switch -l)
-	createClaudeSettings(t, claudeSettingsPath, map[string]string{
-		"ANTHROPIC_API_KEY":  "sk-local-key",
-		"ANTHROPIC_BASE_URL": "https://local.example.com",
-	})
-
-	// Step 2: Terminal 2 opens and executes load-active
-	// load-active should detect active sessions and restore Claude Code to global
-	
-	// Check for active sessions (simulating HasActiveLocalSessions)
-	entries, err := os.ReadDir(configDir)
-	if err != nil {
-		t.Fatalf("Failed to read config dir: %v", err)
-	}
-	
-	hasActiveSessions := false
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "session-") {
-			hasActiveSessions = true
-			break
-		}
+	// Step 1: Terminal 1 executes switch -l, which points Claude Code at the
+	// local config
+	terminal1PID := trapPID(t, runIntegrationCommand(t, "switch", "-l", "local-alias", "--no-prompt"))
+	claudeSettings := readClaudeSettings(t, claudeSettingsPath)
+	env := claudeSettings["env"].(map[string]interface{})
+	if env["ANTHROPIC_API_KEY"] != "sk-local-key" {
+		t.Fatalf("Terminal 1 Claude Code not switched to local: expected sk-local-key, got %v", env["ANTHROPIC_API_KEY"])
 	}
 
-	if !hasActiveSessions {
-		t.Error("Should detect active sessions from terminal 1")
+	// Step 2: Terminal 2 opens and executes load-active, which detects the
+	// active session of terminal 1 and restores Claude Code to global
+	output := runIntegrationCommand(t, "load-active")
+	if !strings.Contains(output, `export ANTHROPIC_AUTH_TOKEN='global-token'`) {
+		t.Errorf("Terminal 2 should load the global config, got:\n%s", output)
 	}
 
-	// Terminal 2's load-active restores Claude Code to global
-	// (simulating RestoreClaudeToGlobal)
-	createClaudeSettings(t, claudeSettingsPath, map[string]string{
-		"ANTHROPIC_AUTH_TOKEN": "global-token",
-		"ANTHROPIC_BASE_URL":   "https://global.example.com",
-	})
-
 	// Verify: Terminal 2's Claude Code is restored to global
-	claudeSettings := readClaudeSettings(t, claudeSettingsPath)
-	env := claudeSettings["env"].(map[string]interface{})
+	claudeSettings = readClaudeSettings(t, claudeSettingsPath)
+	env = claudeSettings["env"].(map[string]interface{})
 	if env["ANTHROPIC_AUTH_TOKEN"] != "global-token" {
 		t.Errorf("Terminal 2 Claude Code not restored to global: expected global-token, got %v", env["ANTHROPIC_AUTH_TOKEN"])
 	}
@@ -331,31 +346,15 @@ func TestIntegrationMultiTerminalIsolation(t *testing.T) {
 		t.Error("Terminal 2 Claude Code should not have API key from local config")
 	}
 
-	// Step 3: Simulate terminal 1 exit
-	if err := os.Remove(marker1Path); err != nil {
-		t.Fatalf("Failed to remove terminal 1 session marker: %v", err)
+	// Step 3: Terminal 1 exits
+	runIntegrationCommand(t, "cleanup-session", terminal1PID)
+	if sessionMarkerExists(sessionDir, terminal1PID) {
+		t.Error("Should not keep the session of terminal 1 after it exits")
 	}
 
 	// Step 4: Terminal 3 opens and executes load-active
 	// No active sessions now, should keep global config
-	
-	// Check for active sessions again
-	entries, err = os.ReadDir(configDir)
-	if err != nil {
-		t.Fatalf("Failed to read config dir: %v", err)
-	}
-	
-	hasActiveSessions = false
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "session-") {
-			hasActiveSessions = true
-			break
-		}
-	}
-
-	if hasActiveSessions {
-		t.Error("Should not detect active sessions after terminal 1 exit")
-	}
+	runIntegrationCommand(t, "load-active")
 
 	// Verify: Terminal 3's Claude Code remains at global
 	claudeSettings = readClaudeSettings(t, claudeSettingsPath)
@@ -371,9 +370,6 @@ func TestIntegrationGlobalModeUnchanged(t *testing.T) {
 	_, configPath, claudeSettingsPath, cleanup := setupIntegrationTestEnv(t)
 	defer cleanup()
 
-	configDir := filepath.Dir(configPath)
-	activeEnvPath := filepath.Join(configDir, "active.env")
-
 	// Create test config with initial global active
 	configs := []models.APIConfig{
 		{
@@ -397,19 +393,19 @@ func TestIntegrationGlobalModeUnchanged(t *testing.T) {
 		"ANTHROPIC_AUTH_TOKEN": "old-token",
 		"ANTHROPIC_BASE_URL":   "https://old.example.com",
 	})
+	activeEnvPath := filepath.Join(integrationStateDir(t), config.ActiveEnvFileName)
 
 	// Step 1: Execute switch (without -l) command
 	// This should:
 	// - Update global active field
 	// - Generate active.env
 	// - Update Claude Code
-
-	// Simulate SetActive
-	configFile := readConfigFile(t, configPath)
-	configFile.Active = "new-alias"
-	data, _ := json.MarshalIndent(configFile, "", "  ")
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		t.Fatalf("Failed to update config file: %v", err)
+	output := runIntegrationCommand(t, "switch", "new-alias", "--no-prompt")
+	if strings.Contains(output, "trap ") {
+		t.Errorf("Global switch should not output a trap command, got:\n%s", output)
+	}
+	if !strings.Contains(output, `export ANTHROPIC_API_KEY='sk-new-key'`) {
+		t.Errorf("Global switch should export the new API key, got:\n%s", output)
 	}
 
 	// Verify: Global active updated
@@ -418,28 +414,6 @@ func TestIntegrationGlobalModeUnchanged(t *testing.T) {
 		t.Errorf("Global active not updated: expected new-alias, got %s", updatedConfig.Active)
 	}
 
-	// Simulate GenerateActiveScript
-	envScript := `# Auto-generated active configuration
-unset ANTHROPIC_API_KEY
-unset ANTHROPIC_AUTH_TOKEN
-unset ANTHROPIC_BASE_URL
-unset ANTHROPIC_MODEL
-unset APIMGR_ACTIVE
-
-export ANTHROPIC_API_KEY="sk-new-key"
-export ANTHROPIC_BASE_URL="https://new.example.com"
-export ANTHROPIC_MODEL="claude-3-opus"
-export APIMGR_ACTIVE="new-alias"
-`
-	if err := os.WriteFile(activeEnvPath, []byte(envScript), 0600); err != nil {
-		t.Fatalf("Failed to write active.env: %v", err)
-	}
-
-	// Verify: active.env generated
-	if _, err := os.Stat(activeEnvPath); os.IsNotExist(err) {
-		t.Error("active.env was not generated")
-	}
-
 	// Verify: active.env contains correct content
 	activeEnvContent, err := os.ReadFile(activeEnvPath)
 	if err != nil {
@@ -452,13 +426,6 @@ export APIMGR_ACTIVE="new-alias"
 		t.Error("active.env does not contain new alias")
 	}
 
-	// Simulate Claude Code sync
-	createClaudeSettings(t, claudeSettingsPath, map[string]string{
-		"ANTHROPIC_API_KEY":  "sk-new-key",
-		"ANTHROPIC_BASE_URL": "https://new.example.com",
-		"ANTHROPIC_MODEL":    "claude-3-opus",
-	})
-
 	// Verify: Claude Code updated
 	claudeSettings := readClaudeSettings(t, claudeSettingsPath)
 	env := claudeSettings["env"].(map[string]interface{})
@@ -467,35 +434,18 @@ export APIMGR_ACTIVE="new-alias"
 	}
 
 	// Step 2: Verify status command shows correct active config
-	// The status command reads from the config file's active field
-	finalConfig := readConfigFile(t, configPath)
-	if finalConfig.Active != "new-alias" {
-		t.Errorf("Status should show new-alias as active, got %s", finalConfig.Active)
-	}
-
-	// Verify the active config details
-	var activeConfig *models.APIConfig
-	for _, cfg := range finalConfig.Configs {
-		if cfg.Alias == finalConfig.Active {
-			activeConfig = &cfg
-			break
-		}
-	}
-	if activeConfig == nil {
-		t.Fatal("Active config not found")
+	t.Setenv("APIMGR_ACTIVE", "")
+	status := runIntegrationCommand(t, "status")
+	if !strings.Contains(status, "new-alias") {
+		t.Errorf("Status should show new-alias as active, got:\n%s", status)
 	}
-	if activeConfig.APIKey != "sk-new-key" {
-		t.Errorf("Active config API key mismatch: expected sk-new-key, got %s", activeConfig.APIKey)
-	}
-	if activeConfig.BaseURL != "https://new.example.com" {
-		t.Errorf("Active config base URL mismatch: expected https://new.example.com, got %s", activeConfig.BaseURL)
+	if !strings.Contains(status, "https://new.example.com") {
+		t.Errorf("Status should show the new base URL, got:\n%s", status)
 	}
 }
 
 // TestIntegrationSwitchCommandOutput tests the actual switch command output format
 func TestIntegrationSwitchCommandOutput(t *testing.T) {
-	// Test that the switch command output format is correct for shell eval
-	
 	// Expected output format for local mode:
 	// trap 'apimgr cleanup-session <pid>' EXIT
 	// unset ANTHROPIC_API_KEY
@@ -514,19 +464,16 @@ func TestIntegrationSwitchCommandOutput(t *testing.T) {
 		baseURL   string
 		model     string
 		alias     string
-		pid       string
 	}{
 		{
 			name:   "API key only",
 			apiKey: "sk-test-123",
 			alias:  "test-alias",
-			pid:    "12345",
 		},
 		{
 			name:      "Auth token only",
 			authToken: "token-abc",
 			alias:     "token-alias",
-			pid:       "67890",
 		},
 		{
 			name:    "Full config",
@@ -534,43 +481,27 @@ func TestIntegrationSwitchCommandOutput(t *testing.T) {
 			baseURL: "https://api.example.com",
 			model:   "claude-3-opus",
 			alias:   "full-alias",
-			pid:     "11111",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			var output bytes.Buffer
-
-			// Generate expected output
-			output.WriteString("trap 'apimgr cleanup-session " + tc.pid + "' EXIT\n")
-			output.WriteString("unset ANTHROPIC_API_KEY\n")
-			output.WriteString("unset ANTHROPIC_AUTH_TOKEN\n")
-			output.WriteString("unset ANTHROPIC_BASE_URL\n")
-			output.WriteString("unset ANTHROPIC_MODEL\n")
-			output.WriteString("unset APIMGR_ACTIVE\n")
-
-			if tc.apiKey != "" {
-				output.WriteString("export ANTHROPIC_API_KEY=\"" + tc.apiKey + "\"\n")
-			}
-			if tc.authToken != "" {
-				output.WriteString("export ANTHROPIC_AUTH_TOKEN=\"" + tc.authToken + "\"\n")
-			}
-			if tc.baseURL != "" {
-				output.WriteString("export ANTHROPIC_BASE_URL=\"" + tc.baseURL + "\"\n")
-			}
-			if tc.model != "" {
-				output.WriteString("export ANTHROPIC_MODEL=\"" + tc.model + "\"\n")
-			}
-			output.WriteString("export APIMGR_ACTIVE=\"" + tc.alias + "\"\n")
+			_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+
+			createIntegrationTestConfig(t, configPath, []models.APIConfig{{
+				Alias:     tc.alias,
+				Provider:  "anthropic",
+				APIKey:    tc.apiKey,
+				AuthToken: tc.authToken,
+				BaseURL:   tc.baseURL,
+				Model:     tc.model,
+			}}, "")
 
-			outputStr := output.String()
+			outputStr := runIntegrationCommand(t, "switch", "-l", tc.alias, "--no-prompt")
 
 			// Verify trap command
-			trapRegex := regexp.MustCompile(`trap 'apimgr cleanup-session (\d+)' EXIT`)
-			if !trapRegex.MatchString(outputStr) {
-				t.Error("Output missing valid trap command")
-			}
+			trapPID(t, outputStr)
 
 			// Verify unset commands
 			unsetVars := []string{"ANTHROPIC_API_KEY", "ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_BASE_URL", "ANTHROPIC_MODEL", "APIMGR_ACTIVE"}
@@ -581,13 +512,19 @@ func TestIntegrationSwitchCommandOutput(t *testing.T) {
 			}
 
 			// Verify export commands
-			if tc.apiKey != "" && !strings.Contains(outputStr, "export ANTHROPIC_API_KEY=\""+tc.apiKey+"\"") {
+			if tc.apiKey != "" && !strings.Contains(outputStr, "export ANTHROPIC_API_KEY='"+tc.apiKey+"'") {
 				t.Error("Output missing API key export")
 			}
-			if tc.authToken != "" && !strings.Contains(outputStr, "export ANTHROPIC_AUTH_TOKEN=\""+tc.authToken+"\"") {
+			if tc.authToken != "" && !strings.Contains(outputStr, "export ANTHROPIC_AUTH_TOKEN='"+tc.authToken+"'") {
 				t.Error("Output missing auth token export")
 			}
-			if !strings.Contains(outputStr, "export APIMGR_ACTIVE=\""+tc.alias+"\"") {
+			if tc.baseURL != "" && !strings.Contains(outputStr, "export ANTHROPIC_BASE_URL='"+tc.baseURL+"'") {
+				t.Error("Output missing base URL export")
+			}
+			if tc.model != "" && !strings.Contains(outputStr, "export ANTHROPIC_MODEL='"+tc.model+"'") {
+				t.Error("Output missing model export")
+			}
+			if !strings.Contains(outputStr, "export APIMGR_ACTIVE='"+tc.alias+"'") {
 				t.Error("Output missing APIMGR_ACTIVE export")
 			}
 		})
@@ -599,49 +536,34 @@ func TestIntegrationStaleSessionCleanup(t *testing.T) {
 	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
 	defer cleanup()
 
-	configDir := filepath.Dir(configPath)
+	// Create a config file
+	configs := []models.APIConfig{
+		{
+			Alias:    "test-alias",
+			Provider: "anthropic",
+			APIKey:   "sk-test-key",
+		},
+	}
+	createIntegrationTestConfig(t, configPath, configs, "test-alias")
+	sessionDir := integrationSessionDir(t)
 
 	// Create a stale session marker with a non-existent PID
 	// Use a very high PID that's unlikely to exist
 	stalePID := "999999999"
-	staleMarkerPath := filepath.Join(configDir, "session-"+stalePID)
-	staleMarker := session.SessionMarker{
-		PID:   stalePID,
-		Alias: "stale-alias",
-	}
-	staleMarkerData, _ := json.MarshalIndent(staleMarker, "", "  ")
-	if err := os.WriteFile(staleMarkerPath, staleMarkerData, 0600); err != nil {
+	if err := session.CreateSessionMarker(sessionDir, stalePID, "stale-alias"); err != nil {
 		t.Fatalf("Failed to create stale session marker: %v", err)
 	}
 
 	// Verify stale marker exists
-	if _, err := os.Stat(staleMarkerPath); os.IsNotExist(err) {
+	if !sessionMarkerExists(sessionDir, stalePID) {
 		t.Fatal("Stale session marker was not created")
 	}
 
-	// Create a config file
-	configs := []models.APIConfig{
-		{
-			Alias:    "test-alias",
-			Provider: "anthropic",
-			APIKey:   "sk-test-key",
-		},
-	}
-	createIntegrationTestConfig(t, configPath, configs, "test-alias")
+	// load-active checks whether the shells of session markers still run
+	// and removes the markers of those that do not
+	runIntegrationCommand(t, "load-active")
 
-	// Simulate HasActiveLocalSessions which should clean up stale sessions
-	// The actual implementation checks if PIDs are running and removes stale ones
-	
-	// For this test, we manually verify the cleanup logic
-	// In the real implementation, HasActiveLocalSessions would:
-	// 1. List all session-* files
-	// 2. Check if each PID is running
-	// 3. Remove files for non-running PIDs
-
-	// Since PID 999999999 is unlikely to be running, it should be cleaned up
-	// We simulate this by checking if the PID exists and removing if not
-	
-	// Note: In a real test with the actual binary, we would run:
-	// apimgr load-active
-	// and verify the stale session is cleaned up
+	if sessionMarkerExists(sessionDir, stalePID) {
+		t.Error("Stale session marker was not cleaned up by load-active")
+	}
 }
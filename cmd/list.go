@@ -1,41 +1,155 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
 	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listProvider string   // Only list configurations of this provider
+	listEnv      string   // Only list configurations of this environment
+	listLimit    int      // Maximum number of configurations listed, 0 for all
+	listOffset   int      // Configurations skipped before listing
+	listFields   []string // Fields listed as columns instead of the default format
+	listFormat   string   // Output format: text, csv or tsv
+)
+
+// defaultListFields are the columns of list --format csv|tsv without --fields
+var defaultListFields = []string{"alias", "provider", "environment", "extends", "base_url", "model", "models"}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listProvider, "provider", "", "Only list configurations of this provider")
+	listCmd.Flags().StringVar(&listEnv, "env", "", "Only list configurations of this environment")
+	listCmd.Flags().StringVar(&listEnv, "tag", "", "Only list configurations of this environment")
+	// Deprecated alias of --env. MarkDeprecated would print its warning to
+	// stdout, so it is hidden and warned about with the notices instead.
+	_ = listCmd.Flags().MarkHidden("tag")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Maximum number of configurations listed (0 for all)")
+	listCmd.Flags().IntVar(&listOffset, "offset", 0, "Number of configurations skipped before listing")
+	listCmd.Flags().StringSliceVar(&listFields, "fields", nil, "Comma-separated fields listed as columns, e.g. alias,model,base_url")
+	listCmd.Flags().StringVar(&listFormat, "format", formatText, formatFlagUsage)
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all API configurations",
-	Long:  "List all saved API configurations",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewConfigManager()
+	Long: `List all saved API configurations
+
+Filters narrow the list down, then --offset and --limit select a page of
+what is left. --fields lists the named fields as columns instead, for
+scripts and large stores; keys are masked. --format csv or tsv writes the
+columns with a header row for spreadsheets and awk, by default alias,
+provider, environment, extends, base_url, model and models.
+
+--format template='...' prints each configuration with a Go template over
+its fields, such as {{.Alias}}, {{.Model}} and {{join .Models ","}}; keys
+are masked.
+
+Fields:
+  Any field 'apimgr get' shows, and vars.<name>
+
+Examples:
+  apimgr list --provider anthropic --env work
+  apimgr list --limit 20 --offset 20
+  apimgr list --fields alias,model,base_url
+  apimgr list --format csv > configs.csv
+  apimgr list --format 'template={{.Alias}} {{.Model}}'`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if listLimit < 0 || listOffset < 0 {
+			return exitcode.New(exitcode.Usage, "--limit and --offset cannot be negative")
+		}
+		comma, err := formatDelimiter(listFormat)
+		if err != nil {
+			return err
+		}
+		tmpl, err := parseFormatTemplate(listFormat)
+		if err != nil {
+			return err
+		}
+		if tmpl != nil && len(listFields) > 0 {
+			return exitcode.New(exitcode.Usage, "--format %s cannot be used with --fields", formatTemplate)
+		}
+		for _, name := range listFields {
+			if _, _, err := config.FieldValue(&models.APIConfig{}, name); err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("tag") {
+			notice.Println("Warning: --tag is deprecated, use --env instead")
+		}
+
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
 		configs, err := configManager.List()
-		if err != nil {
+		var resolveErr *config.ResolveError
+		if errors.As(err, &resolveErr) {
+			// The others are listed, then the broken ones fail the command
+			defer func() {
+				if err == nil {
+					err = resolveErr
+				}
+			}()
+		} else if err != nil {
 			return err
 		}
 
+		total := len(configs)
+		configs = filterListed(configs, listProvider, listEnv)
+		matched := len(configs)
+		configs = pageConfigs(configs, listOffset, listLimit)
+
+		if tmpl != nil {
+			for _, cfg := range configs {
+				if err := writeTemplate(stdout, tmpl, templateConfig(cfg)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if comma != 0 {
+			fields := listFields
+			if len(fields) == 0 {
+				fields = defaultListFields
+			}
+			return writeFieldRecords(stdout, comma, configs, fields)
+		}
+		if len(listFields) > 0 {
+			out, err := formatFieldColumns(configs, listFields)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(stdout, out)
+			return nil
+		}
+
 		if len(configs) == 0 {
-			fmt.Println("No configurations available")
+			switch {
+			case total == 0:
+				fmt.Fprintln(stdout, "No configurations available")
+			case matched == 0:
+				fmt.Fprintln(stdout, "No configurations match the filters")
+			default:
+				fmt.Fprintf(stdout, "No configurations past offset %d (%d in total)\n", listOffset, matched)
+			}
 			return nil
 		}
 
 		// Get active configuration name
-		activeName, _ := configManager.GetActiveName()
+		activeName, _ := configManager.GetActiveName(config.ScopeShell)
 
-		fmt.Println("Available configurations:")
+		fmt.Fprintln(stdout, "Available configurations:")
 		for _, cfg := range configs {
 			// Display masked API key or auth token
 			var authInfo string
@@ -54,18 +168,119 @@ var listCmd = &cobra.Command{
 			// Format models display with active model marker
 			modelsDisplay := formatModelsDisplay(cfg.Models, cfg.Model)
 
-			fmt.Printf("%s %s: %s (URL: %s, Models: %s)\n",
-				activeMarker, cfg.Alias, authInfo, cfg.BaseURL, modelsDisplay)
+			// Show the environment tag when set
+			envTag := ""
+			if cfg.Environment != "" {
+				envTag = fmt.Sprintf(" [env: %s]", cfg.Environment)
+			}
+			if cfg.Extends != "" {
+				envTag += fmt.Sprintf(" [extends: %s]", cfg.Extends)
+			}
+
+			fmt.Fprintf(stdout, "%s %s%s: %s (URL: %s, Models: %s)\n",
+				activeMarker, cfg.Alias, envTag, authInfo, cfg.BaseURL, modelsDisplay)
 		}
 
+		if len(configs) < matched {
+			fmt.Fprintf(stdout, "\nShowing %d-%d of %d configurations\n", listOffset+1, listOffset+len(configs), matched)
+		}
 		if activeName != "" {
-			fmt.Printf("\n* indicates the currently active configuration\n")
+			fmt.Fprintf(stdout, "\n* indicates the currently active configuration\n")
 		}
-		fmt.Printf("[active] indicates the currently active model within a configuration\n")
+		fmt.Fprintf(stdout, "[active] indicates the currently active model within a configuration\n")
 		return nil
 	},
 }
 
+// filterListed returns the configurations of a provider and environment,
+// either left empty to not filter on it. An unset provider is anthropic.
+func filterListed(configs []models.APIConfig, provider, environment string) []models.APIConfig {
+	if environment != "" {
+		configs = filterByEnvironment(configs, environment)
+	}
+	if provider == "" {
+		return configs
+	}
+	filtered := make([]models.APIConfig, 0, len(configs))
+	for _, cfg := range configs {
+		cfgProvider := cfg.Provider
+		if cfgProvider == "" {
+			cfgProvider = "anthropic"
+		}
+		if strings.EqualFold(cfgProvider, provider) {
+			filtered = append(filtered, cfg)
+		}
+	}
+	return filtered
+}
+
+// pageConfigs returns limit configurations starting at offset, all the rest
+// when limit is 0
+func pageConfigs(configs []models.APIConfig, offset, limit int) []models.APIConfig {
+	if offset >= len(configs) {
+		return nil
+	}
+	configs = configs[offset:]
+	if limit > 0 && limit < len(configs) {
+		configs = configs[:limit]
+	}
+	return configs
+}
+
+// formatFieldColumns formats the named fields of each configuration as
+// aligned columns under a header, secrets masked
+func formatFieldColumns(configs []models.APIConfig, names []string) (string, error) {
+	rows := [][]string{make([]string, len(names))}
+	widths := make([]int, len(names))
+	for i, name := range names {
+		rows[0][i] = strings.ToUpper(name)
+		widths[i] = len(name)
+	}
+	for _, cfg := range configs {
+		row := make([]string, len(names))
+		for i, name := range names {
+			value, secret, err := config.FieldValue(&cfg, name)
+			if err != nil {
+				return "", err
+			}
+			row[i] = formatField(value, secret, false)
+			widths[i] = max(widths[i], len(row[i]))
+		}
+		rows = append(rows, row)
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		for i, value := range row {
+			if i == len(row)-1 {
+				b.WriteString(value)
+				break
+			}
+			fmt.Fprintf(&b, "%-*s  ", widths[i], value)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// writeFieldRecords writes the named fields of each configuration as CSV or
+// TSV under a header row of the field names, secrets masked
+func writeFieldRecords(w io.Writer, comma rune, configs []models.APIConfig, names []string) error {
+	records := make([][]string, 0, len(configs))
+	for _, cfg := range configs {
+		record := make([]string, len(names))
+		for i, name := range names {
+			value, secret, err := config.FieldValue(&cfg, name)
+			if err != nil {
+				return err
+			}
+			record[i] = delimitedField(value, secret)
+		}
+		records = append(records, record)
+	}
+	return writeDelimited(w, comma, names, records)
+}
+
 // formatModelsDisplay formats the models list for display, marking the active model.
 // Requirements: 3.1, 3.3
 func formatModelsDisplay(models []string, activeModel string) string {
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"slices"
+	"strings"
 	"testing"
+
+	"apimgr/config/models"
 )
 
 func TestListCmd(t *testing.T) {
@@ -33,3 +38,137 @@ func TestListCmd(t *testing.T) {
 		}
 	})
 }
+
+func TestFilterListed(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "a", Environment: "work"},
+		{Alias: "b", Provider: "openai", Environment: "work"},
+		{Alias: "c", Provider: "anthropic"},
+	}
+	tests := []struct {
+		name        string
+		provider    string
+		environment string
+		want        []string
+	}{
+		{"no filters", "", "", []string{"a", "b", "c"}},
+		{"unset provider is anthropic", "anthropic", "", []string{"a", "c"}},
+		{"provider ignores case", "OpenAI", "", []string{"b"}},
+		{"environment", "", "work", []string{"a", "b"}},
+		{"both", "anthropic", "work", []string{"a"}},
+		{"no match", "gemini", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cfg := range filterListed(configs, tt.provider, tt.environment) {
+				got = append(got, cfg.Alias)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterListed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageConfigs(t *testing.T) {
+	configs := []models.APIConfig{{Alias: "a"}, {Alias: "b"}, {Alias: "c"}}
+	tests := []struct {
+		name          string
+		offset, limit int
+		want          []string
+	}{
+		{"all", 0, 0, []string{"a", "b", "c"}},
+		{"limit", 0, 2, []string{"a", "b"}},
+		{"offset", 1, 0, []string{"b", "c"}},
+		{"page", 1, 1, []string{"b"}},
+		{"limit past the end", 2, 5, []string{"c"}},
+		{"offset past the end", 3, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cfg := range pageConfigs(configs, tt.offset, tt.limit) {
+				got = append(got, cfg.Alias)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pageConfigs(%d, %d) = %v, want %v", tt.offset, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFieldColumns(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "relay", APIKey: "sk-ant-api03-abcdefgh", Model: "claude-sonnet"},
+		{Alias: "b"},
+	}
+	got, err := formatFieldColumns(configs, []string{"alias", "api_key", "model"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ALIAS  API_KEY       MODEL\n" +
+		"relay  sk-a****efgh  claude-sonnet\n" +
+		"b      (unset)       (unset)\n"
+	if got != want {
+		t.Errorf("formatFieldColumns() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := formatFieldColumns(configs, []string{"nope"}); err == nil {
+		t.Error("formatFieldColumns() with an unknown field should fail")
+	}
+}
+
+func TestWriteFieldRecords(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "relay", APIKey: "sk-ant-api03-abcdefgh", Models: []string{"m1", "m2"}},
+		{Alias: "b"},
+	}
+	var b strings.Builder
+	if err := writeFieldRecords(&b, ',', configs, []string{"alias", "api_key", "models"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "alias,api_key,models\n" +
+		"relay,sk-a****efgh,\"m1,m2\"\n" +
+		"b,,\n"
+	if b.String() != want {
+		t.Errorf("writeFieldRecords() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestListTagAlias(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "dev", APIKey: "sk-dev", Environment: "dev"},
+		{Alias: "prod", APIKey: "sk-prod", Environment: "prod"},
+	}, "")
+
+	for _, flag := range []string{"--env", "--tag"} {
+		t.Run(flag, func(t *testing.T) {
+			out := runIntegrationCommand(t, "list", flag, "prod", "--format", "template={{.Alias}}")
+			if got := strings.TrimSpace(out); got != "prod" {
+				t.Errorf("list %s prod = %q, want only prod", flag, got)
+			}
+		})
+	}
+}
+
+func TestListBrokenExtends(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "relay", APIKey: "sk-relay"},
+		{Alias: "orphan", Extends: "missing"},
+		{Alias: "alice", Extends: "relay"},
+	}, "")
+
+	var out bytes.Buffer
+	err := Run([]string{"list", "--format", "template={{.Alias}}"}, IO{Out: &out}, nil)
+	if err == nil || !strings.Contains(err.Error(), "'orphan' extends unknown configuration") {
+		t.Errorf("list error = %v, want the broken config reported", err)
+	}
+	if got := strings.Fields(out.String()); !slices.Equal(got, []string{"relay", "alice"}) {
+		t.Errorf("list = %q, want the other configs listed", got)
+	}
+}
@@ -2,39 +2,95 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
 
 	"apimgr/config"
+	"apimgr/config/models"
 	"apimgr/config/session"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
 	"github.com/spf13/cobra"
 )
 
+var (
+	loadActiveRefresh bool
+	loadActiveShell   string // Shell syntax to print, detected from $SHELL when empty
+)
+
 func init() {
 	rootCmd.AddCommand(loadActiveCmd)
+	loadActiveCmd.Flags().BoolVar(&loadActiveRefresh, "refresh", false, "Reload after a global switch elsewhere, printing nothing in shells with a local session")
+	loadActiveCmd.Flags().StringVar(&loadActiveShell, "shell", "", "Shell syntax to print: bash, zsh, fish or powershell (default from $SHELL)")
 }
 
 var loadActiveCmd = &cobra.Command{
 	Use:   "load-active",
 	Short: "Load global active configuration (for shell initialization)",
-	Long:  "This command is used in shell initialization scripts to load the global active configuration and restore Claude Code settings if needed. Use: eval \"$(apimgr load-active)\"",
+	Long: `This command is used in shell initialization scripts to load the global active configuration and restore Claude Code settings if needed. Use: eval "$(apimgr load-active)"
+
+The output also records the global switch notification file, which the shell
+integration checks at each prompt. When another terminal switches globally,
+the prompt hook runs 'apimgr load-active --refresh' to pick up the new
+configuration. Shells with a local session (switch -l) keep theirs.
+
+The commands are printed for the shell running apimgr, else the one in
+$SHELL, or the one given with --shell:
+  bash, zsh   eval "$(apimgr load-active)"
+  fish        apimgr load-active --shell fish | source
+  PowerShell  apimgr load-active --shell powershell | Out-String | Invoke-Expression
+
+Run in a terminal instead of being evaluated, it prints these instructions.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configManager, err := config.NewConfigManager()
+		shell, err := loadActiveSyntax(loadActiveShell, session.ShellName(), os.Getenv("SHELL"), runtime.GOOS)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+
+		// stdout is evaluated by the shell, diagnostics go to stderr
+		out := cmd.OutOrStdout()
+		if isTerminal(out) {
+			fmt.Fprintln(out, loadActiveInstructions(shell))
+			return nil
+		}
+
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
+		if loadActiveRefresh {
+			marker, _ := session.ReadSessionMarker(configManager.SessionDir(), strconv.Itoa(session.ShellPID()))
+			if marker != nil {
+				return nil
+			}
+			// The shell's APIMGR_ACTIVE still names the config it loaded
+			// before, so read the global one from the config file. Without
+			// one, only unset lines are printed.
+			var apiConfig *models.APIConfig
+			if alias, err := configManager.GetActiveName(config.ScopeGlobal); err == nil && alias != "" {
+				apiConfig, _ = configManager.Get(alias)
+			}
+			fmt.Fprint(out, syncpkg.GenerateShellEnvCommands(apiConfig, shell))
+			return nil
+		}
+		defer printGlobalChangeVars(out, configManager, shell)
 
 		// Check for active local sessions and clean up stale ones
 		// This also restores Claude Code to global config if there are active sessions
-		hasActiveSessions, err := session.HasActiveLocalSessions(configManager.GetConfigPath())
+		hasActiveSessions, err := session.HasActiveLocalSessions(configManager.SessionDir())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to check for active sessions: %v\n", err)
+			notice.Printf("Warning: Failed to check for active sessions: %v\n", err)
 		}
 
 		// If there are active local sessions in other terminals, restore Claude Code to global config
 		// This ensures new shells use the global configuration, not a local one from another terminal
 		if hasActiveSessions {
 			if err := configManager.RestoreClaudeToGlobal(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to restore Claude Code to global: %v\n", err)
+				notice.Printf("Warning: Failed to restore Claude Code to global: %v\n", err)
 			}
 		}
 
@@ -42,34 +98,54 @@ var loadActiveCmd = &cobra.Command{
 		apiConfig, err := configManager.GetActive()
 		if err != nil {
 			// If no active config, output unset commands to clear any stale env vars
-			fmt.Println("unset ANTHROPIC_API_KEY")
-			fmt.Println("unset ANTHROPIC_AUTH_TOKEN")
-			fmt.Println("unset ANTHROPIC_BASE_URL")
-			fmt.Println("unset ANTHROPIC_MODEL")
-			fmt.Println("unset APIMGR_ACTIVE")
+			fmt.Fprint(out, syncpkg.GenerateShellEnvCommands(nil, shell))
 			return nil
 		}
 
-		// Output unset commands first to clear any stale env vars
-		fmt.Println("unset ANTHROPIC_API_KEY")
-		fmt.Println("unset ANTHROPIC_AUTH_TOKEN")
-		fmt.Println("unset ANTHROPIC_BASE_URL")
-		fmt.Println("unset ANTHROPIC_MODEL")
-		fmt.Println("unset APIMGR_ACTIVE")
-
-		// Export environment variables for the global active configuration
-		if apiConfig.APIKey != "" {
-			fmt.Printf("export ANTHROPIC_API_KEY=\"%s\"\n", apiConfig.APIKey)
-		} else if apiConfig.AuthToken != "" {
-			fmt.Printf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", apiConfig.AuthToken)
-		}
-		if apiConfig.BaseURL != "" {
-			fmt.Printf("export ANTHROPIC_BASE_URL=\"%s\"\n", apiConfig.BaseURL)
-		}
-		if apiConfig.Model != "" {
-			fmt.Printf("export ANTHROPIC_MODEL=\"%s\"\n", apiConfig.Model)
-		}
-		fmt.Printf("export APIMGR_ACTIVE=\"%s\"\n", apiConfig.Alias)
+		// Clear stale env vars and export the global active configuration
+		fmt.Fprint(out, syncpkg.GenerateShellEnvCommands(apiConfig, shell))
 		return nil
 	},
 }
+
+// printGlobalChangeVars sets the shell variables the prompt hook of the
+// shell integration uses to notice global switches in other terminals
+func printGlobalChangeVars(out io.Writer, configManager *config.Manager, shell string) {
+	fmt.Fprint(out, syncpkg.ShellSetVar(shell, "__apimgr_changed", configManager.GlobalChangedPath()))
+	fmt.Fprint(out, syncpkg.ShellSetVar(shell, "__apimgr_seen", configManager.GlobalChangeStamp()))
+}
+
+// loadActiveSyntax returns the shell syntax to print: the one of --shell,
+// else of the shell running apimgr when it is a known one, since $SHELL
+// names the login shell, else of $SHELL, else PowerShell on Windows, where
+// $SHELL is rarely set
+func loadActiveSyntax(flag, running, shellEnv, goos string) (string, error) {
+	switch {
+	case flag != "":
+		return syncpkg.ParseShell(flag)
+	case running != "":
+		if shell, err := syncpkg.ParseShell(strings.TrimSuffix(running, ".exe")); err == nil {
+			return shell, nil
+		}
+	}
+	switch {
+	case shellEnv == "" && goos == "windows":
+		return syncpkg.ShellPowerShell, nil
+	default:
+		return syncpkg.DetectShell(shellEnv), nil
+	}
+}
+
+// loadActiveInstructions tells how to load the active configuration in shell
+func loadActiveInstructions(shell string) string {
+	var line, rcFile string
+	switch shell {
+	case syncpkg.ShellFish:
+		line, rcFile = "apimgr load-active --shell fish | source", "~/.config/fish/config.fish"
+	case syncpkg.ShellPowerShell:
+		line, rcFile = "apimgr load-active --shell powershell | Out-String | Invoke-Expression", "$PROFILE"
+	default:
+		line, rcFile = `eval "$(apimgr load-active)"`, "~/.bashrc or ~/.zshrc ('apimgr install' adds it)"
+	}
+	return fmt.Sprintf("load-active prints shell commands that set the active configuration's variables.\nRun this to load them, or add it to %s:\n\n  %s", rcFile, line)
+}
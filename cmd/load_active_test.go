@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	syncpkg "apimgr/config/sync"
+)
+
+func TestLoadActiveSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		running  string
+		shellEnv string
+		goos     string
+		want     string
+		wantErr  bool
+	}{
+		{"flag wins", "fish", "zsh", "/bin/zsh", "linux", syncpkg.ShellFish, false},
+		{"running shell", "", "fish", "/bin/zsh", "linux", syncpkg.ShellFish, false},
+		{"unknown running program", "", "tmux", "/usr/bin/fish", "linux", syncpkg.ShellFish, false},
+		{"login shell", "", "", "/bin/bash", "darwin", syncpkg.ShellPOSIX, false},
+		{"windows without SHELL", "", "", "", "windows", syncpkg.ShellPowerShell, false},
+		{"pwsh.exe", "", "pwsh.exe", "", "windows", syncpkg.ShellPowerShell, false},
+		{"unsupported flag", "tcsh", "", "", "linux", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadActiveSyntax(tt.flag, tt.running, tt.shellEnv, tt.goos)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("loadActiveSyntax() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadActiveFish(t *testing.T) {
+	stdout, err := runEvalCommand(t, "load-active", "--shell", "fish")
+	if err != nil {
+		t.Fatalf("load-active --shell fish: %v", err)
+	}
+	for _, line := range []string{"set -e ANTHROPIC_API_KEY\n", "set -gx ANTHROPIC_API_KEY 'sk-relay-key'\n", "set -g __apimgr_changed "} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("stdout missing %q in:\n%s", line, stdout)
+		}
+	}
+	if strings.Contains(stdout, "export ") {
+		t.Errorf("stdout should not contain POSIX exports:\n%s", stdout)
+	}
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/modelinfo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(modelCmd)
+	modelCmd.AddCommand(modelListCmd)
+	modelCmd.AddCommand(modelUseCmd)
+	modelCmd.AddCommand(modelCurrentCmd)
+	modelCmd.AddCommand(modelSetCmd)
+	modelCmd.AddCommand(modelAddCmd)
+	modelCmd.AddCommand(modelRemoveCmd)
+	modelCmd.AddCommand(modelInfoCmd)
+	modelInfoCmd.Flags().IntVar(&modelInputTokens, "input-tokens", 0, "Input tokens for the cost estimate")
+	modelInfoCmd.Flags().IntVar(&modelOutputTokens, "output-tokens", 0, "Output tokens for the cost estimate")
+}
+
+var (
+	modelInputTokens  int // Input tokens for model info cost estimate
+	modelOutputTokens int // Output tokens for model info cost estimate
+)
+
+var modelCmd = &cobra.Command{
+	Use:     "model",
+	Aliases: []string{"models"},
+	Short:   "Manage the models of a configuration",
+	Long: `List a configuration's supported models, switch its active model, show
+the model currently in use or maintain the supported models list.
+
+Examples:
+  apimgr model list work                  # List models supported by 'work'
+  apimgr model use work claude-sonnet-4   # Make claude-sonnet-4 the active model of 'work'
+  apimgr model current                    # Show the active configuration's model
+  apimgr models set work m1,m2,m3         # Replace the supported models list
+  apimgr models add work m4               # Append models to the list
+  apimgr models remove work m1            # Remove models from the list
+  apimgr model info claude-sonnet-4 --input-tokens 20000 --output-tokens 1000`,
+}
+
+var modelListCmd = &cobra.Command{
+	Use:   "list [alias]",
+	Short: "List the supported models of a configuration",
+	Long:  "List the supported models of a configuration, marking the active one. Defaults to the active configuration.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		alias, err := resolveModelAlias(configManager, args)
+		if err != nil {
+			return err
+		}
+		cfg, err := configManager.Get(alias)
+		if err != nil {
+			return err
+		}
+		supported, err := configManager.GetModels(alias)
+		if err != nil {
+			return err
+		}
+
+		if len(supported) == 0 {
+			fmt.Fprintf(stdout, "No models configured for '%s'. Add some with 'apimgr edit %s --models <list>'\n", alias, alias)
+			return nil
+		}
+
+		table, err := modelinfo.Load(configManager.GetConfigPath())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "Models for '%s':\n", alias)
+		for _, model := range supported {
+			marker := " "
+			if model == cfg.Model {
+				marker = "*"
+			}
+			if info, ok := table.Lookup(model); ok {
+				fmt.Fprintf(stdout, "%s %s (%s)\n", marker, model, describeModelInfo(info))
+			} else {
+				fmt.Fprintf(stdout, "%s %s\n", marker, model)
+			}
+		}
+		fmt.Fprintf(stdout, "\n* indicates the active model\n")
+		return nil
+	},
+}
+
+var modelUseCmd = &cobra.Command{
+	Use:         "use <alias> <model>",
+	Annotations: mutates,
+	Short:       "Switch the active model of a configuration",
+	Long: `Switch the active model of a configuration. The model must be in the
+configuration's supported models list. When the configuration is globally
+active, active.env and Claude Code settings are updated too.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		if err := configManager.SwitchModel(alias, args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✓ Switched model of '%s' to: %s\n", alias, args[1])
+		return nil
+	},
+}
+
+var modelCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the model of the active configuration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		cfg, err := configManager.GetActive()
+		if err != nil {
+			return err
+		}
+		if cfg.Model == "" {
+			return fmt.Errorf("configuration '%s' has no active model", cfg.Alias)
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", cfg.Alias, cfg.Model)
+		return nil
+	},
+}
+
+var modelInfoCmd = &cobra.Command{
+	Use:   "info [model]",
+	Short: "Show capabilities and pricing of a model",
+	Long: `Show the context window, vision and tool support and list price of a model,
+and estimate the cost of a request with --input-tokens and --output-tokens.
+Defaults to the active configuration's model.
+
+Metadata is bundled with apimgr. Add or correct entries in model_info.json
+next to the config file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		var model string
+		if len(args) == 1 {
+			model = args[0]
+		} else {
+			cfg, err := configManager.GetActive()
+			if err != nil {
+				return err
+			}
+			if cfg.Model == "" {
+				return fmt.Errorf("configuration '%s' has no active model", cfg.Alias)
+			}
+			model = cfg.Model
+		}
+
+		table, err := modelinfo.Load(configManager.GetConfigPath())
+		if err != nil {
+			return err
+		}
+		info, ok := table.Lookup(model)
+		if !ok {
+			return fmt.Errorf("no metadata for model '%s', add it to %s next to the config file", model, modelinfo.OverrideFileName)
+		}
+
+		fmt.Fprintf(stdout, "Model:          %s\n", model)
+		fmt.Fprintf(stdout, "Context window: %s tokens\n", modelinfo.FormatTokens(info.ContextWindow))
+		fmt.Fprintf(stdout, "Vision:         %s\n", yesNo(info.Vision))
+		fmt.Fprintf(stdout, "Tool use:       %s\n", yesNo(info.Tools))
+		fmt.Fprintf(stdout, "Price:          %s input / %s output per million tokens\n",
+			modelinfo.FormatPrice(info.InputPrice), modelinfo.FormatPrice(info.OutputPrice))
+		if modelInputTokens > 0 || modelOutputTokens > 0 {
+			fmt.Fprintf(stdout, "Estimated cost: $%.4f for %d input and %d output tokens\n",
+				info.EstimateCost(modelInputTokens, modelOutputTokens), modelInputTokens, modelOutputTokens)
+		}
+		return nil
+	},
+}
+
+// describeModelInfo summarizes model metadata on one line
+func describeModelInfo(info modelinfo.Info) string {
+	parts := []string{modelinfo.FormatTokens(info.ContextWindow) + " context"}
+	if info.Vision {
+		parts = append(parts, "vision")
+	}
+	if info.Tools {
+		parts = append(parts, "tools")
+	}
+	if info.InputPrice > 0 || info.OutputPrice > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s per MTok", modelinfo.FormatPrice(info.InputPrice), modelinfo.FormatPrice(info.OutputPrice)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// yesNo formats a boolean for display
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var modelSetCmd = &cobra.Command{
+	Use:         "set <alias> <models>",
+	Annotations: mutates,
+	Short:       "Replace the supported models list of a configuration",
+	Long: `Replace the supported models list with a comma-separated list. If the
+active model is no longer in the list, the first model becomes active.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateModels(args[0], func(current []string) ([]string, error) {
+			return parseModelsList(args[1]), nil
+		})
+	},
+}
+
+var modelAddCmd = &cobra.Command{
+	Use:         "add <alias> <model>...",
+	Annotations: mutates,
+	Short:       "Add models to the supported models list",
+	Args:        cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateModels(args[0], func(current []string) ([]string, error) {
+			return append(current, args[1:]...), nil
+		})
+	},
+}
+
+var modelRemoveCmd = &cobra.Command{
+	Use:         "remove <alias> <model>...",
+	Annotations: mutates,
+	Short:       "Remove models from the supported models list",
+	Long: `Remove models from the supported models list. If the active model is
+removed, the first remaining model becomes active. The last model cannot be removed.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateModels(args[0], func(current []string) ([]string, error) {
+			return removeModels(current, args[1:])
+		})
+	},
+}
+
+// updateModels replaces a configuration's supported models with the list
+// returned by change, reporting when the active model had to fall back
+func updateModels(pattern string, change func(current []string) ([]string, error)) error {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	alias, err := configManager.ResolveAlias(pattern)
+	if err != nil {
+		return err
+	}
+	before, err := configManager.Get(alias)
+	if err != nil {
+		return err
+	}
+
+	updated, err := change(before.Models)
+	if err != nil {
+		return err
+	}
+	if err := configManager.SetModels(alias, updated); err != nil {
+		return err
+	}
+
+	after, err := configManager.Get(alias)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✓ Models for '%s': %s\n", alias, formatModelsDisplay(after.Models, after.Model))
+	if msg := modelFallbackMessage(before, after); msg != "" {
+		fmt.Fprintln(stdout, msg)
+	}
+	return nil
+}
+
+// removeModels returns current without the removed models, failing when a
+// model is not in the list
+func removeModels(current, removed []string) ([]string, error) {
+	drop := make(map[string]bool, len(removed))
+	for _, model := range parseModelsList(strings.Join(removed, ",")) {
+		if !slices.Contains(current, model) {
+			return nil, fmt.Errorf("model '%s' is not in supported models list: %v", model, current)
+		}
+		drop[model] = true
+	}
+
+	var kept []string
+	for _, model := range current {
+		if !drop[model] {
+			kept = append(kept, model)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("cannot remove every model, at least one must remain")
+	}
+	return kept, nil
+}
+
+// modelFallbackMessage explains an active model change caused by a models
+// list update, or returns an empty string when the active model was kept
+func modelFallbackMessage(before, after *models.APIConfig) string {
+	if before.Model == after.Model {
+		return ""
+	}
+	if before.Model == "" {
+		return fmt.Sprintf("Active model set to '%s'", after.Model)
+	}
+	return fmt.Sprintf("Active model '%s' is no longer supported, switched to '%s'", before.Model, after.Model)
+}
+
+// resolveModelAlias returns the alias argument, or the active configuration
+// when none was given
+func resolveModelAlias(configManager *config.Manager, args []string) (string, error) {
+	if len(args) == 1 {
+		return configManager.ResolveAlias(args[0])
+	}
+	cfg, err := configManager.GetActive()
+	if err != nil {
+		return "", err
+	}
+	return cfg.Alias, nil
+}
@@ -46,21 +46,21 @@ func (ms *ModelSelector) ShouldPrompt(cfg *models.APIConfig, modelFlag string, n
 // Users can select by number or press Enter to use the current model
 func (ms *ModelSelector) PromptSimple(models []string, currentModel string) (string, error) {
 	// Create a reader for terminal input
-	reader := bufio.NewReader(os.Stdin)
+	reader := bufio.NewReader(stdin)
 
 	// Display the available models
-	fmt.Fprintln(os.Stderr, "📋 Available models:")
+	fmt.Fprintln(stderr, "📋 Available models:")
 	for i, model := range models {
 		selection := fmt.Sprintf("  %2d. %s", i+1, model)
 		if model == currentModel {
 			selection = fmt.Sprintf("  ➤ %2d. %s (current)", i+1, model)
 		}
-		fmt.Fprintln(os.Stderr, selection)
+		fmt.Fprintln(stderr, selection)
 	}
 
 	// Prompt the user
 	prompt := fmt.Sprintf("\nSelect model (1-%d) [Enter to use '%s']: ", len(models), currentModel)
-	fmt.Fprint(os.Stderr, prompt)
+	fmt.Fprint(stderr, prompt)
 
 	// Read user input
 	input, err := reader.ReadString('\n')
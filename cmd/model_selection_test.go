@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"os"
 	"strings"
 	"testing"
 
@@ -81,12 +80,9 @@ func TestPromptSimple_有效输入(t *testing.T) {
 	// Arrange
 	// Mock stdin for this test
 	testInput := "2\n" // Select the second model
-	oldStdin := os.Stdin
-	r, w, _ := os.Pipe()
-	os.Stdin = r
-	w.WriteString(testInput)
-	w.Close()
-	defer func() { os.Stdin = oldStdin }()
+	oldStdin := stdin
+	stdin = strings.NewReader(testInput)
+	defer func() { stdin = oldStdin }()
 
 	ms := NewModelSelector()
 	models := []string{"model-1", "model-2", "model-3"}
@@ -108,12 +104,9 @@ func TestPromptSimple_空输入使用默认值(t *testing.T) {
 	// Arrange
 	// Mock stdin for this test
 	testInput := "\n" // Press Enter
-	oldStdin := os.Stdin
-	r, w, _ := os.Pipe()
-	os.Stdin = r
-	w.WriteString(testInput)
-	w.Close()
-	defer func() { os.Stdin = oldStdin }()
+	oldStdin := stdin
+	stdin = strings.NewReader(testInput)
+	defer func() { stdin = oldStdin }()
 
 	ms := NewModelSelector()
 	models := []string{"model-1", "model-2", "model-3"}
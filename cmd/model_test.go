@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/modelinfo"
+)
+
+func TestModelCmd(t *testing.T) {
+	t.Run("Subcommands", func(t *testing.T) {
+		want := map[string]bool{"list": false, "use": false, "current": false, "set": false, "add": false, "remove": false, "info": false}
+		for _, sub := range modelCmd.Commands() {
+			if _, ok := want[sub.Name()]; ok {
+				want[sub.Name()] = true
+			}
+		}
+		for name, found := range want {
+			if !found {
+				t.Errorf("model command is missing subcommand %q", name)
+			}
+		}
+	})
+
+	t.Run("Argument validation", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			cmd     func([]string) error
+			args    []string
+			wantErr bool
+		}{
+			{name: "list without alias", cmd: func(a []string) error { return modelListCmd.Args(modelListCmd, a) }, args: nil},
+			{name: "list with two aliases", cmd: func(a []string) error { return modelListCmd.Args(modelListCmd, a) }, args: []string{"a", "b"}, wantErr: true},
+			{name: "use with alias and model", cmd: func(a []string) error { return modelUseCmd.Args(modelUseCmd, a) }, args: []string{"work", "m1"}},
+			{name: "use without model", cmd: func(a []string) error { return modelUseCmd.Args(modelUseCmd, a) }, args: []string{"work"}, wantErr: true},
+			{name: "current with argument", cmd: func(a []string) error { return modelCurrentCmd.Args(modelCurrentCmd, a) }, args: []string{"work"}, wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.cmd(tt.args); (err != nil) != tt.wantErr {
+					t.Errorf("Args(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+				}
+			})
+		}
+	})
+}
+
+func TestRemoveModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []string
+		removed []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "remove one", current: []string{"m1", "m2", "m3"}, removed: []string{"m2"}, want: []string{"m1", "m3"}},
+		{name: "remove comma-separated", current: []string{"m1", "m2", "m3"}, removed: []string{"m1,m3"}, want: []string{"m2"}},
+		{name: "unknown model", current: []string{"m1", "m2"}, removed: []string{"m9"}, wantErr: true},
+		{name: "remove every model", current: []string{"m1"}, removed: []string{"m1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := removeModels(tt.current, tt.removed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("removeModels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("removeModels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelFallbackMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{name: "kept", before: "m1", after: "m1", want: ""},
+		{name: "fallback", before: "m1", after: "m2", want: "Active model 'm1' is no longer supported, switched to 'm2'"},
+		{name: "first model", before: "", after: "m1", want: "Active model set to 'm1'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := modelFallbackMessage(&models.APIConfig{Model: tt.before}, &models.APIConfig{Model: tt.after})
+			if got != tt.want {
+				t.Errorf("modelFallbackMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeModelInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info modelinfo.Info
+		want string
+	}{
+		{name: "full", info: modelinfo.Info{ContextWindow: 200000, Vision: true, Tools: true, InputPrice: 3, OutputPrice: 15}, want: "200K context, vision, tools, $3/$15 per MTok"},
+		{name: "no price", info: modelinfo.Info{ContextWindow: 32000}, want: "32K context"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeModelInfo(tt.info); got != tt.want {
+				t.Errorf("describeModelInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
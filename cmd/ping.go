@@ -1,18 +1,23 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"apimgr/config"
+	"apimgr/config/history"
 	"apimgr/config/models"
 	"apimgr/internal/compatibility"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"apimgr/internal/probe"
 	"apimgr/internal/providers"
 	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
@@ -23,10 +28,19 @@ var (
 	outputJSON    bool
 	requestMethod string
 	timeout       time.Duration
-	testRealAPI   bool   // Test real API functionality (simulate ClaudeCode usage)
-	apiPath       string // Custom path for real API testing
-	streamTest    bool   // Test streaming mode
-	verboseOutput bool   // Verbose output
+	testRealAPI   bool          // Test real API functionality (simulate ClaudeCode usage)
+	apiPath       string        // Custom path for real API testing
+	streamTest    bool          // Test streaming mode
+	verboseOutput bool          // Verbose output
+	testRetries   int           // Retries after a transient failure (use with -T)
+	testBackoff   time.Duration // Initial delay between retries (use with -T)
+	pingCount     int           // Number of samples for the basic test
+	pingAllModels bool          // Test every model of the configuration (implies -T)
+	pingFormat    string        // Output format of -T and --all-models: text, csv or tsv
+	pingFixURL    bool          // Probe the base URL with and without /v1 and save the form that works
+	contextProbe  int           // Prompt size in tokens of the context window check (use with -T)
+	visionCheck   bool          // Check that the endpoint accepts image input (use with -T)
+	fidelityCheck bool          // Check that system prompts and stop sequences are honored (use with -T)
 )
 
 var pingCmd = &cobra.Command{
@@ -37,8 +51,9 @@ var pingCmd = &cobra.Command{
 1. Test active configuration:
    apimgr ping
 
-2. Test specific configuration:
+2. Test specific configuration (unique prefixes and globs are accepted):
    apimgr ping my-config
+   apimgr ping my*
 
 3. Test custom URL:
    apimgr ping -u https://api.example.com
@@ -46,19 +61,69 @@ var pingCmd = &cobra.Command{
 4. Test real API compatibility with Claude Code:
    apimgr ping -T [alias]
    apimgr ping -T --stream [alias]  # Include streaming test
-   apimgr ping -T -v [alias]        # Verbose output`,
+   apimgr ping -T -v [alias]        # Verbose output
+   apimgr ping -T --context-probe   # Check that ~100k-token prompts get through
+   apimgr ping -T --vision          # Check that image input is accepted
+   apimgr ping -T --fidelity        # Check system prompt and stop sequence support
+   apimgr ping --all-models [alias] # Pass/fail for each model in the list
+
+5. Fix the /v1 path prefix of the base URL:
+   apimgr ping --fix-url [alias]    # Probe with and without /v1, save what works
+
+With -T, --format csv or tsv writes one row per check under a header row;
+with --all-models, one row per model. --format template='...' prints the
+result with a Go template instead: with -T over the test result, such as
+{{.Alias}} {{.CompatibilityLevel}} {{.ResponseTime}}, with --all-models once
+per model, such as {{.Model}} {{.Success}} {{.ResponseTimeMs}}.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runPingCommand,
 }
 
 func runPingCommand(cmd *cobra.Command, args []string) error {
-	configManager, err := config.NewConfigManager()
+	comma, err := formatDelimiter(pingFormat)
+	if err != nil {
+		return err
+	}
+	tmpl, err := parseFormatTemplate(pingFormat)
+	if err != nil {
+		return err
+	}
+	formatted := comma != 0 || tmpl != nil
+	if formatted && outputJSON {
+		return exitcode.New(exitcode.Usage, "--format cannot be used with --json")
+	}
+	if formatted && !testRealAPI && !pingAllModels {
+		return exitcode.New(exitcode.Usage, "--format %s needs -T or --all-models", pingFormat)
+	}
+	for _, name := range []string{"context-probe", "vision", "fidelity"} {
+		if cmd.Flags().Changed(name) && (!testRealAPI || pingAllModels) {
+			return exitcode.New(exitcode.Usage, "--%s needs -T and cannot be used with --all-models", name)
+		}
+	}
+	if contextProbe < 0 {
+		return exitcode.New(exitcode.Usage, "invalid --context-probe %d: must not be negative", contextProbe)
+	}
+
+	configManager, err := newConfigManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize config manager: %w", err)
 	}
 
+	// Resolve alias prefixes and patterns to a single configuration
+	if len(args) == 1 {
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		args = []string{alias}
+	}
+
+	if pingFixURL {
+		return runFixURL(cmd, args, configManager)
+	}
+
 	// If -T flag is set, use the compatibility tester
-	if testRealAPI {
+	if testRealAPI || pingAllModels {
 		return runCompatibilityTest(cmd, args, configManager)
 	}
 
@@ -92,62 +157,372 @@ func runCompatibilityTest(cmd *cobra.Command, args []string, configManager *conf
 		alias = cfg.Alias
 	}
 
-	if !outputJSON {
-		fmt.Printf("Testing API compatibility for: %s\n", alias)
+	comma, _ := formatDelimiter(pingFormat)
+	tmpl, _ := parseFormatTemplate(pingFormat)
+	if !outputJSON && comma == 0 && tmpl == nil && !pingAllModels {
+		fmt.Fprintf(stdout, "Testing API compatibility for: %s\n", alias)
 	}
+	printTLSWarnings(cfg)
 
-	// Create tester with options
-	opts := []compatibility.TesterOption{
-		compatibility.WithVerbose(verboseOutput),
+	opts, err := testerOptions(cmd, configManager)
+	if err != nil {
+		return err
 	}
-	if apiPath != "" {
-		opts = append(opts, compatibility.WithCustomPath(apiPath))
+	// Rejected or rate-limited keys of a pool are retried with the next one
+	if len(cfg.APIKeys) > 0 {
+		opts = append(opts, compatibility.WithKeyRotation(func(key string, status int) (string, bool) {
+			return configManager.NextKey(alias, key, status)
+		}))
+	}
+
+	if pingAllModels {
+		return runModelMatrix(configManager, cfg, opts)
 	}
 
 	tester, err := compatibility.NewTester(cfg, opts...)
 	if err != nil {
 		if outputJSON {
 			errData, _ := json.Marshal(map[string]interface{}{
-				"error":   err.Error(),
+				"error":   utils.Redact(err.Error()),
 				"success": false,
 			})
-			fmt.Println(string(errData))
+			fmt.Fprintln(stdout, string(errData))
 		}
 		return err
 	}
 
 	// Run the compatibility test
 	result, err := tester.RunFullTest(streamTest)
+	if result != nil {
+		recordHistory(configManager, cfg.Alias, history.Entry{
+			Kind:      history.KindTest,
+			Success:   result.Success,
+			LatencyMs: result.ResponseTime.Milliseconds(),
+			Detail:    result.CompatibilityLevel,
+		})
+	}
 	if err != nil {
 		if outputJSON {
 			errData, _ := json.Marshal(map[string]interface{}{
-				"error":   err.Error(),
+				"error":   utils.Redact(err.Error()),
 				"success": false,
 			})
-			fmt.Println(string(errData))
+			fmt.Fprintln(stdout, string(errData))
 		}
 		return err
 	}
 
-	// Create reporter and output results
-	reporter := compatibility.NewReporter(
-		os.Stdout,
-		compatibility.WithJSONOutput(outputJSON),
-		compatibility.WithVerboseOutput(verboseOutput),
-	)
-
-	if err := reporter.Report(result); err != nil {
-		return fmt.Errorf("error reporting results: %w", err)
+	if tmpl != nil {
+		data := testTemplateData{TestResult: *result, Alias: cfg.Alias, Model: cfg.Model}
+		if err := writeTemplate(stdout, tmpl, data); err != nil {
+			return err
+		}
+	} else {
+		// Create reporter and output results
+		reporter := compatibility.NewReporter(
+			stdout,
+			compatibility.WithJSONOutput(outputJSON),
+			compatibility.WithDelimitedOutput(comma),
+			compatibility.WithVerboseOutput(verboseOutput),
+		)
+
+		if err := reporter.Report(result); err != nil {
+			return fmt.Errorf("error reporting results: %w", err)
+		}
 	}
 
 	// Determine exit code based on compatibility level
 	_, exitCode := compatibility.DetermineCompatibilityLevel(result.Checks)
 	if exitCode != 0 {
-		os.Exit(exitCode)
+		return exitcode.Silent(exitCode)
+	}
+	return nil
+}
+
+// testTemplateData is what ping -T --format template=... executes on
+type testTemplateData struct {
+	compatibility.TestResult
+	Alias string
+	Model string
+}
+
+// modelResult is the outcome of the basic compatibility test for one model
+type modelResult struct {
+	Model              string `json:"model"`
+	Success            bool   `json:"success"`
+	CompatibilityLevel string `json:"compatibilityLevel"`
+	ResponseTimeMs     int64  `json:"responseTimeMs"`
+	Error              string `json:"error,omitempty"`
+}
+
+// testerOptions returns the options of the compatibility tester, config
+// file defaults first so flags win
+func testerOptions(cmd *cobra.Command, configManager *config.Manager) ([]compatibility.TesterOption, error) {
+	settings, err := configManager.GetTestSettings()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := compatibility.OptionsFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, compatibility.WithVerbose(verboseOutput), compatibility.WithLog(stderr))
+	if apiPath != "" {
+		opts = append(opts, compatibility.WithCustomPath(apiPath))
+	}
+	if cmd.Flags().Changed("timeout") {
+		opts = append(opts, compatibility.WithTimeout(timeout))
+	}
+	if cmd.Flags().Changed("retries") {
+		opts = append(opts, compatibility.WithRetries(testRetries))
+	}
+	if cmd.Flags().Changed("backoff") {
+		opts = append(opts, compatibility.WithBackoff(testBackoff))
+	}
+	if cmd.Flags().Changed("context-probe") {
+		opts = append(opts, compatibility.WithContextProbe(contextProbe))
+	}
+	if visionCheck {
+		opts = append(opts, compatibility.WithVisionCheck(true))
+	}
+	if fidelityCheck {
+		opts = append(opts, compatibility.WithFidelityChecks(true))
+	}
+	return opts, nil
+}
+
+// runFixURL probes the base URL of a configuration with and without the /v1
+// path prefix and saves the form whose endpoint answered
+func runFixURL(cmd *cobra.Command, args []string, configManager *config.Manager) error {
+	if cmd.Flags().Changed("url") || testRealAPI || pingAllModels || apiPath != "" {
+		return exitcode.New(exitcode.Usage, "--fix-url cannot be used with -u, -T, --all-models or --path")
+	}
+
+	var cfg *models.APIConfig
+	var err error
+	if len(args) == 1 {
+		cfg, err = configManager.Get(args[0])
+	} else {
+		cfg, err = configManager.GetActive()
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.BaseURL == "" {
+		return exitcode.New(exitcode.Validation, "configuration '%s' uses the provider's default base URL, there is nothing to fix", cfg.Alias)
+	}
+	if cfg.ChatPath != "" {
+		return exitcode.New(exitcode.Validation, "configuration '%s' sets chat_path %s, which --fix-url does not probe", cfg.Alias, cfg.ChatPath)
+	}
+
+	opts, err := testerOptions(cmd, configManager)
+	if err != nil {
+		return err
+	}
+	tester, err := compatibility.NewTester(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	if !outputJSON {
+		fmt.Fprintf(stdout, "Probing the base URL of '%s' with and without /v1...\n", cfg.Alias)
+	}
+	resolution, resolveErr := tester.ResolveBaseURL()
+
+	fixed := ""
+	if resolveErr == nil && resolution.BaseURL != utils.NormalizeBaseURL(cfg.BaseURL) {
+		fixed = resolvedTemplate(rawBaseURL(configManager, cfg), resolution.BaseURL)
+		if err := configManager.UpdatePartial(cfg.Alias, map[string]string{"base_url": fixed}); err != nil {
+			return err
+		}
+	}
+
+	if outputJSON {
+		data, _ := json.Marshal(map[string]interface{}{
+			"alias":   cfg.Alias,
+			"baseUrl": cfg.BaseURL,
+			"fixed":   fixed,
+			"probes":  resolution.Probes,
+			"success": resolveErr == nil,
+		})
+		fmt.Fprintln(stdout, string(data))
+	} else {
+		for _, probe := range resolution.Probes {
+			mark := "✗"
+			if probe.Found {
+				mark = "✓"
+			}
+			fmt.Fprintf(stdout, "  %s %s (HTTP %d)\n", mark, probe.URL, probe.StatusCode)
+		}
+	}
+
+	if resolveErr != nil {
+		// Without both probes the request itself failed
+		if len(resolution.Probes) < 2 {
+			return exitcode.New(exitcode.Network, "%v", resolveErr)
+		}
+		return exitcode.New(exitcode.Validation, "%v", resolveErr)
+	}
+	if outputJSON {
+		return nil
+	}
+	if fixed != "" {
+		fmt.Fprintf(stdout, "✓ Updated the base URL of '%s': %s -> %s\n", cfg.Alias, cfg.BaseURL, fixed)
+	} else {
+		fmt.Fprintf(stdout, "✓ The base URL of '%s' needs no change\n", cfg.Alias)
+	}
+	return nil
+}
+
+// rawBaseURL returns the base URL of cfg as written in the config file, with
+// its placeholders, or the effective one when it is inherited
+func rawBaseURL(configManager *config.Manager, cfg *models.APIConfig) string {
+	configs, err := configManager.Load()
+	if err != nil {
+		return cfg.BaseURL
+	}
+	for _, raw := range configs {
+		if raw.Alias == cfg.Alias && raw.BaseURL != "" {
+			return raw.BaseURL
+		}
+	}
+	return cfg.BaseURL
+}
+
+// resolvedTemplate applies the /v1 form of a resolved base URL to the
+// configured one, keeping its placeholders
+func resolvedTemplate(configured, resolved string) string {
+	root := strings.TrimSuffix(utils.NormalizeBaseURL(configured), "/v1")
+	if strings.HasSuffix(resolved, "/v1") {
+		return root + "/v1"
+	}
+	return root
+}
+
+// runModelMatrix runs the basic compatibility test for every model of a
+// configuration and prints a pass/fail row per model
+func runModelMatrix(configManager *config.Manager, cfg *models.APIConfig, opts []compatibility.TesterOption) error {
+	modelList := cfg.Models
+	if len(modelList) == 0 && cfg.Model != "" {
+		modelList = []string{cfg.Model}
+	}
+	if len(modelList) == 0 {
+		return exitcode.New(exitcode.Validation, "configuration '%s' lists no models to test", cfg.Alias)
+	}
+
+	width := 0
+	for _, model := range modelList {
+		width = max(width, len(model))
+	}
+	comma, _ := formatDelimiter(pingFormat)
+	tmpl, _ := parseFormatTemplate(pingFormat)
+	text := !outputJSON && comma == 0 && tmpl == nil
+	if text {
+		fmt.Fprintf(stdout, "Testing %d models for: %s\n", len(modelList), cfg.Alias)
+	}
+
+	results := make([]modelResult, 0, len(modelList))
+	failed := 0
+	var total time.Duration
+	for _, model := range modelList {
+		result := testModel(cfg, model, opts)
+		results = append(results, result)
+		total += time.Duration(result.ResponseTimeMs) * time.Millisecond
+		if !result.Success {
+			failed++
+		}
+		if text {
+			row := fmt.Sprintf("  %s %-*s %6dms  %s", compatibilityMark(result.CompatibilityLevel), width, model, result.ResponseTimeMs, result.Error)
+			fmt.Fprintln(stdout, strings.TrimRight(row, " "))
+		}
+	}
+
+	recordHistory(configManager, cfg.Alias, history.Entry{
+		Kind:      history.KindTest,
+		Success:   failed == 0,
+		LatencyMs: total.Milliseconds() / int64(len(results)),
+		Detail:    fmt.Sprintf("%d/%d models passed", len(results)-failed, len(results)),
+	})
+
+	switch {
+	case outputJSON:
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"alias":   cfg.Alias,
+			"models":  results,
+			"success": failed == 0,
+		}, "", "  ")
+		fmt.Fprintln(stdout, string(data))
+	case comma != 0:
+		if err := writeDelimited(stdout, comma, modelResultHeader, modelResultRecords(results)); err != nil {
+			return err
+		}
+	case tmpl != nil:
+		for _, r := range results {
+			if err := writeTemplate(stdout, tmpl, r); err != nil {
+				return err
+			}
+		}
+	default:
+		fmt.Fprintf(stdout, "%d of %d models passed\n", len(results)-failed, len(results))
+	}
+	if failed > 0 {
+		return exitcode.New(exitcode.Failure, "%d of %d models failed", failed, len(results))
 	}
 	return nil
 }
 
+// modelResultHeader names the columns of ping --all-models --format csv|tsv
+var modelResultHeader = []string{"model", "success", "compatibility_level", "response_time_ms", "error"}
+
+// modelResultRecords returns a row per model under modelResultHeader
+func modelResultRecords(results []modelResult) [][]string {
+	records := make([][]string, 0, len(results))
+	for _, r := range results {
+		records = append(records, []string{r.Model, strconv.FormatBool(r.Success), r.CompatibilityLevel, strconv.FormatInt(r.ResponseTimeMs, 10), r.Error})
+	}
+	return records
+}
+
+// testModel runs the basic compatibility test of cfg against one model
+func testModel(cfg *models.APIConfig, model string, opts []compatibility.TesterOption) modelResult {
+	modelCfg := *cfg
+	modelCfg.Model = model
+	outcome := modelResult{Model: model, CompatibilityLevel: compatibility.CompatibilityNone}
+
+	tester, err := compatibility.NewTester(&modelCfg, opts...)
+	if err != nil {
+		outcome.Error = utils.Redact(err.Error())
+		return outcome
+	}
+	result, err := tester.TestBasic()
+	if err != nil {
+		outcome.Error = utils.Redact(err.Error())
+		return outcome
+	}
+
+	outcome.Success = result.Success
+	outcome.CompatibilityLevel = result.CompatibilityLevel
+	outcome.ResponseTimeMs = result.ResponseTime.Milliseconds()
+	outcome.Error = result.Error
+	for _, check := range result.Checks {
+		if outcome.Error == "" && !check.Passed {
+			outcome.Error = check.Message
+		}
+	}
+	outcome.Error = utils.Redact(outcome.Error)
+	return outcome
+}
+
+// compatibilityMark returns the status icon of a compatibility level
+func compatibilityMark(level string) string {
+	switch level {
+	case compatibility.CompatibilityFull:
+		return "✅"
+	case compatibility.CompatibilityPartial:
+		return "⚠️ "
+	}
+	return "❌"
+}
+
 // runBasicConnectivityTest runs the original basic connectivity test
 func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *config.Manager) error {
 	var baseURL string
@@ -158,7 +533,7 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 	case isCustomURL:
 		// Custom URL mode
 		baseURL = customURL
-		fmt.Printf("Testing custom URL: %s\n", baseURL)
+		fmt.Fprintf(stdout, "Testing custom URL: %s\n", baseURL)
 
 	case len(args) == 1:
 		// Specific configuration mode
@@ -174,7 +549,7 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 				baseURL = provider.NormalizeConfig(baseURL)
 			}
 		}
-		fmt.Printf("Testing configuration: %s\n", alias)
+		fmt.Fprintf(stdout, "Testing configuration: %s\n", alias)
 
 	default:
 		// Active configuration mode
@@ -189,17 +564,30 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 				baseURL = provider.NormalizeConfig(baseURL)
 			}
 		}
-		fmt.Printf("Testing active configuration: %s\n", cfg.Alias)
+		fmt.Fprintf(stdout, "Testing active configuration: %s\n", cfg.Alias)
 	}
 
 	// Ensure URL has default value
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com"
-		fmt.Printf("⚠️  Note: Using default URL: %s\n", baseURL)
+		fmt.Fprintf(stdout, "⚠️  Note: Using default URL: %s\n", baseURL)
 	}
 
-	// Perform connectivity test
-	start := time.Now()
+	// Resolve TLS options of the tested configuration (not for custom URL mode)
+	var tlsConfig *tls.Config
+	if !isCustomURL {
+		var tlsCfg *models.APIConfig
+		if len(args) == 1 {
+			tlsCfg, _ = configManager.Get(args[0])
+		} else {
+			tlsCfg, _ = configManager.GetActive()
+		}
+		var err error
+		if tlsConfig, err = utils.NewTLSConfig(tlsCfg); err != nil {
+			return err
+		}
+		printTLSWarnings(tlsCfg)
+	}
 
 	// Create optimized HTTP client (connection pooling + custom timeout)
 	client := &http.Client{
@@ -209,9 +597,22 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 			IdleConnTimeout:       30 * time.Second, // Idle connection timeout
 			TLSHandshakeTimeout:   5 * time.Second,  // TLS handshake timeout
 			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig:       tlsConfig,
 		},
 	}
 
+	// Placeholders without a value would only be reported as a malformed URL
+	if err := utils.CheckPlaceholders(baseURL); err != nil {
+		if outputJSON {
+			errData, _ := json.Marshal(map[string]interface{}{
+				"error":   err.Error(),
+				"success": false,
+			})
+			fmt.Fprintln(stdout, string(errData))
+		}
+		return err
+	}
+
 	// Enhanced URL validation
 	if !utils.ValidateURL(baseURL) {
 		if outputJSON {
@@ -220,7 +621,7 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 				"url":     baseURL,
 				"success": false,
 			})
-			fmt.Println(string(errData))
+			fmt.Fprintln(stdout, string(errData))
 		}
 		return fmt.Errorf("invalid URL format: %s (URL must include http or https protocol and valid hostname)", baseURL)
 	}
@@ -263,10 +664,10 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 		if outputJSON {
 			errData, _ := json.Marshal(map[string]interface{}{
 				"error":   "failed to create request",
-				"message": err.Error(),
+				"message": utils.Redact(err.Error()),
 				"success": false,
 			})
-			fmt.Println(string(errData))
+			fmt.Fprintln(stdout, string(errData))
 		}
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -289,104 +690,211 @@ func runBasicConnectivityTest(cmd *cobra.Command, args []string, configManager *
 		}
 	}
 
+	// The config file's ping_count applies unless --count is given
+	samples := pingCount
+	if !cmd.Flags().Changed("count") {
+		if settings, err := configManager.GetTestSettings(); err == nil && settings != nil && settings.PingCount > 0 {
+			samples = settings.PingCount
+		}
+	}
+	if samples < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+
 	// Progress indicator
 	if !outputJSON {
-		fmt.Print("Connecting... ")
+		fmt.Fprint(stdout, "Connecting... ")
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		if !outputJSON {
-			fmt.Printf("\r") // Clear progress indicator
-		}
-
-		// Categorize errors
-		var errMsg string
-		errStr := err.Error()
-
-		// Check timeout first
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			errMsg = fmt.Sprintf("Request timed out (more than %ds)", int(timeout.Seconds()))
-		} else if strings.Contains(errStr, "connection refused") {
-			errMsg = "Connection refused (server not listening on this port)"
-		} else if strings.Contains(errStr, "network is unreachable") {
-			errMsg = "Network unreachable"
-		} else if strings.Contains(errStr, "EOF") {
-			errMsg = "Connection closed unexpectedly (server may not exist or be unresponsive)"
-		} else if strings.Contains(errStr, "no such host") || strings.Contains(errStr, "NXDOMAIN") {
-			errMsg = "DNS resolution failed (domain does not exist or network configuration error)"
-		} else if strings.Contains(errStr, "invalid URL") || strings.Contains(errStr, "parse error") {
-			errMsg = "Invalid URL format"
-		} else {
-			// Other network errors
-			if netErr, ok := err.(net.Error); ok {
-				errMsg = fmt.Sprintf("Network error: %v", netErr)
-			} else {
-				errMsg = fmt.Sprintf("Request failed: %v", err)
-			}
+	// Send the samples, timing each phase: DNS, TCP connect, TLS handshake and first byte
+	var (
+		durations   []time.Duration
+		succeeded   []probe.Timings // Phases of the samples that got a response
+		failures    int
+		lastErr     error
+		lastTimings probe.Timings
+		statusCode  int
+	)
+	for i := 0; i < samples; i++ {
+		resp, timings, err := probe.Do(client, req)
+		lastTimings = timings
+		if err != nil {
+			failures++
+			lastErr = err
+			continue
 		}
+		resp.Body.Close()
+		durations = append(durations, timings.Total)
+		succeeded = append(succeeded, timings)
+		statusCode = resp.StatusCode
+	}
+	stats := probe.Summarize(durations, failures)
+	// Phases are averaged like latency, over the samples that got a response
+	phases := probe.MeanTimings(succeeded)
 
+	// Clear progress indicator
+	if !outputJSON {
+		fmt.Fprintf(stdout, "\r")
+	}
+
+	if len(durations) == 0 {
+		errMsg := describePingError(lastErr)
+		if cfg != nil {
+			recordHistory(configManager, cfg.Alias, history.Entry{Kind: history.KindPing, Detail: errMsg})
+		}
 		if outputJSON {
 			errData, _ := json.Marshal(map[string]interface{}{
-				"error":   errMsg,
-				"url":     baseURL,
-				"success": false,
+				"error":    errMsg,
+				"url":      baseURL,
+				"phasesMs": phasesMs(lastTimings),
+				"samples":  stats.Samples,
+				"failures": stats.Failures,
+				"success":  false,
 			})
-			fmt.Println(string(errData))
+			fmt.Fprintln(stdout, string(errData))
+		} else if phases := lastTimings.String(); phases != "" {
+			fmt.Fprintf(stdout, "   Completed phases: %s\n", phases)
 		}
-		return fmt.Errorf("connection failed: %s", errMsg)
-	}
-	defer resp.Body.Close()
-
-	duration := time.Since(start)
-
-	// Clear progress indicator
-	if !outputJSON {
-		fmt.Printf("\r")
+		return exitcode.New(exitcode.Network, "connection failed: %s", errMsg)
 	}
 
 	// Output result
-	isSuccess := resp.StatusCode >= 200 && resp.StatusCode < 300
+	isSuccess := statusCode >= 200 && statusCode < 300
+	if cfg != nil {
+		// Any response below 500 means the endpoint is reachable
+		recordHistory(configManager, cfg.Alias, history.Entry{
+			Kind:      history.KindPing,
+			Success:   statusCode < 500,
+			LatencyMs: stats.Avg.Milliseconds(),
+			Detail:    fmt.Sprintf("HTTP %d", statusCode),
+		})
+	}
 	if outputJSON {
 		result := map[string]interface{}{
 			"url":           finalURL,
-			"statusCode":    resp.StatusCode,
-			"statusText":    http.StatusText(resp.StatusCode),
+			"statusCode":    statusCode,
+			"statusText":    http.StatusText(statusCode),
 			"requestMethod": req.Method,
-			"durationMs":    duration.Milliseconds(),
+			"durationMs":    stats.Avg.Milliseconds(),
+			"phasesMs":      phasesMs(phases),
+			"samples":       stats.Samples,
+			"failures":      stats.Failures,
+			"minMs":         stats.Min.Milliseconds(),
+			"avgMs":         stats.Avg.Milliseconds(),
+			"p95Ms":         stats.P95.Milliseconds(),
+			"maxMs":         stats.Max.Milliseconds(),
 			"timeoutMs":     timeout.Milliseconds(),
 			"success":       isSuccess,
 		}
 		data, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(data))
+		fmt.Fprintln(stdout, string(data))
 	} else {
-		fmt.Printf("✅ Connection successful! \n")
-		fmt.Printf("   URL: %s\n", finalURL)
-		fmt.Printf("   Method: %s\n", req.Method)
-		fmt.Printf("   Status Code: %d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
-		fmt.Printf("   Response Time: %dms\n", duration.Milliseconds())
-		fmt.Printf("   Timeout Setting: %s\n", timeout)
+		fmt.Fprintf(stdout, "✅ Connection successful! \n")
+		fmt.Fprintf(stdout, "   URL: %s\n", finalURL)
+		fmt.Fprintf(stdout, "   Method: %s\n", req.Method)
+		fmt.Fprintf(stdout, "   Status Code: %d %s\n", statusCode, http.StatusText(statusCode))
+		if stats.Samples > 1 {
+			fmt.Fprintf(stdout, "   Samples: %d sent, %d failed (%.0f%% failure)\n", stats.Samples, stats.Failures, stats.FailureRate()*100)
+			fmt.Fprintf(stdout, "   Response Time: min %dms / avg %dms / p95 %dms / max %dms\n",
+				stats.Min.Milliseconds(), stats.Avg.Milliseconds(), stats.P95.Milliseconds(), stats.Max.Milliseconds())
+		} else {
+			fmt.Fprintf(stdout, "   Response Time: %dms\n", stats.Avg.Milliseconds())
+		}
+		for _, phase := range phases.Phases() {
+			fmt.Fprintf(stdout, "     %-14s %dms\n", phase.Name+":", phase.Duration.Milliseconds())
+		}
+		fmt.Fprintf(stdout, "   Timeout Setting: %s\n", timeout)
+		if failures > 0 {
+			fmt.Fprintf(stdout, "⚠️  %d of %d samples failed, last error: %s\n", failures, stats.Samples, describePingError(lastErr))
+		}
 
 		// Provide additional tips
 		if !isSuccess {
-			fmt.Printf("⚠️  Note: Server returned non-success status code\n")
-			fmt.Printf("   - This is usually because the API's base URL doesn't support simple HEAD/GET requests\n")
-			fmt.Printf("   - But the API's core functionality may still be available (e.g., POST requests used by ClaudeCode)\n")
-			fmt.Printf("   - Try using this configuration in actual scenarios\n")
+			fmt.Fprintf(stdout, "⚠️  Note: Server returned non-success status code\n")
+			fmt.Fprintf(stdout, "   - This is usually because the API's base URL doesn't support simple HEAD/GET requests\n")
+			fmt.Fprintf(stdout, "   - But the API's core functionality may still be available (e.g., POST requests used by ClaudeCode)\n")
+			fmt.Fprintf(stdout, "   - Try using this configuration in actual scenarios\n")
 		}
 	}
 	return nil
 }
 
+// recordHistory stores a result in the configuration's health history.
+// Failing to record does not fail the test itself.
+func recordHistory(configManager *config.Manager, alias string, entry history.Entry) {
+	entry.Time = time.Now()
+	_ = history.Record(configManager.StateDir(), alias, entry)
+}
+
+// describePingError categorizes a ping request error into a readable message
+func describePingError(err error) string {
+	errStr := err.Error()
+
+	// Check timeout first
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return fmt.Sprintf("Request timed out (more than %ds)", int(timeout.Seconds()))
+	} else if strings.Contains(errStr, "connection refused") {
+		return "Connection refused (server not listening on this port)"
+	} else if strings.Contains(errStr, "network is unreachable") {
+		return "Network unreachable"
+	} else if strings.Contains(errStr, "EOF") {
+		return "Connection closed unexpectedly (server may not exist or be unresponsive)"
+	} else if strings.Contains(errStr, "no such host") || strings.Contains(errStr, "NXDOMAIN") {
+		return "DNS resolution failed (domain does not exist or network configuration error)"
+	} else if strings.Contains(errStr, "invalid URL") || strings.Contains(errStr, "parse error") {
+		return "Invalid URL format"
+	}
+
+	// Other network errors
+	if netErr, ok := err.(net.Error); ok {
+		return utils.Redact(fmt.Sprintf("Network error: %v", netErr))
+	}
+	return utils.Redact(fmt.Sprintf("Request failed: %v", err))
+}
+
 func init() {
 	rootCmd.AddCommand(pingCmd)
 	// Define flag and bind to variable
 	pingCmd.Flags().StringVarP(&customURL, "url", "u", "", "Test custom URL")
 	pingCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "JSON format output")
+	pingCmd.Flags().StringVar(&pingFormat, "format", formatText, "Output format of -T and --all-models: text, csv, tsv or template=<Go template>")
 	pingCmd.Flags().StringVarP(&requestMethod, "method", "X", "HEAD", "Request method")
 	pingCmd.Flags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "Request timeout")
+	pingCmd.Flags().IntVarP(&pingCount, "count", "c", 1, "Number of samples to send for the basic test")
 	pingCmd.Flags().BoolVarP(&testRealAPI, "test", "T", false, "Test real API compatibility with Claude Code")
-	pingCmd.Flags().StringVarP(&apiPath, "path", "p", "", "Custom endpoint path for API testing (e.g.: /v1/chat/completions)")
+	pingCmd.Flags().StringVarP(&apiPath, "path", "p", "", "Custom endpoint path for API testing, overrides the config's chat_path (e.g.: /v1/chat/completions)")
 	pingCmd.Flags().BoolVar(&streamTest, "stream", false, "Include streaming test (use with -T)")
+	pingCmd.Flags().BoolVar(&pingAllModels, "all-models", false, "Run the basic test for every model of the configuration (implies -T)")
 	pingCmd.Flags().BoolVarP(&verboseOutput, "verbose", "v", false, "Verbose output (show request/response details)")
+	pingCmd.Flags().IntVar(&testRetries, "retries", 0, "Retry transient failures (network errors, 429, 5xx) this many times (use with -T)")
+	pingCmd.Flags().BoolVar(&pingFixURL, "fix-url", false, "Probe the base URL with and without /v1 and save the form that works")
+	pingCmd.Flags().DurationVar(&testBackoff, "backoff", compatibility.DefaultBackoff, "Initial delay between retries, doubled each attempt (use with -T)")
+	pingCmd.Flags().IntVar(&contextProbe, "context-probe", 0, "Also send a prompt of about this many tokens to detect truncated or rejected long contexts (use with -T)")
+	pingCmd.Flags().Lookup("context-probe").NoOptDefVal = strconv.Itoa(compatibility.DefaultProbeTokens)
+	pingCmd.Flags().BoolVar(&visionCheck, "vision", false, "Also send a small image to check that the endpoint accepts image input (use with -T)")
+	pingCmd.Flags().BoolVar(&fidelityCheck, "fidelity", false, "Also check that system prompts and stop sequences are honored (use with -T)")
+}
+
+// printTLSWarnings warns when a configuration weakens or customizes TLS
+// verification
+func printTLSWarnings(cfg *models.APIConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.InsecureSkipVerify {
+		notice.Println("⚠️  Warning: TLS certificate verification is disabled (insecure_skip_verify)")
+	}
+	if cfg.CABundle != "" {
+		notice.Printf("⚠️  Note: Using custom CA bundle: %s\n", cfg.CABundle)
+	}
+}
+
+// phasesMs converts probe timings to milliseconds for JSON output
+func phasesMs(timings probe.Timings) map[string]int64 {
+	return map[string]int64{
+		"dns":       timings.DNS.Milliseconds(),
+		"connect":   timings.Connect.Milliseconds(),
+		"tls":       timings.TLS.Milliseconds(),
+		"firstByte": timings.FirstByte.Milliseconds(),
+	}
 }
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+)
+
+func TestTestModel(t *testing.T) {
+	// A relay advertising a model it doesn't serve
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "missing-model") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"type":"not_found_error","message":"model not found"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &models.APIConfig{Alias: "relay", Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+	tests := []struct {
+		model       string
+		wantSuccess bool
+		wantLevel   string
+	}{
+		{model: "served-model", wantSuccess: true, wantLevel: compatibility.CompatibilityFull},
+		{model: "missing-model", wantSuccess: false, wantLevel: compatibility.CompatibilityNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got := testModel(cfg, tt.model, nil)
+			if got.Model != tt.model || got.Success != tt.wantSuccess || got.CompatibilityLevel != tt.wantLevel {
+				t.Errorf("testModel(%q) = %+v, want success %v level %s", tt.model, got, tt.wantSuccess, tt.wantLevel)
+			}
+			if !tt.wantSuccess && got.Error == "" {
+				t.Errorf("testModel(%q) has no error detail", tt.model)
+			}
+		})
+	}
+	if cfg.Model != "" {
+		t.Errorf("testModel() changed the configuration's model to %q", cfg.Model)
+	}
+}
+
+func TestResolvedTemplate(t *testing.T) {
+	tests := []struct {
+		configured string
+		resolved   string
+		want       string
+	}{
+		{"https://relay.example.com/v1/", "https://relay.example.com", "https://relay.example.com"},
+		{"https://relay.example.com", "https://relay.example.com/v1", "https://relay.example.com/v1"},
+		{"https://{region}.relay.example.com/v1", "https://eu.relay.example.com", "https://{region}.relay.example.com"},
+		{"https://relay.example.com/api/v4", "https://relay.example.com/api/v4", "https://relay.example.com/api/v4"},
+	}
+
+	for _, tt := range tests {
+		if got := resolvedTemplate(tt.configured, tt.resolved); got != tt.want {
+			t.Errorf("resolvedTemplate(%q, %q) = %q, want %q", tt.configured, tt.resolved, got, tt.want)
+		}
+	}
+}
+
+func TestPingCount(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want int32
+	}{
+		{name: "default", args: []string{"ping", "--url", server.URL}, want: 1},
+		{name: "several samples", args: []string{"ping", "--url", server.URL, "-c", "3"}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+			requests.Store(0)
+
+			var out bytes.Buffer
+			if err := Run(tt.args, IO{Out: &out, Err: &bytes.Buffer{}}, nil); err != nil {
+				t.Fatalf("ping unexpected error: %v\n%s", err, out.String())
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("ping sent %d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
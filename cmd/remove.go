@@ -1,33 +1,117 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"strings"
 
 	"apimgr/config"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
 	"github.com/spf13/cobra"
 )
 
+var removeForce bool // Skip the typed confirmation of a configuration in use
+
 func init() {
 	rootCmd.AddCommand(removeCmd)
+	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "Remove an active or in-use configuration without typing its alias")
 }
 
 var removeCmd = &cobra.Command{
-	Use:   "remove [alias]",
-	Short: "Remove specified API configuration",
-	Long:  "Remove API configuration with specified alias",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		alias := args[0]
+	Use:         "remove [alias]",
+	Annotations: mutates,
+	Short:       "Remove specified API configuration",
+	Long: `Remove API configuration with specified alias, a unique alias prefix or a glob pattern (e.g. wo*)
+
+Removed configurations are kept in the trash and can be brought back with
+'apimgr trash restore <alias>' until the trash retention runs out.
 
-		configManager, err := config.NewConfigManager()
+Removing the global active configuration, or one a shell runs a local
+session on, asks to type its alias first, then offers to switch to another
+configuration. --force removes it without asking.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
+
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		if !removeForce {
+			use, err := configManager.UseOf(alias)
+			if err != nil {
+				return err
+			}
+			if use.InUse() {
+				if err := confirmRemoval(configManager, alias, use); err != nil {
+					return err
+				}
+			}
+		}
 		if err := configManager.Remove(alias); err != nil {
 			return err
 		}
 
-		fmt.Printf("Configuration removed: %s\n", alias)
+		fmt.Fprintf(stdout, "Configuration removed: %s (restore it with 'apimgr trash restore %s')\n", alias, alias)
 		return nil
 	},
 }
+
+// confirmRemoval asks to type the alias of a configuration in use before
+// removing it and, for the active one, offers to switch to another
+func confirmRemoval(configManager *config.Manager, alias string, use config.ConfigUse) error {
+	if use.Active {
+		fmt.Fprintf(stdout, "'%s' is the active configuration.\n", alias)
+	}
+	if len(use.Sessions) > 0 {
+		fmt.Fprintf(stdout, "'%s' is used by the local sessions of shells %s.\n", alias, strings.Join(use.Sessions, ", "))
+	}
+	fmt.Fprintf(stdout, "Type '%s' to remove it: ", alias)
+	reader := bufio.NewReader(stdin)
+	line, _ := reader.ReadString('\n')
+	if strings.TrimSpace(line) != alias {
+		return exitcode.New(exitcode.Usage, "removal cancelled, the alias was not typed (--force skips the confirmation)")
+	}
+	if !use.Active {
+		return nil
+	}
+
+	configs, err := configManager.List()
+	if err != nil {
+		return err
+	}
+	var others []string
+	for _, cfg := range configs {
+		if cfg.Alias != alias {
+			others = append(others, cfg.Alias)
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	fmt.Fprintf(stdout, "Switch to another configuration (%s), or press Enter to leave none active: ", strings.Join(others, ", "))
+	line, _ = reader.ReadString('\n')
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	target, err := configManager.ResolveAlias(strings.TrimSpace(line))
+	if err != nil {
+		return err
+	}
+	if target == alias {
+		return exitcode.New(exitcode.Usage, "cannot switch to '%s', the configuration being removed", alias)
+	}
+
+	if err := configManager.SetActive(target); err != nil {
+		return err
+	}
+	if err := configManager.GenerateActiveScript(); err != nil {
+		notice.Printf("Warning: Failed to generate activation script: %v\n", err)
+	}
+	fmt.Fprintf(stdout, "Switched to configuration: %s\n", target)
+	return nil
+}
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"slices"
+	"strings"
 	"testing"
+
+	"apimgr/config/models"
 )
 
 func TestRemoveCmd(t *testing.T) {
@@ -50,3 +55,51 @@ func TestRemoveCmd(t *testing.T) {
 		}
 	})
 }
+
+func TestRemoveInUse(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantErr    string
+		wantAlias  []string
+		wantActive string
+	}{
+		{name: "inactive", args: []string{"remove", "home"}, wantAlias: []string{"work", "spare"}, wantActive: "work"},
+		{name: "alias not typed", args: []string{"remove", "work"}, stdin: "yes\n", wantErr: "removal cancelled", wantAlias: []string{"work", "home", "spare"}, wantActive: "work"},
+		{name: "no input", args: []string{"remove", "work"}, wantErr: "removal cancelled", wantAlias: []string{"work", "home", "spare"}, wantActive: "work"},
+		{name: "typed, none active", args: []string{"remove", "work"}, stdin: "work\n\n", wantAlias: []string{"home", "spare"}},
+		{name: "typed, switched", args: []string{"remove", "work"}, stdin: "work\nsp\n", wantAlias: []string{"home", "spare"}, wantActive: "spare"},
+		{name: "switch to itself", args: []string{"remove", "work"}, stdin: "work\nwork\n", wantErr: "being removed", wantAlias: []string{"work", "home", "spare"}, wantActive: "work"},
+		{name: "force", args: []string{"remove", "work", "--force"}, wantAlias: []string{"home", "spare"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+			createIntegrationTestConfig(t, configPath, []models.APIConfig{
+				{Alias: "work", APIKey: "sk-work"},
+				{Alias: "home", APIKey: "sk-home"},
+				{Alias: "spare", APIKey: "sk-spare"},
+			}, "work")
+
+			var out, errOut bytes.Buffer
+			err := Run(tt.args, IO{In: strings.NewReader(tt.stdin), Out: &out, Err: &errOut}, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("remove unexpected error: %v\n%s", err, out.String())
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("remove error = %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			configFile := readConfigFile(t, configPath)
+			var aliases []string
+			for _, cfg := range configFile.Configs {
+				aliases = append(aliases, cfg.Alias)
+			}
+			if !slices.Equal(aliases, tt.wantAlias) || configFile.Active != tt.wantActive {
+				t.Errorf("configs = %v, active %q, want %v, active %q", aliases, configFile.Active, tt.wantAlias, tt.wantActive)
+			}
+		})
+	}
+}
@@ -1,8 +1,22 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"apimgr/config"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/logging"
+	"apimgr/internal/notice"
 	"apimgr/internal/tui"
+	"apimgr/internal/utils"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 )
 
@@ -25,15 +39,115 @@ var rootCmd = &cobra.Command{
 	Short: "API key and model configuration management tool",
 	Long:  "A command line tool for managing Anthropic API keys and model configurations",
 	// Version information will be set in the Execute function
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if config.ReadOnly() && cmd.Annotations["mutates"] != "" {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("cannot run '%s': %w", cmd.CommandPath(), config.ErrReadOnly))
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// When no subcommand is provided, launch the TUI interface
 		// Requirements: 1.1, 1.4
-		return tui.Run()
+		return tui.Run(tuiOptions())
 	},
 }
 
-// Execute executes the root command
+var (
+	configPathFlag string    // Config file selected with --config
+	verboseFlag    bool      // Debug logging on stderr
+	logFileFlag    bool      // Log to apimgr.log in the state directory
+	logCloser      io.Closer // Closes the log file after the command
+	errorFormat    string    // How errors are printed: text or json
+	noColorFlag    bool      // Plain output without colors or styles
+	asciiFlag      bool      // TUI drawn with ASCII symbols only
+	accessibleFlag bool      // TUI for screen readers
+	quietFlag      bool      // Drop notices, see package notice
+	readOnlyFlag   bool      // Refuse changes, see config.ReadOnly
+)
+
+// mutates annotates the commands that change the config file or the synced
+// settings, which read-only mode refuses
+var mutates = map[string]string{"mutates": "true"}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPathFlag, "config", "", "Config file to use instead of the workspace's (or set "+config.ConfigEnvVar+")")
+	rootCmd.PersistentFlags().BoolVar(&verboseFlag, "verbose", false, "Print debug logs to stderr")
+	rootCmd.PersistentFlags().BoolVar(&logFileFlag, "log-file", false, "Append logs to "+logging.FileName+" in the state directory (or set "+logging.FileEnvVar+")")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "Error output format: text, json")
+	rootCmd.Flags().BoolVar(&asciiFlag, "ascii", false, "Draw the TUI with ASCII symbols only (or set "+asciiEnvVar+")")
+	rootCmd.Flags().BoolVar(&accessibleFlag, "accessible", false, "Screen reader mode: linear output and state changes as text (or set "+accessibleEnvVar+")")
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "Disable colors and styles (or set NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress notices and warnings such as confirmations, sync status and migration messages")
+	rootCmd.PersistentFlags().BoolVar(&readOnlyFlag, "read-only", false, "Refuse commands that change the configuration (or set "+config.ReadOnlyEnvVar+")")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
+	cobra.OnInitialize(func() {
+		config.SetConfigPath(configPathFlag)
+		config.SetReadOnly(readOnlyFlag)
+		notice.SetQuiet(quietFlag)
+		setupLogging()
+		if colorDisabled(noColorFlag, os.Getenv) {
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
+	})
+}
+
+// asciiEnvVar enables ASCII rendering like --ascii when set to a non-empty value
+const asciiEnvVar = "APIMGR_ASCII"
+
+// asciiEnabled reports whether the TUI replaces box-drawing characters,
+// arrows and emoji: with --ascii, APIMGR_ASCII or TERM=dumb
+func asciiEnabled(flag bool, getenv func(string) string) bool {
+	return flag || getenv(asciiEnvVar) != "" || getenv("TERM") == "dumb"
+}
+
+// accessibleEnvVar enables screen reader mode like --accessible when set to a
+// non-empty value
+const accessibleEnvVar = "APIMGR_ACCESSIBLE"
+
+// tuiOptions returns the TUI options selected by flags and the environment
+func tuiOptions() tui.Options {
+	return tui.Options{
+		ASCII:      asciiEnabled(asciiFlag, os.Getenv),
+		Accessible: accessibleFlag || os.Getenv(accessibleEnvVar) != "",
+	}
+}
+
+// colorDisabled reports whether output should be plain: with --no-color, a
+// non-empty NO_COLOR (https://no-color.org) or TERM=dumb
+func colorDisabled(flag bool, getenv func(string) string) bool {
+	return flag || getenv("NO_COLOR") != "" || getenv("TERM") == "dumb"
+}
+
+// setupLogging installs the logger selected by --verbose and --log-file.
+// ping's own -v/--verbose enables debug logs too.
+func setupLogging() {
+	opts := logging.Options{Verbose: verboseFlag || verboseOutput}
+	if logFileFlag || os.Getenv(logging.FileEnvVar) != "" {
+		stateDir, err := config.ResolveStateDir()
+		if err != nil {
+			notice.Printf("Warning: Failed to locate log file: %v\n", err)
+		} else {
+			opts.File = filepath.Join(stateDir, logging.FileName)
+		}
+	}
+
+	closer, err := logging.Setup(opts)
+	if err != nil {
+		notice.Printf("Warning: %v\n", err)
+		closer, _ = logging.Setup(logging.Options{Verbose: opts.Verbose})
+	}
+	logCloser = closer
+}
+
+// Execute executes the root command with the process's arguments
 func Execute() error {
+	return execute(os.Args[1:])
+}
+
+// execute executes the root command with args
+func execute(args []string) error {
 	// Set version info
 	rootCmd.Version = version
 
@@ -43,5 +157,67 @@ Commit: ` + commit + `
 Date: ` + date + `
 `)
 
-	return rootCmd.Execute()
+	addCompletionInstall()
+
+	rootCmd.SetArgs(args)
+	rootCmd.SetIn(stdin)
+	rootCmd.SetOut(stdout)
+	// Errors may quote requests or configs, never print keys in them
+	rootCmd.SetErr(utils.NewRedactWriter(stderr))
+
+	// ExitWithError prints errors, so cobra does not print them twice.
+	// Cobra prints the usage text to the output stream, which is stdout
+	// here, so it is printed below instead.
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	ran, err := rootCmd.ExecuteC()
+	// Flags are parsed inside ExecuteC, so look for --error-format in args
+	// to skip the usage text, also skipped for unknown commands like cobra
+	if _, _, findErr := rootCmd.Find(args); err != nil && findErr == nil && !jsonErrors(args) && !ran.SilenceUsage {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), ran.UsageString())
+	}
+	if logCloser != nil {
+		logCloser.Close()
+		logCloser = nil
+	}
+	if err != nil {
+		return exitcode.Wrap(exitcode.Of(err), errors.New(utils.Redact(err.Error())))
+	}
+	return nil
+}
+
+// ExitWithError prints err as selected by --error-format and exits with
+// its exit code
+func ExitWithError(err error) {
+	code := exitcode.Of(err)
+	if err.Error() == "" {
+		// Silent: the command has reported the failure itself
+		os.Exit(code)
+	}
+	if jsonErrors(os.Args[1:]) {
+		data, _ := json.Marshal(map[string]any{
+			"error": err.Error(),
+			"code":  code,
+			"kind":  exitcode.Name(code),
+		})
+		fmt.Fprintln(stderr, string(data))
+	} else {
+		// Never to stdout, which 'eval "$(apimgr switch -l ...)"' runs
+		fmt.Fprintln(stderr, "Error:", err)
+	}
+	os.Exit(code)
+}
+
+// jsonErrors reports whether args select --error-format json
+func jsonErrors(args []string) bool {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--error-format=json" || (arg == "--error-format" && i+1 < len(args) && args[i+1] == "json") {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestColorDisabled(t *testing.T) {
+	tests := []struct {
+		name string
+		flag bool
+		env  map[string]string
+		want bool
+	}{
+		{name: "default", env: map[string]string{"TERM": "xterm-256color"}, want: false},
+		{name: "flag", flag: true, env: map[string]string{"TERM": "xterm-256color"}, want: true},
+		{name: "NO_COLOR", env: map[string]string{"NO_COLOR": "1"}, want: true},
+		{name: "empty NO_COLOR", env: map[string]string{"NO_COLOR": ""}, want: false},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := colorDisabled(tt.flag, getenv); got != tt.want {
+				t.Errorf("colorDisabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestASCIIEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		flag bool
+		env  map[string]string
+		want bool
+	}{
+		{name: "default", env: map[string]string{"TERM": "xterm-256color"}, want: false},
+		{name: "flag", flag: true, want: true},
+		{name: "env", env: map[string]string{asciiEnvVar: "1"}, want: true},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := asciiEnabled(tt.flag, getenv); got != tt.want {
+				t.Errorf("asciiEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{{Alias: "work", APIKey: "sk-work"}}, "")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     string
+		wantErr bool
+	}{
+		{name: "flag refuses add", args: []string{"--read-only", "add", "other", "--sk", "sk-other"}, wantErr: true},
+		{name: "environment refuses switch", args: []string{"switch", "work"}, env: "1", wantErr: true},
+		{name: "environment refuses model use", args: []string{"model", "use", "work", "m1"}, env: "1", wantErr: true},
+		{name: "reading is allowed", args: []string{"--read-only", "list"}},
+		{name: "off again without the flag", args: []string{"set", "work", "model=m1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.ReadOnlyEnvVar, tt.env)
+			err := Run(tt.args, IO{}, nil)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("apimgr %s unexpected error: %v", strings.Join(tt.args, " "), err)
+				}
+				return
+			}
+			// Errors are redacted into new ones, so only the message is left
+			if err == nil || !strings.Contains(err.Error(), "read-only mode") {
+				t.Errorf("apimgr %s error = %v, want the read-only error", strings.Join(tt.args, " "), err)
+			}
+			if exitcode.Of(err) != exitcode.Usage {
+				t.Errorf("exit code = %d, want %d", exitcode.Of(err), exitcode.Usage)
+			}
+		})
+	}
+
+	if configFile := readConfigFile(t, configPath); len(configFile.Configs) != 1 || configFile.Active != "" {
+		t.Errorf("config file changed in read-only mode: %+v", configFile)
+	}
+}
+
+func TestQuietDropsWarnings(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{{Alias: "work", APIKey: "sk-work"}}, "")
+	// A config file others can read is warned about on every command
+	if err := os.Chmod(configPath, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "warned", args: []string{"list"}, want: true},
+		{name: "quiet", args: []string{"-q", "list"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			if err := Run(tt.args, IO{Out: &out, Err: &errOut}, nil); err != nil {
+				t.Fatalf("apimgr %s unexpected error: %v", strings.Join(tt.args, " "), err)
+			}
+			if got := strings.Contains(errOut.String(), "loose permissions"); got != tt.want {
+				t.Errorf("stderr = %q, want the permission warning: %v", errOut.String(), tt.want)
+			}
+			if strings.Contains(out.String(), "loose permissions") {
+				t.Errorf("stdout = %q, want no warning", out.String())
+			}
+		})
+	}
+}
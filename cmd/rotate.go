@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/internal/admin"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rotateProvider string // Admin API to use, the provider of the configuration when empty
+	rotateNewKey   string // Replacement key, "-" reads it from stdin
+	rotateAdminKey string // Admin key, the provider's admin key variable when empty
+	rotateDryRun   bool   // Check everything without changing anything
+)
+
+// newKeyAdmin returns the admin API client of a provider, replaced in tests
+var newKeyAdmin = admin.New
+
+func init() {
+	rootCmd.AddCommand(rotateCmd)
+	rotateCmd.Flags().StringVar(&rotateProvider, "provider", "", "Provider whose admin API manages the key (default: the configuration's provider)")
+	rotateCmd.Flags().StringVar(&rotateNewKey, "new-key", "", "Replacement API key, or - to read it from stdin")
+	rotateCmd.Flags().StringVar(&rotateAdminKey, "admin-key", "", "Admin API key (default: $ANTHROPIC_ADMIN_KEY)")
+	rotateCmd.Flags().BoolVar(&rotateDryRun, "dry-run", false, "Look up the old key and test the new one without changing anything")
+}
+
+var rotateCmd = &cobra.Command{
+	Use:         "rotate <alias> --new-key <key>",
+	Annotations: mutates,
+	Short:       "Replace the API key of a configuration and revoke the old one",
+	Long: `Replace the API key of a configuration and revoke the old key through the
+provider's admin API:
+
+1. The old key is looked up among the organization's active keys
+2. The new key is tested with a basic compatibility test
+3. The configuration is updated, and the settings are synced when it is active
+4. The old key is deactivated
+
+Nothing is changed when the old key cannot be found or the new key fails its
+test. The Anthropic Admin API cannot create keys, so the new key is created
+in the Console and passed with --new-key, or on stdin with --new-key - so it
+stays out of the shell history. The admin key is read from
+$ANTHROPIC_ADMIN_KEY unless --admin-key is given.
+
+Supported providers: anthropic
+
+Examples:
+  apimgr rotate work --new-key - --dry-run
+  pass show anthropic/work | apimgr rotate work --provider anthropic --new-key -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+		// The key is replaced where it is defined, for every config sharing it
+		owner, err := configManager.KeyOwner(alias)
+		if err != nil {
+			return err
+		}
+		if owner != alias {
+			fmt.Fprintf(stdout, "'%s' inherits its key from '%s', rotating it there\n", alias, owner)
+			alias = owner
+		}
+		cfg, err := configManager.Get(alias)
+		if err != nil {
+			return err
+		}
+		if cfg.APIKey == "" {
+			return exitcode.New(exitcode.Validation, "'%s' has no API key to rotate", alias)
+		}
+		if len(cfg.APIKeys) > 0 {
+			return exitcode.New(exitcode.Validation, "'%s' uses a key pool, update it with 'apimgr edit %s --keys'", alias, alias)
+		}
+
+		provider := rotateProvider
+		if provider == "" {
+			provider = cfg.Provider
+		}
+		if provider == "" {
+			provider = "anthropic"
+		}
+		adminKey := rotateAdminKey
+		if adminKey == "" {
+			adminKey = os.Getenv(admin.AdminKeyEnv[provider])
+		}
+		keyAdmin, err := newKeyAdmin(provider, adminKey)
+		if err != nil {
+			return exitcode.New(exitcode.Usage, "%v", err)
+		}
+
+		newKey, err := readNewKey(rotateNewKey)
+		if err != nil {
+			return err
+		}
+		if newKey == "" && !rotateDryRun {
+			return exitcode.New(exitcode.Usage, "--new-key is required")
+		}
+		if newKey == cfg.APIKey {
+			return exitcode.New(exitcode.Usage, "the new key is the key '%s' already uses", alias)
+		}
+
+		oldKey, err := keyAdmin.FindKey(cfg.APIKey)
+		if err != nil {
+			return fmt.Errorf("failed to find the key of '%s': %w", alias, err)
+		}
+		fmt.Fprintf(stdout, "Old key: %s (%s, %s)\n", oldKey.Name, oldKey.ID, oldKey.Hint)
+
+		if newKey != "" {
+			if err := verifyNewKey(configManager, alias, newKey); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "✓ New key %s passed the compatibility test\n", utils.MaskAPIKey(newKey))
+		}
+
+		if rotateDryRun {
+			fmt.Fprintf(stdout, "Dry run: would update '%s' and deactivate %s\n", alias, oldKey.ID)
+			return nil
+		}
+
+		if err := saveAndApplyChanges(configManager, alias, map[string]string{"api_key": newKey}); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✓ Updated '%s'\n", alias)
+
+		if err := keyAdmin.DeactivateKey(oldKey.ID); err != nil {
+			return fmt.Errorf("'%s' uses the new key, but the old key %s is still active, deactivate it in the Console: %w", alias, oldKey.ID, err)
+		}
+		fmt.Fprintf(stdout, "✓ Deactivated old key %s\n", oldKey.ID)
+		return nil
+	},
+}
+
+// readNewKey returns the key given with --new-key, reading the first line
+// of stdin for "-"
+func readNewKey(value string) (string, error) {
+	if value != "-" {
+		return strings.TrimSpace(value), nil
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read the new key from stdin: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// verifyNewKey runs the basic compatibility test of alias with newKey in
+// place of its key
+func verifyNewKey(configManager *config.Manager, alias, newKey string) error {
+	cfg, err := configManager.Get(alias)
+	if err != nil {
+		return err
+	}
+	settings, err := configManager.GetTestSettings()
+	if err != nil {
+		return err
+	}
+	opts, err := compatibility.OptionsFromSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	cfg.APIKey = newKey
+	result := testModel(cfg, cfg.Model, opts)
+	if !result.Success {
+		return fmt.Errorf("the new key failed the compatibility test, nothing was changed: %s", result.Error)
+	}
+	return nil
+}
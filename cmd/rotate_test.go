@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/admin"
+)
+
+// fakeKeyAdmin is an admin API holding the keys by ID
+type fakeKeyAdmin struct {
+	keys        map[string]string // ID -> key
+	deactivated []string
+}
+
+func (f *fakeKeyAdmin) FindKey(key string) (admin.Key, error) {
+	for id, k := range f.keys {
+		if k == key {
+			return admin.Key{ID: id, Name: "work", Hint: key[:3] + "...", Status: "active"}, nil
+		}
+	}
+	return admin.Key{}, errors.New("no active key of the organization matches the configured key")
+}
+
+func (f *fakeKeyAdmin) DeactivateKey(id string) error {
+	f.deactivated = append(f.deactivated, id)
+	return nil
+}
+
+func TestRotateCommand(t *testing.T) {
+	// The endpoint only accepts the new key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		args            []string
+		stdin           string
+		wantErr         string
+		wantKey         string
+		wantDeactivated bool
+	}{
+		{name: "rotates the inherited key", args: []string{"rotate", "child", "--new-key", "-"}, stdin: "sk-new\n", wantKey: "sk-new", wantDeactivated: true},
+		{name: "dry run", args: []string{"rotate", "base", "--new-key", "sk-new", "--dry-run"}, wantKey: "sk-old"},
+		{name: "new key failing its test", args: []string{"rotate", "base", "--new-key", "sk-bad"}, wantErr: "failed the compatibility test", wantKey: "sk-old"},
+		{name: "missing new key", args: []string{"rotate", "base"}, wantErr: "--new-key is required", wantKey: "sk-old"},
+		{name: "unsupported provider", args: []string{"rotate", "base", "--provider", "openai", "--new-key", "sk-new"}, wantErr: "no supported admin API", wantKey: "sk-old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+			createIntegrationTestConfig(t, configPath, []models.APIConfig{
+				{Alias: "base", Provider: "anthropic", APIKey: "sk-old", BaseURL: server.URL},
+				{Alias: "child", Extends: "base", Model: "claude-sonnet-4"},
+			}, "")
+
+			fake := &fakeKeyAdmin{keys: map[string]string{"apikey_01": "sk-old"}}
+			oldNewKeyAdmin := newKeyAdmin
+			newKeyAdmin = func(provider, adminKey string) (admin.KeyAdmin, error) {
+				if _, err := admin.New(provider, adminKey); err != nil {
+					return nil, err
+				}
+				return fake, nil
+			}
+			defer func() { newKeyAdmin = oldNewKeyAdmin }()
+			t.Setenv("ANTHROPIC_ADMIN_KEY", "sk-ant-admin-test")
+
+			var out, errOut bytes.Buffer
+			err := Run(tt.args, IO{In: strings.NewReader(tt.stdin), Out: &out, Err: &errOut}, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("rotate unexpected error: %v\n%s", err, out.String())
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("rotate error = %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			configFile := readConfigFile(t, configPath)
+			if got := configFile.Configs[0].APIKey; got != tt.wantKey {
+				t.Errorf("base api_key = %q, want %q", got, tt.wantKey)
+			}
+			if configFile.Configs[1].APIKey != "" {
+				t.Errorf("child api_key = %q, want it inherited", configFile.Configs[1].APIKey)
+			}
+			if deactivated := len(fake.deactivated) > 0; deactivated != tt.wantDeactivated {
+				t.Errorf("deactivated = %v, want %v", fake.deactivated, tt.wantDeactivated)
+			}
+		})
+	}
+}
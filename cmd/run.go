@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"apimgr/config"
+	"apimgr/internal/notice"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Streams commands read from and write to: the process's standard streams,
+// unless Run replaces them
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// managerOverride is the Manager commands use instead of creating one, set
+// by Run
+var managerOverride *config.Manager
+
+// newConfigManager returns the Manager commands work on
+func newConfigManager() (*config.Manager, error) {
+	if managerOverride != nil {
+		return managerOverride, nil
+	}
+	return config.NewConfigManager()
+}
+
+// IO holds the streams of a command run in-process. A nil In reads nothing
+// and a nil Out or Err discards what is written to it.
+type IO struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// runMu serializes Run, which swaps the streams of the package
+var runMu sync.Mutex
+
+// Run executes the command line args in-process, as the apimgr binary does
+// with its arguments, reading and writing streams instead of the process's
+// standard streams. When manager is not nil, commands use it instead of the
+// config file selected by --config, APIMGR_CONFIG or the workspace. Flags
+// are reset to their defaults before each run, and runs are serialized.
+func Run(args []string, streams IO, manager *config.Manager) error {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	if streams.In == nil {
+		streams.In = strings.NewReader("")
+	}
+	if streams.Out == nil {
+		streams.Out = io.Discard
+	}
+	if streams.Err == nil {
+		streams.Err = io.Discard
+	}
+
+	oldIn, oldOut, oldErr, oldManager := stdin, stdout, stderr, managerOverride
+	stdin, stdout, stderr, managerOverride = streams.In, streams.Out, streams.Err, manager
+	notice.SetStderr(streams.Err)
+	defer func() {
+		stdin, stdout, stderr, managerOverride = oldIn, oldOut, oldErr, oldManager
+		notice.SetStderr(oldErr)
+		rootCmd.SetArgs(nil)
+	}()
+
+	resetFlags(rootCmd)
+	return execute(args)
+}
+
+// resetFlags restores the flags of cmd and its subcommands to their
+// defaults, so a run does not see the flags of the previous one
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			_ = slice.Replace(values)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
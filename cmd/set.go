@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(setCmd)
+}
+
+var setCmd = &cobra.Command{
+	Use:         "set <alias> <field>=<value>...",
+	Annotations: mutates,
+	Short:       "Change fields of a configuration",
+	Long: `Change one or more fields of a configuration without prompts, for scripts.
+Values are validated like 'apimgr edit' does, and a configuration left
+invalid is not saved. An empty value clears a field.
+
+Fields:
+  ` + strings.Join(config.SettableFieldNames(), ", ") + `
+  vars.<name>  Value of the {name} placeholder in base_url
+
+Examples:
+  apimgr set relay base_url=https://relay.example.com model=claude-sonnet-4
+  apimgr set relay models=claude-sonnet-4,claude-opus-4
+  apimgr set relay ca_bundle=                # Clear a field
+  apimgr set gateway vars.region=eu          # Fill {region} in base_url`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		updates, order, err := parseFieldAssignments(args[1:])
+		if err != nil {
+			return err
+		}
+
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		alias, err := configManager.ResolveAlias(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := applyUpdates(configManager, alias, updates); err != nil {
+			return err
+		}
+		if err := configManager.GenerateActiveScript(); err != nil {
+			notice.Printf("Warning: Failed to generate activation script: %v\n", err)
+		}
+
+		for _, name := range order {
+			fmt.Fprintf(stdout, "✓ %s\n", describeFieldUpdate(name, updates[name]))
+		}
+		return nil
+	},
+}
+
+// describeFieldUpdate describes setting a field, masking keys
+func describeFieldUpdate(name, value string) string {
+	switch {
+	case value == "":
+		return "Cleared " + name
+	case name == "api_key" || name == "auth_token":
+		return fmt.Sprintf("Set %s = %s", name, utils.MaskAPIKey(value))
+	default:
+		return fmt.Sprintf("Set %s = %s", name, value)
+	}
+}
+
+// parseFieldAssignments parses field=value arguments, returning the updates
+// and the fields in the order they were given
+func parseFieldAssignments(args []string) (map[string]string, []string, error) {
+	updates := make(map[string]string, len(args))
+	var order []string
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, nil, exitcode.New(exitcode.Usage, "invalid argument '%s', expected field=value", arg)
+		}
+		if err := config.CheckSettableField(name); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := updates[name]; ok {
+			return nil, nil, exitcode.New(exitcode.Usage, "field '%s' is given more than once", name)
+		}
+		updates[name] = value
+		order = append(order, name)
+	}
+	return updates, order, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"apimgr/internal/exitcode"
+)
+
+func TestParseFieldAssignments(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantUpdates map[string]string
+		wantOrder   []string
+		wantCode    int
+	}{
+		{
+			name:        "several fields",
+			args:        []string{"model=claude-sonnet-4", "base_url=https://x.example.com/a=b"},
+			wantUpdates: map[string]string{"model": "claude-sonnet-4", "base_url": "https://x.example.com/a=b"},
+			wantOrder:   []string{"model", "base_url"},
+		},
+		{
+			name:        "empty value clears",
+			args:        []string{"ca_bundle="},
+			wantUpdates: map[string]string{"ca_bundle": ""},
+			wantOrder:   []string{"ca_bundle"},
+		},
+		{name: "missing equals", args: []string{"model"}, wantCode: exitcode.Usage},
+		{name: "missing name", args: []string{"=x"}, wantCode: exitcode.Usage},
+		{name: "repeated field", args: []string{"model=a", "model=b"}, wantCode: exitcode.Usage},
+		{name: "unknown field", args: []string{"colour=red"}, wantCode: exitcode.Validation},
+		{name: "read-only field", args: []string{"provider=openai"}, wantCode: exitcode.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates, order, err := parseFieldAssignments(tt.args)
+			if code := exitcode.Of(err); code != tt.wantCode {
+				t.Fatalf("parseFieldAssignments(%v) code = %d, want %d (err %v)", tt.args, code, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(updates, tt.wantUpdates) || !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("parseFieldAssignments(%v) = %v, %v, want %v, %v", tt.args, updates, order, tt.wantUpdates, tt.wantOrder)
+			}
+		})
+	}
+}
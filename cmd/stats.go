@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"github.com/spf13/cobra"
+)
+
+// stalestKeysShown is the number of configurations listed under stalest keys
+const stalestKeysShown = 5
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the configuration store",
+	Long: `Summarize all configurations to help prune the config file: count by
+provider, configurations without models, configurations never tested,
+the stalest keys and when configurations were last used.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		configs, err := configManager.List()
+		if err != nil {
+			return err
+		}
+
+		if len(configs) == 0 {
+			fmt.Fprintln(stdout, "No configurations available")
+			return nil
+		}
+
+		tested := func(alias string) bool {
+			entries, err := history.Load(configManager.StateDir(), alias)
+			return err == nil && len(entries) > 0
+		}
+		fmt.Fprint(stdout, formatStoreStats(collectStoreStats(configs, tested, time.Now())))
+		return nil
+	},
+}
+
+// storeStats summarizes a set of configurations
+type storeStats struct {
+	Total         int
+	ByProvider    map[string]int
+	MissingModels []string
+	NeverTested   []string
+	StalestKeys   []models.APIConfig // Oldest key first, unknown ages last
+	LastUsed      [len(lastUsedBuckets)]int
+}
+
+// lastUsedBuckets are the last-used distribution labels, newest first.
+// The final bucket counts configurations never switched to.
+var lastUsedBuckets = [...]string{"Last 24 hours", "Last 7 days", "Last 30 days", "Older", "Never"}
+
+// lastUsedBucket returns the index in lastUsedBuckets for a last-used time
+func lastUsedBucket(lastUsed, now time.Time) int {
+	if lastUsed.IsZero() {
+		return len(lastUsedBuckets) - 1
+	}
+	switch age := now.Sub(lastUsed); {
+	case age < 24*time.Hour:
+		return 0
+	case age < 7*24*time.Hour:
+		return 1
+	case age < 30*24*time.Hour:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// collectStoreStats builds the summary; tested reports whether a
+// configuration has any recorded ping or test result
+func collectStoreStats(configs []models.APIConfig, tested func(alias string) bool, now time.Time) storeStats {
+	stats := storeStats{Total: len(configs), ByProvider: make(map[string]int)}
+	for _, cfg := range configs {
+		provider := cfg.Provider
+		if provider == "" {
+			provider = "anthropic"
+		}
+		stats.ByProvider[provider]++
+
+		if cfg.Model == "" && len(cfg.Models) == 0 {
+			stats.MissingModels = append(stats.MissingModels, cfg.Alias)
+		}
+		if !tested(cfg.Alias) {
+			stats.NeverTested = append(stats.NeverTested, cfg.Alias)
+		}
+		stats.LastUsed[lastUsedBucket(cfg.LastUsedAt, now)]++
+	}
+
+	stats.StalestKeys = append([]models.APIConfig(nil), configs...)
+	sort.SliceStable(stats.StalestKeys, func(i, j int) bool {
+		a, b := stats.StalestKeys[i].KeyUpdatedAt, stats.StalestKeys[j].KeyUpdatedAt
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	if len(stats.StalestKeys) > stalestKeysShown {
+		stats.StalestKeys = stats.StalestKeys[:stalestKeysShown]
+	}
+	return stats
+}
+
+// formatStoreStats renders the summary printed by apimgr stats
+func formatStoreStats(stats storeStats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Configurations: %d\n", stats.Total)
+
+	b.WriteString("\nBy provider:\n")
+	providers := make([]string, 0, len(stats.ByProvider))
+	for provider := range stats.ByProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		fmt.Fprintf(&b, "  %-12s %d\n", provider, stats.ByProvider[provider])
+	}
+
+	fmt.Fprintf(&b, "\nMissing models: %d\n", len(stats.MissingModels))
+	if len(stats.MissingModels) > 0 {
+		fmt.Fprintf(&b, "  %s\n", strings.Join(stats.MissingModels, ", "))
+	}
+
+	fmt.Fprintf(&b, "\nNever tested: %d\n", len(stats.NeverTested))
+	if len(stats.NeverTested) > 0 {
+		fmt.Fprintf(&b, "  %s\n", strings.Join(stats.NeverTested, ", "))
+	}
+
+	b.WriteString("\nStalest keys:\n")
+	for _, cfg := range stats.StalestKeys {
+		updated := "unknown"
+		if !cfg.KeyUpdatedAt.IsZero() {
+			updated = cfg.KeyUpdatedAt.Local().Format("2006-01-02")
+		}
+		fmt.Fprintf(&b, "  %-20s %s\n", cfg.Alias, updated)
+	}
+
+	b.WriteString("\nLast used:\n")
+	for i, label := range lastUsedBuckets {
+		fmt.Fprintf(&b, "  %-14s %d\n", label, stats.LastUsed[i])
+	}
+	return b.String()
+}
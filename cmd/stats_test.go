@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestCollectStoreStats(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	configs := []models.APIConfig{
+		{Alias: "work", Model: "claude-sonnet", KeyUpdatedAt: now.AddDate(0, -3, 0), LastUsedAt: now.Add(-time.Hour)},
+		{Alias: "relay", Provider: "openai", KeyUpdatedAt: now.AddDate(-1, 0, 0), LastUsedAt: now.AddDate(0, 0, -3)},
+		{Alias: "legacy", Models: []string{"m1"}},
+		{Alias: "old", Provider: "anthropic", Model: "claude-haiku", KeyUpdatedAt: now.AddDate(0, 0, -1), LastUsedAt: now.AddDate(0, -2, 0)},
+	}
+	tested := func(alias string) bool { return alias == "work" }
+
+	stats := collectStoreStats(configs, tested, now)
+
+	if stats.Total != 4 {
+		t.Errorf("Total = %d, want 4", stats.Total)
+	}
+	if stats.ByProvider["anthropic"] != 3 || stats.ByProvider["openai"] != 1 {
+		t.Errorf("ByProvider = %v, want anthropic:3 openai:1", stats.ByProvider)
+	}
+	if strings.Join(stats.MissingModels, ",") != "relay" {
+		t.Errorf("MissingModels = %v, want [relay]", stats.MissingModels)
+	}
+	if strings.Join(stats.NeverTested, ",") != "relay,legacy,old" {
+		t.Errorf("NeverTested = %v, want [relay legacy old]", stats.NeverTested)
+	}
+
+	var stalest []string
+	for _, cfg := range stats.StalestKeys {
+		stalest = append(stalest, cfg.Alias)
+	}
+	if got := strings.Join(stalest, ","); got != "relay,work,old,legacy" {
+		t.Errorf("StalestKeys = %s, want relay,work,old,legacy", got)
+	}
+
+	want := [len(lastUsedBuckets)]int{1, 1, 0, 1, 1}
+	if stats.LastUsed != want {
+		t.Errorf("LastUsed = %v, want %v", stats.LastUsed, want)
+	}
+
+	output := formatStoreStats(stats)
+	for _, s := range []string{"Configurations: 4", "Missing models: 1", "Never tested: 3", "legacy               unknown"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("formatStoreStats() missing %q:\n%s", s, output)
+		}
+	}
+}
@@ -2,23 +2,53 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/config/session"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
 	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var statusFormat string // Output format: text, csv or tsv
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVar(&statusFormat, "format", formatText, formatFlagUsage)
 }
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show currently active configuration",
-	Long:  "Show currently active API configuration information, including global configuration and current shell environment",
+	Long: `Show currently active API configuration information, including global configuration and current shell environment.
+
+The configuration used in this terminal is resolved in this order:
+  1. APIMGR_ACTIVE exported in this shell (switch, switch -l, env)
+  2. The local session of this terminal (switch -l)
+  3. The global active configuration (config file)
+
+--format csv or tsv writes one row per scope (global, shell, session) under
+a header row, in_use marking the one this terminal uses. --format
+template='...' prints the configuration this terminal uses with a Go
+template over its fields, such as {{.Alias}} and {{.Model}}, and {{.Source}},
+where it comes from. Keys are masked.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		comma, err := formatDelimiter(statusFormat)
+		if err != nil {
+			return err
+		}
+		tmpl, err := parseFormatTemplate(statusFormat)
+		if err != nil {
+			return err
+		}
+
 		// Get shell environment variables
 		shellAPIKey := os.Getenv("ANTHROPIC_API_KEY")
 		shellAuthToken := os.Getenv("ANTHROPIC_AUTH_TOKEN")
@@ -26,88 +56,213 @@ var statusCmd = &cobra.Command{
 		shellModel := os.Getenv("ANTHROPIC_MODEL")
 		shellActiveAlias := os.Getenv("APIMGR_ACTIVE")
 
-		// Get global configuration
-		configManager, err := config.NewConfigManager()
+		// Get global configuration, ignoring this shell's APIMGR_ACTIVE
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
-		globalActiveConfig, globalErr := configManager.GetActive()
-		var globalActiveAlias string
+		globalActiveAlias, globalErr := configManager.GetActiveName(config.ScopeGlobal)
+		var globalActiveConfig *models.APIConfig
+		if globalErr == nil && globalActiveAlias == "" {
+			globalErr = fmt.Errorf("no active configuration set")
+		}
 		if globalErr == nil {
-			globalActiveAlias = globalActiveConfig.Alias
+			globalActiveConfig, globalErr = configManager.Get(globalActiveAlias)
 		}
 
-		fmt.Println("Current configuration status:")
-		fmt.Println("=========================================")
+		// Local session started by 'switch -l' in this terminal
+		marker, err := session.ReadSessionMarker(configManager.SessionDir(), strconv.Itoa(session.ShellPID()))
+		if err != nil {
+			notice.Printf("Warning: %v\n", err)
+		}
+		var sessionAlias string
+		if marker != nil {
+			sessionAlias = marker.Alias
+		}
+		effective, source := resolveStatusAlias(shellActiveAlias, sessionAlias, globalActiveAlias)
+
+		if tmpl != nil {
+			if effective == "" {
+				return exitcode.New(exitcode.NotFound, "no configuration set")
+			}
+			cfg, err := configManager.Get(effective)
+			if err != nil {
+				return err
+			}
+			return writeTemplate(stdout, tmpl, statusTemplateData{APIConfig: templateConfig(*cfg), Source: source})
+		}
+		if comma != 0 {
+			if globalErr != nil {
+				globalActiveConfig = nil
+			}
+			shell := models.APIConfig{Alias: shellActiveAlias, APIKey: shellAPIKey, AuthToken: shellAuthToken, BaseURL: shellAPIBase, Model: shellModel}
+			return writeDelimited(stdout, comma, statusHeader, statusRecords(globalActiveConfig, shell, marker, source))
+		}
+
+		fmt.Fprintln(stdout, "Current configuration status:")
+		fmt.Fprintln(stdout, "=========================================")
 
 		// Show global active configuration
-		fmt.Println("1. Global active configuration (config file):")
+		fmt.Fprintln(stdout, "1. Global active configuration (config file):")
 		if globalErr != nil {
-			fmt.Println("   No global active configuration set")
+			fmt.Fprintln(stdout, "   No global active configuration set")
 		} else {
-			fmt.Printf("   Alias: %s\n", globalActiveConfig.Alias)
+			fmt.Fprintf(stdout, "   Alias: %s\n", globalActiveConfig.Alias)
 			if globalActiveConfig.APIKey != "" {
-				fmt.Printf("   API Key: %s\n", utils.MaskAPIKey(globalActiveConfig.APIKey))
+				fmt.Fprintf(stdout, "   API Key: %s\n", utils.MaskAPIKey(globalActiveConfig.APIKey))
 			}
 			if globalActiveConfig.AuthToken != "" {
-				fmt.Printf("   Auth Token: %s\n", utils.MaskAPIKey(globalActiveConfig.AuthToken))
+				fmt.Fprintf(stdout, "   Auth Token: %s\n", utils.MaskAPIKey(globalActiveConfig.AuthToken))
 			}
 			if globalActiveConfig.BaseURL != "" {
-				fmt.Printf("   Base URL: %s\n", globalActiveConfig.BaseURL)
+				fmt.Fprintf(stdout, "   Base URL: %s\n", globalActiveConfig.BaseURL)
 			}
 			// Show active model
 			if globalActiveConfig.Model != "" {
-				fmt.Printf("   Active Model: %s\n", globalActiveConfig.Model)
+				fmt.Fprintf(stdout, "   Active Model: %s\n", globalActiveConfig.Model)
 			}
 			// Show all supported models (Requirements: 3.2, 3.3)
 			if len(globalActiveConfig.Models) > 0 {
-				fmt.Printf("   Supported Models: %s\n", formatModelsListForStatus(globalActiveConfig.Models, globalActiveConfig.Model))
+				fmt.Fprintf(stdout, "   Supported Models: %s\n", formatModelsListForStatus(globalActiveConfig.Models, globalActiveConfig.Model))
 			}
 		}
 
 		// Show shell environment configuration
-		fmt.Println("\n2. Current Shell environment:")
+		fmt.Fprintln(stdout, "\n2. Current Shell environment:")
 		if shellAPIKey == "" && shellAuthToken == "" {
-			fmt.Println("   No environment variables set")
+			fmt.Fprintln(stdout, "   No environment variables set")
 		} else {
 			if shellActiveAlias != "" {
-				fmt.Printf("   Alias: %s\n", shellActiveAlias)
+				fmt.Fprintf(stdout, "   Alias: %s\n", shellActiveAlias)
 			}
 			if shellAPIKey != "" {
-				fmt.Printf("   API Key: %s\n", utils.MaskAPIKey(shellAPIKey))
+				fmt.Fprintf(stdout, "   API Key: %s\n", utils.MaskAPIKey(shellAPIKey))
 			}
 			if shellAuthToken != "" {
-				fmt.Printf("   Auth Token: %s\n", utils.MaskAPIKey(shellAuthToken))
+				fmt.Fprintf(stdout, "   Auth Token: %s\n", utils.MaskAPIKey(shellAuthToken))
 			}
 			if shellAPIBase != "" {
-				fmt.Printf("   Base URL: %s\n", shellAPIBase)
+				fmt.Fprintf(stdout, "   Base URL: %s\n", shellAPIBase)
 			}
 			if shellModel != "" {
-				fmt.Printf("   Model: %s\n", shellModel)
+				fmt.Fprintf(stdout, "   Model: %s\n", shellModel)
 			}
 		}
 
+		// Show the local session of this terminal
+		fmt.Fprintln(stdout, "\n3. Local session (this terminal):")
+		if marker == nil {
+			fmt.Fprintln(stdout, "   No local session")
+		} else {
+			fmt.Fprintf(stdout, "   Alias: %s\n", marker.Alias)
+			fmt.Fprintf(stdout, "   Started: %s\n", marker.Timestamp.Format("2006-01-02 15:04:05"))
+		}
+
 		// Show configuration source
-		fmt.Println("\n=========================================")
-		if shellAPIKey != "" || shellAuthToken != "" {
-			if globalErr != nil || (globalActiveAlias != "" && globalActiveAlias != shellActiveAlias) {
-				fmt.Println("💡 Currently using Shell environment configuration (overrides global configuration)")
-			} else {
-				fmt.Println("💡 Currently using global configuration")
+		fmt.Fprintln(stdout, "\n=========================================")
+		switch {
+		case effective == "":
+			fmt.Fprintln(stdout, "💡 No configuration set")
+		case source == statusSourceGlobal:
+			fmt.Fprintf(stdout, "💡 Using global configuration: %s\n", effective)
+		case globalActiveAlias == "":
+			fmt.Fprintf(stdout, "💡 Local override: %s (this terminal, %s), no global configuration\n", effective, source)
+		default:
+			fmt.Fprintf(stdout, "💡 Local override: %s (this terminal, %s), global: %s\n", effective, source, globalActiveAlias)
+		}
+		if effective != "" {
+			effectiveConfig := globalActiveConfig
+			if effective != globalActiveAlias || globalErr != nil {
+				effectiveConfig, _ = configManager.Get(effective)
 			}
-		} else {
-			if globalErr != nil {
-				fmt.Println("💡 No configuration set")
-			} else {
-				fmt.Println("💡 Currently using global configuration (Shell has no environment variables set)")
+			if effectiveConfig != nil {
+				writeEnvOverrides(stdout, effectiveConfig.Alias, config.EnvOverrides(effectiveConfig, os.LookupEnv))
 			}
 		}
+		fmt.Fprintln(stdout, "   Resolution order: APIMGR_ACTIVE > local session (switch -l) > global (config file)")
 
-		fmt.Println("\n💡 Tip: Run 'apimgr install' to install shell integration for better experience")
+		fmt.Fprintln(stdout, "\n💡 Tip: Run 'apimgr install' to install shell integration for better experience")
 		return nil
 	},
 }
 
+// Sources of the configuration used in a terminal, in resolution order
+const (
+	statusSourceEnv     = "APIMGR_ACTIVE"
+	statusSourceSession = "local session"
+	statusSourceGlobal  = "config file"
+)
+
+// resolveStatusAlias returns the configuration used in this terminal and
+// where it comes from
+func resolveStatusAlias(envAlias, sessionAlias, globalAlias string) (string, string) {
+	switch {
+	case envAlias != "":
+		if envAlias == globalAlias && sessionAlias == "" {
+			return envAlias, statusSourceGlobal
+		}
+		return envAlias, statusSourceEnv
+	case sessionAlias != "":
+		return sessionAlias, statusSourceSession
+	case globalAlias != "":
+		return globalAlias, statusSourceGlobal
+	default:
+		return "", ""
+	}
+}
+
+// statusTemplateData is what status --format template=... executes on
+type statusTemplateData struct {
+	models.APIConfig
+	Source string // statusSourceEnv, statusSourceSession or statusSourceGlobal
+}
+
+// statusHeader names the columns of status --format csv|tsv
+var statusHeader = []string{"scope", "alias", "api_key", "auth_token", "base_url", "model", "started", "in_use"}
+
+// statusRecords lists the global configuration, the one exported in this
+// shell and the local session as rows under statusHeader. global and marker
+// are nil when unset; source is where the configuration in use comes from.
+func statusRecords(global *models.APIConfig, shell models.APIConfig, marker *session.SessionMarker, source string) [][]string {
+	record := func(scope string, cfg models.APIConfig, started, scopeSource string) []string {
+		return []string{scope, cfg.Alias, delimitedField(cfg.APIKey, true), delimitedField(cfg.AuthToken, true),
+			cfg.BaseURL, cfg.Model, started, strconv.FormatBool(source != "" && source == scopeSource)}
+	}
+
+	var globalCfg models.APIConfig
+	if global != nil {
+		globalCfg = *global
+	}
+	var sessionCfg models.APIConfig
+	var started string
+	if marker != nil {
+		sessionCfg.Alias = marker.Alias
+		started = marker.Timestamp.Format(time.RFC3339)
+	}
+	return [][]string{
+		record("global", globalCfg, "", statusSourceGlobal),
+		record("shell", shell, "", statusSourceEnv),
+		record("session", sessionCfg, started, statusSourceSession),
+	}
+}
+
+// writeEnvOverrides warns about variables exported in this shell that
+// Claude Code would use over the synced settings of alias
+func writeEnvOverrides(w io.Writer, alias string, overrides []config.FieldDiff) {
+	if len(overrides) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "⚠️  This shell exports variables overriding the synced configuration '%s':\n", alias)
+	names := make([]string, 0, len(overrides))
+	for _, o := range overrides {
+		fmt.Fprintf(w, "   %s=%s (config: %s)\n", o.Name, formatField(o.A, o.Secret, false), formatField(o.B, o.Secret, false))
+		names = append(names, o.Name)
+	}
+	fmt.Fprintln(w, "   Claude Code prefers its process environment over the env of settings.json, so")
+	fmt.Fprintln(w, "   when started from this shell it uses these values. To use the config, run:")
+	fmt.Fprintf(w, "   unset %s\n", strings.Join(names, " "))
+}
+
 // formatModelsListForStatus formats the models list for status display, marking the active model.
 // Requirements: 3.2, 3.3
 func formatModelsListForStatus(models []string, activeModel string) string {
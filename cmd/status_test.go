@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"bytes"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/config/session"
 )
 
 func TestStatusCmd(t *testing.T) {
@@ -33,3 +41,74 @@ func TestStatusCmd(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveStatusAlias(t *testing.T) {
+	tests := []struct {
+		name                           string
+		envAlias, sessionAlias, global string
+		want, wantSource               string
+	}{
+		{"nothing set", "", "", "", "", ""},
+		{"global only", "", "", "work", "work", statusSourceGlobal},
+		{"global exported by switch", "work", "", "work", "work", statusSourceGlobal},
+		{"local session", "", "test", "work", "test", statusSourceSession},
+		{"switch -l exported", "test", "test", "work", "test", statusSourceEnv},
+		{"env overrides session", "other", "test", "work", "other", statusSourceEnv},
+		{"env without global", "test", "", "", "test", statusSourceEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source := resolveStatusAlias(tt.envAlias, tt.sessionAlias, tt.global)
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("resolveStatusAlias() = %q, %q; want %q, %q", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestStatusRecords(t *testing.T) {
+	global := &models.APIConfig{Alias: "g", APIKey: "sk-ant-api03-abcdefgh", BaseURL: "https://api.example.com", Model: "m"}
+	shell := models.APIConfig{Alias: "s", AuthToken: "tok-1234567890"}
+	marker := &session.SessionMarker{Alias: "s", Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	records := statusRecords(global, shell, marker, statusSourceEnv)
+	want := [][]string{
+		{"global", "g", "sk-a****efgh", "", "https://api.example.com", "m", "", "false"},
+		{"shell", "s", "", "tok-****7890", "", "", "", "true"},
+		{"session", "s", "", "", "", "", "2026-01-02T03:04:05Z", "false"},
+	}
+	if !slices.EqualFunc(records, want, slices.Equal) {
+		t.Errorf("statusRecords() =\n%q\nwant\n%q", records, want)
+	}
+	for _, record := range records {
+		if len(record) != len(statusHeader) {
+			t.Errorf("record %q has %d columns, header has %d", record, len(record), len(statusHeader))
+		}
+	}
+
+	empty := statusRecords(nil, models.APIConfig{}, nil, "")
+	for _, record := range empty {
+		if record[1] != "" || record[7] != "false" {
+			t.Errorf("statusRecords() without configurations = %q", record)
+		}
+	}
+}
+
+func TestWriteEnvOverrides(t *testing.T) {
+	var out bytes.Buffer
+	writeEnvOverrides(&out, "work", nil)
+	if out.Len() != 0 {
+		t.Errorf("writeEnvOverrides() without overrides wrote %q", out.String())
+	}
+
+	writeEnvOverrides(&out, "work", []config.FieldDiff{
+		{Name: "ANTHROPIC_API_KEY", A: "sk-ant-api03-abcdefgh", B: "sk-ant-api03-12345678", Secret: true},
+		{Name: "ANTHROPIC_BASE_URL", A: "https://old.example.com"},
+	})
+	for _, want := range []string{"'work'", "ANTHROPIC_API_KEY=sk-a****efgh (config: sk-a****5678)", "ANTHROPIC_BASE_URL=https://old.example.com (config: (unset))", "unset ANTHROPIC_API_KEY ANTHROPIC_BASE_URL"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeEnvOverrides() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
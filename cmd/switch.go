@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"apimgr/config"
+	"apimgr/config/group"
+	"apimgr/config/models"
 	"apimgr/config/session"
+	syncpkg "apimgr/config/sync"
 	"apimgr/config/validation"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/hooks"
+	"apimgr/internal/notice"
+	"apimgr/internal/preflight"
+	"apimgr/internal/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
@@ -20,11 +30,19 @@ func init() {
 	switchCmd.Flags().StringP("model", "m", "", "Switch to a specific model within the configuration")
 	// Add no-prompt parameter for non-interactive use
 	switchCmd.Flags().Bool("no-prompt", false, "Disable interactive model selection even when multiple models are available")
+	// Add environment parameter to resolve the alias within one environment
+	switchCmd.Flags().String("env", "", "Resolve the alias among configurations of this environment (e.g. prod)")
+	// Add no-hooks parameter to skip the configured switch hooks
+	switchCmd.Flags().Bool("no-hooks", false, "Do not run the pre_switch and post_switch hooks")
+	// Add verify parameters to test the target before switching
+	switchCmd.Flags().Bool("verify", false, "Test the configuration first and cancel the switch when it fails")
+	switchCmd.Flags().Bool("no-verify", false, "Do not test the configuration even when defaults.verify_switch is set")
 }
 
 var switchCmd = &cobra.Command{
-	Use:   "switch [alias]",
-	Short: "Switch to specified API configuration",
+	Use:         "switch [alias]",
+	Annotations: mutates,
+	Short:       "Switch to specified API configuration",
 	Long: `Switch to specified API configuration and output export commands for environment variables
 
 To make environment variables effective in current shell, there are two methods:
@@ -37,11 +55,37 @@ Using -l/--local parameter switches configuration only in current shell session
 
 Using -m/--model parameter switches to a specific model within the configuration:
   apimgr switch <alias> --model claude-3-sonnet
-  eval "$(apimgr switch <alias> -m gpt-4)"`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		alias := args[0]
+  eval "$(apimgr switch <alias> -m gpt-4)"
+
+Running without an alias opens a fuzzy picker that matches alias, model and base URL:
+  apimgr switch
+
+Unique alias prefixes and glob patterns are accepted:
+  apimgr switch wo*
+
+Using --env resolves the alias among configurations of one environment:
+  apimgr switch --env prod openai
+
+Using - as the alias switches back to the previously active configuration:
+  apimgr switch -
+
+Using group:<name> switches to a member of a group declared under "groups"
+in the config file, picked by weight on every switch, or once per working
+directory for groups with "strategy": "sticky":
+  apimgr switch group:relays
+
+Hooks configured under "hooks" in the config file or a configuration run
+before (pre_switch) and after (post_switch) the switch, with their output on
+stderr. A failing pre_switch hook cancels the switch. Skip them with:
+  apimgr switch <alias> --no-hooks
 
+Using --verify sends a test request with the configuration first and
+cancels the switch when it fails, leaving the active configuration as it
+was. Set defaults.verify_switch to true to verify every switch, and skip it
+once with --no-verify:
+  apimgr switch <alias> --verify`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Read the local flag
 		local, _ := cmd.Flags().GetBool("local")
 		// Read the model flag
@@ -49,37 +93,36 @@ Using -m/--model parameter switches to a specific model within the configuration
 
 		successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
 
-		configManager, err := config.NewConfigManager()
+		configManager, err := newConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
 
+		// Resolve the alias, prompting with the fuzzy picker when omitted
+		environment, _ := cmd.Flags().GetString("env")
+		alias, err := resolveSwitchAlias(configManager, args, environment)
+		if err != nil {
+			return err
+		}
+
 		// Get the configuration first (needed for both modes)
 		apiConfig, err := configManager.Get(alias)
 		if err != nil {
 			return err
 		}
+		if err := utils.CheckPlaceholders(apiConfig.BaseURL); err != nil {
+			return err
+		}
 
-		// Handle model switch if --model flag is provided
+		// Resolve the model to switch to, prompting when several are available
+		targetModel := ""
 		if modelFlag != "" {
 			// Validate model is in supported list
 			validator := validation.NewModelValidator()
 			if err := validator.ValidateModelInList(modelFlag, apiConfig.Models); err != nil {
 				return err
 			}
-
-			// Switch the model in the configuration
-			if err := configManager.SwitchModel(alias, modelFlag); err != nil {
-				return err
-			}
-
-			// Refresh the config to get the updated model
-			apiConfig, err = configManager.Get(alias)
-			if err != nil {
-				return err
-			}
-
-			fmt.Fprintln(os.Stderr, successStyle.Render(fmt.Sprintf("✓ Switched model to: %s", modelFlag)))
+			targetModel = modelFlag
 		} else {
 			// Check if we need to prompt for model selection
 			noPrompt, _ := cmd.Flags().GetBool("no-prompt")
@@ -91,41 +134,86 @@ Using -m/--model parameter switches to a specific model within the configuration
 				if err != nil {
 					return fmt.Errorf("model selection failed: %w", err)
 				}
-
-				// If user selected a different model, update it
 				if selectedModel != apiConfig.Model {
-					// Switch the model in the configuration
-					if err := configManager.SwitchModel(alias, selectedModel); err != nil {
-						return err
-					}
-
-					// Refresh the config to get the updated model
-					apiConfig, err = configManager.Get(alias)
-					if err != nil {
-						return err
-					}
-
-					fmt.Fprintln(os.Stderr, successStyle.Render(fmt.Sprintf("✓ Switched model to: %s", selectedModel)))
+					targetModel = selectedModel
 				}
 			}
 		}
 
+		// Test the configuration as it will be switched to, before any hook
+		// runs or anything is saved
+		if verify, err := shouldVerifySwitch(cmd, configManager); err != nil {
+			return err
+		} else if verify {
+			if err := verifySwitch(configManager, alias, targetModel); err != nil {
+				return err
+			}
+		}
+
+		// Pre-switch hooks run before anything changes, so they can cancel the switch
+		noHooks, _ := cmd.Flags().GetBool("no-hooks")
+		var switchHooks models.Hooks
+		event := hooks.Event{To: alias, Scope: hooks.ScopeGlobal}
+		if local {
+			event.Scope = hooks.ScopeLocal
+		}
+		event.From = switchFrom(configManager, local)
+		if !noHooks {
+			if switchHooks, err = configManager.SwitchHooks(alias); err != nil {
+				return err
+			}
+			event.Phase = hooks.PreSwitch
+			if err := runSwitchHooks(switchHooks.PreSwitch, event); err != nil {
+				return fmt.Errorf("%w, switch cancelled", err)
+			}
+		}
+
+		// Configs with a key pool take the next key of the pool
+		if len(apiConfig.APIKeys) > 0 {
+			if err := configManager.PickKey(alias); err != nil {
+				return err
+			}
+			if apiConfig, err = configManager.Get(alias); err != nil {
+				return err
+			}
+		}
+
+		// Switch the model in the configuration
+		if targetModel != "" {
+			if err := configManager.SwitchModel(alias, targetModel); err != nil {
+				return err
+			}
+
+			// Refresh the config to get the updated model
+			apiConfig, err = configManager.Get(alias)
+			if err != nil {
+				return err
+			}
+
+			notice.Println(successStyle.Render(fmt.Sprintf("✓ Switched model to: %s", targetModel)))
+		}
+
 		if local {
-			// Local mode: update Claude Code but not global active
-			pid := fmt.Sprintf("%d", os.Getpid())
+			// Local mode: update Claude Code but not global active.
+			// The session belongs to the shell that evals the output.
+			pid := fmt.Sprintf("%d", session.ShellPID())
 
 			// Create session marker
-			if err := session.CreateSessionMarker(configManager.GetConfigPath(), pid, alias); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to create session marker: %v\n", err)
+			if err := session.CreateSessionMarker(configManager.SessionDir(), pid, alias); err != nil {
+				notice.Printf("Warning: Failed to create session marker: %v\n", err)
+			}
+
+			if err := configManager.MarkUsed(alias); err != nil {
+				notice.Printf("Warning: Failed to record usage: %v\n", err)
 			}
 
 			// Sync to Claude Code only (no global active update)
 			if err := configManager.SyncClaudeSettingsOnly(apiConfig); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to sync to Claude Code: %v\n", err)
+				notice.Printf("Warning: Failed to sync to Claude Code: %v\n", err)
 			}
 
 			// Output trap command for cleanup on shell exit
-			fmt.Printf("trap 'apimgr cleanup-session %s' EXIT\n", pid)
+			fmt.Fprintf(cmd.OutOrStdout(), "trap 'apimgr cleanup-session %s' EXIT\n", pid)
 		} else {
 			// Global mode: update global configuration
 			// Set the active configuration
@@ -135,69 +223,193 @@ Using -m/--model parameter switches to a specific model within the configuration
 
 			// Generate active.env script for auto-loading
 			if err := configManager.GenerateActiveScript(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to generate activation script: %v\n", err)
+				notice.Printf("Warning: Failed to generate activation script: %v\n", err)
 			}
 
 			// Show sync information
-			showSyncInfo(alias)
+			showSyncInfo(configManager)
 		}
 
-		// Clear previous environment variables
-		fmt.Println("unset ANTHROPIC_API_KEY")
-		fmt.Println("unset ANTHROPIC_AUTH_TOKEN")
-		fmt.Println("unset ANTHROPIC_BASE_URL")
-		fmt.Println("unset ANTHROPIC_MODEL")
-		fmt.Println("unset APIMGR_ACTIVE")
-
-		// Export new environment variables
-		if apiConfig.APIKey != "" {
-			fmt.Printf("export ANTHROPIC_API_KEY=\"%s\"\n", apiConfig.APIKey)
-		} else if apiConfig.AuthToken != "" {
-			fmt.Printf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", apiConfig.AuthToken)
-		}
-		if apiConfig.BaseURL != "" {
-			fmt.Printf("export ANTHROPIC_BASE_URL=\"%s\"\n", apiConfig.BaseURL)
-		}
-		if apiConfig.Model != "" {
-			fmt.Printf("export ANTHROPIC_MODEL=\"%s\"\n", apiConfig.Model)
-		}
-		fmt.Printf("export APIMGR_ACTIVE=\"%s\"\n", alias)
+		// Clear previous environment variables and export the new ones,
+		// named after the config's provider. Only shell code goes to
+		// stdout, which is evaluated; everything else goes to stderr.
+		fmt.Fprint(cmd.OutOrStdout(), syncpkg.GenerateEnvCommands(apiConfig))
 
 		if local {
-			fmt.Fprintln(os.Stderr, successStyle.Render(fmt.Sprintf("✓ Switched to configuration locally: %s", alias)))
+			notice.Println(successStyle.Render(fmt.Sprintf("✓ Switched to configuration locally: %s", alias)))
 		} else {
 			if modelFlag == "" {
-				fmt.Fprintln(os.Stderr, successStyle.Render(fmt.Sprintf("✓ Switched to configuration: %s", alias)))
+				notice.Println(successStyle.Render(fmt.Sprintf("✓ Switched to configuration: %s", alias)))
 			}
 		}
+
+		event.Phase = hooks.PostSwitch
+		if err := runSwitchHooks(switchHooks.PostSwitch, event); err != nil {
+			notice.Printf("Warning: %v\n", err)
+		}
 		return nil
 	},
 }
 
+// switchFrom returns the alias a switch moves away from: the global active
+// alias for a global switch, and for a local one the session of this shell,
+// falling back to its APIMGR_ACTIVE and then the global alias
+func switchFrom(configManager *config.Manager, local bool) string {
+	if !local {
+		alias, _ := configManager.GetActiveName(config.ScopeGlobal)
+		return alias
+	}
+	if marker, _ := session.ReadSessionMarker(configManager.SessionDir(), fmt.Sprintf("%d", session.ShellPID())); marker != nil {
+		return marker.Alias
+	}
+	alias, _ := configManager.GetActiveName(config.ScopeShell)
+	return alias
+}
+
+// runSwitchHooks runs one phase of hooks, echoing their output to stderr so
+// it stays out of the exports evaluated by the shell
+func runSwitchHooks(commands []string, event hooks.Event) error {
+	results := hooks.Run(commands, event)
+	for _, r := range results {
+		slog.Debug("ran switch hook", "phase", event.Phase, "command", r.Command, "error", r.Err)
+		if r.Output != "" {
+			fmt.Fprintln(stderr, r.Output)
+		}
+	}
+	return hooks.Failed(results)
+}
+
+// shouldVerifySwitch reports whether the configuration is tested before
+// switching: with --verify, or defaults.verify_switch unless --no-verify
+func shouldVerifySwitch(cmd *cobra.Command, configManager *config.Manager) (bool, error) {
+	verify, _ := cmd.Flags().GetBool("verify")
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	switch {
+	case verify && noVerify:
+		return false, exitcode.New(exitcode.Usage, "--verify and --no-verify cannot be used together")
+	case verify || noVerify:
+		return verify, nil
+	}
+	return preflight.Enabled(configManager)
+}
+
+// verifySwitch runs the basic compatibility test of alias with the key and
+// model it is about to be switched to, returning an error cancelling the
+// switch when it fails
+func verifySwitch(configManager *config.Manager, alias, model string) error {
+	target, err := preflight.Target(configManager, alias, model)
+	if err != nil {
+		return err
+	}
+
+	notice.Printf("Verifying %s...\n", alias)
+	result, err := preflight.Check(configManager, target)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return exitcode.New(exitcode.Network, "'%s' failed verification, switch cancelled: %s", alias, preflight.Failure(result))
+	}
+	notice.Printf("✓ Verified %s (%dms)\n", alias, result.ResponseTime.Milliseconds())
+	return nil
+}
+
+// resolveSwitchAlias returns the alias argument, or lets the user pick one
+// interactively when no alias was given
+func resolveSwitchAlias(configManager *config.Manager, args []string, environment string) (string, error) {
+	if len(args) > 0 {
+		if args[0] == "-" {
+			return configManager.GetPrevious()
+		}
+		if name, ok := strings.CutPrefix(args[0], group.Prefix); ok {
+			return pickGroupMember(configManager, name)
+		}
+		if environment != "" {
+			return configManager.ResolveAliasInEnvironment(args[0], environment)
+		}
+		return configManager.ResolveAlias(args[0])
+	}
+	if !isInteractiveTerminal() {
+		return "", fmt.Errorf("alias is required in non-interactive mode")
+	}
+
+	configs, err := configManager.List()
+	if err != nil {
+		return "", err
+	}
+	activeAlias, _ := configManager.GetActiveName(config.ScopeShell)
+
+	if environment != "" {
+		configs = filterByEnvironment(configs, environment)
+	}
+
+	return NewConfigPicker().Pick(configs, activeAlias)
+}
+
+// pickGroupMember selects the configuration of group name to switch to,
+// sticky groups keeping one per working directory
+func pickGroupMember(configManager *config.Manager, name string) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	alias, err := configManager.PickGroupMember(name, dir)
+	if err != nil {
+		return "", err
+	}
+	notice.Printf("Group %s picked: %s\n", name, alias)
+	return alias, nil
+}
+
+// filterByEnvironment returns the configurations belonging to an environment
+func filterByEnvironment(configs []models.APIConfig, environment string) []models.APIConfig {
+	filtered := make([]models.APIConfig, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Environment == environment {
+			filtered = append(filtered, cfg)
+		}
+	}
+	return filtered
+}
+
 // showSyncInfo shows sync status information
-func showSyncInfo(alias string) {
+func showSyncInfo(configManager *config.Manager) {
 	// Check sync status
-	globalClaudePath := filepath.Join(os.Getenv("HOME"), ".claude", "settings.json")
+	var synced []config.SyncTarget
+	if targets, err := configManager.SyncTargets(); err == nil {
+		for _, target := range targets {
+			if _, err := os.Stat(target.Path); err == nil {
+				synced = append(synced, target)
+			}
+		}
+	}
 	projectClaudePath := filepath.Join(".", ".claude", "settings.json")
 
-	hasGlobal := false
 	hasProject := false
-
-	if _, err := os.Stat(globalClaudePath); err == nil {
-		hasGlobal = true
-	}
 	if _, err := os.Stat(projectClaudePath); err == nil {
 		hasProject = true
 	}
 
-	if hasGlobal || hasProject {
-		fmt.Fprintf(os.Stderr, "\n✅ Configuration sync status:\n")
-		if hasGlobal {
-			fmt.Fprintf(os.Stderr, "   • Global Claude Code: ~/.claude/settings.json\n")
+	if len(synced) > 0 || hasProject {
+		notice.Printf("\n✅ Configuration sync status:\n")
+		for _, target := range synced {
+			notice.Printf("   • Claude Code (%s): %s\n", target.Name, shortenHome(target.Path))
 		}
 		if hasProject {
-			fmt.Fprintf(os.Stderr, "   • Project-level Claude Code: %s\n", projectClaudePath)
+			notice.Printf("   • Project-level Claude Code: %s\n", projectClaudePath)
 		}
-		fmt.Fprintf(os.Stderr, "\n💡 Configuration has been automatically synced to Claude Code, ready to use.\n")
+		notice.Printf("\n💡 Configuration has been automatically synced to Claude Code, ready to use.\n")
+	}
+}
+
+// shortenHome replaces the home directory at the start of a path with ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, home); ok && (rest == "" || strings.HasPrefix(rest, string(filepath.Separator))) {
+		return "~" + rest
 	}
+	return path
 }
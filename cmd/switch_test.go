@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -14,6 +18,7 @@ import (
 
 	"apimgr/config"
 	"apimgr/config/models"
+	"apimgr/config/session"
 )
 
 // Helper function to create a test config file
@@ -1085,4 +1090,123 @@ func TestPropertyPrioritizeAPIKeyOverAuthToken(t *testing.T) {
 	if err := quick.Check(property, cfg); err != nil {
 		t.Errorf("Property test failed: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestSwitchVerify(t *testing.T) {
+	// The endpoint only accepts the good key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		args       []string
+		always     bool // defaults.verify_switch
+		wantErr    string
+		wantActive string
+	}{
+		{name: "healthy target", args: []string{"switch", "good", "--verify"}, wantActive: "good"},
+		{name: "unhealthy target", args: []string{"switch", "bad", "--verify"}, wantErr: "failed verification", wantActive: "current"},
+		{name: "no verification", args: []string{"switch", "bad"}, wantActive: "bad"},
+		{name: "always verify", args: []string{"switch", "bad"}, always: true, wantErr: "switch cancelled", wantActive: "current"},
+		{name: "skip once", args: []string{"switch", "bad", "--no-verify"}, always: true, wantActive: "bad"},
+		{name: "conflicting flags", args: []string{"switch", "good", "--verify", "--no-verify"}, wantErr: "cannot be used together", wantActive: "current"},
+		{name: "unhealthy model", args: []string{"switch", "multi", "--verify", "--model", "m2"}, wantErr: "failed verification", wantActive: "current"},
+		{name: "unhealthy pool key", args: []string{"switch", "pool", "--verify"}, wantErr: "failed verification", wantActive: "current"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+			createIntegrationTestConfig(t, configPath, []models.APIConfig{
+				{Alias: "current", APIKey: "sk-current"},
+				{Alias: "good", APIKey: "sk-good", BaseURL: server.URL},
+				{Alias: "bad", APIKey: "sk-bad", BaseURL: server.URL},
+				{Alias: "multi", APIKey: "sk-bad", BaseURL: server.URL, Model: "m1", Models: []string{"m1", "m2"}},
+				{Alias: "pool", APIKeys: []string{"sk-bad", "sk-good"}, BaseURL: server.URL},
+			}, "current")
+			if tt.always {
+				if err := Run([]string{"config", "set", "defaults.verify_switch", "true"}, IO{Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}, nil); err != nil {
+					t.Fatalf("config set unexpected error: %v", err)
+				}
+			}
+
+			before := readConfigFile(t, configPath)
+			var out, errOut bytes.Buffer
+			err := Run(tt.args, IO{Out: &out, Err: &errOut}, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("switch unexpected error: %v\n%s", err, errOut.String())
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("switch error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			// A cancelled switch saves nothing, neither a model nor a picked key
+			if after := readConfigFile(t, configPath); tt.wantErr != "" && !reflect.DeepEqual(after.Configs, before.Configs) {
+				t.Errorf("configs = %+v, want them unchanged from %+v", after.Configs, before.Configs)
+			}
+			if active := readConfigFile(t, configPath).Active; active != tt.wantActive {
+				t.Errorf("active = %q, want %q", active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestSwitchHookFrom(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook command uses POSIX shell syntax")
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		envAlias string // APIMGR_ACTIVE of the shell
+		session  string // Alias of the shell's session marker
+		want     string
+	}{
+		{name: "global", args: []string{"switch", "target"}, envAlias: "shell", want: "global"},
+		{name: "local", args: []string{"switch", "-l", "target"}, want: "global"},
+		{name: "local after a local switch", args: []string{"switch", "-l", "target"}, envAlias: "shell", want: "shell"},
+		{name: "local session", args: []string{"switch", "-l", "target"}, envAlias: "shell", session: "session", want: "session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+			defer cleanup()
+			configFile := models.File{
+				Active: "global",
+				Configs: []models.APIConfig{
+					{Alias: "global", APIKey: "sk-global"},
+					{Alias: "shell", APIKey: "sk-shell"},
+					{Alias: "session", APIKey: "sk-session"},
+					{Alias: "target", APIKey: "sk-target"},
+				},
+				Hooks: &models.Hooks{PreSwitch: []string{`echo "from=$APIMGR_HOOK_FROM"`}},
+			}
+			data, _ := json.Marshal(configFile)
+			if err := os.WriteFile(configPath, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.session != "" {
+				if err := session.CreateSessionMarker(integrationSessionDir(t), strconv.Itoa(session.ShellPID()), tt.session); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("APIMGR_ACTIVE", tt.envAlias)
+
+			var errOut bytes.Buffer
+			if err := Run(tt.args, IO{Err: &errOut}, nil); err != nil {
+				t.Fatalf("switch unexpected error: %v\n%s", err, errOut.String())
+			}
+			if want := "from=" + tt.want + "\n"; !strings.Contains(errOut.String(), want) {
+				t.Errorf("hook output = %q, want %q", errOut.String(), want)
+			}
+		})
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
 	"apimgr/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -25,7 +28,9 @@ Subcommands:
   status     View sync status
   claude     Sync to Claude Code
   init       Initialize tool configuration files for project
-  list       List all tools that can be synced`,
+  list       List all tools that can be synced
+  team pull  Refresh the shared team file
+  project    Sync project .claude settings on switch`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Default to show status
@@ -47,10 +52,11 @@ func init() {
 
 // claude subcommand
 var syncClaudeCmd = &cobra.Command{
-	Use:   "claude",
-	Short: "Sync to Claude Code",
-	Long:  `Force sync current active configuration to Claude Code`,
-	Run:   runSyncClaude,
+	Use:         "claude",
+	Annotations: mutates,
+	Short:       "Sync to Claude Code",
+	Long:        `Force sync current active configuration to Claude Code`,
+	RunE:        runSyncClaude,
 }
 
 func init() {
@@ -59,10 +65,11 @@ func init() {
 
 // init subcommand
 var syncInitCmd = &cobra.Command{
-	Use:   "init",
-	Short: "Initialize tool configuration files for project",
-	Long:  `Create configuration file templates for various tools in the current project directory`,
-	Run:   runSyncInit,
+	Use:         "init",
+	Annotations: mutates,
+	Short:       "Initialize tool configuration files for project",
+	Long:        `Create configuration file templates for various tools in the current project directory`,
+	RunE:        runSyncInit,
 }
 
 func init() {
@@ -81,12 +88,85 @@ func init() {
 	syncCmd.AddCommand(syncListCmd)
 }
 
+// team subcommand
+var syncTeamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Manage the shared team file",
+	Long: `Manage the shared team file set by "team" in the config file. It holds
+configs without keys, overlaid with the configs of the same alias in the
+config file.`,
+	Args: cobra.NoArgs,
+}
+
+var syncTeamPullCmd = &cobra.Command{
+	Use:         "pull",
+	Annotations: mutates,
+	Short:       "Refresh the shared team file",
+	Long: `Refresh the shared team file: download it from team.url, or run
+"git pull --ff-only" in its directory when no URL is set. A downloaded file
+holding keys is refused.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncTeamPull,
+}
+
+func init() {
+	syncTeamCmd.AddCommand(syncTeamPullCmd)
+	syncCmd.AddCommand(syncTeamCmd)
+}
+
+var syncProjectShared bool
+
+// project subcommand
+var syncProjectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "List the projects synced on switch",
+	Long: `List the projects whose Claude Code settings are synced on switch.
+Projects are opt-in: apimgr never writes into a project that was not enabled.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncProject,
+}
+
+var syncProjectEnableCmd = &cobra.Command{
+	Use:         "enable [dir]",
+	Annotations: mutates,
+	Short:       "Sync a project's Claude Code settings on switch",
+	Long: `Sync the .claude/settings.local.json of a project on switch, creating it
+when missing. The project defaults to the one holding the current directory:
+the nearest directory with a .claude or .git entry.
+
+--shared syncs the committed .claude/settings.json instead. It never receives
+a key in clear: configurations in the env key mode are written without their
+key, so use key_mode helper or shell for them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSyncProjectEnable,
+}
+
+var syncProjectDisableCmd = &cobra.Command{
+	Use:         "disable [dir]",
+	Annotations: mutates,
+	Short:       "Stop syncing a project's Claude Code settings",
+	Long: `Stop syncing the Claude Code settings of a project and remove the
+configuration apimgr wrote into them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSyncProjectDisable,
+}
+
+func init() {
+	syncProjectEnableCmd.Flags().BoolVar(&syncProjectShared, "shared", false, "Sync the committed .claude/settings.json instead, without keys in clear")
+	// settings.local.json used to need --local
+	syncProjectEnableCmd.Flags().Bool("local", false, "Sync .claude/settings.local.json, the default")
+	_ = syncProjectEnableCmd.Flags().MarkDeprecated("local", "settings.local.json is now the default")
+	syncProjectCmd.AddCommand(syncProjectEnableCmd)
+	syncProjectCmd.AddCommand(syncProjectDisableCmd)
+	syncCmd.AddCommand(syncProjectCmd)
+}
+
 func showSyncStatus() error {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("Configuration Sync Status")
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "Configuration Sync Status")
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 
-	configManager, err := config.NewConfigManager()
+	configManager, err := newConfigManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize config manager: %w", err)
 	}
@@ -94,82 +174,195 @@ func showSyncStatus() error {
 	// Show current active configuration
 	active, err := configManager.GetActive()
 	if err != nil {
-		fmt.Println("\n❌ No active configuration")
+		fmt.Fprintln(stdout, "\n❌ No active configuration")
 		return nil
 	}
 
-	fmt.Printf("\nCurrent configuration: %s\n", active.Alias)
-	fmt.Printf("Model: %s\n", active.Model)
-	fmt.Printf("API Key: %s\n", utils.MaskAPIKey(active.APIKey))
-	fmt.Printf("Base URL: %s\n", active.BaseURL)
+	fmt.Fprintf(stdout, "\nCurrent configuration: %s\n", active.Alias)
+	fmt.Fprintf(stdout, "Model: %s\n", active.Model)
+	fmt.Fprintf(stdout, "API Key: %s\n", utils.MaskAPIKey(active.APIKey))
+	fmt.Fprintf(stdout, "Base URL: %s\n", active.BaseURL)
 
 	// Check sync status
-	fmt.Println("\nSync status:")
+	fmt.Fprintln(stdout, "\nSync status:")
 
-	// Global Claude Code
-	globalClaudePath := filepath.Join(os.Getenv("HOME"), ".claude", "settings.json")
-	if _, err := os.Stat(globalClaudePath); err == nil {
-		fmt.Println("✅ Claude Code (Global): ~/.claude/settings.json")
-	} else {
-		fmt.Println("⚪ Claude Code (Global): Not installed")
+	// Claude Code settings written on switch
+	targets, err := configManager.SyncTargets()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(stdout, "⚪ Claude Code: No sync targets enabled")
+	}
+	for _, target := range targets {
+		if _, err := os.Stat(target.Path); err == nil {
+			fmt.Fprintf(stdout, "✅ Claude Code (%s): %s\n", target.Name, shortenHome(target.Path))
+		} else {
+			fmt.Fprintf(stdout, "⚪ Claude Code (%s): %s (Not found)\n", target.Name, shortenHome(target.Path))
+		}
 	}
 
-	// Project-level Claude Code
+	// Project-level Claude Code, synced once enabled
 	workDir, _ := os.Getwd()
-	projectClaudePath := filepath.Join(workDir, ".claude", "settings.json")
-	if _, err := os.Stat(projectClaudePath); err == nil {
-		fmt.Printf("✅ Claude Code (Project): %s\n", projectClaudePath)
-	} else {
-		fmt.Printf("⚪ Claude Code (Project): %s (Not initialized)\n", projectClaudePath)
+	projects, err := configManager.Projects()
+	if err != nil {
+		return err
+	}
+	root := config.FindProjectRoot(workDir)
+	if !slices.ContainsFunc(projects, func(p models.Project) bool { return p.Root == root }) {
+		fmt.Fprintf(stdout, "⚪ Claude Code (project): %s (Not enabled, see 'apimgr sync project enable')\n", shortenHome(root))
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
 	return nil
 }
 
 func runSyncStatus(cmd *cobra.Command, args []string) {
 	if err := showSyncStatus(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(stderr, "Error: %v\n", err)
 	}
 }
 
-func runSyncClaude(cmd *cobra.Command, args []string) {
-	configManager, err := config.NewConfigManager()
+func runSyncClaude(cmd *cobra.Command, args []string) error {
+	configManager, err := newConfigManager()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to initialize config manager: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: failed to initialize config manager: %v\n", err)
+		return exitcode.Silent(exitcode.Failure)
 	}
 
 	if _, err := configManager.GetActive(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitcode.Silent(exitcode.Failure)
 	}
 
-	fmt.Println("Syncing to Claude Code...")
+	fmt.Fprintln(stdout, "Syncing to Claude Code...")
 
 	// Sync global settings
 	if err := configManager.GenerateActiveScript(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Sync failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: Sync failed: %v\n", err)
+		return exitcode.Silent(exitcode.Failure)
+	}
+
+	fmt.Fprintln(stdout, "\n✅ Sync completed!")
+	return nil
+}
+
+func runSyncTeamPull(cmd *cobra.Command, args []string) error {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	pull, err := configManager.PullTeam()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Pulled team file %s\n", shortenHome(pull.Path))
+	if len(pull.Added)+len(pull.Changed)+len(pull.Removed) == 0 {
+		fmt.Fprintln(stdout, "No team configurations changed")
+		return nil
+	}
+	for _, change := range []struct {
+		label   string
+		aliases []string
+	}{{"Added", pull.Added}, {"Changed", pull.Changed}, {"Removed", pull.Removed}} {
+		if len(change.aliases) > 0 {
+			fmt.Fprintf(stdout, "%s: %s\n", change.label, strings.Join(change.aliases, ", "))
+		}
+	}
+	return nil
+}
+
+func runSyncProject(cmd *cobra.Command, args []string) error {
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	projects, err := configManager.Projects()
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		fmt.Fprintln(stdout, "No projects enabled, see 'apimgr sync project enable'")
+		return nil
+	}
+	for _, project := range projects {
+		settings := "settings.json"
+		if project.Local {
+			settings = "settings.local.json"
+		}
+		fmt.Fprintf(stdout, "%s (%s)\n", shortenHome(project.Root), settings)
+	}
+	return nil
+}
+
+// projectDir returns the directory named by args, or the project holding
+// the current directory
+func projectDir(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return config.FindProjectRoot(workDir), nil
+}
+
+func runSyncProjectEnable(cmd *cobra.Command, args []string) error {
+	dir, err := projectDir(args)
+	if err != nil {
+		return err
+	}
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
 	}
 
-	fmt.Println("\n✅ Sync completed!")
+	path, err := configManager.EnableProject(dir, !syncProjectShared)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Enabled syncing %s\n", shortenHome(path))
+	if syncProjectShared {
+		fmt.Fprintln(stdout, "The file is committed, so keys are only written as a key helper (key_mode helper), never in clear")
+	}
+	fmt.Fprintln(stdout, "Run 'apimgr sync claude' to write the active configuration now")
+	return nil
 }
 
-func runSyncInit(cmd *cobra.Command, args []string) {
+func runSyncProjectDisable(cmd *cobra.Command, args []string) error {
+	dir, err := projectDir(args)
+	if err != nil {
+		return err
+	}
+	configManager, err := newConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	if err := configManager.DisableProject(dir); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Disabled syncing the project's Claude Code settings")
+	return nil
+}
+
+func runSyncInit(cmd *cobra.Command, args []string) error {
 	workDir, err := os.Getwd()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get current directory: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: Failed to get current directory: %v\n", err)
+		return exitcode.Silent(exitcode.Failure)
 	}
 
-	fmt.Println("Initializing tool configuration files for project...")
+	fmt.Fprintln(stdout, "Initializing tool configuration files for project...")
 
 	// Create .claude directory (if it doesn't exist)
 	claudeDir := filepath.Join(workDir, ".claude")
 	if err := os.MkdirAll(claudeDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create .claude directory: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: Failed to create .claude directory: %v\n", err)
+		return exitcode.Silent(exitcode.Failure)
 	}
 
 	// Create Claude Code configuration file
@@ -188,41 +381,42 @@ func runSyncInit(cmd *cobra.Command, args []string) {
 		}
 
 		if err := writeJSONFile(claudeSettingsPath, settings); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to create Claude Code configuration file: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error: Failed to create Claude Code configuration file: %v\n", err)
+			return exitcode.Silent(exitcode.Failure)
 		}
 
-		fmt.Printf("✅ Created Claude Code configuration: %s\n", claudeSettingsPath)
+		fmt.Fprintf(stdout, "✅ Created Claude Code configuration: %s\n", claudeSettingsPath)
 	} else {
-		fmt.Printf("ℹ️  Claude Code configuration already exists: %s\n", claudeSettingsPath)
+		fmt.Fprintf(stdout, "ℹ️  Claude Code configuration already exists: %s\n", claudeSettingsPath)
 	}
 
-	fmt.Println("\n✅ Project initialization completed!")
-	fmt.Println("\napimgr will now automatically sync configuration to this project.")
+	fmt.Fprintln(stdout, "\n✅ Project initialization completed!")
+	fmt.Fprintln(stdout, "\napimgr will now automatically sync configuration to this project.")
+	return nil
 }
 
 func runSyncList(cmd *cobra.Command, args []string) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("Supported Sync Tools")
-	fmt.Println(strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "Supported Sync Tools")
+	fmt.Fprintln(stdout, strings.Repeat("=", 60))
 
 	tools := []struct {
 		Name   string
 		Config string
 		Status string
 	}{
-		{"Claude Code", "~/.claude/settings.json", "✅ Implemented"},
+		{"Claude Code", shortenHome(config.ClaudeSettingsPath()), "✅ Implemented"},
 		{"Grok (xAI)", "~/.config/grok/config.json", "🚧 Planned"},
 		{"GitHub Copilot", "~/.config/copilot/config.json", "🚧 Planned"},
 		{"OpenAI CLI", "~/.config/openai/config.json", "🚧 Planned"},
 	}
 
-	fmt.Println()
+	fmt.Fprintln(stdout)
 	for _, tool := range tools {
-		fmt.Printf("%-20s %-40s %s\n", tool.Name, tool.Config, tool.Status)
+		fmt.Fprintf(stdout, "%-20s %-40s %s\n", tool.Name, tool.Config, tool.Status)
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Fprintln(stdout, "\n"+strings.Repeat("=", 60))
 }
 
 // writeJSONFile writes JSON file
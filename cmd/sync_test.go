@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -42,8 +46,8 @@ func TestSyncSubcommands(t *testing.T) {
 		hasRunE bool
 	}{
 		{"status", "status", true, false},
-		{"claude", "claude", true, false},
-		{"init", "init", true, false},
+		{"claude", "claude", false, true},
+		{"init", "init", false, true},
 		{"list", "list", true, false},
 	}
 
@@ -163,3 +167,32 @@ func TestWriteJSONFile(t *testing.T) {
 		}
 	})
 }
+
+func TestSyncTeamPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"configs": [{"alias": "relay", "base_url": "https://relay.example.com"}]}`))
+	}))
+	defer server.Close()
+
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	if err := os.WriteFile(configPath, []byte(`{"team": {"url": "`+server.URL+`"}, "configs": [{"alias": "relay", "api_key": "sk-mine"}]}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	if err := Run([]string{"sync", "team", "pull"}, IO{Out: &out, Err: &errOut}, nil); err != nil {
+		t.Fatalf("sync team pull unexpected error: %v\n%s", err, errOut.String())
+	}
+	if !strings.Contains(out.String(), "Added: relay") {
+		t.Errorf("sync team pull output = %q, want relay added", out.String())
+	}
+
+	out.Reset()
+	if err := Run([]string{"get", "relay", "--field", "base_url"}, IO{Out: &out, Err: &errOut}, nil); err != nil {
+		t.Fatalf("get unexpected error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "https://relay.example.com" {
+		t.Errorf("get base_url = %q, want the team base URL", out.String())
+	}
+}
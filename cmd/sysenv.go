@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/sysenv"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(sysenvCmd)
+	sysenvCmd.AddCommand(sysenvApplyCmd)
+	sysenvCmd.AddCommand(sysenvClearCmd)
+}
+
+var sysenvCmd = &cobra.Command{
+	Use:   "sysenv",
+	Short: "Share the active configuration with GUI apps",
+	Long: `Set the active configuration's variables for your whole login session, so
+apps started outside a shell, e.g. from the Dock or an app launcher, inherit
+them too.
+
+  macOS  launchctl setenv, apps started afterwards see the variables
+  Linux  ~/.config/environment.d/60-apimgr.conf (mode 0600), read by the
+         systemd user manager at login, and systemctl --user
+         set-environment for the running session
+
+The variables are not updated on switch, run 'sysenv apply' again after
+switching.
+
+Examples:
+  apimgr sysenv apply   # Export the active configuration to the session
+  apimgr sysenv clear   # Remove it again`,
+}
+
+var sysenvApplyCmd = &cobra.Command{
+	Use:         "apply",
+	Annotations: mutates,
+	Short:       "Set the active configuration's variables for the login session",
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		cfg, err := configManager.GetActive()
+		if err != nil {
+			return err
+		}
+
+		var vars []sysenv.Var
+		for _, v := range syncpkg.EnvExports(cfg) {
+			// APIMGR_ACTIVE would pin every new shell to this configuration
+			if v.Name != "APIMGR_ACTIVE" {
+				vars = append(vars, sysenv.Var{Name: v.Name, Value: v.Value})
+			}
+		}
+
+		result, err := sysenv.Apply(vars, sysenvNames())
+		if err != nil {
+			return fmt.Errorf("failed to set system environment: %w", err)
+		}
+		fmt.Fprintf(stdout, "✓ Applied %s to the system environment\n", cfg.Alias)
+		printSysenvResult(result)
+		return nil
+	},
+}
+
+var sysenvClearCmd = &cobra.Command{
+	Use:         "clear",
+	Annotations: mutates,
+	Short:       "Remove apimgr's variables from the login session",
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := sysenv.Clear(sysenvNames())
+		if err != nil {
+			return fmt.Errorf("failed to clear system environment: %w", err)
+		}
+		fmt.Fprintln(stdout, "✓ Cleared the system environment")
+		printSysenvResult(result)
+		return nil
+	},
+}
+
+// sysenvNames returns every variable apimgr may set in the system environment
+func sysenvNames() []string {
+	var names []string
+	for _, name := range syncpkg.EnvUnsetNames() {
+		if name != "APIMGR_ACTIVE" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// printSysenvResult explains where the change took effect
+func printSysenvResult(result *sysenv.Result) {
+	if result.File != "" {
+		fmt.Fprintf(stdout, "  File: %s\n", result.File)
+	}
+	if result.Live {
+		fmt.Fprintln(stdout, "  Apps started from now on see the change")
+	} else {
+		fmt.Fprintln(stdout, "  The systemd user manager is not reachable, the change takes effect at next login")
+	}
+}
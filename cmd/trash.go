@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"apimgr/config"
+	"github.com/spf13/cobra"
+)
+
+var restoreAs string // Alias to restore a removed configuration under
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	trashRestoreCmd.Flags().StringVar(&restoreAs, "as", "", "Restore under another alias")
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore or delete removed configurations",
+	Long: fmt.Sprintf(`Removed configurations are kept in the trash section of the config file
+for %d days, or defaults.trash_days, and can be restored until then.
+
+Examples:
+  apimgr trash list                  # Show removed configurations
+  apimgr trash restore work          # Bring back the last removed 'work'
+  apimgr trash restore work --as w2  # Restore it under another alias
+  apimgr trash empty                 # Delete removed configurations for good`, config.DefaultTrashDays),
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List removed configurations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		trash, err := configManager.Trash()
+		if err != nil {
+			return err
+		}
+		if len(trash) == 0 {
+			fmt.Fprintln(stdout, "The trash is empty")
+			return nil
+		}
+		for _, t := range trash {
+			fmt.Fprintf(stdout, "%-20s removed %s\n", t.Config.Alias, t.RemovedAt.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:         "restore <alias>",
+	Annotations: mutates,
+	Short:       "Restore a removed configuration",
+	Long: `Restore the most recently removed configuration with the given alias into
+the main config file. It is not made active again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		alias, err := configManager.Restore(args[0], restoreAs)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Configuration restored: %s\n", alias)
+		return nil
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:         "empty",
+	Annotations: mutates,
+	Short:       "Permanently delete removed configurations",
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		count, err := configManager.EmptyTrash()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Deleted %d removed configuration(s)\n", count)
+		return nil
+	},
+}
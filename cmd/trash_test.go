@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestTrashCmd(t *testing.T) {
+	_, configPath, _, cleanup := setupIntegrationTestEnv(t)
+	defer cleanup()
+	createIntegrationTestConfig(t, configPath, []models.APIConfig{
+		{Alias: "work", APIKey: "sk-work"},
+		{Alias: "home", APIKey: "sk-home"},
+	}, "work")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		err := Run(args, IO{In: strings.NewReader(""), Out: &out, Err: &errOut}, nil)
+		return out.String(), err
+	}
+
+	if out, err := run("remove", "home"); err != nil || !strings.Contains(out, "apimgr trash restore home") {
+		t.Fatalf("remove = %q, %v, want the restore hint", out, err)
+	}
+	if out, err := run("trash", "list"); err != nil || !strings.Contains(out, "home") {
+		t.Errorf("trash list = %q, %v, want home listed", out, err)
+	}
+	if _, err := run("trash", "restore", "home", "--as", "house"); err != nil {
+		t.Fatalf("trash restore unexpected error: %v", err)
+	}
+	if configs := readConfigFile(t, configPath).Configs; len(configs) != 2 || configs[1].Alias != "house" || configs[1].APIKey != "sk-home" {
+		t.Errorf("configs = %+v, want home restored as house", configs)
+	}
+
+	if _, err := run("remove", "house"); err != nil {
+		t.Fatalf("remove unexpected error: %v", err)
+	}
+	if out, err := run("trash", "empty"); err != nil || !strings.Contains(out, "Deleted 1") {
+		t.Errorf("trash empty = %q, %v, want 1 deleted", out, err)
+	}
+	if out, err := run("trash", "list"); err != nil || !strings.Contains(out, "empty") {
+		t.Errorf("trash list = %q, %v, want an empty trash", out, err)
+	}
+	if len(readConfigFile(t, configPath).Trash) != 0 {
+		t.Error("config file still holds trashed configs after trash empty")
+	}
+}
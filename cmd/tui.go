@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"apimgr/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var demoFlag bool // Synthetic configs and test results
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Launch the interactive TUI",
+	Long: `Launch the interactive TUI, as running apimgr without a command does.
+
+With --demo the TUI shows synthetic configs and health history, and tests
+return canned results, on a temporary store that is removed on exit: the real
+configs and Claude Code settings are never read or changed. Useful to explore
+the key bindings or record reproducible screenshots.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := tuiOptions()
+		options.Demo = demoFlag
+		return tui.Run(options)
+	},
+}
+
+func init() {
+	tuiCmd.Flags().BoolVar(&asciiFlag, "ascii", false, "Draw the TUI with ASCII symbols only (or set "+asciiEnvVar+")")
+	tuiCmd.Flags().BoolVar(&accessibleFlag, "accessible", false, "Screen reader mode: linear output and state changes as text (or set "+accessibleEnvVar+")")
+	tuiCmd.Flags().BoolVar(&demoFlag, "demo", false, "Use synthetic configs and test results, leaving the real ones untouched")
+	rootCmd.AddCommand(tuiCmd)
+}
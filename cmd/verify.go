@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that apimgr's files agree with each other",
+	Long: `Check that the config file, active.env, the Claude Code settings files and
+the session markers of 'switch -l' are consistent: the active configuration
+exists, active.env and the settings files hold its values (or those of a
+running local session), and every session marker belongs to a running shell
+and an existing configuration. Each mismatch is printed with a way to fix it.
+Keys are never printed and nothing is written.
+
+Examples:
+  apimgr verify`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		mismatches, err := configManager.Verify()
+		if err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			fmt.Fprintln(stdout, "✓ Config, active.env, Claude Code settings and session markers are consistent")
+			return nil
+		}
+		fmt.Fprint(stdout, formatMismatches(mismatches))
+		return exitcode.New(exitcode.Validation, "found %d mismatch(es)", len(mismatches))
+	},
+}
+
+// formatMismatches formats each mismatch with its fix
+func formatMismatches(mismatches []config.Mismatch) string {
+	var b strings.Builder
+	for _, m := range mismatches {
+		fmt.Fprintf(&b, "✗ %s (%s): %s\n", m.Artifact, shortenHome(m.Path), m.Problem)
+		fmt.Fprintf(&b, "  Fix: %s\n", m.Fix)
+	}
+	return b.String()
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"apimgr/config"
+)
+
+func TestFormatMismatches(t *testing.T) {
+	got := formatMismatches([]config.Mismatch{
+		{Artifact: "session", Path: "/tmp/session-1", Problem: "orphaned, shell 1 is no longer running", Fix: "remove it"},
+	})
+	want := "✗ session (/tmp/session-1): orphaned, shell 1 is no longer running\n  Fix: remove it\n"
+	if got != want {
+		t.Errorf("formatMismatches() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"apimgr/config"
+	"apimgr/config/session"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/providers"
+	"apimgr/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
+
+var whichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Explain where each effective API variable comes from",
+	Long: `Show every place an API variable is set and which one wins, to debug why a
+tool still uses an old key or URL.
+
+Claude Code reads, from highest to lowest precedence:
+  1. .claude/settings.local.json in the current directory
+  2. .claude/settings.json in the current directory
+  3. ~/.claude/settings.json, or $CLAUDE_CONFIG_DIR/settings.json when set
+  4. The environment it was started from (this shell)
+
+This shell's environment in turn comes from active.env (new shells), a local
+session (switch -l) or variables exported by hand.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		sources := collectWhichSources(configManager)
+		names := whichNames(sources)
+		if len(names) == 0 {
+			fmt.Fprintln(stdout, "No API variables are set in any settings file or in this shell")
+			return nil
+		}
+
+		for i, name := range names {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintln(stdout, name)
+			winner := effectiveSource(sources, name)
+			for j, src := range sources {
+				value, ok := src.Values[name]
+				if !ok {
+					continue
+				}
+				mark := " "
+				if j == winner {
+					mark = "✓"
+				}
+				detail := src.Detail
+				if src.Name == whichSourceProcess {
+					detail = processOrigin(sources, name, value)
+				}
+				fmt.Fprintf(stdout, "  %s %-22s %-34s %s\n", mark, src.Name, detail, displayValue(name, value))
+			}
+			if winner >= 0 && sources[winner].Name != whichSourceProcess {
+				if value, ok := sources[whichProcessIndex(sources)].Values[name]; ok && value != sources[winner].Values[name] {
+					fmt.Fprintf(stdout, "  ⚠️  Claude Code uses %s, not this shell's value\n", sources[winner].Detail)
+				}
+			}
+		}
+		fmt.Fprintln(stdout, "\n✓ marks the value Claude Code uses. active.env and local session values only apply through a shell.")
+		return nil
+	},
+}
+
+// Source names shown by which
+const (
+	whichSourceProjectLocal = "Claude project (local)"
+	whichSourceProject      = "Claude project"
+	whichSourceUser         = "Claude user"
+	whichSourceProcess      = "Process env"
+	whichSourceActiveEnv    = "active.env"
+	whichSourceSession      = "Local session"
+)
+
+// whichSource is a place API variables are set
+type whichSource struct {
+	Name   string
+	Detail string
+	Values map[string]string
+	Direct bool // Whether Claude Code reads it directly, in precedence order
+}
+
+// collectWhichSources reads every source, Claude Code's in precedence order first
+func collectWhichSources(configManager *config.Manager) []whichSource {
+	homeDir := os.Getenv("HOME")
+	sources := []whichSource{
+		{Name: whichSourceProjectLocal, Detail: filepath.Join(".claude", "settings.local.json"), Direct: true},
+		{Name: whichSourceProject, Detail: filepath.Join(".claude", "settings.json"), Direct: true},
+		{Name: whichSourceUser, Detail: shortenHome(config.ClaudeSettingsPath()), Direct: true},
+		{Name: whichSourceProcess, Detail: "this shell", Direct: true},
+		{Name: whichSourceActiveEnv, Detail: "new shells"},
+		{Name: whichSourceSession, Detail: "switch -l"},
+	}
+	sources[0].Values = syncpkg.ReadSettingsEnv(sources[0].Detail)
+	sources[1].Values = syncpkg.ReadSettingsEnv(sources[1].Detail)
+	sources[2].Values = syncpkg.ReadSettingsEnv(config.ClaudeSettingsPath())
+
+	sources[3].Values = make(map[string]string)
+	for _, name := range append(providers.AllEnvVarNames(), "APIMGR_ACTIVE") {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			sources[3].Values[name] = value
+		}
+	}
+
+	activeEnvPath := filepath.Join(configManager.StateDir(), config.ActiveEnvFileName)
+	sources[4].Detail = strings.Replace(activeEnvPath, homeDir, "~", 1)
+	sources[4].Values = readActiveEnv(activeEnvPath)
+
+	marker, _ := session.ReadSessionMarker(configManager.SessionDir(), strconv.Itoa(session.ShellPID()))
+	if marker != nil {
+		sources[5].Detail = "switch -l " + marker.Alias
+		if cfg, err := configManager.Get(marker.Alias); err == nil {
+			sources[5].Values = make(map[string]string)
+			for _, v := range syncpkg.EnvExports(cfg) {
+				sources[5].Values[v.Name] = v.Value
+			}
+		}
+	}
+	return sources
+}
+
+// whichNames returns the known API variables set in any source, in the
+// order providers list them
+func whichNames(sources []whichSource) []string {
+	var names []string
+	for _, name := range append(providers.AllEnvVarNames(), "APIMGR_ACTIVE") {
+		for _, src := range sources {
+			if _, ok := src.Values[name]; ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// effectiveSource returns the index of the source Claude Code takes name
+// from, or -1 when it sees no value
+func effectiveSource(sources []whichSource, name string) int {
+	for i, src := range sources {
+		if _, ok := src.Values[name]; ok && src.Direct {
+			return i
+		}
+	}
+	return -1
+}
+
+// whichProcessIndex returns the index of the process environment source
+func whichProcessIndex(sources []whichSource) int {
+	for i, src := range sources {
+		if src.Name == whichSourceProcess {
+			return i
+		}
+	}
+	return -1
+}
+
+// processOrigin explains where this shell's value of name came from
+func processOrigin(sources []whichSource, name, value string) string {
+	for _, src := range sources {
+		if src.Name == whichSourceSession && src.Values[name] == value {
+			return "this shell, from " + src.Detail
+		}
+	}
+	for _, src := range sources {
+		if src.Name == whichSourceActiveEnv && src.Values[name] == value {
+			return "this shell, from active.env"
+		}
+	}
+	return "this shell, set outside apimgr"
+}
+
+// displayValue masks secret values
+func displayValue(name, value string) string {
+	if utils.IsSecretName(name) {
+		return utils.MaskAPIKey(value)
+	}
+	return value
+}
+
+// readActiveEnv parses the export lines of active.env
+func readActiveEnv(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return syncpkg.ParseEnvScript(string(data))
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	syncpkg "apimgr/config/sync"
+)
+
+func TestEffectiveSource(t *testing.T) {
+	sources := []whichSource{
+		{Name: whichSourceProject, Values: map[string]string{"ANTHROPIC_BASE_URL": "https://project"}, Direct: true},
+		{Name: whichSourceUser, Values: map[string]string{"ANTHROPIC_BASE_URL": "https://user", "ANTHROPIC_MODEL": "m"}, Direct: true},
+		{Name: whichSourceProcess, Values: map[string]string{"ANTHROPIC_API_KEY": "sk-shell"}, Direct: true},
+		{Name: whichSourceActiveEnv, Values: map[string]string{"OPENAI_API_KEY": "sk-new"}},
+	}
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"ANTHROPIC_BASE_URL", 0},
+		{"ANTHROPIC_MODEL", 1},
+		{"ANTHROPIC_API_KEY", 2},
+		{"OPENAI_API_KEY", -1}, // Only reaches Claude Code through a shell
+	}
+	for _, tt := range tests {
+		if got := effectiveSource(sources, tt.name); got != tt.want {
+			t.Errorf("effectiveSource(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadWhichFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	settingsPath := filepath.Join(dir, "settings.json")
+	settings := "{\n  // relay\n  \"env\": {\"ANTHROPIC_BASE_URL\": \"https://relay\", \"MAX_TOKENS\": 8},\n}\n"
+	if err := os.WriteFile(settingsPath, []byte(settings), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := syncpkg.ReadSettingsEnv(settingsPath)
+	if env["ANTHROPIC_BASE_URL"] != "https://relay" || env["MAX_TOKENS"] != "8" {
+		t.Errorf("syncpkg.ReadSettingsEnv() = %v", env)
+	}
+	if syncpkg.ReadSettingsEnv(filepath.Join(dir, "missing.json")) != nil {
+		t.Error("syncpkg.ReadSettingsEnv() should return nil for a missing file")
+	}
+
+	activeEnvPath := filepath.Join(dir, "active.env")
+	script := "# comment\nunset ANTHROPIC_API_KEY\nexport ANTHROPIC_API_KEY=\"sk-\\\"q\"\nexport APIMGR_ACTIVE=\"work\"\n"
+	if err := os.WriteFile(activeEnvPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env = readActiveEnv(activeEnvPath)
+	if len(env) != 2 || env["ANTHROPIC_API_KEY"] != `sk-"q` || env["APIMGR_ACTIVE"] != "work" {
+		t.Errorf("readActiveEnv() = %v", env)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	"apimgr/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceUseCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceCurrentCmd)
+}
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Switch between separate configuration stores",
+	Long: `Keep separate configuration stores, e.g. personal and client work, and
+switch between them. The default workspace is the usual config file; named
+workspaces live under workspaces/<name>/ in the config directory, each with
+its own configurations, active configuration and history.
+
+--config <path> and ` + config.ConfigEnvVar + ` select a config file directly and
+take precedence over the workspace in use.
+
+Examples:
+  apimgr workspace use client-acme   # Create and switch to 'client-acme'
+  apimgr workspace list              # List workspaces
+  apimgr workspace use default       # Back to the default store
+  apimgr --config ./team.yaml list   # Use a config file once`,
+}
+
+var workspaceUseCmd = &cobra.Command{
+	Use:         "use <name>",
+	Annotations: mutates,
+	Short:       "Switch to a workspace, creating it if needed",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.UseWorkspace(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✓ Switched to workspace: %s\n", args[0])
+		if path, source := config.ConfigPathOverride(); path != "" {
+			fmt.Fprintf(stdout, "Note: %s is set to %s and takes precedence over the workspace\n", source, path)
+		}
+		fmt.Fprintln(stdout, "Run 'apimgr switch <alias>' to activate one of its configurations")
+		return nil
+	},
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workspaces",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.ListWorkspaces()
+		if err != nil {
+			return err
+		}
+		current, err := config.CurrentWorkspace()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			marker := " "
+			if name == current {
+				marker = "*"
+			}
+			fmt.Fprintf(stdout, "%s %s\n", marker, name)
+		}
+		return nil
+	},
+}
+
+var workspaceCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the workspace and config file in use",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configManager, err := newConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		if path, source := config.ConfigPathOverride(); path != "" {
+			fmt.Fprintf(stdout, "Config file: %s (from %s)\n", configManager.GetConfigPath(), source)
+			return nil
+		}
+		current, err := config.CurrentWorkspace()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Workspace:   %s\n", current)
+		fmt.Fprintf(stdout, "Config file: %s\n", configManager.GetConfigPath())
+		return nil
+	},
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"apimgr/config/models"
+)
+
+// configCache holds the last parsed config file, so that reading an
+// unchanged file neither locks nor parses it again
+type configCache struct {
+	mu      sync.Mutex
+	modTime time.Time    // Modification time of the cached content
+	size    int64        // Size of the cached content
+	file    *models.File // Parsed content, nil when nothing is cached
+}
+
+// get returns a copy of the cached file if info still describes it
+func (c *configCache) get(info os.FileInfo) *models.File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil || !info.ModTime().Equal(c.modTime) || info.Size() != c.size {
+		return nil
+	}
+	return c.file.Clone()
+}
+
+// put caches a copy of file as the content described by info
+func (c *configCache) put(info os.FileInfo, file *models.File) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modTime, c.size, c.file = info.ModTime(), info.Size(), file.Clone()
+}
+
+// invalidate drops the cached file
+func (c *configCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file = nil
+}
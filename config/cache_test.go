@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestLoadConfigFileCache(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "a", APIKey: "sk-a", Models: []string{"m1"}}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Callers get their own copy
+	first.Configs[0].Alias = "changed"
+	first.Configs[0].Models[0] = "changed"
+	second, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := second.Configs[0]; got.Alias != "a" || got.Models[0] != "m1" {
+		t.Errorf("a change to a loaded file leaked into the cache: %+v", got)
+	}
+
+	// Content of the same size and modification time is not read again
+	info, err := os.Stat(cm.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced := strings.Replace(string(data), `"a"`, `"b"`, 1)
+	if err := os.WriteFile(cm.configPath, []byte(replaced), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cm.configPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.Get("a"); err != nil {
+		t.Errorf("Get() of a cached config unexpected error: %v", err)
+	}
+
+	// A new modification time invalidates the cache
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(cm.configPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.Get("b"); err != nil {
+		t.Errorf("Get() after the file changed unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfigFileCacheAfterSave(t *testing.T) {
+	cm := setupTestConfig(t)
+	for _, alias := range []string{"a", "b"} {
+		if err := cm.Add(models.APIConfig{Alias: alias, APIKey: "sk-" + alias}); err != nil {
+			t.Fatal(err)
+		}
+		configs, err := cm.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if configs[len(configs)-1].Alias != alias {
+			t.Errorf("List() after adding %q = %+v", alias, configs)
+		}
+	}
+}
+
+func TestFileClone(t *testing.T) {
+	notifications := true
+	original := &models.File{
+		Configs:       []models.APIConfig{{Alias: "a", Models: []string{"m1"}, Vars: map[string]string{"v": "1"}, Hooks: &models.Hooks{PreSwitch: []string{"x"}}}},
+		Keybindings:   map[string][]string{"up": {"k"}},
+		TestSettings:  &models.TestSettings{Timeout: "30s"},
+		Notifications: &notifications,
+		Defaults:      &models.Defaults{SyncTargets: []string{"claude"}},
+		Hooks:         &models.Hooks{PostSwitch: []string{"y"}},
+		Targets:       map[string]string{"t": "/p"},
+	}
+	clone := original.Clone()
+	clone.Configs[0].Models[0] = "changed"
+	clone.Configs[0].Vars["v"] = "changed"
+	clone.Configs[0].Hooks.PreSwitch[0] = "changed"
+	clone.Keybindings["up"][0] = "changed"
+	clone.TestSettings.Timeout = "changed"
+	*clone.Notifications = false
+	clone.Defaults.SyncTargets[0] = "changed"
+	clone.Hooks.PostSwitch[0] = "changed"
+	clone.Targets["t"] = "changed"
+
+	cfg := original.Configs[0]
+	if cfg.Models[0] != "m1" || cfg.Vars["v"] != "1" || cfg.Hooks.PreSwitch[0] != "x" ||
+		original.Keybindings["up"][0] != "k" || original.TestSettings.Timeout != "30s" || !*original.Notifications ||
+		original.Defaults.SyncTargets[0] != "claude" || original.Hooks.PostSwitch[0] != "y" || original.Targets["t"] != "/p" {
+		t.Errorf("changing the clone changed the original: %+v", original)
+	}
+
+	empty := (&models.File{}).Clone()
+	if empty.Configs != nil || empty.Defaults != nil || empty.Hooks != nil {
+		t.Errorf("Clone() of an empty file = %+v, want nil fields kept nil", empty)
+	}
+}
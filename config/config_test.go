@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"apimgr/config/models"
 	"apimgr/config/validation"
@@ -115,24 +117,27 @@ func TestGetActiveEnvOverride(t *testing.T) {
 	cm.SetActive("global")
 
 	// Verify global is active initially
-	active, err := cm.GetActiveName()
+	active, err := cm.GetActiveName(ScopeShell)
 	if err != nil {
-		t.Fatalf("GetActiveName() unexpected error: %v", err)
+		t.Fatalf("GetActiveName(ScopeShell) unexpected error: %v", err)
 	}
 	if active != "global" {
-		t.Errorf("Initial GetActiveName() = %q, want %q", active, "global")
+		t.Errorf("Initial GetActiveName(ScopeShell) = %q, want %q", active, "global")
 	}
 
 	// Set environment variable override
 	t.Setenv("APIMGR_ACTIVE", "local")
 
 	// Verify local is now active via GetActiveName
-	active, err = cm.GetActiveName()
+	active, err = cm.GetActiveName(ScopeShell)
 	if err != nil {
-		t.Fatalf("GetActiveName() unexpected error: %v", err)
+		t.Fatalf("GetActiveName(ScopeShell) unexpected error: %v", err)
 	}
 	if active != "local" {
-		t.Errorf("Overridden GetActiveName() = %q, want %q", active, "local")
+		t.Errorf("Overridden GetActiveName(ScopeShell) = %q, want %q", active, "local")
+	}
+	if active, _ := cm.GetActiveName(ScopeGlobal); active != "global" {
+		t.Errorf("GetActiveName(ScopeGlobal) = %q, want %q ignoring the override", active, "global")
 	}
 
 	// Verify local is now active via GetActive
@@ -198,15 +203,70 @@ func TestSetActive(t *testing.T) {
 					t.Errorf("SetActive(%q) unexpected error: %v", tt.alias, err)
 				}
 				// Verify active was set correctly
-				activeName, _ := cm.GetActiveName()
+				activeName, _ := cm.GetActiveName(ScopeShell)
 				if activeName != tt.alias {
-					t.Errorf("GetActiveName() = %q, want %q", activeName, tt.alias)
+					t.Errorf("GetActiveName(ScopeShell) = %q, want %q", activeName, tt.alias)
 				}
 			}
 		})
 	}
 }
 
+// TestUsageTimestamps tests that key changes and switches are timestamped
+func TestUsageTimestamps(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-old"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	cfg, _ := cm.Get("work")
+	added := cfg.KeyUpdatedAt
+	if added.IsZero() {
+		t.Fatal("Add() should set KeyUpdatedAt")
+	}
+	if !cfg.LastUsedAt.IsZero() {
+		t.Errorf("LastUsedAt = %v before any switch, want zero", cfg.LastUsedAt)
+	}
+
+	if err := cm.UpdatePartial("work", map[string]string{"base_url": "https://example.com"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	cfg, _ = cm.Get("work")
+	if !cfg.KeyUpdatedAt.Equal(added) {
+		t.Errorf("KeyUpdatedAt changed without a key change: %v -> %v", added, cfg.KeyUpdatedAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := cm.UpdatePartial("work", map[string]string{"api_key": "sk-new"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	cfg, _ = cm.Get("work")
+	if !cfg.KeyUpdatedAt.After(added) {
+		t.Errorf("KeyUpdatedAt = %v, want after %v once the key changed", cfg.KeyUpdatedAt, added)
+	}
+
+	if err := cm.SetActive("work"); err != nil {
+		t.Fatalf("SetActive() unexpected error: %v", err)
+	}
+	cfg, _ = cm.Get("work")
+	used := cfg.LastUsedAt
+	if used.IsZero() {
+		t.Error("SetActive() should set LastUsedAt")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := cm.MarkUsed("work"); err != nil {
+		t.Fatalf("MarkUsed() unexpected error: %v", err)
+	}
+	cfg, _ = cm.Get("work")
+	if !cfg.LastUsedAt.After(used) {
+		t.Errorf("MarkUsed() LastUsedAt = %v, want after %v", cfg.LastUsedAt, used)
+	}
+	if err := cm.MarkUsed("missing"); err == nil {
+		t.Error("MarkUsed() on a missing alias expected error")
+	}
+}
+
 // TestGetActive tests getting the active configuration
 func TestGetActive(t *testing.T) {
 	tests := []struct {
@@ -310,6 +370,58 @@ func TestGenerateActiveScript(t *testing.T) {
 		}
 	})
 
+	t.Run("uses provider env var names", func(t *testing.T) {
+		cm := setupTestConfig(t)
+		cm.Add(models.APIConfig{
+			Alias:    "oa",
+			Provider: "openai",
+			APIKey:   "sk-openai",
+			BaseURL:  "https://api.openai.com/v1",
+			Model:    "gpt-4o",
+		})
+		cm.SetActive("oa")
+
+		activeEnvPath := filepath.Join(filepath.Dir(cm.configPath), "active.env")
+		data, err := os.ReadFile(activeEnvPath)
+		if err != nil {
+			t.Fatalf("Failed to read active.env: %v", err)
+		}
+
+		content := string(data)
+		for _, want := range []string{`export OPENAI_API_KEY="sk-openai"`, `export OPENAI_BASE_URL=`, `export OPENAI_MODEL="gpt-4o"`, "unset ANTHROPIC_API_KEY"} {
+			if !contains(content, want) {
+				t.Errorf("active.env should contain %q, got:\n%s", want, content)
+			}
+		}
+		if contains(content, "export ANTHROPIC_") {
+			t.Error("active.env should not export ANTHROPIC_* for an openai config")
+		}
+	})
+
+	t.Run("exports client certificate paths", func(t *testing.T) {
+		cm := setupTestConfig(t)
+		cm.Add(models.APIConfig{
+			Alias:      "corp",
+			APIKey:     "sk-corp",
+			ClientCert: "/etc/apimgr/client.pem",
+			ClientKey:  "/etc/apimgr/client-key.pem",
+		})
+		cm.SetActive("corp")
+
+		activeEnvPath := filepath.Join(filepath.Dir(cm.configPath), "active.env")
+		data, err := os.ReadFile(activeEnvPath)
+		if err != nil {
+			t.Fatalf("Failed to read active.env: %v", err)
+		}
+
+		content := string(data)
+		for _, want := range []string{`export CLAUDE_CODE_CLIENT_CERT="/etc/apimgr/client.pem"`, `export CLAUDE_CODE_CLIENT_KEY="/etc/apimgr/client-key.pem"`, "unset CLAUDE_CODE_CLIENT_CERT"} {
+			if !contains(content, want) {
+				t.Errorf("active.env should contain %q, got:\n%s", want, content)
+			}
+		}
+	})
+
 	t.Run("cleans up active.env when no active config", func(t *testing.T) {
 		cm := setupTestConfig(t)
 
@@ -376,6 +488,192 @@ func TestUpdatePartial(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "update TLS options",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias: "test",
+			updates: map[string]string{
+				"insecure_skip_verify": "true",
+				"ca_bundle":            "/etc/ssl/private-ca.pem",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if !cfg.InsecureSkipVerify {
+					t.Error("InsecureSkipVerify = false, want true")
+				}
+				if cfg.CABundle != "/etc/ssl/private-ca.pem" {
+					t.Errorf("CABundle = %q, want %q", cfg.CABundle, "/etc/ssl/private-ca.pem")
+				}
+			},
+		},
+		{
+			name: "invalid insecure_skip_verify value returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"insecure_skip_verify": "maybe"},
+			wantErr:   true,
+			errSubstr: "invalid insecure_skip_verify",
+		},
+		{
+			name: "update API version and beta features",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias: "test",
+			updates: map[string]string{
+				"api_version":   "2024-01-01",
+				"beta_features": "beta-1, beta-2,",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if cfg.APIVersion != "2024-01-01" {
+					t.Errorf("APIVersion = %q, want %q", cfg.APIVersion, "2024-01-01")
+				}
+				if strings.Join(cfg.BetaFeatures, ",") != "beta-1,beta-2" {
+					t.Errorf("BetaFeatures = %q, want [beta-1 beta-2]", cfg.BetaFeatures)
+				}
+			},
+		},
+		{
+			name: "update organization and project of an openai config",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", Provider: "openai", APIKey: "sk-test"})
+			},
+			alias:   "test",
+			updates: map[string]string{"org_id": "org-1", "project_id": "proj_1"},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if cfg.OrgID != "org-1" || cfg.ProjectID != "proj_1" {
+					t.Errorf("OrgID, ProjectID = %q, %q, want org-1, proj_1", cfg.OrgID, cfg.ProjectID)
+				}
+			},
+		},
+		{
+			name: "organization of an anthropic config returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"org_id": "org-1"},
+			wantErr:   true,
+			errSubstr: "has no organization ID",
+		},
+		{
+			name: "key pool replaces a key outside it",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-old"})
+			},
+			alias:   "test",
+			updates: map[string]string{"api_keys": "sk-one, sk-two", "key_strategy": "lru"},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if cfg.APIKey != "sk-one" || strings.Join(cfg.APIKeys, ",") != "sk-one,sk-two" {
+					t.Errorf("APIKey, APIKeys = %q, %q, want sk-one, [sk-one sk-two]", cfg.APIKey, cfg.APIKeys)
+				}
+				if cfg.KeyStrategy != "lru" {
+					t.Errorf("KeyStrategy = %q, want lru", cfg.KeyStrategy)
+				}
+			},
+		},
+		{
+			name: "API key outside the key pool returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKeys: []string{"sk-one", "sk-two"}})
+			},
+			alias:     "test",
+			updates:   map[string]string{"api_key": "sk-other"},
+			wantErr:   true,
+			errSubstr: "must be one of api_keys",
+		},
+		{
+			name: "unknown key strategy returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKeys: []string{"sk-one", "sk-two"}})
+			},
+			alias:     "test",
+			updates:   map[string]string{"key_strategy": "random"},
+			wantErr:   true,
+			errSubstr: "unknown key strategy",
+		},
+		{
+			name: "update and clear generation parameters",
+			setup: func(cm *Manager) {
+				temperature := 0.5
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test", Temperature: &temperature})
+			},
+			alias:   "test",
+			updates: map[string]string{"temperature": "", "max_tokens": "4096", "top_p": "0.9"},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if cfg.Temperature != nil || cfg.MaxTokens != 4096 || cfg.TopP == nil || *cfg.TopP != 0.9 {
+					t.Errorf("Temperature, MaxTokens, TopP = %v, %d, %v, want unset, 4096, 0.9", cfg.Temperature, cfg.MaxTokens, cfg.TopP)
+				}
+			},
+		},
+		{
+			name: "temperature above the anthropic range returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"temperature": "1.5"},
+			wantErr:   true,
+			errSubstr: "temperature must be between 0 and 1",
+		},
+		{
+			name: "invalid max_tokens value returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"max_tokens": "lots"},
+			wantErr:   true,
+			errSubstr: "invalid max_tokens value",
+		},
+		{
+			name: "API version with a space returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"api_version": "2024 01"},
+			wantErr:   true,
+			errSubstr: "invalid API version",
+		},
+		{
+			name: "update chat path",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:   "test",
+			updates: map[string]string{"chat_path": " /api/claude/v1/messages "},
+			wantErr: false,
+			verify: func(t *testing.T, cm *Manager) {
+				cfg, _ := cm.Get("test")
+				if cfg.ChatPath != "/api/claude/v1/messages" {
+					t.Errorf("ChatPath = %q, want %q", cfg.ChatPath, "/api/claude/v1/messages")
+				}
+			},
+		},
+		{
+			name: "relative chat path returns error",
+			setup: func(cm *Manager) {
+				cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"})
+			},
+			alias:     "test",
+			updates:   map[string]string{"chat_path": "v1/messages"},
+			wantErr:   true,
+			errSubstr: "invalid chat path",
+		},
 		{
 			name: "update non-existent config returns error",
 			setup: func(cm *Manager) {
@@ -478,7 +776,7 @@ func TestRenameAlias(t *testing.T) {
 			newAlias: "renamed",
 			wantErr:  false,
 			verify: func(t *testing.T, cm *Manager) {
-				activeName, _ := cm.GetActiveName()
+				activeName, _ := cm.GetActiveName(ScopeShell)
 				if activeName != "renamed" {
 					t.Errorf("active = %q, want %q", activeName, "renamed")
 				}
@@ -524,3 +822,304 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// TestGetKeybindings tests that keybinding overrides are read and survive other writes
+func TestGetKeybindings(t *testing.T) {
+	cm := setupTestConfig(t)
+	content := `{"active":"","configs":[],"keybindings":{"switch_local":["l"],"delete":[]}}`
+	if err := os.WriteFile(cm.configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Writing a config must not drop the keybindings section
+	if err := cm.Add(models.APIConfig{Alias: "test", APIKey: "sk-test"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	bindings, err := cm.GetKeybindings()
+	if err != nil {
+		t.Fatalf("GetKeybindings() unexpected error: %v", err)
+	}
+	if got := bindings["switch_local"]; len(got) != 1 || got[0] != "l" {
+		t.Errorf("GetKeybindings()[switch_local] = %v, want [l]", got)
+	}
+	if got, ok := bindings["delete"]; !ok || len(got) != 0 {
+		t.Errorf("GetKeybindings()[delete] = %v, %v, want empty list", got, ok)
+	}
+}
+
+// TestGetTestSettings tests reading the compatibility test defaults
+func TestGetTestSettings(t *testing.T) {
+	cm := setupTestConfig(t)
+
+	settings, err := cm.GetTestSettings()
+	if err != nil {
+		t.Fatalf("GetTestSettings() unexpected error: %v", err)
+	}
+	if settings != nil {
+		t.Errorf("GetTestSettings() = %+v, want nil without a config file", settings)
+	}
+
+	content := `{"active":"","configs":[],"test_settings":{"timeout":"45s","retries":2}}`
+	if err := os.WriteFile(cm.configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	settings, err = cm.GetTestSettings()
+	if err != nil {
+		t.Fatalf("GetTestSettings() unexpected error: %v", err)
+	}
+	if settings == nil || settings.Timeout != "45s" || settings.Retries != 2 {
+		t.Errorf("GetTestSettings() = %+v, want timeout 45s and 2 retries", settings)
+	}
+}
+
+// TestNotificationsEnabled tests that notifications default to on
+func TestNotificationsEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "unset", content: `{"active":"","configs":[]}`, want: true},
+		{name: "enabled", content: `{"active":"","configs":[],"notifications":true}`, want: true},
+		{name: "disabled", content: `{"active":"","configs":[],"notifications":false}`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupTestConfig(t)
+			if err := os.WriteFile(cm.configPath, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			got, err := cm.NotificationsEnabled()
+			if err != nil {
+				t.Fatalf("NotificationsEnabled() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NotificationsEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetPrevious tests that global switches remember the previously active config
+func TestGetPrevious(t *testing.T) {
+	cm := setupTestConfig(t)
+	t.Setenv("HOME", t.TempDir())
+	cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work"})
+	cm.Add(models.APIConfig{Alias: "home", APIKey: "sk-home"})
+
+	if _, err := cm.GetPrevious(); err == nil {
+		t.Error("GetPrevious() expected error before any switch")
+	}
+
+	cm.SetActive("work")
+	cm.SetActive("home")
+	if prev, err := cm.GetPrevious(); err != nil || prev != "work" {
+		t.Errorf("GetPrevious() = %q, %v, want %q", prev, err, "work")
+	}
+
+	// Re-activating the same config keeps the previous one
+	cm.SetActive("home")
+	if prev, _ := cm.GetPrevious(); prev != "work" {
+		t.Errorf("GetPrevious() after same switch = %q, want %q", prev, "work")
+	}
+
+	// Toggling flips the pair
+	cm.SetActive("work")
+	if prev, _ := cm.GetPrevious(); prev != "home" {
+		t.Errorf("GetPrevious() after toggle = %q, want %q", prev, "home")
+	}
+
+	// Renaming and removing keep the previous alias consistent
+	if err := cm.RenameAlias("home", "house"); err != nil {
+		t.Fatalf("RenameAlias() unexpected error: %v", err)
+	}
+	if prev, _ := cm.GetPrevious(); prev != "house" {
+		t.Errorf("GetPrevious() after rename = %q, want %q", prev, "house")
+	}
+	cm.Remove("house")
+	if _, err := cm.GetPrevious(); err == nil {
+		t.Error("GetPrevious() expected error after previous config was removed")
+	}
+}
+
+// TestResolveAlias tests exact, prefix and glob alias resolution
+func TestResolveAlias(t *testing.T) {
+	cm := setupTestConfig(t)
+	for _, alias := range []string{"work", "work-eu", "personal", "proxy"} {
+		cm.Add(models.APIConfig{Alias: alias, APIKey: "sk-" + alias})
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "exact match wins over prefix", pattern: "work", want: "work"},
+		{name: "unique prefix", pattern: "pe", want: "personal"},
+		{name: "ambiguous prefix", pattern: "p", wantErr: true},
+		{name: "unique glob", pattern: "*-eu", want: "work-eu"},
+		{name: "ambiguous glob", pattern: "wo*", wantErr: true},
+		{name: "no match", pattern: "missing", wantErr: true},
+		{name: "invalid glob", pattern: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cm.ResolveAlias(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveAlias(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveAlias(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+
+	// Ambiguity errors list the candidates
+	_, err := cm.ResolveAlias("wo*")
+	if err == nil || !strings.Contains(err.Error(), "work, work-eu") {
+		t.Errorf("ResolveAlias(\"wo*\") error = %v, want candidate list", err)
+	}
+}
+
+// TestResolveAliasInEnvironment tests alias resolution scoped to one environment
+func TestResolveAliasInEnvironment(t *testing.T) {
+	cm := setupTestConfig(t)
+	cm.Add(models.APIConfig{Alias: "openai-dev", APIKey: "sk-1", Environment: "dev"})
+	cm.Add(models.APIConfig{Alias: "openai-prod", APIKey: "sk-2", Environment: "prod"})
+	cm.Add(models.APIConfig{Alias: "claude-prod", APIKey: "sk-3", Environment: "prod"})
+
+	tests := []struct {
+		name        string
+		pattern     string
+		environment string
+		want        string
+		wantErr     bool
+	}{
+		{name: "prefix within prod", pattern: "openai", environment: "prod", want: "openai-prod"},
+		{name: "prefix within dev", pattern: "openai", environment: "dev", want: "openai-dev"},
+		{name: "exact alias in environment", pattern: "claude-prod", environment: "prod", want: "claude-prod"},
+		{name: "alias from another environment", pattern: "openai-dev", environment: "prod", wantErr: true},
+		{name: "unknown environment", pattern: "openai", environment: "staging", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cm.ResolveAliasInEnvironment(tt.pattern, tt.environment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveAliasInEnvironment(%q, %q) error = %v, wantErr %v", tt.pattern, tt.environment, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveAliasInEnvironment(%q, %q) = %q, want %q", tt.pattern, tt.environment, got, tt.want)
+			}
+		})
+	}
+
+	// Environment can be changed through partial updates
+	if err := cm.UpdatePartial("openai-dev", map[string]string{"environment": "staging"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	if got, err := cm.ResolveAliasInEnvironment("openai", "staging"); err != nil || got != "openai-dev" {
+		t.Errorf("ResolveAliasInEnvironment after update = %q, %v, want %q", got, err, "openai-dev")
+	}
+}
+
+func TestUpdateRequestSettings(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		updates     map[string]string
+		wantTimeout string
+		wantRetries string
+		wantErr     bool
+	}{
+		{name: "set", updates: map[string]string{"api_timeout": " 10m ", "max_retries": "5"}, wantTimeout: "10m", wantRetries: "5"},
+		{name: "no retries", updates: map[string]string{"max_retries": "0"}, wantTimeout: "10m", wantRetries: "0"},
+		{name: "clear", updates: map[string]string{"api_timeout": "", "max_retries": ""}},
+		{name: "bad timeout", updates: map[string]string{"api_timeout": "10"}, wantErr: true},
+		{name: "negative timeout", updates: map[string]string{"api_timeout": "-1s"}, wantErr: true},
+		{name: "negative retries", updates: map[string]string{"max_retries": "-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cm.UpdatePartial("relay", tt.updates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdatePartial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			cfg, _ := cm.Get("relay")
+			if cfg.APITimeout != tt.wantTimeout || formatOptionalCount(cfg.MaxRetries) != tt.wantRetries {
+				t.Errorf("api_timeout = %q, max_retries = %q, want %q, %q", cfg.APITimeout, formatOptionalCount(cfg.MaxRetries), tt.wantTimeout, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestUpdateKeyExpiry(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		updates map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "set", updates: map[string]string{"key_expires_at": "2025-12-31"}, want: "2025-12-31"},
+		{name: "bad date", updates: map[string]string{"key_expires_at": "31/12/2025"}, want: "2025-12-31", wantErr: true},
+		{name: "new key clears it", updates: map[string]string{"api_key": "sk-new"}},
+		{name: "new key with its expiry", updates: map[string]string{"api_key": "sk-newer", "key_expires_at": "2026-03-01"}, want: "2026-03-01"},
+		{name: "clear", updates: map[string]string{"key_expires_at": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cm.UpdatePartial("relay", tt.updates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdatePartial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			cfg, _ := cm.Get("relay")
+			if got := formatOptionalDate(cfg.KeyExpiresAt); got != tt.want {
+				t.Errorf("key_expires_at = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePartialAliasAndModels(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com", Model: "m1", Models: []string{"m1"}}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	// A failing field leaves the rename and the models unsaved
+	err := cm.UpdatePartial("relay", map[string]string{"alias": "renamed", "models": "m2,m3", "max_tokens": "many"})
+	if err == nil {
+		t.Fatal("UpdatePartial() with an invalid field succeeded")
+	}
+	if cfg, err := cm.Get("relay"); err != nil || cfg.Model != "m1" || len(cfg.Models) != 1 {
+		t.Errorf("Get(relay) = %+v, %v; want the config unchanged", cfg, err)
+	}
+
+	if err := cm.UpdatePartial("relay", map[string]string{"alias": "renamed", "models": "m2,m3", "max_tokens": "1024"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	cfg, err := cm.Get("renamed")
+	if err != nil {
+		t.Fatalf("Get(renamed) unexpected error: %v", err)
+	}
+	if cfg.Model != "m2" || len(cfg.Models) != 2 || cfg.MaxTokens != 1024 {
+		t.Errorf("Get(renamed) = %+v, want models m2,m3 with m2 active and max_tokens 1024", cfg)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"apimgr/config/storage"
+)
+
+// ConvertFormat rewrites the config file in another format next to the
+// current one and renames the current file with a .bak suffix so the new
+// file is picked up. It returns the path of the new file.
+func (cm *Manager) ConvertFormat(format storage.Format) (string, error) {
+	current := storage.FormatFromPath(cm.configPath)
+	if current == format {
+		return "", fmt.Errorf("config file is already in %s format: %s", format, cm.configPath)
+	}
+	if !storage.FileExists(cm.configPath) {
+		return "", fmt.Errorf("config file does not exist: %s", cm.configPath)
+	}
+
+	newPath := filepath.Join(filepath.Dir(cm.configPath), "config"+format.Extension())
+	if storage.FileExists(newPath) {
+		return "", fmt.Errorf("%s already exists, remove it first", newPath)
+	}
+	backupPath := cm.configPath + ".bak"
+	if storage.FileExists(backupPath) {
+		return "", fmt.Errorf("%s already exists, remove it first", backupPath)
+	}
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	oldPath := cm.configPath
+	cm.configPath = newPath
+	configFile.ETag = "" // The new file does not exist yet
+	if err := cm.saveConfigFile(configFile); err != nil {
+		cm.configPath = oldPath
+		os.Remove(newPath)
+		return "", err
+	}
+	if err := os.Rename(oldPath, backupPath); err != nil {
+		cm.configPath = oldPath
+		os.Remove(newPath)
+		return "", fmt.Errorf("failed to back up %s: %w", oldPath, err)
+	}
+	return newPath, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+)
+
+func TestYAMLConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `# my API keys
+active: work
+configs:
+  - alias: work # company account
+    api_key: sk-work
+    base_url: https://api.example.com
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := storage.FindConfigFile(dir); got != path {
+		t.Fatalf("FindConfigFile() = %s, want %s", got, path)
+	}
+
+	cm := &Manager{configPath: path}
+	cfg, err := cm.Get("work")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if cfg.APIKey != "sk-work" || cfg.BaseURL != "https://api.example.com" {
+		t.Errorf("Get() = %+v, want values from config.yaml", cfg)
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "home", APIKey: "sk-home"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, s := range []string{"# my API keys", "alias: work # company account", "alias: home"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("config.yaml missing %q after Add():\n%s", s, data)
+		}
+	}
+}
+
+func TestConvertFormat(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work", Models: []string{"m1", "m2"}}); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := cm.GetConfigPath()
+
+	if _, err := cm.ConvertFormat(storage.FormatJSON); err == nil {
+		t.Error("ConvertFormat(json) expected error for a JSON config")
+	}
+
+	tomlPath, err := cm.ConvertFormat(storage.FormatTOML)
+	if err != nil {
+		t.Fatalf("ConvertFormat(toml) unexpected error: %v", err)
+	}
+	if filepath.Base(tomlPath) != "config.toml" || cm.GetConfigPath() != tomlPath {
+		t.Errorf("ConvertFormat(toml) path = %s, manager uses %s", tomlPath, cm.GetConfigPath())
+	}
+	if storage.FileExists(jsonPath) || !storage.FileExists(jsonPath+".bak") {
+		t.Error("ConvertFormat() should rename the old file with a .bak suffix")
+	}
+	if got := storage.FindConfigFile(filepath.Dir(tomlPath)); got != tomlPath {
+		t.Errorf("FindConfigFile() = %s, want %s", got, tomlPath)
+	}
+
+	cfg, err := cm.Get("work")
+	if err != nil {
+		t.Fatalf("Get() after convert unexpected error: %v", err)
+	}
+	if cfg.APIKey != "sk-work" || len(cfg.Models) != 2 {
+		t.Errorf("Get() after convert = %+v", cfg)
+	}
+
+	if _, err := cm.ConvertFormat(storage.FormatYAML); err != nil {
+		t.Fatalf("ConvertFormat(yaml) unexpected error: %v", err)
+	}
+	if _, err := cm.ConvertFormat(storage.FormatJSON); err != nil {
+		t.Fatalf("ConvertFormat(json) unexpected error: %v", err)
+	}
+	// config.json.bak from the first conversion would be overwritten
+	if _, err := cm.ConvertFormat(storage.FormatTOML); err == nil {
+		t.Error("ConvertFormat(toml) expected error when config.json.bak exists")
+	}
+}
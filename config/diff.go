@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/utils"
+)
+
+// SwitchDiff is how a switch would change one of the files apimgr writes
+type SwitchDiff struct {
+	Name    string      // ActiveEnvFileName or a sync target name
+	Path    string      // File path
+	Missing bool        // The file does not exist: active.env is created, settings files are skipped
+	Fields  []FieldDiff // A is the current value, B the value after the switch
+}
+
+// Changed reports whether the switch would change any field
+func (d SwitchDiff) Changed() bool {
+	for _, f := range d.Fields {
+		if f.Differs() {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSwitch returns how a global switch to alias would change active.env
+// and every enabled sync target, without writing anything
+func (cm *Manager) DiffSwitch(alias string) ([]SwitchDiff, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := resolveEffective(configFile, alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.CheckPlaceholders(cfg.BaseURL); err != nil {
+		return nil, err
+	}
+
+	activeEnv := SwitchDiff{Name: ActiveEnvFileName, Path: filepath.Join(cm.StateDir(), ActiveEnvFileName)}
+	current, err := os.ReadFile(activeEnv.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		activeEnv.Missing = true
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", activeEnv.Path, err)
+	}
+	activeEnv.Fields = diffEnv(syncpkg.ParseEnvScript(string(current)), syncpkg.ParseEnvScript(syncpkg.GenerateEnvScript(&cfg)))
+	diffs := []SwitchDiff{activeEnv}
+
+	opts := cm.keySyncOptions(&cfg, configFile.Defaults)
+	for _, target := range syncTargets(configFile) {
+		diff := SwitchDiff{Name: target.Name, Path: target.Path}
+		current, err := os.ReadFile(target.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			diff.Missing = true
+			diffs = append(diffs, diff)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", target.Path, err)
+		}
+		updated, err := cm.updateSettingsContent(string(current), &cfg, target.keyOptions(opts))
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", target.Name, target.Path, err)
+		}
+		diff.Fields = diffSettings(current, []byte(updated))
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// diffEnv compares two sets of variables, those apimgr exports first in
+// the order they are unset, then the others by name
+func diffEnv(current, next map[string]string) []FieldDiff {
+	rest := make(map[string]bool)
+	for name := range current {
+		rest[name] = true
+	}
+	for name := range next {
+		rest[name] = true
+	}
+	var names []string
+	for _, name := range syncpkg.EnvUnsetNames() {
+		if rest[name] {
+			names = append(names, name)
+			delete(rest, name)
+		}
+	}
+	names = append(names, slices.Sorted(maps.Keys(rest))...)
+
+	diffs := make([]FieldDiff, 0, len(names))
+	for _, name := range names {
+		diffs = append(diffs, FieldDiff{Name: name, A: current[name], B: next[name], Secret: utils.IsSecretName(name)})
+	}
+	return diffs
+}
+
+// diffSettings compares the env variables and apiKeyHelper of two versions
+// of a Claude Code settings file
+func diffSettings(current, next []byte) []FieldDiff {
+	diffs := diffEnv(syncpkg.ParseSettingsEnv(current), syncpkg.ParseSettingsEnv(next))
+	helper := FieldDiff{Name: syncpkg.APIKeyHelperField, A: syncpkg.ParseKeyHelper(current), B: syncpkg.ParseKeyHelper(next)}
+	if helper.A != "" || helper.B != "" {
+		diffs = append(diffs, helper)
+	}
+	return diffs
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestDiffSwitch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	cm := setupTestConfig(t)
+	user := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(user), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(user, []byte(`{"env": {"FOO": "1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := &models.File{
+		Active:  "a",
+		Targets: map[string]string{"missing": filepath.Join(home, "missing", "settings.json")},
+		Configs: []models.APIConfig{
+			{Alias: "a", APIKey: "sk-a", BaseURL: "https://relay.example.com", Model: "m1"},
+			{Alias: "b", APIKey: "sk-b", BaseURL: "https://relay.example.com"},
+		},
+	}
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+	if err := cm.GenerateActiveScript(); err != nil {
+		t.Fatalf("GenerateActiveScript() unexpected error: %v", err)
+	}
+	before, _ := os.ReadFile(user)
+
+	diffs, err := cm.DiffSwitch("b")
+	if err != nil {
+		t.Fatalf("DiffSwitch() unexpected error: %v", err)
+	}
+	changed := make(map[string][]string)
+	for _, d := range diffs {
+		changed[d.Name] = []string{}
+		for _, f := range d.Fields {
+			if f.Differs() {
+				changed[d.Name] = append(changed[d.Name], f.Name+"="+f.B)
+			}
+		}
+	}
+	want := map[string][]string{
+		ActiveEnvFileName: {"ANTHROPIC_API_KEY=sk-b", "ANTHROPIC_MODEL=", "APIMGR_ACTIVE=b"},
+		"claude":          {"ANTHROPIC_API_KEY=sk-b", "ANTHROPIC_MODEL="},
+		"missing":         {},
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("DiffSwitch() changes = %v, want %v", changed, want)
+	}
+	if !diffs[len(diffs)-1].Missing {
+		t.Error("DiffSwitch() should report the missing target as missing")
+	}
+	if after, _ := os.ReadFile(user); string(after) != string(before) {
+		t.Errorf("DiffSwitch() wrote the settings file: %s", after)
+	}
+
+	diffs, err = cm.DiffSwitch("a")
+	if err != nil {
+		t.Fatalf("DiffSwitch() unexpected error: %v", err)
+	}
+	for _, d := range diffs {
+		if d.Changed() {
+			t.Errorf("DiffSwitch() to the active config changes %s: %+v", d.Name, d.Fields)
+		}
+	}
+}
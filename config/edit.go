@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+	"apimgr/internal/exitcode"
+)
+
+// editFileName is the copy of the config file opened in an editor, kept in
+// the state directory until the edit is saved so a rejected edit can be
+// re-opened
+const editFileName = "config.edit"
+
+// EditableCopy copies the config file to a private file for editing in an
+// external editor and returns its path
+func (cm *Manager) EditableCopy() (string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	etag := etagOf(data)
+	if len(data) == 0 {
+		format := storage.FormatFromPath(cm.configPath)
+		if data, err = storage.Marshal(format, &models.File{Configs: []models.APIConfig{}}, nil); err != nil {
+			return "", fmt.Errorf("failed to serialize config: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(cm.StateDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	path := filepath.Join(cm.StateDir(), editFileName+filepath.Ext(cm.configPath))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if cm.editETags == nil {
+		cm.editETags = make(map[string]string)
+	}
+	cm.editETags[path] = etag
+	return path, nil
+}
+
+// ApplyEditedCopy validates the file written by EditableCopy and replaces
+// the config file with it as is, keeping its comments. An invalid edit
+// leaves the config file and the copy untouched, as does one made while
+// another process changed the config file, which ApplyEditedCopy then saves
+// when called again.
+func (cm *Manager) ApplyEditedCopy(path string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := cm.validateConfigData(storage.FormatFromPath(cm.configPath), data); err != nil {
+		return err
+	}
+
+	etag, ok := cm.editETags[path]
+	if !ok {
+		// A copy made by another process, take the current content as its base
+		current, _ := os.ReadFile(cm.configPath)
+		etag = etagOf(current)
+	}
+	_, err = cm.writeConfigData(etag, func([]byte) ([]byte, error) { return data, nil })
+	if errors.Is(err, ErrConflict) {
+		current, _ := os.ReadFile(cm.configPath)
+		if cm.editETags == nil {
+			cm.editETags = make(map[string]string)
+		}
+		cm.editETags[path] = etagOf(current)
+		return fmt.Errorf("%w while it was being edited, saving the edit again overwrites those changes", ErrConflict)
+	}
+	if err != nil {
+		return err
+	}
+	delete(cm.editETags, path)
+	os.Remove(path)
+	return cm.generateActiveScript()
+}
+
+// validateConfigData parses the content of a config file and validates every
+// configuration in it
+func (cm *Manager) validateConfigData(format storage.Format, data []byte) error {
+	configFile, err := parseConfigData(format, data)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	// Configs may extend or activate the configs of included and team files
+	if err := cm.loadIncludes(configFile); err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+	if err := cm.loadTeam(configFile); err != nil {
+		return exitcode.Wrap(exitcode.Validation, err)
+	}
+
+	seen := make(map[string]bool, len(configFile.Configs))
+	for _, cfg := range configFile.Configs {
+		if seen[cfg.Alias] {
+			return exitcode.New(exitcode.Validation, "configuration '%s' is defined more than once", cfg.Alias)
+		}
+		seen[cfg.Alias] = true
+		if err := validateResolved(configFile.Configs, cfg); err != nil {
+			return fmt.Errorf("configuration '%s': %w", cfg.Alias, err)
+		}
+	}
+	if configFile.Active != "" && !seen[configFile.Active] {
+		return exitcode.New(exitcode.Validation, "active configuration '%s' does not exist", configFile.Active)
+	}
+	return nil
+}
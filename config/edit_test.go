@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestApplyEditedCopy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep Claude Code settings out of the test
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invalid JSON", `{"configs": [`, "failed to parse"},
+		{"invalid config", `{"configs": [{"alias": "work"}]}`, "cannot both be empty"},
+		{"duplicate alias", `{"configs": [{"alias": "a", "api_key": "sk-a"}, {"alias": "a", "api_key": "sk-b"}]}`, "more than once"},
+		{"missing active", `{"active": "gone", "configs": [{"alias": "a", "api_key": "sk-a"}]}`, "'gone' does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := os.ReadFile(cm.GetConfigPath())
+			path, err := cm.EditableCopy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			err = cm.ApplyEditedCopy(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ApplyEditedCopy() error = %v, want %q", err, tt.wantErr)
+			}
+			if code := exitcode.Of(err); code != exitcode.Validation {
+				t.Errorf("exit code = %d, want %d", code, exitcode.Validation)
+			}
+			if after, _ := os.ReadFile(cm.GetConfigPath()); string(after) != string(before) {
+				t.Error("an invalid edit should leave the config file unchanged")
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Error("an invalid edit should keep the copy to re-open")
+			}
+		})
+	}
+
+	t.Run("valid edit keeps comments", func(t *testing.T) {
+		path, err := cm.EditableCopy()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := "{\n  // renamed\n  \"active\": \"home\",\n  \"configs\": [{\"alias\": \"home\", \"api_key\": \"sk-home\"},],\n}\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := cm.ApplyEditedCopy(path); err != nil {
+			t.Fatalf("ApplyEditedCopy() error = %v", err)
+		}
+		if saved, _ := os.ReadFile(cm.GetConfigPath()); string(saved) != content {
+			t.Errorf("config file = %q, want the edited content", saved)
+		}
+		if _, err := cm.Get("home"); err != nil {
+			t.Errorf("Get(home) error = %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("a saved edit should remove the copy")
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"strings"
+
+	"apimgr/config/models"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/utils"
+)
+
+// EnvOverrides returns the ANTHROPIC_*, CLAUDE_CODE_* and API_TIMEOUT_MS
+// variables lookup finds set to a value other than the one cfg syncs into
+// Claude Code settings. Claude Code prefers its process environment over the
+// env of settings.json, so these win over a switch. A is the exported value, B the
+// config's, empty when it sets none.
+func EnvOverrides(cfg *models.APIConfig, lookup func(string) (string, bool)) []FieldDiff {
+	want := make(map[string]string)
+	for _, v := range syncpkg.EnvExports(cfg) {
+		want[v.Name] = v.Value
+	}
+
+	var overrides []FieldDiff
+	for _, name := range syncpkg.EnvUnsetNames() {
+		if !strings.HasPrefix(name, "ANTHROPIC_") && !strings.HasPrefix(name, "CLAUDE_CODE_") && name != syncpkg.APITimeoutEnvVar {
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok || value == "" || value == want[name] {
+			continue
+		}
+		overrides = append(overrides, FieldDiff{Name: name, A: value, B: want[name], Secret: utils.IsSecretName(name)})
+	}
+	return overrides
+}
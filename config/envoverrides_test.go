@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	cfg := &models.APIConfig{Alias: "work", APIKey: "sk-work", BaseURL: "https://relay.example.com", Model: "claude-sonnet-4"}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []FieldDiff
+	}{
+		{name: "nothing exported", env: map[string]string{}, want: nil},
+		{name: "exported by apimgr", env: map[string]string{"ANTHROPIC_API_KEY": "sk-work", "ANTHROPIC_BASE_URL": "https://relay.example.com"}, want: nil},
+		{name: "stale base URL", env: map[string]string{"ANTHROPIC_BASE_URL": "https://old.example.com"}, want: []FieldDiff{
+			{Name: "ANTHROPIC_BASE_URL", A: "https://old.example.com", B: "https://relay.example.com"},
+		}},
+		{name: "token the config does not set", env: map[string]string{"ANTHROPIC_AUTH_TOKEN": "tok-1", "ANTHROPIC_API_KEY": "sk-other"}, want: []FieldDiff{
+			{Name: "ANTHROPIC_API_KEY", A: "sk-other", B: "sk-work", Secret: true},
+			{Name: "ANTHROPIC_AUTH_TOKEN", A: "tok-1", Secret: true},
+		}},
+		{name: "empty and unrelated variables", env: map[string]string{"ANTHROPIC_MODEL": "", "OPENAI_API_KEY": "sk-openai"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+			if got := EnvOverrides(cfg, lookup); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvOverrides() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// conflictAttempts bounds how often update starts over when the config file
+// keeps changing under it
+const conflictAttempts = 5
+
+// ErrConflict is returned when the config file was changed by another
+// process after it was loaded
+var ErrConflict = errors.New("config file was changed by another process")
+
+// etagOf returns the ETag identifying the content of the config file, empty
+// for a missing or empty file
+func etagOf(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
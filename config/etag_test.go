@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestSaveConfigFileConflict(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "a", APIKey: "sk-a"}); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process saves in between
+	other := &Manager{configPath: cm.configPath}
+	if err := other.Add(models.APIConfig{Alias: "b", APIKey: "sk-b"}); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := os.ReadFile(cm.configPath)
+
+	stale.Active = "a"
+	if err := cm.saveConfigFile(stale); !errors.Is(err, ErrConflict) {
+		t.Fatalf("saveConfigFile() of a stale file error = %v, want ErrConflict", err)
+	}
+	if current, _ := os.ReadFile(cm.configPath); string(current) != string(saved) {
+		t.Error("a conflicting save should leave the config file unchanged")
+	}
+
+	fresh, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.saveConfigFile(fresh); err != nil {
+		t.Errorf("saveConfigFile() of a fresh file unexpected error: %v", err)
+	}
+	if err := cm.saveConfigFile(fresh); err != nil {
+		t.Errorf("saveConfigFile() again after saving unexpected error: %v", err)
+	}
+}
+
+func TestUpdateRetriesOnConflict(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "a", APIKey: "sk-a"}); err != nil {
+		t.Fatal(err)
+	}
+	other := &Manager{configPath: cm.configPath}
+
+	attempts := 0
+	err := cm.update(func(configFile *models.File) error {
+		attempts++
+		if attempts == 1 {
+			// Another process saves between this load and save
+			if err := other.Add(models.APIConfig{Alias: "b", APIKey: "sk-b"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		configFile.Active = "a"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update() unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("update() ran %d attempts, want 2", attempts)
+	}
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configFile.Active != "a" || len(configFile.Configs) != 2 {
+		t.Errorf("update() lost a change: active = %q, %d configs", configFile.Active, len(configFile.Configs))
+	}
+}
+
+func TestApplyEditedCopyConflict(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep Claude Code settings out of the test
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "a", APIKey: "sk-a"}); err != nil {
+		t.Fatal(err)
+	}
+	path, err := cm.EditableCopy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := `{"configs": [{"alias": "a", "api_key": "sk-edited"}]}`
+	if err := os.WriteFile(path, []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process saves while the copy is being edited
+	other := &Manager{configPath: cm.configPath}
+	if err := other.Add(models.APIConfig{Alias: "b", APIKey: "sk-b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.ApplyEditedCopy(path); !errors.Is(err, ErrConflict) {
+		t.Fatalf("ApplyEditedCopy() error = %v, want ErrConflict", err)
+	}
+	if _, err := cm.Get("b"); err != nil {
+		t.Errorf("a conflicting edit should leave the config file unchanged: %v", err)
+	}
+
+	// Saving again overwrites the other change on purpose
+	if err := cm.ApplyEditedCopy(path); err != nil {
+		t.Fatalf("ApplyEditedCopy() again unexpected error: %v", err)
+	}
+	if current, _ := os.ReadFile(cm.configPath); string(current) != edited {
+		t.Errorf("config file = %s, want the edit", current)
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// VarFieldPrefix prefixes the fields naming a base URL placeholder value,
+// e.g. vars.region
+const VarFieldPrefix = "vars."
+
+// varNamePattern matches the name of a base URL placeholder
+var varNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// field is a configuration field readable with apimgr get
+type field struct {
+	name     string
+	get      func(cfg *models.APIConfig) string
+	settable bool // Whether apimgr set can change it
+	secret   bool // Whether it is masked unless printed raw
+}
+
+// fields lists the configuration fields in the order apimgr get prints them
+var fields = []field{
+	{name: "alias", get: func(cfg *models.APIConfig) string { return cfg.Alias }, settable: true},
+	{name: "provider", get: func(cfg *models.APIConfig) string { return cfg.Provider }},
+	{name: "api_key", get: func(cfg *models.APIConfig) string { return cfg.APIKey }, settable: true, secret: true},
+	{name: "auth_token", get: func(cfg *models.APIConfig) string { return cfg.AuthToken }, settable: true, secret: true},
+	{name: "api_keys", get: func(cfg *models.APIConfig) string { return strings.Join(cfg.APIKeys, ",") }, settable: true, secret: true},
+	{name: "base_url", get: func(cfg *models.APIConfig) string { return cfg.BaseURL }, settable: true},
+	{name: "region", get: func(cfg *models.APIConfig) string { return cfg.Region }, settable: true},
+	{name: "chat_path", get: func(cfg *models.APIConfig) string { return cfg.ChatPath }, settable: true},
+	{name: "model", get: func(cfg *models.APIConfig) string { return cfg.Model }, settable: true},
+	{name: "models", get: func(cfg *models.APIConfig) string { return strings.Join(cfg.Models, ",") }, settable: true},
+	{name: "environment", get: func(cfg *models.APIConfig) string { return cfg.Environment }, settable: true},
+	{name: "extends", get: func(cfg *models.APIConfig) string { return cfg.Extends }, settable: true},
+	{name: "vars", get: formatVars},
+	{name: "key_mode", get: func(cfg *models.APIConfig) string { return cfg.KeyMode }, settable: true},
+	{name: "key_strategy", get: func(cfg *models.APIConfig) string { return cfg.KeyStrategy }, settable: true},
+	{name: "api_version", get: func(cfg *models.APIConfig) string { return cfg.APIVersion }, settable: true},
+	{name: "beta_features", get: func(cfg *models.APIConfig) string { return strings.Join(cfg.BetaFeatures, ",") }, settable: true},
+	{name: "org_id", get: func(cfg *models.APIConfig) string { return cfg.OrgID }, settable: true},
+	{name: "project_id", get: func(cfg *models.APIConfig) string { return cfg.ProjectID }, settable: true},
+	{name: "temperature", get: func(cfg *models.APIConfig) string { return formatOptionalFloat(cfg.Temperature) }, settable: true},
+	{name: "max_tokens", get: func(cfg *models.APIConfig) string { return formatOptionalInt(cfg.MaxTokens) }, settable: true},
+	{name: "top_p", get: func(cfg *models.APIConfig) string { return formatOptionalFloat(cfg.TopP) }, settable: true},
+	{name: "api_timeout", get: func(cfg *models.APIConfig) string { return cfg.APITimeout }, settable: true},
+	{name: "max_retries", get: func(cfg *models.APIConfig) string { return formatOptionalCount(cfg.MaxRetries) }, settable: true},
+	{name: "key_expires_at", get: func(cfg *models.APIConfig) string { return formatOptionalDate(cfg.KeyExpiresAt) }, settable: true},
+	{name: "insecure_skip_verify", get: func(cfg *models.APIConfig) string { return strconv.FormatBool(cfg.InsecureSkipVerify) }, settable: true},
+	{name: "ca_bundle", get: func(cfg *models.APIConfig) string { return cfg.CABundle }, settable: true},
+	{name: "client_cert", get: func(cfg *models.APIConfig) string { return cfg.ClientCert }, settable: true},
+	{name: "client_key", get: func(cfg *models.APIConfig) string { return cfg.ClientKey }, settable: true},
+}
+
+// FieldNames returns the configuration fields readable with FieldValue
+func FieldNames() []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// SettableFieldNames returns the configuration fields apimgr set can change
+func SettableFieldNames() []string {
+	var names []string
+	for _, f := range fields {
+		if f.settable {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// FieldValue returns the value of a configuration field, and whether it is
+// a secret that should be masked when shown
+func FieldValue(cfg *models.APIConfig, name string) (value string, secret bool, err error) {
+	if varName, ok := strings.CutPrefix(name, VarFieldPrefix); ok && varNamePattern.MatchString(varName) {
+		return cfg.Vars[varName], false, nil
+	}
+	for _, f := range fields {
+		if f.name == name {
+			return f.get(cfg), f.secret, nil
+		}
+	}
+	return "", false, exitcode.New(exitcode.Validation, "unknown field '%s' (available: %s)", name, strings.Join(FieldNames(), ", "))
+}
+
+// CheckSettableField returns an error unless apimgr set can change the field
+func CheckSettableField(name string) error {
+	if varName, ok := strings.CutPrefix(name, VarFieldPrefix); ok && varNamePattern.MatchString(varName) {
+		return nil
+	}
+	for _, f := range fields {
+		if f.name == name && f.settable {
+			return nil
+		}
+	}
+	return exitcode.New(exitcode.Validation, "field '%s' cannot be set (available: %s, %s<name>)", name, strings.Join(SettableFieldNames(), ", "), VarFieldPrefix)
+}
+
+// formatVars lists a config's placeholder values as name=value pairs
+func formatVars(cfg *models.APIConfig) string {
+	pairs := make([]string, 0, len(cfg.Vars))
+	for _, name := range slices.Sorted(maps.Keys(cfg.Vars)) {
+		pairs = append(pairs, name+"="+cfg.Vars[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// splitList splits a comma-separated field value, nil when it is empty
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// formatOptionalFloat formats an optional number, empty when unset
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// formatOptionalInt formats a number where 0 means unset
+func formatOptionalInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// formatOptionalCount formats a count where nil means unset
+func formatOptionalCount(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// parseOptionalFloat parses the value of an optional number field, nil when
+// empty
+func parseOptionalFloat(name, value string) (*float64, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value '%s': %w", name, value, err)
+	}
+	return &number, nil
+}
+
+// parseOptionalInt parses the value of a number field where 0 means unset
+func parseOptionalInt(name, value string) (int, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value '%s': %w", name, value, err)
+	}
+	return number, nil
+}
+
+// parseOptionalCount parses the value of an optional count, nil when empty
+func parseOptionalCount(name, value string) (*int, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value '%s': %w", name, value, err)
+	}
+	return &number, nil
+}
+
+// dateLayout is how date fields are written and read
+const dateLayout = "2006-01-02"
+
+// formatOptionalDate formats the value of an optional date field, empty
+// when unset
+func formatOptionalDate(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(dateLayout)
+}
+
+// parseOptionalDate parses the value of an optional date field, the zero
+// time when empty
+func parseOptionalDate(name, value string) (time.Time, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value '%s', expected a date like 2025-12-31", name, value)
+	}
+	return date, nil
+}
+
+// FieldDiff is one field of two configurations compared side by side
+type FieldDiff struct {
+	Name   string
+	A, B   string
+	Secret bool // Whether the values should be masked when shown
+}
+
+// Differs reports whether the two values differ
+func (d FieldDiff) Differs() bool {
+	return d.A != d.B
+}
+
+// CompareFields returns every field of two configurations in the order
+// apimgr get prints them
+func CompareFields(a, b *models.APIConfig) []FieldDiff {
+	diffs := make([]FieldDiff, len(fields))
+	for i, f := range fields {
+		diffs[i] = FieldDiff{Name: f.name, A: f.get(a), B: f.get(b), Secret: f.secret}
+	}
+	return diffs
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestFieldValue(t *testing.T) {
+	cfg := &models.APIConfig{
+		Alias:              "relay",
+		APIKey:             "sk-relay-1234567890",
+		BaseURL:            "https://relay.example.com",
+		Models:             []string{"a", "b"},
+		InsecureSkipVerify: true,
+	}
+
+	tests := []struct {
+		field      string
+		wantValue  string
+		wantSecret bool
+		wantCode   int
+	}{
+		{"alias", "relay", false, exitcode.Success},
+		{"api_key", "sk-relay-1234567890", true, exitcode.Success},
+		{"base_url", "https://relay.example.com", false, exitcode.Success},
+		{"models", "a,b", false, exitcode.Success},
+		{"insecure_skip_verify", "true", false, exitcode.Success},
+		{"client_key", "", false, exitcode.Success},
+		{"nope", "", false, exitcode.Validation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			value, secret, err := FieldValue(cfg, tt.field)
+			if code := exitcode.Of(err); code != tt.wantCode {
+				t.Fatalf("FieldValue(%q) code = %d, want %d (err %v)", tt.field, code, tt.wantCode, err)
+			}
+			if value != tt.wantValue || secret != tt.wantSecret {
+				t.Errorf("FieldValue(%q) = %q, %v, want %q, %v", tt.field, value, secret, tt.wantValue, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestCheckSettableField(t *testing.T) {
+	for _, name := range SettableFieldNames() {
+		if err := CheckSettableField(name); err != nil {
+			t.Errorf("CheckSettableField(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"provider", "key_updated_at", ""} {
+		if err := CheckSettableField(name); exitcode.Of(err) != exitcode.Validation {
+			t.Errorf("CheckSettableField(%q) = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestCompareFields(t *testing.T) {
+	a := &models.APIConfig{Alias: "a", APIKey: "sk-a", BaseURL: "https://relay.example.com", Models: []string{"m1", "m2"}}
+	b := &models.APIConfig{Alias: "b", APIKey: "sk-b", BaseURL: "https://relay.example.com", Models: []string{"m1"}}
+
+	var differing []string
+	for _, d := range CompareFields(a, b) {
+		if d.Differs() {
+			differing = append(differing, d.Name)
+		}
+		if d.Name == "api_key" && !d.Secret {
+			t.Errorf("api_key is not marked secret")
+		}
+	}
+	if want := []string{"alias", "api_key", "models"}; !slices.Equal(differing, want) {
+		t.Errorf("differing fields = %v, want %v", differing, want)
+	}
+}
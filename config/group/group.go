@@ -0,0 +1,120 @@
+package group
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+)
+
+// Member selection strategies
+const (
+	Weighted = "weighted" // A random member on every switch, by weight, the default
+	Sticky   = "sticky"   // A random member by weight, then the same one in each directory
+)
+
+// Strategies lists the valid group strategy values
+var Strategies = []string{Weighted, Sticky}
+
+// Prefix marks a group name where an alias is expected, as in
+// "apimgr switch group:relays"
+const Prefix = "group:"
+
+// FileName is the sticky choices file kept in the state directory
+const FileName = "groups.json"
+
+// choicesPath returns the sticky choices file stored in the state directory
+func choicesPath(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// loadChoices reads the sticky choices of every group, keyed by group name
+// then directory
+func loadChoices(stateDir string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(choicesPath(stateDir))
+	if os.IsNotExist(err) {
+		return map[string]map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group choices: %v", err)
+	}
+
+	all := map[string]map[string]string{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed to parse group choices: %v", err)
+		}
+	}
+	return all, nil
+}
+
+// saveChoices writes the sticky choices of every group
+func saveChoices(stateDir string, all map[string]map[string]string) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize group choices: %v", err)
+	}
+	return storage.AtomicFileUpdate(choicesPath(stateDir), string(data), nil)
+}
+
+// Pick selects the member of group name to switch to from dir. Sticky
+// groups keep the member first picked in dir for as long as it belongs to
+// the group; weighted groups pick anew every time.
+func Pick(stateDir, name string, g models.Group, dir string) (string, error) {
+	if len(g.Members) == 0 {
+		return "", fmt.Errorf("group '%s' has no members", name)
+	}
+	if g.Strategy != Sticky {
+		return pickWeighted(g.Members, rand.IntN(totalWeight(g.Members))), nil
+	}
+
+	all, err := loadChoices(stateDir)
+	if err != nil {
+		return "", err
+	}
+	if alias, ok := all[name][dir]; ok && isMember(g.Members, alias) {
+		return alias, nil
+	}
+	alias := pickWeighted(g.Members, rand.IntN(totalWeight(g.Members)))
+	if all[name] == nil {
+		all[name] = map[string]string{}
+	}
+	all[name][dir] = alias
+	return alias, saveChoices(stateDir, all)
+}
+
+// weight returns the share of m, at least 1
+func weight(m models.GroupMember) int {
+	return max(m.Weight, 1)
+}
+
+// totalWeight returns the sum of the weights of members
+func totalWeight(members []models.GroupMember) int {
+	total := 0
+	for _, m := range members {
+		total += weight(m)
+	}
+	return total
+}
+
+// pickWeighted returns the member n falls on when the members are laid end
+// to end by weight, n being in [0, totalWeight)
+func pickWeighted(members []models.GroupMember, n int) string {
+	for _, m := range members {
+		if n < weight(m) {
+			return m.Alias
+		}
+		n -= weight(m)
+	}
+	return members[len(members)-1].Alias
+}
+
+// isMember reports whether alias belongs to members
+func isMember(members []models.GroupMember, alias string) bool {
+	return slices.ContainsFunc(members, func(m models.GroupMember) bool { return m.Alias == alias })
+}
@@ -0,0 +1,91 @@
+package group
+
+import (
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestPickWeighted(t *testing.T) {
+	members := []models.GroupMember{
+		{Alias: "relay-a", Weight: 3},
+		{Alias: "relay-b"}, // weight 0 counts as 1
+		{Alias: "relay-c", Weight: 2},
+	}
+	if got := totalWeight(members); got != 6 {
+		t.Fatalf("totalWeight() = %d, want 6", got)
+	}
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: "relay-a"},
+		{n: 2, want: "relay-a"},
+		{n: 3, want: "relay-b"},
+		{n: 4, want: "relay-c"},
+		{n: 5, want: "relay-c"},
+	}
+	for _, tt := range tests {
+		if got := pickWeighted(members, tt.n); got != tt.want {
+			t.Errorf("pickWeighted(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPick_Sticky(t *testing.T) {
+	stateDir := t.TempDir()
+	g := models.Group{
+		Strategy: Sticky,
+		Members:  []models.GroupMember{{Alias: "relay-a"}, {Alias: "relay-b"}, {Alias: "relay-c"}},
+	}
+
+	first, err := Pick(stateDir, "relays", g, "/work/project")
+	if err != nil {
+		t.Fatalf("Pick() unexpected error: %v", err)
+	}
+	for range 10 {
+		if got, _ := Pick(stateDir, "relays", g, "/work/project"); got != first {
+			t.Fatalf("Pick() = %q, want the sticky choice %q", got, first)
+		}
+	}
+
+	// A remembered member that left the group is replaced
+	var rest []models.GroupMember
+	for _, m := range g.Members {
+		if m.Alias != first {
+			rest = append(rest, m)
+		}
+	}
+	g.Members = rest
+	got, err := Pick(stateDir, "relays", g, "/work/project")
+	if err != nil {
+		t.Fatalf("Pick() unexpected error: %v", err)
+	}
+	if got == first || !isMember(g.Members, got) {
+		t.Errorf("Pick() = %q, want a current member other than %q", got, first)
+	}
+	if again, _ := Pick(stateDir, "relays", g, "/work/project"); again != got {
+		t.Errorf("Pick() = %q, want the new sticky choice %q", again, got)
+	}
+}
+
+func TestPick_Weighted(t *testing.T) {
+	g := models.Group{Members: []models.GroupMember{{Alias: "relay-a", Weight: 1}, {Alias: "relay-b", Weight: 1}}}
+	stateDir := t.TempDir()
+	seen := map[string]bool{}
+	for range 200 {
+		alias, err := Pick(stateDir, "relays", g, "/work")
+		if err != nil {
+			t.Fatalf("Pick() unexpected error: %v", err)
+		}
+		seen[alias] = true
+	}
+	if !seen["relay-a"] || !seen["relay-b"] {
+		t.Errorf("Pick() only picked %v, want both members", seen)
+	}
+
+	if _, err := Pick(stateDir, "empty", models.Group{}, "/work"); err == nil {
+		t.Error("Pick() should fail for a group without members")
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"apimgr/config/group"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// PickGroupMember selects the configuration of group name to switch to
+// from dir, with the group's strategy
+func (cm *Manager) PickGroupMember(name, dir string) (string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+	g, ok := configFile.Groups[name]
+	if !ok {
+		return "", exitcode.New(exitcode.NotFound, "group '%s' does not exist", name)
+	}
+	if err := checkGroup(configFile, name, g); err != nil {
+		return "", exitcode.New(exitcode.Validation, "%v", err)
+	}
+	return group.Pick(cm.StateDir(), name, g, dir)
+}
+
+// checkGroup returns an error when g has an unknown strategy, a negative
+// weight or a member that is not a configuration
+func checkGroup(configFile *models.File, name string, g models.Group) error {
+	if g.Strategy != "" && !slices.Contains(group.Strategies, g.Strategy) {
+		return fmt.Errorf("group '%s' has unknown strategy '%s' (available: %s)", name, g.Strategy, strings.Join(group.Strategies, ", "))
+	}
+	for _, m := range g.Members {
+		if m.Weight < 0 {
+			return fmt.Errorf("group '%s' gives '%s' a negative weight", name, m.Alias)
+		}
+		if !slices.ContainsFunc(configFile.Configs, func(c models.APIConfig) bool { return c.Alias == m.Alias }) {
+			return fmt.Errorf("group '%s' member '%s' is not a configuration", name, m.Alias)
+		}
+	}
+	return nil
+}
+
+// renameGroupMembers keeps the groups holding oldAlias pointing at it
+func renameGroupMembers(configFile *models.File, oldAlias, newAlias string) {
+	for _, g := range configFile.Groups {
+		for i := range g.Members {
+			if g.Members[i].Alias == oldAlias {
+				g.Members[i].Alias = newAlias
+			}
+		}
+	}
+}
+
+// removeGroupMember drops alias from every group
+func removeGroupMember(configFile *models.File, alias string) {
+	for name, g := range configFile.Groups {
+		g.Members = slices.DeleteFunc(g.Members, func(m models.GroupMember) bool { return m.Alias == alias })
+		configFile.Groups[name] = g
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"apimgr/config/group"
+	"apimgr/config/models"
+)
+
+func TestPickGroupMember(t *testing.T) {
+	cm := setupTestConfig(t)
+	for _, alias := range []string{"relay-a", "relay-b", "other"} {
+		if err := cm.Add(models.APIConfig{Alias: alias, APIKey: "sk-" + alias}); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+	setGroups := func(groups map[string]models.Group) {
+		t.Helper()
+		if err := cm.update(func(f *models.File) error { f.Groups = groups; return nil }); err != nil {
+			t.Fatalf("update() unexpected error: %v", err)
+		}
+	}
+	setGroups(map[string]models.Group{
+		"relays":  {Strategy: group.Sticky, Members: []models.GroupMember{{Alias: "relay-a", Weight: 2}, {Alias: "relay-b"}}},
+		"bad":     {Strategy: "fastest", Members: []models.GroupMember{{Alias: "relay-a"}}},
+		"missing": {Members: []models.GroupMember{{Alias: "gone"}}},
+	})
+
+	alias, err := cm.PickGroupMember("relays", "/work")
+	if err != nil {
+		t.Fatalf("PickGroupMember() unexpected error: %v", err)
+	}
+	if alias != "relay-a" && alias != "relay-b" {
+		t.Errorf("PickGroupMember() = %q, want a member of the group", alias)
+	}
+	for _, name := range []string{"bad", "missing", "unknown"} {
+		if _, err := cm.PickGroupMember(name, "/work"); err == nil {
+			t.Errorf("PickGroupMember(%q) expected error, got nil", name)
+		}
+	}
+
+	// Renaming and removing configurations keeps the groups consistent
+	if err := cm.RenameAlias("relay-a", "relay-x"); err != nil {
+		t.Fatalf("RenameAlias() unexpected error: %v", err)
+	}
+	if err := cm.Remove("relay-b"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatalf("loadConfigFile() unexpected error: %v", err)
+	}
+	members := configFile.Groups["relays"].Members
+	if len(members) != 1 || members[0].Alias != "relay-x" || members[0].Weight != 2 {
+		t.Errorf("relays members = %+v, want only relay-x with weight 2", members)
+	}
+	if alias, err := cm.PickGroupMember("relays", "/work"); err != nil || alias != "relay-x" {
+		t.Errorf("PickGroupMember() = %q, %v, want relay-x", alias, err)
+	}
+}
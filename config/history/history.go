@@ -0,0 +1,153 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"apimgr/config/storage"
+	"apimgr/internal/utils"
+)
+
+// MaxEntries is the number of results kept per configuration
+const MaxEntries = 50
+
+// Result kinds
+const (
+	KindPing = "ping"
+	KindTest = "test"
+)
+
+// Entry is a single recorded ping or compatibility test result
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Success   bool      `json:"success"`
+	LatencyMs int64     `json:"latency_ms"`
+	Detail    string    `json:"detail,omitempty"` // Error message or compatibility level
+}
+
+// historyPath returns the history file stored in the state directory
+func historyPath(stateDir string) string {
+	return filepath.Join(stateDir, "history.json")
+}
+
+// loadAll reads the history of every configuration, keyed by alias
+func loadAll(stateDir string) (map[string][]Entry, error) {
+	data, err := os.ReadFile(historyPath(stateDir))
+	if os.IsNotExist(err) {
+		return map[string][]Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %v", err)
+	}
+
+	all := map[string][]Entry{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed to parse history: %v", err)
+		}
+	}
+	return all, nil
+}
+
+// Record appends a result to a configuration's history, dropping the oldest
+// entries beyond MaxEntries
+func Record(stateDir, alias string, entry Entry) error {
+	// Tests running side by side record at the same time
+	unlock, err := storage.LockPath(historyPath(stateDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return err
+	}
+
+	// Error details may quote a request, never store keys in them
+	entry.Detail = utils.Redact(entry.Detail)
+	entries := append(all[alias], entry)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	all[alias] = entries
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize history: %v", err)
+	}
+	return storage.AtomicFileUpdate(historyPath(stateDir), string(data), nil)
+}
+
+// Load returns a configuration's recorded results, oldest first
+func Load(stateDir, alias string) ([]Entry, error) {
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	return all[alias], nil
+}
+
+// Last returns at most n of the most recent entries
+func Last(entries []Entry, n int) []Entry {
+	if n <= 0 {
+		return nil
+	}
+	if len(entries) > n {
+		return entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// sparkBlocks are the bar heights used by Sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders latency as one bar per entry, scaled between the fastest
+// and slowest successful result. Failures are shown as "×".
+func Sparkline(entries []Entry) string {
+	var min, max int64 = -1, 0
+	for _, e := range entries {
+		if !e.Success {
+			continue
+		}
+		if min < 0 || e.LatencyMs < min {
+			min = e.LatencyMs
+		}
+		if e.LatencyMs > max {
+			max = e.LatencyMs
+		}
+	}
+
+	var b strings.Builder
+	for _, e := range entries {
+		if !e.Success {
+			b.WriteRune('×')
+			continue
+		}
+		level := 0
+		if max > min {
+			level = int((e.LatencyMs - min) * int64(len(sparkBlocks)-1) / (max - min))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// SuccessRate returns the fraction of entries that succeeded
+func SuccessRate(entries []Entry) float64 {
+	if len(entries) == 0 {
+		return 0
+	}
+	succeeded := 0
+	for _, e := range entries {
+		if e.Success {
+			succeeded++
+		}
+	}
+	return float64(succeeded) / float64(len(entries))
+}
@@ -0,0 +1,113 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	stateDir := t.TempDir()
+
+	entries, err := Load(stateDir, "work")
+	if err != nil {
+		t.Fatalf("Load() without a history file unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Load() without a history file = %v, want empty", entries)
+	}
+
+	for i := 0; i < MaxEntries+5; i++ {
+		if err := Record(stateDir, "work", Entry{Kind: KindPing, Success: true, LatencyMs: int64(i)}); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+	}
+	if err := Record(stateDir, "home", Entry{Kind: KindTest, Detail: "none"}); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+
+	entries, err = Load(stateDir, "work")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("Load() returned %d entries, want %d", len(entries), MaxEntries)
+	}
+	if entries[0].LatencyMs != 5 || entries[len(entries)-1].LatencyMs != MaxEntries+4 {
+		t.Errorf("Load() should keep the newest entries, got %d..%d", entries[0].LatencyMs, entries[len(entries)-1].LatencyMs)
+	}
+
+	home, _ := Load(stateDir, "home")
+	if len(home) != 1 || home[0].Detail != "none" {
+		t.Errorf("Load(home) = %v, want one entry", home)
+	}
+}
+
+func TestRecordConcurrent(t *testing.T) {
+	stateDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Record(stateDir, fmt.Sprintf("config-%d", i), Entry{Kind: KindPing, Success: true}); err != nil {
+				t.Errorf("Record() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		if entries, _ := Load(stateDir, fmt.Sprintf("config-%d", i)); len(entries) != 1 {
+			t.Errorf("Load(config-%d) = %v, want the recorded entry", i, entries)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    string
+	}{
+		{name: "empty", entries: nil, want: ""},
+		{name: "flat", entries: []Entry{{Success: true, LatencyMs: 50}, {Success: true, LatencyMs: 50}}, want: "▁▁"},
+		{name: "scaled", entries: []Entry{{Success: true, LatencyMs: 100}, {Success: true, LatencyMs: 800}, {Success: true, LatencyMs: 450}}, want: "▁█▄"},
+		{name: "failures", entries: []Entry{{Success: true, LatencyMs: 10}, {Success: false, LatencyMs: 9999}, {Success: true, LatencyMs: 80}}, want: "▁×█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.entries); got != tt.want {
+				t.Errorf("Sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastAndSuccessRate(t *testing.T) {
+	entries := []Entry{
+		{Time: time.Unix(1, 0), Success: true},
+		{Time: time.Unix(2, 0), Success: false},
+		{Time: time.Unix(3, 0), Success: true},
+		{Time: time.Unix(4, 0), Success: true},
+	}
+
+	if got := Last(entries, 2); len(got) != 2 || got[0].Time.Unix() != 3 {
+		t.Errorf("Last(2) = %v, want the two newest entries", got)
+	}
+	if got := Last(entries, 10); len(got) != 4 {
+		t.Errorf("Last(10) returned %d entries, want 4", len(got))
+	}
+	if got := Last(entries, 0); len(got) != 0 {
+		t.Errorf("Last(0) = %v, want empty", got)
+	}
+	if got := SuccessRate(entries); got != 0.75 {
+		t.Errorf("SuccessRate() = %v, want 0.75", got)
+	}
+	if got := SuccessRate(nil); got != 0 {
+		t.Errorf("SuccessRate(nil) = %v, want 0", got)
+	}
+}
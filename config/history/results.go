@@ -0,0 +1,96 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+)
+
+// DefaultResultTTL is how long a cached result is reused when the config
+// file sets no test_settings.cache_ttl
+const DefaultResultTTL = 10 * time.Minute
+
+// CachedResult is the last result of one kind of test on a configuration,
+// shown again instead of re-testing while it is fresh
+type CachedResult struct {
+	Time        time.Time       `json:"time"`
+	Success     bool            `json:"success"`
+	Summary     string          `json:"summary,omitempty"` // Short outcome, such as the latency
+	Fingerprint string          `json:"fingerprint"`       // Settings the result was obtained with, see Fingerprint
+	Result      json.RawMessage `json:"result"`            // The result as its producer serialized it
+}
+
+// Fresh reports whether the result is younger than ttl and was obtained with
+// cfg's current settings
+func (r CachedResult) Fresh(cfg *models.APIConfig, ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(r.Time) < ttl && r.Fingerprint == Fingerprint(cfg)
+}
+
+// Fingerprint identifies the settings of cfg a test depends on, so results
+// are not reused after the endpoint, key, model or request headers changed.
+// Keys are hashed, never stored.
+func Fingerprint(cfg *models.APIConfig) string {
+	fields := []string{
+		cfg.Provider, cfg.BaseURL, cfg.ChatPath, cfg.APIKey, cfg.AuthToken, cfg.Model,
+		cfg.APIVersion, strings.Join(cfg.BetaFeatures, ","), cfg.OrgID, cfg.ProjectID,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// resultsPath returns the result cache stored in the state directory
+func resultsPath(stateDir string) string {
+	return filepath.Join(stateDir, "results.json")
+}
+
+// LoadResults returns the cached results of every configuration, keyed by
+// alias then kind
+func LoadResults(stateDir string) (map[string]map[string]CachedResult, error) {
+	data, err := os.ReadFile(resultsPath(stateDir))
+	if os.IsNotExist(err) {
+		return map[string]map[string]CachedResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result cache: %v", err)
+	}
+
+	all := map[string]map[string]CachedResult{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed to parse result cache: %v", err)
+		}
+	}
+	return all, nil
+}
+
+// StoreResult replaces the cached result of a kind of test on a configuration
+func StoreResult(stateDir, alias, kind string, result CachedResult) error {
+	unlock, err := storage.LockPath(resultsPath(stateDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := LoadResults(stateDir)
+	if err != nil {
+		return err
+	}
+	if all[alias] == nil {
+		all[alias] = map[string]CachedResult{}
+	}
+	all[alias][kind] = result
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize result cache: %v", err)
+	}
+	return storage.AtomicFileUpdate(resultsPath(stateDir), string(data), nil)
+}
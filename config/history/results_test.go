@@ -0,0 +1,83 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestStoreAndLoadResults(t *testing.T) {
+	stateDir := t.TempDir()
+	cfg := &models.APIConfig{Alias: "work", APIKey: "sk-work", BaseURL: "https://work.example.com"}
+
+	results, err := LoadResults(stateDir)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("LoadResults() without a cache = %v, %v, want empty", results, err)
+	}
+
+	first := CachedResult{Time: time.Now(), Success: false, Fingerprint: Fingerprint(cfg), Result: json.RawMessage(`{"n":1}`)}
+	second := CachedResult{Time: time.Now(), Success: true, Summary: "120ms", Fingerprint: Fingerprint(cfg), Result: json.RawMessage(`{"n":2}`)}
+	for _, r := range []CachedResult{first, second} {
+		if err := StoreResult(stateDir, "work", KindPing, r); err != nil {
+			t.Fatalf("StoreResult() unexpected error: %v", err)
+		}
+	}
+	if err := StoreResult(stateDir, "work", KindTest, first); err != nil {
+		t.Fatalf("StoreResult() unexpected error: %v", err)
+	}
+
+	results, err = LoadResults(stateDir)
+	if err != nil {
+		t.Fatalf("LoadResults() unexpected error: %v", err)
+	}
+	got := results["work"][KindPing]
+	var payload struct{ N int }
+	if err := json.Unmarshal(got.Result, &payload); err != nil || !got.Success || payload.N != 2 {
+		t.Errorf("cached ping = %+v, want the last one stored", got)
+	}
+	if _, ok := results["work"][KindTest]; !ok {
+		t.Error("cached test missing")
+	}
+}
+
+func TestCachedResultFresh(t *testing.T) {
+	now := time.Now()
+	cfg := &models.APIConfig{Alias: "work", APIKey: "sk-work", BaseURL: "https://work.example.com"}
+	rekeyed := *cfg
+	rekeyed.APIKey = "sk-new"
+	rerouted := *cfg
+	rerouted.ChatPath = "/v1/chat"
+	versioned := *cfg
+	versioned.APIVersion = "2024-01-01"
+	beta := *cfg
+	beta.BetaFeatures = []string{"prompt-caching-2024-07-31"}
+	scoped := *cfg
+	scoped.OrgID = "org-work"
+	result := CachedResult{Time: now.Add(-time.Minute), Fingerprint: Fingerprint(cfg)}
+
+	tests := []struct {
+		name string
+		cfg  *models.APIConfig
+		ttl  time.Duration
+		want bool
+	}{
+		{"within ttl", cfg, 10 * time.Minute, true},
+		{"expired", cfg, 30 * time.Second, false},
+		{"caching disabled", cfg, 0, false},
+		{"key changed", &rekeyed, 10 * time.Minute, false},
+		{"chat path changed", &rerouted, 10 * time.Minute, false},
+		{"api version changed", &versioned, 10 * time.Minute, false},
+		{"beta features changed", &beta, 10 * time.Minute, false},
+		{"organization changed", &scoped, 10 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.Fresh(tt.cfg, tt.ttl, now); got != tt.want {
+				t.Errorf("Fresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
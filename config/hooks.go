@@ -0,0 +1,29 @@
+package config
+
+import "apimgr/config/models"
+
+// SwitchHooks returns the commands run around a switch to alias: the
+// config file's global hooks first, then the configuration's own, which may
+// be inherited
+func (cm *Manager) SwitchHooks(alias string) (models.Hooks, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return models.Hooks{}, err
+	}
+	cfg, err := resolveConfig(configFile.Configs, alias)
+	if err != nil {
+		return models.Hooks{}, err
+	}
+
+	var hooks models.Hooks
+	for _, h := range []*models.Hooks{configFile.Hooks, cfg.Hooks} {
+		if h != nil {
+			hooks.PreSwitch = append(hooks.PreSwitch, h.PreSwitch...)
+			hooks.PostSwitch = append(hooks.PostSwitch, h.PostSwitch...)
+		}
+	}
+	return hooks, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestSwitchHooks(t *testing.T) {
+	cm := setupTestConfig(t)
+	configFile := &models.File{
+		Hooks: &models.Hooks{PreSwitch: []string{"global-pre"}, PostSwitch: []string{"global-post"}},
+		Configs: []models.APIConfig{
+			{Alias: "relay", APIKey: "sk-relay", Hooks: &models.Hooks{PostSwitch: []string{"pkill -HUP proxy"}}},
+			{Alias: "alice", Extends: "relay", APIKey: "sk-alice"},
+			{Alias: "bob", Extends: "relay", APIKey: "sk-bob", Hooks: &models.Hooks{}},
+		},
+	}
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		alias string
+		want  models.Hooks
+	}{
+		{"relay", models.Hooks{PreSwitch: []string{"global-pre"}, PostSwitch: []string{"global-post", "pkill -HUP proxy"}}},
+		{"alice", models.Hooks{PreSwitch: []string{"global-pre"}, PostSwitch: []string{"global-post", "pkill -HUP proxy"}}},
+		{"bob", models.Hooks{PreSwitch: []string{"global-pre"}, PostSwitch: []string{"global-post"}}},
+	}
+	for _, tt := range tests {
+		got, err := cm.SwitchHooks(tt.alias)
+		if err != nil {
+			t.Fatalf("SwitchHooks(%s) unexpected error: %v", tt.alias, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SwitchHooks(%s) = %+v, want %+v", tt.alias, got, tt.want)
+		}
+	}
+
+	if _, err := cm.SwitchHooks("nobody"); err == nil {
+		t.Error("SwitchHooks() of an unknown alias expected error")
+	}
+}
@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+)
+
+// Source is a tool whose configuration can be imported
+type Source string
+
+// Supported import sources
+const (
+	SourceCCSwitch Source = "cc-switch"
+	SourceCCR      Source = "claude-code-router"
+	SourceLLMEnv   Source = "llm-env"
+)
+
+// Sources lists the supported import sources
+var Sources = []Source{SourceCCSwitch, SourceCCR, SourceLLMEnv}
+
+// ParseSource parses a source name, accepting "ccr" for claude-code-router
+func ParseSource(name string) (Source, error) {
+	switch strings.ToLower(name) {
+	case "cc-switch", "ccswitch":
+		return SourceCCSwitch, nil
+	case "claude-code-router", "ccr":
+		return SourceCCR, nil
+	case "llm-env":
+		return SourceLLMEnv, nil
+	default:
+		return "", fmt.Errorf("unsupported import source '%s' (supported: cc-switch, claude-code-router, llm-env)", name)
+	}
+}
+
+// DefaultPath returns where the source keeps its configuration
+func (s Source) DefaultPath(homeDir string) string {
+	switch s {
+	case SourceCCSwitch:
+		return filepath.Join(homeDir, ".cc-switch", "config.json")
+	case SourceCCR:
+		return filepath.Join(homeDir, ".claude-code-router", "config.json")
+	default:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(homeDir, ".config")
+		}
+		return filepath.Join(configHome, "llm-env", "config.conf")
+	}
+}
+
+// Skipped is an entry of the source that could not be converted
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Result holds the configs converted from a source
+type Result struct {
+	Configs []models.APIConfig
+	Skipped []Skipped
+}
+
+// Parse converts the source's configuration into API configs. getenv
+// resolves the environment variables that sources refer to for keys.
+func Parse(source Source, data []byte, getenv func(string) string) (*Result, error) {
+	switch source {
+	case SourceCCSwitch:
+		return parseCCSwitch(data)
+	case SourceCCR:
+		return parseCCR(data, getenv)
+	case SourceLLMEnv:
+		return parseLLMEnv(data, getenv)
+	default:
+		return nil, fmt.Errorf("unsupported import source '%s'", source)
+	}
+}
+
+// ccSwitchProvider is a provider entry of cc-switch
+type ccSwitchProvider struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	SettingsConfig struct {
+		Env map[string]string `json:"env"`
+	} `json:"settingsConfig"`
+}
+
+// ccSwitchApp holds the providers cc-switch manages for one app
+type ccSwitchApp struct {
+	Providers map[string]ccSwitchProvider `json:"providers"`
+}
+
+// parseCCSwitch reads cc-switch's config.json. Newer versions nest the
+// providers under "claude", older ones keep them at the top level.
+func parseCCSwitch(data []byte) (*Result, error) {
+	var file struct {
+		ccSwitchApp
+		Claude *ccSwitchApp `json:"claude"`
+	}
+	if err := json.Unmarshal(storage.StandardizeJSON(data), &file); err != nil {
+		return nil, fmt.Errorf("invalid cc-switch config: %w", err)
+	}
+	app := file.ccSwitchApp
+	if file.Claude != nil {
+		app = *file.Claude
+	}
+
+	ids := make([]string, 0, len(app.Providers))
+	for id := range app.Providers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := &Result{}
+	for _, id := range ids {
+		p := app.Providers[id]
+		name := p.Name
+		if name == "" {
+			name = id
+		}
+		env := p.SettingsConfig.Env
+		cfg := models.APIConfig{
+			Alias:     Alias(name),
+			Provider:  "anthropic",
+			AuthToken: env["ANTHROPIC_AUTH_TOKEN"],
+			BaseURL:   env["ANTHROPIC_BASE_URL"],
+			Model:     env["ANTHROPIC_MODEL"],
+		}
+		if cfg.AuthToken == "" {
+			cfg.APIKey = env["ANTHROPIC_API_KEY"]
+		}
+		if cfg.APIKey == "" && cfg.AuthToken == "" {
+			result.Skipped = append(result.Skipped, Skipped{Name: name, Reason: "no API key or auth token"})
+			continue
+		}
+		result.Configs = append(result.Configs, cfg)
+	}
+	return result, nil
+}
+
+// parseCCR reads claude-code-router's config.json. Keys written as $VAR or
+// ${VAR} are resolved from the environment like the router does.
+func parseCCR(data []byte, getenv func(string) string) (*Result, error) {
+	var file struct {
+		Providers []struct {
+			Name       string   `json:"name"`
+			APIBaseURL string   `json:"api_base_url"`
+			APIKey     string   `json:"api_key"`
+			Models     []string `json:"models"`
+		} `json:"Providers"`
+	}
+	if err := json.Unmarshal(storage.StandardizeJSON(data), &file); err != nil {
+		return nil, fmt.Errorf("invalid claude-code-router config: %w", err)
+	}
+
+	result := &Result{}
+	for _, p := range file.Providers {
+		apiKey := os.Expand(p.APIKey, getenv)
+		if apiKey == "" {
+			result.Skipped = append(result.Skipped, Skipped{Name: p.Name, Reason: "no API key"})
+			continue
+		}
+
+		// The router stores full endpoint URLs, apimgr keeps the base URL
+		provider, baseURL := "openai", p.APIBaseURL
+		switch {
+		case strings.HasSuffix(baseURL, "/chat/completions"):
+			baseURL = strings.TrimSuffix(baseURL, "/chat/completions")
+		case strings.HasSuffix(baseURL, "/v1/messages"):
+			provider, baseURL = "anthropic", strings.TrimSuffix(baseURL, "/v1/messages")
+		}
+
+		cfg := models.APIConfig{
+			Alias:    Alias(p.Name),
+			Provider: provider,
+			APIKey:   apiKey,
+			BaseURL:  baseURL,
+		}
+		if len(p.Models) > 0 {
+			cfg.Model = p.Models[0]
+			cfg.Models = p.Models
+		}
+		result.Configs = append(result.Configs, cfg)
+	}
+	return result, nil
+}
+
+// parseLLMEnv reads llm-env's INI style config.conf. Each section is a
+// provider whose key lives in the environment variable named by api_key_var.
+func parseLLMEnv(data []byte, getenv func(string) string) (*Result, error) {
+	type section struct {
+		name   string
+		values map[string]string
+	}
+	var sections []*section
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			sections = append(sections, &section{name: strings.TrimSpace(line[1 : len(line)-1]), values: map[string]string{}})
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || len(sections) == 0 {
+				return nil, fmt.Errorf("invalid llm-env config: line %d: %s", n, line)
+			}
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			sections[len(sections)-1].values[strings.TrimSpace(key)] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, s := range sections {
+		if s.values["enabled"] == "false" {
+			result.Skipped = append(result.Skipped, Skipped{Name: s.name, Reason: "disabled"})
+			continue
+		}
+		keyVar := s.values["api_key_var"]
+		apiKey := ""
+		if keyVar != "" {
+			apiKey = getenv(keyVar)
+		}
+		if apiKey == "" {
+			reason := "no api_key_var"
+			if keyVar != "" {
+				reason = fmt.Sprintf("%s is not set", keyVar)
+			}
+			result.Skipped = append(result.Skipped, Skipped{Name: s.name, Reason: reason})
+			continue
+		}
+
+		cfg := models.APIConfig{
+			Alias:    Alias(s.name),
+			Provider: "openai",
+			APIKey:   apiKey,
+			BaseURL:  s.values["base_url"],
+			Model:    s.values["default_model"],
+		}
+		if cfg.Model != "" {
+			cfg.Models = []string{cfg.Model}
+		}
+		result.Configs = append(result.Configs, cfg)
+	}
+	return result, nil
+}
+
+// Alias turns a provider name into a valid alias: lower case, spaces and
+// characters not allowed in aliases become dashes
+func Alias(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if r == ' ' || strings.ContainsRune("<>\"'&/\\", r) {
+			dash = true
+			continue
+		}
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		dash = false
+		b.WriteRune(r)
+	}
+	alias := b.String()
+	for len(alias) > 50 {
+		_, size := utf8.DecodeLastRuneInString(alias)
+		alias = strings.TrimRight(alias[:len(alias)-size], "-")
+	}
+	return alias
+}
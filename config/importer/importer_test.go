@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestParse(t *testing.T) {
+	env := map[string]string{"OPENROUTER_KEY": "sk-or-123", "LLM_OPENAI_API_KEY": "sk-oa-456"}
+	getenv := func(name string) string { return env[name] }
+
+	tests := []struct {
+		name        string
+		source      Source
+		data        string
+		wantConfigs []models.APIConfig
+		wantSkipped []string
+	}{
+		{
+			name:   "cc-switch nested under claude",
+			source: SourceCCSwitch,
+			data: `{
+  "claude": {
+    "providers": {
+      "b": {"id": "b", "name": "Kimi K2", "settingsConfig": {"env": {"ANTHROPIC_AUTH_TOKEN": "tok", "ANTHROPIC_BASE_URL": "https://api.moonshot.cn/anthropic"}}},
+      "a": {"id": "a", "name": "Official", "settingsConfig": {"env": {}}},
+    },
+    "current": "b"
+  }
+}`,
+			wantConfigs: []models.APIConfig{
+				{Alias: "kimi-k2", Provider: "anthropic", AuthToken: "tok", BaseURL: "https://api.moonshot.cn/anthropic"},
+			},
+			wantSkipped: []string{"Official"},
+		},
+		{
+			name:   "cc-switch legacy top level",
+			source: SourceCCSwitch,
+			data:   `{"providers": {"x": {"name": "relay", "settingsConfig": {"env": {"ANTHROPIC_API_KEY": "sk-ant", "ANTHROPIC_MODEL": "claude-sonnet-4"}}}}}`,
+			wantConfigs: []models.APIConfig{
+				{Alias: "relay", Provider: "anthropic", APIKey: "sk-ant", Model: "claude-sonnet-4"},
+			},
+		},
+		{
+			name:   "claude-code-router",
+			source: SourceCCR,
+			data: `{
+  "Providers": [
+    {"name": "openrouter", "api_base_url": "https://openrouter.ai/api/v1/chat/completions", "api_key": "$OPENROUTER_KEY", "models": ["a/b", "c/d"]},
+    {"name": "anthropic", "api_base_url": "https://api.anthropic.com/v1/messages", "api_key": "sk-ant"},
+    {"name": "ollama", "api_base_url": "http://localhost:11434/v1/chat/completions", "api_key": "${MISSING}"}
+  ],
+  "Router": {"default": "openrouter,a/b"}
+}`,
+			wantConfigs: []models.APIConfig{
+				{Alias: "openrouter", Provider: "openai", APIKey: "sk-or-123", BaseURL: "https://openrouter.ai/api/v1", Model: "a/b", Models: []string{"a/b", "c/d"}},
+				{Alias: "anthropic", Provider: "anthropic", APIKey: "sk-ant", BaseURL: "https://api.anthropic.com"},
+			},
+			wantSkipped: []string{"ollama"},
+		},
+		{
+			name:   "llm-env",
+			source: SourceLLMEnv,
+			data: `# providers
+[openai]
+base_url=https://api.openai.com/v1
+api_key_var=LLM_OPENAI_API_KEY
+default_model="gpt-5"
+enabled=true
+
+[groq]
+base_url=https://api.groq.com/openai/v1
+api_key_var=LLM_GROQ_API_KEY
+
+[old]
+enabled=false
+`,
+			wantConfigs: []models.APIConfig{
+				{Alias: "openai", Provider: "openai", APIKey: "sk-oa-456", BaseURL: "https://api.openai.com/v1", Model: "gpt-5", Models: []string{"gpt-5"}},
+			},
+			wantSkipped: []string{"groq", "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.source, []byte(tt.data), getenv)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Configs, tt.wantConfigs) {
+				t.Errorf("Parse() configs = %+v, want %+v", result.Configs, tt.wantConfigs)
+			}
+			var skipped []string
+			for _, s := range result.Skipped {
+				skipped = append(skipped, s.Name)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("Parse() skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(SourceCCSwitch, []byte("not json"), nil); err == nil {
+		t.Error("Parse() expected error for invalid cc-switch config")
+	}
+	if _, err := Parse(SourceLLMEnv, []byte("base_url=x"), nil); err == nil {
+		t.Error("Parse() expected error for key outside a section")
+	}
+}
+
+func TestAlias(t *testing.T) {
+	tests := map[string]string{
+		"Kimi K2":          "kimi-k2",
+		"  DeepSeek  ":     "deepseek",
+		"a/b & c":          "a-b-c",
+		"already-an-alias": "already-an-alias",
+	}
+	for name, want := range tests {
+		if got := Alias(name); got != want {
+			t.Errorf("Alias(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+)
+
+// includePath resolves an include entry, relative to the directory of the
+// main config file unless absolute
+func (cm *Manager) includePath(include string) string {
+	path := expandTargetPath(include)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(cm.configPath), path)
+	}
+	return path
+}
+
+// readIncludedFile parses an included file, which holds configs only
+func readIncludedFile(path string) (*models.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included file: %w", err)
+	}
+	return parseIncludedFile(path, data)
+}
+
+// parseIncludedFile parses the content of an included file
+func parseIncludedFile(path string, data []byte) (*models.File, error) {
+	included, err := parseConfigData(storage.FormatFromPath(path), data)
+	if err != nil {
+		return nil, fmt.Errorf("included file %s: %w", path, err)
+	}
+	if len(included.Include) > 0 {
+		return nil, fmt.Errorf("included file %s cannot include other files", path)
+	}
+	return included, nil
+}
+
+// loadIncludes appends the configs of the files configFile includes, each
+// remembering its file in Source. An alias defined twice is an error.
+func (cm *Manager) loadIncludes(configFile *models.File) error {
+	defined := make(map[string]string, len(configFile.Configs))
+	for _, cfg := range configFile.Configs {
+		defined[cfg.Alias] = cm.configPath
+	}
+
+	for _, include := range configFile.Include {
+		path := cm.includePath(include)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read included file: %w", err)
+		}
+		included, err := parseIncludedFile(path, data)
+		if err != nil {
+			return err
+		}
+		for _, cfg := range included.Configs {
+			if where, ok := defined[cfg.Alias]; ok {
+				return fmt.Errorf("configuration '%s' is defined in both %s and %s", cfg.Alias, where, path)
+			}
+			defined[cfg.Alias] = path
+			cfg.Source = path
+			configFile.Configs = append(configFile.Configs, cfg)
+		}
+		configFile.IncludedFiles = append(configFile.IncludedFiles, path)
+		if configFile.IncludedETags == nil {
+			configFile.IncludedETags = make(map[string]string)
+		}
+		configFile.IncludedETags[path] = etagOf(data)
+	}
+	return nil
+}
+
+// mainConfigs returns the configs stored in the main config file
+func mainConfigs(configs []models.APIConfig) []models.APIConfig {
+	main := []models.APIConfig{}
+	for _, cfg := range configs {
+		if cfg.Source == "" {
+			main = append(main, cfg)
+		}
+	}
+	return main
+}
+
+// includedFile is what is written back to an included file
+type includedFile struct {
+	Configs []models.APIConfig `json:"configs"`
+}
+
+// includeWrite is a pending change of an included file, whose lock is held
+// until the change is written or dropped
+type includeWrite struct {
+	path string
+	file *os.File
+	data []byte
+}
+
+// prepareIncludes locks the included files whose configs changed and
+// serializes their new content, before the main file is saved. It returns
+// ErrConflict when one of them changed since configFile was loaded, so
+// that no file is written.
+func (cm *Manager) prepareIncludes(configFile *models.File) ([]includeWrite, error) {
+	var writes []includeWrite
+	for _, path := range configFile.IncludedFiles {
+		configs := []models.APIConfig{}
+		for _, cfg := range configFile.Configs {
+			if cfg.Source == path {
+				configs = append(configs, cfg)
+			}
+		}
+
+		included, err := readIncludedFile(path)
+		if err != nil {
+			cm.releaseIncludes(writes)
+			return nil, err
+		}
+		for i := range included.Configs {
+			included.Configs[i].Source = path
+		}
+		if slices.EqualFunc(included.Configs, configs, func(a, b models.APIConfig) bool { return reflect.DeepEqual(a, b) }) {
+			continue
+		}
+
+		w, err := cm.lockInclude(path, configFile.IncludedETags[path], configs)
+		if err != nil {
+			cm.releaseIncludes(writes)
+			return nil, err
+		}
+		writes = append(writes, w)
+	}
+	return writes, nil
+}
+
+// lockInclude locks an included file, checks it still has the content with
+// the given ETag and serializes configs as its new content
+func (cm *Manager) lockInclude(path, etag string, configs []models.APIConfig) (includeWrite, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return includeWrite{}, fmt.Errorf("failed to open included file: %w", err)
+	}
+	if err := cm.lockFile(file); err != nil {
+		file.Close()
+		return includeWrite{}, fmt.Errorf("failed to lock included file %s: %w", path, err)
+	}
+	w := includeWrite{path: path, file: file}
+
+	previous, err := io.ReadAll(file)
+	if err != nil {
+		cm.releaseIncludes([]includeWrite{w})
+		return includeWrite{}, fmt.Errorf("failed to read included file: %w", err)
+	}
+	if etagOf(previous) != etag {
+		cm.releaseIncludes([]includeWrite{w})
+		return includeWrite{}, ErrConflict
+	}
+	// The current content lets YAML files keep their comments
+	w.data, err = storage.Marshal(storage.FormatFromPath(path), includedFile{Configs: configs}, previous)
+	if err != nil {
+		cm.releaseIncludes([]includeWrite{w})
+		return includeWrite{}, fmt.Errorf("failed to serialize included file: %w", err)
+	}
+	return w, nil
+}
+
+// writeIncludes writes the prepared included files and releases their locks
+func (cm *Manager) writeIncludes(writes []includeWrite) error {
+	defer cm.releaseIncludes(writes)
+	for _, w := range writes {
+		if err := w.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to write included file %s: %w", w.path, err)
+		}
+		if _, err := w.file.WriteAt(w.data, 0); err != nil {
+			return fmt.Errorf("failed to write included file %s: %w", w.path, err)
+		}
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync included file %s: %w", w.path, err)
+		}
+	}
+	return nil
+}
+
+// releaseIncludes unlocks and closes the included files of writes
+func (cm *Manager) releaseIncludes(writes []includeWrite) {
+	for _, w := range writes {
+		if err := cm.unlockFile(w.file); err != nil {
+			slog.Warn("failed to unlock included file", "path", w.path, "error", err)
+		}
+		w.file.Close()
+	}
+}
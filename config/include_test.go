@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+// writeTestFile writes content to name in the directory of the config file
+func writeTestFile(t *testing.T, cm *Manager, name, content string) string {
+	t.Helper()
+	path := filepath.Join(filepath.Dir(cm.configPath), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestIncludes(t *testing.T) {
+	cm := setupTestConfig(t)
+	writeTestFile(t, cm, "config.json", `{"active": "work", "include": ["work.json", "personal.yaml"], "configs": [{"alias": "main", "api_key": "sk-main"}]}`)
+	workPath := writeTestFile(t, cm, "work.json", `{"configs": [{"alias": "work", "api_key": "sk-work", "model": "m1"}]}`)
+	personalPath := writeTestFile(t, cm, "personal.yaml", "# Personal accounts\nconfigs:\n  - alias: home\n    api_key: sk-home\n")
+
+	configs, err := cm.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	var aliases []string
+	for _, cfg := range configs {
+		aliases = append(aliases, cfg.Alias)
+	}
+	if strings.Join(aliases, ",") != "main,work,home" {
+		t.Fatalf("List() aliases = %v, want main, work and home", aliases)
+	}
+	if active, err := cm.GetActive(); err != nil || active.Source != workPath {
+		t.Errorf("GetActive() = %+v, %v, want work from %s", active, err, workPath)
+	}
+
+	// Changes land in the file defining the config
+	if err := cm.UpdatePartial("work", map[string]string{"model": "m2"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "new", APIKey: "sk-new"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.RenameAlias("home", "house"); err != nil {
+		t.Fatalf("RenameAlias() unexpected error: %v", err)
+	}
+	work, _ := os.ReadFile(workPath)
+	if !strings.Contains(string(work), `"model": "m2"`) || strings.Contains(string(work), "source") {
+		t.Errorf("work.json = %s, want the new model and no source", work)
+	}
+	personal, _ := os.ReadFile(personalPath)
+	if !strings.Contains(string(personal), "alias: house") || !strings.Contains(string(personal), "# Personal accounts") {
+		t.Errorf("personal.yaml = %s, want the renamed alias and its comment", personal)
+	}
+	main, _ := os.ReadFile(cm.configPath)
+	if !strings.Contains(string(main), `"new"`) || strings.Contains(string(main), "sk-work") || strings.Contains(string(main), "sk-home") {
+		t.Errorf("config.json = %s, want only its own configs", main)
+	}
+
+	// Switching does not touch the included files
+	before, _ := os.ReadFile(workPath)
+	if err := cm.MarkUsed("work"); err != nil {
+		t.Fatalf("MarkUsed() unexpected error: %v", err)
+	}
+	if after, _ := os.ReadFile(workPath); string(after) != string(before) {
+		t.Errorf("MarkUsed() rewrote work.json:\n%s", after)
+	}
+	if err := cm.SetActive("work"); err != nil {
+		t.Fatalf("SetActive() unexpected error: %v", err)
+	}
+	if after, _ := os.ReadFile(workPath); string(after) != string(before) {
+		t.Errorf("SetActive() rewrote work.json:\n%s", after)
+	}
+
+	// Included files are read again when they change
+	writeTestFile(t, cm, "work.json", `{"configs": [{"alias": "work", "api_key": "sk-work2"}]}`)
+	if work, err := cm.Get("work"); err != nil || work.APIKey != "sk-work2" {
+		t.Errorf("Get() = %+v, %v, want the key written to work.json", work, err)
+	}
+
+	if err := cm.Remove("work"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if work, _ := os.ReadFile(workPath); strings.Contains(string(work), "sk-work2") {
+		t.Errorf("work.json = %s, want the config removed", work)
+	}
+}
+
+func TestIncludeConflict(t *testing.T) {
+	cm := setupTestConfig(t)
+	writeTestFile(t, cm, "config.json", `{"include": ["work.json"], "configs": [{"alias": "main", "api_key": "sk-main"}]}`)
+	writeTestFile(t, cm, "work.json", `{"configs": [{"alias": "work", "api_key": "sk-work"}]}`)
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatalf("loadConfigFile() unexpected error: %v", err)
+	}
+	for i := range configFile.Configs {
+		configFile.Configs[i].Model = "m1"
+	}
+
+	// Another process edits the included file in between
+	edited := `{"configs": [{"alias": "work", "api_key": "sk-edited"}]}`
+	workPath := writeTestFile(t, cm, "work.json", edited)
+	mainBefore, _ := os.ReadFile(cm.configPath)
+	if err := cm.saveConfigFile(configFile); !errors.Is(err, ErrConflict) {
+		t.Fatalf("saveConfigFile() error = %v, want ErrConflict", err)
+	}
+	if work, _ := os.ReadFile(workPath); string(work) != edited {
+		t.Errorf("work.json = %s, want the concurrent edit kept", work)
+	}
+	if main, _ := os.ReadFile(cm.configPath); string(main) != string(mainBefore) {
+		t.Errorf("config.json = %s, want it untouched", main)
+	}
+
+	// Updates start over from the edited file
+	if err := cm.UpdatePartial("work", map[string]string{"model": "m2"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	if work, _ := os.ReadFile(workPath); !strings.Contains(string(work), "sk-edited") || !strings.Contains(string(work), "m2") {
+		t.Errorf("work.json = %s, want the edit and the new model", work)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "duplicate alias",
+			files: map[string]string{
+				"config.json": `{"include": ["work.json"], "configs": [{"alias": "work", "api_key": "sk-a"}]}`,
+				"work.json":   `{"configs": [{"alias": "work", "api_key": "sk-b"}]}`,
+			},
+			wantErr: "is defined in both",
+		},
+		{
+			name: "duplicate alias across included files",
+			files: map[string]string{
+				"config.json": `{"include": ["a.json", "b.json"], "configs": []}`,
+				"a.json":      `{"configs": [{"alias": "work", "api_key": "sk-a"}]}`,
+				"b.json":      `{"configs": [{"alias": "work", "api_key": "sk-b"}]}`,
+			},
+			wantErr: "is defined in both",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{"config.json": `{"include": ["missing.json"], "configs": []}`},
+			wantErr: "failed to read included file",
+		},
+		{
+			name: "nested include",
+			files: map[string]string{
+				"config.json": `{"include": ["a.json"], "configs": []}`,
+				"a.json":      `{"include": ["b.json"], "configs": []}`,
+			},
+			wantErr: "cannot include other files",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupTestConfig(t)
+			for name, content := range tt.files {
+				writeTestFile(t, cm, name, content)
+			}
+			if _, err := cm.List(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("List() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// resolveConfig returns the configuration with the given alias with every
+// unset field filled in from the chain of configurations it extends
+func resolveConfig(configs []models.APIConfig, alias string) (models.APIConfig, error) {
+	var chain []models.APIConfig
+	seen := make(map[string]bool)
+	for current := alias; current != ""; {
+		if seen[current] {
+			return models.APIConfig{}, exitcode.New(exitcode.Validation, "configuration '%s' has an inheritance cycle through '%s'", alias, current)
+		}
+		seen[current] = true
+
+		i := slices.IndexFunc(configs, func(c models.APIConfig) bool { return c.Alias == current })
+		if i < 0 {
+			if current == alias {
+				return models.APIConfig{}, exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+			}
+			return models.APIConfig{}, exitcode.New(exitcode.Validation, "configuration '%s' extends unknown configuration '%s'", chain[len(chain)-1].Alias, current)
+		}
+		chain = append(chain, configs[i])
+		current = configs[i].Extends
+	}
+
+	// Resolve from the root down so each config inherits effective values
+	resolved := chain[len(chain)-1]
+	for i := len(chain) - 2; i >= 0; i-- {
+		child := chain[i]
+		inherit(&child, resolved)
+		resolved = child
+	}
+	return resolved, nil
+}
+
+// ResolveError reports the configurations that cannot be resolved, such as
+// one extending an unknown configuration. List returns it along with the
+// configurations that can.
+type ResolveError struct {
+	Errs []error // One per configuration left out
+}
+
+func (e *ResolveError) Error() string {
+	return errors.Join(e.Errs...).Error()
+}
+
+func (e *ResolveError) Unwrap() []error {
+	return e.Errs
+}
+
+// resolveAll resolves every configuration, keeping their order. The ones
+// that cannot be resolved are left out and reported with a *ResolveError.
+func resolveAll(configs []models.APIConfig) ([]models.APIConfig, error) {
+	resolved := make([]models.APIConfig, 0, len(configs))
+	var errs []error
+	for _, cfg := range configs {
+		r, err := resolveConfig(configs, cfg.Alias)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	if len(errs) > 0 {
+		return resolved, &ResolveError{Errs: errs}
+	}
+	return resolved, nil
+}
+
+// inherit fills the unset fields of child from parent. The API key, auth
+// token and key pool are inherited together, and only when the child sets
+// none of them.
+func inherit(child *models.APIConfig, parent models.APIConfig) {
+	if child.Provider == "" {
+		child.Provider = parent.Provider
+	}
+	if child.APIKey == "" && child.AuthToken == "" {
+		child.APIKey = parent.APIKey
+		child.AuthToken = parent.AuthToken
+		child.KeyUpdatedAt = parent.KeyUpdatedAt
+		child.KeyExpiresAt = parent.KeyExpiresAt
+		child.APIKeys = slices.Clone(parent.APIKeys)
+		child.KeyStrategy = parent.KeyStrategy
+	}
+	if child.BaseURL == "" {
+		child.BaseURL = parent.BaseURL
+		child.Region = parent.Region
+	}
+	switch {
+	case len(child.Models) == 0 && child.Model == "":
+		child.Model = parent.Model
+		child.Models = slices.Clone(parent.Models)
+	case len(child.Models) == 0:
+		// Keep the child's model selectable from the inherited list
+		child.Models = slices.Clone(parent.Models)
+		if !slices.Contains(child.Models, child.Model) {
+			child.Models = append([]string{child.Model}, child.Models...)
+		}
+	case child.Model == "":
+		child.Model = child.Models[0]
+		if slices.Contains(child.Models, parent.Model) {
+			child.Model = parent.Model
+		}
+	}
+	if child.Environment == "" {
+		child.Environment = parent.Environment
+	}
+	if len(parent.Vars) > 0 {
+		vars := maps.Clone(parent.Vars)
+		maps.Copy(vars, child.Vars)
+		child.Vars = vars
+	}
+	child.InsecureSkipVerify = child.InsecureSkipVerify || parent.InsecureSkipVerify
+	if child.CABundle == "" {
+		child.CABundle = parent.CABundle
+	}
+	if child.ClientCert == "" && child.ClientKey == "" {
+		child.ClientCert = parent.ClientCert
+		child.ClientKey = parent.ClientKey
+	}
+	if child.Hooks == nil {
+		child.Hooks = parent.Hooks
+	}
+	if child.KeyMode == "" {
+		child.KeyMode = parent.KeyMode
+	}
+	if child.ChatPath == "" {
+		child.ChatPath = parent.ChatPath
+	}
+	if child.APIVersion == "" {
+		child.APIVersion = parent.APIVersion
+	}
+	if child.BetaFeatures == nil {
+		child.BetaFeatures = parent.BetaFeatures
+	}
+	if child.OrgID == "" {
+		child.OrgID = parent.OrgID
+	}
+	if child.ProjectID == "" {
+		child.ProjectID = parent.ProjectID
+	}
+	if child.Temperature == nil {
+		child.Temperature = parent.Temperature
+	}
+	if child.MaxTokens == 0 {
+		child.MaxTokens = parent.MaxTokens
+	}
+	if child.TopP == nil {
+		child.TopP = parent.TopP
+	}
+	if child.APITimeout == "" {
+		child.APITimeout = parent.APITimeout
+	}
+	if child.MaxRetries == nil {
+		child.MaxRetries = parent.MaxRetries
+	}
+}
+
+// extendedBy returns the aliases of the configurations extending alias
+func extendedBy(configs []models.APIConfig, alias string) []string {
+	var children []string
+	for _, cfg := range configs {
+		if cfg.Extends == alias {
+			children = append(children, cfg.Alias)
+		}
+	}
+	return children
+}
+
+// dropInheritedUpdates returns updates without the values cfg already
+// inherits, so saving a form prefilled with effective values keeps the
+// fields inherited rather than copying them into cfg
+func dropInheritedUpdates(configs []models.APIConfig, cfg models.APIConfig, updates map[string]string) map[string]string {
+	resolved, err := resolveConfig(configs, cfg.Alias)
+	if err != nil {
+		return updates
+	}
+
+	inherited := map[string]string{}
+	if cfg.APIKey == "" && cfg.AuthToken == "" {
+		inherited["api_key"] = resolved.APIKey
+		inherited["auth_token"] = resolved.AuthToken
+		inherited["api_keys"] = strings.Join(resolved.APIKeys, ",")
+		inherited["key_strategy"] = resolved.KeyStrategy
+		inherited["key_expires_at"] = formatOptionalDate(resolved.KeyExpiresAt)
+	}
+	if cfg.BaseURL == "" {
+		inherited["base_url"] = resolved.BaseURL
+		inherited["region"] = resolved.Region
+	}
+	if cfg.Model == "" {
+		inherited["model"] = resolved.Model
+	}
+	if cfg.Environment == "" {
+		inherited["environment"] = resolved.Environment
+	}
+	if cfg.CABundle == "" {
+		inherited["ca_bundle"] = resolved.CABundle
+	}
+	if cfg.ChatPath == "" {
+		inherited["chat_path"] = resolved.ChatPath
+	}
+	if cfg.APIVersion == "" {
+		inherited["api_version"] = resolved.APIVersion
+	}
+	if cfg.BetaFeatures == nil {
+		inherited["beta_features"] = strings.Join(resolved.BetaFeatures, ",")
+	}
+	if cfg.OrgID == "" {
+		inherited["org_id"] = resolved.OrgID
+	}
+	if cfg.ProjectID == "" {
+		inherited["project_id"] = resolved.ProjectID
+	}
+	if cfg.Temperature == nil {
+		inherited["temperature"] = formatOptionalFloat(resolved.Temperature)
+	}
+	if cfg.MaxTokens == 0 {
+		inherited["max_tokens"] = formatOptionalInt(resolved.MaxTokens)
+	}
+	if cfg.TopP == nil {
+		inherited["top_p"] = formatOptionalFloat(resolved.TopP)
+	}
+	if cfg.APITimeout == "" {
+		inherited["api_timeout"] = resolved.APITimeout
+	}
+	if cfg.MaxRetries == nil {
+		inherited["max_retries"] = formatOptionalCount(resolved.MaxRetries)
+	}
+
+	kept := make(map[string]string, len(updates))
+	for key, value := range updates {
+		if current, ok := inherited[key]; ok && value == current {
+			continue
+		}
+		kept[key] = value
+	}
+	return kept
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestResolveConfig(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "relay", Provider: "anthropic", APIKey: "sk-team", BaseURL: "https://relay.example.com", Model: "m1", Models: []string{"m1", "m2"}, CABundle: "/etc/relay.pem"},
+		{Alias: "alice", Extends: "relay", APIKey: "sk-alice"},
+		{Alias: "bob", Extends: "alice", Model: "m2", BaseURL: "https://eu.relay.example.com"},
+		{Alias: "carol", Extends: "relay", Model: "m3"},
+		{Alias: "loop-a", Extends: "loop-b", APIKey: "sk-a"},
+		{Alias: "loop-b", Extends: "loop-a", APIKey: "sk-b"},
+		{Alias: "orphan", Extends: "missing", APIKey: "sk-o"},
+	}
+
+	tests := []struct {
+		name       string
+		alias      string
+		wantKey    string
+		wantURL    string
+		wantModel  string
+		wantModels string
+		errSubstr  string
+	}{
+		{name: "overrides key only", alias: "alice", wantKey: "sk-alice", wantURL: "https://relay.example.com", wantModel: "m1", wantModels: "m1,m2"},
+		{name: "multi-level chain", alias: "bob", wantKey: "sk-alice", wantURL: "https://eu.relay.example.com", wantModel: "m2", wantModels: "m1,m2"},
+		{name: "own model added to inherited list", alias: "carol", wantKey: "sk-team", wantURL: "https://relay.example.com", wantModel: "m3", wantModels: "m3,m1,m2"},
+		{name: "cycle", alias: "loop-a", errSubstr: "inheritance cycle"},
+		{name: "unknown base", alias: "orphan", errSubstr: "extends unknown configuration 'missing'"},
+		{name: "unknown alias", alias: "nobody", errSubstr: "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveConfig(configs, tt.alias)
+			if tt.errSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Fatalf("resolveConfig(%q) error = %v, want error containing %q", tt.alias, err, tt.errSubstr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveConfig(%q) unexpected error: %v", tt.alias, err)
+			}
+			if got.APIKey != tt.wantKey || got.BaseURL != tt.wantURL || got.Model != tt.wantModel || strings.Join(got.Models, ",") != tt.wantModels {
+				t.Errorf("resolveConfig(%q) = key %q url %q model %q models %v", tt.alias, got.APIKey, got.BaseURL, got.Model, got.Models)
+			}
+			if got.Extends != configs[indexOf(configs, tt.alias)].Extends {
+				t.Errorf("resolveConfig(%q) should keep the config's own extends", tt.alias)
+			}
+		})
+	}
+}
+
+func indexOf(configs []models.APIConfig, alias string) int {
+	for i, c := range configs {
+		if c.Alias == alias {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestManagerInheritance(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "relay", APIKey: "sk-team", BaseURL: "https://relay.example.com", Model: "m1", Models: []string{"m1", "m2"}}); err != nil {
+		t.Fatalf("Add(relay) unexpected error: %v", err)
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "ghost", Extends: "missing"}); err == nil {
+		t.Error("Add() extending an unknown config expected error")
+	}
+	if err := cm.Add(models.APIConfig{Alias: "self", Extends: "self", APIKey: "sk-x"}); err == nil {
+		t.Error("Add() extending itself expected error")
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "alice", Extends: "relay", APIKey: "sk-alice"}); err != nil {
+		t.Fatalf("Add(alice) unexpected error: %v", err)
+	}
+	alice, err := cm.Get("alice")
+	if err != nil {
+		t.Fatalf("Get(alice) unexpected error: %v", err)
+	}
+	if alice.BaseURL != "https://relay.example.com" || alice.APIKey != "sk-alice" || alice.Provider != "anthropic" {
+		t.Errorf("Get(alice) = %+v, want inherited URL and provider with own key", alice)
+	}
+
+	// Switching to an inherited model and saving a form with effective values keeps inheritance
+	if err := cm.SwitchModel("alice", "m2"); err != nil {
+		t.Fatalf("SwitchModel() on inherited models unexpected error: %v", err)
+	}
+	if err := cm.UpdatePartial("alice", map[string]string{"api_key": "sk-alice", "auth_token": "", "base_url": "https://relay.example.com", "model": "m2"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	if err := cm.UpdatePartial("relay", map[string]string{"base_url": "https://new-relay.example.com"}); err != nil {
+		t.Fatalf("UpdatePartial(relay) unexpected error: %v", err)
+	}
+	alice, _ = cm.Get("alice")
+	if alice.BaseURL != "https://new-relay.example.com" || alice.Model != "m2" {
+		t.Errorf("Get(alice) after base change = %+v, want URL following the base", alice)
+	}
+
+	if err := cm.UpdatePartial("relay", map[string]string{"extends": "alice"}); err == nil {
+		t.Error("UpdatePartial() creating a cycle expected error")
+	}
+
+	if err := cm.Remove("relay"); err == nil || !strings.Contains(err.Error(), "extended by: alice") {
+		t.Errorf("Remove(relay) error = %v, want it to name the configs extending it", err)
+	}
+
+	if err := cm.RenameAlias("relay", "team"); err != nil {
+		t.Fatalf("RenameAlias() unexpected error: %v", err)
+	}
+	alice, err = cm.Get("alice")
+	if err != nil || alice.Extends != "team" {
+		t.Errorf("Get(alice) after rename = %+v, %v, want extends 'team'", alice, err)
+	}
+}
+
+func TestBaseURLPlaceholders(t *testing.T) {
+	cm := setupTestConfig(t)
+	t.Setenv("APIMGR_VAR_REGION", "")
+	if err := cm.Add(models.APIConfig{Alias: "gateway", APIKey: "sk-gw", BaseURL: "https://{region}.gateway.example.com/{tenant}", Vars: map[string]string{"region": "us", "tenant": "acme"}}); err != nil {
+		t.Fatalf("Add(gateway) unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "eu", Extends: "gateway", Vars: map[string]string{"region": "eu"}}); err != nil {
+		t.Fatalf("Add(eu) unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "bare", APIKey: "sk-bare", BaseURL: "https://{zone}.example.com"}); err != nil {
+		t.Fatalf("Add(bare) unexpected error: %v", err)
+	}
+
+	for alias, want := range map[string]string{
+		"gateway": "https://us.gateway.example.com/acme",
+		"eu":      "https://eu.gateway.example.com/acme",
+		"bare":    "https://{zone}.example.com",
+	} {
+		cfg, err := cm.Get(alias)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", alias, err)
+		}
+		if cfg.BaseURL != want {
+			t.Errorf("Get(%s).BaseURL = %q, want %q", alias, cfg.BaseURL, want)
+		}
+	}
+
+	t.Setenv("APIMGR_VAR_REGION", "ap")
+	if cfg, _ := cm.Get("eu"); cfg.BaseURL != "https://ap.gateway.example.com/acme" {
+		t.Errorf("Get(eu).BaseURL with APIMGR_VAR_REGION = %q, want the environment to win", cfg.BaseURL)
+	}
+
+	// Saving a form prefilled with the expanded URL keeps the template
+	if err := cm.UpdatePartial("gateway", map[string]string{"base_url": "https://ap.gateway.example.com/acme", "vars.tenant": "globex"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	configs, _ := cm.Load()
+	gateway := configs[indexOf(configs, "gateway")]
+	if gateway.BaseURL != "https://{region}.gateway.example.com/{tenant}" || gateway.Vars["tenant"] != "globex" {
+		t.Errorf("gateway = %q %v, want the template kept and tenant changed", gateway.BaseURL, gateway.Vars)
+	}
+
+	if err := cm.SetActive("bare"); err == nil || !strings.Contains(err.Error(), "APIMGR_VAR_ZONE") {
+		t.Errorf("SetActive(bare) error = %v, want the missing placeholder reported", err)
+	}
+	if err := cm.SetActive("eu"); err != nil {
+		t.Errorf("SetActive(eu) unexpected error: %v", err)
+	}
+}
+
+func TestListBrokenExtends(t *testing.T) {
+	cm := setupTestConfig(t)
+	// Edited by hand, as changes through the manager reject broken bases
+	data := `{"active": "", "configs": [
+		{"alias": "relay", "api_key": "sk-team"},
+		{"alias": "orphan", "extends": "missing"},
+		{"alias": "alice", "extends": "relay"}
+	]}`
+	if err := os.WriteFile(cm.configPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := cm.List()
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || len(resolveErr.Errs) != 1 || !strings.Contains(err.Error(), "'orphan' extends unknown configuration 'missing'") {
+		t.Errorf("List() error = %v, want a ResolveError for orphan", err)
+	}
+	if exitcode.Of(err) != exitcode.Validation {
+		t.Errorf("exit code = %d, want %d", exitcode.Of(err), exitcode.Validation)
+	}
+	var aliases []string
+	for _, cfg := range configs {
+		aliases = append(aliases, cfg.Alias)
+	}
+	if !slices.Equal(aliases, []string{"relay", "alice"}) || configs[1].APIKey != "sk-team" {
+		t.Errorf("List() = %+v, want relay and the resolved alice", configs)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"apimgr/config/session"
+)
+
+// ConfigUse is how a configuration is in use
+type ConfigUse struct {
+	Active   bool     // It is the global active configuration
+	Sessions []string // PIDs of the shells running a local session on it
+}
+
+// InUse reports whether the configuration is active or has live local
+// sessions, so removing it asks for confirmation
+func (u ConfigUse) InUse() bool {
+	return u.Active || len(u.Sessions) > 0
+}
+
+// UseOf returns how the configuration with the given alias is in use
+func (cm *Manager) UseOf(alias string) (ConfigUse, error) {
+	var use ConfigUse
+	active, err := cm.GetActiveName(ScopeGlobal)
+	if err != nil {
+		return use, err
+	}
+	use.Active = active == alias
+
+	markers, err := session.ListMarkers(cm.SessionDir())
+	if err != nil {
+		return use, err
+	}
+	for _, m := range markers {
+		if m.Running && m.Marker != nil && m.Marker.Alias == alias {
+			use.Sessions = append(use.Sessions, m.PID)
+		}
+	}
+	return use, nil
+}
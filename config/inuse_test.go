@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/config/session"
+)
+
+func TestUseOf(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.saveConfigFile(&models.File{Active: "work", Configs: []models.APIConfig{{Alias: "work"}, {Alias: "home"}, {Alias: "spare"}}}); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+	// This process stands in for a shell with a live session, a PID that
+	// cannot exist for one that exited
+	pid := strconv.Itoa(os.Getpid())
+	if err := session.CreateSessionMarker(cm.SessionDir(), pid, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.CreateSessionMarker(cm.SessionDir(), "999999999", "spare"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		alias string
+		want  ConfigUse
+	}{
+		{alias: "work", want: ConfigUse{Active: true}},
+		{alias: "home", want: ConfigUse{Sessions: []string{pid}}},
+		{alias: "spare", want: ConfigUse{}},
+	}
+	for _, tt := range tests {
+		use, err := cm.UseOf(tt.alias)
+		if err != nil {
+			t.Fatalf("UseOf(%q) unexpected error: %v", tt.alias, err)
+		}
+		if use.Active != tt.want.Active || !slices.Equal(use.Sessions, tt.want.Sessions) || use.InUse() != tt.want.InUse() {
+			t.Errorf("UseOf(%q) = %+v, want %+v", tt.alias, use, tt.want)
+		}
+	}
+}
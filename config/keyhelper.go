@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"apimgr/config/models"
+	syncpkg "apimgr/config/sync"
+)
+
+// keyHelperBaseName is the apiKeyHelper script kept in the state directory,
+// without its platform extension
+const keyHelperBaseName = "api-key-helper"
+
+// KeyHelperPath returns the apiKeyHelper script Claude Code runs in helper
+// key mode
+func (cm *Manager) KeyHelperPath() string {
+	return filepath.Join(cm.StateDir(), keyHelperFileName(runtime.GOOS))
+}
+
+// writeKeyHelper writes the script printing cfg's key, so settings files
+// only reference the script
+func (cm *Manager) writeKeyHelper(cfg *models.APIConfig) error {
+	field := "api_key"
+	if cfg.APIKey == "" {
+		field = "auth_token"
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "apimgr"
+	}
+	script := keyHelperScript(runtime.GOOS, exe, cm.configPath, cfg.Alias, field)
+	if err := os.WriteFile(cm.KeyHelperPath(), []byte(script), 0700); err != nil {
+		return fmt.Errorf("failed to write key helper: %w", err)
+	}
+	return nil
+}
+
+// keyHelperFileName returns the script name for the platform
+func keyHelperFileName(goos string) string {
+	if goos == "windows" {
+		return keyHelperBaseName + ".cmd"
+	}
+	return keyHelperBaseName + ".sh"
+}
+
+// keyHelperScript returns a script running apimgr get for a config's key
+func keyHelperScript(goos, exe, configPath, alias, field string) string {
+	if goos == "windows" {
+		quote := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+		return fmt.Sprintf("@%s --config %s get %s --field %s --raw\r\n", quote(exe), quote(configPath), quote(alias), field)
+	}
+	return fmt.Sprintf("#!/bin/sh\n# Written by apimgr on switch, prints the active configuration's key\nexec %s --config %s get %s --field %s --raw\n",
+		syncpkg.ShellQuote(exe), syncpkg.ShellQuote(configPath), syncpkg.ShellQuote(alias), field)
+}
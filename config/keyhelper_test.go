@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestKeyHelperScript(t *testing.T) {
+	got := keyHelperScript("linux", "/usr/bin/apimgr", "/home/me/config.json", "it's", "api_key")
+	want := "exec '/usr/bin/apimgr' --config '/home/me/config.json' get 'it'\\''s' --field api_key --raw\n"
+	if !strings.HasPrefix(got, "#!/bin/sh\n") || !strings.HasSuffix(got, want) {
+		t.Errorf("keyHelperScript(linux) = %q, want suffix %q", got, want)
+	}
+
+	got = keyHelperScript("windows", `C:\apimgr.exe`, `C:\config.json`, "relay", "auth_token")
+	want = "@\"C:\\apimgr.exe\" --config \"C:\\config.json\" get \"relay\" --field auth_token --raw\r\n"
+	if got != want {
+		t.Errorf("keyHelperScript(windows) = %q, want %q", got, want)
+	}
+}
+
+func TestSyncKeyHelper(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	cm := setupTestConfig(t)
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"env": {"ANTHROPIC_API_KEY": "sk-old"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := &models.File{
+		Defaults: &models.Defaults{KeyMode: KeyModeHelper},
+		Configs:  []models.APIConfig{{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"}},
+	}
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(cm.StateDir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.syncClaudeSettings(&configFile.Configs[0]); err != nil {
+		t.Fatalf("syncClaudeSettings() unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(settingsPath)
+	if strings.Contains(string(data), "sk-") || !strings.Contains(string(data), `"apiKeyHelper": "`+cm.KeyHelperPath()+`"`) {
+		t.Errorf("settings in helper mode = %s", data)
+	}
+	script, err := os.ReadFile(cm.KeyHelperPath())
+	if err != nil || !strings.Contains(string(script), "get 'relay' --field api_key --raw") {
+		t.Errorf("key helper = %q, %v", script, err)
+	}
+
+	// Back in env mode the key returns and the helper is dropped
+	if err := cm.SetSetting("defaults.key_mode", KeyModeEnv); err != nil {
+		t.Fatalf("SetSetting() unexpected error: %v", err)
+	}
+	if err := cm.syncClaudeSettings(&configFile.Configs[0]); err != nil {
+		t.Fatalf("syncClaudeSettings() unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(settingsPath)
+	if !strings.Contains(string(data), "sk-relay") || strings.Contains(string(data), "apiKeyHelper") {
+		t.Errorf("settings in env mode = %s", data)
+	}
+}
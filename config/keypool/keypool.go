@@ -0,0 +1,233 @@
+package keypool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"apimgr/config/storage"
+)
+
+// Key selection strategies
+const (
+	RoundRobin        = "round-robin" // The key after the last one used, the default
+	LeastRecentlyUsed = "lru"         // The key unused for the longest time
+)
+
+// Strategies lists the valid key_strategy values
+var Strategies = []string{RoundRobin, LeastRecentlyUsed}
+
+// Cooldown is how long a rate-limited key is skipped
+const Cooldown = time.Minute
+
+// FileName is the key health file kept in the state directory
+const FileName = "keys.json"
+
+// Health is what is known about one key of a pool. Keys are stored by
+// fingerprint, never in clear.
+type Health struct {
+	LastUsedAt    time.Time `json:"last_used_at,omitzero"`
+	LastStatus    int       `json:"last_status,omitempty"` // HTTP status of the last reported response
+	Failures      int       `json:"failures,omitempty"`    // Consecutive failed responses
+	LastFailureAt time.Time `json:"last_failure_at,omitzero"`
+	Disabled      bool      `json:"disabled,omitempty"`      // Rejected (401) until a request with it succeeds
+	CooldownUntil time.Time `json:"cooldown_until,omitzero"` // Rate limited (429) until then
+}
+
+// Healthy reports whether the key may be picked at now
+func (h Health) Healthy(now time.Time) bool {
+	return !h.Disabled && !now.Before(h.CooldownUntil)
+}
+
+// Fingerprint identifies a key in the health file
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// IsKeyFailure reports whether status blames the key rather than the
+// request, so another key of the pool may succeed
+func IsKeyFailure(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusTooManyRequests
+}
+
+// healthPath returns the health file stored in the state directory
+func healthPath(stateDir string) string {
+	return filepath.Join(stateDir, FileName)
+}
+
+// loadAll reads the key health of every pool, keyed by alias then fingerprint
+func loadAll(stateDir string) (map[string]map[string]Health, error) {
+	data, err := os.ReadFile(healthPath(stateDir))
+	if os.IsNotExist(err) {
+		return map[string]map[string]Health{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key health: %v", err)
+	}
+
+	all := map[string]map[string]Health{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed to parse key health: %v", err)
+		}
+	}
+	return all, nil
+}
+
+// saveAll writes the key health of every pool, under the lock its caller
+// took with storage.LockPath
+func saveAll(stateDir string, all map[string]map[string]Health) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize key health: %v", err)
+	}
+	return storage.AtomicFileUpdate(healthPath(stateDir), string(data), nil)
+}
+
+// Load returns the health of the keys of alias's pool, by fingerprint
+func Load(stateDir, alias string) (map[string]Health, error) {
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	return all[alias], nil
+}
+
+// Pick selects the key of the pool to use next with strategy and records
+// its use. Unhealthy keys and those in exclude are skipped; when every key
+// is unhealthy, the least recently failed one is picked anyway unless
+// exclude is set, in which case "" is returned.
+func Pick(stateDir, alias string, keys []string, strategy string, exclude ...string) (string, error) {
+	unlock, err := storage.LockPath(healthPath(stateDir))
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return "", err
+	}
+	health := all[alias]
+	now := time.Now()
+
+	key := pick(keys, health, strategy, now, exclude)
+	if key == "" {
+		return "", nil
+	}
+	if health == nil {
+		health = map[string]Health{}
+		all[alias] = health
+	}
+	entry := health[Fingerprint(key)]
+	entry.LastUsedAt = now
+	health[Fingerprint(key)] = entry
+	prune(health, keys)
+	return key, saveAll(stateDir, all)
+}
+
+// Peek returns the key Pick would select next with strategy, without
+// recording its use
+func Peek(stateDir, alias string, keys []string, strategy string) (string, error) {
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return pick(keys, all[alias], strategy, time.Now(), nil), nil
+}
+
+// pick selects a key without recording it
+func pick(keys []string, health map[string]Health, strategy string, now time.Time, exclude []string) string {
+	var candidates []string
+	for _, key := range keys {
+		if !slices.Contains(exclude, key) && health[Fingerprint(key)].Healthy(now) {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) == 0 {
+		if len(exclude) > 0 || len(keys) == 0 {
+			return ""
+		}
+		// Better to try a failing key than none
+		return slices.MinFunc(keys, func(a, b string) int {
+			return health[Fingerprint(a)].LastFailureAt.Compare(health[Fingerprint(b)].LastFailureAt)
+		})
+	}
+
+	if strategy == LeastRecentlyUsed {
+		return slices.MinFunc(candidates, func(a, b string) int {
+			return health[Fingerprint(a)].LastUsedAt.Compare(health[Fingerprint(b)].LastUsedAt)
+		})
+	}
+
+	// Round-robin: the first candidate after the last key used
+	last := -1
+	for i, key := range keys {
+		if used := health[Fingerprint(key)].LastUsedAt; !used.IsZero() && (last < 0 || used.After(health[Fingerprint(keys[last])].LastUsedAt)) {
+			last = i
+		}
+	}
+	for i := 1; i <= len(keys); i++ {
+		if key := keys[(last+i)%len(keys)]; slices.Contains(candidates, key) {
+			return key
+		}
+	}
+	return candidates[0]
+}
+
+// Report records the status of a response to a request sent with key: a
+// 401 disables the key, a 429 puts it on cooldown and a success clears both
+func Report(stateDir, alias, key string, status int) error {
+	unlock, err := storage.LockPath(healthPath(stateDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return err
+	}
+	if all[alias] == nil {
+		all[alias] = map[string]Health{}
+	}
+
+	now := time.Now()
+	entry := all[alias][Fingerprint(key)]
+	entry.LastStatus = status
+	switch {
+	case status < http.StatusBadRequest:
+		entry.Failures = 0
+		entry.Disabled = false
+		entry.CooldownUntil = time.Time{}
+	case IsKeyFailure(status):
+		entry.Failures++
+		entry.LastFailureAt = now
+		entry.Disabled = status == http.StatusUnauthorized
+		if status == http.StatusTooManyRequests {
+			entry.CooldownUntil = now.Add(Cooldown)
+		}
+	}
+	all[alias][Fingerprint(key)] = entry
+	return saveAll(stateDir, all)
+}
+
+// prune drops the health of keys no longer in the pool
+func prune(health map[string]Health, keys []string) {
+	current := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		current[Fingerprint(key)] = true
+	}
+	for fingerprint := range health {
+		if !current[fingerprint] {
+			delete(health, fingerprint)
+		}
+	}
+}
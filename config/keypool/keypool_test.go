@@ -0,0 +1,137 @@
+package keypool
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPick(t *testing.T) {
+	keys := []string{"sk-a", "sk-b", "sk-c"}
+	now := time.Now()
+	used := func(ago ...time.Duration) map[string]Health {
+		health := map[string]Health{}
+		for i, d := range ago {
+			health[Fingerprint(keys[i])] = Health{LastUsedAt: now.Add(-d)}
+		}
+		return health
+	}
+
+	tests := []struct {
+		name     string
+		health   map[string]Health
+		strategy string
+		exclude  []string
+		want     string
+	}{
+		{name: "round-robin starts with the first key", health: nil, want: "sk-a"},
+		{name: "round-robin takes the key after the last used", health: used(time.Hour, time.Minute, 2*time.Hour), want: "sk-c"},
+		{name: "round-robin wraps around", health: used(time.Hour, 2*time.Hour, time.Minute), want: "sk-a"},
+		{name: "lru takes the key unused longest", health: used(time.Minute, 2*time.Hour, time.Hour), strategy: LeastRecentlyUsed, want: "sk-b"},
+		{
+			name: "skips disabled and cooling down keys",
+			health: map[string]Health{
+				Fingerprint("sk-a"): {Disabled: true},
+				Fingerprint("sk-b"): {CooldownUntil: now.Add(time.Minute)},
+			},
+			want: "sk-c",
+		},
+		{
+			name: "every key unhealthy takes the least recently failed",
+			health: map[string]Health{
+				Fingerprint("sk-a"): {Disabled: true, LastFailureAt: now.Add(-time.Minute)},
+				Fingerprint("sk-b"): {Disabled: true, LastFailureAt: now.Add(-time.Hour)},
+				Fingerprint("sk-c"): {Disabled: true, LastFailureAt: now},
+			},
+			want: "sk-b",
+		},
+		{
+			name: "nothing healthy besides the excluded key",
+			health: map[string]Health{
+				Fingerprint("sk-b"): {Disabled: true},
+				Fingerprint("sk-c"): {Disabled: true},
+			},
+			exclude: []string{"sk-a"},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pick(keys, tt.health, tt.strategy, now, tt.exclude); got != tt.want {
+				t.Errorf("pick() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickRotatesAndReports(t *testing.T) {
+	dir := t.TempDir()
+	keys := []string{"sk-a", "sk-b"}
+
+	var picked []string
+	for range 3 {
+		key, err := Pick(dir, "pool", keys, RoundRobin)
+		if err != nil {
+			t.Fatalf("Pick() unexpected error: %v", err)
+		}
+		picked = append(picked, key)
+		time.Sleep(time.Millisecond) // Distinct use times
+	}
+	if picked[0] != "sk-a" || picked[1] != "sk-b" || picked[2] != "sk-a" {
+		t.Errorf("Pick() sequence = %v, want [sk-a sk-b sk-a]", picked)
+	}
+
+	if err := Report(dir, "pool", "sk-b", http.StatusUnauthorized); err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if key, _ := Pick(dir, "pool", keys, RoundRobin); key != "sk-a" {
+		t.Errorf("Pick() after sk-b was rejected = %q, want sk-a", key)
+	}
+	if err := Report(dir, "pool", "sk-a", http.StatusTooManyRequests); err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if key, _ := Pick(dir, "pool", keys, RoundRobin, "sk-a"); key != "" {
+		t.Errorf("Pick() with no healthy key left = %q, want none", key)
+	}
+
+	health, err := Load(dir, "pool")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if h := health[Fingerprint("sk-a")]; h.Healthy(time.Now()) || h.Failures != 1 || h.LastStatus != http.StatusTooManyRequests {
+		t.Errorf("health of sk-a = %+v, want one rate-limited failure", h)
+	}
+	if err := Report(dir, "pool", "sk-b", http.StatusOK); err != nil {
+		t.Fatal(err)
+	}
+	if health, _ = Load(dir, "pool"); !health[Fingerprint("sk-b")].Healthy(time.Now()) {
+		t.Errorf("health of sk-b after a success = %+v, want healthy", health[Fingerprint("sk-b")])
+	}
+}
+
+func TestReportConcurrent(t *testing.T) {
+	stateDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Report(stateDir, "pool", fmt.Sprintf("sk-%d", i), http.StatusUnauthorized); err != nil {
+				t.Errorf("Report() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	health, err := Load(stateDir, "pool")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(health) != 10 {
+		t.Errorf("Load() has the health of %d keys, want all 10 reported", len(health))
+	}
+}
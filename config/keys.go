@@ -0,0 +1,155 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+
+	"apimgr/config/keypool"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// poolOwner returns the index of the configuration holding the key pool
+// alias uses, its own or an inherited one, or -1 when it uses none
+func poolOwner(configs []models.APIConfig, alias string) int {
+	seen := make(map[string]bool)
+	for current := alias; current != "" && !seen[current]; {
+		seen[current] = true
+		i := slices.IndexFunc(configs, func(c models.APIConfig) bool { return c.Alias == current })
+		switch {
+		case i < 0:
+			return -1
+		case len(configs[i].APIKeys) > 0:
+			return i
+		case configs[i].APIKey != "" || configs[i].AuthToken != "":
+			return -1
+		}
+		current = configs[i].Extends
+	}
+	return -1
+}
+
+// PickKey selects the key alias uses next from its key pool, with the
+// pool's strategy, and makes it the api_key of the configuration holding
+// the pool. Configurations without a pool are left unchanged.
+func (cm *Manager) PickKey(alias string) error {
+	configs, err := cm.Load()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(configs, func(c models.APIConfig) bool { return c.Alias == alias }) {
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+	}
+	i := poolOwner(configs, alias)
+	if i < 0 {
+		return nil
+	}
+	owner := configs[i]
+	// Picked once, outside the update, as a retry of the update after a
+	// conflict would advance the pool again
+	key, err := keypool.Pick(cm.StateDir(), owner.Alias, owner.APIKeys, owner.KeyStrategy)
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.update(func(configFile *models.File) error {
+		j := slices.IndexFunc(configFile.Configs, func(c models.APIConfig) bool { return c.Alias == owner.Alias })
+		if j < 0 || !slices.Contains(configFile.Configs[j].APIKeys, key) {
+			return nil
+		}
+		// Rotating within the pool is not a new credential, so
+		// key_updated_at is left alone
+		configFile.Configs[j].APIKey = key
+		return nil
+	})
+}
+
+// PeekKey returns the key PickKey would select next for alias, without
+// recording it, or "" when alias uses no key pool
+func (cm *Manager) PeekKey(alias string) (string, error) {
+	configs, err := cm.Load()
+	if err != nil {
+		return "", err
+	}
+	i := poolOwner(configs, alias)
+	if i < 0 {
+		return "", nil
+	}
+	owner := configs[i]
+	return keypool.Peek(cm.StateDir(), owner.Alias, owner.APIKeys, owner.KeyStrategy)
+}
+
+// NextKey records the status of a response to a request alias sent with
+// key and, when the key was rejected or rate limited, returns a healthy key
+// of alias's pool to retry with. It returns false when there is none.
+func (cm *Manager) NextKey(alias, key string, status int) (string, bool) {
+	configs, err := cm.Load()
+	if err != nil {
+		return "", false
+	}
+	i := poolOwner(configs, alias)
+	if i < 0 {
+		return "", false
+	}
+	owner := configs[i]
+	if err := keypool.Report(cm.StateDir(), owner.Alias, key, status); err != nil {
+		slog.Warn("failed to record key health", "alias", owner.Alias, "error", err)
+	}
+	if !keypool.IsKeyFailure(status) {
+		return "", false
+	}
+	next, err := keypool.Pick(cm.StateDir(), owner.Alias, owner.APIKeys, owner.KeyStrategy, key)
+	if err != nil || next == "" {
+		return "", false
+	}
+	return next, true
+}
+
+// KeyHealth returns the health of the keys of alias's pool, in pool order.
+// Keys never used have a zero Health.
+func (cm *Manager) KeyHealth(alias string) ([]string, []keypool.Health, error) {
+	configs, err := cm.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	i := poolOwner(configs, alias)
+	if i < 0 {
+		return nil, nil, nil
+	}
+	owner := configs[i]
+	health, err := keypool.Load(cm.StateDir(), owner.Alias)
+	if err != nil {
+		return nil, nil, err
+	}
+	states := make([]keypool.Health, len(owner.APIKeys))
+	for j, key := range owner.APIKeys {
+		states[j] = health[keypool.Fingerprint(key)]
+	}
+	return owner.APIKeys, states, nil
+}
+
+// KeyOwner returns the alias of the configuration defining the credentials
+// alias uses, alias itself or one it extends, so a new key reaches every
+// configuration sharing it
+func (cm *Manager) KeyOwner(alias string) (string, error) {
+	configs, err := cm.Load()
+	if err != nil {
+		return "", err
+	}
+	seen := make(map[string]bool)
+	for current := alias; current != "" && !seen[current]; {
+		seen[current] = true
+		i := slices.IndexFunc(configs, func(c models.APIConfig) bool { return c.Alias == current })
+		if i < 0 {
+			break
+		}
+		if configs[i].APIKey != "" || configs[i].AuthToken != "" || len(configs[i].APIKeys) > 0 {
+			return current, nil
+		}
+		current = configs[i].Extends
+	}
+	return "", exitcode.New(exitcode.NotFound, "configuration '%s' has no credentials", alias)
+}
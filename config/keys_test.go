@@ -0,0 +1,96 @@
+package config
+
+import (
+	"net/http"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestPickKey(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "pool", APIKeys: []string{"sk-a", "sk-b"}}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "child", Extends: "pool", Model: "m1"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "plain", APIKey: "sk-plain"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	before, _ := cm.Get("pool")
+	if before.APIKey != "sk-a" {
+		t.Fatalf("APIKey of a new pool = %q, want its first key", before.APIKey)
+	}
+
+	// The child shares the pool of the config it extends
+	var picked []string
+	for _, alias := range []string{"pool", "child", "pool"} {
+		if err := cm.PickKey(alias); err != nil {
+			t.Fatalf("PickKey(%q) unexpected error: %v", alias, err)
+		}
+		cfg, _ := cm.Get(alias)
+		picked = append(picked, cfg.APIKey)
+	}
+	if picked[0] != "sk-a" || picked[1] != "sk-b" || picked[2] != "sk-a" {
+		t.Errorf("picked keys = %v, want [sk-a sk-b sk-a]", picked)
+	}
+	if after, _ := cm.Get("pool"); !after.KeyUpdatedAt.Equal(before.KeyUpdatedAt) {
+		t.Errorf("KeyUpdatedAt changed from %v to %v, want it kept", before.KeyUpdatedAt, after.KeyUpdatedAt)
+	}
+
+	if err := cm.PickKey("plain"); err != nil {
+		t.Errorf("PickKey() without a pool unexpected error: %v", err)
+	}
+	if err := cm.PickKey("missing"); err == nil {
+		t.Error("PickKey() of a missing config expected error, got nil")
+	}
+
+	// A rejected key is skipped until it succeeds again
+	if next, ok := cm.NextKey("child", "sk-a", http.StatusUnauthorized); !ok || next != "sk-b" {
+		t.Errorf("NextKey() after a 401 = %q, %v, want sk-b", next, ok)
+	}
+	if next, ok := cm.NextKey("child", "sk-b", http.StatusTooManyRequests); ok {
+		t.Errorf("NextKey() with no healthy key left = %q, want none", next)
+	}
+	if _, ok := cm.NextKey("pool", "sk-a", http.StatusOK); ok {
+		t.Error("NextKey() after a success wants a retry")
+	}
+	keys, health, err := cm.KeyHealth("child")
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("KeyHealth() = %v, %v, want the two pool keys", keys, err)
+	}
+	if health[0].Disabled || health[1].LastStatus != http.StatusTooManyRequests {
+		t.Errorf("KeyHealth() = %+v, want sk-a healthy again and sk-b rate limited", health)
+	}
+}
+
+func TestKeyOwner(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "base", APIKey: "sk-base"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "child", Extends: "base", Model: "m1"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "own", Extends: "base", APIKey: "sk-own"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		alias string
+		want  string
+	}{
+		{alias: "base", want: "base"},
+		{alias: "child", want: "base"},
+		{alias: "own", want: "own"},
+	}
+	for _, tt := range tests {
+		if got, err := cm.KeyOwner(tt.alias); err != nil || got != tt.want {
+			t.Errorf("KeyOwner(%q) = %q, %v, want %q", tt.alias, got, err, tt.want)
+		}
+	}
+	if _, err := cm.KeyOwner("missing"); err == nil {
+		t.Error("KeyOwner() of a missing config expected error, got nil")
+	}
+}
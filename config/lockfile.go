@@ -0,0 +1,323 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"apimgr/config/session"
+	"apimgr/config/storage"
+	"apimgr/internal/exitcode"
+)
+
+// LockEnvVar selects how the config file is locked, see LockStrategy
+const LockEnvVar = "APIMGR_LOCK"
+
+// Lock strategies
+const (
+	LockAuto     = "auto"     // lockfile on network filesystems, flock otherwise
+	LockFlock    = "flock"    // The OS file lock (flock, LockFileEx)
+	LockLockfile = "lockfile" // A <config>.lock file created with O_EXCL
+)
+
+// LockStrategies lists the values of APIMGR_LOCK and defaults.lock
+var LockStrategies = []string{LockAuto, LockFlock, LockLockfile}
+
+const (
+	lockHeartbeat  = 2 * time.Second  // How often the holder touches its lock file
+	lockStaleAfter = 15 * time.Second // A lock file untouched for this long is abandoned
+)
+
+// LockStrategy returns how the config file is locked and why: APIMGR_LOCK
+// or else defaults.lock when set to flock or lockfile, otherwise lockfile
+// when the config directory is on a network filesystem, whose OS locks are
+// unreliable
+func (cm *Manager) LockStrategy() (string, string) {
+	return lockStrategyFor(cm.configPath, cm.lockSetting(), os.Getenv)
+}
+
+// lockSetting returns defaults.lock of the config file. It is read without
+// a lock, whose strategy it decides; an unreadable file counts as unset.
+func (cm *Manager) lockSetting() string {
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		return ""
+	}
+	configFile, err := parseConfigData(storage.FormatFromPath(cm.configPath), data)
+	if err != nil || configFile.Defaults == nil {
+		return ""
+	}
+	return configFile.Defaults.Lock
+}
+
+// lockStrategyFor returns the lock strategy of configPath and why it was
+// chosen, given the defaults.lock setting
+func lockStrategyFor(configPath, setting string, getenv func(string) string) (string, string) {
+	switch value := strings.ToLower(getenv(LockEnvVar)); value {
+	case LockFlock, LockLockfile:
+		return value, LockEnvVar + "=" + value
+	case "", LockAuto:
+	default:
+		slog.Warn("unknown lock strategy, using auto", "env", LockEnvVar, "value", value)
+	}
+	switch value := strings.ToLower(setting); value {
+	case LockFlock, LockLockfile:
+		return value, "defaults.lock=" + value
+	case "", LockAuto:
+	default:
+		slog.Warn("unknown lock strategy, using auto", "setting", "defaults.lock", "value", value)
+	}
+	if fs := networkFilesystem(filepath.Dir(configPath)); fs != "" {
+		return LockLockfile, "config directory is on " + fs
+	}
+	return LockFlock, "local filesystem"
+}
+
+// usesLockfile reports whether the config file is locked with a lock file
+func (cm *Manager) usesLockfile() bool {
+	cm.lockOnce.Do(func() {
+		var reason string
+		cm.lockStrategy, reason = cm.LockStrategy()
+		slog.Debug("locking config file", "strategy", cm.lockStrategy, "reason", reason)
+	})
+	return cm.lockStrategy == LockLockfile
+}
+
+// lockWithLockfile takes the lock file of the config file on behalf of file,
+// until unlockFile releases it. Shared locks let other readers in.
+func (cm *Manager) lockWithLockfile(file *os.File, shared bool) error {
+	acquire := acquireLockfile
+	if shared {
+		acquire = acquireSharedLockfile
+	}
+	held, err := acquire(cm.configPath + ".lock")
+	if err != nil {
+		return err
+	}
+	cm.lockfiles.Store(file, held)
+	return nil
+}
+
+// lockfileOwner is the content of a lock file
+type lockfileOwner struct {
+	PID  int       `json:"pid"`
+	Host string    `json:"host"`
+	Time time.Time `json:"time"`
+}
+
+// heldLockfile is a lock file this process holds. Its mtime is refreshed
+// every lockHeartbeat, so other hosts can tell it from an abandoned one.
+type heldLockfile struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// acquireLockfile creates the lock file at path, waiting up to LockTimeout
+// for another holder to release it and then for the readers to leave.
+// Abandoned lock files are removed.
+func acquireLockfile(path string) (*heldLockfile, error) {
+	deadline := time.Now().Add(storage.LockTimeout)
+	for {
+		err := createLockfile(path)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if removeStaleLockfile(path, time.Now()) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, exitcode.New(exitcode.LockTimeout, "lock timeout: %s is held by another process", path)
+		}
+		time.Sleep(storage.LockRetryDelay)
+	}
+	held := &heldLockfile{path: path, stop: make(chan struct{}), done: make(chan struct{})}
+	go held.heartbeat()
+
+	// New readers stay out while the lock file exists
+	for readerLockfiles(path, time.Now()) {
+		if time.Now().After(deadline) {
+			held.release()
+			return nil, exitcode.New(exitcode.LockTimeout, "lock timeout: %s is read by another process", strings.TrimSuffix(path, ".lock"))
+		}
+		time.Sleep(storage.LockRetryDelay)
+	}
+	return held, nil
+}
+
+// acquireSharedLockfile takes a read lock next to the lock file at path: a
+// file of its own named <path>.read-*, created once no writer holds path.
+// Writers wait for these files to go away.
+func acquireSharedLockfile(path string) (*heldLockfile, error) {
+	deadline := time.Now().Add(storage.LockTimeout)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			if removeStaleLockfile(path, time.Now()) {
+				continue
+			}
+		} else {
+			f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+readerSuffix+"*")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create lock file: %w", err)
+			}
+			reader := f.Name()
+			if err := writeLockfileOwner(f); err != nil {
+				os.Remove(reader)
+				return nil, err
+			}
+			// A writer that came in meanwhile goes first
+			if _, err := os.Stat(path); err != nil {
+				held := &heldLockfile{path: reader, stop: make(chan struct{}), done: make(chan struct{})}
+				go held.heartbeat()
+				return held, nil
+			}
+			os.Remove(reader)
+		}
+		if time.Now().After(deadline) {
+			return nil, exitcode.New(exitcode.LockTimeout, "lock timeout: %s is held by another process", path)
+		}
+		time.Sleep(storage.LockRetryDelay)
+	}
+}
+
+// Suffixes of the lock file name in the names of read locks and of stale
+// lock files being removed
+const (
+	readerSuffix = ".read-"
+	staleSuffix  = ".stale-"
+)
+
+// readerLockfiles reports whether readers hold read locks of the lock file
+// at path, removing abandoned ones
+func readerLockfiles(path string, now time.Time) bool {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	prefix := filepath.Base(path) + readerSuffix
+	for _, entry := range entries {
+		// Stale files moved aside are on their way out
+		if !strings.HasPrefix(entry.Name(), prefix) || strings.Contains(entry.Name(), staleSuffix) {
+			continue
+		}
+		if !removeStaleLockfile(filepath.Join(filepath.Dir(path), entry.Name()), now) {
+			return true
+		}
+	}
+	return false
+}
+
+// createLockfile creates the lock file at path with O_EXCL, which fails when
+// it exists, and writes this process's PID and host to it
+func createLockfile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := writeLockfileOwner(f); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// writeLockfileOwner writes this process's PID and host to a new lock file
+// and closes it
+func writeLockfileOwner(f *os.File) error {
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(lockfileOwner{PID: os.Getpid(), Host: host, Time: time.Now()})
+	_, err := f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// heartbeat touches the lock file until release
+func (h *heldLockfile) heartbeat() {
+	defer close(h.done)
+	ticker := time.NewTicker(lockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case now := <-ticker.C:
+			if err := os.Chtimes(h.path, now, now); err != nil {
+				slog.Warn("failed to refresh lock file", "path", h.path, "error", err)
+			}
+		}
+	}
+}
+
+// release stops the heartbeat and removes the lock file
+func (h *heldLockfile) release() error {
+	close(h.stop)
+	<-h.done
+	if err := os.Remove(h.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// removeStaleLockfile removes the lock file at path when its holder is gone,
+// reporting whether the lock may be taken again right away
+func removeStaleLockfile(path string, now time.Time) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Released in the meantime
+		return os.IsNotExist(err)
+	}
+	data, _ := os.ReadFile(path)
+	var owner lockfileOwner
+	// A lock file being written has no owner yet, its age decides
+	_ = json.Unmarshal(data, &owner)
+
+	host, _ := os.Hostname()
+	if !lockfileStale(owner, info.ModTime(), now, host) {
+		return false
+	}
+
+	// Another process may have replaced the stale lock file since it was
+	// read. Moving it aside first makes sure the file checked again is the
+	// one removed.
+	aside := fmt.Sprintf("%s%s%d-%d", path, staleSuffix, os.Getpid(), now.UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		return os.IsNotExist(err)
+	}
+	if current, err := os.ReadFile(aside); err != nil || string(current) != string(data) {
+		// A new holder's lock file, put it back unless yet another one
+		// took its place
+		if err := os.Link(aside, path); err != nil {
+			slog.Warn("failed to restore lock file", "path", path, "error", err)
+		}
+		os.Remove(aside)
+		return false
+	}
+	slog.Warn("removing stale lock file", "path", path, "pid", owner.PID, "host", owner.Host)
+	os.Remove(aside)
+	return true
+}
+
+// lockfileStale reports whether a lock file was abandoned: its holder on this
+// host no longer runs, or no heartbeat touched it for lockStaleAfter
+func lockfileStale(owner lockfileOwner, modTime, now time.Time, host string) bool {
+	// Signal 0 does not tell whether a process runs on Windows
+	if owner.PID > 0 && owner.Host == host && runtime.GOOS != "windows" && !session.IsProcessRunning(owner.PID) {
+		return true
+	}
+	return now.Sub(modTime) > lockStaleAfter
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestLockStrategyFor(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	tests := []struct {
+		env     string
+		setting string
+		want    string
+	}{
+		{"", "", LockFlock},
+		{"auto", "", LockFlock},
+		{"flock", "", LockFlock},
+		{"lockfile", "", LockLockfile},
+		{"LockFile", "", LockLockfile},
+		{"bogus", "", LockFlock},
+		{"", "lockfile", LockLockfile},
+		{"auto", "lockfile", LockLockfile},
+		{"flock", "lockfile", LockFlock},
+		{"", "bogus", LockFlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+"/"+tt.setting, func(t *testing.T) {
+			getenv := func(string) string { return tt.env }
+			if got, reason := lockStrategyFor(configPath, tt.setting, getenv); got != tt.want || reason == "" {
+				t.Errorf("lockStrategyFor() = %q, %q, want %q", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockfileStale(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		owner   lockfileOwner
+		modTime time.Time
+		want    bool
+	}{
+		{"fresh, holder running", lockfileOwner{PID: os.Getpid(), Host: "here"}, now, false},
+		{"fresh, other host", lockfileOwner{PID: 999999999, Host: "there"}, now, false},
+		{"no heartbeat", lockfileOwner{PID: 999999999, Host: "there"}, now.Add(-time.Minute), true},
+		{"being written", lockfileOwner{}, now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockfileStale(tt.owner, tt.modTime, now, "here"); got != tt.want {
+				t.Errorf("lockfileStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockfileStrategy(t *testing.T) {
+	t.Setenv(LockEnvVar, LockLockfile)
+	cm := setupTestConfig(t)
+	lockPath := cm.configPath + ".lock"
+
+	// A lock file left by a crashed process of this host is taken over
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(lockfileOwner{PID: 999999999, Host: host, Time: time.Now()})
+	if err := os.WriteFile(lockPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work"}); err != nil {
+		t.Fatalf("Add() with a stale lock file: %v", err)
+	}
+	if _, err := cm.Get("work"); err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file left after unlocking: %v", err)
+	}
+}
+
+func TestSharedLockfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json.lock")
+
+	first, err := acquireSharedLockfile(path)
+	if err != nil {
+		t.Fatalf("acquireSharedLockfile() error: %v", err)
+	}
+	second, err := acquireSharedLockfile(path)
+	if err != nil {
+		t.Fatalf("acquireSharedLockfile() with another reader: %v", err)
+	}
+
+	// The writer waits for both readers
+	acquired := make(chan *heldLockfile)
+	go func() {
+		held, err := acquireLockfile(path)
+		if err != nil {
+			t.Errorf("acquireLockfile() error: %v", err)
+		}
+		acquired <- held
+	}()
+	first.release()
+	select {
+	case <-acquired:
+		t.Fatal("acquireLockfile() returned while a reader held the lock")
+	case <-time.After(200 * time.Millisecond):
+	}
+	second.release()
+	writer := <-acquired
+	if writer == nil {
+		t.FailNow()
+	}
+	defer writer.release()
+
+	if readerLockfiles(path, time.Now()) {
+		t.Error("read locks left after releasing them")
+	}
+}
+
+func TestLockSetting(t *testing.T) {
+	t.Setenv(LockEnvVar, "")
+	cm := setupTestConfig(t)
+	if err := cm.SetSetting("defaults.lock", "LockFile"); err != nil {
+		t.Fatalf("SetSetting() error: %v", err)
+	}
+	if strategy, reason := cm.LockStrategy(); strategy != LockLockfile || reason != "defaults.lock=lockfile" {
+		t.Errorf("LockStrategy() = %q, %q, want lockfile from defaults.lock", strategy, reason)
+	}
+	if err := cm.SetSetting("defaults.lock", "nfs"); err == nil {
+		t.Error("SetSetting() accepted an unknown lock strategy")
+	}
+}
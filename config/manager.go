@@ -2,22 +2,35 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"apimgr/config/models"
 	"apimgr/config/storage"
 	syncpkg "apimgr/config/sync"
 	"apimgr/config/validation"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/notice"
+	"apimgr/internal/utils"
 )
 
 // normalizeModels ensures backward compatibility for configs loaded without models field.
 // If models field is empty but model field has a value, populate models from model.
 // If model field is empty, models list remains empty.
+// Configs extending another one keep an empty list so it is inherited.
 func normalizeModels(config *models.APIConfig) {
-	if len(config.Models) == 0 && config.Model != "" {
+	if len(config.Models) == 0 && config.Model != "" && config.Extends == "" {
 		config.Models = []string{config.Model}
 	}
 }
@@ -25,48 +38,83 @@ func normalizeModels(config *models.APIConfig) {
 // Manager manages API configurations
 type Manager struct {
 	configPath string
+	stateDir   string     // Runtime files, see StateDir
+	sessionDir string     // Session markers, see SessionDir
 	mu         sync.Mutex // Mutex to protect concurrent access
+
+	editETags map[string]string // ETag of the content each editable copy was made from
+
+	cache configCache // Last parsed config file, see loadConfigFile
+
+	lockOnce     sync.Once
+	lockStrategy string   // See LockStrategy, resolved on first lock
+	lockfiles    sync.Map // *os.File -> *heldLockfile taken on its behalf
 }
 
-// NewConfigManager creates a new Manager with unified config path
+// NewConfigManager creates a new Manager with unified config path. The
+// path comes from --config, APIMGR_CONFIG or the workspace in use.
 func NewConfigManager() (*Manager, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	// Check XDG_CONFIG_HOME environment variable for custom config location
-	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
-	if xdgConfigHome == "" {
-		// Use default XDG path (~/.config)
-		xdgConfigHome = filepath.Join(homeDir, ".config")
+	// Always use XDG config location (new standard), in JSON, YAML or TOML
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return nil, err
 	}
-
-	// Always use XDG config location (new standard)
-	xdgConfigPath := filepath.Join(xdgConfigHome, "apimgr", "config.json")
 	oldConfigPath := filepath.Join(homeDir, ".apimgr.json")
 
-	configPath := xdgConfigPath
-
-	// Ensure XDG directory exists
-	configDir := filepath.Dir(xdgConfigPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	// Ensure the config directory exists
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Migrate from old config if it exists and new config doesn't
-	if storage.ShouldMigrateConfig(oldConfigPath, xdgConfigPath) {
-		if err := storage.MigrateConfig(oldConfigPath, xdgConfigPath); err != nil {
-			fmt.Printf("⚠️  Failed to migrate config: %v\n", err)
+	// Migrate from old config into the default location if it exists and new config doesn't
+	override, _ := ConfigPathOverride()
+	if override == "" && filepath.Dir(configPath) == baseDir && storage.ShouldMigrateConfig(oldConfigPath, configPath) {
+		if err := storage.MigrateConfig(oldConfigPath, configPath); err != nil {
+			notice.Printf("Warning: Failed to migrate config: %v\n", err)
 			// Continue with new config path anyway
 		} else {
-			fmt.Println("✅ Migrated config from old location successfully")
+			notice.Println("✅ Migrated config from old location successfully")
 		}
 	}
 
-	return &Manager{
+	// Keep runtime files in XDG_STATE_HOME so the config directory stays clean
+	stateDir, err := stateDirFor(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if override == "" {
+		if err := migrateState(filepath.Dir(configPath), stateDir); err != nil {
+			notice.Printf("⚠️  Failed to migrate state files: %v\n", err)
+		}
+	}
+
+	sessionDir := sessionDirFor(stateDir)
+	if sessionDir != stateDir {
+		if err := moveSessionMarkers(stateDir, sessionDir); err != nil {
+			notice.Printf("⚠️  Failed to move session markers: %v\n", err)
+		}
+	}
+
+	slog.Debug("using config file", "path", configPath, "state_dir", stateDir, "session_dir", sessionDir)
+	cm := &Manager{
 		configPath: configPath,
-	}, nil
+		stateDir:   stateDir,
+		sessionDir: sessionDir,
+	}
+	cm.warnPermissions()
+	return cm, nil
 }
 
 // GetConfigPath returns the path to the config file
@@ -74,112 +122,235 @@ func (cm *Manager) GetConfigPath() string {
 	return cm.configPath
 }
 
-// loadConfigFile loads the config file with locking
+// loadConfigFile loads the config file with locking. A file that cannot be
+// parsed is recovered from its newest backup, see recoverConfigFile.
 func (cm *Manager) loadConfigFile() (*models.File, error) {
+	// An unchanged file is served from the cache
+	if info, err := os.Stat(cm.configPath); err == nil {
+		if configFile := cm.cache.get(info); configFile != nil {
+			return configFile, nil
+		}
+	}
+
+	data, info, err := cm.readConfigData()
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := parseConfigData(storage.FormatFromPath(cm.configPath), data)
+	if err != nil {
+		// Recovery takes the exclusive lock, so it runs once the shared one
+		// is released
+		return cm.recoverConfigFile(data, err)
+	}
+	configFile.ETag = etagOf(data)
+	if err := cm.loadIncludes(configFile); err != nil {
+		return nil, err
+	}
+	if err := cm.loadTeam(configFile); err != nil {
+		return nil, err
+	}
+	// Included and team files may change without the main file changing
+	if info != nil && len(configFile.Include) == 0 && configFile.Team == nil {
+		cm.cache.put(info, configFile)
+	}
+	return configFile, nil
+}
+
+// readConfigData reads the config file holding a shared lock, returning no
+// data for a missing file
+func (cm *Manager) readConfigData() ([]byte, os.FileInfo, error) {
 	// Open the file with read lock
 	file, err := os.OpenFile(cm.configPath, os.O_RDONLY, 0600)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &models.File{Configs: []models.APIConfig{}}, nil
+			return nil, nil, nil
 		}
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+		return nil, nil, fmt.Errorf("failed to open config file: %w", err)
 	}
 	defer file.Close()
 
 	// Lock the file for shared read access (LOCK_SH)
 	if err := cm.lockFileShared(file); err != nil {
-		return nil, fmt.Errorf("failed to lock config file: %w", err)
+		return nil, nil, fmt.Errorf("failed to lock config file: %w", err)
 	}
 	defer func() {
 		if err := cm.unlockFile(file); err != nil {
-			// fmt.Printf("⚠️  Failed to unlock file: %v\n", err)
+			slog.Warn("failed to unlock config file", "path", cm.configPath, "error", err)
 		}
 	}()
 
 	// Read from the locked file descriptor instead of using os.ReadFile
 	data, err := os.ReadFile(cm.configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	info, _ := file.Stat()
+	return data, info, nil
+}
 
+// parseConfigData parses the content of a config file
+func parseConfigData(format storage.Format, data []byte) (*models.File, error) {
 	if len(data) == 0 {
 		return &models.File{Configs: []models.APIConfig{}}, nil
 	}
 
 	var configFile models.File
-	err = json.Unmarshal(data, &configFile)
+	err := storage.Unmarshal(format, data, &configFile)
 	if err != nil {
 		// Try to parse as old format (array of configs)
 		var configs []models.APIConfig
-		if err2 := json.Unmarshal(data, &configs); err2 == nil {
+		if err2 := json.Unmarshal(storage.StandardizeJSON(data), &configs); format == storage.FormatJSON && err2 == nil {
 			// Normalize models for backward compatibility
 			for i := range configs {
 				normalizeModels(&configs[i])
+				utils.RegisterSecrets(configs[i].APIKey, configs[i].AuthToken)
 			}
 			return &models.File{Configs: configs}, nil
 		}
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Normalize models for backward compatibility
+	// Normalize models for backward compatibility, and let output
+	// redaction recognize every stored key
 	for i := range configFile.Configs {
 		normalizeModels(&configFile.Configs[i])
+		utils.RegisterSecrets(configFile.Configs[i].APIKey, configFile.Configs[i].AuthToken)
 	}
 
 	return &configFile, nil
 }
 
-// saveConfigFile saves the config file with locking
+// saveConfigFile saves the config file with locking. It returns
+// ErrConflict, leaving the file untouched, when the file or one of the
+// included files changed since configFile was loaded.
 func (cm *Manager) saveConfigFile(configFile *models.File) error {
-	data, err := json.MarshalIndent(configFile, "", "  ")
+	// Changed included files are locked and checked first, and written
+	// back once the main file is saved
+	writes, err := cm.prepareIncludes(configFile)
 	if err != nil {
-		return fmt.Errorf("failed to serialize config: %w", err)
+		return err
+	}
+
+	// Only local overrides of team configs are kept
+	main := *configFile
+	main.Configs = localConfigs(configFile, mainConfigs(configFile.Configs))
+	data, err := cm.writeConfigData(configFile.ETag, func(previous []byte) ([]byte, error) {
+		// The current content lets YAML files keep their comments
+		data, err := storage.Marshal(storage.FormatFromPath(cm.configPath), &main, previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize config: %w", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		cm.releaseIncludes(writes)
+		return err
+	}
+	configFile.ETag = etagOf(data)
+	return cm.writeIncludes(writes)
+}
+
+// update loads the config file, applies fn and saves the result. When
+// another process saved the file in between, it starts over from the new
+// content, so concurrent changes are not lost.
+func (cm *Manager) update(fn func(configFile *models.File) error) error {
+	for attempt := 1; ; attempt++ {
+		configFile, err := cm.loadConfigFile()
+		if err != nil {
+			return err
+		}
+		if err := fn(configFile); err != nil {
+			return err
+		}
+		err = cm.saveConfigFile(configFile)
+		if !errors.Is(err, ErrConflict) || attempt == conflictAttempts {
+			return err
+		}
+		slog.Debug("config file changed while saving, retrying", "path", cm.configPath, "attempt", attempt)
+	}
+}
+
+// writeConfigData replaces the content of the config file with the data
+// build returns for the current content, holding an exclusive lock
+// throughout. It returns ErrConflict when the current content does not
+// match etag, the ETag of the content the change is based on.
+func (cm *Manager) writeConfigData(etag string, build func(previous []byte) ([]byte, error)) ([]byte, error) {
+	if err := checkWritable(); err != nil {
+		return nil, err
 	}
 
-	// Open the file with write access (create if not exists)
-	file, err := os.OpenFile(cm.configPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	// Open the file with write access (create if not exists), truncating
+	// only once the lock is held
+	file, err := os.OpenFile(cm.configPath, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
-		return fmt.Errorf("failed to open config file: %w", err)
+		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
 	defer file.Close()
 
 	// Lock the file for exclusive write access
 	if err := cm.lockFile(file); err != nil {
-		return fmt.Errorf("failed to lock config file: %w", err)
+		return nil, fmt.Errorf("failed to lock config file: %w", err)
 	}
 	defer func() {
 		if err := cm.unlockFile(file); err != nil {
-			// fmt.Printf("⚠️  Failed to unlock file: %v\n", err)
+			slog.Warn("failed to unlock config file", "path", cm.configPath, "error", err)
 		}
 	}()
 
-	// Write the file while holding the lock
-	_, err = file.Write(data)
+	// The file is about to change, or changed without the cache noticing
+	cm.cache.invalidate()
+
+	previous, err := io.ReadAll(file)
 	if err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if etagOf(previous) != etag {
+		return nil, ErrConflict
+	}
+	data, err := build(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the file while holding the lock
+	if err := file.Truncate(0); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	// Ensure data is flushed to disk
 	if err := file.Sync(); err != nil {
-		return fmt.Errorf("failed to sync config file: %w", err)
+		return nil, fmt.Errorf("failed to sync config file: %w", err)
 	}
+	cm.backupConfig()
 
-	return nil
+	return data, nil
 }
 
 // lockFile locks the config file with exclusive lock (for write operations)
 func (cm *Manager) lockFile(file *os.File) error {
-	return lockFileExclusive(file)
+	if cm.usesLockfile() {
+		return cm.lockWithLockfile(file, false)
+	}
+	return storage.LockExclusive(file)
 }
 
 // lockFileShared locks the config file with shared lock (for read operations)
 func (cm *Manager) lockFileShared(file *os.File) error {
-	return lockFileShared(file)
+	if cm.usesLockfile() {
+		return cm.lockWithLockfile(file, true)
+	}
+	return storage.LockShared(file)
 }
 
 // unlockFile unlocks the config file
 func (cm *Manager) unlockFile(file *os.File) error {
-	return unlockFile(file)
+	if held, ok := cm.lockfiles.LoadAndDelete(file); ok {
+		return held.(*heldLockfile).release()
+	}
+	return storage.Unlock(file)
 }
 
 // Load loads all configurations from the config file
@@ -199,68 +370,95 @@ func (cm *Manager) Save(configs []models.APIConfig) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configFile, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
-	configFile.Configs = configs
-	return cm.saveConfigFile(configFile)
+	return cm.update(func(configFile *models.File) error {
+		configFile.Configs = configs
+		return nil
+	})
 }
 
 // Add adds a new configuration
 func (cm *Manager) Add(config models.APIConfig) error {
-	// Set default provider
-	if config.Provider == "" {
-		config.Provider = "anthropic"
-	}
-
-	validator := validation.NewValidator()
-	if err := validator.ValidateConfig(config); err != nil {
-		return err
-	}
-
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configs, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
+	return cm.update(func(configs *models.File) error {
+		config := config
+		config.BaseURL = utils.NormalizeBaseURL(config.BaseURL)
+		if config.APIKey == "" && config.AuthToken == "" && len(config.APIKeys) > 0 {
+			config.APIKey = config.APIKeys[0]
+		}
 
-	// Check if alias already exists
-	for i, existingConfig := range configs.Configs {
-		if existingConfig.Alias == config.Alias {
-			configs.Configs[i] = config
-			return cm.saveConfigFile(configs)
+		// Set default provider, configs extending another one inherit it
+		if config.Provider == "" && config.Extends == "" {
+			config.Provider = "anthropic"
+			if configs.Defaults != nil && configs.Defaults.Provider != "" {
+				config.Provider = configs.Defaults.Provider
+			}
+		}
+		if config.Region != "" {
+			baseURL, err := regionBaseURL(config.Provider, config.BaseURL, config.Region)
+			if err != nil {
+				return err
+			}
+			config.BaseURL = baseURL
 		}
-	}
 
-	configs.Configs = append(configs.Configs, config)
-	return cm.saveConfigFile(configs)
+		if err := validateResolved(configs.Configs, config); err != nil {
+			return err
+		}
+
+		// Check if alias already exists
+		for i, existingConfig := range configs.Configs {
+			if existingConfig.Alias == config.Alias {
+				// Keep usage timestamps unless the credentials changed
+				if config.APIKey == existingConfig.APIKey && config.AuthToken == existingConfig.AuthToken {
+					config.KeyUpdatedAt = existingConfig.KeyUpdatedAt
+				} else {
+					config.KeyUpdatedAt = time.Now()
+				}
+				config.LastUsedAt = existingConfig.LastUsedAt
+				configs.Configs[i] = config
+				return nil
+			}
+		}
+
+		config.KeyUpdatedAt = time.Now()
+		configs.Configs = append(configs.Configs, config)
+		return nil
+	})
 }
 
-// Remove removes a configuration by alias
+// Remove moves a configuration to the trash by alias
 func (cm *Manager) Remove(alias string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configs, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
+	return cm.update(func(configs *models.File) error {
+		if err := cm.checkNotTeam(configs, alias); err != nil {
+			return err
+		}
+		if children := extendedBy(configs.Configs, alias); len(children) > 0 {
+			return fmt.Errorf("configuration '%s' is extended by: %s", alias, strings.Join(children, ", "))
+		}
 
-	for i, config := range configs.Configs {
-		if config.Alias == alias {
-			configs.Configs = append(configs.Configs[:i], configs.Configs[i+1:]...)
-			// If removing the active config, clear the active config
-			if configs.Active == alias {
-				configs.Active = ""
+		for i, config := range configs.Configs {
+			if config.Alias == alias {
+				moveToTrash(configs, config, time.Now())
+				configs.Configs = append(configs.Configs[:i], configs.Configs[i+1:]...)
+				// If removing the active config, clear the active config
+				if configs.Active == alias {
+					configs.Active = ""
+				}
+				if configs.Previous == alias {
+					configs.Previous = ""
+				}
+				removeGroupMember(configs, alias)
+				return nil
 			}
-			return cm.saveConfigFile(configs)
 		}
-	}
 
-	return fmt.Errorf("configuration '%s' does not exist", alias)
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+	})
 }
 
 // Get returns a configuration by alias
@@ -273,16 +471,110 @@ func (cm *Manager) Get(alias string) (*models.APIConfig, error) {
 		return nil, err
 	}
 
-	for _, config := range configs.Configs {
-		if config.Alias == alias {
-			return &config, nil
+	config, err := resolveEffective(configs, alias)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetStored returns a configuration as stored, without inherited fields,
+// defaults or expanded placeholders
+func (cm *Manager) GetStored(alias string) (*models.APIConfig, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range configFile.Configs {
+		if cfg.Alias == alias {
+			return &cfg, nil
 		}
 	}
+	return nil, exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+}
 
-	return nil, fmt.Errorf("configuration '%s' does not exist", alias)
+// ResolveAlias resolves an exact alias, unique prefix or glob pattern
+// (e.g. "wo*") to a single configuration alias
+func (cm *Manager) ResolveAlias(pattern string) (string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+	return resolveAliasIn(configFile.Configs, pattern)
 }
 
-// List returns all configurations
+// ResolveAliasInEnvironment resolves an alias, prefix or glob pattern among
+// the configurations of one environment, so "openai" with env "prod" can
+// resolve to "openai-prod"
+func (cm *Manager) ResolveAliasInEnvironment(pattern, environment string) (string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	var configs []models.APIConfig
+	for _, config := range configFile.Configs {
+		if config.Environment == environment {
+			configs = append(configs, config)
+		}
+	}
+	if len(configs) == 0 {
+		return "", exitcode.New(exitcode.NotFound, "no configurations in environment '%s'", environment)
+	}
+
+	alias, err := resolveAliasIn(configs, pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w (environment '%s')", err, environment)
+	}
+	return alias, nil
+}
+
+// resolveAliasIn resolves a pattern against the given configurations
+func resolveAliasIn(configs []models.APIConfig, pattern string) (string, error) {
+	// Exact match always wins
+	for _, config := range configs {
+		if config.Alias == pattern {
+			return pattern, nil
+		}
+	}
+
+	isGlob := strings.ContainsAny(pattern, "*?[")
+	var matches []string
+	for _, config := range configs {
+		if isGlob {
+			ok, err := path.Match(pattern, config.Alias)
+			if err != nil {
+				return "", exitcode.New(exitcode.Validation, "invalid alias pattern '%s': %w", pattern, err)
+			}
+			if ok {
+				matches = append(matches, config.Alias)
+			}
+		} else if strings.HasPrefix(config.Alias, pattern) {
+			matches = append(matches, config.Alias)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", pattern)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", exitcode.New(exitcode.Validation, "'%s' matches multiple configurations: %s", pattern, strings.Join(matches, ", "))
+	}
+}
+
+// List returns all configurations. Configurations that cannot be resolved
+// are left out and reported with a *ResolveError, returned with the others.
 func (cm *Manager) List() ([]models.APIConfig, error) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
@@ -291,7 +583,12 @@ func (cm *Manager) List() ([]models.APIConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	return configs.Configs, nil
+	resolved, err := resolveAll(configs.Configs)
+	for i := range resolved {
+		applyDefaults(&resolved[i], configs.Defaults)
+		resolved[i].BaseURL = utils.ExpandPlaceholders(resolved[i].BaseURL, resolved[i].Vars)
+	}
+	return resolved, err
 }
 
 // SetActive sets the active configuration
@@ -299,30 +596,69 @@ func (cm *Manager) SetActive(alias string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configFile, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
+	err := cm.update(func(configFile *models.File) error {
+		// Verify the alias exists
+		found := false
+		for i, config := range configFile.Configs {
+			if config.Alias == alias {
+				// Included files, which may be shared, are not touched by a switch
+				if config.Source == "" {
+					configFile.Configs[i].LastUsedAt = time.Now()
+				}
+				found = true
+				break
+			}
+		}
 
-	// Verify the alias exists
-	found := false
-	for _, config := range configFile.Configs {
-		if config.Alias == alias {
-			found = true
-			break
+		if !found {
+			return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+		}
+		if resolved, err := resolveEffective(configFile, alias); err == nil {
+			if err := utils.CheckPlaceholders(resolved.BaseURL); err != nil {
+				return err
+			}
 		}
-	}
 
-	if !found {
-		return fmt.Errorf("configuration '%s' does not exist", alias)
+		// Remember the outgoing config so it can be switched back to
+		if configFile.Active != "" && configFile.Active != alias {
+			configFile.Previous = configFile.Active
+		}
+		configFile.Active = alias
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	configFile.Active = alias
-	if err := cm.saveConfigFile(configFile); err != nil {
+	if err := cm.generateActiveScript(); err != nil {
 		return err
 	}
+	// Other terminals reload the global configuration at their next prompt
+	if err := cm.notifyGlobalChange(alias); err != nil {
+		slog.Debug("failed to notify other terminals of the global switch", "error", err)
+	}
+	return nil
+}
 
-	return cm.generateActiveScript()
+// MarkUsed records that a configuration was switched to without changing
+// the global active configuration, e.g. by a local switch
+func (cm *Manager) MarkUsed(alias string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.update(func(configFile *models.File) error {
+		for i, config := range configFile.Configs {
+			if config.Alias == alias {
+				// Included files, which may be shared, are not touched by a switch
+				if config.Source == "" {
+					configFile.Configs[i].LastUsedAt = time.Now()
+				}
+				return nil
+			}
+		}
+
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+	})
 }
 
 // GetActive returns the active configuration
@@ -347,17 +683,29 @@ func (cm *Manager) GetActive() (*models.APIConfig, error) {
 
 	for _, config := range configFile.Configs {
 		if config.Alias == activeAlias {
-			return &config, nil
+			resolved, err := resolveEffective(configFile, activeAlias)
+			if err != nil {
+				return nil, err
+			}
+			return &resolved, nil
 		}
 	}
 
-	return nil, fmt.Errorf("active configuration '%s' does not exist", activeAlias)
+	return nil, exitcode.New(exitcode.NotFound, "active configuration '%s' does not exist", activeAlias)
 }
 
-// GetActiveName returns the active configuration name
-func (cm *Manager) GetActiveName() (string, error) {
+// Scope selects which active configuration GetActiveName returns
+type Scope int
+
+const (
+	ScopeShell  Scope = iota // The APIMGR_ACTIVE override of the current shell, then the global one
+	ScopeGlobal              // The one recorded in the config file
+)
+
+// GetActiveName returns the active configuration name in scope
+func (cm *Manager) GetActiveName(scope Scope) (string, error) {
 	// Check environment variable override first
-	if envActive := os.Getenv("APIMGR_ACTIVE"); envActive != "" {
+	if envActive := os.Getenv("APIMGR_ACTIVE"); envActive != "" && scope == ScopeShell {
 		return envActive, nil
 	}
 
@@ -371,49 +719,232 @@ func (cm *Manager) GetActiveName() (string, error) {
 	return configFile.Active, nil
 }
 
-// UpdatePartial updates only the specified fields of a configuration
-func (cm *Manager) UpdatePartial(alias string, updates map[string]string) error {
+// GetPrevious returns the alias that was active before the last global switch
+func (cm *Manager) GetPrevious() (string, error) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
 	configFile, err := cm.loadConfigFile()
 	if err != nil {
-		return err
+		return "", err
 	}
+	if configFile.Previous == "" {
+		return "", fmt.Errorf("no previous configuration to switch back to")
+	}
+	return configFile.Previous, nil
+}
 
-	for i, config := range configFile.Configs {
-		if config.Alias == alias {
-			// Update only the fields that are provided
-			if apiKey, ok := updates["api_key"]; ok {
-				configFile.Configs[i].APIKey = apiKey
-				if apiKey != "" {
-					configFile.Configs[i].AuthToken = "" // Clear auth token
-				}
-			}
-			if authToken, ok := updates["auth_token"]; ok {
-				configFile.Configs[i].AuthToken = authToken
-				if authToken != "" {
-					configFile.Configs[i].APIKey = "" // Clear API key
-				}
-			}
-			if baseURL, ok := updates["base_url"]; ok {
-				configFile.Configs[i].BaseURL = baseURL
-			}
-			if model, ok := updates["model"]; ok {
-				configFile.Configs[i].Model = model
-			}
+// UpdatePartial updates only the specified fields of a configuration, in a
+// single save. An "alias" update renames it and a "models" update replaces
+// its models list as SetModels does.
+func (cm *Manager) UpdatePartial(alias string, updates map[string]string) error {
+	var modelList []string
+	if list, ok := updates["models"]; ok {
+		var err error
+		if modelList, err = normalizeModelList(splitList(list)); err != nil {
+			return err
+		}
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 
-			// Validate the updated config
-			validator := validation.NewValidator()
-			if err := validator.ValidateConfig(configFile.Configs[i]); err != nil {
+	active := false
+	err := cm.update(func(configFile *models.File) error {
+		// Each attempt starts from the given alias and updates
+		alias, updates := alias, updates
+		if newAlias, ok := updates["alias"]; ok && newAlias != alias {
+			if err := cm.renameConfig(configFile, alias, newAlias); err != nil {
+				return err
+			}
+			alias = newAlias
+		}
+		if _, ok := updates["models"]; ok {
+			var err error
+			if active, err = setModels(configFile, alias, modelList); err != nil {
 				return err
 			}
+		}
 
-			return cm.saveConfigFile(configFile)
+		for i, config := range configFile.Configs {
+			if config.Alias == alias {
+				// A form prefilled with the expanded base URL keeps the template
+				if baseURL, ok := updates["base_url"]; ok {
+					if resolved, err := resolveConfig(configFile.Configs, alias); err == nil && baseURL == utils.ExpandPlaceholders(resolved.BaseURL, resolved.Vars) {
+						updates = maps.Clone(updates)
+						updates["base_url"] = resolved.BaseURL
+					}
+				}
+				if config.Extends != "" {
+					updates = dropInheritedUpdates(configFile.Configs, config, updates)
+				}
+
+				// Update only the fields that are provided
+				if apiKey, ok := updates["api_key"]; ok {
+					if apiKey != config.APIKey {
+						configFile.Configs[i].KeyUpdatedAt = time.Now()
+						configFile.Configs[i].KeyExpiresAt = time.Time{} // The expiry was the old key's
+					}
+					configFile.Configs[i].APIKey = apiKey
+					if apiKey != "" {
+						configFile.Configs[i].AuthToken = "" // Clear auth token
+					}
+				}
+				if authToken, ok := updates["auth_token"]; ok {
+					if authToken != config.AuthToken {
+						configFile.Configs[i].KeyUpdatedAt = time.Now()
+						configFile.Configs[i].KeyExpiresAt = time.Time{}
+					}
+					configFile.Configs[i].AuthToken = authToken
+					if authToken != "" {
+						configFile.Configs[i].APIKey = "" // Clear API key
+						configFile.Configs[i].APIKeys = nil
+					}
+				}
+				if keys, ok := updates["api_keys"]; ok {
+					pool := splitList(keys)
+					configFile.Configs[i].APIKeys = pool
+					// Keep the picked key while it stays in the pool
+					if len(pool) > 0 && !slices.Contains(pool, configFile.Configs[i].APIKey) {
+						configFile.Configs[i].APIKey = pool[0]
+						configFile.Configs[i].AuthToken = ""
+						configFile.Configs[i].KeyUpdatedAt = time.Now()
+					}
+				}
+				if baseURL, ok := updates["base_url"]; ok {
+					configFile.Configs[i].BaseURL = utils.NormalizeBaseURL(baseURL)
+				}
+				if provider, ok := updates["provider"]; ok {
+					configFile.Configs[i].Provider = provider
+				}
+				if region, ok := updates["region"]; ok {
+					if err := setRegion(configFile, i, region); err != nil {
+						return err
+					}
+				} else if _, ok := updates["base_url"]; ok && configFile.Configs[i].Region != "" {
+					// A new base URL keeps the region only when it is one of its hosts
+					configFile.Configs[i].Region = regionOf(configFile.Configs[i].Provider, configFile.Configs[i].BaseURL)
+				}
+				if model, ok := updates["model"]; ok {
+					configFile.Configs[i].Model = model
+				}
+				if environment, ok := updates["environment"]; ok {
+					configFile.Configs[i].Environment = environment
+				}
+				if insecure, ok := updates["insecure_skip_verify"]; ok {
+					skip, err := strconv.ParseBool(insecure)
+					if err != nil {
+						return fmt.Errorf("invalid insecure_skip_verify value '%s': %w", insecure, err)
+					}
+					configFile.Configs[i].InsecureSkipVerify = skip
+				}
+				if caBundle, ok := updates["ca_bundle"]; ok {
+					configFile.Configs[i].CABundle = caBundle
+				}
+				if clientCert, ok := updates["client_cert"]; ok {
+					configFile.Configs[i].ClientCert = clientCert
+				}
+				if clientKey, ok := updates["client_key"]; ok {
+					configFile.Configs[i].ClientKey = clientKey
+				}
+				if extends, ok := updates["extends"]; ok {
+					configFile.Configs[i].Extends = extends
+				}
+				if mode, ok := updates["key_mode"]; ok {
+					if err := checkKeyMode(mode); err != nil {
+						return err
+					}
+					configFile.Configs[i].KeyMode = mode
+				}
+				if strategy, ok := updates["key_strategy"]; ok {
+					configFile.Configs[i].KeyStrategy = strings.TrimSpace(strategy)
+				}
+				if chatPath, ok := updates["chat_path"]; ok {
+					configFile.Configs[i].ChatPath = strings.TrimSpace(chatPath)
+				}
+				if version, ok := updates["api_version"]; ok {
+					configFile.Configs[i].APIVersion = strings.TrimSpace(version)
+				}
+				if betas, ok := updates["beta_features"]; ok {
+					configFile.Configs[i].BetaFeatures = splitList(betas)
+				}
+				if orgID, ok := updates["org_id"]; ok {
+					configFile.Configs[i].OrgID = strings.TrimSpace(orgID)
+				}
+				if projectID, ok := updates["project_id"]; ok {
+					configFile.Configs[i].ProjectID = strings.TrimSpace(projectID)
+				}
+				if temperature, ok := updates["temperature"]; ok {
+					value, err := parseOptionalFloat("temperature", temperature)
+					if err != nil {
+						return err
+					}
+					configFile.Configs[i].Temperature = value
+				}
+				if maxTokens, ok := updates["max_tokens"]; ok {
+					value, err := parseOptionalInt("max_tokens", maxTokens)
+					if err != nil {
+						return err
+					}
+					configFile.Configs[i].MaxTokens = value
+				}
+				if topP, ok := updates["top_p"]; ok {
+					value, err := parseOptionalFloat("top_p", topP)
+					if err != nil {
+						return err
+					}
+					configFile.Configs[i].TopP = value
+				}
+				if timeout, ok := updates["api_timeout"]; ok {
+					configFile.Configs[i].APITimeout = strings.TrimSpace(timeout)
+				}
+				if retries, ok := updates["max_retries"]; ok {
+					value, err := parseOptionalCount("max_retries", retries)
+					if err != nil {
+						return err
+					}
+					configFile.Configs[i].MaxRetries = value
+				}
+				if expires, ok := updates["key_expires_at"]; ok {
+					value, err := parseOptionalDate("key_expires_at", expires)
+					if err != nil {
+						return err
+					}
+					configFile.Configs[i].KeyExpiresAt = value
+				}
+				for key, value := range updates {
+					name, ok := strings.CutPrefix(key, VarFieldPrefix)
+					switch {
+					case !ok:
+					case value == "":
+						delete(configFile.Configs[i].Vars, name)
+					case configFile.Configs[i].Vars == nil:
+						configFile.Configs[i].Vars = map[string]string{name: value}
+					default:
+						configFile.Configs[i].Vars[name] = value
+					}
+				}
+
+				// Validate the updated config
+				if err := validateResolved(configFile.Configs, configFile.Configs[i]); err != nil {
+					return err
+				}
+
+				return nil
+			}
 		}
+
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+	})
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("configuration '%s' does not exist", alias)
+	// New models may change the model of the active configuration
+	if active {
+		return cm.generateActiveScript()
+	}
+	return nil
 }
 
 // RenameAlias renames a configuration alias
@@ -421,11 +952,14 @@ func (cm *Manager) RenameAlias(oldAlias, newAlias string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configFile, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
+	return cm.update(func(configFile *models.File) error {
+		return cm.renameConfig(configFile, oldAlias, newAlias)
+	})
+}
 
+// renameConfig renames a configuration of configFile, along with the
+// references to it
+func (cm *Manager) renameConfig(configFile *models.File, oldAlias, newAlias string) error {
 	// Check if new alias already exists
 	for _, cfg := range configFile.Configs {
 		if cfg.Alias == newAlias {
@@ -433,6 +967,10 @@ func (cm *Manager) RenameAlias(oldAlias, newAlias string) error {
 		}
 	}
 
+	if err := cm.checkNotTeam(configFile, oldAlias); err != nil {
+		return err
+	}
+
 	// Find and rename
 	found := false
 	for i, cfg := range configFile.Configs {
@@ -444,15 +982,26 @@ func (cm *Manager) RenameAlias(oldAlias, newAlias string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("configuration '%s' does not exist", oldAlias)
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", oldAlias)
+	}
+
+	// Keep configs extending the renamed one pointing at it
+	for i, cfg := range configFile.Configs {
+		if cfg.Extends == oldAlias {
+			configFile.Configs[i].Extends = newAlias
+		}
 	}
 
 	// Update active config if needed
 	if configFile.Active == oldAlias {
 		configFile.Active = newAlias
 	}
+	if configFile.Previous == oldAlias {
+		configFile.Previous = newAlias
+	}
+	renameGroupMembers(configFile, oldAlias, newAlias)
 
-	return cm.saveConfigFile(configFile)
+	return nil
 }
 
 // SwitchModel switches the active model for a configuration.
@@ -461,38 +1010,40 @@ func (cm *Manager) SwitchModel(alias string, model string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	configFile, err := cm.loadConfigFile()
-	if err != nil {
-		return err
-	}
-
-	// Find the configuration by alias
-	for i, config := range configFile.Configs {
-		if config.Alias == alias {
-			// Validate model is in supported list
-			validator := validation.NewModelValidator()
-			if err := validator.ValidateModelInList(model, config.Models); err != nil {
-				return err
-			}
-
-			// Update active model
-			configFile.Configs[i].Model = model
+	active := false
+	err := cm.update(func(configFile *models.File) error {
+		// Find the configuration by alias
+		for i, config := range configFile.Configs {
+			if config.Alias == alias {
+				// Validate model is in supported list, which may be inherited
+				resolved, err := resolveEffective(configFile, alias)
+				if err != nil {
+					return err
+				}
+				validator := validation.NewModelValidator()
+				if err := validator.ValidateModelInList(model, resolved.Models); err != nil {
+					return err
+				}
 
-			// Save configuration
-			if err := cm.saveConfigFile(configFile); err != nil {
-				return err
-			}
+				// Update active model
+				configFile.Configs[i].Model = model
 
-			// If this is the active configuration, update the active.env
-			if configFile.Active == alias {
-				return cm.generateActiveScript()
+				active = configFile.Active == alias
+				return nil
 			}
-
-			return nil
 		}
+
+		return exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+	})
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("configuration '%s' does not exist", alias)
+	// If this is the active configuration, update the active.env
+	if active {
+		return cm.generateActiveScript()
+	}
+	return nil
 }
 
 // GetModels returns the supported models list for a configuration.
@@ -505,39 +1056,67 @@ func (cm *Manager) GetModels(alias string) ([]string, error) {
 		return nil, err
 	}
 
-	for _, config := range configFile.Configs {
-		if config.Alias == alias {
-			// Return a copy to prevent external modification
-			result := make([]string, len(config.Models))
-			copy(result, config.Models)
-			return result, nil
-		}
+	config, err := resolveEffective(configFile, alias)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("configuration '%s' does not exist", alias)
+	// Return a copy to prevent external modification
+	result := make([]string, len(config.Models))
+	copy(result, config.Models)
+	return result, nil
 }
 
 // SetModels updates the supported models list for a configuration.
 // It validates the models list and handles active model fallback when the current active model is removed.
-func (cm *Manager) SetModels(alias string, models []string) error {
+func (cm *Manager) SetModels(alias string, modelList []string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
 	// Validate and normalize the models list
-	validator := validation.NewModelValidator()
-	normalizedModels := validator.NormalizeModels(models)
-	if err := validator.ValidateModelsList(normalizedModels); err != nil {
+	normalizedModels, err := normalizeModelList(modelList)
+	if err != nil {
 		return err
 	}
 
-	configFile, err := cm.loadConfigFile()
+	active := false
+	err = cm.update(func(configFile *models.File) error {
+		var err error
+		active, err = setModels(configFile, alias, normalizedModels)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	// If this is the active configuration, update the active.env
+	if active {
+		return cm.generateActiveScript()
+	}
+	return nil
+}
+
+// normalizeModelList normalizes and validates a models list
+func normalizeModelList(modelList []string) ([]string, error) {
+	validator := validation.NewModelValidator()
+	normalizedModels := validator.NormalizeModels(modelList)
+	if err := validator.ValidateModelsList(normalizedModels); err != nil {
+		return nil, err
+	}
+	return normalizedModels, nil
+}
+
+// setModels sets the models list of a configuration of configFile, falling
+// back to the first model when the current one was removed. It reports
+// whether the configuration is the active one.
+func setModels(configFile *models.File, alias string, normalizedModels []string) (bool, error) {
 	// Find the configuration by alias
 	for i, config := range configFile.Configs {
 		if config.Alias == alias {
+			// Compare against the effective model, which may be inherited
+			if resolved, err := resolveEffective(configFile, alias); err == nil {
+				config.Model = resolved.Model
+			}
+
 			// Update models list
 			configFile.Configs[i].Models = normalizedModels
 
@@ -556,21 +1135,86 @@ func (cm *Manager) SetModels(alias string, models []string) error {
 				configFile.Configs[i].Model = normalizedModels[0]
 			}
 
-			// Save configuration
-			if err := cm.saveConfigFile(configFile); err != nil {
-				return err
-			}
+			return configFile.Active == alias, nil
+		}
+	}
 
-			// If this is the active configuration, update the active.env
-			if configFile.Active == alias {
-				return cm.generateActiveScript()
-			}
+	return false, exitcode.New(exitcode.NotFound, "configuration '%s' does not exist", alias)
+}
 
-			return nil
+// validateResolved validates cfg as it will be used, with inherited fields
+// filled in from the config it extends. configs is the stored list, in which
+// cfg replaces any entry with the same alias.
+func validateResolved(configs []models.APIConfig, cfg models.APIConfig) error {
+	if cfg.Extends == cfg.Alias && cfg.Alias != "" {
+		return exitcode.New(exitcode.Validation, "configuration '%s' cannot extend itself", cfg.Alias)
+	}
+
+	candidate := make([]models.APIConfig, 0, len(configs)+1)
+	for _, c := range configs {
+		if c.Alias != cfg.Alias {
+			candidate = append(candidate, c)
 		}
 	}
+	candidate = append(candidate, cfg)
 
-	return fmt.Errorf("configuration '%s' does not exist", alias)
+	resolved := cfg
+	if cfg.Extends != "" {
+		var err error
+		if resolved, err = resolveConfig(candidate, cfg.Alias); err != nil {
+			return err
+		}
+	}
+	return exitcode.Wrap(exitcode.Validation, validation.NewValidator().ValidateConfig(resolved))
+}
+
+// GetKeybindings returns the TUI keybinding overrides from the config file
+func (cm *Manager) GetKeybindings() (map[string][]string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return configFile.Keybindings, nil
+}
+
+// GetTestSettings returns the compatibility test defaults from the config file,
+// or nil when none are configured
+func (cm *Manager) GetTestSettings() (*models.TestSettings, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to the timeout in the defaults block
+	settings := configFile.TestSettings
+	if configFile.Defaults != nil && configFile.Defaults.TestTimeout != "" && (settings == nil || settings.Timeout == "") {
+		withDefault := models.TestSettings{}
+		if settings != nil {
+			withDefault = *settings
+		}
+		withDefault.Timeout = configFile.Defaults.TestTimeout
+		settings = &withDefault
+	}
+	return settings, nil
+}
+
+// NotificationsEnabled reports whether desktop notifications are enabled.
+// They are on unless the config file sets "notifications": false.
+func (cm *Manager) NotificationsEnabled() (bool, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return false, err
+	}
+	return configFile.Notifications == nil || *configFile.Notifications, nil
 }
 
 // GenerateActiveScript generates the activation script for active configuration
@@ -587,26 +1231,21 @@ func (cm *Manager) generateActiveScript() error {
 	configFile, err := cm.loadConfigFile()
 	if err != nil {
 		// No active configuration, clean up active.env file
-		activeEnvPath := filepath.Join(filepath.Dir(cm.configPath), "active.env")
+		activeEnvPath := filepath.Join(cm.StateDir(), ActiveEnvFileName)
 		os.Remove(activeEnvPath)
 		return nil
 	}
 
 	var active *models.APIConfig
 	if configFile.Active != "" {
-		for _, config := range configFile.Configs {
-			if config.Alias == configFile.Active {
-				// Create a copy to avoid implicit memory aliasing
-				activeCopy := config
-				active = &activeCopy
-				break
-			}
+		if resolved, err := resolveEffective(configFile, configFile.Active); err == nil {
+			active = &resolved
 		}
 	}
 
 	if active == nil {
 		// No active configuration, clean up active.env file
-		activeEnvPath := filepath.Join(filepath.Dir(cm.configPath), "active.env")
+		activeEnvPath := filepath.Join(cm.StateDir(), ActiveEnvFileName)
 		os.Remove(activeEnvPath)
 		return nil
 	}
@@ -615,15 +1254,15 @@ func (cm *Manager) generateActiveScript() error {
 	envScript := syncpkg.GenerateEnvScript(active)
 
 	// Write to file
-	activeEnvPath := filepath.Join(filepath.Dir(cm.configPath), "active.env")
+	activeEnvPath := filepath.Join(cm.StateDir(), ActiveEnvFileName)
 	if err := os.WriteFile(activeEnvPath, []byte(envScript), 0600); err != nil {
 		return err
 	}
 
 	// Sync to global Claude Code settings (optional feature, doesn't affect main flow)
 	if syncErr := cm.SyncClaudeSettingsOnly(active); syncErr != nil {
-		// Silently ignore error in TUI mode or log to file if logger is available
-		// fmt.Printf("⚠️  Failed to sync to global Claude Code settings: %v\n", syncErr)
+		// Not printed so the TUI stays intact, see --verbose or --log-file
+		slog.Warn("failed to sync Claude Code settings", "alias", active.Alias, "error", syncErr)
 	}
 
 	return nil
@@ -633,54 +1272,122 @@ func (cm *Manager) generateActiveScript() error {
 // without updating global active field or generating active.env file.
 // This is used for local mode to update Claude Code immediately.
 func (cm *Manager) SyncClaudeSettingsOnly(cfg *models.APIConfig) error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+
 	// Sync to global Claude Code settings
 	if err := cm.syncClaudeSettings(cfg); err != nil {
-		return fmt.Errorf("failed to sync to global Claude Code settings: %v", err)
+		return fmt.Errorf("failed to sync Claude Code settings: %v", err)
 	}
 
 	return nil
 }
 
-// syncClaudeSettings syncs configuration to global Claude Code settings file
-// Uses surgical update mechanism to preserve JSON structure and non-ANTHROPIC fields
+// syncClaudeSettings syncs configuration to every Claude Code settings file
+// enabled as a sync target, continuing past targets that fail
 func (cm *Manager) syncClaudeSettings(cfg *models.APIConfig) error {
-	claudeSettingsPath := filepath.Join(os.Getenv("HOME"), ".claude", "settings.json")
+	if err := utils.CheckPlaceholders(cfg.BaseURL); err != nil {
+		return err
+	}
 
-	// Check if Claude Code config file exists
-	if _, err := os.Stat(claudeSettingsPath); os.IsNotExist(err) {
-		// models.File doesn't exist, skip sync
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		configFile = &models.File{}
+	}
+	targets := syncTargets(configFile)
+	if len(targets) == 0 {
+		slog.Debug("no sync targets enabled, skipping sync")
 		return nil
 	}
 
-	// Read existing settings content (raw to preserve structure and comments)
-	originalContent, err := os.ReadFile(claudeSettingsPath)
-	if err != nil {
-		return fmt.Errorf("Failed to read global Claude Code settings: %v", err)
+	opts := cm.keySyncOptions(cfg, configFile.Defaults)
+	if opts.KeyHelper != "" {
+		if err := cm.writeKeyHelper(cfg); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := cm.syncSettingsFile(target.Path, cfg, target.keyOptions(opts)); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", target.Name, target.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// keySyncOptions returns how cfg's key is written into settings files: in
+// helper key mode they reference a script printing the key, in shell key mode
+// they hold no key at all
+func (cm *Manager) keySyncOptions(cfg *models.APIConfig, defaults *models.Defaults) syncpkg.SyncOptions {
+	mode := keyMode(cfg, defaults)
+	opts := syncpkg.SyncOptions{OmitKey: mode == KeyModeShell}
+	if mode == KeyModeHelper && (cfg.APIKey != "" || cfg.AuthToken != "") {
+		opts.KeyHelper = cm.KeyHelperPath()
 	}
+	return opts
+}
 
+// updateSettingsContent returns Claude Code settings content with a config
+// written into it, keyOpts choosing how the key is written
+func (cm *Manager) updateSettingsContent(content string, cfg *models.APIConfig, keyOpts syncpkg.SyncOptions) (string, error) {
 	// Create synchronization options
 	opts := syncpkg.SyncOptions{
 		DryRun:        false,
 		CreateBackup:  true,  // Create backup before update to ensure data safety
 		PreserveOther: true,  // Preserve non-ANTHROPIC environment variables
+		KeyHelper:     keyOpts.KeyHelper,
+		OmitKey:       keyOpts.OmitKey,
 	}
 
 	// Perform surgical update using sjson
-	updatedContent, err := syncpkg.UpdateEnvField(string(originalContent), cfg, opts)
+	updatedContent, err := syncpkg.UpdateEnvField(content, cfg, opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.KeyHelper == "" {
+		// Drop the helper left by helper key mode
+		return syncpkg.RemoveKeyHelper(updatedContent, cm.KeyHelperPath())
+	}
+	return updatedContent, nil
+}
+
+// syncSettingsFile writes a config into one Claude Code settings file, keyOpts
+// choosing how the key is written
+// Uses surgical update mechanism to preserve JSON structure and non-ANTHROPIC fields
+func (cm *Manager) syncSettingsFile(claudeSettingsPath string, cfg *models.APIConfig, keyOpts syncpkg.SyncOptions) error {
+	// Check if Claude Code config file exists
+	if _, err := os.Stat(claudeSettingsPath); os.IsNotExist(err) {
+		// models.File doesn't exist, skip sync
+		slog.Debug("Claude Code settings not found, skipping sync", "path", claudeSettingsPath)
+		return nil
+	}
+
+	// Read existing settings content (raw to preserve structure and comments)
+	originalContent, err := os.ReadFile(claudeSettingsPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read Claude Code settings: %v", err)
+	}
+
+	updatedContent, err := cm.updateSettingsContent(string(originalContent), cfg, keyOpts)
 	if err != nil {
 		return fmt.Errorf("Failed to update settings content: %v", err)
 	}
 
 	// Write back to file using atomic update to prevent data corruption
-	if err := storage.AtomicFileUpdate(claudeSettingsPath, updatedContent, true); err != nil {
+	backups := storage.NewBackupManager(storage.DefaultBackupRetention)
+	backups.Dir = cm.BackupDir()
+	if err := storage.AtomicFileUpdate(claudeSettingsPath, updatedContent, backups); err != nil {
 		// Attempt to restore from backup if update fails
-		restoreErr := storage.NewBackupManager(storage.DefaultBackupRetention).RestoreFromLatestBackup(claudeSettingsPath)
+		restoreErr := backups.RestoreFromLatestBackup(claudeSettingsPath)
 		if restoreErr != nil {
 			return fmt.Errorf("Failed to write settings file and restore from backup: update error=%v, restore error=%v", err, restoreErr)
 		}
 		return fmt.Errorf("Failed to write settings file but restored from backup: %v", err)
 	}
 
+	slog.Debug("synced Claude Code settings", "path", claudeSettingsPath, "alias", cfg.Alias)
 	return nil
 }
 
@@ -702,16 +1409,35 @@ func (cm *Manager) RestoreClaudeToGlobal() error {
 func (cm *Manager) clearClaudeSettings() error {
 	// Clear global Claude Code settings
 	if err := cm.clearGlobalClaudeSettings(); err != nil {
-		return fmt.Errorf("failed to clear global Claude Code settings: %v", err)
+		return fmt.Errorf("failed to clear global Claude Code settings: %w", err)
 	}
 
 	return nil
 }
 
-// clearGlobalClaudeSettings removes ANTHROPIC_* env vars from global Claude Code settings
+// clearGlobalClaudeSettings removes ANTHROPIC_* env vars from every sync target
 func (cm *Manager) clearGlobalClaudeSettings() error {
-	claudeSettingsPath := filepath.Join(os.Getenv("HOME"), ".claude", "settings.json")
+	if err := checkWritable(); err != nil {
+		return err
+	}
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		configFile = &models.File{}
+	}
 
+	var errs []error
+	for _, target := range syncTargets(configFile) {
+		if err := clearSettingsFile(target.Path, cm.KeyHelperPath()); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", target.Name, target.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// clearSettingsFile removes ANTHROPIC_* env vars and the apiKeyHelper set to
+// keyHelper from one Claude Code settings file
+func clearSettingsFile(claudeSettingsPath, keyHelper string) error {
 	// Check if Claude Code config file exists
 	if _, err := os.Stat(claudeSettingsPath); os.IsNotExist(err) {
 		// models.File doesn't exist, nothing to clear
@@ -721,36 +1447,33 @@ func (cm *Manager) clearGlobalClaudeSettings() error {
 	// Read existing settings
 	data, err := os.ReadFile(claudeSettingsPath)
 	if err != nil {
-		return fmt.Errorf("failed to read global Claude Code settings: %v", err)
+		return fmt.Errorf("failed to read Claude Code settings: %v", err)
+	}
+
+	// Clear ANTHROPIC related variables and the request settings apimgr
+	// wrote, keeping comments and other fields
+	names := append([]string{
+		"ANTHROPIC_API_KEY",
+		"ANTHROPIC_AUTH_TOKEN",
+		"ANTHROPIC_BASE_URL",
+		"ANTHROPIC_MODEL",
+		syncpkg.CustomHeadersEnvVar,
+		syncpkg.SyncedEnvVar,
+	}, syncpkg.SyncedRequestSettings(syncpkg.ParseSettingsEnv(data))...)
+	updated, err := syncpkg.RemoveEnvFields(string(data), names)
+	if err == nil {
+		updated, err = syncpkg.RemoveKeyHelper(updated, keyHelper)
 	}
-
-	var settings map[string]interface{}
-	if err := json.Unmarshal(data, &settings); err != nil {
-		return fmt.Errorf("failed to parse global Claude Code settings: %v", err)
+	if err != nil {
+		return fmt.Errorf("failed to parse Claude Code settings: %v", err)
 	}
-
-	// Check if env field exists
-	if settings["env"] == nil {
-		// No env field, nothing to clear
+	if updated == string(data) {
+		// Nothing to clear
 		return nil
 	}
 
-	env := settings["env"].(map[string]interface{})
-
-	// Clear ANTHROPIC related variables
-	delete(env, "ANTHROPIC_API_KEY")
-	delete(env, "ANTHROPIC_AUTH_TOKEN")
-	delete(env, "ANTHROPIC_BASE_URL")
-	delete(env, "ANTHROPIC_MODEL")
-
-	// Write back to file
-	updatedData, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize global Claude Code settings: %v", err)
-	}
-
-	if err := os.WriteFile(claudeSettingsPath, updatedData, 0600); err != nil {
-		return fmt.Errorf("failed to write global Claude Code settings: %v", err)
+	if err := os.WriteFile(claudeSettingsPath, []byte(updated), 0600); err != nil {
+		return fmt.Errorf("failed to write Claude Code settings: %v", err)
 	}
 
 	return nil
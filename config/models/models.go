@@ -1,18 +1,247 @@
 package models
 
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
 // APIConfig represents a single API configuration
 type APIConfig struct {
 	Alias     string   `json:"alias"`
 	Provider  string   `json:"provider"` // API provider type
 	APIKey    string   `json:"api_key"`
 	AuthToken string   `json:"auth_token"`
-	BaseURL   string   `json:"base_url"`
+	BaseURL   string   `json:"base_url"`         // May contain {name} placeholders filled from Vars
+	Region    string   `json:"region,omitempty"` // Region of a built-in regional endpoint, picks the base URL
 	Model     string   `json:"model"`            // Currently active model
 	Models    []string `json:"models,omitempty"` // Supported models list
+
+	Environment string `json:"environment,omitempty"` // Deployment environment, e.g. dev/staging/prod
+	Extends     string `json:"extends,omitempty"`     // Alias of the base config that unset fields are inherited from
+
+	Vars map[string]string `json:"vars,omitempty"` // Values for the base URL's {name} placeholders
+
+	ChatPath string `json:"chat_path,omitempty"` // Chat endpoint path of tests for relays with non-standard routes
+
+	Hooks *Hooks `json:"hooks,omitempty"` // Commands run around a switch to this config
+
+	KeyMode string `json:"key_mode,omitempty"` // How Claude Code gets the key, overrides defaults.key_mode
+
+	APIKeys     []string `json:"api_keys,omitempty"`     // Key pool, api_key holds the key picked from it
+	KeyStrategy string   `json:"key_strategy,omitempty"` // How switch picks from the pool: "round-robin" (default) or "lru"
+
+	APIVersion   string   `json:"api_version,omitempty"`   // anthropic-version header, the API's default when empty
+	BetaFeatures []string `json:"beta_features,omitempty"` // anthropic-beta header values
+
+	OrgID     string `json:"org_id,omitempty"`     // Organization of org-scoped keys, e.g. OPENAI_ORG_ID
+	ProjectID string `json:"project_id,omitempty"` // Project of project-scoped keys, e.g. OPENAI_PROJECT_ID
+
+	Temperature *float64 `json:"temperature,omitempty"` // Sampling temperature of test requests, the API's default when nil
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Output token limit of test requests and Claude Code
+	TopP        *float64 `json:"top_p,omitempty"`       // Nucleus sampling of test requests, the API's default when nil
+
+	APITimeout string `json:"api_timeout,omitempty"` // Request timeout of Claude Code, e.g. "10m", exported as API_TIMEOUT_MS
+	MaxRetries *int   `json:"max_retries,omitempty"` // Retries of Claude Code after a failed request, its default when nil
+
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // Skip TLS certificate verification in tests
+	CABundle           string `json:"ca_bundle,omitempty"`            // PEM CA bundle path for private CAs
+	ClientCert         string `json:"client_cert,omitempty"`          // PEM client certificate path for mutual TLS
+	ClientKey          string `json:"client_key,omitempty"`           // PEM client key path for mutual TLS
+
+	KeyUpdatedAt time.Time `json:"key_updated_at,omitzero"` // When the API key or auth token was last set
+	KeyExpiresAt time.Time `json:"key_expires_at,omitzero"` // When the API key or auth token expires, zero when it does not
+	LastUsedAt   time.Time `json:"last_used_at,omitzero"`   // When the config was last switched to
+
+	Source string `json:"-"` // Included file the config was loaded from, empty for the main config file
 }
 
 // File represents the structure of the config file
 type File struct {
-	Active  string     `json:"active"`
-	Configs []APIConfig `json:"configs"`
+	Active      string              `json:"active"`
+	Previous    string              `json:"previous,omitempty"` // Previously active alias, for switching back
+	Include     []string            `json:"include,omitempty"`  // Files whose configs are merged in, relative to this one
+	Team        *Team               `json:"team,omitempty"`     // Shared team file the configs of this one are overlaid on
+	Configs     []APIConfig         `json:"configs"`
+	Keybindings map[string][]string `json:"keybindings,omitempty"` // TUI key overrides, action -> keys
+
+	TestSettings *TestSettings `json:"test_settings,omitempty"` // Compatibility test defaults
+
+	Notifications *bool `json:"notifications,omitempty"` // Desktop notifications in watch mode, nil means enabled
+
+	Defaults *Defaults `json:"defaults,omitempty"` // Values applied to configs that leave them empty
+
+	Hooks *Hooks `json:"hooks,omitempty"` // Commands run around every switch
+
+	Targets map[string]string `json:"targets,omitempty"` // Extra Claude Code settings files synced on switch, name -> path
+
+	Projects []Project `json:"projects,omitempty"` // Projects whose Claude Code settings are synced on switch
+
+	Groups map[string]Group `json:"groups,omitempty"` // Configs switched to with group:<name>, name -> group
+
+	Trash []TrashedConfig `json:"trash,omitempty"` // Removed configs kept for "apimgr trash restore"
+
+	ETag string `json:"-"` // Identifies the content the file was loaded from, see config.Manager
+
+	IncludedFiles []string `json:"-"` // Included files whose configs were merged in, see config.Manager
+
+	IncludedETags map[string]string `json:"-"` // ETags of the included files as loaded, path -> ETag
+
+	TeamConfigs []APIConfig `json:"-"` // Configs of the team file, see config.Manager
+}
+
+// Team locates the shared, version-controlled team file holding configs
+// without keys. Configs of the main file with the same alias override the
+// fields they set.
+type Team struct {
+	File string `json:"file,omitempty"` // Team file relative to the config file, team.json when empty
+	URL  string `json:"url,omitempty"`  // Where "apimgr sync team pull" downloads it from, a git pull when empty
+}
+
+// TrashedConfig is a removed configuration, kept until it is restored or
+// its retention runs out
+type TrashedConfig struct {
+	Config    APIConfig `json:"config"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// Group is a named set of configurations "apimgr switch group:<name>"
+// picks one of, splitting traffic across them
+type Group struct {
+	Members  []GroupMember `json:"members"`
+	Strategy string        `json:"strategy,omitempty"` // "weighted" (default) or "sticky", see config/group
+}
+
+// GroupMember is a configuration of a group and its share of the picks
+type GroupMember struct {
+	Alias  string `json:"alias"`
+	Weight int    `json:"weight,omitempty"` // Relative share, 0 means 1
+}
+
+// Project is a project whose .claude settings file is synced on switch
+type Project struct {
+	Root  string `json:"root"`            // Project directory
+	Local bool   `json:"local,omitempty"` // Sync settings.local.json, which is not committed, instead of settings.json
+}
+
+// Hooks holds shell commands run around a switch, e.g. to restart a proxy
+type Hooks struct {
+	PreSwitch  []string `json:"pre_switch,omitempty"`  // Run before switching, a failure cancels the switch
+	PostSwitch []string `json:"post_switch,omitempty"` // Run after switching, failures are reported
+}
+
+// Defaults holds the values applied to configs that leave a field empty
+type Defaults struct {
+	Provider    string   `json:"provider,omitempty"`     // Provider of new configs, "anthropic" when unset
+	Model       string   `json:"model,omitempty"`        // Model of configs without one
+	SyncTargets []string `json:"sync_targets,omitempty"` // Targets synced on switch, nil means claude, every declared target and the enabled projects
+	TestTimeout string   `json:"test_timeout,omitempty"` // Test timeout when test_settings has none, e.g. "30s"
+	KeyMode     string   `json:"key_mode,omitempty"`     // How Claude Code gets the key: "env" (default), "helper" or "shell"
+
+	MessageTimeout string `json:"message_timeout,omitempty"` // How long TUI status messages stay, e.g. "5s", "0" keeps them
+
+	TrashDays int `json:"trash_days,omitempty"` // Days removed configs stay in the trash, 30 when unset
+
+	VerifySwitch bool `json:"verify_switch,omitempty"` // Test the target of every switch and cancel it when the test fails
+
+	Lock string `json:"lock,omitempty"` // How the config file is locked: "auto" (default), "flock" or "lockfile", APIMGR_LOCK overrides it
+}
+
+// TestSettings holds the compatibility test defaults shared by the CLI and TUI
+type TestSettings struct {
+	Timeout string `json:"timeout,omitempty"` // Per-request timeout, e.g. "30s"
+	Retries int    `json:"retries,omitempty"` // Retries after a transient failure
+	Backoff string `json:"backoff,omitempty"` // Initial delay between retries, e.g. "1s"
+
+	PingCount int `json:"ping_count,omitempty"` // Samples sent per ping
+
+	CacheTTL string `json:"cache_ttl,omitempty"` // How long the TUI reuses a result, e.g. "10m", "0" always tests
+}
+
+// Clone returns a copy of c that shares no slices, maps or pointers with it
+func (c APIConfig) Clone() APIConfig {
+	c.Models = slices.Clone(c.Models)
+	c.BetaFeatures = slices.Clone(c.BetaFeatures)
+	c.APIKeys = slices.Clone(c.APIKeys)
+	c.Temperature = clonePtr(c.Temperature)
+	c.TopP = clonePtr(c.TopP)
+	c.MaxRetries = clonePtr(c.MaxRetries)
+	c.Vars = maps.Clone(c.Vars)
+	c.Hooks = c.Hooks.Clone()
+	return c
+}
+
+// Clone returns a copy of f that shares no slices, maps or pointers with it
+func (f *File) Clone() *File {
+	clone := *f
+	if f.Configs != nil {
+		clone.Configs = make([]APIConfig, len(f.Configs))
+		for i, cfg := range f.Configs {
+			clone.Configs[i] = cfg.Clone()
+		}
+	}
+	if f.Keybindings != nil {
+		clone.Keybindings = make(map[string][]string, len(f.Keybindings))
+		for action, keys := range f.Keybindings {
+			clone.Keybindings[action] = slices.Clone(keys)
+		}
+	}
+	if f.TestSettings != nil {
+		settings := *f.TestSettings
+		clone.TestSettings = &settings
+	}
+	if f.Notifications != nil {
+		notifications := *f.Notifications
+		clone.Notifications = &notifications
+	}
+	if f.Defaults != nil {
+		defaults := *f.Defaults
+		defaults.SyncTargets = slices.Clone(defaults.SyncTargets)
+		clone.Defaults = &defaults
+	}
+	clone.Hooks = f.Hooks.Clone()
+	clone.Targets = maps.Clone(f.Targets)
+	clone.Projects = slices.Clone(f.Projects)
+	if f.Groups != nil {
+		clone.Groups = make(map[string]Group, len(f.Groups))
+		for name, g := range f.Groups {
+			g.Members = slices.Clone(g.Members)
+			clone.Groups[name] = g
+		}
+	}
+	if f.Trash != nil {
+		clone.Trash = make([]TrashedConfig, len(f.Trash))
+		for i, t := range f.Trash {
+			t.Config = t.Config.Clone()
+			clone.Trash[i] = t
+		}
+	}
+	clone.Include = slices.Clone(f.Include)
+	clone.IncludedFiles = slices.Clone(f.IncludedFiles)
+	clone.IncludedETags = maps.Clone(f.IncludedETags)
+	clone.Team = clonePtr(f.Team)
+	if f.TeamConfigs != nil {
+		clone.TeamConfigs = make([]APIConfig, len(f.TeamConfigs))
+		for i, cfg := range f.TeamConfigs {
+			clone.TeamConfigs[i] = cfg.Clone()
+		}
+	}
+	return &clone
+}
+
+// Clone returns a copy of h, nil when h is nil
+func (h *Hooks) Clone() *Hooks {
+	if h == nil {
+		return nil
+	}
+	return &Hooks{PreSwitch: slices.Clone(h.PreSwitch), PostSwitch: slices.Clone(h.PostSwitch)}
+}
+
+// clonePtr returns a pointer to a copy of *p, nil when p is nil
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
 }
@@ -0,0 +1,29 @@
+//go:build darwin || freebsd
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// networkFilesystemTypes lists the filesystem types whose file locks are
+// unreliable
+var networkFilesystemTypes = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+	"cifs":   true,
+}
+
+// networkFilesystem returns the type of the network filesystem dir is on,
+// empty when it is local or cannot be told
+func networkFilesystem(dir string) string {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return ""
+	}
+	name := unix.ByteSliceToString(stat.Fstypename[:])
+	if networkFilesystemTypes[name] {
+		return name
+	}
+	return ""
+}
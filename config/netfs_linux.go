@@ -0,0 +1,29 @@
+//go:build linux
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// networkFilesystemTypes names the filesystem types, by statfs magic number,
+// whose file locks are unreliable
+var networkFilesystemTypes = map[int64]string{
+	unix.NFS_SUPER_MAGIC:  "nfs",
+	unix.SMB_SUPER_MAGIC:  "smb",
+	unix.SMB2_SUPER_MAGIC: "smb2",
+	unix.CIFS_SUPER_MAGIC: "cifs",
+	unix.V9FS_MAGIC:       "9p",
+	unix.CEPH_SUPER_MAGIC: "ceph",
+	unix.AFS_SUPER_MAGIC:  "afs",
+	unix.AFS_FS_MAGIC:     "afs",
+	unix.CODA_SUPER_MAGIC: "coda",
+}
+
+// networkFilesystem returns the type of the network filesystem dir is on,
+// empty when it is local or cannot be told
+func networkFilesystem(dir string) string {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return ""
+	}
+	return networkFilesystemTypes[int64(stat.Type)]
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd
+
+package config
+
+// networkFilesystem returns the type of the network filesystem dir is on.
+// It cannot be told on this platform; set APIMGR_LOCK=lockfile instead.
+func networkFilesystem(dir string) string {
+	return ""
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"apimgr/internal/notice"
+)
+
+// PermissionIssue is a file or directory holding keys that others can
+// read, or a directory others can write to
+type PermissionIssue struct {
+	Path string
+	Mode os.FileMode // Current permissions
+	Want os.FileMode // Tightened permissions
+}
+
+// String describes the issue, e.g. "config.json is 0644, want 0600"
+func (p PermissionIssue) String() string {
+	return fmt.Sprintf("%s is %04o, want %04o", p.Path, p.Mode.Perm(), p.Want.Perm())
+}
+
+// CheckPermissions returns the config file, active.env and backups that
+// are not 0600, and their directories when group or world writable. The
+// directory of a config file selected with --config or APIMGR_CONFIG is
+// left alone unless it is apimgr's own. Platforms without Unix permissions
+// report nothing.
+func (cm *Manager) CheckPermissions() ([]PermissionIssue, error) {
+	if !unixPermissions {
+		return nil, nil
+	}
+
+	files := []string{cm.configPath, filepath.Join(cm.StateDir(), ActiveEnvFileName)}
+	backups, err := filepath.Glob(filepath.Join(cm.BackupDir(), "*.backup-*"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, backups...)
+	dirs := []string{cm.StateDir(), cm.BackupDir()}
+	if configDir := filepath.Dir(cm.configPath); ownConfigDir(configDir) {
+		dirs = append(dirs, configDir)
+	}
+
+	var issues []PermissionIssue
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if mode := info.Mode().Perm(); mode&0177 != 0 {
+			issues = append(issues, PermissionIssue{Path: path, Mode: mode, Want: mode & 0600})
+		}
+	}
+	seen := map[string]bool{}
+	for _, path := range dirs {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if mode := info.Mode().Perm(); mode&0022 != 0 {
+			issues = append(issues, PermissionIssue{Path: path, Mode: mode, Want: mode &^ 0022})
+		}
+	}
+	return issues, nil
+}
+
+// ownConfigDir reports whether dir is apimgr's config directory or one of
+// its workspaces, rather than a directory holding a file passed with --config
+func ownConfigDir(dir string) bool {
+	base, err := baseConfigDir()
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(base, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// FixPermissions tightens the permissions of every issue
+func FixPermissions(issues []PermissionIssue) error {
+	for _, issue := range issues {
+		if err := os.Chmod(issue.Path, issue.Want); err != nil {
+			return fmt.Errorf("failed to change permissions of %s: %w", issue.Path, err)
+		}
+	}
+	return nil
+}
+
+// warnPermissions prints a warning to stderr when files holding keys have
+// loose permissions
+func (cm *Manager) warnPermissions() {
+	issues, err := cm.CheckPermissions()
+	if err != nil || len(issues) == 0 {
+		return
+	}
+	notice.Printf("⚠️  %d apimgr path(s) holding API keys have loose permissions, run 'apimgr doctor --fix-perms'\n", len(issues))
+}
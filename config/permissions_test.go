@@ -0,0 +1,91 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SetActive("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := cm.CheckPermissions()
+	if err != nil {
+		t.Fatalf("CheckPermissions() unexpected error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("CheckPermissions() on fresh files = %v, want none", issues)
+	}
+
+	activeEnv := filepath.Join(cm.StateDir(), ActiveEnvFileName)
+	os.Chmod(cm.configPath, 0644)
+	os.Chmod(activeEnv, 0640)
+	os.Chmod(cm.StateDir(), 0777)
+
+	issues, err = cm.CheckPermissions()
+	if err != nil {
+		t.Fatalf("CheckPermissions() unexpected error: %v", err)
+	}
+	want := map[string]os.FileMode{cm.configPath: 0600, activeEnv: 0600, cm.StateDir(): 0755}
+	if len(issues) != len(want) {
+		t.Fatalf("CheckPermissions() = %v, want %d issues", issues, len(want))
+	}
+	for _, issue := range issues {
+		if want[issue.Path] != issue.Want {
+			t.Errorf("issue %s, want mode %04o", issue, want[issue.Path])
+		}
+	}
+
+	if err := FixPermissions(issues); err != nil {
+		t.Fatalf("FixPermissions() unexpected error: %v", err)
+	}
+	if issues, _ := cm.CheckPermissions(); len(issues) != 0 {
+		t.Errorf("CheckPermissions() after fix = %v, want none", issues)
+	}
+}
+
+func TestCheckPermissionsConfigDir(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	tests := []struct {
+		name string
+		dir  string
+		want bool
+	}{
+		{name: "apimgr's config directory", dir: filepath.Join(xdg, "apimgr"), want: true},
+		{name: "workspace", dir: filepath.Join(xdg, "apimgr", workspacesDirName, "work"), want: true},
+		{name: "directory of --config", dir: filepath.Join(t.TempDir(), "shared"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.MkdirAll(tt.dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			os.Chmod(tt.dir, 0777)
+			cm := &Manager{configPath: filepath.Join(tt.dir, "config.json"), stateDir: t.TempDir()}
+
+			issues, err := cm.CheckPermissions()
+			if err != nil {
+				t.Fatalf("CheckPermissions() unexpected error: %v", err)
+			}
+			got := false
+			for _, issue := range issues {
+				got = got || issue.Path == tt.dir
+			}
+			if got != tt.want {
+				t.Errorf("CheckPermissions() = %v, want %s reported: %v", issues, tt.dir, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package config
+
+// unixPermissions reports whether file permission bits are enforced
+const unixPermissions = true
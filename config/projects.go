@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// projectSettingsPath returns the Claude Code settings file of a project
+func projectSettingsPath(project models.Project) string {
+	name := "settings.json"
+	if project.Local {
+		name = "settings.local.json"
+	}
+	return filepath.Join(expandTargetPath(project.Root), ".claude", name)
+}
+
+// FindProjectRoot returns the project directory holding dir: the nearest
+// one with a .claude or .git entry, dir itself when there is none
+func FindProjectRoot(dir string) string {
+	for current := dir; ; {
+		for _, marker := range []string{".claude", ".git"} {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// Projects returns the projects whose settings are synced on switch
+func (cm *Manager) Projects() ([]models.Project, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return configFile.Projects, nil
+}
+
+// EnableProject syncs the Claude Code settings of the project at root on
+// switch, creating an empty settings file when there is none. It returns
+// the settings file.
+func (cm *Manager) EnableProject(root string, local bool) (string, error) {
+	root, err := filepath.Abs(expandTargetPath(root))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", exitcode.New(exitcode.NotFound, "project directory '%s' does not exist", root)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	project := models.Project{Root: root, Local: local}
+	err = cm.update(func(configFile *models.File) error {
+		i := slices.IndexFunc(configFile.Projects, func(p models.Project) bool { return sameProject(p.Root, root) })
+		if i < 0 {
+			configFile.Projects = append(configFile.Projects, project)
+		} else {
+			configFile.Projects[i] = project
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	path := projectSettingsPath(project)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+	return path, nil
+}
+
+// DisableProject stops syncing the project at root and removes the
+// configuration it was last synced from its settings file
+func (cm *Manager) DisableProject(root string) error {
+	root, err := filepath.Abs(expandTargetPath(root))
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var removed models.Project
+	err = cm.update(func(configFile *models.File) error {
+		i := slices.IndexFunc(configFile.Projects, func(p models.Project) bool { return sameProject(p.Root, root) })
+		if i < 0 {
+			return exitcode.New(exitcode.NotFound, "project '%s' is not enabled", root)
+		}
+		removed = configFile.Projects[i]
+		configFile.Projects = slices.Delete(configFile.Projects, i, i+1)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return clearSettingsFile(projectSettingsPath(removed), cm.KeyHelperPath())
+}
+
+// sameProject reports whether an enabled project root is the directory root
+func sameProject(projectRoot, root string) bool {
+	return filepath.Clean(expandTargetPath(projectRoot)) == root
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "app")
+	nested := filepath.Join(project, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(project, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindProjectRoot(nested); got != project {
+		t.Errorf("FindProjectRoot(%q) = %q, want %q", nested, got, project)
+	}
+	if got := FindProjectRoot(root); got != root {
+		t.Errorf("FindProjectRoot(%q) = %q, want the directory itself", root, got)
+	}
+}
+
+func TestEnableProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	cm := setupTestConfig(t)
+	if err := cm.saveConfigFile(&models.File{Configs: []models.APIConfig{{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"}}}); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+	project := t.TempDir()
+
+	path, err := cm.EnableProject(project, true)
+	if err != nil {
+		t.Fatalf("EnableProject() unexpected error: %v", err)
+	}
+	if want := filepath.Join(project, ".claude", "settings.local.json"); path != want {
+		t.Errorf("EnableProject() = %q, want %q", path, want)
+	}
+	// Enabling again updates the project rather than adding it twice
+	if path, err = cm.EnableProject(project, false); err != nil {
+		t.Fatalf("EnableProject() unexpected error: %v", err)
+	}
+	if projects, _ := cm.Projects(); len(projects) != 1 || projects[0].Local {
+		t.Errorf("Projects() = %+v, want the project once, not local", projects)
+	}
+
+	relay, _ := cm.Get("relay")
+	if err := cm.syncClaudeSettings(relay); err != nil {
+		t.Fatalf("syncClaudeSettings() unexpected error: %v", err)
+	}
+	// The committed settings.json gets the config without its key
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "https://relay.example.com") || strings.Contains(string(data), "sk-relay") {
+		t.Errorf("%s was not synced without the key: %s", path, data)
+	}
+	if mismatches, err := cm.Verify(); err != nil || slices.ContainsFunc(mismatches, func(m Mismatch) bool { return m.Path == path }) {
+		t.Errorf("Verify() = %+v, %v, want %s consistent", mismatches, err, path)
+	}
+
+	if err := cm.DisableProject(project); err != nil {
+		t.Fatalf("DisableProject() unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "https://relay.example.com") {
+		t.Errorf("%s was not cleared: %s", path, data)
+	}
+	if projects, _ := cm.Projects(); len(projects) != 0 {
+		t.Errorf("Projects() = %+v, want none", projects)
+	}
+	if err := cm.DisableProject(project); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("DisableProject() of a disabled project error = %v, want not enabled", err)
+	}
+
+	if _, err := cm.EnableProject(filepath.Join(project, "missing"), false); err == nil {
+		t.Error("EnableProject() of a missing directory expected error, got nil")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"os"
+
+	"apimgr/internal/exitcode"
+)
+
+// ReadOnlyEnvVar enables read-only mode like --read-only when set to a
+// non-empty value
+const ReadOnlyEnvVar = "APIMGR_READONLY"
+
+// ErrReadOnly is returned by changes attempted in read-only mode
+var ErrReadOnly = errors.New("the configuration cannot be changed in read-only mode (--read-only or " + ReadOnlyEnvVar + ")")
+
+// readOnlyOverride is set by the --read-only flag
+var readOnlyOverride bool
+
+// SetReadOnly enables read-only mode, in addition to APIMGR_READONLY
+func SetReadOnly(readOnly bool) {
+	readOnlyOverride = readOnly
+}
+
+// ReadOnly reports whether changes to the config file and the synced
+// settings are refused, for shared or demo machines
+func ReadOnly() bool {
+	return readOnlyOverride || os.Getenv(ReadOnlyEnvVar) != ""
+}
+
+// checkWritable returns ErrReadOnly in read-only mode
+func checkWritable() error {
+	if ReadOnly() {
+		return exitcode.Wrap(exitcode.Usage, ErrReadOnly)
+	}
+	return nil
+}
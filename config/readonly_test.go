@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestReadOnly(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		flag bool
+		env  string
+	}{
+		{name: "flag", flag: true},
+		{name: "environment", env: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetReadOnly(tt.flag)
+			defer SetReadOnly(false)
+			t.Setenv(ReadOnlyEnvVar, tt.env)
+
+			err := cm.Add(models.APIConfig{Alias: "other", APIKey: "sk-other"})
+			if !errors.Is(err, ErrReadOnly) || exitcode.Of(err) != exitcode.Usage {
+				t.Errorf("Add() error = %v, want ErrReadOnly", err)
+			}
+			if err := cm.SetActive("work"); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("SetActive() error = %v, want ErrReadOnly", err)
+			}
+			if err := cm.SyncClaudeSettingsOnly(&models.APIConfig{Alias: "work", APIKey: "sk-work"}); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("SyncClaudeSettingsOnly() error = %v, want ErrReadOnly", err)
+			}
+			// No config is active, so the settings would be cleared
+			if err := cm.RestoreClaudeToGlobal(); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("RestoreClaudeToGlobal() error = %v, want ErrReadOnly", err)
+			}
+			if configs, err := cm.List(); err != nil || len(configs) != 1 {
+				t.Errorf("List() = %v, %v, want the one config", configs, err)
+			}
+		})
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "other", APIKey: "sk-other"}); err != nil {
+		t.Errorf("Add() after read-only mode unexpected error: %v", err)
+	}
+}
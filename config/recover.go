@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+	"apimgr/internal/notice"
+)
+
+// configBackupRetention is the number of config file backups kept in the
+// backup directory
+const configBackupRetention = 5
+
+// corruptSuffix is appended, with a timestamp, to the copy of a config file
+// that could not be parsed
+const corruptSuffix = ".corrupt-"
+
+// configBackups returns the backups of the config file, kept with those of
+// the synced settings files
+func (cm *Manager) configBackups() *storage.BackupManager {
+	backups := storage.NewBackupManager(configBackupRetention)
+	backups.Dir = cm.BackupDir()
+	return backups
+}
+
+// backupConfig keeps a copy of the config file just written, so a file
+// corrupted later can be recovered
+func (cm *Manager) backupConfig() {
+	backups := cm.configBackups()
+	if _, err := backups.CreateBackup(cm.configPath); err != nil {
+		slog.Warn("failed to back up config file", "path", cm.configPath, "error", err)
+		return
+	}
+	if err := backups.CleanupOldBackups(cm.configPath); err != nil {
+		slog.Warn("failed to remove old config backups", "path", cm.configPath, "error", err)
+	}
+}
+
+// recoverConfigFile handles a config file that could not be parsed: the
+// broken content is copied next to it with a timestamp, and the file is
+// replaced with the newest backup that parses. Without one the file is left
+// as it is and parseErr is returned.
+func (cm *Manager) recoverConfigFile(data []byte, parseErr error) (*models.File, error) {
+	format := storage.FormatFromPath(cm.configPath)
+	var quarantine, restored string
+	var configFile *models.File
+	written, err := cm.writeConfigData(etagOf(data), func(previous []byte) ([]byte, error) {
+		backup, file := cm.newestValidBackup(format)
+		if file == nil {
+			return nil, fmt.Errorf("%w (no backup to recover from, fix it with 'apimgr edit')", parseErr)
+		}
+		quarantine = cm.configPath + corruptSuffix + time.Now().Format("20060102150405")
+		if err := os.WriteFile(quarantine, previous, 0600); err != nil {
+			return nil, fmt.Errorf("%w, and it could not be moved aside: %v", parseErr, err)
+		}
+		restored, configFile = backup, file
+		return os.ReadFile(backup)
+	})
+	if errors.Is(err, ErrConflict) {
+		// Another process rewrote the file in the meantime
+		return cm.loadConfigFile()
+	}
+	if err != nil {
+		return nil, err
+	}
+	configFile.ETag = etagOf(written)
+
+	notice.Printf("Warning: %s could not be parsed (%v)\n", cm.configPath, parseErr)
+	notice.Printf("  Restored the backup of %s, the broken file was moved to %s\n", backupTime(restored), quarantine)
+	notice.Printf("  Compare them with: diff %s %s\n", quarantine, cm.configPath)
+	slog.Warn("recovered corrupted config file", "path", cm.configPath, "backup", restored, "quarantine", quarantine, "error", parseErr)
+	return configFile, nil
+}
+
+// newestValidBackup returns the newest config file backup that parses, or
+// a nil file when there is none
+func (cm *Manager) newestValidBackup(format storage.Format) (string, *models.File) {
+	backups, _ := cm.configBackups().ListBackups(cm.configPath)
+	for _, backup := range slices.Backward(backups) {
+		data, err := os.ReadFile(backup)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		if file, err := parseConfigData(format, data); err == nil {
+			return backup, file
+		}
+	}
+	return "", nil
+}
+
+// backupTime returns when a backup was made, from its modification time
+func backupTime(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return info.ModTime().Format("2006-01-02 15:04:05")
+}
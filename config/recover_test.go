@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestLoadConfigFileRecoversFromBackup(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.Add(models.APIConfig{Alias: "a", APIKey: "sk-a"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	broken := []byte(`{"configs": [`)
+	if err := os.WriteFile(cm.configPath, broken, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh manager, as the next apimgr run would use
+	cm = &Manager{configPath: cm.configPath}
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		t.Fatalf("loadConfigFile() unexpected error: %v", err)
+	}
+	if len(configFile.Configs) != 1 || configFile.Configs[0].Alias != "a" {
+		t.Errorf("loadConfigFile() configs = %+v, want the backed up one", configFile.Configs)
+	}
+
+	quarantined, _ := filepath.Glob(cm.configPath + corruptSuffix + "*")
+	if len(quarantined) != 1 {
+		t.Fatalf("quarantined files = %v, want one", quarantined)
+	}
+	if data, _ := os.ReadFile(quarantined[0]); string(data) != string(broken) {
+		t.Errorf("quarantined content = %q, want %q", data, broken)
+	}
+	if _, err := cm.Get("a"); err != nil {
+		t.Errorf("Get() after recovery unexpected error: %v", err)
+	}
+	// The recovered file can be saved without a conflict
+	if err := cm.Add(models.APIConfig{Alias: "b", APIKey: "sk-b"}); err != nil {
+		t.Errorf("Add() after recovery unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfigFileWithoutBackup(t *testing.T) {
+	cm := setupTestConfig(t)
+	broken := []byte(`{bad`)
+	if err := os.WriteFile(cm.configPath, broken, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := cm.loadConfigFile()
+	if err == nil || !strings.Contains(err.Error(), "no backup to recover from") {
+		t.Fatalf("loadConfigFile() error = %v, want one saying there is no backup", err)
+	}
+	if data, _ := os.ReadFile(cm.configPath); string(data) != string(broken) {
+		t.Errorf("config file = %q, want it left as it was", data)
+	}
+	if quarantined, _ := filepath.Glob(cm.configPath + corruptSuffix + "*"); len(quarantined) != 0 {
+		t.Errorf("quarantined files = %v, want none", quarantined)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/providers"
+)
+
+// regionBaseURL returns the base URL of region on the regional endpoint of
+// a config with the given provider and base URL
+func regionBaseURL(provider, baseURL, region string) (string, error) {
+	endpoint, ok := providers.EndpointOf(provider, baseURL)
+	if !ok {
+		var names []string
+		for _, e := range providers.Endpoints() {
+			names = append(names, e.Name)
+		}
+		return "", exitcode.New(exitcode.Validation, "base URL '%s' has no regional hosts (known endpoints: %s)", baseURL, strings.Join(names, ", "))
+	}
+	url, ok := endpoint.Regions[region]
+	if !ok {
+		return "", exitcode.New(exitcode.Validation, "unknown region '%s' for %s (available: %s)", region, endpoint.Name, strings.Join(endpoint.RegionNames(), ", "))
+	}
+	return url, nil
+}
+
+// setRegion points the config at index i at region, rewriting its base URL.
+// An empty region only clears the field.
+func setRegion(configFile *models.File, i int, region string) error {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		configFile.Configs[i].Region = ""
+		return nil
+	}
+	resolved, err := resolveConfig(configFile.Configs, configFile.Configs[i].Alias)
+	if err != nil {
+		return err
+	}
+	baseURL, err := regionBaseURL(resolved.Provider, resolved.BaseURL, region)
+	if err != nil {
+		return err
+	}
+	configFile.Configs[i].Region = region
+	configFile.Configs[i].BaseURL = baseURL
+	return nil
+}
+
+// regionOf returns the region of the regional endpoint a base URL points
+// to, or an empty string
+func regionOf(provider, baseURL string) string {
+	endpoint, ok := providers.EndpointOf(provider, baseURL)
+	if !ok {
+		return ""
+	}
+	return endpoint.RegionOf(baseURL)
+}
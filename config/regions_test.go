@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestRegion(t *testing.T) {
+	cm := setupTestConfig(t)
+	for _, cfg := range []models.APIConfig{
+		{Alias: "gpt", Provider: "openai", APIKey: "sk-gpt", Region: "eu"},
+		{Alias: "kimi", APIKey: "sk-kimi", BaseURL: "https://api.moonshot.cn/anthropic"},
+		{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"},
+	} {
+		if err := cm.Add(cfg); err != nil {
+			t.Fatalf("Add(%s) unexpected error: %v", cfg.Alias, err)
+		}
+	}
+	if gpt, _ := cm.Get("gpt"); gpt.BaseURL != "https://eu.api.openai.com/v1" {
+		t.Errorf("Add() with region eu base URL = %q, want the EU host", gpt.BaseURL)
+	}
+
+	tests := []struct {
+		name        string
+		alias       string
+		updates     map[string]string
+		wantBaseURL string
+		wantRegion  string
+		wantErr     string
+	}{
+		{name: "switch region", alias: "kimi", updates: map[string]string{"region": "global"}, wantBaseURL: "https://api.moonshot.ai/anthropic", wantRegion: "global"},
+		{name: "unknown region", alias: "kimi", updates: map[string]string{"region": "eu"}, wantErr: "available: cn, global"},
+		{name: "base URL of another region", alias: "kimi", updates: map[string]string{"base_url": "https://api.moonshot.cn/anthropic"}, wantBaseURL: "https://api.moonshot.cn/anthropic", wantRegion: "cn"},
+		{name: "base URL off the endpoint", alias: "gpt", updates: map[string]string{"base_url": "https://relay.example.com/v1"}, wantBaseURL: "https://relay.example.com/v1"},
+		{name: "not regional", alias: "relay", updates: map[string]string{"region": "cn"}, wantErr: "no regional hosts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cm.UpdatePartial(tt.alias, tt.updates)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("UpdatePartial() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdatePartial() unexpected error: %v", err)
+			}
+			cfg, _ := cm.Get(tt.alias)
+			if cfg.BaseURL != tt.wantBaseURL || cfg.Region != tt.wantRegion {
+				t.Errorf("base URL = %q, region %q, want %q, %q", cfg.BaseURL, cfg.Region, tt.wantBaseURL, tt.wantRegion)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var (
+	bootOnce sync.Once
+	bootID   string
+)
+
+// BootID identifies the current boot of the machine, so markers written
+// before a reboot are not mistaken for shells that reuse their PIDs. It is
+// empty where it cannot be told, such as on Windows.
+func BootID() string {
+	bootOnce.Do(func() {
+		if data, err := os.ReadFile("/proc/sys/kernel/random/boot_id"); err == nil {
+			bootID = strings.TrimSpace(string(data))
+			return
+		}
+		// macOS and the BSDs report the boot time instead
+		if out, err := exec.Command("sysctl", "-n", "kern.boottime").Output(); err == nil {
+			bootID = strings.TrimSpace(string(out))
+		}
+	})
+	return bootID
+}
+
+// fromOtherBoot reports whether marker was written before the last reboot
+func fromOtherBoot(marker *SessionMarker) bool {
+	current := BootID()
+	return marker != nil && marker.BootID != "" && current != "" && marker.BootID != current
+}
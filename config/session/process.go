@@ -0,0 +1,120 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DepthEnvVar sets how many parent processes apimgr walks up to reach the
+// shell owning a local session, instead of skipping subshells automatically
+const DepthEnvVar = "APIMGR_SESSION_DEPTH"
+
+// maxWalk bounds the parent walk
+const maxWalk = 16
+
+// processInfoFunc returns a process's parent PID and command line
+type processInfoFunc func(pid int) (ppid int, command string, err error)
+
+// ShellPID returns the PID of the shell that owns a local session started by
+// this process. 'eval "$(apimgr switch -l x)"' runs apimgr in a command
+// substitution subshell, so forks with the same command line as their
+// parent are skipped to reach the interactive shell.
+func ShellPID() int {
+	depth, _ := strconv.Atoi(os.Getenv(DepthEnvVar))
+	return shellPID(os.Getppid(), depth, processInfo)
+}
+
+// ShellName returns the program name of the shell ShellPID finds, such as
+// "zsh" or "fish", empty when it cannot be told
+func ShellName() string {
+	_, command, err := processInfo(ShellPID())
+	if err != nil {
+		return ""
+	}
+	fields := strings.FieldsFunc(command, func(r rune) bool { return r == 0 || r == ' ' })
+	if len(fields) == 0 {
+		return ""
+	}
+	// Login shells are started as -zsh
+	return strings.TrimPrefix(filepath.Base(fields[0]), "-")
+}
+
+// shellPID walks up from start: exactly depth-1 levels when depth is set,
+// otherwise while the process is a fork of its parent
+func shellPID(start, depth int, info processInfoFunc) int {
+	pid := start
+	if depth > 0 {
+		for i := 1; i < depth && i < maxWalk; i++ {
+			ppid, _, err := info(pid)
+			if err != nil || ppid <= 1 {
+				break
+			}
+			pid = ppid
+		}
+		return pid
+	}
+
+	for i := 0; i < maxWalk; i++ {
+		ppid, command, err := info(pid)
+		if err != nil || ppid <= 1 {
+			break
+		}
+		_, parentCommand, err := info(ppid)
+		if err != nil || parentCommand != command {
+			break
+		}
+		pid = ppid
+	}
+	return pid
+}
+
+// OwnsSession reports whether caller, the process running the EXIT trap,
+// may end the session of shell pid. Subshells inherit the trap in some
+// shells; their exit must not end the session of a shell that still runs.
+func OwnsSession(pid string, caller int) bool {
+	shell, err := strconv.Atoi(pid)
+	if err != nil || shell == caller {
+		return true
+	}
+	return !IsProcessRunning(shell)
+}
+
+// processInfo reads a process's parent and command line from /proc, or
+// from ps where /proc is not available
+func processInfo(pid int) (int, string, error) {
+	if stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		// The command name is parenthesized and may contain spaces
+		end := strings.LastIndexByte(string(stat), ')')
+		fields := strings.Fields(string(stat[end+1:]))
+		if end < 0 || len(fields) < 2 {
+			return 0, "", fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, "", err
+		}
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil {
+			return 0, "", err
+		}
+		return ppid, string(cmdline), nil
+	}
+
+	out, err := exec.Command("ps", "-o", "ppid=,command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, "", err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return 0, "", fmt.Errorf("process %d not found", pid)
+	}
+	ppid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, "", err
+	}
+	return ppid, strings.Join(fields[1:], " "), nil
+}
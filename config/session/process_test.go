@@ -0,0 +1,86 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestShellPID(t *testing.T) {
+	// 100 login shell -> 200 interactive bash -> 300 and 400 subshell forks
+	procs := map[int]struct {
+		ppid    int
+		command string
+	}{
+		100: {1, "-zsh"},
+		200: {100, "bash"},
+		300: {200, "bash"},
+		400: {300, "bash"},
+	}
+	info := func(pid int) (int, string, error) {
+		p, ok := procs[pid]
+		if !ok {
+			return 0, "", fmt.Errorf("no process %d", pid)
+		}
+		return p.ppid, p.command, nil
+	}
+
+	tests := []struct {
+		name  string
+		start int
+		depth int
+		want  int
+	}{
+		{"direct parent is the shell", 200, 0, 200},
+		{"skips subshell forks", 400, 0, 200},
+		{"stops below init", 100, 0, 100},
+		{"explicit depth 1", 400, 1, 400},
+		{"explicit depth 3", 400, 3, 200},
+		{"depth past the root", 400, 10, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellPID(tt.start, tt.depth, info); got != tt.want {
+				t.Errorf("shellPID(%d, %d) = %d, want %d", tt.start, tt.depth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnsSession(t *testing.T) {
+	self := os.Getpid()
+	tests := []struct {
+		name   string
+		pid    string
+		caller int
+		want   bool
+	}{
+		{"shell exits", strconv.Itoa(self), self, true},
+		{"subshell of a running shell", strconv.Itoa(self), self + 1, false},
+		{"shell already gone", "999999999", self, true},
+		{"invalid pid", "abc", self, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OwnsSession(tt.pid, tt.caller); got != tt.want {
+				t.Errorf("OwnsSession(%s, %d) = %v, want %v", tt.pid, tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessInfo(t *testing.T) {
+	ppid, command, err := processInfo(os.Getpid())
+	if err != nil {
+		t.Skipf("process info not available: %v", err)
+	}
+	if ppid != os.Getppid() {
+		t.Errorf("processInfo() ppid = %d, want %d", ppid, os.Getppid())
+	}
+	if command == "" {
+		t.Error("processInfo() returned an empty command line")
+	}
+}
@@ -16,14 +16,16 @@ type SessionMarker struct {
 	PID       string    `json:"pid"`
 	Alias     string    `json:"alias"`
 	Timestamp time.Time `json:"timestamp"`
+	BootID    string    `json:"boot_id,omitempty"` // See BootID
 }
 
 // CreateSessionMarker creates a session marker file for local mode
-func CreateSessionMarker(configPath string, pid string, alias string) error {
+func CreateSessionMarker(sessionDir string, pid string, alias string) error {
 	marker := SessionMarker{
 		PID:       pid,
 		Alias:     alias,
 		Timestamp: time.Now(),
+		BootID:    BootID(),
 	}
 
 	data, err := json.MarshalIndent(marker, "", "  ")
@@ -31,7 +33,10 @@ func CreateSessionMarker(configPath string, pid string, alias string) error {
 		return fmt.Errorf("failed to serialize session marker: %v", err)
 	}
 
-	markerPath := filepath.Join(filepath.Dir(configPath), fmt.Sprintf("session-%s", pid))
+	if err := os.MkdirAll(sessionDir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %v", err)
+	}
+	markerPath := filepath.Join(sessionDir, fmt.Sprintf("session-%s", pid))
 	if err := os.WriteFile(markerPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write session marker: %v", err)
 	}
@@ -39,9 +44,38 @@ func CreateSessionMarker(configPath string, pid string, alias string) error {
 	return nil
 }
 
+// ReadSessionMarker reads the session marker of a shell, returning nil when
+// the shell has no local session. A marker left from before a reboot belongs
+// to an earlier shell with the same PID and is ignored.
+func ReadSessionMarker(sessionDir string, pid string) (*SessionMarker, error) {
+	marker, err := readMarkerFile(filepath.Join(sessionDir, fmt.Sprintf("session-%s", pid)))
+	if err != nil || fromOtherBoot(marker) {
+		return nil, err
+	}
+	return marker, nil
+}
+
+// readMarkerFile reads a session marker file, returning nil when it does not
+// exist
+func readMarkerFile(path string) (*SessionMarker, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session marker: %v", err)
+	}
+
+	var marker SessionMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("invalid session marker: %v", err)
+	}
+	return &marker, nil
+}
+
 // CleanupSession removes a session marker file
-func CleanupSession(configPath string, pid string) error {
-	markerPath := filepath.Join(filepath.Dir(configPath), fmt.Sprintf("session-%s", pid))
+func CleanupSession(sessionDir string, pid string) error {
+	markerPath := filepath.Join(sessionDir, fmt.Sprintf("session-%s", pid))
 	err := os.Remove(markerPath)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove session marker: %v", err)
@@ -50,12 +84,16 @@ func CleanupSession(configPath string, pid string) error {
 }
 
 // HasActiveLocalSessions checks if there are any active local sessions
-// It also cleans up stale session files (PIDs that no longer exist)
-func HasActiveLocalSessions(configPath string) (bool, error) {
-	configDir := filepath.Dir(configPath)
-	entries, err := os.ReadDir(configDir)
+// It also cleans up stale session files (PIDs that no longer exist, or
+// markers written before a reboot)
+func HasActiveLocalSessions(sessionDir string) (bool, error) {
+	entries, err := os.ReadDir(sessionDir)
+	if os.IsNotExist(err) {
+		// Created with the first marker
+		return false, nil
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to read config directory: %v", err)
+		return false, fmt.Errorf("failed to read session directory: %v", err)
 	}
 
 	hasActive := false
@@ -73,24 +111,66 @@ func HasActiveLocalSessions(configPath string) (bool, error) {
 		pid, err := strconv.Atoi(pidStr)
 		if err != nil {
 			// Invalid session file name, clean it up
-			os.Remove(filepath.Join(configDir, name))
+			os.Remove(filepath.Join(sessionDir, name))
 			continue
 		}
 
 		// Check if process is still running
-		if isProcessRunning(pid) {
+		if markerRunning(filepath.Join(sessionDir, name), pid) {
 			hasActive = true
 		} else {
 			// Clean up stale session file
-			os.Remove(filepath.Join(configDir, name))
+			os.Remove(filepath.Join(sessionDir, name))
 		}
 	}
 
 	return hasActive, nil
 }
 
-// isProcessRunning checks if a process with the given PID is still running
-func isProcessRunning(pid int) bool {
+// markerRunning reports whether the shell of the marker at path still runs:
+// the marker is from this boot and its PID is running
+func markerRunning(path string, pid int) bool {
+	marker, _ := readMarkerFile(path)
+	return !fromOtherBoot(marker) && IsProcessRunning(pid)
+}
+
+// MarkerFile is a session marker file found in the session directory
+type MarkerFile struct {
+	Path    string
+	PID     string
+	Marker  *SessionMarker // nil when the file cannot be parsed
+	Running bool           // The shell that created it is still running
+}
+
+// ListMarkers returns the session marker files in the session directory,
+// unlike HasActiveLocalSessions leaving stale ones in place
+func ListMarkers(sessionDir string) ([]MarkerFile, error) {
+	entries, err := os.ReadDir(sessionDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %v", err)
+	}
+
+	var markers []MarkerFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "session-") {
+			continue
+		}
+		file := MarkerFile{Path: filepath.Join(sessionDir, name), PID: strings.TrimPrefix(name, "session-")}
+		file.Marker, _ = readMarkerFile(file.Path)
+		if pid, err := strconv.Atoi(file.PID); err == nil {
+			file.Running = !fromOtherBoot(file.Marker) && IsProcessRunning(pid)
+		}
+		markers = append(markers, file)
+	}
+	return markers, nil
+}
+
+// IsProcessRunning checks if a process with the given PID is still running
+func IsProcessRunning(pid int) bool {
 	process, err := os.FindProcess(pid)
 	if err != nil {
 		return false
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestMarkerFromOtherBoot(t *testing.T) {
+	if BootID() == "" {
+		t.Skip("boot ID not available")
+	}
+	dir := t.TempDir()
+	pid := strconv.Itoa(os.Getpid())
+
+	// A marker written before a reboot by a shell whose PID is reused now
+	data, _ := json.Marshal(SessionMarker{PID: pid, Alias: "old", BootID: "earlier-boot"})
+	if err := os.WriteFile(filepath.Join(dir, "session-"+pid), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if marker, err := ReadSessionMarker(dir, pid); err != nil || marker != nil {
+		t.Errorf("ReadSessionMarker() = %v, %v, want no marker from another boot", marker, err)
+	}
+	markers, err := ListMarkers(dir)
+	if err != nil || len(markers) != 1 || markers[0].Running {
+		t.Errorf("ListMarkers() = %+v, %v, want one marker that is not running", markers, err)
+	}
+	if active, err := HasActiveLocalSessions(dir); err != nil || active {
+		t.Errorf("HasActiveLocalSessions() = %v, %v, want false", active, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "session-"+pid)); !os.IsNotExist(err) {
+		t.Error("HasActiveLocalSessions() should remove the marker from another boot")
+	}
+
+	// Markers of this boot belong to running shells
+	if err := CreateSessionMarker(dir, pid, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if marker, err := ReadSessionMarker(dir, pid); err != nil || marker == nil || marker.Alias != "new" {
+		t.Errorf("ReadSessionMarker() = %v, %v, want the new marker", marker, err)
+	}
+	if active, err := HasActiveLocalSessions(dir); err != nil || !active {
+		t.Errorf("HasActiveLocalSessions() = %v, %v, want true", active, err)
+	}
+}
@@ -74,7 +74,7 @@ func TestPropertySessionMarkerCreation(t *testing.T) {
 		beforeTime := time.Now().Add(-time.Second)
 
 		// Create session marker
-		err := session.CreateSessionMarker(cm.configPath, pid, alias)
+		err := session.CreateSessionMarker(cm.StateDir(), pid, alias)
 		if err != nil {
 			t.Logf("CreateSessionMarker failed: %v", err)
 			return false
@@ -135,7 +135,7 @@ func TestCleanupSession(t *testing.T) {
 
 	// Create a session marker
 	pid := "12345"
-	err := session.CreateSessionMarker(cm.configPath, pid, "test-alias")
+	err := session.CreateSessionMarker(cm.StateDir(), pid, "test-alias")
 	if err != nil {
 		t.Fatalf("Failed to create session marker: %v", err)
 	}
@@ -147,7 +147,7 @@ func TestCleanupSession(t *testing.T) {
 	}
 
 	// Clean up the session
-	err = session.CleanupSession(cm.configPath, pid)
+	err = session.CleanupSession(cm.StateDir(), pid)
 	if err != nil {
 		t.Fatalf("Failed to cleanup session: %v", err)
 	}
@@ -158,7 +158,7 @@ func TestCleanupSession(t *testing.T) {
 	}
 
 	// Cleanup of non-existent session should not error
-	err = session.CleanupSession(cm.configPath, "99999")
+	err = session.CleanupSession(cm.StateDir(), "99999")
 	if err != nil {
 		t.Errorf("Cleanup of non-existent session should not error: %v", err)
 	}
@@ -169,7 +169,7 @@ func TestHasActiveLocalSessions(t *testing.T) {
 	cm, tempDir := setupTestSession(t)
 
 	// Initially no sessions
-	hasActive, err := session.HasActiveLocalSessions(cm.configPath)
+	hasActive, err := session.HasActiveLocalSessions(cm.StateDir())
 	if err != nil {
 		t.Fatalf("HasActiveLocalSessions failed: %v", err)
 	}
@@ -179,13 +179,13 @@ func TestHasActiveLocalSessions(t *testing.T) {
 
 	// Create a session marker with current process PID (which is running)
 	currentPID := strconv.Itoa(os.Getpid())
-	err = session.CreateSessionMarker(cm.configPath, currentPID, "test-alias")
+	err = session.CreateSessionMarker(cm.StateDir(), currentPID, "test-alias")
 	if err != nil {
 		t.Fatalf("Failed to create session marker: %v", err)
 	}
 
 	// Now should have active session
-	hasActive, err = session.HasActiveLocalSessions(cm.configPath)
+	hasActive, err = session.HasActiveLocalSessions(cm.StateDir())
 	if err != nil {
 		t.Fatalf("HasActiveLocalSessions failed: %v", err)
 	}
@@ -201,7 +201,7 @@ func TestHasActiveLocalSessions(t *testing.T) {
 	os.WriteFile(staleMarkerPath, data, 0600)
 
 	// HasActiveLocalSessions should clean up stale session
-	hasActive, err = session.HasActiveLocalSessions(cm.configPath)
+	hasActive, err = session.HasActiveLocalSessions(cm.StateDir())
 	if err != nil {
 		t.Fatalf("HasActiveLocalSessions failed: %v", err)
 	}
@@ -215,7 +215,7 @@ func TestHasActiveLocalSessions(t *testing.T) {
 	}
 
 	// Clean up current session
-	session.CleanupSession(cm.configPath, currentPID)
+	session.CleanupSession(cm.StateDir(), currentPID)
 }
 
 // Feature: switch-local-mode-fix, Property 4: Local mode updates Claude Code settings
@@ -649,7 +649,7 @@ func TestPropertyStaleSessionCleanup(t *testing.T) {
 		}
 
 		// Call HasActiveLocalSessions which should clean up stale sessions
-		hasActive, err := session.HasActiveLocalSessions(cm.configPath)
+		hasActive, err := session.HasActiveLocalSessions(cm.StateDir())
 		if err != nil {
 			t.Logf("HasActiveLocalSessions failed: %v", err)
 			return false
@@ -690,7 +690,7 @@ func TestPropertyMixedSessionCleanup(t *testing.T) {
 
 	// Create an active session marker with current process PID (which is running)
 	currentPID := strconv.Itoa(os.Getpid())
-	err := session.CreateSessionMarker(cm.configPath, currentPID, "active-alias")
+	err := session.CreateSessionMarker(cm.StateDir(), currentPID, "active-alias")
 	if err != nil {
 		t.Fatalf("Failed to create active session marker: %v", err)
 	}
@@ -711,7 +711,7 @@ func TestPropertyMixedSessionCleanup(t *testing.T) {
 	}
 
 	// Call HasActiveLocalSessions
-	hasActive, err := session.HasActiveLocalSessions(cm.configPath)
+	hasActive, err := session.HasActiveLocalSessions(cm.StateDir())
 	if err != nil {
 		t.Fatalf("HasActiveLocalSessions failed: %v", err)
 	}
@@ -736,7 +736,7 @@ func TestPropertyMixedSessionCleanup(t *testing.T) {
 	}
 
 	// Clean up active session
-	session.CleanupSession(cm.configPath, currentPID)
+	session.CleanupSession(cm.StateDir(), currentPID)
 }
 
 // Feature: switch-local-mode-fix, Property 14: Cleanup-session removes marker
@@ -767,7 +767,7 @@ func TestPropertyCleanupSessionRemovesMarker(t *testing.T) {
 		cm := &Manager{configPath: configPath}
 
 		// Create session marker first
-		err := session.CreateSessionMarker(cm.configPath, pid, alias)
+		err := session.CreateSessionMarker(cm.StateDir(), pid, alias)
 		if err != nil {
 			t.Logf("CreateSessionMarker failed: %v", err)
 			return false
@@ -781,7 +781,7 @@ func TestPropertyCleanupSessionRemovesMarker(t *testing.T) {
 		}
 
 		// Cleanup the session
-		err = session.CleanupSession(cm.configPath, pid)
+		err = session.CleanupSession(cm.StateDir(), pid)
 		if err != nil {
 			t.Logf("CleanupSession failed: %v", err)
 			return false
@@ -831,7 +831,7 @@ func TestPropertyCleanupSessionNonExistent(t *testing.T) {
 		}
 
 		// Cleanup the session (should not error even though marker doesn't exist)
-		err := session.CleanupSession(cm.configPath, pid)
+		err := session.CleanupSession(cm.StateDir(), pid)
 		if err != nil {
 			t.Logf("CleanupSession should not error for non-existent marker: %v", err)
 			return false
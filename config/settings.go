@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+	"apimgr/internal/providers"
+	"apimgr/internal/utils"
+)
+
+// Sync targets accepted in defaults.sync_targets
+const (
+	SyncTargetClaude  = "claude"  // Claude Code user settings.json, see ClaudeSettingsPath
+	SyncTargetNone    = "none"    // Sync nothing on switch
+	SyncTargetProject = "project" // Settings of the projects enabled with EnableProject
+)
+
+// Key modes accepted in defaults.key_mode
+const (
+	KeyModeEnv    = "env"    // Key written into the settings env
+	KeyModeHelper = "helper" // Key printed by an apiKeyHelper script
+	KeyModeShell  = "shell"  // Key left to the shell environment
+)
+
+// setting is a config file value editable with apimgr config set/get
+type setting struct {
+	get   func(d *models.Defaults) string
+	set   func(d *models.Defaults, value string) error
+	check func(configFile *models.File) error // Optional, validates the value against the rest of the file
+}
+
+// settings maps the keys accepted by GetSetting and SetSetting
+var settings = map[string]setting{
+	"defaults.provider": {
+		get: func(d *models.Defaults) string { return d.Provider },
+		set: func(d *models.Defaults, value string) error {
+			if value != "" {
+				if _, err := providers.Get(value); err != nil {
+					return fmt.Errorf("unknown API provider: %s (available: %s)", value, strings.Join(providers.List(), ", "))
+				}
+			}
+			d.Provider = value
+			return nil
+		},
+	},
+	"defaults.model": {
+		get: func(d *models.Defaults) string { return d.Model },
+		set: func(d *models.Defaults, value string) error {
+			d.Model = value
+			return nil
+		},
+	},
+	"defaults.sync_targets": {
+		get: func(d *models.Defaults) string { return strings.Join(d.SyncTargets, ",") },
+		set: func(d *models.Defaults, value string) error {
+			var targets []string
+			for _, t := range strings.Split(value, ",") {
+				t = strings.TrimSpace(t)
+				if t == "" {
+					continue
+				}
+				targets = append(targets, t)
+			}
+			d.SyncTargets = targets
+			return nil
+		},
+		check: checkSyncTargets,
+	},
+	"defaults.key_mode": {
+		get: func(d *models.Defaults) string { return d.KeyMode },
+		set: func(d *models.Defaults, value string) error {
+			if err := checkKeyMode(value); err != nil {
+				return err
+			}
+			d.KeyMode = value
+			return nil
+		},
+	},
+	"defaults.test_timeout": {
+		get: func(d *models.Defaults) string { return d.TestTimeout },
+		set: func(d *models.Defaults, value string) error {
+			if value != "" {
+				if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+					return fmt.Errorf("invalid test timeout '%s', expected a positive duration such as 30s", value)
+				}
+			}
+			d.TestTimeout = value
+			return nil
+		},
+	},
+	"defaults.message_timeout": {
+		get: func(d *models.Defaults) string { return d.MessageTimeout },
+		set: func(d *models.Defaults, value string) error {
+			if value != "" {
+				if timeout, err := time.ParseDuration(value); err != nil || timeout < 0 {
+					return fmt.Errorf("invalid message timeout '%s', expected a duration such as 5s, or 0 to keep messages", value)
+				}
+			}
+			d.MessageTimeout = value
+			return nil
+		},
+	},
+	"defaults.trash_days": {
+		get: func(d *models.Defaults) string {
+			if d.TrashDays == 0 {
+				return ""
+			}
+			return strconv.Itoa(d.TrashDays)
+		},
+		set: func(d *models.Defaults, value string) error {
+			days := 0
+			if value != "" {
+				var err error
+				if days, err = strconv.Atoi(value); err != nil || days <= 0 {
+					return fmt.Errorf("invalid trash retention '%s', expected a positive number of days", value)
+				}
+			}
+			d.TrashDays = days
+			return nil
+		},
+	},
+	"defaults.lock": {
+		get: func(d *models.Defaults) string { return d.Lock },
+		set: func(d *models.Defaults, value string) error {
+			value = strings.ToLower(value)
+			if value != "" && !slices.Contains(LockStrategies, value) {
+				return fmt.Errorf("invalid lock strategy '%s' (available: %s)", value, strings.Join(LockStrategies, ", "))
+			}
+			d.Lock = value
+			return nil
+		},
+	},
+	"defaults.verify_switch": {
+		get: func(d *models.Defaults) string {
+			if !d.VerifySwitch {
+				return ""
+			}
+			return "true"
+		},
+		set: func(d *models.Defaults, value string) error {
+			verify := false
+			if value != "" {
+				var err error
+				if verify, err = strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("invalid value '%s' for verify_switch, expected true or false", value)
+				}
+			}
+			d.VerifySwitch = verify
+			return nil
+		},
+	},
+}
+
+// SettingKeys returns the keys accepted by GetSetting and SetSetting, sorted
+func SettingKeys() []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// lookupSetting returns the setting for key or an error listing valid keys
+func lookupSetting(key string) (setting, error) {
+	s, ok := settings[key]
+	if !ok {
+		return setting{}, fmt.Errorf("unknown setting '%s' (available: %s)", key, strings.Join(SettingKeys(), ", "))
+	}
+	return s, nil
+}
+
+// GetSetting returns a config file setting, or an empty string when unset
+func (cm *Manager) GetSetting(key string) (string, error) {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+	if configFile.Defaults == nil {
+		return "", nil
+	}
+	return s.get(configFile.Defaults), nil
+}
+
+// SetSetting validates and saves a config file setting. An empty value
+// clears it.
+func (cm *Manager) SetSetting(key, value string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.update(func(configFile *models.File) error {
+		if configFile.Defaults == nil {
+			configFile.Defaults = &models.Defaults{}
+		}
+		if err := s.set(configFile.Defaults, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+		if s.check != nil {
+			if err := s.check(configFile); err != nil {
+				return err
+			}
+		}
+		if d := configFile.Defaults; d.Provider == "" && d.Model == "" && d.SyncTargets == nil && d.TestTimeout == "" && d.KeyMode == "" && d.MessageTimeout == "" && d.TrashDays == 0 && !d.VerifySwitch && d.Lock == "" {
+			configFile.Defaults = nil
+		}
+		return nil
+	})
+}
+
+// resolveEffective resolves a config's inheritance, applies the defaults
+// block and fills the base URL's placeholders, giving the values used on
+// switch and in tests
+func resolveEffective(configFile *models.File, alias string) (models.APIConfig, error) {
+	cfg, err := resolveConfig(configFile.Configs, alias)
+	if err != nil {
+		return cfg, err
+	}
+	applyDefaults(&cfg, configFile.Defaults)
+	cfg.BaseURL = utils.ExpandPlaceholders(cfg.BaseURL, cfg.Vars)
+	return cfg, nil
+}
+
+// applyDefaults fills a resolved config's empty provider and model from the
+// defaults block
+func applyDefaults(cfg *models.APIConfig, defaults *models.Defaults) {
+	if defaults == nil {
+		return
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = defaults.Provider
+	}
+	if cfg.Model == "" && defaults.Model != "" {
+		cfg.Model = defaults.Model
+		if len(cfg.Models) == 0 {
+			cfg.Models = []string{defaults.Model}
+		}
+	}
+}
+
+// checkKeyMode returns an error unless mode is a key mode or empty
+func checkKeyMode(mode string) error {
+	switch mode {
+	case "", KeyModeEnv, KeyModeHelper, KeyModeShell:
+		return nil
+	}
+	return exitcode.New(exitcode.Validation, "unknown key mode '%s' (available: %s, %s, %s)", mode, KeyModeEnv, KeyModeHelper, KeyModeShell)
+}
+
+// keyMode returns how Claude Code gets a resolved config's key, its own
+// key_mode first, then the defaults block's
+func keyMode(cfg *models.APIConfig, defaults *models.Defaults) string {
+	switch {
+	case cfg.KeyMode != "":
+		return cfg.KeyMode
+	case defaults != nil && defaults.KeyMode != "":
+		return defaults.KeyMode
+	}
+	return KeyModeEnv
+}
+
+// syncTargetEnabled reports whether the defaults block enables a sync
+// target, every target is enabled when sync_targets is unset
+func syncTargetEnabled(defaults *models.Defaults, target string) bool {
+	if defaults == nil || defaults.SyncTargets == nil {
+		return true
+	}
+	return slices.Contains(defaults.SyncTargets, target)
+}
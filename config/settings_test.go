@@ -0,0 +1,142 @@
+package config
+
+import (
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestSettings(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{key: "defaults.provider", value: "openai", want: "openai"},
+		{key: "defaults.provider", value: "nope", wantErr: true},
+		{key: "defaults.model", value: "claude-sonnet-4", want: "claude-sonnet-4"},
+		{key: "defaults.sync_targets", value: "none", want: "none"},
+		{key: "defaults.sync_targets", value: "claude, bogus", wantErr: true},
+		{key: "defaults.test_timeout", value: "45s", want: "45s"},
+		{key: "defaults.test_timeout", value: "-1s", wantErr: true},
+		{key: "defaults.message_timeout", value: "0", want: "0"},
+		{key: "defaults.message_timeout", value: "soon", wantErr: true},
+		{key: "defaults.key_mode", value: "shell", want: "shell"},
+		{key: "defaults.key_mode", value: "file", wantErr: true},
+		{key: "defaults.trash_days", value: "7", want: "7"},
+		{key: "defaults.trash_days", value: "0", wantErr: true},
+		{key: "defaults.verify_switch", value: "true", want: "true"},
+		{key: "defaults.verify_switch", value: "false", want: ""},
+		{key: "defaults.verify_switch", value: "always", wantErr: true},
+		{key: "defaults.unknown", value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cm := setupTestConfig(t)
+			err := cm.SetSetting(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetSetting(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := cm.GetSetting(tt.key)
+			if err != nil || got != tt.want {
+				t.Errorf("GetSetting(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+			}
+
+			// Clearing the only setting removes the defaults block
+			if err := cm.SetSetting(tt.key, ""); err != nil {
+				t.Fatalf("SetSetting(%q, \"\") unexpected error: %v", tt.key, err)
+			}
+			configFile, _ := cm.loadConfigFile()
+			if configFile.Defaults != nil {
+				t.Errorf("defaults block = %+v after clearing, want nil", configFile.Defaults)
+			}
+		})
+	}
+}
+
+func TestDefaultsApplied(t *testing.T) {
+	cm := setupTestConfig(t)
+	if err := cm.SetSetting("defaults.provider", "openai"); err != nil {
+		t.Fatalf("SetSetting() unexpected error: %v", err)
+	}
+	if err := cm.SetSetting("defaults.model", "gpt-4o"); err != nil {
+		t.Fatalf("SetSetting() unexpected error: %v", err)
+	}
+	if err := cm.SetSetting("defaults.test_timeout", "45s"); err != nil {
+		t.Fatalf("SetSetting() unexpected error: %v", err)
+	}
+
+	if err := cm.Add(models.APIConfig{Alias: "plain", APIKey: "sk-1"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "pinned", APIKey: "sk-2", Model: "gpt-4.1", Models: []string{"gpt-4.1"}}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	plain, _ := cm.Get("plain")
+	if plain.Provider != "openai" || plain.Model != "gpt-4o" {
+		t.Errorf("Get(plain) provider %q model %q, want defaults openai/gpt-4o", plain.Provider, plain.Model)
+	}
+	pinned, _ := cm.Get("pinned")
+	if pinned.Model != "gpt-4.1" {
+		t.Errorf("Get(pinned) model = %q, want its own gpt-4.1", pinned.Model)
+	}
+
+	// The default model is applied when read, not stored
+	configFile, _ := cm.loadConfigFile()
+	if configFile.Configs[0].Model != "" {
+		t.Errorf("stored model = %q, want empty", configFile.Configs[0].Model)
+	}
+
+	settings, err := cm.GetTestSettings()
+	if err != nil || settings == nil || settings.Timeout != "45s" {
+		t.Errorf("GetTestSettings() = %+v, %v, want timeout 45s from defaults", settings, err)
+	}
+}
+
+func TestSyncTargetEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults *models.Defaults
+		want     bool
+	}{
+		{name: "no defaults", defaults: nil, want: true},
+		{name: "targets unset", defaults: &models.Defaults{Model: "m"}, want: true},
+		{name: "claude", defaults: &models.Defaults{SyncTargets: []string{"claude"}}, want: true},
+		{name: "none", defaults: &models.Defaults{SyncTargets: []string{"none"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncTargetEnabled(tt.defaults, SyncTargetClaude); got != tt.want {
+				t.Errorf("syncTargetEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      models.APIConfig
+		defaults *models.Defaults
+		want     string
+	}{
+		{name: "unset", want: KeyModeEnv},
+		{name: "defaults", defaults: &models.Defaults{KeyMode: KeyModeHelper}, want: KeyModeHelper},
+		{name: "config wins", cfg: models.APIConfig{KeyMode: KeyModeShell}, defaults: &models.Defaults{KeyMode: KeyModeHelper}, want: KeyModeShell},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyMode(&tt.cfg, tt.defaults); got != tt.want {
+				t.Errorf("keyMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"apimgr/internal/notice"
+)
+
+// ActiveEnvFileName is the activation script kept in the state directory
+const ActiveEnvFileName = "active.env"
+
+// GlobalChangedFileName is rewritten on every global switch. The shell
+// integration compares its content at each prompt to reload the global
+// configuration in other terminals.
+const GlobalChangedFileName = "global.changed"
+
+// stateFileNames lists the runtime files kept in the state directory.
+// Session markers (session-<pid>) are moved too.
+var stateFileNames = []string{ActiveEnvFileName, GlobalChangedFileName, "history.json"}
+
+// baseStateDir returns the apimgr directory under XDG_STATE_HOME
+func baseStateDir() (string, error) {
+	xdgStateHome := os.Getenv("XDG_STATE_HOME")
+	if xdgStateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		// Use default XDG path (~/.local/state)
+		xdgStateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(xdgStateHome, "apimgr"), nil
+}
+
+// stateDirFor returns the state directory of a config file: the base state
+// directory for the default workspace, workspaces/<name> for named ones and
+// files/<hash> for files selected with --config or APIMGR_CONFIG
+func stateDirFor(configPath string) (string, error) {
+	baseState, err := baseStateDir()
+	if err != nil {
+		return "", err
+	}
+	baseConfig, err := baseConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	configDir := filepath.Dir(configPath)
+	if configDir == baseConfig {
+		return baseState, nil
+	}
+	workspaces := filepath.Join(baseConfig, workspacesDirName)
+	if filepath.Dir(configDir) == workspaces {
+		return filepath.Join(baseState, workspacesDirName, filepath.Base(configDir)), nil
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	return filepath.Join(baseState, "files", hex.EncodeToString(sum[:6])), nil
+}
+
+// migrateState moves runtime files left in the config directory by older
+// versions into the state directory, keeping files already there
+func migrateState(configDir, stateDir string) error {
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil
+	}
+
+	var moved []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isStateFile(name) {
+			continue
+		}
+		target := filepath.Join(stateDir, name)
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := os.Rename(filepath.Join(configDir, name), target); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", name, stateDir, err)
+		}
+		moved = append(moved, name)
+	}
+	if len(moved) > 0 {
+		notice.Printf("✅ Moved %s to %s\n", strings.Join(moved, ", "), stateDir)
+	}
+	return nil
+}
+
+// isStateFile reports whether a file name is a runtime file
+func isStateFile(name string) bool {
+	for _, stateFile := range stateFileNames {
+		if name == stateFile {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, "session-")
+}
+
+// ResolveStateDir returns the state directory of the config file in use,
+// for callers that need it before a Manager exists
+func ResolveStateDir() (string, error) {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return stateDirFor(configPath)
+}
+
+// StateDir returns the directory holding active.env, history and backups,
+// and session markers where there is no XDG_RUNTIME_DIR. Managers created
+// without NewConfigManager keep them next to the config file.
+func (cm *Manager) StateDir() string {
+	if cm.stateDir != "" {
+		return cm.stateDir
+	}
+	return filepath.Dir(cm.configPath)
+}
+
+// sessionDirFor returns the directory of the session markers of stateDir:
+// the same layout under apimgr in XDG_RUNTIME_DIR, which is cleared on
+// logout and reboot, or stateDir itself where XDG_RUNTIME_DIR is not set
+func sessionDirFor(stateDir string) string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return stateDir
+	}
+	if info, err := os.Stat(runtimeDir); err != nil || !info.IsDir() {
+		return stateDir
+	}
+	baseState, err := baseStateDir()
+	if err != nil {
+		return stateDir
+	}
+	rel, err := filepath.Rel(baseState, stateDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return stateDir
+	}
+	return filepath.Join(runtimeDir, "apimgr", rel)
+}
+
+// moveSessionMarkers moves the session markers older versions kept in the
+// state directory to the session directory, so running local sessions keep
+// working
+func moveSessionMarkers(stateDir, sessionDir string) error {
+	entries, err := os.ReadDir(stateDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "session-") {
+			continue
+		}
+		if err := os.MkdirAll(sessionDir, 0700); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(stateDir, name), filepath.Join(sessionDir, name)); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", name, sessionDir, err)
+		}
+	}
+	return nil
+}
+
+// SessionDir returns the directory holding session markers, in
+// XDG_RUNTIME_DIR when it is set, see sessionDirFor. Managers created
+// without NewConfigManager keep them in the state directory.
+func (cm *Manager) SessionDir() string {
+	if cm.sessionDir != "" {
+		return cm.sessionDir
+	}
+	return cm.StateDir()
+}
+
+// GlobalChangedPath returns the path of the global switch notification file
+func (cm *Manager) GlobalChangedPath() string {
+	return filepath.Join(cm.StateDir(), GlobalChangedFileName)
+}
+
+// GlobalChangeStamp returns the content of the global switch notification
+// file, empty before the first global switch
+func (cm *Manager) GlobalChangeStamp() string {
+	data, err := os.ReadFile(cm.GlobalChangedPath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// notifyGlobalChange rewrites the global switch notification file with a
+// new stamp
+func (cm *Manager) notifyGlobalChange(alias string) error {
+	stamp := fmt.Sprintf("%d %s\n", time.Now().UnixNano(), alias)
+	return os.WriteFile(cm.GlobalChangedPath(), []byte(stamp), 0600)
+}
+
+// BackupDir returns the directory holding backups of synced tool settings
+func (cm *Manager) BackupDir() string {
+	return filepath.Join(cm.StateDir(), "backups")
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestStateDir(t *testing.T) {
+	xdgConfig := t.TempDir()
+	xdgState := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgConfig)
+	t.Setenv("XDG_STATE_HOME", xdgState)
+	t.Setenv(ConfigEnvVar, "")
+
+	tests := []struct {
+		name       string
+		configPath string
+		want       string
+	}{
+		{"default workspace", filepath.Join(xdgConfig, "apimgr", "config.json"), filepath.Join(xdgState, "apimgr")},
+		{"named workspace", filepath.Join(xdgConfig, "apimgr", "workspaces", "acme", "config.yaml"), filepath.Join(xdgState, "apimgr", "workspaces", "acme")},
+		{"other file", "/srv/team/apimgr.json", filepath.Join(xdgState, "apimgr", "files")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stateDirFor(tt.configPath)
+			if err != nil {
+				t.Fatalf("stateDirFor() unexpected error: %v", err)
+			}
+			if got != tt.want && filepath.Dir(got) != tt.want {
+				t.Errorf("stateDirFor(%s) = %s, want %s", tt.configPath, got, tt.want)
+			}
+		})
+	}
+
+	// Managers built directly keep state next to the config file
+	cm := &Manager{configPath: filepath.Join(xdgConfig, "x", "config.json")}
+	if cm.StateDir() != filepath.Join(xdgConfig, "x") {
+		t.Errorf("StateDir() = %s, want the config directory", cm.StateDir())
+	}
+}
+
+func TestMigrateState(t *testing.T) {
+	xdgConfig := t.TempDir()
+	xdgState := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgConfig)
+	t.Setenv("XDG_STATE_HOME", xdgState)
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv(ConfigEnvVar, "")
+
+	configDir := filepath.Join(xdgConfig, "apimgr")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config.json", "active.env", "history.json", "session-123", "model_info.json"} {
+		if err := os.WriteFile(filepath.Join(configDir, name), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	stateDir := filepath.Join(xdgState, "apimgr")
+	if cm.StateDir() != stateDir {
+		t.Errorf("StateDir() = %s, want %s", cm.StateDir(), stateDir)
+	}
+
+	for _, name := range []string{"active.env", "history.json", "session-123"} {
+		if _, err := os.Stat(filepath.Join(stateDir, name)); err != nil {
+			t.Errorf("%s should be moved to the state directory", name)
+		}
+	}
+	entries, _ := os.ReadDir(configDir)
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	if strings.Join(left, ",") != "config.json,model_info.json" {
+		t.Errorf("config directory contains %v, want only config.json and model_info.json", left)
+	}
+}
+
+func TestSessionDir(t *testing.T) {
+	xdgConfig := t.TempDir()
+	xdgState := t.TempDir()
+	xdgRuntime := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgConfig)
+	t.Setenv("XDG_STATE_HOME", xdgState)
+	t.Setenv(ConfigEnvVar, "")
+
+	stateDir := filepath.Join(xdgState, "apimgr")
+	tests := []struct {
+		name       string
+		runtimeDir string
+		stateDir   string
+		want       string
+	}{
+		{"no runtime dir", "", stateDir, stateDir},
+		{"missing runtime dir", filepath.Join(xdgRuntime, "missing"), stateDir, stateDir},
+		{"default workspace", xdgRuntime, stateDir, filepath.Join(xdgRuntime, "apimgr")},
+		{"named workspace", xdgRuntime, filepath.Join(stateDir, "workspaces", "acme"), filepath.Join(xdgRuntime, "apimgr", "workspaces", "acme")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_RUNTIME_DIR", tt.runtimeDir)
+			if got := sessionDirFor(tt.stateDir); got != tt.want {
+				t.Errorf("sessionDirFor(%s) = %s, want %s", tt.stateDir, got, tt.want)
+			}
+		})
+	}
+
+	// Markers of running sessions move from the state directory
+	t.Setenv("XDG_RUNTIME_DIR", xdgRuntime)
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stateDir, "session-123"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	if want := filepath.Join(xdgRuntime, "apimgr"); cm.SessionDir() != want {
+		t.Errorf("SessionDir() = %s, want %s", cm.SessionDir(), want)
+	}
+	if _, err := os.Stat(filepath.Join(cm.SessionDir(), "session-123")); err != nil {
+		t.Error("session-123 should be moved to the session directory")
+	}
+}
+
+func TestGlobalChangeStamp(t *testing.T) {
+	cm := setupTestConfig(t)
+	if stamp := cm.GlobalChangeStamp(); stamp != "" {
+		t.Errorf("GlobalChangeStamp() before any switch = %q, want empty", stamp)
+	}
+	for _, alias := range []string{"a", "b"} {
+		if err := cm.Add(models.APIConfig{Alias: alias, APIKey: "sk-" + alias}); err != nil {
+			t.Fatalf("Add(%s) unexpected error: %v", alias, err)
+		}
+	}
+
+	if err := cm.SetActive("a"); err != nil {
+		t.Fatalf("SetActive(a) unexpected error: %v", err)
+	}
+	first := cm.GlobalChangeStamp()
+	if !strings.HasSuffix(first, " a") {
+		t.Errorf("GlobalChangeStamp() = %q, want it to end with the alias", first)
+	}
+
+	// A local switch leaves other terminals alone
+	if err := cm.MarkUsed("b"); err != nil {
+		t.Fatalf("MarkUsed(b) unexpected error: %v", err)
+	}
+	if stamp := cm.GlobalChangeStamp(); stamp != first {
+		t.Errorf("GlobalChangeStamp() after a local switch = %q, want %q", stamp, first)
+	}
+
+	if err := cm.SetActive("a"); err != nil {
+		t.Fatalf("SetActive(a) unexpected error: %v", err)
+	}
+	if stamp := cm.GlobalChangeStamp(); stamp == first {
+		t.Error("GlobalChangeStamp() should change on every global switch, even to the same alias")
+	}
+}
@@ -20,6 +20,16 @@ const (
 type BackupManager struct {
 	// MaxBackups is the maximum number of backups to retain
 	MaxBackups int
+	// Dir holds the backups, they are kept next to the file when empty
+	Dir string
+}
+
+// backupPrefix returns the path backups of filePath start with
+func (bm *BackupManager) backupPrefix(filePath string) string {
+	if bm.Dir == "" {
+		return filePath
+	}
+	return filepath.Join(bm.Dir, filepath.Base(filePath))
 }
 
 // NewBackupManager creates a new BackupManager with default settings
@@ -39,19 +49,20 @@ func (bm *BackupManager) CreateBackup(filePath string) (string, error) {
 
 	// Create backup filename with pattern: original.backup-YYYYMMDDHHMMSS-PID
 	timestamp := time.Now().Format("20060102150405")
-	backupPath := fmt.Sprintf("%s.backup-%s-%d", filePath, timestamp, pid)
+	backupPath := fmt.Sprintf("%s.backup-%s-%d", bm.backupPrefix(filePath), timestamp, pid)
+	if bm.Dir != "" {
+		if err := os.MkdirAll(bm.Dir, 0700); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
 
 	// Copy the file to create backup
 	if err := copyFile(filePath, backupPath); err != nil {
 		return "", fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Preserve file permissions
-	srcInfo, err := os.Stat(filePath)
-	if err != nil {
-		return backupPath, nil // Non-fatal, backup was created
-	}
-	if err := os.Chmod(backupPath, srcInfo.Mode()); err != nil {
+	// Backups hold API keys, so keep them private whatever the source mode
+	if err := os.Chmod(backupPath, 0600); err != nil {
 		return backupPath, nil // Non-fatal, backup was created
 	}
 
@@ -61,7 +72,7 @@ func (bm *BackupManager) CreateBackup(filePath string) (string, error) {
 // ListBackups returns a list of all backup files for the given filePath
 func (bm *BackupManager) ListBackups(filePath string) ([]string, error) {
 	// Pattern to match backup files
-	pattern := fmt.Sprintf("%s.backup-*", filePath)
+	pattern := fmt.Sprintf("%s.backup-*", bm.backupPrefix(filePath))
 
 	// Find all matching backup files
 	backupFiles, err := filepath.Glob(pattern)
@@ -109,7 +120,7 @@ func (bm *BackupManager) CleanupOldBackups(filePath string) error {
 // RestoreFromBackup restores the file from a specific backup path
 func (bm *BackupManager) RestoreFromBackup(filePath string, backupPath string) error {
 	// Validate the backup file path
-	pattern := fmt.Sprintf("%s.backup-*", filePath)
+	pattern := fmt.Sprintf("%s.backup-*", bm.backupPrefix(filePath))
 	match, err := filepath.Match(pattern, backupPath)
 	if err != nil {
 		return fmt.Errorf("invalid backup path: %w", err)
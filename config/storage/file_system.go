@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"apimgr/internal/notice"
 )
 
 // FileExists checks if a file exists
@@ -13,12 +15,12 @@ func FileExists(path string) bool {
 	return !os.IsNotExist(err)
 }
 
-// AtomicFileUpdate ensures atomic file update to prevent data corruption
-func AtomicFileUpdate(filePath string, newContent string, createBackup bool) error {
+// AtomicFileUpdate ensures atomic file update to prevent data corruption.
+// The file is backed up first unless backups is nil.
+func AtomicFileUpdate(filePath string, newContent string, backups *BackupManager) error {
 	// Create backup if requested
-	if createBackup {
-		bm := NewBackupManager(DefaultBackupRetention)
-		if _, err := bm.CreateBackup(filePath); err != nil {
+	if backups != nil {
+		if _, err := backups.CreateBackup(filePath); err != nil {
 			return fmt.Errorf("failed to create backup file: %w", err)
 		}
 	}
@@ -48,9 +50,8 @@ func AtomicFileUpdate(filePath string, newContent string, createBackup bool) err
 	}
 
 	// Cleanup old backups after successful update
-	if createBackup {
-		bm := NewBackupManager(DefaultBackupRetention)
-		if err := bm.CleanupOldBackups(filePath); err != nil {
+	if backups != nil {
+		if err := backups.CleanupOldBackups(filePath); err != nil {
 			// Non-fatal error, update was successful
 			// fmt.Printf("⚠️  Failed to cleanup old backups: %v\n", err)
 		}
@@ -70,9 +71,9 @@ func MigrateConfig(oldPath, newPath string) error {
 		return fmt.Errorf("old config file is empty")
 	}
 
-	// Validate that it's a valid JSON
+	// Validate that it's a valid JSON, comments and trailing commas allowed
 	var temp interface{}
-	if err := json.Unmarshal(data, &temp); err != nil {
+	if err := json.Unmarshal(StandardizeJSON(data), &temp); err != nil {
 		return fmt.Errorf("old config file format is invalid: %w", err)
 	}
 
@@ -83,7 +84,6 @@ func MigrateConfig(oldPath, newPath string) error {
 	}
 
 	// Lock the new config file exclusively
-	// Note: lockFileExclusive is only available inside config package
 	// For simplicity, we'll skip locking during migration
 	// since this is only called once when initializing the config manager
 
@@ -109,7 +109,7 @@ func MigrateConfig(oldPath, newPath string) error {
 	backupPath := oldPath + ".backup"
 	if err := os.Rename(oldPath, backupPath); err != nil {
 		// Don't fail migration if backup fails
-		fmt.Printf("⚠️  Failed to create backup of old config: %v\n", err)
+		notice.Printf("Warning: Failed to create backup of old config: %v\n", err)
 	}
 
 	return nil
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file encoding
+type Format string
+
+// Supported config file formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// Formats lists the supported formats, JSON first
+var Formats = []Format{FormatJSON, FormatYAML, FormatTOML}
+
+// FormatFromPath detects the format from a file extension, defaulting to JSON
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseFormat parses a format name such as "yaml" or "yml"
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s' (supported: json, yaml, toml)", name)
+	}
+}
+
+// Extension returns the file extension used for the format
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// configFileNames lists the config file names looked up in the config
+// directory, in order of precedence
+var configFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FindConfigFile returns the config file in dir, detecting YAML and TOML
+// files by extension. It returns config.json when no config file exists.
+func FindConfigFile(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if FileExists(path) {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileNames[0])
+}
+
+// Unmarshal decodes data in the given format into v. YAML and TOML are
+// converted to JSON first so v's json tags apply to every format.
+func Unmarshal(format Format, data []byte, v any) error {
+	switch format {
+	case FormatYAML:
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		return decodeViaJSON(doc, v)
+	case FormatTOML:
+		var doc map[string]any
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		return decodeViaJSON(doc, v)
+	default:
+		return json.Unmarshal(StandardizeJSON(data), v)
+	}
+}
+
+// Marshal encodes v in the given format using its json tags. For YAML,
+// comments and key order in previous, the file's current content, are kept
+// for keys that still exist. For JSON, the leading comment block is kept.
+func Marshal(format Format, v any, previous []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatYAML:
+		// JSON is valid YAML, so parsing it keeps the field order
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		blockStyle(&node)
+
+		var old yaml.Node
+		if len(previous) > 0 && yaml.Unmarshal(previous, &old) == nil && len(old.Content) > 0 {
+			keepComments(&node, &old)
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatTOML:
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		// Comments inside the document cannot be placed back, only the
+		// comment block at the top of the file is kept
+		if head := leadingComments(previous); head != nil {
+			return append(head, data...), nil
+		}
+		return data, nil
+	}
+}
+
+// decodeViaJSON re-encodes a generic document as JSON and decodes it into v
+func decodeViaJSON(doc any, v any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// blockStyle switches the flow style nodes parsed from JSON to block style
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// keepComments copies comments from old to the matching nodes of node.
+// Mapping entries match by key and sequence items by alias or position.
+func keepComments(node, old *yaml.Node) {
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+
+	switch {
+	case node.Kind == yaml.DocumentNode && old.Kind == yaml.DocumentNode:
+		if len(node.Content) > 0 && len(old.Content) > 0 {
+			keepComments(node.Content[0], old.Content[0])
+		}
+	case node.Kind == yaml.MappingNode && old.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			for j := 0; j+1 < len(old.Content); j += 2 {
+				if node.Content[i].Value == old.Content[j].Value {
+					keepComments(node.Content[i], old.Content[j])
+					keepComments(node.Content[i+1], old.Content[j+1])
+					break
+				}
+			}
+		}
+	case node.Kind == yaml.SequenceNode && old.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			if match := matchItem(item, old.Content, i); match != nil {
+				keepComments(item, match)
+			}
+		}
+	}
+}
+
+// matchItem finds the old sequence item matching item: the mapping with the
+// same alias when item has one, otherwise the item at the same position
+func matchItem(item *yaml.Node, old []*yaml.Node, index int) *yaml.Node {
+	if alias := mappingValue(item, "alias"); alias != "" {
+		for _, candidate := range old {
+			if mappingValue(candidate, "alias") == alias {
+				return candidate
+			}
+		}
+		return nil
+	}
+	if index < len(old) {
+		return old[index]
+	}
+	return nil
+}
+
+// mappingValue returns the scalar value of key in a mapping node
+func mappingValue(node *yaml.Node, key string) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+type formatTestFile struct {
+	Active  string             `json:"active"`
+	Configs []formatTestConfig `json:"configs"`
+}
+
+type formatTestConfig struct {
+	Alias   string   `json:"alias"`
+	APIKey  string   `json:"api_key"`
+	Models  []string `json:"models,omitempty"`
+	Timeout string   `json:"timeout,omitempty"`
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	want := formatTestFile{
+		Active: "work",
+		Configs: []formatTestConfig{
+			{Alias: "work", APIKey: "sk-work", Models: []string{"m1", "m2"}},
+			{Alias: "home", APIKey: "true", Timeout: "30s"},
+		},
+	}
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Marshal(format, want, nil)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if format != FormatJSON && strings.Contains(string(data), "{") {
+				t.Errorf("Marshal() should not use JSON syntax for %s:\n%s", format, data)
+			}
+
+			var got formatTestFile
+			if err := Unmarshal(format, data, &got); err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v\n%s", err, data)
+			}
+			if got.Active != want.Active || len(got.Configs) != 2 || got.Configs[1].APIKey != "true" || strings.Join(got.Configs[0].Models, ",") != "m1,m2" {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalYAMLKeepsComments(t *testing.T) {
+	previous := []byte(`# apimgr configs
+active: work
+configs:
+  # personal key
+  - alias: home
+    api_key: sk-old # rotated monthly
+  - alias: work
+    api_key: sk-work
+`)
+	v := formatTestFile{
+		Active: "home",
+		Configs: []formatTestConfig{
+			{Alias: "work", APIKey: "sk-work"},
+			{Alias: "home", APIKey: "sk-new"},
+		},
+	}
+
+	data, err := Marshal(FormatYAML, v, previous)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	out := string(data)
+	for _, s := range []string{"# apimgr configs", "# personal key", "api_key: sk-new # rotated monthly", "active: home"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Marshal() output missing %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"config.json": FormatJSON,
+		"config.yaml": FormatYAML,
+		"config.YML":  FormatYAML,
+		"config.toml": FormatTOML,
+		"config":      FormatJSON,
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if _, err := ParseFormat("ini"); err == nil {
+		t.Error("ParseFormat(ini) expected error")
+	}
+}
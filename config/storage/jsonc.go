@@ -0,0 +1,75 @@
+package storage
+
+import "bytes"
+
+// StandardizeJSON converts JSONC, JSON with // and /* */ comments and
+// trailing commas, to standard JSON. Comments and trailing commas are
+// replaced with spaces so byte offsets match the original content.
+func StandardizeJSON(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// Blank out comments, keeping newlines
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch {
+		case inString:
+			if out[i] == '\\' {
+				i++
+			} else if out[i] == '"' {
+				inString = false
+			}
+		case out[i] == '"':
+			inString = true
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+
+	// Blank out commas followed only by whitespace before } or ]
+	inString = false
+	for i := 0; i < len(out); i++ {
+		switch {
+		case inString:
+			if out[i] == '\\' {
+				i++
+			} else if out[i] == '"' {
+				inString = false
+			}
+		case out[i] == '"':
+			inString = true
+		case out[i] == ',':
+			rest := bytes.TrimLeft(out[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// leadingComments returns the comment block before the first value of a
+// JSONC document, or nil when there is none
+func leadingComments(data []byte) []byte {
+	standard := StandardizeJSON(data)
+	start := len(standard) - len(bytes.TrimLeft(standard, " \t\r\n"))
+	head := bytes.TrimSpace(data[:start])
+	if len(head) == 0 {
+		return nil
+	}
+	return append(head, '\n')
+}
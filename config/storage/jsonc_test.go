@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStandardizeJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // Compact form of the standardized JSON
+	}{
+		{"plain", `{"a": 1}`, `{"a":1}`},
+		{"line comment", "{\n  // note\n  \"a\": 1 // trailing\n}", `{"a":1}`},
+		{"block comment", "/* header */ {\"a\": /* inline */ 1}", `{"a":1}`},
+		{"trailing commas", "{\"a\": [1, 2,], \"b\": 2,\n}", `{"a":[1,2],"b":2}`},
+		{"comment markers in strings", `{"url": "https://x.com/*", "s": "a, }"}`, `{"s":"a, }","url":"https://x.com/*"}`},
+		{"escaped quote", `{"a": "say \"//\"", "b": 1,}`, `{"a":"say \"//\"","b":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StandardizeJSON([]byte(tt.input))
+			if len(got) != len(tt.input) {
+				t.Errorf("StandardizeJSON() changed length from %d to %d", len(tt.input), len(got))
+			}
+			var v any
+			if err := json.Unmarshal(got, &v); err != nil {
+				t.Fatalf("StandardizeJSON() = %q, not valid JSON: %v", got, err)
+			}
+			compact, _ := json.Marshal(v)
+			if string(compact) != tt.want {
+				t.Errorf("StandardizeJSON() = %s, want %s", compact, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONKeepsLeadingComments(t *testing.T) {
+	previous := []byte("// apimgr configs\n// edited by hand\n{\n  \"active\": \"old\", // inline\n}\n")
+	data, err := Marshal(FormatJSON, map[string]string{"active": "new"}, previous)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	want := "// apimgr configs\n// edited by hand\n{\n  \"active\": \"new\"\n}"
+	if string(data) != want {
+		t.Errorf("Marshal() = %q, want %q", data, want)
+	}
+
+	var v map[string]string
+	if err := Unmarshal(FormatJSON, data, &v); err != nil || v["active"] != "new" {
+		t.Errorf("Unmarshal() = %v, %v", v, err)
+	}
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// LockPath takes an exclusive lock guarding path, held on the lock file
+// path.lock, and returns the function releasing it. Files replaced with
+// AtomicFileUpdate cannot hold the lock themselves, as the rename drops it.
+func LockPath(path string) (func(), error) {
+	file, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := LockExclusive(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		Unlock(file)
+		file.Close()
+	}, nil
+}
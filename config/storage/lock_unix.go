@@ -1,7 +1,7 @@
 //go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
 // +build linux darwin freebsd netbsd openbsd dragonfly
 
-package config
+package storage
 
 import (
 	"fmt"
@@ -9,26 +9,28 @@ import (
 
 	"golang.org/x/sys/unix"
 	"os"
+
+	"apimgr/internal/exitcode"
 )
 
 const (
-	lockTimeout     = 5 * time.Second
-	lockRetryDelay  = 50 * time.Millisecond
+	LockTimeout     = 5 * time.Second        // How long a lock is waited for
+	LockRetryDelay  = 50 * time.Millisecond // Delay between attempts to take a lock
 )
 
-// lockFileExclusive acquires an exclusive lock (write lock) with timeout
-func lockFileExclusive(f *os.File) error {
+// LockExclusive acquires an exclusive lock (write lock) with timeout
+func LockExclusive(f *os.File) error {
 	return lockWithTimeout(f, unix.LOCK_EX)
 }
 
-// lockFileShared acquires a shared lock (read lock) with timeout
-func lockFileShared(f *os.File) error {
+// LockShared acquires a shared lock (read lock) with timeout
+func LockShared(f *os.File) error {
 	return lockWithTimeout(f, unix.LOCK_SH)
 }
 
 // lockWithTimeout attempts to acquire a lock with timeout to prevent blocking
 func lockWithTimeout(f *os.File, lockType int) error {
-	deadline := time.Now().Add(lockTimeout)
+	deadline := time.Now().Add(LockTimeout)
 	
 	for {
 		// Try non-blocking lock first
@@ -44,15 +46,15 @@ func lockWithTimeout(f *os.File, lockType int) error {
 		
 		// Check timeout
 		if time.Now().After(deadline) {
-			return fmt.Errorf("lock timeout: file is locked by another process")
+			return exitcode.New(exitcode.LockTimeout, "lock timeout: file is locked by another process")
 		}
 		
 		// Wait before retry
-		time.Sleep(lockRetryDelay)
+		time.Sleep(LockRetryDelay)
 	}
 }
 
-// unlockFile releases the file lock
-func unlockFile(f *os.File) error {
+// Unlock releases the file lock
+func Unlock(f *os.File) error {
 	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
 }
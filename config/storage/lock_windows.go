@@ -1,21 +1,22 @@
 //go:build windows
 // +build windows
 
-package config
+package storage
 
 import (
-	"fmt"
 	"os"
 	"syscall"
 	"time"
 	"unsafe"
+
+	"apimgr/internal/exitcode"
 )
 
 const (
 	lockfileExclusiveLock   = 0x00000002
 	lockfileFailImmediately = 0x00000001
-	lockTimeout             = 5 * time.Second
-	lockRetryDelay          = 50 * time.Millisecond
+	LockTimeout             = 5 * time.Second        // How long a lock is waited for
+	LockRetryDelay          = 50 * time.Millisecond // Delay between attempts to take a lock
 )
 
 var (
@@ -24,19 +25,19 @@ var (
 	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
 )
 
-// lockFileExclusive acquires an exclusive lock (write lock) with timeout
-func lockFileExclusive(f *os.File) error {
+// LockExclusive acquires an exclusive lock (write lock) with timeout
+func LockExclusive(f *os.File) error {
 	return lockWithTimeout(f, lockfileExclusiveLock)
 }
 
-// lockFileShared acquires a shared lock (read lock) with timeout
-func lockFileShared(f *os.File) error {
+// LockShared acquires a shared lock (read lock) with timeout
+func LockShared(f *os.File) error {
 	return lockWithTimeout(f, 0)
 }
 
 // lockWithTimeout attempts to acquire a lock with timeout to prevent blocking
 func lockWithTimeout(f *os.File, flags uintptr) error {
-	deadline := time.Now().Add(lockTimeout)
+	deadline := time.Now().Add(LockTimeout)
 	
 	for {
 		var overlapped syscall.Overlapped
@@ -56,19 +57,19 @@ func lockWithTimeout(f *os.File, flags uintptr) error {
 		
 		// Check timeout
 		if time.Now().After(deadline) {
-			return fmt.Errorf("lock timeout: file is locked by another process")
+			return exitcode.New(exitcode.LockTimeout, "lock timeout: file is locked by another process")
 		}
 		
 		// Wait before retry
-		time.Sleep(lockRetryDelay)
+		time.Sleep(LockRetryDelay)
 		
 		// Ignore the error from Call, we'll retry
 		_ = err
 	}
 }
 
-// unlockFile releases the file lock
-func unlockFile(f *os.File) error {
+// Unlock releases the file lock
+func Unlock(f *os.File) error {
 	var overlapped syscall.Overlapped
 	r1, _, err := procUnlockFileEx.Call(
 		uintptr(f.Fd()),
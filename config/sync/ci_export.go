@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/config/models"
+	"apimgr/internal/utils"
+)
+
+// Supported CI systems
+const (
+	CIGitHub = "github"
+	CIGitLab = "gitlab"
+)
+
+// GenerateCIMasks returns the commands that make the CI mask the config's
+// secrets in job logs. GitLab can only mask variables defined in the
+// project settings, so nothing is emitted for it.
+func GenerateCIMasks(cfg *models.APIConfig, ci string) (string, error) {
+	switch ci {
+	case CIGitHub:
+		var buf strings.Builder
+		for _, v := range EnvExports(cfg) {
+			if utils.IsSecretName(v.Name) && v.Value != "" {
+				buf.WriteString(fmt.Sprintf("::add-mask::%s\n", v.Value))
+			}
+		}
+		return buf.String(), nil
+	case CIGitLab:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported CI '%s' (supported: github, gitlab)", ci)
+	}
+}
+
+// GenerateCIExports returns the config's variables in the CI's syntax:
+// NAME=value lines for GitHub's $GITHUB_ENV file, export lines for GitLab
+// scripts to eval
+func GenerateCIExports(cfg *models.APIConfig, ci string) (string, error) {
+	var buf strings.Builder
+	for _, v := range EnvExports(cfg) {
+		switch ci {
+		case CIGitHub:
+			if strings.ContainsAny(v.Value, "\r\n") {
+				return "", fmt.Errorf("%s contains a line break", v.Name)
+			}
+			buf.WriteString(fmt.Sprintf("%s=%s\n", v.Name, v.Value))
+		case CIGitLab:
+			buf.WriteString(fmt.Sprintf("export %s=%s\n", v.Name, ShellQuote(v.Value)))
+		default:
+			return "", fmt.Errorf("unsupported CI '%s' (supported: github, gitlab)", ci)
+		}
+	}
+	return buf.String(), nil
+}
+
+// ShellQuote single-quotes s for POSIX shells
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
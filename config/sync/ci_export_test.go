@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestGenerateCIEnv(t *testing.T) {
+	cfg := &models.APIConfig{Alias: "relay", AuthToken: "tok'en", BaseURL: "https://relay.example.com"}
+
+	masks, err := GenerateCIMasks(cfg, CIGitHub)
+	if err != nil {
+		t.Fatalf("GenerateCIMasks() unexpected error: %v", err)
+	}
+	if masks != "::add-mask::tok'en\n" {
+		t.Errorf("GenerateCIMasks(github) = %q, want only the token masked", masks)
+	}
+
+	tests := []struct {
+		ci   string
+		want string
+	}{
+		{CIGitHub, "ANTHROPIC_AUTH_TOKEN=tok'en\n"},
+		{CIGitLab, `export ANTHROPIC_AUTH_TOKEN='tok'\''en'` + "\n"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateCIExports(cfg, tt.ci)
+		if err != nil {
+			t.Fatalf("GenerateCIExports(%s) unexpected error: %v", tt.ci, err)
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("GenerateCIExports(%s) = %q, want prefix %q", tt.ci, got, tt.want)
+		}
+	}
+
+	if _, err := GenerateCIExports(cfg, "jenkins"); err == nil {
+		t.Error("GenerateCIExports() expected error for unsupported CI")
+	}
+}
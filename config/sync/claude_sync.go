@@ -3,25 +3,39 @@ package sync
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"apimgr/config/models"
+	"apimgr/config/storage"
 	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
 )
 
+// APIKeyHelperField is the Claude Code setting naming a command that prints
+// the API key
+const APIKeyHelperField = "apiKeyHelper"
+
+// SyncedEnvVar lists the request settings apimgr wrote into the settings
+// env, comma separated, so that values set by hand are never removed
+const SyncedEnvVar = "APIMGR_SYNCED_ENV"
+
 // SyncOptions provides options for synchronization
 type SyncOptions struct {
 	DryRun        bool  // 仅验证，不写入
 	CreateBackup  bool  // 更新前创建备份
 	PreserveOther bool  // 保留非 ANTHROPIC 环境变量
+	KeyHelper     string // 设为 apiKeyHelper 的命令，此时 env 中不写入密钥
+	OmitKey       bool   // env 中不写入密钥，由 shell 环境提供
 }
 
 // UpdateEnvField updates the env field in Claude Code configuration JSON
 // It only updates ANTHROPIC_ fields and preserves non-ANTHROPIC fields when PreserveOther is true
+// Comments and trailing commas outside the env field are kept
 func UpdateEnvField(originalContent string, cfg *models.APIConfig, opts SyncOptions) (string, error) {
 	// Parse the JSON content to verify it's valid
-	result := gjson.Parse(originalContent)
+	standard := string(storage.StandardizeJSON([]byte(originalContent)))
+	result := gjson.Parse(standard)
 	if !result.Exists() {
 		return "", fmt.Errorf("invalid JSON content")
 	}
@@ -38,19 +52,23 @@ func UpdateEnvField(originalContent string, cfg *models.APIConfig, opts SyncOpti
 	// Create updated env map
 	updatedEnv := make(map[string]string)
 
-	// Preserve non-ANTHROPIC environment variables if requested
+	// Preserve the environment variables apimgr does not manage if requested
 	if opts.PreserveOther {
+		synced := SyncedRequestSettings(existingEnv)
 		for key, value := range existingEnv {
-			if !strings.HasPrefix(strings.ToUpper(key), "ANTHROPIC_") {
+			if !managedEnvName(key) && !slices.Contains(synced, key) {
 				updatedEnv[key] = value
 			}
 		}
 	}
 
-	// Set new ANTHROPIC values (only non-empty values)
-	if cfg.APIKey != "" {
+	// Set new ANTHROPIC values (only non-empty values), leaving the key to
+	// the helper when there is one
+	switch {
+	case opts.KeyHelper != "" || opts.OmitKey:
+	case cfg.APIKey != "":
 		updatedEnv["ANTHROPIC_API_KEY"] = cfg.APIKey
-	} else if cfg.AuthToken != "" {
+	case cfg.AuthToken != "":
 		updatedEnv["ANTHROPIC_AUTH_TOKEN"] = cfg.AuthToken
 	}
 	if cfg.Model != "" {
@@ -59,6 +77,17 @@ func UpdateEnvField(originalContent string, cfg *models.APIConfig, opts SyncOpti
 	if cfg.BaseURL != "" {
 		updatedEnv["ANTHROPIC_BASE_URL"] = cfg.BaseURL
 	}
+	if headers := CustomHeaders(cfg); headers != "" {
+		updatedEnv[CustomHeadersEnvVar] = headers
+	}
+	var synced []string
+	for _, v := range RequestSettings(cfg) {
+		updatedEnv[v.Name] = v.Value
+		synced = append(synced, v.Name)
+	}
+	if len(synced) > 0 {
+		updatedEnv[SyncedEnvVar] = strings.Join(synced, ",")
+	}
 
 	// Convert updatedEnv to JSON string
 	envJSON, err := json.Marshal(updatedEnv)
@@ -66,20 +95,152 @@ func UpdateEnvField(originalContent string, cfg *models.APIConfig, opts SyncOpti
 		return "", fmt.Errorf("failed to marshal updated env: %w", err)
 	}
 
-	// Use sjson.SetRaw to update the env field precisely
-	updatedContent, err := sjson.SetRaw(originalContent, "env", string(envJSON))
+	// Replace the env field precisely
+	updatedContent, err := setEnvRaw(originalContent, standard, string(envJSON))
 	if err != nil {
 		return "", fmt.Errorf("failed to update env field: %w", err)
 	}
 
+	if opts.KeyHelper != "" {
+		helperJSON, err := json.Marshal(opts.KeyHelper)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal key helper: %w", err)
+		}
+		updatedStandard := string(storage.StandardizeJSON([]byte(updatedContent)))
+		updatedContent, err = setFieldRaw(updatedContent, updatedStandard, APIKeyHelperField, string(helperJSON))
+		if err != nil {
+			return "", fmt.Errorf("failed to update %s field: %w", APIKeyHelperField, err)
+		}
+	}
+
 	// Validate the update to ensure only env field has changed and non-ANTHROPIC fields are preserved
-	if err := validateJSONUpdate(originalContent, updatedContent); err != nil {
+	updatedStandard := string(storage.StandardizeJSON([]byte(updatedContent)))
+	if err := validateJSONUpdate(standard, updatedStandard); err != nil {
 		return "", fmt.Errorf("update validation failed: %w", err)
 	}
 
 	return updatedContent, nil
 }
 
+// RemoveEnvFields removes the named variables from the env field in Claude
+// Code configuration JSON, keeping comments outside the env field
+func RemoveEnvFields(originalContent string, names []string) (string, error) {
+	standard := string(storage.StandardizeJSON([]byte(originalContent)))
+	if !gjson.Valid(standard) {
+		return "", fmt.Errorf("invalid JSON content")
+	}
+	env := gjson.Get(standard, "env")
+	if !env.IsObject() {
+		return originalContent, nil
+	}
+
+	// Keep the remaining variables with their raw values and order
+	var kept []string
+	removed := false
+	env.ForEach(func(key, value gjson.Result) bool {
+		if slices.Contains(names, key.Str) {
+			removed = true
+		} else {
+			kept = append(kept, key.Raw+":"+value.Raw)
+		}
+		return true
+	})
+	if !removed {
+		return originalContent, nil
+	}
+	return setEnvRaw(originalContent, standard, "{"+strings.Join(kept, ",")+"}")
+}
+
+// RemoveKeyHelper removes the apiKeyHelper field from Claude Code
+// configuration JSON when it is set to command, keeping comments
+func RemoveKeyHelper(originalContent, command string) (string, error) {
+	standard := string(storage.StandardizeJSON([]byte(originalContent)))
+	root := gjson.Parse(standard)
+	if !gjson.Valid(standard) || !root.IsObject() {
+		return "", fmt.Errorf("invalid JSON content")
+	}
+	if helper := root.Get(APIKeyHelperField); !helper.Exists() || helper.String() != command {
+		return originalContent, nil
+	}
+
+	// Cut from the end of the previous field, or from the opening brace
+	// through the following comma when it is the first field
+	start, end := -1, -1
+	root.ForEach(func(key, value gjson.Result) bool {
+		if key.Str == APIKeyHelperField {
+			end = value.Index + len(value.Raw)
+			return false
+		}
+		start = value.Index + len(value.Raw)
+		return true
+	})
+	if start >= 0 {
+		return originalContent[:start] + originalContent[end:], nil
+	}
+	open := strings.IndexByte(standard, '{')
+	if comma := strings.IndexByte(standard[end:], ','); comma >= 0 && strings.TrimSpace(standard[end:end+comma]) == "" {
+		end += comma + 1
+	}
+	return originalContent[:open+1] + originalContent[end:], nil
+}
+
+// setEnvRaw sets the env field of content to envJSON without touching the
+// rest of the content. standard is content passed through
+// storage.StandardizeJSON, which keeps byte offsets, so positions found in
+// it apply to content.
+func setEnvRaw(content, standard, envJSON string) (string, error) {
+	return setFieldRaw(content, standard, "env", envJSON)
+}
+
+// setFieldRaw sets a top-level field of content to raw JSON, see setEnvRaw
+func setFieldRaw(content, standard, field, raw string) (string, error) {
+	root := gjson.Parse(standard)
+	if !root.IsObject() {
+		return "", fmt.Errorf("settings are not a JSON object")
+	}
+
+	if value := root.Get(field); value.Exists() && value.Index > 0 {
+		return content[:value.Index] + raw + content[value.Index+len(value.Raw):], nil
+	}
+
+	// Add the field after the last one, or as the only field
+	end := -1
+	root.ForEach(func(key, value gjson.Result) bool {
+		end = value.Index + len(value.Raw)
+		return true
+	})
+	if end < 0 {
+		open := strings.IndexByte(standard, '{')
+		return content[:open+1] + "\"" + field + "\": " + raw + content[open+1:], nil
+	}
+	return content[:end] + ",\n  \"" + field + "\": " + raw + content[end:], nil
+}
+
+// managedEnvName reports whether apimgr always owns the settings env
+// variable: the ANTHROPIC_ ones and SyncedEnvVar. Request settings are
+// only owned once listed in SyncedEnvVar.
+func managedEnvName(key string) bool {
+	return strings.HasPrefix(strings.ToUpper(key), "ANTHROPIC_") || key == SyncedEnvVar
+}
+
+// requestSettingName reports whether a config's request settings may set
+// the settings env variable
+func requestSettingName(key string) bool {
+	return key == APITimeoutEnvVar || key == MaxRetriesEnvVar
+}
+
+// SyncedRequestSettings returns the request settings apimgr wrote into a
+// settings env, as listed in SyncedEnvVar
+func SyncedRequestSettings(env map[string]string) []string {
+	var names []string
+	for _, name := range strings.Split(env[SyncedEnvVar], ",") {
+		if requestSettingName(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // validateJSONUpdate validates that only the env field has changed in the JSON
 func validateJSONUpdate(originalContent string, updatedContent string) error {
 	// 1. Validate JSON validity
@@ -97,7 +258,9 @@ func validateJSONUpdate(originalContent string, updatedContent string) error {
 		return err
 	}
 
-	// Compare all fields except env
+	// Compare all fields except env and the key helper apimgr manages
+	delete(original, APIKeyHelperField)
+	delete(updated, APIKeyHelperField)
 	differences := deepCompare(original, updated)
 	if len(differences) > 0 {
 		return fmt.Errorf("unexpected changes to non-env fields: %s", strings.Join(differences, ", "))
@@ -114,9 +277,10 @@ func validateJSONUpdate(originalContent string, updatedContent string) error {
 		return err
 	}
 
-	// Check that all non-ANTHROPIC fields are preserved
+	// Check that all non-ANTHROPIC fields are preserved, except the request
+	// settings a config overrides
 	for key, originalVal := range originalEnv {
-		if !strings.HasPrefix(strings.ToUpper(key), "ANTHROPIC_") {
+		if !managedEnvName(key) && !requestSettingName(key) {
 			if updatedVal, exists := updatedEnv[key]; exists {
 				if fmt.Sprintf("%v", originalVal) != fmt.Sprintf("%v", updatedVal) {
 					return fmt.Errorf("non-ANTHROPIC field '%s' was modified", key)
@@ -211,3 +375,58 @@ func extractEnv(jsonContent string) (map[string]interface{}, error) {
 
 	return envMap, nil
 }
+
+// ReadSettingsEnv returns the env field of a Claude Code settings file, or
+// nil when the file does not exist or cannot be parsed
+func ReadSettingsEnv(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return ParseSettingsEnv(data)
+}
+
+// ParseSettingsEnv returns the env field of Claude Code settings content, or
+// nil when it cannot be parsed
+func ParseSettingsEnv(data []byte) map[string]string {
+	var settings struct {
+		Env map[string]any `json:"env"`
+	}
+	if err := json.Unmarshal(storage.StandardizeJSON(data), &settings); err != nil {
+		return nil
+	}
+	env := make(map[string]string, len(settings.Env))
+	for name, value := range settings.Env {
+		env[name] = fmt.Sprint(value)
+	}
+	return env
+}
+
+// ParseKeyHelper returns the apiKeyHelper command of Claude Code settings
+// content, empty when there is none
+func ParseKeyHelper(data []byte) string {
+	var settings map[string]any
+	if err := json.Unmarshal(storage.StandardizeJSON(data), &settings); err != nil {
+		return ""
+	}
+	helper, _ := settings[APIKeyHelperField].(string)
+	return helper
+}
+
+// SettingsEnvMatches reports whether the env field of a Claude Code settings
+// file points Claude Code at cfg, with the same key or token and base URL
+func SettingsEnvMatches(cfg *models.APIConfig, env map[string]string) bool {
+	switch {
+	case cfg.APIKey != "":
+		if env["ANTHROPIC_API_KEY"] != cfg.APIKey {
+			return false
+		}
+	case cfg.AuthToken != "":
+		if env["ANTHROPIC_AUTH_TOKEN"] != cfg.AuthToken {
+			return false
+		}
+	default:
+		return false
+	}
+	return env["ANTHROPIC_BASE_URL"] == cfg.BaseURL
+}
@@ -0,0 +1,179 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestUpdateEnvFieldJSONC(t *testing.T) {
+	cfg := &models.APIConfig{APIKey: "sk-new", BaseURL: "https://api.example.com"}
+	opts := SyncOptions{PreserveOther: true}
+
+	tests := []struct {
+		name     string
+		original string
+		keep     []string // Substrings that must survive the update
+	}{
+		{
+			name:     "replace env",
+			original: "{\n  // my settings\n  \"env\": {\"FOO\": \"bar\", \"ANTHROPIC_API_KEY\": \"sk-old\"},\n  \"theme\": \"dark\", // keep me\n}\n",
+			keep:     []string{"// my settings", "\"theme\": \"dark\", // keep me", "\"FOO\":\"bar\""},
+		},
+		{
+			name:     "add env",
+			original: "{\n  /* header */\n  \"theme\": \"dark\",\n}\n",
+			keep:     []string{"/* header */", "\"theme\": \"dark\""},
+		},
+		{
+			name:     "empty object",
+			original: "// empty\n{}\n",
+			keep:     []string{"// empty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := UpdateEnvField(tt.original, cfg, opts)
+			if err != nil {
+				t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+			}
+			for _, s := range append(tt.keep, "\"ANTHROPIC_API_KEY\":\"sk-new\"") {
+				if !strings.Contains(updated, s) {
+					t.Errorf("UpdateEnvField() result missing %q:\n%s", s, updated)
+				}
+			}
+			if strings.Contains(updated, "sk-old") {
+				t.Errorf("UpdateEnvField() kept the old key:\n%s", updated)
+			}
+		})
+	}
+}
+
+func TestRemoveEnvFields(t *testing.T) {
+	original := "{\n  // keep\n  \"env\": {\"ANTHROPIC_MODEL\": \"m\", \"DEBUG\": 1},\n}\n"
+	updated, err := RemoveEnvFields(original, []string{"ANTHROPIC_MODEL"})
+	if err != nil {
+		t.Fatalf("RemoveEnvFields() unexpected error: %v", err)
+	}
+	want := "{\n  // keep\n  \"env\": {\"DEBUG\":1},\n}\n"
+	if updated != want {
+		t.Errorf("RemoveEnvFields() = %q, want %q", updated, want)
+	}
+
+	unchanged, err := RemoveEnvFields(updated, []string{"ANTHROPIC_MODEL"})
+	if err != nil || unchanged != updated {
+		t.Errorf("RemoveEnvFields() without matches = %q, %v; want unchanged", unchanged, err)
+	}
+}
+
+func TestUpdateEnvFieldRequestSettings(t *testing.T) {
+	retries := 3
+	original := "{\n  \"env\": {\"API_TIMEOUT_MS\": \"5000\", \"CLAUDE_CODE_MAX_RETRIES\": \"1\", \"DEBUG\": \"1\"}\n}\n"
+	opts := SyncOptions{PreserveOther: true}
+
+	updated, err := UpdateEnvField(original, &models.APIConfig{APIKey: "sk", APITimeout: "2m", MaxRetries: &retries}, opts)
+	if err != nil {
+		t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+	}
+	for _, s := range []string{`"API_TIMEOUT_MS":"120000"`, `"CLAUDE_CODE_MAX_RETRIES":"3"`, `"DEBUG":"1"`} {
+		if !strings.Contains(updated, s) {
+			t.Errorf("UpdateEnvField() result missing %q:\n%s", s, updated)
+		}
+	}
+
+	// A config without request settings drops those apimgr wrote
+	cleared, err := UpdateEnvField(updated, &models.APIConfig{APIKey: "sk"}, opts)
+	if err != nil {
+		t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+	}
+	if strings.Contains(cleared, "API_TIMEOUT_MS") || strings.Contains(cleared, "CLAUDE_CODE_MAX_RETRIES") || strings.Contains(cleared, SyncedEnvVar) || !strings.Contains(cleared, `"DEBUG":"1"`) {
+		t.Errorf("UpdateEnvField() without request settings = %s", cleared)
+	}
+
+	// and keeps those set by hand
+	kept, err := UpdateEnvField(original, &models.APIConfig{APIKey: "sk"}, opts)
+	if err != nil {
+		t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+	}
+	for _, s := range []string{`"API_TIMEOUT_MS":"5000"`, `"CLAUDE_CODE_MAX_RETRIES":"1"`} {
+		if !strings.Contains(kept, s) {
+			t.Errorf("UpdateEnvField() dropped the hand-set %s:\n%s", s, kept)
+		}
+	}
+}
+
+func TestSettingsEnvMatches(t *testing.T) {
+	relay := &models.APIConfig{APIKey: "sk-relay", BaseURL: "https://relay.example.com"}
+	official := &models.APIConfig{AuthToken: "token"}
+
+	tests := []struct {
+		name string
+		cfg  *models.APIConfig
+		env  map[string]string
+		want bool
+	}{
+		{"same key and URL", relay, map[string]string{"ANTHROPIC_API_KEY": "sk-relay", "ANTHROPIC_BASE_URL": "https://relay.example.com", "ANTHROPIC_MODEL": "other"}, true},
+		{"other key", relay, map[string]string{"ANTHROPIC_API_KEY": "sk-other", "ANTHROPIC_BASE_URL": "https://relay.example.com"}, false},
+		{"other URL", relay, map[string]string{"ANTHROPIC_API_KEY": "sk-relay"}, false},
+		{"token without URL", official, map[string]string{"ANTHROPIC_AUTH_TOKEN": "token"}, true},
+		{"no env", official, nil, false},
+		{"config without credentials", &models.APIConfig{}, map[string]string{}, false},
+	}
+	for _, tt := range tests {
+		if got := SettingsEnvMatches(tt.cfg, tt.env); got != tt.want {
+			t.Errorf("%s: SettingsEnvMatches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKeyHelper(t *testing.T) {
+	cfg := &models.APIConfig{APIKey: "sk-new", BaseURL: "https://api.example.com"}
+	original := "{\n  // keep\n  \"env\": {\"ANTHROPIC_API_KEY\": \"sk-old\"},\n  \"theme\": \"dark\"\n}\n"
+
+	updated, err := UpdateEnvField(original, cfg, SyncOptions{PreserveOther: true, KeyHelper: "/state/api-key-helper.sh"})
+	if err != nil {
+		t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+	}
+	for _, s := range []string{"// keep", `"apiKeyHelper": "/state/api-key-helper.sh"`, "https://api.example.com"} {
+		if !strings.Contains(updated, s) {
+			t.Errorf("UpdateEnvField() result missing %q:\n%s", s, updated)
+		}
+	}
+	if strings.Contains(updated, "sk-") {
+		t.Errorf("UpdateEnvField() wrote a key with a helper set:\n%s", updated)
+	}
+
+	updated, err = UpdateEnvField(original, cfg, SyncOptions{PreserveOther: true, OmitKey: true})
+	if err != nil {
+		t.Fatalf("UpdateEnvField() unexpected error: %v", err)
+	}
+	if strings.Contains(updated, "sk-") || strings.Contains(updated, "apiKeyHelper") || !strings.Contains(updated, "https://api.example.com") {
+		t.Errorf("UpdateEnvField() with OmitKey = %s", updated)
+	}
+
+	tests := []struct {
+		name     string
+		original string
+		command  string
+		want     string
+	}{
+		{name: "last", original: `{"theme": "dark", "apiKeyHelper": "/h"}`, command: "/h", want: `{"theme": "dark"}`},
+		{name: "first", original: `{"apiKeyHelper": "/h", "theme": "dark"}`, command: "/h", want: `{ "theme": "dark"}`},
+		{name: "only", original: `{"apiKeyHelper": "/h"}`, command: "/h", want: `{}`},
+		{name: "middle", original: "{\"a\": 1, // c\n\"apiKeyHelper\": \"/h\", \"b\": 2}", command: "/h", want: "{\"a\": 1, \"b\": 2}"},
+		{name: "other helper", original: `{"apiKeyHelper": "/mine"}`, command: "/h", want: `{"apiKeyHelper": "/mine"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RemoveKeyHelper(tt.original, tt.command)
+			if err != nil {
+				t.Fatalf("RemoveKeyHelper() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RemoveKeyHelper() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"apimgr/config/models"
+)
+
+// GenerateDockerEnv generates a docker --env-file. Docker takes values
+// verbatim, so they are written without quotes.
+func GenerateDockerEnv(cfg *models.APIConfig) (string, error) {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("# apimgr configuration: %s\n", cfg.Alias))
+	for _, v := range EnvExports(cfg) {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return "", fmt.Errorf("%s contains a line break, which an env file cannot hold", v.Name)
+		}
+		buf.WriteString(fmt.Sprintf("%s=%s\n", v.Name, v.Value))
+	}
+	return buf.String(), nil
+}
+
+// GenerateK8sSecret generates an Opaque Kubernetes Secret manifest holding
+// the config's variables, for use with envFrom. An empty name is derived
+// from the alias.
+func GenerateK8sSecret(cfg *models.APIConfig, name string) (string, error) {
+	if name == "" {
+		name = K8sSecretName(cfg.Alias)
+	}
+	if !k8sNamePattern.MatchString(name) || len(name) > 253 {
+		return "", fmt.Errorf("invalid secret name '%s': use lower case letters, digits, '-' and '.'", name)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("apiVersion: v1\n")
+	buf.WriteString("kind: Secret\n")
+	buf.WriteString("metadata:\n")
+	buf.WriteString(fmt.Sprintf("  name: %s\n", name))
+	buf.WriteString("  labels:\n")
+	buf.WriteString("    app.kubernetes.io/managed-by: apimgr\n")
+	buf.WriteString("type: Opaque\n")
+	buf.WriteString("data:\n")
+	for _, v := range EnvExports(cfg) {
+		buf.WriteString(fmt.Sprintf("  %s: %s\n", v.Name, base64.StdEncoding.EncodeToString([]byte(v.Value))))
+	}
+	return buf.String(), nil
+}
+
+// k8sNamePattern matches a DNS subdomain name, required for Secret names
+var k8sNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// K8sSecretName derives a valid Secret name from an alias, e.g.
+// "My_Relay" becomes "apimgr-my-relay"
+func K8sSecretName(alias string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(alias) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+	if name == "" {
+		return "apimgr"
+	}
+	return "apimgr-" + name
+}
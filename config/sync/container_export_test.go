@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestGenerateDockerEnv(t *testing.T) {
+	cfg := &models.APIConfig{Alias: "relay", APIKey: "sk-test", BaseURL: "https://relay.example.com", Model: "claude-sonnet-4"}
+
+	got, err := GenerateDockerEnv(cfg)
+	if err != nil {
+		t.Fatalf("GenerateDockerEnv() unexpected error: %v", err)
+	}
+	for _, line := range []string{"ANTHROPIC_API_KEY=sk-test\n", "ANTHROPIC_BASE_URL=https://relay.example.com\n", "ANTHROPIC_MODEL=claude-sonnet-4\n", "APIMGR_ACTIVE=relay\n"} {
+		if !strings.Contains(got, line) {
+			t.Errorf("GenerateDockerEnv() missing %q in:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, `"`) {
+		t.Errorf("GenerateDockerEnv() should not quote values:\n%s", got)
+	}
+
+	cfg.Model = "bad\nmodel"
+	if _, err := GenerateDockerEnv(cfg); err == nil {
+		t.Error("GenerateDockerEnv() expected error for value with a line break")
+	}
+}
+
+func TestGenerateK8sSecret(t *testing.T) {
+	cfg := &models.APIConfig{Alias: "My_Relay", Provider: "openai", APIKey: "sk-test"}
+
+	tests := []struct {
+		name     string
+		secret   string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "derived name", wantName: "name: apimgr-my-relay\n"},
+		{name: "explicit name", secret: "claude-creds", wantName: "name: claude-creds\n"},
+		{name: "invalid name", secret: "Bad_Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateK8sSecret(cfg, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateK8sSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// base64 of "sk-test"
+			for _, want := range []string{"kind: Secret\n", tt.wantName, "  OPENAI_API_KEY: c2stdGVzdA==\n"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("GenerateK8sSecret() missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
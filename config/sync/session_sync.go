@@ -3,11 +3,141 @@ package sync
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"apimgr/config/models"
+	"apimgr/internal/providers"
+	"apimgr/internal/utils"
 )
 
+// Mutual TLS client certificate paths, read by Claude Code
+const (
+	ClientCertEnvVar = "CLAUDE_CODE_CLIENT_CERT"
+	ClientKeyEnvVar  = "CLAUDE_CODE_CLIENT_KEY"
+)
+
+// CustomHeadersEnvVar holds extra request headers for Claude Code, one
+// "Name: Value" per line
+const CustomHeadersEnvVar = "ANTHROPIC_CUSTOM_HEADERS"
+
+// CustomHeaders returns the anthropic-version and anthropic-beta headers of
+// a config in the format of CustomHeadersEnvVar, empty when it sets neither
+func CustomHeaders(cfg *models.APIConfig) string {
+	var lines []string
+	if cfg.APIVersion != "" {
+		lines = append(lines, "anthropic-version: "+cfg.APIVersion)
+	}
+	if len(cfg.BetaFeatures) > 0 {
+		lines = append(lines, "anthropic-beta: "+strings.Join(cfg.BetaFeatures, ","))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Generation parameters of a config, for scripts and tools that read them
+// from the environment
+const (
+	TemperatureEnvVar = "APIMGR_TEMPERATURE"
+	MaxTokensEnvVar   = "APIMGR_MAX_TOKENS"
+	TopPEnvVar        = "APIMGR_TOP_P"
+)
+
+// Request settings of Claude Code, synced into its settings on switch
+const (
+	APITimeoutEnvVar = "API_TIMEOUT_MS"
+	MaxRetriesEnvVar = "CLAUDE_CODE_MAX_RETRIES"
+)
+
+// RequestSettings returns the API_TIMEOUT_MS and CLAUDE_CODE_MAX_RETRIES
+// values a config sets
+func RequestSettings(cfg *models.APIConfig) []EnvVar {
+	var vars []EnvVar
+	if timeout, err := time.ParseDuration(cfg.APITimeout); err == nil && timeout > 0 {
+		vars = append(vars, EnvVar{APITimeoutEnvVar, strconv.FormatInt(timeout.Milliseconds(), 10)})
+	}
+	if cfg.MaxRetries != nil {
+		vars = append(vars, EnvVar{MaxRetriesEnvVar, strconv.Itoa(*cfg.MaxRetries)})
+	}
+	return vars
+}
+
+// EnvVar is a single environment variable assignment
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvUnsetNames returns the variables to clear before exporting a config,
+// covering every provider so switching between providers leaves nothing stale
+func EnvUnsetNames() []string {
+	return append(providers.AllEnvVarNames(), CustomHeadersEnvVar, ClientCertEnvVar, ClientKeyEnvVar,
+		APITimeoutEnvVar, MaxRetriesEnvVar, TemperatureEnvVar, MaxTokensEnvVar, TopPEnvVar, "APIMGR_ACTIVE")
+}
+
+// EnvExports returns the variables to export for a config, named after the
+// config's provider (ANTHROPIC_* for anthropic and unknown providers)
+func EnvExports(cfg *models.APIConfig) []EnvVar {
+	names := providers.EnvVarsFor(cfg.Provider)
+
+	var vars []EnvVar
+	if cfg.APIKey != "" {
+		vars = append(vars, EnvVar{names.APIKey, cfg.APIKey})
+	} else if cfg.AuthToken != "" {
+		vars = append(vars, EnvVar{names.AuthToken, cfg.AuthToken})
+	}
+	if cfg.BaseURL != "" {
+		vars = append(vars, EnvVar{names.BaseURL, cfg.BaseURL})
+	}
+	if cfg.Model != "" {
+		vars = append(vars, EnvVar{names.Model, cfg.Model})
+	}
+	if cfg.OrgID != "" && names.OrgID != "" {
+		vars = append(vars, EnvVar{names.OrgID, cfg.OrgID})
+	}
+	if cfg.ProjectID != "" && names.ProjectID != "" {
+		vars = append(vars, EnvVar{names.ProjectID, cfg.ProjectID})
+	}
+	if headers := CustomHeaders(cfg); headers != "" {
+		vars = append(vars, EnvVar{CustomHeadersEnvVar, headers})
+	}
+	if cfg.ClientCert != "" {
+		vars = append(vars, EnvVar{ClientCertEnvVar, cfg.ClientCert}, EnvVar{ClientKeyEnvVar, cfg.ClientKey})
+	}
+	vars = append(vars, RequestSettings(cfg)...)
+	if cfg.Temperature != nil {
+		vars = append(vars, EnvVar{TemperatureEnvVar, strconv.FormatFloat(*cfg.Temperature, 'f', -1, 64)})
+	}
+	if cfg.MaxTokens > 0 {
+		maxTokens := strconv.Itoa(cfg.MaxTokens)
+		if names.MaxTokens != "" {
+			vars = append(vars, EnvVar{names.MaxTokens, maxTokens})
+		}
+		vars = append(vars, EnvVar{MaxTokensEnvVar, maxTokens})
+	}
+	if cfg.TopP != nil {
+		vars = append(vars, EnvVar{TopPEnvVar, strconv.FormatFloat(*cfg.TopP, 'f', -1, 64)})
+	}
+	vars = append(vars, EnvVar{"APIMGR_ACTIVE", cfg.Alias})
+	return vars
+}
+
+// GenerateEnvCommands generates the unset and export commands printed for
+// eval by switch and load-active
+func GenerateEnvCommands(cfg *models.APIConfig) string {
+	var buf strings.Builder
+	for _, name := range EnvUnsetNames() {
+		buf.WriteString(fmt.Sprintf("unset %s\n", name))
+	}
+	if cfg == nil {
+		return buf.String()
+	}
+	for _, v := range EnvExports(cfg) {
+		buf.WriteString(fmt.Sprintf("export %s=%s\n", v.Name, ShellQuote(v.Value)))
+	}
+	return buf.String()
+}
+
 // GenerateEnvScript generates environment variable script content
 func GenerateEnvScript(cfg *models.APIConfig) string {
 	var buf strings.Builder
@@ -18,30 +148,63 @@ func GenerateEnvScript(cfg *models.APIConfig) string {
 
 	// Clear old environment variables
 	buf.WriteString("# Clear previously set environment variables\n")
-	buf.WriteString("unset ANTHROPIC_API_KEY\n")
-	buf.WriteString("unset ANTHROPIC_AUTH_TOKEN\n")
-	buf.WriteString("unset ANTHROPIC_BASE_URL\n")
-	buf.WriteString("unset ANTHROPIC_MODEL\n")
-	buf.WriteString("unset APIMGR_ACTIVE\n\n")
+	for _, name := range EnvUnsetNames() {
+		buf.WriteString(fmt.Sprintf("unset %s\n", name))
+	}
+	buf.WriteString("\n")
 
 	// Set new environment variables
 	buf.WriteString("# Set new environment variables\n")
-	if cfg.APIKey != "" {
-		buf.WriteString(fmt.Sprintf("export ANTHROPIC_API_KEY=%q\n", cfg.APIKey))
-	} else if cfg.AuthToken != "" {
-		buf.WriteString(fmt.Sprintf("export ANTHROPIC_AUTH_TOKEN=%q\n", cfg.AuthToken))
+	for _, v := range EnvExports(cfg) {
+		buf.WriteString(fmt.Sprintf("export %s=%s\n", v.Name, quoteEnvValue(v.Value)))
 	}
-	if cfg.BaseURL != "" {
-		buf.WriteString(fmt.Sprintf("export ANTHROPIC_BASE_URL=%q\n", cfg.BaseURL))
-	}
-	if cfg.Model != "" {
-		buf.WriteString(fmt.Sprintf("export ANTHROPIC_MODEL=%q\n", cfg.Model))
-	}
-	buf.WriteString(fmt.Sprintf("export APIMGR_ACTIVE=%q\n", cfg.Alias))
 
 	return buf.String()
 }
 
+// quoteEnvValue quotes a value like strconv.Quote, except that the newlines
+// of a multi-line value are kept, since a shell does not expand \n in double
+// quotes
+func quoteEnvValue(value string) string {
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		quoted := strconv.Quote(line)
+		lines[i] = quoted[1 : len(quoted)-1]
+	}
+	return `"` + strings.Join(lines, "\n") + `"`
+}
+
+// ParseEnvScript returns the variables exported by a script GenerateEnvScript
+// generated
+func ParseEnvScript(script string) map[string]string {
+	env := make(map[string]string)
+	lines := strings.Split(script, "\n")
+	for i := 0; i < len(lines); i++ {
+		line, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), "export ")
+		if !ok {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		// A multi-line value continues until its closing quote
+		quoted, end := value, i
+		for j := i + 1; j < len(lines) && strings.HasPrefix(value, `"`); j++ {
+			if _, err := strconv.Unquote(quoted); err == nil {
+				break
+			}
+			quoted += `\n` + lines[j]
+			end = j
+		}
+		if unquoted, err := strconv.Unquote(quoted); err == nil {
+			value, i = unquoted, end
+		}
+		env[name] = value
+	}
+	return env
+}
+
 // showEnvChanges displays the changes between old and new env maps
 func showEnvChanges(oldEnv, newEnv map[string]interface{}) {
 	// Create a map of all variables
@@ -101,6 +264,14 @@ func showEnvChanges(oldEnv, newEnv map[string]interface{}) {
 		if status == "preserved" {
 			newValStr = "(unchanged)"
 		}
+		if utils.IsSecretName(varName) {
+			if oldExists {
+				oldValStr = utils.MaskAPIKey(oldValStr)
+			}
+			if newExists && status != "preserved" {
+				newValStr = utils.MaskAPIKey(newValStr)
+			}
+		}
 
 		// Truncate long strings for table display
 		const maxLen = 20
@@ -169,6 +340,8 @@ func generateDiffReport(originalContent, updatedContent string) (string, error)
 		newValStr := fmt.Sprintf("%v", newVal)
 		if !newExists {
 			newValStr = "(deleted)"
+		} else if utils.IsSecretName(varName) {
+			newValStr = utils.MaskAPIKey(newValStr)
 		}
 
 		// Truncate long strings for table display
@@ -0,0 +1,163 @@
+package sync
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestCustomHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  models.APIConfig
+		want string
+	}{
+		{"none", models.APIConfig{}, ""},
+		{"version", models.APIConfig{APIVersion: "2024-01-01"}, "anthropic-version: 2024-01-01"},
+		{"both", models.APIConfig{APIVersion: "2024-01-01", BetaFeatures: []string{"a-1", "b-2"}}, "anthropic-version: 2024-01-01\nanthropic-beta: a-1,b-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CustomHeaders(&tt.cfg); got != tt.want {
+				t.Errorf("CustomHeaders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvScriptRoundTrip(t *testing.T) {
+	cfg := &models.APIConfig{
+		Alias:        "relay",
+		APIKey:       "sk-relay",
+		BaseURL:      "https://relay.example.com",
+		APIVersion:   "2024-01-01",
+		BetaFeatures: []string{"beta-1"},
+	}
+
+	script := GenerateEnvScript(cfg)
+	// The shell gets a real line break, not \n
+	if !strings.Contains(script, "export ANTHROPIC_CUSTOM_HEADERS=\"anthropic-version: 2024-01-01\nanthropic-beta: beta-1\"\n") {
+		t.Errorf("GenerateEnvScript() headers not exported on two lines:\n%s", script)
+	}
+
+	env := ParseEnvScript(script)
+	for _, v := range EnvExports(cfg) {
+		if env[v.Name] != v.Value {
+			t.Errorf("ParseEnvScript()[%s] = %q, want %q", v.Name, env[v.Name], v.Value)
+		}
+	}
+	if len(env) != len(EnvExports(cfg)) {
+		t.Errorf("ParseEnvScript() = %v, want only the exports", env)
+	}
+}
+
+func TestEnvCommandsQuoteValues(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	// Values of shared team, include or import files reach the eval'd output
+	model := "a\"b$(touch x)`touch y`'c $HOME"
+	cfg := &models.APIConfig{Alias: "relay", APIKey: "sk-relay", Model: model}
+
+	cmd := exec.Command(sh, "-c", GenerateEnvCommands(cfg)+`printf %s "$ANTHROPIC_MODEL"`)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("eval of GenerateEnvCommands() failed: %v\n%s", err, out)
+	}
+	if string(out) != model {
+		t.Errorf("ANTHROPIC_MODEL = %q, want %q", out, model)
+	}
+	for _, name := range []string{"x", "y"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("eval of GenerateEnvCommands() ran a command substitution creating %s", name)
+		}
+	}
+}
+
+func TestEnvExportsScope(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     map[string]string
+	}{
+		{"openai", map[string]string{"OPENAI_ORG_ID": "org-1", "OPENAI_PROJECT_ID": "proj_1"}},
+		{"gemini", map[string]string{"GOOGLE_CLOUD_PROJECT": "proj_1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &models.APIConfig{Alias: "a", Provider: tt.provider, APIKey: "sk", OrgID: "org-1", ProjectID: "proj_1"}
+			got := make(map[string]string)
+			for _, v := range EnvExports(cfg) {
+				if strings.Contains(v.Name, "ORG") || strings.Contains(v.Name, "PROJECT") {
+					got[v.Name] = v.Value
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("EnvExports() scope variables = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("EnvExports()[%s] = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvExportsGeneration(t *testing.T) {
+	temperature := 0.2
+	tests := []struct {
+		provider string
+		want     map[string]string
+	}{
+		{"anthropic", map[string]string{"APIMGR_TEMPERATURE": "0.2", "APIMGR_MAX_TOKENS": "4096", "CLAUDE_CODE_MAX_OUTPUT_TOKENS": "4096"}},
+		{"openai", map[string]string{"APIMGR_TEMPERATURE": "0.2", "APIMGR_MAX_TOKENS": "4096"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &models.APIConfig{Alias: "a", Provider: tt.provider, APIKey: "sk", Temperature: &temperature, MaxTokens: 4096}
+			got := make(map[string]string)
+			for _, v := range EnvExports(cfg) {
+				if strings.Contains(v.Name, "TEMPERATURE") || strings.Contains(v.Name, "TOKENS") || strings.Contains(v.Name, "TOP_P") {
+					got[v.Name] = v.Value
+				}
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("EnvExports() generation variables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestSettings(t *testing.T) {
+	zero, five := 0, 5
+	tests := []struct {
+		name string
+		cfg  models.APIConfig
+		want []EnvVar
+	}{
+		{"unset", models.APIConfig{}, nil},
+		{"timeout", models.APIConfig{APITimeout: "10m"}, []EnvVar{{APITimeoutEnvVar, "600000"}}},
+		{"no retries", models.APIConfig{MaxRetries: &zero}, []EnvVar{{MaxRetriesEnvVar, "0"}}},
+		{"both", models.APIConfig{APITimeout: "1.5s", MaxRetries: &five}, []EnvVar{{APITimeoutEnvVar, "1500"}, {MaxRetriesEnvVar, "5"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestSettings(&tt.cfg); !slices.Equal(got, tt.want) {
+				t.Errorf("RequestSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"apimgr/config/models"
+)
+
+// Shell syntaxes of the commands load-active prints
+const (
+	ShellPOSIX      = "posix" // bash, zsh and other POSIX shells
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// DetectShell returns the syntax of a shell given its path or name, such as
+// $SHELL: fish, powershell for pwsh and powershell, posix otherwise
+func DetectShell(path string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".exe")
+	switch name {
+	case "fish":
+		return ShellFish
+	case "pwsh", "powershell":
+		return ShellPowerShell
+	default:
+		return ShellPOSIX
+	}
+}
+
+// ParseShell returns the syntax of a shell named with --shell
+func ParseShell(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "bash", "zsh", "sh", ShellPOSIX:
+		return ShellPOSIX, nil
+	case ShellFish:
+		return ShellFish, nil
+	case "pwsh", ShellPowerShell:
+		return ShellPowerShell, nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish, powershell)", name)
+	}
+}
+
+// GenerateShellEnvCommands returns the lines of GenerateEnvCommands in the
+// syntax of shell: the variables of every provider are cleared, then the
+// config's are set. A nil cfg only clears them.
+func GenerateShellEnvCommands(cfg *models.APIConfig, shell string) string {
+	if shell == ShellPOSIX {
+		return GenerateEnvCommands(cfg)
+	}
+
+	var buf strings.Builder
+	for _, name := range EnvUnsetNames() {
+		switch shell {
+		case ShellFish:
+			buf.WriteString(fmt.Sprintf("set -e %s\n", name))
+		case ShellPowerShell:
+			buf.WriteString(fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name))
+		}
+	}
+	if cfg == nil {
+		return buf.String()
+	}
+	for _, v := range EnvExports(cfg) {
+		switch shell {
+		case ShellFish:
+			buf.WriteString(fmt.Sprintf("set -gx %s %s\n", v.Name, fishQuote(v.Value)))
+		case ShellPowerShell:
+			buf.WriteString(fmt.Sprintf("$env:%s = %s\n", v.Name, powerShellQuote(v.Value)))
+		}
+	}
+	return buf.String()
+}
+
+// ShellSetVar returns the line setting the unexported shell variable name
+func ShellSetVar(shell, name, value string) string {
+	switch shell {
+	case ShellFish:
+		return fmt.Sprintf("set -g %s %s\n", name, fishQuote(value))
+	case ShellPowerShell:
+		return fmt.Sprintf("$global:%s = %s\n", name, powerShellQuote(value))
+	default:
+		return fmt.Sprintf("%s=%s\n", name, ShellQuote(value))
+	}
+}
+
+// fishQuote single-quotes s for fish, where \ and ' are escaped inside
+// single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// powerShellQuote single-quotes s for PowerShell, where ' is doubled
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestGenerateShellEnvCommands(t *testing.T) {
+	cfg := &models.APIConfig{Alias: "relay", APIKey: "sk-it's", BaseURL: `https://relay.example.com\x`}
+
+	tests := []struct {
+		shell string
+		want  []string // Lines the output must contain
+	}{
+		{ShellPOSIX, []string{"unset ANTHROPIC_API_KEY\n", "export APIMGR_ACTIVE='relay'\n"}},
+		{ShellFish, []string{"set -e ANTHROPIC_API_KEY\n", `set -gx ANTHROPIC_API_KEY 'sk-it\'s'` + "\n", `set -gx ANTHROPIC_BASE_URL 'https://relay.example.com\\x'` + "\n"}},
+		{ShellPowerShell, []string{"Remove-Item Env:ANTHROPIC_API_KEY -ErrorAction SilentlyContinue\n", "$env:ANTHROPIC_API_KEY = 'sk-it''s'\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got := GenerateShellEnvCommands(cfg, tt.shell)
+			for _, line := range tt.want {
+				if !strings.Contains(got, line) {
+					t.Errorf("GenerateShellEnvCommands() missing %q in:\n%s", line, got)
+				}
+			}
+			if cleared := GenerateShellEnvCommands(nil, tt.shell); strings.Contains(cleared, "relay") || cleared == "" {
+				t.Errorf("GenerateShellEnvCommands(nil) = %q, want only clearing lines", cleared)
+			}
+		})
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"bash", ShellPOSIX, false},
+		{"zsh", ShellPOSIX, false},
+		{"fish", ShellFish, false},
+		{"pwsh", ShellPowerShell, false},
+		{"PowerShell", ShellPowerShell, false},
+		{"tcsh", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseShell(tt.name)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("ParseShell(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"apimgr/config/models"
+	syncpkg "apimgr/config/sync"
+)
+
+// ClaudeConfigDirEnv overrides the directory Claude Code reads its user
+// settings from, ~/.claude when unset
+const ClaudeConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// SyncTarget is a Claude Code settings file written on switch
+type SyncTarget struct {
+	Name      string // SyncTargetClaude, SyncTargetProject or a name declared in the targets section
+	Path      string // Expanded settings file path
+	Committed bool   // A project's settings.json, which is under version control
+}
+
+// keyOptions returns how a key is written into the target: a committed
+// settings file never holds a key in clear, only a key helper reference
+func (t SyncTarget) keyOptions(opts syncpkg.SyncOptions) syncpkg.SyncOptions {
+	if t.Committed && opts.KeyHelper == "" {
+		opts.OmitKey = true
+	}
+	return opts
+}
+
+// ClaudeSettingsPath returns the Claude Code user settings file, honoring
+// CLAUDE_CONFIG_DIR
+func ClaudeSettingsPath() string {
+	if dir := os.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Join(expandTargetPath(dir), "settings.json")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "settings.json")
+}
+
+// SyncTargets returns the settings files written on switch
+func (cm *Manager) SyncTargets() ([]SyncTarget, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return syncTargets(configFile), nil
+}
+
+// syncTargets returns the targets enabled by defaults.sync_targets: the
+// built-in claude target, every declared one and the enabled projects when it
+// is unset. A declared claude target replaces the built-in path.
+func syncTargets(configFile *models.File) []SyncTarget {
+	paths := map[string]string{SyncTargetClaude: ClaudeSettingsPath()}
+	for name, path := range configFile.Targets {
+		if name != SyncTargetNone && name != SyncTargetProject && path != "" {
+			paths[name] = expandTargetPath(path)
+		}
+	}
+
+	var targets []SyncTarget
+	for _, name := range slices.Sorted(maps.Keys(paths)) {
+		if syncTargetEnabled(configFile.Defaults, name) {
+			targets = append(targets, SyncTarget{Name: name, Path: paths[name]})
+		}
+	}
+	if syncTargetEnabled(configFile.Defaults, SyncTargetProject) {
+		for _, project := range configFile.Projects {
+			targets = append(targets, SyncTarget{Name: SyncTargetProject, Path: projectSettingsPath(project), Committed: !project.Local})
+		}
+	}
+	if configFile.Defaults != nil {
+		for _, name := range configFile.Defaults.SyncTargets {
+			if _, ok := paths[name]; !ok && name != SyncTargetNone {
+				slog.Debug("sync target is not declared, skipping", "target", name)
+			}
+		}
+	}
+	return targets
+}
+
+// checkSyncTargets returns an error when defaults.sync_targets names a
+// target the targets section does not declare
+func checkSyncTargets(configFile *models.File) error {
+	for _, reserved := range []string{SyncTargetNone, SyncTargetProject} {
+		if _, ok := configFile.Targets[reserved]; ok {
+			return fmt.Errorf("'%s' cannot be declared as a sync target", reserved)
+		}
+	}
+	if configFile.Defaults == nil {
+		return nil
+	}
+	available := []string{SyncTargetClaude, SyncTargetNone, SyncTargetProject}
+	for _, name := range slices.Sorted(maps.Keys(configFile.Targets)) {
+		if name != SyncTargetClaude {
+			available = append(available, name)
+		}
+	}
+	for _, name := range configFile.Defaults.SyncTargets {
+		if !slices.Contains(available, name) {
+			return fmt.Errorf("unknown sync target '%s' (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
+
+// expandTargetPath expands a leading ~ and environment variables in a
+// settings file path
+func expandTargetPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[1:])
+	}
+	return path
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestSyncTargets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+	t.Setenv("WORK_DIR", "/srv/work")
+
+	declared := map[string]string{
+		"local":   "~/.claude/settings.local.json",
+		"work":    "$WORK_DIR/settings.json",
+		"managed": "/etc/claude-code/managed-settings.json",
+	}
+	user := filepath.Join(home, ".claude", "settings.json")
+
+	tests := []struct {
+		name string
+		file *models.File
+		want []SyncTarget
+	}{
+		{name: "default", file: &models.File{}, want: []SyncTarget{{Name: "claude", Path: user}}},
+		{name: "every declared target", file: &models.File{Targets: declared}, want: []SyncTarget{
+			{Name: "claude", Path: user},
+			{Name: "local", Path: filepath.Join(home, ".claude", "settings.local.json")},
+			{Name: "managed", Path: "/etc/claude-code/managed-settings.json"},
+			{Name: "work", Path: "/srv/work/settings.json"},
+		}},
+		{name: "selected", file: &models.File{Targets: declared, Defaults: &models.Defaults{SyncTargets: []string{"work"}}}, want: []SyncTarget{
+			{Name: "work", Path: "/srv/work/settings.json"},
+		}},
+		{name: "claude overridden", file: &models.File{Targets: map[string]string{"claude": "/opt/claude/settings.json"}}, want: []SyncTarget{
+			{Name: "claude", Path: "/opt/claude/settings.json"},
+		}},
+		{name: "none", file: &models.File{Targets: declared, Defaults: &models.Defaults{SyncTargets: []string{"none"}}}, want: nil},
+		{name: "projects", file: &models.File{Projects: []models.Project{{Root: "/srv/app"}, {Root: "/srv/lib", Local: true}}}, want: []SyncTarget{
+			{Name: "claude", Path: user},
+			{Name: "project", Path: "/srv/app/.claude/settings.json", Committed: true},
+			{Name: "project", Path: "/srv/lib/.claude/settings.local.json"},
+		}},
+		{name: "projects not selected", file: &models.File{Projects: []models.Project{{Root: "/srv/app"}}, Defaults: &models.Defaults{SyncTargets: []string{"claude"}}}, want: []SyncTarget{
+			{Name: "claude", Path: user},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncTargets(tt.file); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("syncTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaudeSettingsPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv(ClaudeConfigDirEnv, "")
+	if got, want := ClaudeSettingsPath(), filepath.Join(home, ".claude", "settings.json"); got != want {
+		t.Errorf("ClaudeSettingsPath() = %q, want %q", got, want)
+	}
+	t.Setenv(ClaudeConfigDirEnv, "~/.claude-work")
+	if got, want := ClaudeSettingsPath(), filepath.Join(home, ".claude-work", "settings.json"); got != want {
+		t.Errorf("ClaudeSettingsPath() with %s = %q, want %q", ClaudeConfigDirEnv, got, want)
+	}
+}
+
+func TestSyncClaudeSettingsTargets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	cm := setupTestConfig(t)
+	user := filepath.Join(home, ".claude", "settings.json")
+	local := filepath.Join(home, ".claude", "settings.local.json")
+	missing := filepath.Join(home, "missing", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(user), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{user, local} {
+		if err := os.WriteFile(path, []byte(`{"env": {}}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configFile := &models.File{
+		Targets: map[string]string{"local": local, "missing": missing},
+		Configs: []models.APIConfig{{Alias: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com"}},
+	}
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+
+	if err := cm.syncClaudeSettings(&configFile.Configs[0]); err != nil {
+		t.Fatalf("syncClaudeSettings() unexpected error: %v", err)
+	}
+	for _, path := range []string{user, local} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "https://relay.example.com") {
+			t.Errorf("%s was not synced: %s", path, data)
+		}
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("missing target was created, stat error = %v", err)
+	}
+
+	if err := cm.clearGlobalClaudeSettings(); err != nil {
+		t.Fatalf("clearGlobalClaudeSettings() unexpected error: %v", err)
+	}
+	for _, path := range []string{user, local} {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "https://relay.example.com") {
+			t.Errorf("%s was not cleared: %s", path, data)
+		}
+	}
+}
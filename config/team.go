@@ -0,0 +1,272 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/config/storage"
+	"apimgr/internal/exitcode"
+)
+
+// TeamFileName is the team file in the config directory when team.file is
+// unset
+const TeamFileName = "team.json"
+
+// maxTeamFileSize bounds the download of a team file
+const maxTeamFileSize = 10 << 20
+
+// teamHTTPClient downloads team files
+var teamHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// teamPath returns the path of the team file
+func (cm *Manager) teamPath(team *models.Team) string {
+	if team.File == "" {
+		return filepath.Join(filepath.Dir(cm.configPath), TeamFileName)
+	}
+	return cm.includePath(team.File)
+}
+
+// parseTeamFile parses the content of a team file, which holds configs
+// without keys
+func parseTeamFile(path string, data []byte) ([]models.APIConfig, error) {
+	teamFile, err := parseConfigData(storage.FormatFromPath(path), data)
+	if err != nil {
+		return nil, fmt.Errorf("team file %s: %w", path, err)
+	}
+	if len(teamFile.Include) > 0 || teamFile.Team != nil {
+		return nil, fmt.Errorf("team file %s cannot include other files", path)
+	}
+
+	seen := make(map[string]bool, len(teamFile.Configs))
+	for _, cfg := range teamFile.Configs {
+		if seen[cfg.Alias] {
+			return nil, fmt.Errorf("team file %s defines configuration '%s' more than once", path, cfg.Alias)
+		}
+		seen[cfg.Alias] = true
+		if cfg.APIKey != "" || cfg.AuthToken != "" || len(cfg.APIKeys) > 0 {
+			return nil, fmt.Errorf("team file %s must not hold keys, configuration '%s' sets one", path, cfg.Alias)
+		}
+	}
+	return teamFile.Configs, nil
+}
+
+// readTeamFile reads a team file. A missing one, not pulled yet, holds no
+// configs.
+func readTeamFile(path string) ([]models.APIConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team file: %w", err)
+	}
+	return parseTeamFile(path, data)
+}
+
+// loadTeam overlays the configs of configFile on those of its team file,
+// merged by alias: the fields a local config sets override the team's, and
+// team configs without a local one are added as they are
+func (cm *Manager) loadTeam(configFile *models.File) error {
+	if configFile.Team == nil {
+		return nil
+	}
+	path := cm.teamPath(configFile.Team)
+	teamConfigs, err := readTeamFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teamConfigs {
+		i := slices.IndexFunc(configFile.Configs, func(c models.APIConfig) bool { return c.Alias == team.Alias })
+		switch {
+		case i < 0:
+			configFile.Configs = append(configFile.Configs, team.Clone())
+		case configFile.Configs[i].Source != "":
+			return fmt.Errorf("configuration '%s' is defined in both %s and %s", team.Alias, configFile.Configs[i].Source, path)
+		default:
+			local := &configFile.Configs[i]
+			inherit(local, team.Clone())
+			if local.Extends == "" {
+				local.Extends = team.Extends
+			}
+		}
+	}
+	configFile.TeamConfigs = teamConfigs
+	return nil
+}
+
+// teamConfig returns the team file's config with the given alias
+func teamConfig(configFile *models.File, alias string) (models.APIConfig, bool) {
+	i := slices.IndexFunc(configFile.TeamConfigs, func(c models.APIConfig) bool { return c.Alias == alias })
+	if i < 0 {
+		return models.APIConfig{}, false
+	}
+	return configFile.TeamConfigs[i], true
+}
+
+// checkNotTeam refuses removing or renaming a config of the team file,
+// which would come back on the next load
+func (cm *Manager) checkNotTeam(configFile *models.File, alias string) error {
+	if _, ok := teamConfig(configFile, alias); ok {
+		return exitcode.New(exitcode.Usage, "configuration '%s' is defined by the team file %s, change it there", alias, cm.teamPath(configFile.Team))
+	}
+	return nil
+}
+
+// localConfigs returns the configs to store in the main file: configs of
+// the team file keep only the fields set locally, and are left out when
+// nothing is
+func localConfigs(configFile *models.File, configs []models.APIConfig) []models.APIConfig {
+	local := make([]models.APIConfig, 0, len(configs))
+	for _, cfg := range configs {
+		if team, ok := teamConfig(configFile, cfg.Alias); ok {
+			cfg = overrides(cfg, team)
+			if reflect.DeepEqual(cfg, models.APIConfig{Alias: cfg.Alias}) {
+				continue
+			}
+		}
+		local = append(local, cfg)
+	}
+	return local
+}
+
+// overrides returns cfg without the fields holding the team's values
+func overrides(cfg, team models.APIConfig) models.APIConfig {
+	local := reflect.ValueOf(&cfg).Elem()
+	shared := reflect.ValueOf(team)
+	for i := range local.NumField() {
+		if local.Type().Field(i).Name == "Alias" {
+			continue
+		}
+		if reflect.DeepEqual(local.Field(i).Interface(), shared.Field(i).Interface()) {
+			local.Field(i).SetZero()
+		}
+	}
+
+	// Only the variables set or changed locally
+	cfg.Vars = maps.Clone(cfg.Vars)
+	for name, value := range cfg.Vars {
+		if teamValue, ok := team.Vars[name]; ok && teamValue == value {
+			delete(cfg.Vars, name)
+		}
+	}
+	if len(cfg.Vars) == 0 {
+		cfg.Vars = nil
+	}
+	return cfg
+}
+
+// TeamPull is how "apimgr sync team pull" changed the team file
+type TeamPull struct {
+	Path    string   // Team file
+	Added   []string // Aliases of new team configs
+	Changed []string // Aliases of changed team configs
+	Removed []string // Aliases of team configs no longer in the file
+}
+
+// PullTeam refreshes the team file, downloading it from team.url or with a
+// git pull in its directory
+func (cm *Manager) PullTeam() (*TeamPull, error) {
+	if err := checkWritable(); err != nil {
+		return nil, err
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	// The team file is not merged in, an invalid one is what pulling fixes
+	data, _, err := cm.readConfigData()
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := parseConfigData(storage.FormatFromPath(cm.configPath), data)
+	if err != nil {
+		return nil, err
+	}
+	if configFile.Team == nil {
+		return nil, exitcode.New(exitcode.Usage, "no team file is configured, set team in %s", cm.configPath)
+	}
+
+	path := cm.teamPath(configFile.Team)
+	before, _ := readTeamFile(path)
+	if configFile.Team.URL != "" {
+		err = downloadTeamFile(configFile.Team.URL, path)
+	} else {
+		err = gitPull(filepath.Dir(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	after, err := readTeamFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return diffTeam(path, before, after), nil
+}
+
+// downloadTeamFile replaces the team file at path with the one at url,
+// once it proved valid
+func downloadTeamFile(url, path string) error {
+	resp, err := teamHTTPClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to download team file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download team file: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTeamFileSize))
+	if err != nil {
+		return fmt.Errorf("failed to download team file: %w", err)
+	}
+	if _, err := parseTeamFile(path, data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create team file directory: %w", err)
+	}
+	if err := storage.AtomicFileUpdate(path, string(data), nil); err != nil {
+		return fmt.Errorf("failed to write team file: %w", err)
+	}
+	return nil
+}
+
+// gitPull fast-forwards the git checkout holding the team file
+func gitPull(dir string) error {
+	out, err := exec.Command("git", "-C", dir, "pull", "--ff-only").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git pull in %s failed: %w\n%s", dir, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// diffTeam returns how the team configs changed from before to after
+func diffTeam(path string, before, after []models.APIConfig) *TeamPull {
+	pull := &TeamPull{Path: path}
+	for _, cfg := range after {
+		i := slices.IndexFunc(before, func(c models.APIConfig) bool { return c.Alias == cfg.Alias })
+		switch {
+		case i < 0:
+			pull.Added = append(pull.Added, cfg.Alias)
+		case !reflect.DeepEqual(before[i], cfg):
+			pull.Changed = append(pull.Changed, cfg.Alias)
+		}
+	}
+	for _, cfg := range before {
+		if !slices.ContainsFunc(after, func(c models.APIConfig) bool { return c.Alias == cfg.Alias }) {
+			pull.Removed = append(pull.Removed, cfg.Alias)
+		}
+	}
+	return pull
+}
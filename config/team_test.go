@@ -0,0 +1,169 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTeamOverlay(t *testing.T) {
+	cm := setupTestConfig(t)
+	writeTestFile(t, cm, "config.json", `{"active": "relay", "team": {}, "configs": [{"alias": "relay", "api_key": "sk-mine", "model": "m2"}, {"alias": "personal", "api_key": "sk-home", "base_url": "https://home.example.com"}]}`)
+	writeTestFile(t, cm, TeamFileName, `{"configs": [{"alias": "relay", "base_url": "https://relay.example.com", "model": "m1", "models": ["m1", "m2"], "vars": {"region": "us"}}, {"alias": "staging", "base_url": "https://staging.example.com"}]}`)
+
+	relay, err := cm.Get("relay")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if relay.APIKey != "sk-mine" || relay.BaseURL != "https://relay.example.com" || relay.Model != "m2" || relay.Vars["region"] != "us" {
+		t.Errorf("Get() = %+v, want the local key and model over the team's base URL and vars", relay)
+	}
+	configs, err := cm.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(configs) != 3 || configs[2].Alias != "staging" {
+		t.Errorf("List() = %+v, want the team-only staging config last", configs)
+	}
+
+	// Only local overrides are written to the main file
+	if err := cm.UpdatePartial("staging", map[string]string{"api_key": "sk-staging"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	if err := cm.UpdatePartial("relay", map[string]string{"model": "m1"}); err != nil {
+		t.Fatalf("UpdatePartial() unexpected error: %v", err)
+	}
+	main, _ := os.ReadFile(cm.configPath)
+	for _, team := range []string{"relay.example.com", "staging.example.com", "region", `"m1"`} {
+		if strings.Contains(string(main), team) {
+			t.Errorf("config.json = %s, want no %s from the team file", main, team)
+		}
+	}
+	if !strings.Contains(string(main), "sk-staging") {
+		t.Errorf("config.json = %s, want the key of staging", main)
+	}
+
+	// The team file changes under the local overrides
+	writeTestFile(t, cm, TeamFileName, `{"configs": [{"alias": "relay", "base_url": "https://relay2.example.com", "model": "m1"}, {"alias": "staging", "base_url": "https://staging.example.com"}]}`)
+	if relay, err := cm.Get("relay"); err != nil || relay.BaseURL != "https://relay2.example.com" || relay.APIKey != "sk-mine" {
+		t.Errorf("Get() = %+v, %v, want the new team base URL and the local key", relay, err)
+	}
+
+	if err := cm.Remove("staging"); err == nil || !strings.Contains(err.Error(), "defined by the team file") {
+		t.Errorf("Remove() error = %v, want the team config refused", err)
+	}
+	if err := cm.RenameAlias("relay", "other"); err == nil || !strings.Contains(err.Error(), "defined by the team file") {
+		t.Errorf("RenameAlias() error = %v, want the team config refused", err)
+	}
+	if err := cm.Remove("personal"); err != nil {
+		t.Errorf("Remove() of a local config unexpected error: %v", err)
+	}
+}
+
+func TestTeamFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		team    string
+		wantErr string
+	}{
+		{name: "key", team: `{"configs": [{"alias": "relay", "api_key": "sk-shared"}]}`, wantErr: "must not hold keys"},
+		{name: "key pool", team: `{"configs": [{"alias": "relay", "api_keys": ["sk-a"]}]}`, wantErr: "must not hold keys"},
+		{name: "duplicate alias", team: `{"configs": [{"alias": "relay"}, {"alias": "relay"}]}`, wantErr: "more than once"},
+		{name: "include", team: `{"include": ["other.json"], "configs": []}`, wantErr: "cannot include other files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupTestConfig(t)
+			writeTestFile(t, cm, "config.json", `{"team": {"file": "shared/team.json"}, "configs": []}`)
+			os.MkdirAll(filepath.Join(filepath.Dir(cm.configPath), "shared"), 0700)
+			writeTestFile(t, cm, "shared/team.json", tt.team)
+			if _, err := cm.List(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("List() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPullTeamURL(t *testing.T) {
+	content := `{"configs": [{"alias": "relay", "base_url": "https://relay.example.com"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	cm := setupTestConfig(t)
+	writeTestFile(t, cm, "config.json", `{"team": {"url": "`+server.URL+`"}, "configs": [{"alias": "relay", "api_key": "sk-mine"}]}`)
+
+	pull, err := cm.PullTeam()
+	if err != nil {
+		t.Fatalf("PullTeam() unexpected error: %v", err)
+	}
+	if !slices.Equal(pull.Added, []string{"relay"}) || len(pull.Changed)+len(pull.Removed) != 0 {
+		t.Errorf("PullTeam() = %+v, want relay added", pull)
+	}
+	if relay, err := cm.Get("relay"); err != nil || relay.BaseURL != "https://relay.example.com" {
+		t.Errorf("Get() = %+v, %v, want the pulled base URL", relay, err)
+	}
+
+	content = `{"configs": [{"alias": "relay", "base_url": "https://relay2.example.com"}, {"alias": "new"}]}`
+	if pull, err := cm.PullTeam(); err != nil || !slices.Equal(pull.Changed, []string{"relay"}) || !slices.Equal(pull.Added, []string{"new"}) {
+		t.Errorf("PullTeam() = %+v, %v, want relay changed and new added", pull, err)
+	}
+
+	// A team file holding keys is not written
+	content = `{"configs": [{"alias": "relay", "api_key": "sk-leaked"}]}`
+	if _, err := cm.PullTeam(); err == nil || !strings.Contains(err.Error(), "must not hold keys") {
+		t.Errorf("PullTeam() error = %v, want the keys refused", err)
+	}
+	if team, _ := os.ReadFile(filepath.Join(filepath.Dir(cm.configPath), TeamFileName)); strings.Contains(string(team), "sk-leaked") {
+		t.Errorf("team.json = %s, want the previous content", team)
+	}
+}
+
+func TestPullTeamGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	cm := setupTestConfig(t)
+	origin := filepath.Join(t.TempDir(), "origin")
+	os.MkdirAll(origin, 0700)
+	git(origin, "init", "-q")
+	os.WriteFile(filepath.Join(origin, "team.json"), []byte(`{"configs": [{"alias": "relay"}]}`), 0600)
+	git(origin, "add", "team.json")
+	git(origin, "commit", "-qm", "Add relay")
+	checkout := filepath.Join(filepath.Dir(cm.configPath), "team")
+	git(filepath.Dir(checkout), "clone", "-q", origin, checkout)
+
+	writeTestFile(t, cm, "config.json", `{"team": {"file": "team/team.json"}, "configs": []}`)
+	os.WriteFile(filepath.Join(origin, "team.json"), []byte(`{"configs": [{"alias": "relay"}, {"alias": "staging"}]}`), 0600)
+	git(origin, "commit", "-qam", "Add staging")
+
+	pull, err := cm.PullTeam()
+	if err != nil {
+		t.Fatalf("PullTeam() unexpected error: %v", err)
+	}
+	if !slices.Equal(pull.Added, []string{"staging"}) {
+		t.Errorf("PullTeam() = %+v, want staging added", pull)
+	}
+}
+
+func TestPullTeamNotConfigured(t *testing.T) {
+	cm := setupTestConfig(t)
+	writeTestFile(t, cm, "config.json", `{"configs": []}`)
+	if _, err := cm.PullTeam(); err == nil || !strings.Contains(err.Error(), "no team file") {
+		t.Errorf("PullTeam() error = %v, want no team file configured", err)
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"slices"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+// DefaultTrashDays is how long removed configs stay in the trash when
+// defaults.trash_days is unset
+const DefaultTrashDays = 30
+
+// trashRetention returns how long removed configs stay in the trash
+func trashRetention(configFile *models.File) time.Duration {
+	days := DefaultTrashDays
+	if configFile.Defaults != nil && configFile.Defaults.TrashDays > 0 {
+		days = configFile.Defaults.TrashDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// moveToTrash keeps a removed config in the trash and drops the entries
+// whose retention ran out
+func moveToTrash(configFile *models.File, cfg models.APIConfig, now time.Time) {
+	// Restored configs go to the main file
+	cfg.Source = ""
+	configFile.Trash = append(liveTrash(configFile, now), models.TrashedConfig{Config: cfg, RemovedAt: now})
+}
+
+// liveTrash returns the trash entries whose retention has not run out
+func liveTrash(configFile *models.File, now time.Time) []models.TrashedConfig {
+	cutoff := now.Add(-trashRetention(configFile))
+	var live []models.TrashedConfig
+	for _, t := range configFile.Trash {
+		if t.RemovedAt.After(cutoff) {
+			live = append(live, t)
+		}
+	}
+	return live
+}
+
+// Trash returns the removed configs that can still be restored, oldest
+// first
+func (cm *Manager) Trash() ([]models.TrashedConfig, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return liveTrash(configFile, time.Now()), nil
+}
+
+// Restore moves the most recently removed config with the given alias out
+// of the trash, under the alias as when it is set. It returns the alias
+// the config was restored under.
+func (cm *Manager) Restore(alias, as string) (string, error) {
+	if as == "" {
+		as = alias
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	err := cm.update(func(configFile *models.File) error {
+		configFile.Trash = liveTrash(configFile, time.Now())
+		i := -1
+		for j := len(configFile.Trash) - 1; j >= 0; j-- {
+			if configFile.Trash[j].Config.Alias == alias {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return exitcode.New(exitcode.NotFound, "configuration '%s' is not in the trash", alias)
+		}
+		if slices.ContainsFunc(configFile.Configs, func(c models.APIConfig) bool { return c.Alias == as }) {
+			return exitcode.New(exitcode.Validation, "configuration '%s' already exists, restore it under another alias", as)
+		}
+
+		cfg := configFile.Trash[i].Config
+		cfg.Alias = as
+		if err := validateResolved(configFile.Configs, cfg); err != nil {
+			return err
+		}
+		configFile.Configs = append(configFile.Configs, cfg)
+		configFile.Trash = slices.Delete(configFile.Trash, i, i+1)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return as, nil
+}
+
+// EmptyTrash permanently deletes the removed configs and returns how many
+// there were
+func (cm *Manager) EmptyTrash() (int, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var count int
+	err := cm.update(func(configFile *models.File) error {
+		count = len(liveTrash(configFile, time.Now()))
+		configFile.Trash = nil
+		return nil
+	})
+	return count, err
+}
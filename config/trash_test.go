@@ -0,0 +1,89 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestTrash(t *testing.T) {
+	cm := setupTestConfig(t)
+	for _, cfg := range []models.APIConfig{
+		{Alias: "work", APIKey: "sk-work-1", BaseURL: "https://work.example.com"},
+		{Alias: "home", APIKey: "sk-home"},
+	} {
+		if err := cm.Add(cfg); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	if err := cm.Remove("work"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if err := cm.Add(models.APIConfig{Alias: "work", APIKey: "sk-work-2"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := cm.Remove("work"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+
+	trash, err := cm.Trash()
+	if err != nil || len(trash) != 2 {
+		t.Fatalf("Trash() = %+v, %v, want both removed work configs", trash, err)
+	}
+
+	// The most recent one comes back first
+	if _, err := cm.Restore("work", ""); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+	if work, err := cm.Get("work"); err != nil || work.APIKey != "sk-work-2" {
+		t.Errorf("Get() = %+v, %v, want the last removed work", work, err)
+	}
+	if _, err := cm.Restore("work", ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Restore() over an existing alias error = %v, want refused", err)
+	}
+	if alias, err := cm.Restore("work", "work-old"); err != nil || alias != "work-old" {
+		t.Fatalf("Restore() = %q, %v, want work-old", alias, err)
+	}
+	if old, err := cm.Get("work-old"); err != nil || old.BaseURL != "https://work.example.com" {
+		t.Errorf("Get() = %+v, %v, want the first removed work", old, err)
+	}
+	if _, err := cm.Restore("work", ""); err == nil || !strings.Contains(err.Error(), "not in the trash") {
+		t.Errorf("Restore() error = %v, want an empty trash", err)
+	}
+
+	if err := cm.Remove("home"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if count, err := cm.EmptyTrash(); err != nil || count != 1 {
+		t.Errorf("EmptyTrash() = %d, %v, want 1", count, err)
+	}
+	if trash, _ := cm.Trash(); len(trash) != 0 {
+		t.Errorf("Trash() = %+v after EmptyTrash(), want none", trash)
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	configFile := &models.File{
+		Defaults: &models.Defaults{TrashDays: 7},
+		Trash: []models.TrashedConfig{
+			{Config: models.APIConfig{Alias: "expired"}, RemovedAt: now.AddDate(0, 0, -8)},
+			{Config: models.APIConfig{Alias: "kept"}, RemovedAt: now.AddDate(0, 0, -6)},
+		},
+	}
+	moveToTrash(configFile, models.APIConfig{Alias: "new", Source: "shared.json"}, now)
+
+	var aliases []string
+	for _, t := range configFile.Trash {
+		aliases = append(aliases, t.Config.Alias)
+	}
+	if strings.Join(aliases, ",") != "kept,new" {
+		t.Errorf("trash = %v, want the expired entry dropped", aliases)
+	}
+	if configFile.Trash[1].Config.Source != "" {
+		t.Errorf("trashed config source = %q, want it restored to the main file", configFile.Trash[1].Config.Source)
+	}
+}
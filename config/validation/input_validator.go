@@ -32,7 +32,7 @@ func (iv *InputValidator) ValidateAlias(alias string) error {
 
 // ValidateURL checks if a URL is valid
 func (iv *InputValidator) ValidateURL(url string) error {
-	if url != "" && !utils.ValidateURL(url) {
+	if url != "" && !utils.ValidateURL(utils.SamplePlaceholders(url)) {
 		return fmt.Errorf("invalid URL format")
 	}
 	return nil
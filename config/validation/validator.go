@@ -2,11 +2,19 @@ package validation
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"apimgr/config/keypool"
 	"apimgr/config/models"
 	"apimgr/internal/providers"
 	"apimgr/internal/utils"
 )
 
+// headerSeparators may not appear in api_version or beta_features values
+const headerSeparators = " \t\r\n,"
+
 // Validator validates API configurations
 type Validator struct {
 }
@@ -38,6 +46,19 @@ func (v *Validator) ValidateConfig(config models.APIConfig) error {
 		return fmt.Errorf("API key and auth token cannot both be empty")
 	}
 
+	// The picked key comes from the pool
+	if len(config.APIKeys) > 0 && !slices.Contains(config.APIKeys, config.APIKey) {
+		return fmt.Errorf("API key must be one of api_keys")
+	}
+	for i, key := range config.APIKeys {
+		if slices.Contains(config.APIKeys[:i], key) {
+			return fmt.Errorf("api_keys lists key %s twice", utils.MaskAPIKey(key))
+		}
+	}
+	if config.KeyStrategy != "" && !slices.Contains(keypool.Strategies, config.KeyStrategy) {
+		return fmt.Errorf("unknown key strategy '%s' (available: %s)", config.KeyStrategy, strings.Join(keypool.Strategies, ", "))
+	}
+
 	// Validate provider
 	provider, err := providers.Get(providerName)
 	if err != nil {
@@ -51,10 +72,62 @@ func (v *Validator) ValidateConfig(config models.APIConfig) error {
 
 	// URL format validation
 	if config.BaseURL != "" {
-		if !utils.ValidateURL(config.BaseURL) {
+		if !utils.ValidateURL(utils.SamplePlaceholders(config.BaseURL)) {
 			return fmt.Errorf("invalid URL format: %s", config.BaseURL)
 		}
 	}
 
+	// Mutual TLS needs both the certificate and its key
+	if (config.ClientCert == "") != (config.ClientKey == "") {
+		return fmt.Errorf("client certificate and client key must be set together")
+	}
+
+	// Only exported where the provider has a variable for them
+	names := providers.EnvVarsFor(providerName)
+	if config.OrgID != "" && names.OrgID == "" {
+		return fmt.Errorf("provider %s has no organization ID, remove org_id", providerName)
+	}
+	if config.ProjectID != "" && names.ProjectID == "" {
+		return fmt.Errorf("provider %s has no project ID, remove project_id", providerName)
+	}
+
+	// Ranges accepted by the APIs, Anthropic caps the temperature at 1
+	maxTemperature := 2.0
+	if providerName == "anthropic" {
+		maxTemperature = 1
+	}
+	if config.Temperature != nil && (*config.Temperature < 0 || *config.Temperature > maxTemperature) {
+		return fmt.Errorf("temperature must be between 0 and %g for provider %s", maxTemperature, providerName)
+	}
+	if config.TopP != nil && (*config.TopP <= 0 || *config.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1")
+	}
+	if config.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens cannot be negative")
+	}
+	if config.APITimeout != "" {
+		if timeout, err := time.ParseDuration(config.APITimeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid api_timeout '%s', expected a positive duration such as 10m", config.APITimeout)
+		}
+	}
+	if config.MaxRetries != nil && *config.MaxRetries < 0 {
+		return fmt.Errorf("max_retries cannot be negative")
+	}
+
+	// Joined to the base URL, so an absolute path
+	if config.ChatPath != "" && (!strings.HasPrefix(config.ChatPath, "/") || strings.ContainsAny(config.ChatPath, " \t?#")) {
+		return fmt.Errorf("invalid chat path %q: must be a path starting with /", config.ChatPath)
+	}
+
+	// Sent as header values, so a single token each
+	if strings.ContainsAny(config.APIVersion, headerSeparators) {
+		return fmt.Errorf("invalid API version: %q", config.APIVersion)
+	}
+	for _, beta := range config.BetaFeatures {
+		if beta == "" || strings.ContainsAny(beta, headerSeparators) {
+			return fmt.Errorf("invalid beta feature: %q", beta)
+		}
+	}
+
 	return nil
 }
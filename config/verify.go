@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"apimgr/config/models"
+	"apimgr/config/session"
+	syncpkg "apimgr/config/sync"
+)
+
+// Mismatch is an inconsistency between the files apimgr keeps
+type Mismatch struct {
+	Artifact string // "config", ActiveEnvFileName, a sync target name or "session"
+	Path     string // File path
+	Problem  string
+	Fix      string // How to fix it
+}
+
+// Verify checks that the config file, active.env, the Claude Code settings
+// files and the session markers agree with each other: the active alias
+// exists, active.env and the settings export its values, and every session
+// marker belongs to a running shell and an existing configuration
+func (cm *Manager) Verify() ([]Mismatch, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	configFile, err := cm.loadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	markers, err := session.ListMarkers(cm.SessionDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []Mismatch
+	var active *models.APIConfig
+	if configFile.Active != "" {
+		if cfg, err := resolveEffective(configFile, configFile.Active); err == nil {
+			active = &cfg
+		} else {
+			mismatches = append(mismatches, Mismatch{
+				Artifact: "config",
+				Path:     cm.configPath,
+				Problem:  fmt.Sprintf("active configuration '%s' does not exist", configFile.Active),
+				Fix:      "run 'apimgr switch <alias>' to activate an existing configuration",
+			})
+		}
+	}
+
+	if m := cm.verifyActiveEnv(active); m != nil {
+		mismatches = append(mismatches, *m)
+	}
+
+	// A local session syncs its configuration to the settings files too
+	var sessions []*models.APIConfig
+	for _, marker := range markers {
+		if !marker.Running || marker.Marker == nil {
+			continue
+		}
+		if cfg, err := resolveEffective(configFile, marker.Marker.Alias); err == nil {
+			sessions = append(sessions, &cfg)
+		}
+	}
+	if active != nil {
+		for _, target := range syncTargets(configFile) {
+			m, err := cm.verifySettings(target, configFile.Defaults, active, sessions)
+			if err != nil {
+				return nil, err
+			}
+			if m != nil {
+				mismatches = append(mismatches, *m)
+			}
+		}
+	}
+
+	for _, marker := range markers {
+		if m := verifyMarker(configFile, marker); m != nil {
+			mismatches = append(mismatches, *m)
+		}
+	}
+	return mismatches, nil
+}
+
+// verifyActiveEnv checks that active.env exports the active configuration,
+// or is absent without one
+func (cm *Manager) verifyActiveEnv(active *models.APIConfig) *Mismatch {
+	m := &Mismatch{
+		Artifact: ActiveEnvFileName,
+		Path:     filepath.Join(cm.StateDir(), ActiveEnvFileName),
+		Fix:      "run 'apimgr sync claude' to regenerate it",
+	}
+	current, err := os.ReadFile(m.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && active == nil:
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		m.Problem = fmt.Sprintf("missing, new shells do not load '%s'", active.Alias)
+		return m
+	case err != nil:
+		m.Problem = fmt.Sprintf("cannot be read: %v", err)
+		return m
+	}
+
+	if active == nil {
+		m.Problem = "exists but no configuration is active"
+		m.Fix = "remove it, or run 'apimgr switch <alias>'"
+		return m
+	}
+	if names := changedNames(diffEnv(syncpkg.ParseEnvScript(string(current)), syncpkg.ParseEnvScript(syncpkg.GenerateEnvScript(active)))); names != "" {
+		m.Problem = fmt.Sprintf("values of %s differ from '%s'", names, active.Alias)
+		return m
+	}
+	return nil
+}
+
+// verifySettings checks that a settings file holds the values of the active
+// configuration or of a running local session. Missing files are skipped
+// on switch, so they are fine.
+func (cm *Manager) verifySettings(target SyncTarget, defaults *models.Defaults, active *models.APIConfig, sessions []*models.APIConfig) (*Mismatch, error) {
+	current, err := os.ReadFile(target.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", target.Path, err)
+	}
+
+	var names string
+	for i, cfg := range append([]*models.APIConfig{active}, sessions...) {
+		updated, err := cm.updateSettingsContent(string(current), cfg, target.keyOptions(cm.keySyncOptions(cfg, defaults)))
+		if err != nil {
+			return &Mismatch{
+				Artifact: target.Name,
+				Path:     target.Path,
+				Problem:  err.Error(),
+				Fix:      "fix the file, or remove it and run 'apimgr sync claude'",
+			}, nil
+		}
+		changed := changedNames(diffSettings(current, []byte(updated)))
+		if changed == "" {
+			return nil, nil
+		}
+		if i == 0 {
+			names = changed
+		}
+	}
+	return &Mismatch{
+		Artifact: target.Name,
+		Path:     target.Path,
+		Problem:  fmt.Sprintf("values of %s differ from '%s'", names, active.Alias),
+		Fix:      "run 'apimgr sync claude' to sync it again",
+	}, nil
+}
+
+// verifyMarker checks that a session marker belongs to a running shell and
+// names an existing configuration
+func verifyMarker(configFile *models.File, marker session.MarkerFile) *Mismatch {
+	m := &Mismatch{Artifact: "session", Path: marker.Path}
+	switch {
+	case !marker.Running:
+		m.Problem = fmt.Sprintf("orphaned, shell %s is no longer running", marker.PID)
+		m.Fix = "remove it, 'apimgr load-active' also removes orphaned markers"
+	case marker.Marker == nil:
+		m.Problem = "cannot be parsed"
+		m.Fix = "remove it, the shell falls back to the global configuration"
+	default:
+		if _, err := resolveConfig(configFile.Configs, marker.Marker.Alias); err != nil {
+			m.Problem = fmt.Sprintf("shell %s uses configuration '%s', which no longer exists", marker.PID, marker.Marker.Alias)
+			m.Fix = "run 'apimgr switch -l <alias>' in that shell"
+			break
+		}
+		return nil
+	}
+	return m
+}
+
+// changedNames lists the fields that differ, or returns an empty string
+func changedNames(diffs []FieldDiff) string {
+	var names []string
+	for _, d := range diffs {
+		if d.Differs() {
+			names = append(names, d.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestVerify(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	cm := setupTestConfig(t)
+	user := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(user), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(user, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := &models.File{
+		Active: "a",
+		Configs: []models.APIConfig{
+			{Alias: "a", APIKey: "sk-a", BaseURL: "https://relay.example.com", Model: "m1"},
+			{Alias: "b", APIKey: "sk-b", BaseURL: "https://relay.example.com"},
+		},
+	}
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatalf("saveConfigFile() unexpected error: %v", err)
+	}
+	if err := cm.GenerateActiveScript(); err != nil {
+		t.Fatalf("GenerateActiveScript() unexpected error: %v", err)
+	}
+
+	verify := func() []string {
+		t.Helper()
+		mismatches, err := cm.Verify()
+		if err != nil {
+			t.Fatalf("Verify() unexpected error: %v", err)
+		}
+		var got []string
+		for _, m := range mismatches {
+			got = append(got, m.Artifact+": "+m.Problem)
+		}
+		return got
+	}
+
+	if got := verify(); got != nil {
+		t.Fatalf("Verify() after a switch = %v, want none", got)
+	}
+
+	// A running local session may sync its own configuration
+	self := strconv.Itoa(os.Getpid())
+	writeMarker(t, cm, self, `{"pid": "`+self+`", "alias": "b"}`)
+	if err := cm.SyncClaudeSettingsOnly(&configFile.Configs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := verify(); got != nil {
+		t.Fatalf("Verify() with a local session = %v, want none", got)
+	}
+
+	writeMarker(t, cm, self, `{"pid": "`+self+`", "alias": "gone"}`)
+	writeMarker(t, cm, "99999999", `{"pid": "99999999", "alias": "a"}`)
+	envPath := filepath.Join(cm.StateDir(), ActiveEnvFileName)
+	if err := os.WriteFile(envPath, []byte("export ANTHROPIC_API_KEY=\"sk-old\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"active.env: values of ANTHROPIC_API_KEY, ANTHROPIC_BASE_URL, ANTHROPIC_MODEL, APIMGR_ACTIVE differ from 'a'",
+		"claude: values of ANTHROPIC_API_KEY, ANTHROPIC_MODEL differ from 'a'",
+		"session: shell " + self + " uses configuration 'gone', which no longer exists",
+		"session: orphaned, shell 99999999 is no longer running",
+	}
+	if got := verify(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Verify() = %q, want %q", got, want)
+	}
+
+	configFile.Active = "gone"
+	if err := cm.saveConfigFile(configFile); err != nil {
+		t.Fatal(err)
+	}
+	if got := verify(); len(got) < 2 || got[0] != "config: active configuration 'gone' does not exist" || got[1] != "active.env: exists but no configuration is active" {
+		t.Errorf("Verify() with a missing active config = %q", got)
+	}
+}
+
+// writeMarker writes a session marker file for pid
+func writeMarker(t *testing.T, cm *Manager, pid, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(cm.StateDir(), "session-"+pid), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"apimgr/config/storage"
+)
+
+// ConfigEnvVar names the environment variable that selects a config file
+const ConfigEnvVar = "APIMGR_CONFIG"
+
+// DefaultWorkspace is the workspace stored directly in the config directory
+const DefaultWorkspace = "default"
+
+const (
+	workspacesDirName = "workspaces" // Directory holding named workspaces
+	workspaceFileName = "workspace"  // File recording the workspace in use
+)
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// configPathOverride is set by the --config flag
+var configPathOverride string
+
+// SetConfigPath makes NewConfigManager use path, taking precedence over
+// APIMGR_CONFIG and the workspace in use. An empty path clears it.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+// ConfigPathOverride returns the config file selected by --config or
+// APIMGR_CONFIG, and where it came from, or empty strings when neither is set
+func ConfigPathOverride() (path, source string) {
+	if configPathOverride != "" {
+		return configPathOverride, "--config"
+	}
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, ConfigEnvVar
+	}
+	return "", ""
+}
+
+// baseConfigDir returns the apimgr directory under XDG_CONFIG_HOME
+func baseConfigDir() (string, error) {
+	// Check XDG_CONFIG_HOME environment variable for custom config location
+	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfigHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		// Use default XDG path (~/.config)
+		xdgConfigHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(xdgConfigHome, "apimgr"), nil
+}
+
+// WorkspaceDir returns the directory holding a workspace's config file
+func WorkspaceDir(name string) (string, error) {
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultWorkspace {
+		return baseDir, nil
+	}
+	return filepath.Join(baseDir, workspacesDirName, name), nil
+}
+
+// CurrentWorkspace returns the name of the workspace in use
+func CurrentWorkspace() (string, error) {
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir, workspaceFileName))
+	if os.IsNotExist(err) {
+		return DefaultWorkspace, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read current workspace: %w", err)
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultWorkspace, nil
+	}
+	return name, nil
+}
+
+// UseWorkspace makes name the workspace in use, creating it if needed
+func UseWorkspace(name string) error {
+	if !workspaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid workspace name '%s': use letters, digits, '.', '-' and '_'", name)
+	}
+	dir, err := WorkspaceDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return err
+	}
+	workspaceFile := filepath.Join(baseDir, workspaceFileName)
+	if name == DefaultWorkspace {
+		if err := os.Remove(workspaceFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset workspace: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(workspaceFile, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save workspace: %w", err)
+	}
+	return nil
+}
+
+// ListWorkspaces returns the default workspace followed by the named ones
+func ListWorkspaces() ([]string, error) {
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(baseDir, workspacesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultWorkspace {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return append([]string{DefaultWorkspace}, names...), nil
+}
+
+// resolveConfigPath returns the config file to use: --config, then
+// APIMGR_CONFIG, then the config file of the workspace in use
+func resolveConfigPath() (string, error) {
+	if path, _ := ConfigPathOverride(); path != "" {
+		return filepath.Abs(path)
+	}
+
+	workspace, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	dir, err := WorkspaceDir(workspace)
+	if err != nil {
+		return "", err
+	}
+	return storage.FindConfigFile(dir), nil
+}
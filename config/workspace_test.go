@@ -0,0 +1,83 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWorkspaces(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(ConfigEnvVar, "")
+	baseDir := filepath.Join(xdg, "apimgr")
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	if want := filepath.Join(baseDir, "config.json"); cm.GetConfigPath() != want {
+		t.Errorf("default config path = %s, want %s", cm.GetConfigPath(), want)
+	}
+
+	if err := UseWorkspace("client-acme"); err != nil {
+		t.Fatalf("UseWorkspace() unexpected error: %v", err)
+	}
+	if current, _ := CurrentWorkspace(); current != "client-acme" {
+		t.Errorf("CurrentWorkspace() = %s, want client-acme", current)
+	}
+	cm, err = NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	if want := filepath.Join(baseDir, "workspaces", "client-acme", "config.json"); cm.GetConfigPath() != want {
+		t.Errorf("workspace config path = %s, want %s", cm.GetConfigPath(), want)
+	}
+
+	names, err := ListWorkspaces()
+	if err != nil || !slices.Equal(names, []string{DefaultWorkspace, "client-acme"}) {
+		t.Errorf("ListWorkspaces() = %v, %v", names, err)
+	}
+
+	if err := UseWorkspace("../escape"); err == nil {
+		t.Error("UseWorkspace() expected error for invalid name")
+	}
+
+	if err := UseWorkspace(DefaultWorkspace); err != nil {
+		t.Fatalf("UseWorkspace(default) unexpected error: %v", err)
+	}
+	if current, _ := CurrentWorkspace(); current != DefaultWorkspace {
+		t.Errorf("CurrentWorkspace() = %s, want %s", current, DefaultWorkspace)
+	}
+}
+
+func TestConfigPathOverride(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, "env.yaml")
+	flagPath := filepath.Join(dir, "flag.json")
+	t.Setenv(ConfigEnvVar, envPath)
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	if cm.GetConfigPath() != envPath {
+		t.Errorf("config path = %s, want %s from %s", cm.GetConfigPath(), envPath, ConfigEnvVar)
+	}
+
+	SetConfigPath(flagPath)
+	defer SetConfigPath("")
+	cm, err = NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() unexpected error: %v", err)
+	}
+	if cm.GetConfigPath() != flagPath {
+		t.Errorf("config path = %s, want %s from --config", cm.GetConfigPath(), flagPath)
+	}
+	if _, source := ConfigPathOverride(); source != "--config" {
+		t.Errorf("ConfigPathOverride() source = %s, want --config", source)
+	}
+}
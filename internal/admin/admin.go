@@ -0,0 +1,66 @@
+// Package admin manages the API keys of an organization through the admin
+// APIs of providers, for key rotation
+package admin
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Key is an API key as an admin API describes it. The key itself is never
+// returned, only a hint of it.
+type Key struct {
+	ID     string
+	Name   string
+	Hint   string // Start and end of the key, such as "sk-ant-api03-R2D...igAA"
+	Status string
+}
+
+// KeyAdmin manages the API keys of an organization
+type KeyAdmin interface {
+	// FindKey returns the active key whose hint matches key
+	FindKey(key string) (Key, error)
+	// DeactivateKey stops the key with id from authenticating requests
+	DeactivateKey(id string) error
+}
+
+// constructors creates the admin API client of each supported provider from
+// an admin key
+var constructors = map[string]func(adminKey string) KeyAdmin{
+	"anthropic": func(adminKey string) KeyAdmin { return NewAnthropic(adminKey) },
+}
+
+// AdminKeyEnv names the environment variable holding the admin key of each
+// supported provider
+var AdminKeyEnv = map[string]string{
+	"anthropic": "ANTHROPIC_ADMIN_KEY",
+}
+
+// Providers returns the providers whose keys can be rotated, sorted
+func Providers() []string {
+	return slices.Sorted(maps.Keys(constructors))
+}
+
+// New returns the admin API client of provider authenticated with adminKey
+func New(provider, adminKey string) (KeyAdmin, error) {
+	newAdmin, ok := constructors[provider]
+	if !ok {
+		return nil, fmt.Errorf("provider '%s' has no supported admin API (available: %s)", provider, strings.Join(Providers(), ", "))
+	}
+	if adminKey == "" {
+		return nil, fmt.Errorf("an admin key is required, set %s", AdminKeyEnv[provider])
+	}
+	return newAdmin(adminKey), nil
+}
+
+// MatchesHint reports whether key has the start and end shown by hint,
+// such as "sk-ant-api03-R2D...igAA"
+func MatchesHint(key, hint string) bool {
+	start, end, ok := strings.Cut(hint, "...")
+	if !ok {
+		return key == hint
+	}
+	return len(key) >= len(start)+len(end) && strings.HasPrefix(key, start) && strings.HasSuffix(key, end)
+}
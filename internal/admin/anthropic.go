@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"apimgr/internal/utils"
+)
+
+// anthropicVersion is the API version the admin requests are sent with
+const anthropicVersion = "2023-06-01"
+
+// Anthropic manages keys with the Anthropic Admin API. It can find and
+// deactivate keys; new keys are created in the Console.
+type Anthropic struct {
+	BaseURL  string // https://api.anthropic.com unless overridden
+	AdminKey string // An sk-ant-admin key
+	Client   *http.Client
+}
+
+// NewAnthropic returns an Anthropic Admin API client
+func NewAnthropic(adminKey string) *Anthropic {
+	return &Anthropic{
+		BaseURL:  "https://api.anthropic.com",
+		AdminKey: adminKey,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// anthropicKey is an API key in Admin API responses
+type anthropicKey struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	PartialKeyHint string `json:"partial_key_hint"`
+	Status         string `json:"status"`
+}
+
+// anthropicKeyPage is a page of the key list
+type anthropicKeyPage struct {
+	Data    []anthropicKey `json:"data"`
+	HasMore bool           `json:"has_more"`
+	LastID  string         `json:"last_id"`
+}
+
+// do sends an Admin API request and decodes its JSON response into out
+func (a *Anthropic) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to serialize request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(a.BaseURL, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("x-api-key", a.AdminKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("admin API request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read admin API response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("admin API returned HTTP %d: %s", resp.StatusCode, utils.Redact(strings.TrimSpace(string(data))))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse admin API response: %v", err)
+		}
+	}
+	return nil
+}
+
+// FindKey returns the active key of the organization whose hint matches
+// key, going through every page of the key list
+func (a *Anthropic) FindKey(key string) (Key, error) {
+	var matches []Key
+	query := url.Values{"status": {"active"}, "limit": {"100"}}
+	for {
+		var page anthropicKeyPage
+		if err := a.do(http.MethodGet, "/v1/organizations/api_keys?"+query.Encode(), nil, &page); err != nil {
+			return Key{}, err
+		}
+		for _, k := range page.Data {
+			if MatchesHint(key, k.PartialKeyHint) {
+				matches = append(matches, Key{ID: k.ID, Name: k.Name, Hint: k.PartialKeyHint, Status: k.Status})
+			}
+		}
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		query.Set("after_id", page.LastID)
+	}
+
+	switch len(matches) {
+	case 0:
+		return Key{}, fmt.Errorf("no active key of the organization matches the configured key")
+	case 1:
+		return matches[0], nil
+	default:
+		return Key{}, fmt.Errorf("%d active keys match the configured key, deactivate the old one in the Console", len(matches))
+	}
+}
+
+// DeactivateKey sets the status of the key with id to inactive
+func (a *Anthropic) DeactivateKey(id string) error {
+	return a.do(http.MethodPost, "/v1/organizations/api_keys/"+url.PathEscape(id), map[string]string{"status": "inactive"}, nil)
+}
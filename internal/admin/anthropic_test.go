@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMatchesHint(t *testing.T) {
+	tests := []struct {
+		key  string
+		hint string
+		want bool
+	}{
+		{key: "sk-ant-REDACTED", hint: "sk-ant-api03-R2D...igAA", want: true},
+		{key: "sk-ant-REDACTED", hint: "sk-ant-api03-R2D...igAA", want: false},
+		{key: "sk-ant-REDACTED", hint: "sk-ant-api03-R2D...igAA", want: false},
+		{key: "sk-ant-api03-R2DigAA", hint: "sk-ant-api03-R2Di...gAA", want: true},
+		{key: "sk-ant-api03-R2igAA", hint: "sk-ant-api03-R2Di...gAA", want: false},
+	}
+	for _, tt := range tests {
+		if got := MatchesHint(tt.key, tt.hint); got != tt.want {
+			t.Errorf("MatchesHint(%q, %q) = %v, want %v", tt.key, tt.hint, got, tt.want)
+		}
+	}
+}
+
+func TestAnthropic(t *testing.T) {
+	var deactivated string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-admin-test" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"invalid x-api-key"}}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/organizations/api_keys":
+			// Two pages, the key being on the second
+			page := map[string]any{"data": []map[string]string{{"id": "apikey_01", "name": "ci", "partial_key_hint": "sk-ant-api03-AAA...zzzz", "status": "active"}}, "has_more": true, "last_id": "apikey_01"}
+			if r.URL.Query().Get("after_id") == "apikey_01" {
+				page = map[string]any{"data": []map[string]string{{"id": "apikey_02", "name": "work", "partial_key_hint": "sk-ant-api03-R2D...igAA", "status": "active"}}, "has_more": false}
+			}
+			json.NewEncoder(w).Encode(page)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/organizations/api_keys/"):
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["status"] == "inactive" {
+				deactivated = strings.TrimPrefix(r.URL.Path, "/v1/organizations/api_keys/")
+			}
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	a := NewAnthropic("sk-ant-admin-test")
+	a.BaseURL = server.URL
+
+	key, err := a.FindKey("sk-ant-REDACTED")
+	if err != nil {
+		t.Fatalf("FindKey() unexpected error: %v", err)
+	}
+	if key.ID != "apikey_02" || key.Name != "work" {
+		t.Errorf("FindKey() = %+v, want apikey_02", key)
+	}
+	if _, err := a.FindKey("sk-ant-api03-unknown"); err == nil {
+		t.Error("FindKey() of an unknown key expected error, got nil")
+	}
+
+	if err := a.DeactivateKey("apikey_02"); err != nil {
+		t.Fatalf("DeactivateKey() unexpected error: %v", err)
+	}
+	if deactivated != "apikey_02" {
+		t.Errorf("deactivated %q, want apikey_02", deactivated)
+	}
+
+	a.AdminKey = "sk-ant-admin-wrong"
+	if _, err := a.FindKey("sk-ant-REDACTED"); err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Errorf("FindKey() with a wrong admin key error = %v, want HTTP 401", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("anthropic", ""); err == nil || !strings.Contains(err.Error(), "ANTHROPIC_ADMIN_KEY") {
+		t.Errorf("New() without an admin key error = %v, want it to name ANTHROPIC_ADMIN_KEY", err)
+	}
+	if _, err := New("gemini", "key"); err == nil {
+		t.Error("New() of an unsupported provider expected error, got nil")
+	}
+	if _, err := New("anthropic", "sk-ant-admin-test"); err != nil {
+		t.Errorf("New() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package compatibility
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTokens is a context window probe the size of the prompts
+// Claude Code sends in long sessions
+const DefaultProbeTokens = 100000
+
+// probeCharsPerToken estimates the size of the probe prompt, a little below
+// what tokenizers count for English text so it is not undersized
+const probeCharsPerToken = 4
+
+// probePassphrase opens the probe prompt; a reply without it means the
+// start of the prompt was cut off
+const probePassphrase = "AMBER-FALCON-4821"
+
+// contextProbePrompt returns a prompt of about tokens tokens asking to
+// repeat the passphrase given at its start
+func contextProbePrompt(tokens int) string {
+	var b strings.Builder
+	b.WriteString("The passphrase is " + probePassphrase + ". Remember it.\n\n")
+	question := "\nReply with only the passphrase given at the very beginning of this message."
+	for i := 1; b.Len()+len(question) < tokens*probeCharsPerToken; i++ {
+		fmt.Fprintf(&b, "Filler line %d: this text only pads the prompt to test long contexts.\n", i)
+	}
+	b.WriteString(question)
+	return b.String()
+}
+
+// probeReply is what the context window and vision checks read of a
+// response, in either the Anthropic or the OpenAI format
+type probeReply struct {
+	Content []AnthropicContentBlock `json:"content"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		PromptTokens int `json:"prompt_tokens"`
+	} `json:"usage"`
+}
+
+// parseProbeReply returns the text of a response and the input tokens it
+// reports, 0 when it reports none
+func parseProbeReply(body []byte) (string, int) {
+	var reply probeReply
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return "", 0
+	}
+	var text strings.Builder
+	for _, block := range reply.Content {
+		text.WriteString(block.Text)
+	}
+	for _, choice := range reply.Choices {
+		text.WriteString(choice.Message.Content)
+	}
+	return text.String(), max(reply.Usage.InputTokens, reply.Usage.PromptTokens)
+}
+
+// runProbe sends the request of an optional check and reports it as a
+// single non-critical check named name. judge tells whether the response
+// passes and why.
+func (t *Tester) runProbe(name string, build func(RequestBuilder) (*http.Request, error), judge func(status int, body []byte) (bool, string)) (*TestResult, error) {
+	result := &TestResult{
+		Success: false,
+		Checks:  []CheckResult{},
+	}
+
+	startTime := time.Now()
+	check := CheckResult{Name: name, Critical: false}
+	finish := func() (*TestResult, error) {
+		result.ResponseTime = time.Since(startTime)
+		result.Checks = append(result.Checks, check)
+		result.CompatibilityLevel, _ = DetermineCompatibilityLevel(result.Checks)
+		result.Success = result.CompatibilityLevel == CompatibilityFull
+		return result, nil
+	}
+
+	req, err := build(t.getRequestBuilder())
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to build %s request: %v", strings.ToLower(name), err)
+		check.Message = result.Error
+		return finish()
+	}
+
+	resp, err := t.sendChat(req, build)
+	if err != nil {
+		result.Error = fmt.Sprintf("network error: %v", err)
+		check.Message = CategorizeNetworkError(err).UserMessage
+		return finish()
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response: %v", err)
+		check.Message = result.Error
+		return finish()
+	}
+
+	check.Passed, check.Message = judge(resp.StatusCode, body)
+	return finish()
+}
+
+// TestContextWindow sends a prompt of the size set with WithContextProbe,
+// checking that it is neither rejected nor truncated. The check is not
+// critical: short requests still work without it.
+func (t *Tester) TestContextWindow() (*TestResult, error) {
+	model := t.getModel()
+	prompt := contextProbePrompt(t.probeSize)
+	build := func(b RequestBuilder) (*http.Request, error) {
+		return b.BuildPromptRequest(model, prompt, false)
+	}
+	return t.runProbe("Context Window", build, func(status int, body []byte) (bool, string) {
+		if status != http.StatusOK {
+			return false, fmt.Sprintf("~%d-token prompt rejected (HTTP %d): %s", t.probeSize, status, truncateString(strings.TrimSpace(string(body)), 200))
+		}
+		text, inputTokens := parseProbeReply(body)
+		switch {
+		case inputTokens > 0 && inputTokens < t.probeSize/2:
+			return false, fmt.Sprintf("~%d-token prompt truncated: only %d input tokens counted", t.probeSize, inputTokens)
+		case !strings.Contains(text, probePassphrase):
+			return false, fmt.Sprintf("~%d-token prompt likely truncated: the reply does not repeat the passphrase from its start", t.probeSize)
+		case inputTokens > 0:
+			return true, fmt.Sprintf("~%d-token prompt accepted (%d input tokens)", t.probeSize, inputTokens)
+		default:
+			return true, fmt.Sprintf("~%d-token prompt accepted", t.probeSize)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package compatibility
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestContextProbePrompt(t *testing.T) {
+	prompt := contextProbePrompt(1000)
+	if !strings.HasPrefix(prompt, "The passphrase is "+probePassphrase) {
+		t.Errorf("prompt should open with the passphrase, got %q", prompt[:40])
+	}
+	if got, want := len(prompt), 1000*probeCharsPerToken; got < want || got > want+100 {
+		t.Errorf("len(prompt) = %d, want about %d", got, want)
+	}
+}
+
+// TestTestContextWindow tests that rejected and truncated long prompts fail
+// the non-critical context window check
+func TestTestContextWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		reply       string
+		inputTokens int
+		wantPassed  bool
+		wantMessage string
+	}{
+		{name: "accepted", status: http.StatusOK, reply: probePassphrase, inputTokens: 2100, wantPassed: true, wantMessage: "2100 input tokens"},
+		{name: "rejected", status: http.StatusRequestEntityTooLarge, wantMessage: "HTTP 413"},
+		{name: "fewer input tokens", status: http.StatusOK, reply: probePassphrase, inputTokens: 300, wantMessage: "only 300 input tokens"},
+		{name: "passphrase lost", status: http.StatusOK, reply: "I don't know", inputTokens: 2100, wantMessage: "does not repeat the passphrase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var promptSize int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Messages []struct {
+						Content string `json:"content"`
+					} `json:"messages"`
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				if len(req.Messages) > 0 {
+					promptSize = len(req.Messages[0].Content)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(map[string]any{
+					"content": []map[string]string{{"type": "text", "text": tt.reply}},
+					"usage":   map[string]int{"input_tokens": tt.inputTokens, "output_tokens": 5},
+				})
+			}))
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+			tester, err := NewTester(cfg, WithContextProbe(2000))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := tester.TestContextWindow()
+			if err != nil {
+				t.Fatalf("TestContextWindow() unexpected error: %v", err)
+			}
+			if promptSize < 2000*probeCharsPerToken {
+				t.Errorf("prompt size = %d bytes, want at least %d", promptSize, 2000*probeCharsPerToken)
+			}
+			check := result.Checks[0]
+			if check.Passed != tt.wantPassed || check.Critical {
+				t.Errorf("check = %+v, want Passed %v and not critical", check, tt.wantPassed)
+			}
+			if !strings.Contains(check.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want it to contain %q", check.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRunFullTest_ContextProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+	tester, err := NewTester(cfg, WithContextProbe(1000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := tester.RunFullTest(false)
+	if err != nil {
+		t.Fatalf("RunFullTest() unexpected error: %v", err)
+	}
+	last := result.Checks[len(result.Checks)-1]
+	if last.Name != "Context Window" || last.Passed {
+		t.Errorf("last check = %+v, want a failed context window check", last)
+	}
+	if result.CompatibilityLevel != CompatibilityPartial {
+		t.Errorf("CompatibilityLevel = %q, want %q", result.CompatibilityLevel, CompatibilityPartial)
+	}
+}
@@ -0,0 +1,62 @@
+package compatibility
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// systemPassphrase is what the system prompt of the system prompt check
+// orders the model to reply with
+const systemPassphrase = "ORCHID-2291"
+
+// Prompt of the stop sequence check: the reply should end before stopWord,
+// so neither it nor the words after it come back
+const (
+	stopPrompt = "Repeat exactly, with nothing else: alpha beta HALT gamma delta"
+	stopWord   = "HALT"
+)
+
+// TestSystemPrompt checks that a system prompt reaches the model, as lossy
+// translation layers drop or demote it. The check is not critical.
+func (t *Tester) TestSystemPrompt() (*TestResult, error) {
+	model := t.getModel()
+	system := "You are a test endpoint. Whatever the user says, reply with exactly " + systemPassphrase + " and nothing else."
+	build := func(b RequestBuilder) (*http.Request, error) {
+		return b.BuildSystemRequest(model, system, "Say hello.", nil)
+	}
+	return t.runProbe("System Prompt", build, func(status int, body []byte) (bool, string) {
+		if status != http.StatusOK {
+			return false, fmt.Sprintf("Request with a system prompt rejected (HTTP %d): %s", status, truncateString(strings.TrimSpace(string(body)), 200))
+		}
+		text, _ := parseProbeReply(body)
+		if !strings.Contains(text, systemPassphrase) {
+			return false, fmt.Sprintf("System prompt ignored: %q", truncateString(strings.TrimSpace(text), 80))
+		}
+		return true, "System prompt honored"
+	})
+}
+
+// TestStopSequences checks that custom stop sequences end the reply, as
+// lossy translation layers drop them. The check is not critical.
+func (t *Tester) TestStopSequences() (*TestResult, error) {
+	model := t.getModel()
+	build := func(b RequestBuilder) (*http.Request, error) {
+		return b.BuildSystemRequest(model, "", stopPrompt, []string{stopWord})
+	}
+	return t.runProbe("Stop Sequences", build, func(status int, body []byte) (bool, string) {
+		if status != http.StatusOK {
+			return false, fmt.Sprintf("Request with stop sequences rejected (HTTP %d): %s", status, truncateString(strings.TrimSpace(string(body)), 200))
+		}
+		text, _ := parseProbeReply(body)
+		reply := truncateString(strings.TrimSpace(text), 80)
+		switch {
+		case strings.Contains(text, stopWord) || strings.Contains(text, "gamma"):
+			return false, fmt.Sprintf("Stop sequence ignored: %q", reply)
+		case !strings.Contains(text, "alpha"):
+			return false, fmt.Sprintf("Reply did not follow the prompt, stop sequences unverified: %q", reply)
+		default:
+			return true, "Stop sequences respected"
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package compatibility
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+// fidelityRequest is what the fidelity tests read of a request, in either
+// the Anthropic or the OpenAI format
+type fidelityRequest struct {
+	System        string        `json:"system"`
+	Messages      []ChatMessage `json:"messages"`
+	StopSequences []string      `json:"stop_sequences"`
+	Stop          []string      `json:"stop"`
+}
+
+// newFidelityServer returns a server replying like a model that honors the
+// system prompt and stop sequences when honor is set, and ignores them
+// otherwise
+func newFidelityServer(honor bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req fidelityRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		system := req.System
+		if len(req.Messages) > 1 && req.Messages[0].Role == "system" {
+			system, _ = req.Messages[0].Content.(string)
+		}
+		stop := append(req.StopSequences, req.Stop...)
+
+		reply := "Hello!"
+		switch {
+		case strings.Contains(system, systemPassphrase) && honor:
+			reply = systemPassphrase
+		case slices.Contains(stop, stopWord) && honor:
+			reply = "alpha beta "
+		case len(stop) > 0:
+			reply = "alpha beta HALT gamma delta"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"content": []map[string]string{{"type": "text", "text": reply}},
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+}
+
+func TestFidelityChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   string
+		honor      bool
+		wantPassed bool
+	}{
+		{name: "anthropic honored", provider: "anthropic", honor: true, wantPassed: true},
+		{name: "openai honored", provider: "openai", honor: true, wantPassed: true},
+		{name: "anthropic ignored", provider: "anthropic", honor: false, wantPassed: false},
+		{name: "openai ignored", provider: "openai", honor: false, wantPassed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFidelityServer(tt.honor)
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: tt.provider, APIKey: "test-key", BaseURL: server.URL}
+			tester, err := NewTester(cfg, WithFidelityChecks(true))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, run := range []func() (*TestResult, error){tester.TestSystemPrompt, tester.TestStopSequences} {
+				result, err := run()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if check := result.Checks[0]; check.Passed != tt.wantPassed || check.Critical {
+					t.Errorf("check = %+v, want Passed %v and not critical", check, tt.wantPassed)
+				}
+			}
+		})
+	}
+}
+
+func TestStopSequences_UnverifiedReply(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":[{"type":"text","text":"I can't help with that."}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+	tester, err := NewTester(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := tester.TestStopSequences()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check := result.Checks[0]; check.Passed || !strings.Contains(check.Message, "unverified") {
+		t.Errorf("check = %+v, want a failed unverified check", check)
+	}
+}
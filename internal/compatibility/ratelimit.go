@@ -0,0 +1,97 @@
+package compatibility
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the budget of one rate-limited resource, as reported by the
+// rate limit headers of a response
+type RateLimit struct {
+	Resource  string    `json:"resource"`        // requests, tokens, input-tokens or output-tokens
+	Limit     int64     `json:"limit,omitempty"` // 0 when not reported
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset,omitzero"` // Zero when not reported
+}
+
+// rateLimitResources are the resources rate limit headers report on, in
+// display order
+var rateLimitResources = []string{"requests", "tokens", "input-tokens", "output-tokens"}
+
+// ParseRateLimits reads the anthropic-ratelimit-* headers of the Anthropic
+// API, or the x-ratelimit-* headers of OpenAI-compatible APIs, and returns
+// the budget of each resource they report on. Relative reset times are
+// resolved against now.
+func ParseRateLimits(h http.Header, now time.Time) []RateLimit {
+	var limits []RateLimit
+	for _, resource := range rateLimitResources {
+		names := [3]string{
+			"anthropic-ratelimit-" + resource + "-limit",
+			"anthropic-ratelimit-" + resource + "-remaining",
+			"anthropic-ratelimit-" + resource + "-reset",
+		}
+		if h.Get(names[1]) == "" {
+			names = [3]string{
+				"x-ratelimit-limit-" + resource,
+				"x-ratelimit-remaining-" + resource,
+				"x-ratelimit-reset-" + resource,
+			}
+		}
+		remaining, err := strconv.ParseInt(strings.TrimSpace(h.Get(names[1])), 10, 64)
+		if err != nil {
+			continue
+		}
+		limit, _ := strconv.ParseInt(strings.TrimSpace(h.Get(names[0])), 10, 64)
+		limits = append(limits, RateLimit{
+			Resource:  resource,
+			Limit:     limit,
+			Remaining: remaining,
+			Reset:     parseReset(strings.TrimSpace(h.Get(names[2])), now),
+		})
+	}
+	return limits
+}
+
+// parseReset reads a reset time, either a timestamp like the Anthropic API
+// sends or a delay like "6m0s" or "20" (seconds) as OpenAI-compatible APIs
+// send. It returns the zero time when value is empty or malformed.
+func parseReset(value string, now time.Time) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d)
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(secs * float64(time.Second)))
+	}
+	return time.Time{}
+}
+
+// ResetIn returns how long until the budget resets, rounded to the second,
+// or 0 when the reset time is unknown or past
+func (l RateLimit) ResetIn(now time.Time) time.Duration {
+	if l.Reset.IsZero() || !l.Reset.After(now) {
+		return 0
+	}
+	return l.Reset.Sub(now).Round(time.Second)
+}
+
+// String describes the budget, such as "requests: 49/50 remaining, resets
+// in 1s"
+func (l RateLimit) String() string {
+	s := fmt.Sprintf("%s: %d remaining", l.Resource, l.Remaining)
+	if l.Limit > 0 {
+		s = fmt.Sprintf("%s: %d/%d remaining", l.Resource, l.Remaining, l.Limit)
+	}
+	if in := l.ResetIn(time.Now()); in > 0 {
+		s += fmt.Sprintf(", resets in %s", in)
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package compatibility
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/models"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    []RateLimit
+	}{
+		{
+			name: "anthropic",
+			headers: map[string]string{
+				"anthropic-ratelimit-requests-limit":         "50",
+				"anthropic-ratelimit-requests-remaining":     "49",
+				"anthropic-ratelimit-requests-reset":         "2026-01-02T15:05:05Z",
+				"anthropic-ratelimit-input-tokens-limit":     "40000",
+				"anthropic-ratelimit-input-tokens-remaining": "0",
+			},
+			want: []RateLimit{
+				{Resource: "requests", Limit: 50, Remaining: 49, Reset: now.Add(time.Minute)},
+				{Resource: "input-tokens", Limit: 40000, Remaining: 0},
+			},
+		},
+		{
+			name: "openai",
+			headers: map[string]string{
+				"x-ratelimit-limit-requests":     "500",
+				"x-ratelimit-remaining-requests": "499",
+				"x-ratelimit-reset-requests":     "120ms",
+				"x-ratelimit-remaining-tokens":   "149984",
+				"x-ratelimit-reset-tokens":       "6",
+			},
+			want: []RateLimit{
+				{Resource: "requests", Limit: 500, Remaining: 499, Reset: now.Add(120 * time.Millisecond)},
+				{Resource: "tokens", Remaining: 149984, Reset: now.Add(6 * time.Second)},
+			},
+		},
+		{
+			name:    "malformed or missing",
+			headers: map[string]string{"x-ratelimit-remaining-requests": "many"},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			if got := ParseRateLimits(h, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRateLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitString(t *testing.T) {
+	limit := RateLimit{Resource: "requests", Limit: 50, Remaining: 49, Reset: time.Now().Add(90 * time.Second)}
+	if got := limit.String(); !strings.HasPrefix(got, "requests: 49/50 remaining, resets in 1m") {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (RateLimit{Resource: "tokens", Remaining: 7}).String(); got != "tokens: 7 remaining" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// TestTestBasic_RateLimits tests that the budgets of the response are
+// reported with the result
+func TestTestBasic_RateLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "49")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+	tester, err := NewTester(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := tester.RunFullTest(false)
+	if err != nil {
+		t.Fatalf("RunFullTest() unexpected error: %v", err)
+	}
+	if len(result.RateLimits) != 1 || result.RateLimits[0].Remaining != 49 {
+		t.Fatalf("RateLimits = %+v, want the requests budget", result.RateLimits)
+	}
+
+	var out bytes.Buffer
+	if err := NewReporter(&out).Report(result); err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Rate Limits:\n  requests: 49/50 remaining") {
+		t.Errorf("Report() should list the rate limits, got:\n%s", out.String())
+	}
+}
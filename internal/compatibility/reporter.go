@@ -3,15 +3,20 @@
 package compatibility
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+
+	"apimgr/internal/utils"
 )
 
 // Reporter formats and outputs diagnostic results from compatibility tests.
 type Reporter struct {
 	jsonOutput bool
+	delimiter  rune // CSV or TSV field delimiter, 0 for JSON or text output
 	verbose    bool
 	writer     io.Writer
 }
@@ -26,6 +31,14 @@ func WithJSONOutput(jsonOutput bool) ReporterOption {
 	}
 }
 
+// WithDelimitedOutput enables CSV or TSV output, one row per check, with
+// comma as the field delimiter
+func WithDelimitedOutput(comma rune) ReporterOption {
+	return func(r *Reporter) {
+		r.delimiter = comma
+	}
+}
+
 // WithVerboseOutput enables verbose output
 func WithVerboseOutput(verbose bool) ReporterOption {
 	return func(r *Reporter) {
@@ -64,6 +77,7 @@ type DiagnosticOutput struct {
 	CompatibilityLevel   string        `json:"compatibilityLevel"`
 	Checks               []CheckResult `json:"checks"`
 	ResponseTimeMs       int64         `json:"responseTimeMs"`
+	RateLimits           []RateLimit   `json:"rateLimits,omitempty"`
 	Error                string        `json:"error,omitempty"`
 }
 
@@ -75,6 +89,9 @@ type VerboseData struct {
 
 // Report outputs the test result in the configured format.
 func (r *Reporter) Report(result *TestResult) error {
+	if r.delimiter != 0 {
+		return r.reportDelimited(result)
+	}
 	if r.jsonOutput {
 		return r.reportJSON(result)
 	}
@@ -84,6 +101,9 @@ func (r *Reporter) Report(result *TestResult) error {
 
 // ReportWithVerbose outputs the test result with optional verbose data.
 func (r *Reporter) ReportWithVerbose(result *TestResult, verboseData *VerboseData) error {
+	if r.delimiter != 0 {
+		return r.reportDelimited(result)
+	}
 	if r.jsonOutput {
 		return r.reportJSONWithVerbose(result, verboseData)
 	}
@@ -127,6 +147,7 @@ func (r *Reporter) buildDiagnosticOutput(result *TestResult) DiagnosticOutput {
 		CompatibilityLevel:   result.CompatibilityLevel,
 		Checks:               result.Checks,
 		ResponseTimeMs:       result.ResponseTime.Milliseconds(),
+		RateLimits:           result.RateLimits,
 		Error:                result.Error,
 	}
 	return output
@@ -139,6 +160,23 @@ func (r *Reporter) writeJSON(output interface{}) error {
 	return encoder.Encode(output)
 }
 
+// reportDelimited outputs a header row and one row per check as CSV or TSV
+func (r *Reporter) reportDelimited(result *TestResult) error {
+	w := csv.NewWriter(r.writer)
+	w.Comma = r.delimiter
+	if err := w.Write([]string{"check", "passed", "critical", "message"}); err != nil {
+		return err
+	}
+	for _, check := range result.Checks {
+		record := []string{check.Name, strconv.FormatBool(check.Passed), strconv.FormatBool(check.Critical), utils.Redact(check.Message)}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
 // reportText outputs the result in human-readable text format
 func (r *Reporter) reportText(result *TestResult) error {
 	return r.reportTextWithVerbose(result, nil)
@@ -166,6 +204,15 @@ func (r *Reporter) reportTextWithVerbose(result *TestResult, verboseData *Verbos
 	sb.WriteString(fmt.Sprintf("  Response Time:  %dms\n", result.ResponseTime.Milliseconds()))
 	sb.WriteString("\n")
 
+	// Rate limit budgets, when the endpoint reports them
+	if len(result.RateLimits) > 0 {
+		sb.WriteString("Rate Limits:\n")
+		for _, limit := range result.RateLimits {
+			sb.WriteString(fmt.Sprintf("  %s\n", limit))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Detailed checks
 	sb.WriteString("Checks:\n")
 	for _, check := range result.Checks {
@@ -326,14 +373,14 @@ func (r *Reporter) getStreamingSupport(result *TestResult) string {
 func (r *Reporter) ReportError(err error, category string) error {
 	if r.jsonOutput {
 		output := map[string]string{
-			"error":    err.Error(),
+			"error":    utils.Redact(err.Error()),
 			"category": category,
 		}
 		return r.writeJSON(output)
 	}
 
 	emoji := "❌"
-	_, writeErr := fmt.Fprintf(r.writer, "%s Error [%s]: %s\n", emoji, category, err.Error())
+	_, writeErr := fmt.Fprintf(r.writer, "%s Error [%s]: %s\n", emoji, category, utils.Redact(err.Error()))
 	return writeErr
 }
 
@@ -287,6 +287,27 @@ func TestReporterJSONOutput(t *testing.T) {
 	}
 }
 
+func TestReporterDelimitedOutput(t *testing.T) {
+	result := &TestResult{
+		CompatibilityLevel: CompatibilityNone,
+		Checks: []CheckResult{
+			{Name: "Connection", Passed: true, Message: "Connected", Critical: true},
+			{Name: "Authentication", Passed: false, Message: "Auth failed, \"invalid key\"", Critical: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewReporter(&buf, WithJSONOutput(true), WithDelimitedOutput(',')).Report(result); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	want := "check,passed,critical,message\n" +
+		"Connection,true,true,Connected\n" +
+		"Authentication,false,true,\"Auth failed, \"\"invalid key\"\"\"\n"
+	if buf.String() != want {
+		t.Errorf("CSV output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
 // TestReporterVerboseOutput tests verbose mode output
 func TestReporterVerboseOutput(t *testing.T) {
 	result := &TestResult{
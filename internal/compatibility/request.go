@@ -4,9 +4,12 @@ package compatibility
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"apimgr/config/models"
@@ -17,6 +20,15 @@ import (
 type RequestBuilder interface {
 	// BuildChatRequest builds a chat completion request for the provider
 	BuildChatRequest(model string, streaming bool) (*http.Request, error)
+	// BuildPromptRequest builds a chat completion request sending prompt as
+	// the user message
+	BuildPromptRequest(model, prompt string, streaming bool) (*http.Request, error)
+	// BuildImageRequest builds a chat completion request sending a PNG image
+	// followed by prompt
+	BuildImageRequest(model, prompt string, png []byte) (*http.Request, error)
+	// BuildSystemRequest builds a chat completion request sending prompt
+	// under the system prompt system, with stop sequences stop
+	BuildSystemRequest(model, system, prompt string, stop []string) (*http.Request, error)
 	// GetEndpoint returns the API endpoint path
 	GetEndpoint() string
 	// GetHeaders returns the headers required for the request
@@ -26,22 +38,66 @@ type RequestBuilder interface {
 // ChatMessage represents a message in the chat request
 type ChatMessage struct {
 	Role    string `json:"role"`
-	Content string `json:"content"`
+	Content any    `json:"content"` // A string, or a list of content blocks
+}
+
+// chatTurn is what a test request sends: an optional system prompt, one
+// user message and optional stop sequences
+type chatTurn struct {
+	system  string
+	content any // A string, or a list of content blocks
+	stop    []string
 }
 
 // AnthropicRequestBuilder builds requests for the Anthropic Messages API
 type AnthropicRequestBuilder struct {
-	baseURL   string
-	apiKey    string
-	authToken string
+	baseURL      string
+	apiKey       string
+	authToken    string
+	apiVersion   string   // anthropic-version header, DefaultAPIVersion when empty
+	betaFeatures []string // anthropic-beta header values
+	sampling
+}
+
+// DefaultMaxTokens is the output token limit of test requests when a config
+// sets none
+const DefaultMaxTokens = 100
+
+// sampling holds the generation parameters sent with test requests, so they
+// match how the endpoint is used
+type sampling struct {
+	maxTokens   int      // DefaultMaxTokens when 0
+	temperature *float64 // Omitted when nil
+	topP        *float64 // Omitted when nil
+}
+
+// samplingOf returns the generation parameters of cfg
+func samplingOf(cfg *models.APIConfig) sampling {
+	return sampling{maxTokens: cfg.MaxTokens, temperature: cfg.Temperature, topP: cfg.TopP}
+}
+
+// tokenLimit returns the max_tokens value of a request
+func (s sampling) tokenLimit() int {
+	if s.maxTokens > 0 {
+		return s.maxTokens
+	}
+	return DefaultMaxTokens
 }
 
+// DefaultAPIVersion is the anthropic-version header sent when a config does
+// not pin one
+const DefaultAPIVersion = "2023-06-01"
+
 // AnthropicRequest represents the request body for Anthropic Messages API
 type AnthropicRequest struct {
-	Model     string        `json:"model"`
-	MaxTokens int           `json:"max_tokens"`
-	Messages  []ChatMessage `json:"messages"`
-	Stream    bool          `json:"stream,omitempty"`
+	Model         string        `json:"model"`
+	MaxTokens     int           `json:"max_tokens"`
+	System        string        `json:"system,omitempty"`
+	Messages      []ChatMessage `json:"messages"`
+	StopSequences []string      `json:"stop_sequences,omitempty"`
+	Stream        bool          `json:"stream,omitempty"`
+	Temperature   *float64      `json:"temperature,omitempty"`
+	TopP          *float64      `json:"top_p,omitempty"`
 }
 
 // GetEndpoint returns the Anthropic Messages API endpoint
@@ -54,7 +110,13 @@ func (b *AnthropicRequestBuilder) GetEndpoint() string {
 func (b *AnthropicRequestBuilder) GetHeaders() map[string]string {
 	headers := map[string]string{
 		"Content-Type":      "application/json",
-		"anthropic-version": "2023-06-01",
+		"anthropic-version": DefaultAPIVersion,
+	}
+	if b.apiVersion != "" {
+		headers["anthropic-version"] = b.apiVersion
+	}
+	if len(b.betaFeatures) > 0 {
+		headers["anthropic-beta"] = strings.Join(b.betaFeatures, ",")
 	}
 
 	if b.apiKey != "" {
@@ -69,12 +131,46 @@ func (b *AnthropicRequestBuilder) GetHeaders() map[string]string {
 
 // BuildChatRequest builds a chat completion request for Anthropic Messages API
 func (b *AnthropicRequestBuilder) BuildChatRequest(model string, streaming bool) (*http.Request, error) {
+	return b.BuildPromptRequest(model, "ping", streaming)
+}
+
+// BuildPromptRequest builds a chat completion request for Anthropic Messages API
+// sending prompt
+func (b *AnthropicRequestBuilder) BuildPromptRequest(model, prompt string, streaming bool) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{content: prompt}, streaming)
+}
+
+// BuildImageRequest builds a chat completion request for Anthropic Messages API
+// sending a PNG image followed by prompt
+func (b *AnthropicRequestBuilder) BuildImageRequest(model, prompt string, png []byte) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{content: []any{
+		map[string]any{
+			"type":   "image",
+			"source": map[string]string{"type": "base64", "media_type": "image/png", "data": base64.StdEncoding.EncodeToString(png)},
+		},
+		map[string]string{"type": "text", "text": prompt},
+	}}, false)
+}
+
+// BuildSystemRequest builds a chat completion request for Anthropic Messages API
+// sending prompt under a system prompt, with stop sequences
+func (b *AnthropicRequestBuilder) BuildSystemRequest(model, system, prompt string, stop []string) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{system: system, content: prompt, stop: stop}, false)
+}
+
+// buildRequest builds a chat completion request for Anthropic Messages API
+// sending turn
+func (b *AnthropicRequestBuilder) buildRequest(model string, turn chatTurn, streaming bool) (*http.Request, error) {
 	reqBody := AnthropicRequest{
 		Model:     model,
-		MaxTokens: 100,
+		MaxTokens: b.tokenLimit(),
+		System:    turn.system,
 		Messages: []ChatMessage{
-			{Role: "user", Content: "ping"},
+			{Role: "user", Content: turn.content},
 		},
+		StopSequences: turn.stop,
+		Temperature:   b.temperature,
+		TopP:          b.topP,
 	}
 
 	if streaming {
@@ -103,14 +199,19 @@ func (b *AnthropicRequestBuilder) BuildChatRequest(model string, streaming bool)
 type OpenAIRequestBuilder struct {
 	baseURL string
 	apiKey  string
+	headers map[string]string // Extra headers, e.g. the organization and project
+	sampling
 }
 
 // OpenAIRequest represents the request body for OpenAI Chat Completions API
 type OpenAIRequest struct {
-	Model     string        `json:"model"`
-	MaxTokens int           `json:"max_tokens"`
-	Messages  []ChatMessage `json:"messages"`
-	Stream    bool          `json:"stream,omitempty"`
+	Model       string        `json:"model"`
+	MaxTokens   int           `json:"max_tokens"`
+	Messages    []ChatMessage `json:"messages"`
+	Stop        []string      `json:"stop,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	TopP        *float64      `json:"top_p,omitempty"`
 }
 
 // GetEndpoint returns the OpenAI Chat Completions API endpoint
@@ -120,20 +221,77 @@ func (b *OpenAIRequestBuilder) GetEndpoint() string {
 
 // GetHeaders returns the headers required for OpenAI API requests
 func (b *OpenAIRequestBuilder) GetHeaders() map[string]string {
-	return map[string]string{
+	headers := map[string]string{
 		"Content-Type":  "application/json",
 		"Authorization": "Bearer " + b.apiKey,
 	}
+	maps.Copy(headers, b.headers)
+	return headers
+}
+
+// scopeHeaders returns the headers naming the organization and project of
+// org- or project-scoped keys, as the provider's SDK sends them
+func scopeHeaders(providerName string, cfg *models.APIConfig) map[string]string {
+	headers := make(map[string]string)
+	switch providerName {
+	case "openai":
+		if cfg.OrgID != "" {
+			headers["OpenAI-Organization"] = cfg.OrgID
+		}
+		if cfg.ProjectID != "" {
+			headers["OpenAI-Project"] = cfg.ProjectID
+		}
+	case "gemini":
+		if cfg.ProjectID != "" {
+			headers["x-goog-user-project"] = cfg.ProjectID
+		}
+	}
+	return headers
 }
 
 // BuildChatRequest builds a chat completion request for OpenAI Chat Completions API
 func (b *OpenAIRequestBuilder) BuildChatRequest(model string, streaming bool) (*http.Request, error) {
-	reqBody := OpenAIRequest{
-		Model:     model,
-		MaxTokens: 100,
-		Messages: []ChatMessage{
-			{Role: "user", Content: "ping"},
+	return b.BuildPromptRequest(model, "ping", streaming)
+}
+
+// BuildPromptRequest builds a chat completion request for OpenAI Chat Completions API
+// sending prompt
+func (b *OpenAIRequestBuilder) BuildPromptRequest(model, prompt string, streaming bool) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{content: prompt}, streaming)
+}
+
+// BuildImageRequest builds a chat completion request for OpenAI Chat Completions API
+// sending a PNG image followed by prompt
+func (b *OpenAIRequestBuilder) BuildImageRequest(model, prompt string, png []byte) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{content: []any{
+		map[string]any{
+			"type":      "image_url",
+			"image_url": map[string]string{"url": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)},
 		},
+		map[string]string{"type": "text", "text": prompt},
+	}}, false)
+}
+
+// BuildSystemRequest builds a chat completion request for OpenAI Chat Completions API
+// sending prompt under a system prompt, with stop sequences
+func (b *OpenAIRequestBuilder) BuildSystemRequest(model, system, prompt string, stop []string) (*http.Request, error) {
+	return b.buildRequest(model, chatTurn{system: system, content: prompt, stop: stop}, false)
+}
+
+// buildRequest builds a chat completion request for OpenAI Chat Completions API
+// sending turn, whose system prompt is a system message
+func (b *OpenAIRequestBuilder) buildRequest(model string, turn chatTurn, streaming bool) (*http.Request, error) {
+	var messages []ChatMessage
+	if turn.system != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: turn.system})
+	}
+	reqBody := OpenAIRequest{
+		Model:       model,
+		MaxTokens:   b.tokenLimit(),
+		Messages:    append(messages, ChatMessage{Role: "user", Content: turn.content}),
+		Stop:        turn.stop,
+		Temperature: b.temperature,
+		TopP:        b.topP,
 	}
 
 	if streaming {
@@ -145,7 +303,7 @@ func (b *OpenAIRequestBuilder) BuildChatRequest(model string, streaming bool) (*
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	url := strings.TrimSuffix(b.baseURL, "/") + b.GetEndpoint()
+	url := openAIEndpointURL(b.baseURL, b.GetEndpoint())
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -158,34 +316,61 @@ func (b *OpenAIRequestBuilder) BuildChatRequest(model string, streaming bool) (*
 	return req, nil
 }
 
+// versionSuffix matches a base URL ending in an API version, like the /v1
+// of https://api.openai.com/v1
+var versionSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+// openAIEndpointURL joins a base URL and an endpoint. Base URLs of
+// OpenAI-compatible APIs usually include the version, which is then not
+// added again.
+func openAIEndpointURL(baseURL, endpoint string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if versionSuffix.MatchString(baseURL) {
+		return baseURL + strings.TrimPrefix(endpoint, "/v1")
+	}
+	return baseURL + endpoint
+}
+
 // NewRequestBuilder creates a new RequestBuilder based on the provider type
 func NewRequestBuilder(cfg *models.APIConfig, provider providers.Provider) RequestBuilder {
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = provider.DefaultBaseURL()
-	}
+	baseURL := requestBaseURL(cfg, provider)
 
 	switch provider.Name() {
 	case "anthropic":
 		return &AnthropicRequestBuilder{
-			baseURL:   baseURL,
-			apiKey:    cfg.APIKey,
-			authToken: cfg.AuthToken,
+			baseURL:      baseURL,
+			apiKey:       cfg.APIKey,
+			authToken:    cfg.AuthToken,
+			apiVersion:   cfg.APIVersion,
+			betaFeatures: cfg.BetaFeatures,
+			sampling:     samplingOf(cfg),
 		}
 	case "openai":
 		return &OpenAIRequestBuilder{
-			baseURL: baseURL,
-			apiKey:  cfg.APIKey,
+			baseURL:  baseURL,
+			apiKey:   cfg.APIKey,
+			headers:  scopeHeaders(provider.Name(), cfg),
+			sampling: samplingOf(cfg),
 		}
 	default:
 		// Default to OpenAI-compatible format for unknown providers
 		return &OpenAIRequestBuilder{
-			baseURL: baseURL,
-			apiKey:  cfg.APIKey,
+			baseURL:  baseURL,
+			apiKey:   cfg.APIKey,
+			headers:  scopeHeaders(provider.Name(), cfg),
+			sampling: samplingOf(cfg),
 		}
 	}
 }
 
+// requestBaseURL returns the base URL of cfg, or the provider's default
+func requestBaseURL(cfg *models.APIConfig, provider providers.Provider) string {
+	if cfg.BaseURL != "" {
+		return cfg.BaseURL
+	}
+	return provider.DefaultBaseURL()
+}
+
 // NewRequestBuilderWithCustomPath creates a RequestBuilder with a custom endpoint path
 func NewRequestBuilderWithCustomPath(cfg *models.APIConfig, provider providers.Provider, customPath string) RequestBuilder {
 	builder := NewRequestBuilder(cfg, provider)
@@ -194,6 +379,7 @@ func NewRequestBuilderWithCustomPath(cfg *models.APIConfig, provider providers.P
 	if customPath != "" {
 		return &customPathBuilder{
 			RequestBuilder: builder,
+			baseURL:        requestBaseURL(cfg, provider),
 			customPath:     customPath,
 		}
 	}
@@ -204,6 +390,7 @@ func NewRequestBuilderWithCustomPath(cfg *models.APIConfig, provider providers.P
 // customPathBuilder wraps a RequestBuilder to use a custom endpoint path
 type customPathBuilder struct {
 	RequestBuilder
+	baseURL    string
 	customPath string
 }
 
@@ -218,11 +405,41 @@ func (b *customPathBuilder) BuildChatRequest(model string, streaming bool) (*htt
 	if err != nil {
 		return nil, err
 	}
+	return b.withCustomPath(req)
+}
+
+// BuildPromptRequest builds a request sending prompt using the custom path
+func (b *customPathBuilder) BuildPromptRequest(model, prompt string, streaming bool) (*http.Request, error) {
+	req, err := b.RequestBuilder.BuildPromptRequest(model, prompt, streaming)
+	if err != nil {
+		return nil, err
+	}
+	return b.withCustomPath(req)
+}
+
+// BuildImageRequest builds a request sending an image using the custom path
+func (b *customPathBuilder) BuildImageRequest(model, prompt string, png []byte) (*http.Request, error) {
+	req, err := b.RequestBuilder.BuildImageRequest(model, prompt, png)
+	if err != nil {
+		return nil, err
+	}
+	return b.withCustomPath(req)
+}
+
+// BuildSystemRequest builds a request with a system prompt using the
+// custom path
+func (b *customPathBuilder) BuildSystemRequest(model, system, prompt string, stop []string) (*http.Request, error) {
+	req, err := b.RequestBuilder.BuildSystemRequest(model, system, prompt, stop)
+	if err != nil {
+		return nil, err
+	}
+	return b.withCustomPath(req)
+}
 
-	// Replace the URL path with custom path
-	originalURL := req.URL.String()
-	baseURL := strings.TrimSuffix(originalURL, b.RequestBuilder.GetEndpoint())
-	newURL := strings.TrimSuffix(baseURL, "/") + b.customPath
+// withCustomPath returns req sent to the custom path instead
+func (b *customPathBuilder) withCustomPath(req *http.Request) (*http.Request, error) {
+	// Replace the endpoint with the custom path
+	newURL := strings.TrimSuffix(b.baseURL, "/") + b.customPath
 
 	newReq, err := http.NewRequest(req.Method, newURL, req.Body)
 	if err != nil {
@@ -215,7 +215,8 @@ func TestProperty1_ProviderSpecificRequestFormat(t *testing.T) {
 				return false
 			}
 
-			expectedURL := strings.TrimSuffix(provider.DefaultBaseURL(), "/") + builder.GetEndpoint()
+			// A base URL ending in /v1 does not get it twice
+			expectedURL := strings.TrimSuffix(strings.TrimSuffix(provider.DefaultBaseURL(), "/"), "/v1") + builder.GetEndpoint()
 			return req.URL.String() == expectedURL
 		},
 		gen.OneConstOf("anthropic", "openai"),
@@ -576,3 +577,107 @@ func TestProperty8_CustomPathOverride(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestAnthropicVersionHeaders(t *testing.T) {
+	provider, err := providers.Get("anthropic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		cfg         models.APIConfig
+		wantVersion string
+		wantBeta    string
+	}{
+		{"defaults", models.APIConfig{APIKey: "sk"}, DefaultAPIVersion, ""},
+		{"pinned", models.APIConfig{APIKey: "sk", APIVersion: "2024-01-01", BetaFeatures: []string{"a", "b"}}, "2024-01-01", "a,b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewRequestBuilder(&tt.cfg, provider).BuildChatRequest("m", false)
+			if err != nil {
+				t.Fatalf("BuildChatRequest() unexpected error: %v", err)
+			}
+			if got := req.Header.Get("anthropic-version"); got != tt.wantVersion {
+				t.Errorf("anthropic-version = %q, want %q", got, tt.wantVersion)
+			}
+			if got := req.Header.Get("anthropic-beta"); got != tt.wantBeta {
+				t.Errorf("anthropic-beta = %q, want %q", got, tt.wantBeta)
+			}
+		})
+	}
+}
+
+func TestScopeHeaders(t *testing.T) {
+	cfg := &models.APIConfig{APIKey: "sk", OrgID: "org-1", ProjectID: "proj_1"}
+	tests := []struct {
+		provider string
+		want     map[string]string
+	}{
+		{"openai", map[string]string{"OpenAI-Organization": "org-1", "OpenAI-Project": "proj_1"}},
+		{"gemini", map[string]string{"x-goog-user-project": "proj_1"}},
+		{"anthropic", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			provider, err := providers.Get(tt.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := NewRequestBuilder(cfg, provider).BuildChatRequest("m", false)
+			if err != nil {
+				t.Fatalf("BuildChatRequest() unexpected error: %v", err)
+			}
+			for name, value := range tt.want {
+				if got := req.Header.Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+			if len(tt.want) == 0 && req.Header.Get("OpenAI-Organization") != "" {
+				t.Error("anthropic request has an OpenAI-Organization header")
+			}
+		})
+	}
+}
+
+func TestSamplingParams(t *testing.T) {
+	temperature, topP := 0.0, 0.9
+	tests := []struct {
+		name string
+		cfg  models.APIConfig
+		want string // Request body fields after the messages
+	}{
+		{"defaults", models.APIConfig{}, `"max_tokens":100`},
+		{"config values", models.APIConfig{Temperature: &temperature, MaxTokens: 4096, TopP: &topP}, `"max_tokens":4096,"temperature":0,"top_p":0.9`},
+	}
+
+	for _, tt := range tests {
+		for _, providerName := range []string{"anthropic", "openai"} {
+			t.Run(tt.name+"/"+providerName, func(t *testing.T) {
+				provider, err := providers.Get(providerName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cfg := tt.cfg
+				cfg.APIKey = "sk"
+				req, err := NewRequestBuilder(&cfg, provider).BuildChatRequest("m", false)
+				if err != nil {
+					t.Fatalf("BuildChatRequest() unexpected error: %v", err)
+				}
+				var body map[string]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				delete(body, "model")
+				delete(body, "messages")
+				got, _ := json.Marshal(body)
+				if want := "{" + tt.want + "}"; string(got) != want {
+					t.Errorf("request body = %s, want %s", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package compatibility
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"apimgr/internal/utils"
+)
+
+// EndpointProbe is the outcome of one request sent by ResolveBaseURL
+type EndpointProbe struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Found      bool   `json:"found"` // Anything but an endpoint-not-found error
+}
+
+// BaseURLResolution is the base URL found by ResolveBaseURL
+type BaseURLResolution struct {
+	BaseURL string          `json:"baseUrl"` // Empty when no probed path exists
+	Probes  []EndpointProbe `json:"probes"`
+}
+
+// ResolveBaseURL probes the chat endpoint below the configured base URL with
+// and without the /v1 path prefix, since relays differ in which one they
+// serve, and returns the base URL that reaches the endpoint that answered.
+// For the Anthropic format that is the URL without /v1, which Claude Code
+// adds itself; for the OpenAI format the version stays in the base URL.
+// Probes are returned along with an error when no usable form is found.
+func (t *Tester) ResolveBaseURL() (*BaseURLResolution, error) {
+	builder := NewRequestBuilder(t.config, t.provider)
+	root := strings.TrimSuffix(utils.NormalizeBaseURL(requestBaseURL(t.config, t.provider)), "/v1")
+	endpoint := strings.TrimPrefix(builder.GetEndpoint(), "/v1")
+	anthropic := t.provider.Name() == "anthropic"
+
+	resolution := &BaseURLResolution{}
+	for _, candidate := range []string{root + "/v1" + endpoint, root + endpoint} {
+		probe, err := t.probeEndpoint(builder, candidate)
+		if err != nil {
+			return resolution, err
+		}
+		resolution.Probes = append(resolution.Probes, *probe)
+	}
+
+	withVersion, without := resolution.Probes[0].Found, resolution.Probes[1].Found
+	switch {
+	case withVersion && anthropic:
+		resolution.BaseURL = root
+	case withVersion:
+		resolution.BaseURL = root + "/v1"
+	case without && !anthropic && versionSuffix.MatchString(root):
+		resolution.BaseURL = root
+	case without && anthropic:
+		return resolution, fmt.Errorf("%s serves %s without the /v1 prefix, which Claude Code always adds", root, endpoint)
+	case without:
+		return resolution, fmt.Errorf("%s serves %s without an API version, which the compatibility test cannot reach", root, endpoint)
+	default:
+		return resolution, fmt.Errorf("neither %s nor %s exists, check the base URL", resolution.Probes[0].URL, resolution.Probes[1].URL)
+	}
+	return resolution, nil
+}
+
+// probeEndpoint sends the test request of builder to rawURL and reports
+// whether the endpoint exists. Errors about the key or the model still
+// prove that it does.
+func (t *Tester) probeEndpoint(builder RequestBuilder, rawURL string) (*EndpointProbe, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	build := func() (*http.Request, error) {
+		req, err := builder.BuildChatRequest(t.getModel(), false)
+		if err != nil {
+			return nil, err
+		}
+		req.URL, req.Host = target, target.Host
+		return req, nil
+	}
+
+	req, err := build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := t.doWithRetry(req, build)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	notFound := resp.StatusCode == http.StatusMethodNotAllowed ||
+		(resp.StatusCode == http.StatusNotFound && CategorizeError(resp.StatusCode, body) == ErrorCategoryEndpointNotFound)
+	return &EndpointProbe{URL: rawURL, StatusCode: resp.StatusCode, Found: !notFound}, nil
+}
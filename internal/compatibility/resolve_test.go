@@ -0,0 +1,84 @@
+package compatibility
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestResolveBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		base     string // Appended to the server URL
+		serves   string // Only path answered by the server
+		want     string // Appended to the server URL, or "error"
+	}{
+		{"anthropic with /v1 in the base URL", "anthropic", "/v1/", "/v1/messages", ""},
+		{"anthropic already resolved", "anthropic", "", "/v1/messages", ""},
+		{"anthropic below a path", "anthropic", "/api/v1", "/api/v1/messages", "/api"},
+		{"anthropic without /v1", "anthropic", "", "/messages", "error"},
+		{"openai without /v1 in the base URL", "openai", "", "/v1/chat/completions", "/v1"},
+		{"openai already resolved", "openai", "/v1", "/v1/chat/completions", "/v1"},
+		{"openai with another version", "openai", "/api/v4", "/api/v4/chat/completions", "/api/v4"},
+		{"openai without a version", "openai", "/api", "/api/chat/completions", "error"},
+		{"nothing answers", "anthropic", "", "/other", "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.serves {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: tt.provider, APIKey: "test-key", BaseURL: server.URL + tt.base}
+			tester, err := NewTester(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resolution, err := tester.ResolveBaseURL()
+			if len(resolution.Probes) != 2 {
+				t.Fatalf("ResolveBaseURL() probes = %+v, want two", resolution.Probes)
+			}
+			if tt.want == "error" {
+				if err == nil {
+					t.Errorf("ResolveBaseURL() = %q, want an error", resolution.BaseURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveBaseURL() unexpected error: %v", err)
+			}
+			if resolution.BaseURL != server.URL+tt.want {
+				t.Errorf("ResolveBaseURL() = %q, want %q", resolution.BaseURL, server.URL+tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIEndpointURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"https://api.openai.com", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/v1", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/v1/", "https://api.openai.com/v1/chat/completions"},
+		{"https://open.bigmodel.cn/api/paas/v4", "https://open.bigmodel.cn/api/paas/v4/chat/completions"},
+		{"https://relay.example.com/openai", "https://relay.example.com/openai/v1/chat/completions"},
+	}
+
+	for _, tt := range tests {
+		if got := openAIEndpointURL(tt.base, "/v1/chat/completions"); got != tt.want {
+			t.Errorf("openAIEndpointURL(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
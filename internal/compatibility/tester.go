@@ -3,15 +3,19 @@
 package compatibility
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"apimgr/config/models"
 	"apimgr/internal/providers"
+	"apimgr/internal/utils"
 )
 
 // ProviderURLPatterns maps URL patterns to provider names for auto-detection
@@ -22,29 +26,132 @@ var ProviderURLPatterns = map[string]string{
 	"openai.com":        "openai",
 }
 
+// Service is a hosted API recognized from its base URL
+type Service struct {
+	Name     string   // Display name
+	Provider string   // API the URL speaks: anthropic or openai
+	Models   []string // Suggested models, the first being the default
+}
+
+// knownService matches the base URLs of a hosted API
+type knownService struct {
+	Service
+	host      string                 // Host, subdomains included
+	anthropic func(path string) bool // Whether a URL path is the Anthropic-compatible endpoint
+}
+
+// hasAnthropicPrefix reports whether the path is under /anthropic
+func hasAnthropicPrefix(path string) bool {
+	return path == "/anthropic" || strings.HasPrefix(path, "/anthropic/")
+}
+
+// knownServices lists hosted APIs with their models. Several serve an
+// Anthropic-compatible endpoint next to their OpenAI-compatible one.
+var knownServices = []knownService{
+	{
+		Service: Service{Name: "OpenRouter", Provider: "openai", Models: []string{"anthropic/claude-sonnet-4", "anthropic/claude-opus-4"}},
+		host:    "openrouter.ai",
+		// https://openrouter.ai/api is Anthropic-compatible, /api/v1 OpenAI-compatible
+		anthropic: func(path string) bool { return strings.Trim(path, "/") == "api" },
+	},
+	{
+		Service:   Service{Name: "DeepSeek", Provider: "openai", Models: []string{"deepseek-chat", "deepseek-reasoner"}},
+		host:      "deepseek.com",
+		anthropic: hasAnthropicPrefix,
+	},
+	{
+		Service:   Service{Name: "Moonshot", Provider: "openai", Models: []string{"kimi-k2-0905-preview", "kimi-k2-turbo-preview"}},
+		host:      "moonshot.ai",
+		anthropic: hasAnthropicPrefix,
+	},
+	{
+		Service:   Service{Name: "Moonshot", Provider: "openai", Models: []string{"kimi-k2-0905-preview", "kimi-k2-turbo-preview"}},
+		host:      "moonshot.cn",
+		anthropic: hasAnthropicPrefix,
+	},
+	{
+		Service: Service{Name: "Groq", Provider: "openai", Models: []string{"llama-3.3-70b-versatile", "openai/gpt-oss-120b"}},
+		host:    "groq.com",
+	},
+	{
+		Service: Service{Name: "Together", Provider: "openai", Models: []string{"meta-llama/Llama-3.3-70B-Instruct-Turbo", "deepseek-ai/DeepSeek-V3"}},
+		host:    "together.xyz",
+	},
+	{
+		Service: Service{Name: "Together", Provider: "openai", Models: []string{"meta-llama/Llama-3.3-70B-Instruct-Turbo", "deepseek-ai/DeepSeek-V3"}},
+		host:    "together.ai",
+	},
+}
+
 // DetectProviderFromURL attempts to detect the provider type from a base URL.
 // It returns the detected provider name and a boolean indicating if detection was successful.
 // If the URL is ambiguous or doesn't match known patterns, it returns empty string and false.
 func DetectProviderFromURL(baseURL string) (string, bool) {
-	if baseURL == "" {
+	host, _ := parseHost(baseURL)
+	if host == "" {
 		return "", false
 	}
 
+	// Check for exact matches first
+	if provider, ok := ProviderURLPatterns[host]; ok {
+		return provider, true
+	}
+
+	// Check for suffix matches (e.g., subdomain.api.anthropic.com)
+	for pattern, provider := range ProviderURLPatterns {
+		if strings.HasSuffix(host, "."+pattern) || host == pattern {
+			return provider, true
+		}
+	}
+
+	if service, ok := DetectService(baseURL); ok {
+		return service.Provider, true
+	}
+	return "", false
+}
+
+// DetectService returns the hosted API a base URL belongs to, with the
+// provider matching the endpoint's path
+func DetectService(baseURL string) (Service, bool) {
+	host, path := parseHost(baseURL)
+	if host == "" {
+		return Service{}, false
+	}
+	for _, known := range knownServices {
+		if host != known.host && !strings.HasSuffix(host, "."+known.host) {
+			continue
+		}
+		service := known.Service
+		service.Models = slices.Clone(service.Models)
+		if known.anthropic != nil && known.anthropic(path) {
+			service.Provider = "anthropic"
+		}
+		return service, true
+	}
+	return Service{}, false
+}
+
+// parseHost returns the lowercase host, without port, and the path of a
+// base URL, or an empty host when it cannot be parsed
+func parseHost(baseURL string) (host, path string) {
+	if baseURL == "" {
+		return "", ""
+	}
+
 	// Parse the URL to extract the host
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
-		return "", false
+		return "", ""
 	}
 
-	host := parsedURL.Host
-	if host == "" {
+	if parsedURL.Host == "" {
 		// Try parsing without scheme
 		parsedURL, err = url.Parse("https://" + baseURL)
 		if err != nil {
-			return "", false
+			return "", ""
 		}
-		host = parsedURL.Host
 	}
+	host = parsedURL.Host
 
 	// Remove port if present
 	if colonIdx := strings.LastIndex(host, ":"); colonIdx != -1 {
@@ -55,22 +162,14 @@ func DetectProviderFromURL(baseURL string) (string, bool) {
 	}
 
 	// Convert to lowercase for case-insensitive matching
-	host = strings.ToLower(host)
-
-	// Check for exact matches first
-	if provider, ok := ProviderURLPatterns[host]; ok {
-		return provider, true
-	}
+	return strings.ToLower(host), parsedURL.Path
+}
 
-	// Check for suffix matches (e.g., subdomain.api.anthropic.com)
-	for pattern, provider := range ProviderURLPatterns {
-		if strings.HasSuffix(host, "."+pattern) || host == pattern {
-			return provider, true
-		}
-	}
+// DefaultTimeout is the request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
 
-	return "", false
-}
+// DefaultBackoff is the initial delay between retries, doubled on each attempt
+const DefaultBackoff = time.Second
 
 // Tester coordinates compatibility testing for API configurations
 type Tester struct {
@@ -78,9 +177,22 @@ type Tester struct {
 	config     *models.APIConfig
 	provider   providers.Provider
 	verbose    bool
+	log        io.Writer // Where verbose output goes, stderr by default
 	customPath string
+	timeout    time.Duration   // Request timeout, 0 keeps the client's timeout
+	retries    int             // Extra attempts after a transient failure
+	backoff    time.Duration   // Initial delay between attempts
+	rotateKey  KeyRotation     // Picks another key of the pool after a key failure
+	ctx        context.Context // Cancels requests and retries when done
+	probeSize  int             // Prompt size in tokens of the context window check, 0 skips it
+	vision     bool            // Runs the vision check
+	fidelity   bool            // Runs the system prompt and stop sequence checks
 }
 
+// KeyRotation is told the status of each response to a request sent with
+// key and returns the key to resend it with, or false to keep the response
+type KeyRotation func(key string, status int) (string, bool)
+
 // TesterOption is a functional option for configuring a Tester
 type TesterOption func(*Tester)
 
@@ -91,7 +203,16 @@ func WithVerbose(verbose bool) TesterOption {
 	}
 }
 
-// WithCustomPath sets a custom endpoint path
+// WithLog sets where verbose output goes, so it stays out of results
+// printed to stdout
+func WithLog(w io.Writer) TesterOption {
+	return func(t *Tester) {
+		t.log = w
+	}
+}
+
+// WithCustomPath sets a custom endpoint path, overriding the config's
+// chat_path
 func WithCustomPath(path string) TesterOption {
 	return func(t *Tester) {
 		t.customPath = path
@@ -105,6 +226,68 @@ func WithHTTPClient(client *http.Client) TesterOption {
 	}
 }
 
+// WithTimeout sets the per-request timeout
+func WithTimeout(timeout time.Duration) TesterOption {
+	return func(t *Tester) {
+		t.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a request is retried after a transient
+// failure (network error, HTTP 429 or 5xx)
+func WithRetries(retries int) TesterOption {
+	return func(t *Tester) {
+		t.retries = retries
+	}
+}
+
+// WithBackoff sets the initial delay between retries
+func WithBackoff(backoff time.Duration) TesterOption {
+	return func(t *Tester) {
+		t.backoff = backoff
+	}
+}
+
+// WithContext sends requests with ctx, so canceling it aborts the test
+func WithContext(ctx context.Context) TesterOption {
+	return func(t *Tester) {
+		t.ctx = ctx
+	}
+}
+
+// WithContextProbe makes RunFullTest also send a prompt of about tokens
+// tokens, to check that the endpoint neither rejects nor truncates long
+// contexts. 0 skips the check.
+func WithContextProbe(tokens int) TesterOption {
+	return func(t *Tester) {
+		t.probeSize = tokens
+	}
+}
+
+// WithVisionCheck makes RunFullTest also check that the endpoint accepts
+// image input
+func WithVisionCheck(vision bool) TesterOption {
+	return func(t *Tester) {
+		t.vision = vision
+	}
+}
+
+// WithFidelityChecks makes RunFullTest also check that system prompts and
+// stop sequences are honored
+func WithFidelityChecks(fidelity bool) TesterOption {
+	return func(t *Tester) {
+		t.fidelity = fidelity
+	}
+}
+
+// WithKeyRotation resends requests whose key was rejected or rate limited
+// with the key returned by rotate
+func WithKeyRotation(rotate KeyRotation) TesterOption {
+	return func(t *Tester) {
+		t.rotateKey = rotate
+	}
+}
+
 // NewTester creates a new compatibility tester for the given API configuration.
 // It resolves the provider based on the config's Provider field, or auto-detects
 // from the base URL if the provider is not explicitly set.
@@ -130,13 +313,34 @@ func NewTester(cfg *models.APIConfig, opts ...TesterOption) (*Tester, error) {
 		return nil, fmt.Errorf("failed to resolve provider: %w", err)
 	}
 
+	if err := utils.CheckPlaceholders(cfg.BaseURL); err != nil {
+		return nil, err
+	}
+
+	// Honor the configuration's TLS options, e.g. for private CAs
+	tlsConfig, err := utils.NewTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout: DefaultTimeout,
+	}
+	if tlsConfig != nil {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
 	t := &Tester{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config:   cfg,
-		provider: provider,
-		verbose:  false,
+		client:     client,
+		config:     cfg,
+		provider:   provider,
+		verbose:    false,
+		log:        os.Stderr,
+		customPath: cfg.ChatPath, // Set for relays with non-standard routes
+		backoff:    DefaultBackoff,
+		ctx:        context.Background(),
 	}
 
 	// Apply options
@@ -144,9 +348,95 @@ func NewTester(cfg *models.APIConfig, opts ...TesterOption) (*Tester, error) {
 		opt(t)
 	}
 
+	// Rotated keys replace the key of a copy of the config
+	if t.rotateKey != nil {
+		rotated := cfg.Clone()
+		t.config = &rotated
+	}
+
+	// Apply the timeout to a copy so a shared client is left untouched
+	if t.timeout > 0 {
+		client := *t.client
+		client.Timeout = t.timeout
+		t.client = &client
+	}
+
 	return t, nil
 }
 
+// doWithRetry sends req, retrying transient failures with exponential
+// backoff. rebuild creates a fresh request for each retry because a request
+// body can only be read once.
+func (t *Tester) doWithRetry(req *http.Request, rebuild func() (*http.Request, error)) (*http.Response, error) {
+	resp, err := t.client.Do(req.WithContext(t.ctx))
+	for attempt := 0; attempt < t.retries && isRetryable(resp, err) && !t.rotates(resp); attempt++ {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(t.backoff << attempt):
+		case <-t.ctx.Done():
+			return nil, t.ctx.Err()
+		}
+
+		req, err = rebuild()
+		if err != nil {
+			return nil, err
+		}
+		resp, err = t.client.Do(req.WithContext(t.ctx))
+	}
+	return resp, err
+}
+
+// sendChat sends the chat request req, retrying transient failures and,
+// with a key rotation, resending it with another key when the key was
+// rejected or rate limited. build creates req again for a resend.
+func (t *Tester) sendChat(req *http.Request, build func(RequestBuilder) (*http.Request, error)) (*http.Response, error) {
+	for {
+		builder := t.getRequestBuilder()
+		resp, err := t.doWithRetry(req, func() (*http.Request, error) {
+			return build(builder)
+		})
+		if err != nil || t.rotateKey == nil {
+			return resp, err
+		}
+		next, ok := t.rotateKey(t.config.APIKey, resp.StatusCode)
+		if !ok || next == t.config.APIKey {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if t.verbose {
+			fmt.Fprintf(t.log, "Key %s failed with HTTP %d, retrying with %s\n", utils.MaskAPIKey(t.config.APIKey), resp.StatusCode, utils.MaskAPIKey(next))
+		}
+		t.config.APIKey = next
+		if req, err = build(t.getRequestBuilder()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// chatBuilder returns the build function of sendChat for a chat request
+func chatBuilder(model string, stream bool) func(RequestBuilder) (*http.Request, error) {
+	return func(b RequestBuilder) (*http.Request, error) {
+		return b.BuildChatRequest(model, stream)
+	}
+}
+
+// rotates reports whether a response is left to the key rotation rather
+// than retried with the same key. Pools with no other key to rotate to are
+// retried as usual.
+func (t *Tester) rotates(resp *http.Response) bool {
+	return t.rotateKey != nil && len(t.config.APIKeys) > 1 && resp != nil && resp.StatusCode == http.StatusTooManyRequests
+}
+
+// isRetryable reports whether a request outcome is worth retrying
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
 // getModel returns the model to use for testing.
 // If the config has a model specified, it uses that.
 // Otherwise, it falls back to the provider's default model.
@@ -211,7 +501,7 @@ func (t *Tester) TestBasic() (*TestResult, error) {
 	})
 
 	// Send the request
-	resp, err := t.client.Do(req)
+	resp, err := t.sendChat(req, chatBuilder(model, false))
 	if err != nil {
 		result.Error = fmt.Sprintf("network error: %v", err)
 		result.ResponseTime = time.Since(startTime)
@@ -228,6 +518,7 @@ func (t *Tester) TestBasic() (*TestResult, error) {
 	defer resp.Body.Close()
 
 	result.ResponseTime = time.Since(startTime)
+	result.RateLimits = ParseRateLimits(resp.Header, time.Now())
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
@@ -269,6 +560,13 @@ func (t *Tester) TestBasic() (*TestResult, error) {
 
 		if errCategory != ErrorCategoryAuthFailure {
 			result.Error = errInfo.UserMessage
+			// Authenticated but not served, e.g. a model the relay lacks
+			result.Checks = append(result.Checks, CheckResult{
+				Name:     "Response",
+				Passed:   false,
+				Message:  errInfo.UserMessage,
+				Critical: isCritical,
+			})
 		}
 
 		result.CompatibilityLevel, _ = DetermineCompatibilityLevel(result.Checks)
@@ -360,7 +658,7 @@ func (t *Tester) TestStreaming() (*TestResult, error) {
 	})
 
 	// Send the request
-	resp, err := t.client.Do(req)
+	resp, err := t.sendChat(req, chatBuilder(model, true))
 	if err != nil {
 		result.Error = fmt.Sprintf("network error: %v", err)
 		result.ResponseTime = time.Since(startTime)
@@ -377,6 +675,7 @@ func (t *Tester) TestStreaming() (*TestResult, error) {
 	defer resp.Body.Close()
 
 	result.ResponseTime = time.Since(startTime)
+	result.RateLimits = ParseRateLimits(resp.Header, time.Now())
 
 	// Connection succeeded
 	result.Checks = append(result.Checks, CheckResult{
@@ -478,7 +777,9 @@ func (t *Tester) TestStreaming() (*TestResult, error) {
 }
 
 // RunFullTest runs a complete compatibility test including both basic and streaming tests.
-// If includeStreaming is false, only the basic test is run.
+// If includeStreaming is false, only the basic test is run. The optional
+// checks enabled with WithContextProbe, WithVisionCheck and
+// WithFidelityChecks run last.
 func (t *Tester) RunFullTest(includeStreaming bool) (*TestResult, error) {
 	// Run basic test first
 	basicResult, err := t.TestBasic()
@@ -491,24 +792,53 @@ func (t *Tester) RunFullTest(includeStreaming bool) (*TestResult, error) {
 		return basicResult, nil
 	}
 
-	// If streaming test is not requested, return basic result
-	if !includeStreaming {
-		return basicResult, nil
+	result := basicResult
+	if includeStreaming {
+		streamingResult, err := t.TestStreaming()
+		if err != nil {
+			// Merge basic checks with streaming error
+			streamingResult.Checks = append(basicResult.Checks, streamingResult.Checks...)
+			streamingResult.CompatibilityLevel, _ = DetermineCompatibilityLevel(streamingResult.Checks)
+			return streamingResult, err
+		}
+		result = combineResults(result, streamingResult)
 	}
 
-	// Run streaming test
-	streamingResult, err := t.TestStreaming()
-	if err != nil {
-		// Merge basic checks with streaming error
-		streamingResult.Checks = append(basicResult.Checks, streamingResult.Checks...)
-		streamingResult.CompatibilityLevel, _ = DetermineCompatibilityLevel(streamingResult.Checks)
-		return streamingResult, err
+	for _, check := range t.optionalChecks() {
+		checkResult, err := check()
+		if err != nil {
+			return result, err
+		}
+		result = combineResults(result, checkResult)
+	}
+
+	return result, nil
+}
+
+// optionalChecks returns the optional checks enabled with options
+func (t *Tester) optionalChecks() []func() (*TestResult, error) {
+	var checks []func() (*TestResult, error)
+	if t.probeSize > 0 {
+		checks = append(checks, t.TestContextWindow)
+	}
+	if t.vision {
+		checks = append(checks, t.TestVision)
+	}
+	if t.fidelity {
+		checks = append(checks, t.TestSystemPrompt, t.TestStopSequences)
 	}
+	return checks
+}
 
-	// Merge results
+// combineResults merges the checks, response times and errors of two tests
+func combineResults(first, second *TestResult) *TestResult {
 	combinedResult := &TestResult{
-		Checks:       append(basicResult.Checks, streamingResult.Checks...),
-		ResponseTime: basicResult.ResponseTime + streamingResult.ResponseTime,
+		Checks:       append(first.Checks, second.Checks...),
+		ResponseTime: first.ResponseTime + second.ResponseTime,
+		RateLimits:   first.RateLimits,
+	}
+	if len(second.RateLimits) > 0 {
+		combinedResult.RateLimits = second.RateLimits
 	}
 
 	// Determine combined compatibility level
@@ -516,15 +846,15 @@ func (t *Tester) RunFullTest(includeStreaming bool) (*TestResult, error) {
 	combinedResult.Success = combinedResult.CompatibilityLevel == CompatibilityFull
 
 	// Combine errors if any
-	if basicResult.Error != "" && streamingResult.Error != "" {
-		combinedResult.Error = fmt.Sprintf("%s; %s", basicResult.Error, streamingResult.Error)
-	} else if basicResult.Error != "" {
-		combinedResult.Error = basicResult.Error
-	} else if streamingResult.Error != "" {
-		combinedResult.Error = streamingResult.Error
+	if first.Error != "" && second.Error != "" {
+		combinedResult.Error = fmt.Sprintf("%s; %s", first.Error, second.Error)
+	} else if first.Error != "" {
+		combinedResult.Error = first.Error
+	} else if second.Error != "" {
+		combinedResult.Error = second.Error
 	}
 
-	return combinedResult, nil
+	return combinedResult
 }
 
 // GetProvider returns the resolved provider for this tester
@@ -547,3 +877,34 @@ func (t *Tester) GetModel() string {
 func (t *Tester) WasProviderAutoDetected() bool {
 	return t.config.Provider == ""
 }
+
+// OptionsFromSettings converts the configured test defaults into tester
+// options. A nil settings value yields no options.
+func OptionsFromSettings(settings *models.TestSettings) ([]TesterOption, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	var opts []TesterOption
+	if settings.Timeout != "" {
+		timeout, err := time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test timeout '%s': %w", settings.Timeout, err)
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+	if settings.Retries < 0 {
+		return nil, fmt.Errorf("invalid test retries %d: must not be negative", settings.Retries)
+	}
+	if settings.Retries > 0 {
+		opts = append(opts, WithRetries(settings.Retries))
+	}
+	if settings.Backoff != "" {
+		backoff, err := time.ParseDuration(settings.Backoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test backoff '%s': %w", settings.Backoff, err)
+		}
+		opts = append(opts, WithBackoff(backoff))
+	}
+	return opts, nil
+}
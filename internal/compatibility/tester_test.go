@@ -1,7 +1,13 @@
 package compatibility
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"apimgr/config/models"
 	"apimgr/internal/providers"
@@ -245,6 +251,45 @@ func TestDetectProviderFromURL(t *testing.T) {
 	}
 }
 
+// TestDetectService tests recognizing hosted APIs and their endpoints
+func TestDetectService(t *testing.T) {
+	tests := []struct {
+		url          string
+		wantName     string
+		wantProvider string
+		wantModel    string
+	}{
+		{"https://openrouter.ai/api", "OpenRouter", "anthropic", "anthropic/claude-sonnet-4"},
+		{"https://openrouter.ai/api/v1", "OpenRouter", "openai", "anthropic/claude-sonnet-4"},
+		{"https://api.deepseek.com/anthropic", "DeepSeek", "anthropic", "deepseek-chat"},
+		{"https://api.deepseek.com/v1", "DeepSeek", "openai", "deepseek-chat"},
+		{"https://api.moonshot.cn/anthropic/", "Moonshot", "anthropic", "kimi-k2-0905-preview"},
+		{"https://api.moonshot.ai/v1", "Moonshot", "openai", "kimi-k2-0905-preview"},
+		{"https://api.groq.com/openai/v1", "Groq", "openai", "llama-3.3-70b-versatile"},
+		{"https://API.Together.xyz/v1", "Together", "openai", "meta-llama/Llama-3.3-70B-Instruct-Turbo"},
+		{"https://api.deepseek.com.evil.example/anthropic", "", "", ""},
+		{"https://api.anthropic.com", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			service, ok := DetectService(tt.url)
+			if ok != (tt.wantName != "") {
+				t.Fatalf("DetectService(%q) ok = %v", tt.url, ok)
+			}
+			if service.Name != tt.wantName || service.Provider != tt.wantProvider {
+				t.Errorf("DetectService(%q) = %s/%s, want %s/%s", tt.url, service.Name, service.Provider, tt.wantName, tt.wantProvider)
+			}
+			if ok && service.Models[0] != tt.wantModel {
+				t.Errorf("DetectService(%q) default model = %q, want %q", tt.url, service.Models[0], tt.wantModel)
+			}
+			if provider, _ := DetectProviderFromURL(tt.url); ok && provider != tt.wantProvider {
+				t.Errorf("DetectProviderFromURL(%q) = %q, want %q", tt.url, provider, tt.wantProvider)
+			}
+		})
+	}
+}
+
 // TestNewTester_AutoDetectProvider tests that NewTester auto-detects provider from URL
 func TestNewTester_AutoDetectProvider(t *testing.T) {
 	tests := []struct {
@@ -321,3 +366,249 @@ func TestWasProviderAutoDetected(t *testing.T) {
 		t.Error("expected WasProviderAutoDetected to be true for auto-detected provider")
 	}
 }
+
+// TestTestBasic_RetriesTransientFailures tests that retries recover from
+// transient server errors and stop on the first success
+func TestTestBasic_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		retries      int
+		wantAttempts int32
+		wantSuccess  bool
+	}{
+		{name: "no retries reports failure", failures: 1, retries: 0, wantAttempts: 1, wantSuccess: false},
+		{name: "retry recovers", failures: 2, retries: 2, wantAttempts: 3, wantSuccess: true},
+		{name: "retries exhausted", failures: 5, retries: 2, wantAttempts: 3, wantSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+			}))
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+			tester, err := NewTester(cfg, WithRetries(tt.retries), WithBackoff(time.Millisecond))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := tester.TestBasic()
+			if err != nil {
+				t.Fatalf("TestBasic() unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+		})
+	}
+}
+
+// TestTestBasic_RotatesKeys tests that a rejected or rate-limited key is
+// replaced by the key the rotation returns, without retrying it in place
+func TestTestBasic_RotatesKeys(t *testing.T) {
+	statuses := map[string]int{"sk-revoked": http.StatusUnauthorized, "sk-limited": http.StatusTooManyRequests}
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("x-api-key")
+		sent = append(sent, key)
+		if status, ok := statuses[key]; ok {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	pool := []string{"sk-revoked", "sk-limited", "sk-good"}
+	var reported []int
+	rotate := func(key string, status int) (string, bool) {
+		reported = append(reported, status)
+		i := slices.Index(pool, key)
+		if status == http.StatusOK || i+1 == len(pool) {
+			return "", false
+		}
+		return pool[i+1], true
+	}
+
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "sk-revoked", APIKeys: pool, BaseURL: server.URL}
+	tester, err := NewTester(cfg, WithKeyRotation(rotate), WithRetries(2), WithBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := tester.TestBasic()
+	if err != nil {
+		t.Fatalf("TestBasic() unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Success = false, want true (error: %s)", result.Error)
+	}
+	if !slices.Equal(sent, pool) {
+		t.Errorf("keys sent = %v, want %v", sent, pool)
+	}
+	if want := []int{401, 429, 200}; !slices.Equal(reported, want) {
+		t.Errorf("statuses reported = %v, want %v", reported, want)
+	}
+	if cfg.APIKey != "sk-revoked" {
+		t.Errorf("caller's APIKey = %q, want it left unchanged", cfg.APIKey)
+	}
+}
+
+// TestTestBasic_RetriesSingleKeyPool tests that a rate-limited key of a pool
+// with no other key is retried in place
+func TestTestBasic_RetriesSingleKeyPool(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	rotate := func(key string, status int) (string, bool) { return "", false }
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "sk-only", APIKeys: []string{"sk-only"}, BaseURL: server.URL}
+	tester, err := NewTester(cfg, WithKeyRotation(rotate), WithRetries(2), WithBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tester.TestBasic(); err != nil {
+		t.Fatalf("TestBasic() unexpected error: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3 (one and two retries)", requests)
+	}
+}
+
+// TestWithTimeout tests that the timeout applies without mutating a shared client
+func TestWithTimeout(t *testing.T) {
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key"}
+	shared := &http.Client{Timeout: time.Minute}
+
+	tester, err := NewTester(cfg, WithHTTPClient(shared), WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tester.client.Timeout != 5*time.Second {
+		t.Errorf("client timeout = %v, want 5s", tester.client.Timeout)
+	}
+	if shared.Timeout != time.Minute {
+		t.Errorf("shared client timeout changed to %v", shared.Timeout)
+	}
+}
+
+// TestWithContext tests that canceling the context aborts a request in flight
+func TestWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL}
+	tester, err := NewTester(cfg, WithContext(ctx), WithRetries(3), WithBackoff(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	result, err := tester.TestBasic()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("TestBasic() took %v after the context was canceled", elapsed)
+	}
+	if err == nil && result.Success {
+		t.Error("TestBasic() succeeded after the context was canceled")
+	}
+}
+
+// TestOptionsFromSettings tests converting config file defaults to options
+func TestOptionsFromSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *models.TestSettings
+		wantErr  bool
+		want     Tester
+	}{
+		{name: "nil settings", settings: nil, want: Tester{backoff: DefaultBackoff}},
+		{name: "all fields", settings: &models.TestSettings{Timeout: "45s", Retries: 3, Backoff: "500ms"}, want: Tester{timeout: 45 * time.Second, retries: 3, backoff: 500 * time.Millisecond}},
+		{name: "invalid timeout", settings: &models.TestSettings{Timeout: "soon"}, wantErr: true},
+		{name: "invalid backoff", settings: &models.TestSettings{Backoff: "10"}, wantErr: true},
+		{name: "negative retries", settings: &models.TestSettings{Retries: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := OptionsFromSettings(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OptionsFromSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			tester, err := NewTester(&models.APIConfig{Provider: "anthropic", APIKey: "test-key"}, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tester.timeout != tt.want.timeout || tester.retries != tt.want.retries || tester.backoff != tt.want.backoff {
+				t.Errorf("tester = {timeout: %v, retries: %d, backoff: %v}, want {timeout: %v, retries: %d, backoff: %v}",
+					tester.timeout, tester.retries, tester.backoff, tt.want.timeout, tt.want.retries, tt.want.backoff)
+			}
+		})
+	}
+}
+
+// TestNewTester_ChatPath tests that requests go to the config's chat path
+// unless a custom path overrides it
+func TestNewTester_ChatPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		customPath string
+		wantPath   string
+	}{
+		{name: "config chat path", wantPath: "/api/claude/v1/messages"},
+		{name: "custom path wins", customPath: "/v1/messages", wantPath: "/v1/messages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"m","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+			}))
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: "anthropic", APIKey: "test-key", BaseURL: server.URL, ChatPath: "/api/claude/v1/messages"}
+			var opts []TesterOption
+			if tt.customPath != "" {
+				opts = append(opts, WithCustomPath(tt.customPath))
+			}
+			tester, err := NewTester(cfg, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result, _ := tester.TestBasic(); !result.Success {
+				t.Errorf("TestBasic() failed: %s", result.Error)
+			}
+			if path != tt.wantPath {
+				t.Errorf("request path = %q, want %q", path, tt.wantPath)
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ type TestResult struct {
 	Checks             []CheckResult `json:"checks"`
 	ResponseTime       time.Duration `json:"responseTimeMs"`
 	Error              string        `json:"error,omitempty"`
+	RateLimits         []RateLimit   `json:"rateLimits,omitempty"` // Budgets reported by the last response
 }
 
 // CheckResult represents the result of a single validation check
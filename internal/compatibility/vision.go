@@ -0,0 +1,48 @@
+package compatibility
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strings"
+)
+
+// visionPrompt asks about the test image, a red square
+const visionPrompt = "What color is this image? Reply with one word."
+
+// visionImage returns the test image, a 16x16 red square encoded as PNG
+func visionImage() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// TestVision sends a small image and checks that the reply describes it,
+// since Claude Code sends screenshots as images. The check is not critical:
+// text requests still work without it.
+func (t *Tester) TestVision() (*TestResult, error) {
+	model := t.getModel()
+	img := visionImage()
+	build := func(b RequestBuilder) (*http.Request, error) {
+		return b.BuildImageRequest(model, visionPrompt, img)
+	}
+	return t.runProbe("Vision", build, func(status int, body []byte) (bool, string) {
+		if status != http.StatusOK {
+			return false, fmt.Sprintf("Image input rejected (HTTP %d): %s", status, truncateString(strings.TrimSpace(string(body)), 200))
+		}
+		text, _ := parseProbeReply(body)
+		if !strings.Contains(strings.ToLower(text), "red") {
+			return false, fmt.Sprintf("Reply does not describe the image, the image may have been dropped: %q", truncateString(strings.TrimSpace(text), 80))
+		}
+		return true, "Image input accepted and described"
+	})
+}
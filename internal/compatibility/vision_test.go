@@ -0,0 +1,85 @@
+package compatibility
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+)
+
+func TestVisionImage(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(visionImage()))
+	if err != nil {
+		t.Fatalf("visionImage() is not a PNG: %v", err)
+	}
+	if r, g, b, _ := img.At(8, 8).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Errorf("visionImage() pixel = (%d, %d, %d), want red", r, g, b)
+	}
+}
+
+// TestTestVision tests that the image is sent in the provider's format and
+// that rejected or ignored images fail the non-critical vision check
+func TestTestVision(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   string
+		status     int
+		reply      string
+		wantBlock  string // Type of the image content block
+		wantPassed bool
+	}{
+		{name: "anthropic described", provider: "anthropic", status: http.StatusOK, reply: "Red.", wantBlock: "image", wantPassed: true},
+		{name: "openai described", provider: "openai", status: http.StatusOK, reply: "red", wantBlock: "image_url", wantPassed: true},
+		{name: "image dropped", provider: "anthropic", status: http.StatusOK, reply: "I don't see an image.", wantBlock: "image"},
+		{name: "image rejected", provider: "openai", status: http.StatusBadRequest, wantBlock: "image_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blockType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Messages []struct {
+						Content []map[string]any `json:"content"`
+					} `json:"messages"`
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				if len(req.Messages) > 0 && len(req.Messages[0].Content) > 0 {
+					blockType, _ = req.Messages[0].Content[0]["type"].(string)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(map[string]any{
+					"content": []map[string]string{{"type": "text", "text": tt.reply}},
+					"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": tt.reply}}},
+				})
+			}))
+			defer server.Close()
+
+			cfg := &models.APIConfig{Provider: tt.provider, APIKey: "test-key", BaseURL: server.URL}
+			tester, err := NewTester(cfg, WithVisionCheck(true))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := tester.TestVision()
+			if err != nil {
+				t.Fatalf("TestVision() unexpected error: %v", err)
+			}
+			if blockType != tt.wantBlock {
+				t.Errorf("image block type = %q, want %q", blockType, tt.wantBlock)
+			}
+			check := result.Checks[0]
+			if check.Name != "Vision" || check.Passed != tt.wantPassed || check.Critical {
+				t.Errorf("check = %+v, want a non-critical Vision check with Passed %v", check, tt.wantPassed)
+			}
+			if !tt.wantPassed && !strings.Contains(strings.ToLower(check.Message), "image") {
+				t.Errorf("Message = %q, want it to explain the image failure", check.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package exitcode classifies errors into the process exit codes apimgr
+// documents, so scripts can branch on the kind of failure.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Exit codes returned by apimgr
+const (
+	Success     = 0
+	Failure     = 1 // Any other error
+	NotFound    = 2 // Configuration, alias or file does not exist
+	Validation  = 3 // Invalid input or configuration
+	Network     = 4 // Endpoint unreachable, timed out or failed
+	LockTimeout = 5 // Config file locked by another process
+	Usage       = 6 // Unknown command or flag, wrong number of arguments
+)
+
+// names are the kinds reported by --error-format json
+var names = map[int]string{
+	Failure:     "error",
+	NotFound:    "not_found",
+	Validation:  "validation",
+	Network:     "network",
+	LockTimeout: "lock_timeout",
+	Usage:       "usage",
+}
+
+// Error is an error with an exit code
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New formats an error like fmt.Errorf and attaches code to it
+func New(code int, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap attaches code to err, keeping its message. A nil err stays nil.
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Silent returns an error carrying only code, for commands that have
+// already reported the failure themselves. Its message is empty.
+func Silent(code int) error {
+	return &Error{Code: code, Err: errors.New("")}
+}
+
+// cobraUsagePrefixes start the argument errors cobra returns
+var cobraUsagePrefixes = []string{"unknown command", "accepts ", "requires at least", "requires at most", "received ", "invalid argument"}
+
+// Of returns the exit code for err: the code attached with New or Wrap, or
+// one derived from well-known error types
+func Of(err error) int {
+	if err == nil {
+		return Success
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &urlErr), errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return Network
+	case errors.Is(err, os.ErrNotExist):
+		return NotFound
+	}
+	for _, prefix := range cobraUsagePrefixes {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return Usage
+		}
+	}
+	return Failure
+}
+
+// Name returns the kind of error an exit code stands for
+func Name(code int) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return names[Failure]
+}
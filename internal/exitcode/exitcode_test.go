@@ -0,0 +1,47 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, Success},
+		{"plain", errors.New("boom"), Failure},
+		{"coded", New(NotFound, "configuration '%s' does not exist", "x"), NotFound},
+		{"wrapped coded", fmt.Errorf("failed to lock config file: %w", New(LockTimeout, "lock timeout")), LockTimeout},
+		{"url error", &url.Error{Op: "Get", URL: "http://x", Err: errors.New("refused")}, Network},
+		{"missing file", fmt.Errorf("failed to read: %w", os.ErrNotExist), NotFound},
+		{"cobra args", errors.New("accepts 1 arg(s), received 3"), Usage},
+		{"cobra command", errors.New(`unknown command "x" for "apimgr"`), Usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsMessage(t *testing.T) {
+	if Wrap(Validation, nil) != nil {
+		t.Error("Wrap(nil) should be nil")
+	}
+	err := Wrap(Validation, errors.New("invalid URL format"))
+	if err.Error() != "invalid URL format" {
+		t.Errorf("Wrap() message = %q", err.Error())
+	}
+	if Name(Of(err)) != "validation" || Name(42) != "error" {
+		t.Errorf("Name() = %q, %q", Name(Of(err)), Name(42))
+	}
+}
@@ -0,0 +1,102 @@
+// Package hooks runs the shell commands configured to run before and after
+// a switch, capturing their output.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Timeout bounds each hook command
+const Timeout = 30 * time.Second
+
+// Phases hooks run in
+const (
+	PreSwitch  = "pre_switch"  // Before switching, a failure cancels the switch
+	PostSwitch = "post_switch" // After switching, failures are only reported
+)
+
+// Scopes of a switch
+const (
+	ScopeGlobal = "global"
+	ScopeLocal  = "local"
+)
+
+// Event describes the switch hooks run for. It is passed to the commands
+// as APIMGR_HOOK_* environment variables.
+type Event struct {
+	Phase string // PreSwitch or PostSwitch
+	From  string // Previously active alias, empty when there was none
+	To    string // Alias being switched to
+	Scope string // ScopeGlobal or ScopeLocal
+}
+
+// Result is the outcome of one hook command
+type Result struct {
+	Command string
+	Output  string // Combined stdout and stderr
+	Err     error
+}
+
+// Run runs commands in order, stopping at the first one that fails
+func Run(commands []string, event Event) []Result {
+	results := make([]Result, 0, len(commands))
+	for _, command := range commands {
+		result := run(command, event)
+		results = append(results, result)
+		if result.Err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// Failed returns the error of the failed result, if any
+func Failed(results []Result) error {
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
+
+// run runs a single command through the platform shell
+func run(command string, event Event) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	name, args := shellCommand(runtime.GOOS, command)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(),
+		"APIMGR_HOOK_PHASE="+event.Phase,
+		"APIMGR_HOOK_FROM="+event.From,
+		"APIMGR_HOOK_TO="+event.To,
+		"APIMGR_HOOK_SCOPE="+event.Scope,
+	)
+	output, err := cmd.CombinedOutput()
+
+	result := Result{Command: command, Output: strings.TrimRight(string(output), "\n")}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Err = fmt.Errorf("%s hook %q timed out after %s", event.Phase, command, Timeout)
+	case err != nil:
+		result.Err = fmt.Errorf("%s hook %q failed: %w", event.Phase, command, err)
+	}
+	return result
+}
+
+// shellCommand returns the program and arguments running command in the
+// platform shell
+func shellCommand(goos, command string) (string, []string) {
+	if goos == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+	}{
+		{"linux", "sh", []string{"-c", "echo hi"}},
+		{"darwin", "sh", []string{"-c", "echo hi"}},
+		{"windows", "cmd", []string{"/C", "echo hi"}},
+	}
+	for _, tt := range tests {
+		name, args := shellCommand(tt.goos, "echo hi")
+		if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("shellCommand(%q) = %s %v, want %s %v", tt.goos, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands below use sh")
+	}
+	event := Event{Phase: PreSwitch, From: "old", To: "new", Scope: ScopeLocal}
+
+	results := Run([]string{
+		`echo "$APIMGR_HOOK_PHASE $APIMGR_HOOK_FROM $APIMGR_HOOK_TO $APIMGR_HOOK_SCOPE"`,
+		"echo oops >&2; exit 3",
+		"echo never",
+	}, event)
+
+	if len(results) != 2 {
+		t.Fatalf("Run() returned %d results, want it to stop after the failure", len(results))
+	}
+	if results[0].Err != nil || results[0].Output != "pre_switch old new local" {
+		t.Errorf("first result = %q, %v, want the event in the environment", results[0].Output, results[0].Err)
+	}
+	if results[1].Output != "oops" {
+		t.Errorf("second result output = %q, want stderr captured", results[1].Output)
+	}
+	err := Failed(results)
+	if err == nil || !strings.Contains(err.Error(), "pre_switch hook") || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Failed() = %v, want the failing hook reported", err)
+	}
+
+	if err := Failed(Run(nil, event)); err != nil {
+		t.Errorf("Failed(Run(nil)) = %v, want nil", err)
+	}
+}
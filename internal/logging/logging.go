@@ -0,0 +1,146 @@
+// Package logging sets up apimgr's slog logger: debug records on stderr with
+// --verbose, and an optional log file in the state directory that is
+// rotated when it grows large. Keys are redacted in every record.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"apimgr/internal/utils"
+)
+
+// FileName is the log file kept in the state directory
+const FileName = "apimgr.log"
+
+// FileEnvVar enables the log file like --log-file when set to a non-empty value
+const FileEnvVar = "APIMGR_LOG_FILE"
+
+// Log file rotation limits
+const (
+	maxFileSize = 1 << 20 // Rotate once the file exceeds 1 MiB
+	maxBackups  = 3       // Keep apimgr.log.1 to apimgr.log.3
+)
+
+// Options selects where records go
+type Options struct {
+	Verbose bool // Write debug records to Stderr
+	Stderr  io.Writer
+	File    string // Log file path, empty disables it
+}
+
+// Setup installs the default slog logger. Without any output enabled
+// records are discarded, so the TUI screen is never written over. The
+// returned closer closes the log file.
+func Setup(opts Options) (io.Closer, error) {
+	var handlers []slog.Handler
+	if opts.Verbose {
+		stderr := opts.Stderr
+		if stderr == nil {
+			stderr = os.Stderr
+		}
+		handlers = append(handlers, slog.NewTextHandler(utils.NewRedactWriter(stderr), &slog.HandlerOptions{
+			Level:       slog.LevelDebug,
+			ReplaceAttr: dropTime,
+		}))
+	}
+
+	var closer io.Closer = io.NopCloser(nil)
+	if opts.File != "" {
+		file, err := openLogFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		closer = file
+		handlers = append(handlers, slog.NewTextHandler(utils.NewRedactWriter(file), &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+	}
+
+	slog.SetDefault(slog.New(fanout(handlers)))
+	return closer, nil
+}
+
+// openLogFile rotates path when it is too large and opens it for appending
+func openLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxFileSize {
+		if err := rotate(path); err != nil {
+			return nil, fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return file, nil
+}
+
+// rotate shifts path to path.1, path.1 to path.2 and so on, dropping the oldest
+func rotate(path string) error {
+	for i := maxBackups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(path, path+".1")
+}
+
+// dropTime removes the timestamp from stderr records
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey && len(groups) == 0 {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// fanoutHandler sends records to every handler that accepts their level
+type fanoutHandler []slog.Handler
+
+// fanout combines handlers into one
+func fanout(handlers []slog.Handler) slog.Handler {
+	return fanoutHandler(handlers)
+}
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	path := filepath.Join(t.TempDir(), "state", FileName)
+	var stderr bytes.Buffer
+	closer, err := Setup(Options{Verbose: true, Stderr: &stderr, File: path})
+	if err != nil {
+		t.Fatalf("Setup() unexpected error: %v", err)
+	}
+
+	slog.Debug("sync failed", "error", "401 for key sk-ant-abcdefghijklmnop")
+	closer.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("log file not written: %v", err)
+	}
+	for name, out := range map[string]string{"stderr": stderr.String(), "file": string(content)} {
+		if !strings.Contains(out, `msg="sync failed"`) {
+			t.Errorf("%s missing record: %q", name, out)
+		}
+		if strings.Contains(out, "abcdefghijklmnop") {
+			t.Errorf("%s contains the unredacted key: %q", name, out)
+		}
+	}
+	if strings.Contains(stderr.String(), "time=") {
+		t.Errorf("stderr records should not carry a timestamp: %q", stderr.String())
+	}
+}
+
+func TestSetupQuiet(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	if _, err := Setup(Options{}); err != nil {
+		t.Fatalf("Setup() unexpected error: %v", err)
+	}
+	if slog.Default().Enabled(t.Context(), slog.LevelError) {
+		t.Error("records should be discarded without --verbose or a log file")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	for _, name := range []string{path, path + ".1", path + ".2", path + ".3"} {
+		if err := os.WriteFile(name, []byte(filepath.Base(name)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Truncate(path, maxFileSize+1); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := openLogFile(path)
+	if err != nil {
+		t.Fatalf("openLogFile() unexpected error: %v", err)
+	}
+	file.Close()
+
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("current log size = %d, want a fresh file", info.Size())
+	}
+	if info, _ := os.Stat(path + ".1"); info.Size() != maxFileSize+1 {
+		t.Errorf("%s.1 should hold the rotated log", FileName)
+	}
+	for i, want := range map[int]string{2: FileName + ".1", 3: FileName + ".2"} {
+		got, _ := os.ReadFile(fmt.Sprintf("%s.%d", path, i))
+		if string(got) != want {
+			t.Errorf("%s.%d = %q, want %q", FileName, i, got, want)
+		}
+	}
+}
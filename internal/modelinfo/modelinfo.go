@@ -0,0 +1,117 @@
+// Package modelinfo provides capability and pricing metadata for known
+// models. A table is bundled with the binary and can be extended or
+// corrected with a model_info.json file next to the config file.
+package modelinfo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// OverrideFileName is the file next to the config that overrides the bundled table
+const OverrideFileName = "model_info.json"
+
+//go:embed models.json
+var bundledJSON []byte
+
+// Info describes a model's capabilities and list price
+type Info struct {
+	ContextWindow int     `json:"context_window"` // Maximum input tokens
+	Vision        bool    `json:"vision"`         // Accepts image input
+	Tools         bool    `json:"tools"`          // Supports tool use
+	InputPrice    float64 `json:"input_price"`    // USD per million input tokens
+	OutputPrice   float64 `json:"output_price"`   // USD per million output tokens
+}
+
+// Table maps model IDs, or ID prefixes, to their metadata
+type Table map[string]Info
+
+var (
+	bundledOnce  sync.Once
+	bundledTable Table
+)
+
+// Bundled returns a copy of the table shipped with apimgr
+func Bundled() Table {
+	bundledOnce.Do(func() {
+		if err := json.Unmarshal(bundledJSON, &bundledTable); err != nil {
+			panic(fmt.Sprintf("invalid bundled model metadata: %v", err))
+		}
+	})
+	return maps.Clone(bundledTable)
+}
+
+// Load returns the bundled table merged with the override file stored next
+// to the config file. Entries in the override file replace bundled ones.
+func Load(configPath string) (Table, error) {
+	table := Bundled()
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), OverrideFileName))
+	if os.IsNotExist(err) {
+		return table, nil
+	}
+	if err != nil {
+		return table, fmt.Errorf("failed to read %s: %v", OverrideFileName, err)
+	}
+
+	overrides := Table{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return table, fmt.Errorf("failed to parse %s: %v", OverrideFileName, err)
+	}
+	for id, info := range overrides {
+		table[id] = info
+	}
+	return table, nil
+}
+
+// Lookup finds the metadata of a model. Dated or suffixed IDs such as
+// "claude-sonnet-4-20250514" match the longest known prefix, and routing
+// prefixes such as "anthropic/" are ignored.
+func (t Table) Lookup(model string) (Info, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	if info, ok := t[model]; ok {
+		return info, true
+	}
+
+	best := ""
+	for id := range t {
+		if strings.HasPrefix(model, id+"-") && len(id) > len(best) {
+			best = id
+		}
+	}
+	if best == "" {
+		return Info{}, false
+	}
+	return t[best], true
+}
+
+// EstimateCost returns the list price in USD of a request
+func (i Info) EstimateCost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*i.InputPrice + float64(outputTokens)*i.OutputPrice) / 1_000_000
+}
+
+// FormatTokens shortens a token count, e.g. 200000 -> "200K", 1048576 -> "1M"
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000_000), ".0") + "M"
+	case n >= 1_000:
+		return fmt.Sprintf("%dK", n/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatPrice formats a per-million-token price, e.g. "$3" or "$0.25"
+func FormatPrice(price float64) string {
+	return "$" + strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", price), "0"), ".")
+}
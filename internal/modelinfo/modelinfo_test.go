@@ -0,0 +1,95 @@
+package modelinfo
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	table := Bundled()
+
+	tests := []struct {
+		model     string
+		wantFound bool
+		wantInput float64
+	}{
+		{model: "claude-sonnet-4", wantFound: true, wantInput: 3},
+		{model: "claude-sonnet-4-20250514", wantFound: true, wantInput: 3},
+		{model: "claude-3-5-haiku-latest", wantFound: true, wantInput: 0.8},
+		{model: "anthropic/claude-opus-4-1", wantFound: true, wantInput: 15},
+		{model: "gpt-4o-mini-2024-07-18", wantFound: true, wantInput: 0.15},
+		{model: "o3-mini", wantFound: true, wantInput: 1.1},
+		{model: "o3x", wantFound: false},
+		{model: "my-custom-model", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			info, found := table.Lookup(tt.model)
+			if found != tt.wantFound {
+				t.Fatalf("Lookup(%q) found = %v, want %v", tt.model, found, tt.wantFound)
+			}
+			if found && info.InputPrice != tt.wantInput {
+				t.Errorf("Lookup(%q) InputPrice = %v, want %v", tt.model, info.InputPrice, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+
+	table, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() without override file unexpected error: %v", err)
+	}
+	if _, ok := table.Lookup("claude-sonnet-4"); !ok {
+		t.Error("Load() should include the bundled table")
+	}
+
+	override := `{"claude-sonnet-4": {"context_window": 1000000, "input_price": 6, "output_price": 22.5}, "my-model": {"context_window": 32000}}`
+	if err := os.WriteFile(filepath.Join(dir, OverrideFileName), []byte(override), 0600); err != nil {
+		t.Fatalf("Failed to write override file: %v", err)
+	}
+	table, err = Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if info, _ := table.Lookup("claude-sonnet-4"); info.ContextWindow != 1000000 || info.InputPrice != 6 {
+		t.Errorf("override not applied: %+v", info)
+	}
+	if _, ok := table.Lookup("my-model"); !ok {
+		t.Error("Load() should add models from the override file")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, OverrideFileName), []byte("{"), 0600); err != nil {
+		t.Fatalf("Failed to write override file: %v", err)
+	}
+	if _, err := Load(configPath); err == nil {
+		t.Error("Load() with an invalid override file expected error")
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	info := Info{InputPrice: 3, OutputPrice: 15}
+	if got := info.EstimateCost(10_000, 2_000); math.Abs(got-0.06) > 1e-9 {
+		t.Errorf("EstimateCost() = %v, want 0.06", got)
+	}
+
+	tokens := map[int]string{200000: "200K", 1048576: "1M", 1500000: "1.5M", 512: "512"}
+	for n, want := range tokens {
+		if got := FormatTokens(n); got != want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	prices := map[float64]string{3: "$3", 0.25: "$0.25", 2.5: "$2.5", 75: "$75"}
+	for p, want := range prices {
+		if got := FormatPrice(p); got != want {
+			t.Errorf("FormatPrice(%v) = %q, want %q", p, got, want)
+		}
+	}
+}
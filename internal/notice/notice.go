@@ -0,0 +1,62 @@
+// Package notice prints the messages apimgr shows besides a command's
+// output, such as switch confirmations, sync status, migration notices and
+// warnings. They go to stderr so that stdout only carries output meant for
+// the shell or scripts, and --quiet drops them. Errors are not notices.
+package notice
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	mu     sync.Mutex
+	stderr io.Writer = os.Stderr // Where notices go when not quiet
+	output io.Writer = os.Stderr // Where notices go, io.Discard when quiet
+)
+
+// SetQuiet drops notices when quiet is set, and prints them to stderr
+// otherwise
+func SetQuiet(quiet bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if quiet {
+		output = io.Discard
+	} else {
+		output = stderr
+	}
+}
+
+// SetStderr makes notices go to w instead of the process's stderr, for
+// commands run in-process
+func SetStderr(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if output != io.Discard {
+		output = w
+	}
+	stderr = w
+}
+
+// Quiet reports whether notices are dropped
+func Quiet() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return output == io.Discard
+}
+
+// Printf prints a notice
+func Printf(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintf(output, format, args...)
+}
+
+// Println prints a notice followed by a newline
+func Println(args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintln(output, args...)
+}
@@ -0,0 +1,29 @@
+package notice
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestPrintf(t *testing.T) {
+	t.Cleanup(func() { SetQuiet(false) })
+
+	var buf bytes.Buffer
+	output = &buf
+	Printf("✓ Switched to %s\n", "relay")
+	Println("synced")
+	if got, want := buf.String(), "✓ Switched to relay\nsynced\n"; got != want {
+		t.Errorf("notices = %q, want %q", got, want)
+	}
+
+	SetQuiet(true)
+	if !Quiet() || output != io.Discard {
+		t.Error("SetQuiet(true) should drop notices")
+	}
+	SetQuiet(false)
+	if Quiet() || output != os.Stderr {
+		t.Error("SetQuiet(false) should print notices to stderr")
+	}
+}
@@ -0,0 +1,43 @@
+// Package notify sends desktop notifications through the platform's
+// notification tool.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Send shows a desktop notification, using notify-send on Linux and
+// osascript on macOS
+func Send(title, message string) error {
+	cmd, err := command(runtime.GOOS, title, message)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
+}
+
+// command builds the notification command for the given platform
+func command(goos, title, message string) (*exec.Cmd, error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exec.Command("notify-send", "--app-name=apimgr", title, message), nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.Command("osascript", "-e", script), nil
+	default:
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
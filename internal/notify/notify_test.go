@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantPath string
+		wantArg  string
+		wantErr  bool
+	}{
+		{goos: "linux", wantPath: "notify-send", wantArg: "'work' is failing"},
+		{goos: "darwin", wantPath: "osascript", wantArg: `display notification "try \"backup\"" with title "'work' is failing"`},
+		{goos: "windows", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			cmd, err := command(tt.goos, "'work' is failing", `try "backup"`)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cmd.Args[0] != tt.wantPath {
+				t.Errorf("command() path = %q, want %q", cmd.Args[0], tt.wantPath)
+			}
+			found := false
+			for _, arg := range cmd.Args {
+				if arg == tt.wantArg {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("command() args = %q, want an argument %q", cmd.Args, tt.wantArg)
+			}
+		})
+	}
+}
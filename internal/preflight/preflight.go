@@ -0,0 +1,84 @@
+// Package preflight tests a configuration before it is switched to, so a
+// broken one can be refused while the active one is still intact.
+package preflight
+
+import (
+	"time"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/utils"
+)
+
+// Enabled reports whether defaults.verify_switch asks for every switch to
+// be verified
+func Enabled(cm *config.Manager) (bool, error) {
+	value, err := cm.GetSetting("defaults.verify_switch")
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+// Check runs the basic compatibility test of cfg, as it will be once
+// switched to, with the configured test settings and records it in the
+// test history. A tester that cannot run is reported as a failed result.
+func Check(cm *config.Manager, cfg *models.APIConfig) (*compatibility.TestResult, error) {
+	settings, err := cm.GetTestSettings()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := compatibility.OptionsFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &compatibility.TestResult{CompatibilityLevel: compatibility.CompatibilityNone}
+	if tester, err := compatibility.NewTester(cfg, opts...); err != nil {
+		result.Error = err.Error()
+	} else if result, err = tester.TestBasic(); err != nil {
+		result = &compatibility.TestResult{CompatibilityLevel: compatibility.CompatibilityNone, Error: err.Error()}
+	}
+	_ = history.Record(cm.StateDir(), cfg.Alias, history.Entry{
+		Time:      time.Now(),
+		Kind:      history.KindTest,
+		Success:   result.Success,
+		LatencyMs: result.ResponseTime.Milliseconds(),
+		Detail:    result.CompatibilityLevel,
+	})
+	return result, nil
+}
+
+// Failure returns why a check failed, its error or the first failed
+// check, with secrets redacted
+func Failure(result *compatibility.TestResult) string {
+	reason := result.Error
+	for _, check := range result.Checks {
+		if reason == "" && !check.Passed {
+			reason = check.Message
+		}
+	}
+	return utils.Redact(reason)
+}
+
+// Target returns the configuration alias as the switch will apply it: the
+// next key of its pool, picked without recording, and model when set
+func Target(cm *config.Manager, alias, model string) (*models.APIConfig, error) {
+	cfg, err := cm.Get(alias)
+	if err != nil {
+		return nil, err
+	}
+	key, err := cm.PeekKey(alias)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		cfg.APIKey = key
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+	return cfg, nil
+}
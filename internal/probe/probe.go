@@ -0,0 +1,97 @@
+// Package probe measures the phases of an HTTP request so slow connections
+// can be attributed to the network or the server.
+package probe
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timings holds the duration of each request phase. Phases that did not
+// happen, such as DNS for an IP address or TLS for plain HTTP, are zero.
+type Timings struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration // Server time from request sent to first response byte
+	Total     time.Duration
+}
+
+// Phase is a named request phase with its duration
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Phases returns the phases in the order they occur, skipping those that
+// did not happen
+func (t Timings) Phases() []Phase {
+	all := []Phase{
+		{"DNS", t.DNS},
+		{"TCP connect", t.Connect},
+		{"TLS handshake", t.TLS},
+		{"First byte", t.FirstByte},
+	}
+	phases := make([]Phase, 0, len(all))
+	for _, p := range all {
+		if p.Duration > 0 {
+			phases = append(phases, p)
+		}
+	}
+	return phases
+}
+
+// String formats the phases on one line, e.g. "DNS 12ms │ TCP connect 30ms"
+func (t Timings) String() string {
+	parts := make([]string, 0, 4)
+	for _, p := range t.Phases() {
+		parts = append(parts, fmt.Sprintf("%s %dms", p.Name, p.Duration.Milliseconds()))
+	}
+	return strings.Join(parts, " │ ")
+}
+
+// Do sends req with client and records the timing of each phase. Timings
+// of the phases completed before a failure are returned along with the error.
+func Do(client *http.Client, req *http.Request) (*http.Response, Timings, error) {
+	var (
+		mu                                          sync.Mutex // Trace hooks may run on other goroutines
+		timings                                     Timings
+		dnsStart, connectStart, tlsStart, wroteTime time.Time
+	)
+	mark := func(at *time.Time) {
+		mu.Lock()
+		*at = time.Now()
+		mu.Unlock()
+	}
+	since := func(from *time.Time, phase *time.Duration) {
+		mu.Lock()
+		if !from.IsZero() {
+			*phase = time.Since(*from)
+		}
+		mu.Unlock()
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&dnsStart, &timings.DNS) },
+		ConnectStart:         func(string, string) { mark(&connectStart) },
+		ConnectDone:          func(string, string, error) { since(&connectStart, &timings.Connect) },
+		TLSHandshakeStart:    func() { mark(&tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&tlsStart, &timings.TLS) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&wroteTime) },
+		GotFirstResponseByte: func() { since(&wroteTime, &timings.FirstByte) },
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	timings.Total = time.Since(start)
+	return resp, timings, err
+}
@@ -0,0 +1,175 @@
+package probe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("HEAD", server.URL, nil)
+	resp, timings, err := Do(server.Client(), req)
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if timings.DNS != 0 {
+		t.Errorf("DNS = %v, want 0 for an IP address", timings.DNS)
+	}
+	if timings.Connect <= 0 {
+		t.Errorf("Connect = %v, want > 0", timings.Connect)
+	}
+	if timings.TLS <= 0 {
+		t.Errorf("TLS = %v, want > 0", timings.TLS)
+	}
+	if timings.FirstByte < 20*time.Millisecond {
+		t.Errorf("FirstByte = %v, want >= 20ms of server time", timings.FirstByte)
+	}
+	if timings.Total < timings.Connect+timings.TLS+timings.FirstByte {
+		t.Errorf("Total = %v, want at least the sum of the phases", timings.Total)
+	}
+}
+
+func TestDoConnectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	req, _ := http.NewRequest("HEAD", url, nil)
+	_, timings, err := Do(http.DefaultClient, req)
+	if err == nil {
+		t.Fatal("Do() expected error for a closed server")
+	}
+	if timings.TLS != 0 || timings.FirstByte != 0 {
+		t.Errorf("phases after a failed connect should be zero, got %+v", timings)
+	}
+}
+
+func TestTimingsString(t *testing.T) {
+	tests := []struct {
+		name    string
+		timings Timings
+		want    string
+	}{
+		{name: "empty", timings: Timings{}, want: ""},
+		{name: "plain http skips dns and tls", timings: Timings{Connect: 5 * time.Millisecond, FirstByte: 120 * time.Millisecond}, want: "TCP connect 5ms │ First byte 120ms"},
+		{name: "all phases", timings: Timings{DNS: time.Millisecond, Connect: 2 * time.Millisecond, TLS: 3 * time.Millisecond, FirstByte: 4 * time.Millisecond}, want: "DNS 1ms │ TCP connect 2ms │ TLS handshake 3ms │ First byte 4ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timings.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := len(tt.timings.Phases()); got != strings.Count(tt.want, "ms") {
+				t.Errorf("Phases() returned %d phases for %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		failures  int
+		want      Stats
+	}{
+		{name: "no samples", want: Stats{}},
+		{name: "all failed", failures: 3, want: Stats{Samples: 3, Failures: 3}},
+		{name: "single sample", durations: []time.Duration{ms(40)}, want: Stats{Samples: 1, Min: ms(40), Avg: ms(40), P95: ms(40), Max: ms(40)}},
+		{
+			name:      "unordered with a failure",
+			durations: []time.Duration{ms(30), ms(10), ms(50), ms(20)},
+			failures:  1,
+			want:      Stats{Samples: 5, Failures: 1, Min: ms(10), Avg: ms(27) + 500*time.Microsecond, P95: ms(50), Max: ms(50)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.durations, tt.failures); got != tt.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// p95 of 20 samples is the 19th value
+	var durations []time.Duration
+	for i := 1; i <= 20; i++ {
+		durations = append(durations, ms(i))
+	}
+	if got := Summarize(durations, 0).P95; got != ms(19) {
+		t.Errorf("Summarize() P95 of 1..20ms = %v, want 19ms", got)
+	}
+}
+
+func TestFailureRate(t *testing.T) {
+	if got := (Stats{}).FailureRate(); got != 0 {
+		t.Errorf("FailureRate() with no samples = %v, want 0", got)
+	}
+	if got := (Stats{Samples: 4, Failures: 1}).FailureRate(); got != 0.25 {
+		t.Errorf("FailureRate() = %v, want 0.25", got)
+	}
+}
+
+func TestMeanTimings(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	if got := MeanTimings(nil); got != (Timings{}) {
+		t.Errorf("MeanTimings(nil) = %+v, want zero", got)
+	}
+	got := MeanTimings([]Timings{
+		{DNS: ms(10), Connect: ms(20), FirstByte: ms(100), Total: ms(130)},
+		{Connect: ms(40), TLS: ms(30), FirstByte: ms(200), Total: ms(270)},
+	})
+	// DNS and TLS are averaged over the sample that had them
+	want := Timings{DNS: ms(10), Connect: ms(30), TLS: ms(30), FirstByte: ms(150), Total: ms(200)}
+	if got != want {
+		t.Errorf("MeanTimings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMeanTimingsReusedConnection(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Samples after the first reuse its keep-alive connection
+	client := server.Client()
+	var samples []Timings
+	var connect, handshake time.Duration
+	for i := 0; i < DefaultSamples; i++ {
+		req, _ := http.NewRequest("HEAD", server.URL, nil)
+		resp, timings, err := Do(client, req)
+		if err != nil {
+			t.Fatalf("Do() unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		samples = append(samples, timings)
+		if timings.Connect > 0 && (connect == 0 || timings.Connect < connect) {
+			connect = timings.Connect
+		}
+		if timings.TLS > 0 && (handshake == 0 || timings.TLS < handshake) {
+			handshake = timings.TLS
+		}
+	}
+	if samples[1].Connect != 0 {
+		t.Fatalf("second sample Connect = %v, want a reused connection", samples[1].Connect)
+	}
+
+	// Not diluted by the samples without a connection setup
+	mean := MeanTimings(samples)
+	if mean.Connect < connect || mean.TLS < handshake {
+		t.Errorf("MeanTimings() = %+v, want connect >= %v and TLS >= %v of the samples that connected", mean, connect, handshake)
+	}
+}
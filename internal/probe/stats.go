@@ -0,0 +1,93 @@
+package probe
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultSamples is the number of requests sent per ping when not configured
+const DefaultSamples = 5
+
+// Stats summarizes the latency of several ping samples
+type Stats struct {
+	Samples  int // Requests sent
+	Failures int // Requests that got no response
+	Min      time.Duration
+	Avg      time.Duration
+	P95      time.Duration
+	Max      time.Duration
+}
+
+// Summarize computes latency statistics from the durations of the successful
+// samples and the number of failed ones
+func Summarize(durations []time.Duration, failures int) Stats {
+	stats := Stats{Samples: len(durations) + failures, Failures: failures}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Avg = total / time.Duration(len(sorted))
+	// Nearest-rank percentile
+	rank := (95*len(sorted) + 99) / 100
+	stats.P95 = sorted[rank-1]
+	return stats
+}
+
+// FailureRate returns the fraction of samples that failed
+func (s Stats) FailureRate() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Samples)
+}
+
+// MeanTimings averages the phases of the timings of several samples, zero
+// when there are none. Samples on a reused connection have no DNS, connect
+// or TLS phase, so each of those is averaged over the samples that had it.
+func MeanTimings(timings []Timings) Timings {
+	var mean Timings
+	if len(timings) == 0 {
+		return mean
+	}
+	var dns, connect, handshake time.Duration
+	for _, t := range timings {
+		mean.DNS += t.DNS
+		mean.Connect += t.Connect
+		mean.TLS += t.TLS
+		mean.FirstByte += t.FirstByte
+		mean.Total += t.Total
+		if t.DNS > 0 {
+			dns++
+		}
+		if t.Connect > 0 {
+			connect++
+		}
+		if t.TLS > 0 {
+			handshake++
+		}
+	}
+	n := time.Duration(len(timings))
+	mean.DNS = meanOver(mean.DNS, dns)
+	mean.Connect = meanOver(mean.Connect, connect)
+	mean.TLS = meanOver(mean.TLS, handshake)
+	mean.FirstByte /= n
+	mean.Total /= n
+	return mean
+}
+
+// meanOver divides total by n, zero when n is zero
+func meanOver(total, n time.Duration) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return total / n
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // Provider defines the standard interface for API providers
@@ -17,6 +18,32 @@ type Provider interface {
 	ValidateConfig(baseURL, apiKey, authToken string) error
 	// NormalizeConfig normalizes the API configuration (e.g., add trailing slash to URL)
 	NormalizeConfig(baseURL string) string
+	// EnvVars returns the environment variable names the provider's tools read
+	EnvVars() EnvVars
+}
+
+// EnvVars holds the environment variable names exported for a provider.
+// AuthToken may share a name with APIKey when the provider has no separate token.
+// OrgID, ProjectID and MaxTokens are empty when the provider has no such variable.
+type EnvVars struct {
+	APIKey    string
+	AuthToken string
+	BaseURL   string
+	Model     string
+	OrgID     string
+	ProjectID string
+	MaxTokens string // Output token limit read by the provider's CLI
+}
+
+// Names returns the distinct variable names
+func (e EnvVars) Names() []string {
+	names := []string{e.APIKey}
+	for _, name := range []string{e.AuthToken, e.BaseURL, e.Model, e.OrgID, e.ProjectID, e.MaxTokens} {
+		if name != "" && name != e.APIKey {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // registry stores all registered providers
@@ -36,6 +63,39 @@ func Get(name string) (Provider, error) {
 	return provider, nil
 }
 
+// EnvVarsFor returns the environment variable names for a provider, falling
+// back to the ANTHROPIC_* names for empty or unknown providers such as
+// anthropic-compatible relays
+func EnvVarsFor(name string) EnvVars {
+	if provider, err := Get(name); err == nil {
+		return provider.EnvVars()
+	}
+	return (&AnthropicProvider{}).EnvVars()
+}
+
+// AllEnvVarNames returns the variable names of every registered provider,
+// ANTHROPIC_* first, so scripts can clear whatever a previous switch exported
+func AllEnvVarNames() []string {
+	names := (&AnthropicProvider{}).EnvVars().Names()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+
+	providerNames := List()
+	sort.Strings(providerNames)
+	for _, providerName := range providerNames {
+		provider, _ := Get(providerName)
+		for _, name := range provider.EnvVars().Names() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 // List returns all registered providers
 func List() []string {
 	var list []string
@@ -83,6 +143,17 @@ func (p *AnthropicProvider) NormalizeConfig(baseURL string) string {
 	return baseURL
 }
 
+// EnvVars returns the Anthropic environment variable names
+func (p *AnthropicProvider) EnvVars() EnvVars {
+	return EnvVars{
+		APIKey:    "ANTHROPIC_API_KEY",
+		AuthToken: "ANTHROPIC_AUTH_TOKEN",
+		BaseURL:   "ANTHROPIC_BASE_URL",
+		Model:     "ANTHROPIC_MODEL",
+		MaxTokens: "CLAUDE_CODE_MAX_OUTPUT_TOKENS",
+	}
+}
+
 // OpenAIProvider is the OpenAI API provider implementation
 type OpenAIProvider struct{}
 
@@ -117,8 +188,66 @@ func (p *OpenAIProvider) NormalizeConfig(baseURL string) string {
 	return baseURL
 }
 
+// EnvVars returns the OpenAI environment variable names
+func (p *OpenAIProvider) EnvVars() EnvVars {
+	return EnvVars{
+		APIKey:    "OPENAI_API_KEY",
+		AuthToken: "OPENAI_API_KEY",
+		BaseURL:   "OPENAI_BASE_URL",
+		Model:     "OPENAI_MODEL",
+		OrgID:     "OPENAI_ORG_ID",
+		ProjectID: "OPENAI_PROJECT_ID",
+	}
+}
+
+// GeminiProvider is the Google Gemini API provider implementation
+type GeminiProvider struct{}
+
+// Name returns the provider name
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+// DefaultBaseURL returns the default Gemini API base URL
+func (p *GeminiProvider) DefaultBaseURL() string {
+	return "https://generativelanguage.googleapis.com"
+}
+
+// DefaultModel returns the default Gemini model
+func (p *GeminiProvider) DefaultModel() string {
+	return "gemini-2.5-pro"
+}
+
+// ValidateConfig validates the Gemini API configuration
+func (p *GeminiProvider) ValidateConfig(baseURL, apiKey, authToken string) error {
+	if apiKey == "" {
+		return fmt.Errorf("gemini: must provide API key")
+	}
+	return nil
+}
+
+// NormalizeConfig normalizes the Gemini API configuration
+func (p *GeminiProvider) NormalizeConfig(baseURL string) string {
+	if baseURL != "" && baseURL[len(baseURL)-1] != '/' {
+		return baseURL + "/"
+	}
+	return baseURL
+}
+
+// EnvVars returns the Gemini environment variable names
+func (p *GeminiProvider) EnvVars() EnvVars {
+	return EnvVars{
+		APIKey:    "GEMINI_API_KEY",
+		AuthToken: "GEMINI_API_KEY",
+		BaseURL:   "GOOGLE_GEMINI_BASE_URL",
+		Model:     "GEMINI_MODEL",
+		ProjectID: "GOOGLE_CLOUD_PROJECT",
+	}
+}
+
 // Initialize: register built-in providers
 func init() {
 	Register("anthropic", &AnthropicProvider{})
 	Register("openai", &OpenAIProvider{})
+	Register("gemini", &GeminiProvider{})
 }
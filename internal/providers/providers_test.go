@@ -226,3 +226,50 @@ func TestProviderIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestEnvVarsFor(t *testing.T) {
+	tests := []struct {
+		provider   string
+		wantAPIKey string
+		wantURL    string
+	}{
+		{"anthropic", "ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL"},
+		{"openai", "OPENAI_API_KEY", "OPENAI_BASE_URL"},
+		{"gemini", "GEMINI_API_KEY", "GOOGLE_GEMINI_BASE_URL"},
+		{"", "ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL"},
+		{"some-relay", "ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			vars := EnvVarsFor(tt.provider)
+			if vars.APIKey != tt.wantAPIKey {
+				t.Errorf("EnvVarsFor(%q).APIKey = %q, want %q", tt.provider, vars.APIKey, tt.wantAPIKey)
+			}
+			if vars.BaseURL != tt.wantURL {
+				t.Errorf("EnvVarsFor(%q).BaseURL = %q, want %q", tt.provider, vars.BaseURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestAllEnvVarNames(t *testing.T) {
+	names := AllEnvVarNames()
+
+	if len(names) == 0 || names[0] != "ANTHROPIC_API_KEY" {
+		t.Fatalf("AllEnvVarNames() should start with ANTHROPIC_API_KEY, got %v", names)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("AllEnvVarNames() contains duplicate %q", name)
+		}
+		seen[name] = true
+	}
+	for _, want := range []string{"ANTHROPIC_AUTH_TOKEN", "OPENAI_API_KEY", "OPENAI_ORG_ID", "GEMINI_API_KEY", "GOOGLE_CLOUD_PROJECT", "CLAUDE_CODE_MAX_OUTPUT_TOKENS"} {
+		if !seen[want] {
+			t.Errorf("AllEnvVarNames() missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Endpoint is a service reachable through several regional hosts
+type Endpoint struct {
+	Name     string            // e.g. "openai", "moonshot"
+	Provider string            // Provider whose default base URL is one of the regions, empty for relays
+	Regions  map[string]string // Region name -> base URL
+}
+
+// endpoints lists the built-in regional endpoints
+var endpoints = []Endpoint{
+	{Name: "openai", Provider: "openai", Regions: map[string]string{
+		"us": "https://api.openai.com/v1",
+		"eu": "https://eu.api.openai.com/v1",
+	}},
+	{Name: "moonshot", Regions: map[string]string{
+		"cn":     "https://api.moonshot.cn/anthropic",
+		"global": "https://api.moonshot.ai/anthropic",
+	}},
+	{Name: "zhipu", Regions: map[string]string{
+		"cn":     "https://open.bigmodel.cn/api/anthropic",
+		"global": "https://api.z.ai/api/anthropic",
+	}},
+	{Name: "minimax", Regions: map[string]string{
+		"cn":     "https://api.minimaxi.com/anthropic",
+		"global": "https://api.minimax.io/anthropic",
+	}},
+	{Name: "dashscope", Regions: map[string]string{
+		"cn":   "https://dashscope.aliyuncs.com/apps/anthropic",
+		"intl": "https://dashscope-intl.aliyuncs.com/apps/anthropic",
+	}},
+}
+
+// Endpoints returns the built-in regional endpoints
+func Endpoints() []Endpoint {
+	return slices.Clone(endpoints)
+}
+
+// EndpointOf returns the regional endpoint whose hosts include the one of
+// baseURL, or the endpoint of the provider when baseURL is empty
+func EndpointOf(provider, baseURL string) (Endpoint, bool) {
+	host := hostOf(baseURL)
+	for _, e := range endpoints {
+		if baseURL == "" {
+			if provider != "" && e.Provider == provider {
+				return e, true
+			}
+			continue
+		}
+		for _, u := range e.Regions {
+			if hostOf(u) == host {
+				return e, true
+			}
+		}
+	}
+	return Endpoint{}, false
+}
+
+// RegionNames returns the regions of the endpoint, sorted
+func (e Endpoint) RegionNames() []string {
+	return slices.Sorted(maps.Keys(e.Regions))
+}
+
+// RegionOf returns the region whose host baseURL points to, or an empty
+// string
+func (e Endpoint) RegionOf(baseURL string) string {
+	host := hostOf(baseURL)
+	for _, name := range e.RegionNames() {
+		if hostOf(e.Regions[name]) == host {
+			return name
+		}
+	}
+	return ""
+}
+
+// hostOf returns the lower-cased host of a URL, empty when it has none
+func hostOf(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,30 @@
+package providers
+
+import "testing"
+
+func TestEndpointOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   string
+		baseURL    string
+		wantName   string
+		wantRegion string
+	}{
+		{name: "host of a region", baseURL: "https://api.moonshot.cn/anthropic/", wantName: "moonshot", wantRegion: "cn"},
+		{name: "other path on the host", baseURL: "https://API.Z.AI/api/paas/v4", wantName: "zhipu", wantRegion: "global"},
+		{name: "provider without base URL", provider: "openai", wantName: "openai"},
+		{name: "provider with another host", provider: "openai", baseURL: "https://relay.example.com"},
+		{name: "no regional endpoint", provider: "anthropic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, ok := EndpointOf(tt.provider, tt.baseURL)
+			if ok != (tt.wantName != "") || endpoint.Name != tt.wantName {
+				t.Fatalf("EndpointOf(%q, %q) = %q, %v, want %q", tt.provider, tt.baseURL, endpoint.Name, ok, tt.wantName)
+			}
+			if ok && endpoint.RegionOf(tt.baseURL) != tt.wantRegion {
+				t.Errorf("RegionOf(%q) = %q, want %q", tt.baseURL, endpoint.RegionOf(tt.baseURL), tt.wantRegion)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package sysenv sets environment variables for the user's login session so
+// GUI apps inherit them, using launchctl on macOS and environment.d with the
+// systemd user manager on Linux.
+package sysenv
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Var is an environment variable assignment
+type Var struct {
+	Name  string
+	Value string
+}
+
+// Result describes where the variables were applied
+type Result struct {
+	File string // environment.d file written on Linux, empty on macOS
+	Live bool   // Whether the running session manager was updated too
+}
+
+// run executes a command, replaced in tests
+var run = func(cmd *exec.Cmd) error {
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Apply sets vars for the login session and removes the names in unset
+// that vars does not set
+func Apply(vars []Var, unset []string) (*Result, error) {
+	stale := staleNames(vars, unset)
+	switch runtime.GOOS {
+	case "darwin":
+		for _, name := range stale {
+			if err := run(exec.Command("launchctl", "unsetenv", name)); err != nil {
+				return nil, err
+			}
+		}
+		for _, v := range vars {
+			if err := run(exec.Command("launchctl", "setenv", v.Name, v.Value)); err != nil {
+				return nil, err
+			}
+		}
+		return &Result{Live: true}, nil
+	case "linux":
+		path, err := environmentDPath()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(environmentDContent(vars)), 0600); err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			return nil, err
+		}
+		// Without a running user manager the file still applies at next login
+		return &Result{File: path, Live: systemctl(vars, stale) == nil}, nil
+	default:
+		return nil, fmt.Errorf("system environment is not supported on %s", runtime.GOOS)
+	}
+}
+
+// Clear removes the named variables from the login session
+func Clear(names []string) (*Result, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		for _, name := range names {
+			if err := run(exec.Command("launchctl", "unsetenv", name)); err != nil {
+				return nil, err
+			}
+		}
+		return &Result{Live: true}, nil
+	case "linux":
+		path, err := environmentDPath()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return &Result{File: path, Live: systemctl(nil, names) == nil}, nil
+	default:
+		return nil, fmt.Errorf("system environment is not supported on %s", runtime.GOOS)
+	}
+}
+
+// systemctl updates the running systemd user manager
+func systemctl(vars []Var, unset []string) error {
+	if len(unset) > 0 {
+		if err := run(exec.Command("systemctl", append([]string{"--user", "unset-environment"}, unset...)...)); err != nil {
+			return err
+		}
+	}
+	if len(vars) == 0 {
+		return nil
+	}
+	args := []string{"--user", "set-environment"}
+	for _, v := range vars {
+		args = append(args, v.Name+"="+v.Value)
+	}
+	return run(exec.Command("systemctl", args...))
+}
+
+// environmentDPath returns the environment.d file apimgr owns
+func environmentDPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "environment.d", "60-apimgr.conf"), nil
+}
+
+// environmentDContent renders vars as an environment.d file, escaping the
+// characters environment.d would expand
+func environmentDContent(vars []Var) string {
+	var buf strings.Builder
+	buf.WriteString("# Generated by 'apimgr sysenv apply', removed by 'apimgr sysenv clear'\n")
+	for _, v := range vars {
+		value := strings.ReplaceAll(v.Value, `\`, `\\`)
+		value = strings.ReplaceAll(value, "$", `\$`)
+		buf.WriteString(fmt.Sprintf("%s=%s\n", v.Name, value))
+	}
+	return buf.String()
+}
+
+// staleNames returns the names in unset that vars does not set
+func staleNames(vars []Var, unset []string) []string {
+	set := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		set[v.Name] = true
+	}
+	var stale []string
+	for _, name := range unset {
+		if !set[name] {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,66 @@
+package sysenv
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentDContent(t *testing.T) {
+	got := environmentDContent([]Var{{"ANTHROPIC_API_KEY", `sk-$ab\c`}, {"ANTHROPIC_BASE_URL", "https://relay.example.com"}})
+	for _, want := range []string{`ANTHROPIC_API_KEY=sk-\$ab\\c` + "\n", "ANTHROPIC_BASE_URL=https://relay.example.com\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("environmentDContent() missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestApplyAndClearLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("environment.d is Linux only")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var commands []string
+	original := run
+	t.Cleanup(func() { run = original })
+	run = func(cmd *exec.Cmd) error {
+		commands = append(commands, strings.Join(cmd.Args, " "))
+		return nil
+	}
+
+	result, err := Apply([]Var{{"ANTHROPIC_API_KEY", "sk-test"}}, []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY"})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if !result.Live {
+		t.Error("Apply() Live = false, want true")
+	}
+	if want := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "environment.d", "60-apimgr.conf"); result.File != want {
+		t.Errorf("Apply() File = %q, want %q", result.File, want)
+	}
+	info, err := os.Stat(result.File)
+	if err != nil {
+		t.Fatalf("Apply() did not write the file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %04o, want 0600", info.Mode().Perm())
+	}
+	wantCommands := []string{
+		"systemctl --user unset-environment OPENAI_API_KEY",
+		"systemctl --user set-environment ANTHROPIC_API_KEY=sk-test",
+	}
+	if strings.Join(commands, "\n") != strings.Join(wantCommands, "\n") {
+		t.Errorf("Apply() ran %q, want %q", commands, wantCommands)
+	}
+
+	if _, err := Clear([]string{"ANTHROPIC_API_KEY"}); err != nil {
+		t.Fatalf("Clear() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(result.File); !os.IsNotExist(err) {
+		t.Errorf("Clear() left the file behind: %v", err)
+	}
+}
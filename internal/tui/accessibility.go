@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"apimgr/internal/compatibility"
+	"apimgr/internal/utils"
+)
+
+// viewNames names each view in screen reader announcements
+var viewNames = map[ViewState]string{
+	ViewMain:          "配置列表",
+	ViewDetail:        "配置详情",
+	ViewAdd:           "添加配置",
+	ViewEdit:          "编辑配置",
+	ViewDelete:        "删除确认",
+	ViewHelp:          "帮助",
+	ViewModelSelect:   "选择模型",
+	ViewPingTesting:   "正在进行连接测试",
+	ViewPingResult:    "连接测试结果",
+	ViewCompatTesting: "正在进行兼容性测试",
+	ViewCompatResult:  "兼容性测试结果",
+	ViewExportPreview: "导出预览",
+	ViewCompare:       "配置对比",
+	ViewOnboarding:    "初始设置引导",
+	ViewMessageLog:    "消息历史",
+	ViewJobs:          "后台任务",
+}
+
+// compatLevelNames describes compatibility levels in announcements
+var compatLevelNames = map[string]string{
+	compatibility.CompatibilityFull:    "完全兼容",
+	compatibility.CompatibilityPartial: "部分兼容",
+	compatibility.CompatibilityNone:    "不兼容",
+}
+
+// announce describes what changed from prev to next as text, for screen
+// readers that cannot follow the highlighted line or a redrawn screen.
+// It returns an empty string when nothing worth announcing changed.
+func announce(prev, next Model) string {
+	var parts []string
+
+	if next.viewState != prev.viewState {
+		parts = append(parts, viewNames[next.viewState])
+		switch next.viewState {
+		case ViewPingResult:
+			parts = append(parts, pingOutcome(next.testResult))
+		case ViewCompatResult:
+			parts = append(parts, compatOutcome(next.compatResult))
+		}
+	}
+
+	switch next.viewState {
+	case ViewMain, ViewDetail:
+		if next.viewState != prev.viewState || next.cursor != prev.cursor || selectedAlias(next) != selectedAlias(prev) || len(next.configs) != len(prev.configs) {
+			parts = append(parts, next.describeSelection())
+		}
+	case ViewModelSelect:
+		if (next.viewState != prev.viewState || next.modelCursor != prev.modelCursor) && next.modelCursor < len(next.modelList) {
+			parts = append(parts, fmt.Sprintf("已选中模型 %s，第 %d/%d 项", next.modelList[next.modelCursor], next.modelCursor+1, len(next.modelList)))
+		}
+	case ViewAdd, ViewEdit:
+		if (next.viewState != prev.viewState || next.formFocus != prev.formFocus) && next.formFocus < len(FormLabels()) {
+			parts = append(parts, "输入 "+strings.TrimSuffix(FormLabels()[next.formFocus], ":"))
+		}
+	}
+
+	if next.message != "" && next.message != prev.message {
+		parts = append(parts, next.message)
+	}
+	if next.errorMsg != "" && next.errorMsg != prev.errorMsg {
+		parts = append(parts, "错误: "+next.errorMsg)
+	}
+
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return utils.Redact(strings.Join(kept, "。"))
+}
+
+// selectedAlias returns the alias under the cursor, empty when there is none
+func selectedAlias(m Model) string {
+	if m.cursor < 0 || m.cursor >= len(m.configs) {
+		return ""
+	}
+	return m.configs[m.cursor].Alias
+}
+
+// describeSelection describes the config under the cursor and its position,
+// such as "已选中 work，第 3/7 项，使用中"
+func (m Model) describeSelection() string {
+	alias := selectedAlias(m)
+	if alias == "" {
+		return "暂无配置"
+	}
+	description := fmt.Sprintf("已选中 %s，第 %d/%d 项", alias, m.cursor+1, len(m.configs))
+	if alias == m.activeAlias {
+		description += "，使用中"
+	}
+	return description
+}
+
+// pingOutcome summarizes a ping result
+func pingOutcome(result *TestResult) string {
+	switch {
+	case result == nil:
+		return ""
+	case result.Success:
+		return "成功，" + result.Duration
+	default:
+		return "失败，" + result.Message
+	}
+}
+
+// compatOutcome summarizes a compatibility test result
+func compatOutcome(result *CompatTestResult) string {
+	if result == nil {
+		return ""
+	}
+	outcome := compatLevelNames[result.CompatibilityLevel]
+	failed := 0
+	for _, check := range result.Checks {
+		if !check.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		outcome += fmt.Sprintf("，%d 项检查未通过", failed)
+	}
+	return outcome
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"testing"
+
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAnnounce(t *testing.T) {
+	configs := []models.APIConfig{{Alias: "home"}, {Alias: "work"}, {Alias: "relay"}}
+	base := Model{configs: configs, activeAlias: "work", viewState: ViewMain}
+	with := func(change func(*Model)) Model {
+		m := base
+		change(&m)
+		return m
+	}
+
+	tests := []struct {
+		name string
+		next Model
+		want string
+	}{
+		{"nothing changed", base, ""},
+		{"cursor moved", with(func(m *Model) { m.cursor = 2 }), "已选中 relay，第 3/3 项"},
+		{"active config selected", with(func(m *Model) { m.cursor = 1 }), "已选中 work，第 2/3 项，使用中"},
+		{"detail opened", with(func(m *Model) { m.viewState = ViewDetail }), "配置详情。已选中 home，第 1/3 项"},
+		{"form field focused", with(func(m *Model) { m.viewState = ViewEdit; m.formFocus = FormFieldBaseURL }), "编辑配置。输入 Base URL"},
+		{"ping result", with(func(m *Model) {
+			m.viewState = ViewPingResult
+			m.testResult = &TestResult{Success: true, Duration: "412ms"}
+		}), "连接测试结果。成功，412ms"},
+		{"compatibility result", with(func(m *Model) {
+			m.viewState = ViewCompatResult
+			m.compatResult = &CompatTestResult{CompatibilityLevel: compatibility.CompatibilityPartial, Checks: []CompatCheck{{Passed: true}, {Passed: false}}}
+		}), "兼容性测试结果。部分兼容，1 项检查未通过"},
+		{"error", with(func(m *Model) { m.errorMsg = "bad key sk-ant-1234567890abcdef" }), "错误: bad key sk-a****cdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := announce(base, tt.next); got != tt.want {
+				t.Errorf("announce() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessibleUpdate(t *testing.T) {
+	m := Model{configs: []models.APIConfig{{Alias: "home"}, {Alias: "work"}}, width: 120, height: 40, accessible: true}
+	if m.twoPane() {
+		t.Error("accessible mode should keep the single list layout")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}); cmd == nil {
+		t.Error("moving the cursor in accessible mode should print an announcement")
+	}
+	m.accessible = false
+	m.width = 80
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}); cmd != nil {
+		t.Error("moving the cursor should not print anything outside accessible mode")
+	}
+}
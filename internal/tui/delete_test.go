@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDeleteInUse(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_RUNTIME_DIR", config.ClaudeConfigDirEnv, config.ConfigEnvVar, "APIMGR_ACTIVE"} {
+		t.Setenv(name, "")
+	}
+	t.Chdir(home)
+
+	cm, cleanup, err := NewDemoManager()
+	if err != nil {
+		t.Fatalf("NewDemoManager() unexpected error: %v", err)
+	}
+	defer cleanup()
+	configs, err := cm.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+
+	m := NewModel(cm)
+	m.configs = configs
+	m.activeAlias = demoActive
+	for i, cfg := range configs {
+		if cfg.Alias == demoActive {
+			m.cursor = i
+		}
+	}
+	press := func(m Model, msg tea.KeyMsg) Model {
+		t.Helper()
+		next, _ := m.Update(msg)
+		return next.(Model)
+	}
+	typeText := func(m Model, text string) Model {
+		t.Helper()
+		for _, r := range text {
+			m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return m
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if m.viewState != ViewDelete || !m.deleteUse.Active {
+		t.Fatalf("view = %v, use = %+v, want the delete confirmation of the active config", m.viewState, m.deleteUse)
+	}
+	if view := m.RenderDeleteConfirm(); !strings.Contains(view, "输入别名 "+demoActive) {
+		t.Errorf("RenderDeleteConfirm() = %q, want the alias asked for", view)
+	}
+
+	// y is typed into the input rather than confirming
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewState != ViewDelete || !strings.Contains(m.errorMsg, demoActive) {
+		t.Fatalf("view = %v, error = %q, want the deletion refused", m.viewState, m.errorMsg)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = typeText(m, demoActive)
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	targets := m.deleteSwitchTargets()
+	if len(targets) == 0 || m.deleteSwitchTo != targets[0] {
+		t.Fatalf("deleteSwitchTo = %q, want the first of %v", m.deleteSwitchTo, targets)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatal("enter with the alias typed returned no command")
+	}
+	msg, ok := deleteConfig(cm, demoActive, m.deleteSwitchTo)().(ConfigDeletedMsg)
+	if !ok || msg.Err != nil || msg.SwitchedTo != targets[0] {
+		t.Fatalf("deleteConfig() = %+v, want %s deleted and %s active", msg, demoActive, targets[0])
+	}
+	if active, _ := cm.GetActiveName(config.ScopeGlobal); active != targets[0] {
+		t.Errorf("active config = %q, want %q", active, targets[0])
+	}
+	if _, err := cm.Get(demoActive); err == nil {
+		t.Errorf("%s still exists after the deletion", demoActive)
+	}
+
+	next, _ = m.Update(msg)
+	if m = next.(Model); m.activeAlias != targets[0] || !strings.Contains(m.message, targets[0]) {
+		t.Errorf("activeAlias = %q, message = %q, want the switch to %s shown", m.activeAlias, m.message, targets[0])
+	}
+
+	// u brings the deleted config back from the trash
+	m.viewState = ViewMain
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if cmd == nil {
+		t.Fatal("u after a deletion returned no command")
+	}
+	next, _ = next.(Model).Update(restoreConfig(cm, demoActive)())
+	if m = next.(Model); m.lastDeleted != "" || !strings.Contains(m.message, "配置已恢复") {
+		t.Errorf("lastDeleted = %q, message = %q, want %s restored", m.lastDeleted, m.message, demoActive)
+	}
+	if _, err := cm.Get(demoActive); err != nil {
+		t.Errorf("Get() after the restore unexpected error: %v", err)
+	}
+}
+
+func TestDeleteNotInUse(t *testing.T) {
+	m := NewModel(nil)
+	m.configs = []models.APIConfig{{Alias: "work", APIKey: "sk-work"}}
+	next, _ := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = next.(Model)
+	if m.viewState != ViewDelete || m.deleteUse.InUse() {
+		t.Fatalf("view = %v, use = %+v, want the plain confirmation", m.viewState, m.deleteUse)
+	}
+	if _, cmd := m.handleDeleteViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); cmd == nil {
+		t.Error("y returned no delete command")
+	}
+}
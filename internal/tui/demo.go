@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/probe"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// demoDelay is how long a demo test pretends to run, so the progress views
+// show up
+const demoDelay = 600 * time.Millisecond
+
+// demoConfigs are the synthetic configs of demo mode
+var demoConfigs = []models.APIConfig{
+	{
+		Alias:       "anthropic",
+		Provider:    "anthropic",
+		APIKey:      "sk-ant-REDACTED",
+		BaseURL:     "https://api.anthropic.com",
+		Model:       "claude-sonnet-4-20250514",
+		Models:      []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514", "claude-3-5-haiku-20241022"},
+		Environment: "prod",
+	},
+	{
+		Alias:       "relay-fast",
+		Provider:    "anthropic",
+		AuthToken:   "sk-relay-demo-1111111111111111",
+		BaseURL:     "https://relay.example.com",
+		Model:       "claude-sonnet-4-20250514",
+		Models:      []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514"},
+		Environment: "dev",
+	},
+	{
+		Alias:       "relay-flaky",
+		Provider:    "anthropic",
+		AuthToken:   "sk-flaky-demo-2222222222222222",
+		BaseURL:     "https://flaky.example.com",
+		Model:       "claude-sonnet-4-20250514",
+		Environment: "dev",
+	},
+	{
+		Alias:    "openrouter",
+		Provider: "openai",
+		APIKey:   "sk-or-v1-demo-3333333333333333",
+		BaseURL:  "https://openrouter.ai/api/v1",
+		Model:    "anthropic/claude-sonnet-4",
+		Models:   []string{"anthropic/claude-sonnet-4", "anthropic/claude-opus-4"},
+	},
+	{
+		Alias:       "deepseek",
+		Provider:    "openai",
+		APIKey:      "sk-demo-4444444444444444",
+		BaseURL:     "https://api.deepseek.com",
+		Model:       "deepseek-chat",
+		Models:      []string{"deepseek-chat", "deepseek-reasoner"},
+		Environment: "staging",
+	},
+}
+
+// demoActive is the config demo mode starts switched to
+const demoActive = "anthropic"
+
+// demoOutcome is the synthetic result of testing a demo config
+type demoOutcome struct {
+	latency time.Duration
+	level   string // Compatibility level
+	failure string // Failed check message, empty when every check passes
+}
+
+// demoOutcomes are keyed by alias; configs added in the demo get the default
+var demoOutcomes = map[string]demoOutcome{
+	"anthropic":   {latency: 412 * time.Millisecond, level: compatibility.CompatibilityFull},
+	"relay-fast":  {latency: 187 * time.Millisecond, level: compatibility.CompatibilityFull},
+	"relay-flaky": {latency: 2315 * time.Millisecond, level: compatibility.CompatibilityPartial, failure: "SSE stream ended without message_stop"},
+	"openrouter":  {latency: 655 * time.Millisecond, level: compatibility.CompatibilityFull},
+	"deepseek":    {latency: 298 * time.Millisecond, level: compatibility.CompatibilityNone, failure: "HTTP 401: invalid api key"},
+}
+
+// demoOutcomeOf returns the demo outcome of alias
+func demoOutcomeOf(alias string) demoOutcome {
+	if o, ok := demoOutcomes[alias]; ok {
+		return o
+	}
+	return demoOutcome{latency: 350 * time.Millisecond, level: compatibility.CompatibilityFull}
+}
+
+// NewDemoManager sets up a config manager on a temporary store holding the
+// demo configs and their health history. XDG_CONFIG_HOME, XDG_STATE_HOME,
+// CLAUDE_CONFIG_DIR and the working directory are moved there, so switching
+// and editing in the demo never touch the real configs or Claude Code
+// settings. The returned function removes the store.
+func NewDemoManager() (*config.Manager, func(), error) {
+	dir, err := os.MkdirTemp("", "apimgr-demo-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create demo directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	for name, value := range map[string]string{
+		"XDG_CONFIG_HOME":         filepath.Join(dir, "config"),
+		"XDG_STATE_HOME":          filepath.Join(dir, "state"),
+		config.ClaudeConfigDirEnv: filepath.Join(dir, "claude"),
+	} {
+		os.Setenv(name, value)
+	}
+	os.Unsetenv(config.ConfigEnvVar)
+	os.Unsetenv("APIMGR_ACTIVE")
+	// Session markers stay in the temporary state directory
+	os.Unsetenv("XDG_RUNTIME_DIR")
+	config.SetConfigPath("")
+	if err := os.Chdir(dir); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	cm, err := config.NewConfigManager()
+	if err == nil {
+		err = seedDemo(cm, time.Now())
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to set up demo configs: %w", err)
+	}
+	return cm, cleanup, nil
+}
+
+// seedDemo adds the demo configs, switches to demoActive and records a day
+// of hourly test results for each config
+func seedDemo(cm *config.Manager, now time.Time) error {
+	for _, cfg := range demoConfigs {
+		if err := cm.Add(cfg); err != nil {
+			return err
+		}
+	}
+	if err := cm.SetActive(demoActive); err != nil {
+		return err
+	}
+
+	for _, cfg := range demoConfigs {
+		outcome := demoOutcomeOf(cfg.Alias)
+		for i := 24; i > 0; i-- {
+			entry := history.Entry{
+				Time:      now.Add(-time.Duration(i) * time.Hour),
+				Kind:      history.KindPing,
+				Success:   true,
+				LatencyMs: (outcome.latency + time.Duration(i%5)*outcome.latency/10).Milliseconds(),
+			}
+			switch {
+			case i%6 == 0:
+				entry.Kind = history.KindTest
+				entry.Success = outcome.level == compatibility.CompatibilityFull
+				entry.Detail = outcome.level
+			case cfg.Alias == "relay-flaky" && i%4 == 1:
+				// The flaky relay drops some requests
+				entry.Success = false
+				entry.Detail = "context deadline exceeded"
+			}
+			if err := history.Record(cm.StateDir(), cfg.Alias, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// demoPing returns a synthetic ping result for cfg after demoDelay
+func demoPing(cm *config.Manager, cfg *models.APIConfig) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(demoDelay)
+		latency := demoOutcomeOf(cfg.Alias).latency
+		msg := PingResultMsg{
+			Success:  true,
+			Duration: latency,
+			Timings: probe.Timings{
+				DNS:       latency / 20,
+				Connect:   latency / 10,
+				TLS:       latency / 5,
+				FirstByte: latency / 2,
+				Total:     latency,
+			},
+			Stats: probe.Stats{
+				Samples: probe.DefaultSamples,
+				Min:     latency * 9 / 10,
+				Avg:     latency,
+				P95:     latency * 13 / 10,
+				Max:     latency * 14 / 10,
+			},
+		}
+		// The flaky relay drops some requests
+		if cfg.Alias == "relay-flaky" {
+			msg.Stats.Failures = 2
+		}
+		if cm != nil {
+			recordHistory(cm, cfg.Alias, history.Entry{Kind: history.KindPing, Success: true, LatencyMs: latency.Milliseconds()})
+		}
+		return msg
+	}
+}
+
+// demoTestResult returns the synthetic compatibility test result of cfg
+func demoTestResult(cm *config.Manager, cfg *models.APIConfig, streaming bool) *compatibility.TestResult {
+	outcome := demoOutcomeOf(cfg.Alias)
+	result := &compatibility.TestResult{
+		Success:            outcome.level == compatibility.CompatibilityFull,
+		CompatibilityLevel: outcome.level,
+		ResponseTime:       outcome.latency,
+		Checks: []compatibility.CheckResult{
+			{Name: "Request Construction", Passed: true, Message: "Request built for " + cfg.Model, Critical: true},
+			{Name: "Connection", Passed: true, Message: "Connected to " + cfg.BaseURL, Critical: true},
+		},
+	}
+
+	switch outcome.level {
+	case compatibility.CompatibilityNone:
+		result.Checks = append(result.Checks, compatibility.CheckResult{Name: "Authentication", Message: outcome.failure, Critical: true})
+		result.Error = outcome.failure
+	default:
+		result.Checks = append(result.Checks,
+			compatibility.CheckResult{Name: "Authentication", Passed: true, Message: "Credentials accepted", Critical: true},
+			compatibility.CheckResult{Name: "Response Format", Passed: true, Message: "Response has content, model and usage", Critical: true},
+		)
+		if streaming {
+			result.Checks = append(result.Checks,
+				compatibility.CheckResult{Name: "SSE Format", Passed: true, Message: "Events are valid SSE"},
+				compatibility.CheckResult{Name: "Completion Signal", Passed: outcome.failure == "", Message: "Stream completed"},
+			)
+			if outcome.failure != "" {
+				result.Checks[len(result.Checks)-1].Message = outcome.failure
+			}
+		} else if outcome.failure != "" {
+			// The basic test does not stream, so the flaw does not show
+			result.Success = true
+			result.CompatibilityLevel = compatibility.CompatibilityFull
+		}
+	}
+
+	if cm != nil {
+		recordHistory(cm, cfg.Alias, history.Entry{
+			Kind:      history.KindTest,
+			Success:   result.Success,
+			LatencyMs: result.ResponseTime.Milliseconds(),
+			Detail:    result.CompatibilityLevel,
+		})
+	}
+	return result
+}
+
+// demoCompatibilityTest returns a synthetic full compatibility test result
+// for cfg after demoDelay
+func demoCompatibilityTest(cm *config.Manager, cfg *models.APIConfig) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(demoDelay)
+		return CompatResultMsg{Result: demoTestResult(cm, cfg, true)}
+	}
+}
+
+// demoCompareTest returns synthetic basic test results for both compared
+// configs after demoDelay
+func demoCompareTest(cm *config.Manager, cfgs [2]models.APIConfig) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(demoDelay)
+		return CompareResultMsg{Results: [2]*compatibility.TestResult{
+			demoTestResult(cm, &cfgs[0], false),
+			demoTestResult(cm, &cfgs[1], false),
+		}}
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/internal/compatibility"
+)
+
+func TestNewDemoManager(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", config.ClaudeConfigDirEnv, config.ConfigEnvVar, "APIMGR_ACTIVE"} {
+		t.Setenv(name, "")
+	}
+	t.Chdir(home)
+
+	cm, cleanup, err := NewDemoManager()
+	if err != nil {
+		t.Fatalf("NewDemoManager() unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	configs, err := cm.Load()
+	if err != nil || len(configs) != len(demoConfigs) {
+		t.Fatalf("Load() = %d configs, %v, want %d", len(configs), err, len(demoConfigs))
+	}
+	if active, _ := cm.GetActiveName(config.ScopeShell); active != demoActive {
+		t.Errorf("active config = %q, want %q", active, demoActive)
+	}
+	if entries, _ := history.Load(cm.StateDir(), "relay-flaky"); len(entries) != 24 {
+		t.Errorf("relay-flaky history = %d entries, want 24", len(entries))
+	}
+	for _, path := range []string{filepath.Join(home, ".claude"), filepath.Join(home, ".config"), filepath.Join(home, ".local")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should not be touched by the demo", path)
+		}
+	}
+}
+
+func TestDemoTestResult(t *testing.T) {
+	tests := []struct {
+		alias     string
+		streaming bool
+		want      string
+	}{
+		{"relay-fast", true, compatibility.CompatibilityFull},
+		{"relay-flaky", true, compatibility.CompatibilityPartial},
+		{"relay-flaky", false, compatibility.CompatibilityFull},
+		{"deepseek", false, compatibility.CompatibilityNone},
+		{"added-in-demo", true, compatibility.CompatibilityFull},
+	}
+
+	for _, tt := range tests {
+		cfg := demoConfigs[0]
+		cfg.Alias = tt.alias
+		result := demoTestResult(nil, &cfg, tt.streaming)
+		if result.CompatibilityLevel != tt.want || result.Success != (tt.want == compatibility.CompatibilityFull) {
+			t.Errorf("demoTestResult(%s, streaming %v) = %s (success %v), want %s", tt.alias, tt.streaming, result.CompatibilityLevel, result.Success, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 
+	"apimgr/internal/compatibility"
 	"apimgr/internal/utils"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -17,6 +18,7 @@ const (
 	FormFieldAPIKey
 	FormFieldAuthToken
 	FormFieldBaseURL
+	FormFieldProvider
 	FormFieldModel
 	FormFieldModels
 	FormFieldCount // Total number of fields
@@ -29,6 +31,7 @@ type FormData struct {
 	APIKey    string
 	AuthToken string
 	BaseURL   string
+	Provider  string
 	Model     string
 	Models    string // Comma-separated list of models
 }
@@ -47,7 +50,7 @@ func (f *FormData) Validate() error {
 	}
 
 	// Validate URL format if provided
-	if strings.TrimSpace(f.BaseURL) != "" && !utils.ValidateURL(f.BaseURL) {
+	if strings.TrimSpace(f.BaseURL) != "" && !utils.ValidateURL(utils.SamplePlaceholders(f.BaseURL)) {
 		return errors.New("无效的 URL 格式")
 	}
 
@@ -130,6 +133,13 @@ func FormInputs() []textinput.Model {
 	inputs[FormFieldBaseURL].Width = 40
 	inputs[FormFieldBaseURL].Prompt = ""
 
+	// Provider input
+	inputs[FormFieldProvider] = textinput.New()
+	inputs[FormFieldProvider].Placeholder = "anthropic"
+	inputs[FormFieldProvider].CharLimit = 32
+	inputs[FormFieldProvider].Width = 40
+	inputs[FormFieldProvider].Prompt = ""
+
 	// Model input
 	inputs[FormFieldModel] = textinput.New()
 	inputs[FormFieldModel].Placeholder = "claude-sonnet-4-20250514"
@@ -157,6 +167,7 @@ func GetFormData(inputs []textinput.Model) FormData {
 		APIKey:    inputs[FormFieldAPIKey].Value(),
 		AuthToken: inputs[FormFieldAuthToken].Value(),
 		BaseURL:   inputs[FormFieldBaseURL].Value(),
+		Provider:  inputs[FormFieldProvider].Value(),
 		Model:     inputs[FormFieldModel].Value(),
 		Models:    inputs[FormFieldModels].Value(),
 	}
@@ -169,6 +180,7 @@ func SetFormData(inputs []textinput.Model, data FormData) {
 	inputs[FormFieldAPIKey].SetValue(data.APIKey)
 	inputs[FormFieldAuthToken].SetValue(data.AuthToken)
 	inputs[FormFieldBaseURL].SetValue(data.BaseURL)
+	inputs[FormFieldProvider].SetValue(data.Provider)
 	inputs[FormFieldModel].SetValue(data.Model)
 	inputs[FormFieldModels].SetValue(data.Models)
 }
@@ -180,6 +192,7 @@ func FormLabels() []string {
 		"API Key:",
 		"Auth Token:",
 		"Base URL:",
+		"Provider:",
 		"Model:",
 		"Models:",
 	}
@@ -191,7 +204,8 @@ func FormHints() []string {
 		"配置的唯一标识符",
 		"API 密钥 (与 Auth Token 二选一)",
 		"认证令牌 (与 API Key 二选一)",
-		"API 基础 URL (可选)",
+		"API 基础 URL (可选，离开时按 URL 识别提供商和模型)",
+		"API 提供商: anthropic、openai 或 gemini (可选)",
 		"当前使用的模型 (可选)",
 		"支持的模型列表，逗号分隔 (可选)",
 	}
@@ -199,7 +213,7 @@ func FormHints() []string {
 
 // RenderForm renders the form view with inputs
 // Requirements: 5.2, 6.2
-func RenderForm(inputs []textinput.Model, focusIndex int, title string, errorMsg string) string {
+func RenderForm(inputs []textinput.Model, focusIndex int, title string, errorMsg string, keys KeyMap) string {
 	var b strings.Builder
 
 	// Title
@@ -239,7 +253,7 @@ func RenderForm(inputs []textinput.Model, focusIndex int, title string, errorMsg
 	// Error message
 	if errorMsg != "" {
 		b.WriteString("\n")
-		b.WriteString(formErrorStyle.Render("✗ " + errorMsg))
+		b.WriteString(formErrorStyle.Render("✗ " + utils.Redact(errorMsg)))
 		b.WriteString("\n")
 	}
 
@@ -247,11 +261,32 @@ func RenderForm(inputs []textinput.Model, focusIndex int, title string, errorMsg
 	b.WriteString("\n")
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", 50)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Tab/↓: 下一项 │ Shift+Tab/↑: 上一项 │ Enter: 确认 │ Esc: 取消"))
+	b.WriteString(renderKeyHints(keys.ShortHelpFor(ViewAdd)))
 
 	return b.String()
 }
 
+// DetectFormDefaults fills the empty provider and model fields from the
+// base URL, for known hosted APIs the models too
+func DetectFormDefaults(inputs []textinput.Model) {
+	baseURL := strings.TrimSpace(inputs[FormFieldBaseURL].Value())
+	provider, ok := compatibility.DetectProviderFromURL(baseURL)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(inputs[FormFieldProvider].Value()) == "" {
+		inputs[FormFieldProvider].SetValue(provider)
+	}
+	service, ok := compatibility.DetectService(baseURL)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(inputs[FormFieldModel].Value()) == "" && strings.TrimSpace(inputs[FormFieldModels].Value()) == "" {
+		inputs[FormFieldModel].SetValue(service.Models[0])
+		inputs[FormFieldModels].SetValue(strings.Join(service.Models, ", "))
+	}
+}
+
 // NextFormField moves focus to the next form field
 func NextFormField(inputs []textinput.Model, currentFocus int) int {
 	inputs[currentFocus].Blur()
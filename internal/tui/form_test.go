@@ -366,6 +366,7 @@ func TestFormLabels(t *testing.T) {
 		"API Key:",
 		"Auth Token:",
 		"Base URL:",
+		"Provider:",
 		"Model:",
 		"Models:",
 	}
@@ -401,7 +402,7 @@ func TestRenderForm(t *testing.T) {
 
 	// Test add form rendering
 	t.Run("add form", func(t *testing.T) {
-		output := RenderForm(inputs, 0, "添加配置", "")
+		output := RenderForm(inputs, 0, "添加配置", "", DefaultKeyMap())
 
 		// Check title is present
 		if !strings.Contains(output, "添加配置") {
@@ -419,7 +420,7 @@ func TestRenderForm(t *testing.T) {
 
 	// Test edit form rendering
 	t.Run("edit form", func(t *testing.T) {
-		output := RenderForm(inputs, 0, "编辑配置", "")
+		output := RenderForm(inputs, 0, "编辑配置", "", DefaultKeyMap())
 
 		// Check title is present
 		if !strings.Contains(output, "编辑配置") {
@@ -429,7 +430,7 @@ func TestRenderForm(t *testing.T) {
 
 	// Test error message rendering
 	t.Run("with error", func(t *testing.T) {
-		output := RenderForm(inputs, 0, "添加配置", "测试错误消息")
+		output := RenderForm(inputs, 0, "添加配置", "测试错误消息", DefaultKeyMap())
 
 		// Check error message is present
 		if !strings.Contains(output, "测试错误消息") {
@@ -440,7 +441,7 @@ func TestRenderForm(t *testing.T) {
 	// Test focused field hint
 	t.Run("focused field hint", func(t *testing.T) {
 		hints := FormHints()
-		output := RenderForm(inputs, 0, "添加配置", "")
+		output := RenderForm(inputs, 0, "添加配置", "", DefaultKeyMap())
 
 		// Check that the hint for the focused field is present
 		if !strings.Contains(output, hints[0]) {
@@ -448,3 +449,44 @@ func TestRenderForm(t *testing.T) {
 		}
 	})
 }
+
+// TestDetectFormDefaults tests filling the provider and models from the URL
+func TestDetectFormDefaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		baseURL      string
+		model        string
+		wantProvider string
+		wantModel    string
+		wantModels   string
+	}{
+		{"known service", "https://api.deepseek.com/anthropic", "", "anthropic", "deepseek-chat", "deepseek-chat, deepseek-reasoner"},
+		{"model kept", "https://api.groq.com/openai/v1", "my-model", "openai", "my-model", ""},
+		{"provider only", "https://api.openai.com/v1", "", "openai", "", ""},
+		{"unknown host", "https://relay.example.com", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := FormInputs()
+			inputs[FormFieldBaseURL].SetValue(tt.baseURL)
+			inputs[FormFieldModel].SetValue(tt.model)
+
+			DetectFormDefaults(inputs)
+			data := GetFormData(inputs)
+			if data.Provider != tt.wantProvider || data.Model != tt.wantModel || data.Models != tt.wantModels {
+				t.Errorf("DetectFormDefaults() = %q, %q, %q, want %q, %q, %q",
+					data.Provider, data.Model, data.Models, tt.wantProvider, tt.wantModel, tt.wantModels)
+			}
+		})
+	}
+
+	// A provider typed by the user is kept
+	inputs := FormInputs()
+	inputs[FormFieldBaseURL].SetValue("https://openrouter.ai/api/v1")
+	inputs[FormFieldProvider].SetValue("anthropic")
+	DetectFormDefaults(inputs)
+	if got := inputs[FormFieldProvider].Value(); got != "anthropic" {
+		t.Errorf("DetectFormDefaults() provider = %q, want the typed one kept", got)
+	}
+}
@@ -0,0 +1,396 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"apimgr/config/models"
+	"apimgr/internal/utils"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxJobs is how many jobs the jobs panel keeps, finished ones being
+// dropped oldest first
+const maxJobs = 20
+
+// jobKind tells what a background job does
+type jobKind int
+
+const (
+	jobBatchTest  jobKind = iota // Compatibility test of several configs
+	jobPing                      // Ping test sent to the background
+	jobCompatTest                // Compatibility test sent to the background
+)
+
+// job is a long operation running in the background, listed in the jobs
+// panel
+type job struct {
+	ID       int
+	Kind     jobKind
+	Title    string
+	Alias    string // Tested config of single tests
+	Started  time.Time
+	Finished time.Time // Zero while running
+	Canceled bool
+
+	// Progress of batch jobs, with a result line per item
+	Done   int
+	Total  int
+	Failed int
+	Lines  []string
+
+	run    int                // Test run of a single test sent to the background
+	cancel context.CancelFunc // Stops the job while it runs
+
+	// Result of a single test, opened from the jobs panel
+	pingResult   *TestResult
+	compatResult *CompatTestResult
+}
+
+// Running reports whether the job has not finished yet
+func (j job) Running() bool {
+	return j.Finished.IsZero()
+}
+
+// JobProgressMsg is sent when a batch job finished one of its items
+type JobProgressMsg struct {
+	ID      int
+	Line    string // Result of the item
+	Failed  bool
+	updates <-chan JobProgressMsg
+}
+
+// JobFinishedMsg is sent when a batch job has no items left
+type JobFinishedMsg struct {
+	ID int
+}
+
+// addJob starts tracking a job and returns its ID
+func (m *Model) addJob(j job) int {
+	m.jobSeq++
+	j.ID = m.jobSeq
+	j.Started = time.Now()
+	m.jobs = append(m.jobs, j)
+	for len(m.jobs) > maxJobs {
+		i := 0
+		for i < len(m.jobs) && m.jobs[i].Running() {
+			i++
+		}
+		if i == len(m.jobs) {
+			break
+		}
+		m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+	}
+	return j.ID
+}
+
+// findJob returns the index of the job with the given ID, or -1
+func (m Model) findJob(id int) int {
+	for i, j := range m.jobs {
+		if j.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// runningJobs returns how many jobs have not finished yet
+func (m Model) runningJobs() int {
+	running := 0
+	for _, j := range m.jobs {
+		if j.Running() {
+			running++
+		}
+	}
+	return running
+}
+
+// startBatchTest runs the compatibility test of every listed config as a
+// background job
+func (m Model) startBatchTest() (Model, tea.Cmd) {
+	if len(m.configs) == 0 {
+		return m, nil
+	}
+	cfgs := append([]models.APIConfig(nil), m.configs...)
+	ctx, cancel := context.WithCancel(context.Background())
+	id := m.addJob(job{
+		Kind:   jobBatchTest,
+		Title:  fmt.Sprintf("批量兼容性测试 (%d 个配置)", len(cfgs)),
+		Total:  len(cfgs),
+		cancel: cancel,
+	})
+	m.message = "已在后台开始批量测试"
+	if jobs := m.keyMap().Jobs; jobs.Enabled() {
+		m.message += "，按 " + jobs.Help().Key + " 查看进度"
+	}
+
+	updates := make(chan JobProgressMsg)
+	go func() {
+		defer close(updates)
+		for i := range cfgs {
+			if ctx.Err() != nil {
+				return
+			}
+			msg := m.compatTestCmd(ctx, &cfgs[i])().(CompatResultMsg)
+			if ctx.Err() != nil {
+				return
+			}
+			result := newCompatTestResult(msg)
+			updates <- JobProgressMsg{ID: id, Line: batchResultLine(cfgs[i].Alias, result), Failed: result == nil || !result.Success}
+		}
+	}()
+	return m, waitForJob(id, updates)
+}
+
+// waitForJob creates a command waiting for the next progress of a batch job
+func waitForJob(id int, updates <-chan JobProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return JobFinishedMsg{ID: id}
+		}
+		msg.updates = updates
+		return msg
+	}
+}
+
+// batchResultLine summarizes the test result of one config of a batch job
+func batchResultLine(alias string, result *CompatTestResult) string {
+	switch {
+	case result == nil:
+		return "✗ " + alias + "  没有结果"
+	case !result.Success:
+		reason := result.Error
+		for _, check := range result.Checks {
+			if reason == "" && !check.Passed {
+				reason = check.Message
+			}
+		}
+		return "✗ " + alias + "  " + compatLevelNames[result.CompatibilityLevel] + ": " + utils.Redact(reason)
+	default:
+		return "✓ " + alias + "  " + compatLevelNames[result.CompatibilityLevel] + " " + result.ResponseTime
+	}
+}
+
+// backgroundTest moves the running test to the jobs panel and returns to
+// the main view, its result being kept for the panel
+func (m Model) backgroundTest() Model {
+	j := job{Kind: jobPing, Title: "连接测试", run: m.testRun, cancel: m.cancelTest}
+	if m.viewState == ViewCompatTesting {
+		j.Kind, j.Title = jobCompatTest, "兼容性测试"
+	}
+	// The tested config, which the detail view may have opened away from
+	// the cursor
+	if m.testAlias != "" {
+		j.Alias = m.testAlias
+		j.Title += ": " + j.Alias
+	}
+	m.addJob(j)
+
+	// The result of the run now goes to the job
+	m.testing = false
+	m.cancelTest = nil
+	m.testRun++
+	m.viewState = ViewMain
+	m.message = "测试已转入后台"
+	return m
+}
+
+// finishBackgroundTest stores the result of a test sent to the background,
+// reporting whether run belonged to one
+func (m *Model) finishBackgroundTest(run int, ping *TestResult, compat *CompatTestResult) bool {
+	for i := range m.jobs {
+		j := &m.jobs[i]
+		if j.Kind == jobBatchTest || j.run != run || !j.Running() {
+			continue
+		}
+		j.Finished = time.Now()
+		j.pingResult, j.compatResult = ping, compat
+		if j.cancel != nil {
+			j.cancel()
+		}
+		m.message = j.Title + " 已完成"
+		return true
+	}
+	return false
+}
+
+// handleJobProgress records the progress of a batch job and waits for the
+// next one
+func (m Model) handleJobProgress(msg JobProgressMsg) (Model, tea.Cmd) {
+	if i := m.findJob(msg.ID); i >= 0 {
+		j := &m.jobs[i]
+		j.Done++
+		j.Lines = append(j.Lines, msg.Line)
+		if msg.Failed {
+			j.Failed++
+		}
+	}
+	return m, tea.Batch(waitForJob(msg.ID, msg.updates), loadResults(m.configManager))
+}
+
+// handleJobFinished marks a batch job as done
+func (m Model) handleJobFinished(msg JobFinishedMsg) Model {
+	i := m.findJob(msg.ID)
+	if i < 0 {
+		return m
+	}
+	j := &m.jobs[i]
+	j.Finished = time.Now()
+	if j.cancel != nil {
+		j.cancel()
+	}
+	if !j.Canceled {
+		m.message = fmt.Sprintf("批量测试完成: %d/%d 通过", j.Done-j.Failed, j.Total)
+	}
+	return m
+}
+
+// cancelJob stops a running job
+func (m *Model) cancelJob(i int) {
+	j := &m.jobs[i]
+	if !j.Running() {
+		return
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.Canceled = true
+	// Batch jobs finish once their worker stopped, single tests right away
+	if j.Kind != jobBatchTest {
+		j.Finished = time.Now()
+	}
+	m.message = j.Title + " 已取消"
+}
+
+// handleJobsViewKeys handles keyboard input in the jobs panel
+func (m Model) handleJobsViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keyMap()
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, keys.Back), key.Matches(msg, keys.Jobs), msg.String() == "q":
+		m.viewState = ViewMain
+	case key.Matches(msg, keys.Up):
+		if m.jobCursor > 0 {
+			m.jobCursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.jobCursor < len(m.jobs)-1 {
+			m.jobCursor++
+		}
+	case key.Matches(msg, keys.StopJob):
+		if m.jobCursor < len(m.jobs) {
+			m.cancelJob(m.jobCursor)
+		}
+	case key.Matches(msg, keys.Select):
+		if m.jobCursor < len(m.jobs) {
+			return m.openJobResult(m.jobs[m.jobCursor]), nil
+		}
+	}
+	return m, nil
+}
+
+// openJobResult shows the result of a finished single test in its result
+// view
+func (m Model) openJobResult(j job) Model {
+	if j.pingResult == nil && j.compatResult == nil {
+		return m
+	}
+	// The result views show the config under the cursor
+	for i, cfg := range m.configs {
+		if cfg.Alias == j.Alias {
+			m.cursor = i
+			m.adjustScrollOffset()
+		}
+	}
+	m.resultCachedAt = time.Time{}
+	if j.pingResult != nil {
+		m.testResult = j.pingResult
+		m.viewState = ViewPingResult
+	} else {
+		m.compatResult = j.compatResult
+		m.viewState = ViewCompatResult
+	}
+	return m
+}
+
+// RenderJobsView renders the background jobs, oldest first, with the
+// results of the selected batch job
+func (m Model) RenderJobsView() string {
+	var b strings.Builder
+	effectiveWidth := m.getEffectiveWidth(40)
+
+	b.WriteString(titleStyle.Render("后台任务"))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n\n")
+
+	if len(m.jobs) == 0 {
+		b.WriteString(dimStyle.Render("暂无后台任务"))
+		b.WriteString("\n")
+	}
+	for i, j := range m.jobs {
+		line := m.truncateText(jobStatusLine(j, time.Now()), effectiveWidth-2)
+		if i == m.jobCursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString(normalStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	if m.jobCursor < len(m.jobs) {
+		if j := m.jobs[m.jobCursor]; len(j.Lines) > 0 {
+			b.WriteString("\n")
+			// Leave room for the jobs, the title and the key hints
+			limit := max(m.height-len(m.jobs)-8, 1)
+			for _, line := range j.Lines[max(len(j.Lines)-limit, 0):] {
+				style := messageStyle
+				if strings.HasPrefix(line, "✗") {
+					style = errorStyle
+				}
+				b.WriteString("    " + style.Render(m.truncateText(line, effectiveWidth-4)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
+	return b.String()
+}
+
+// jobStatusLine describes a job on one line: its state, title, progress and
+// duration
+func jobStatusLine(j job, now time.Time) string {
+	end := j.Finished
+	if j.Running() {
+		end = now
+	}
+	elapsed := fmt.Sprintf("%.1fs", end.Sub(j.Started).Seconds())
+
+	var state string
+	switch {
+	case j.Canceled:
+		state = "⊘ 已取消"
+	case j.Running():
+		state = "⏳ 进行中"
+	case j.Failed > 0:
+		state = fmt.Sprintf("✗ %d 项失败", j.Failed)
+	case j.pingResult != nil && !j.pingResult.Success, j.compatResult != nil && !j.compatResult.Success:
+		state = "✗ 失败"
+	default:
+		state = "✓ 完成"
+	}
+
+	line := state + "  " + j.Title
+	if j.Kind == jobBatchTest {
+		line += fmt.Sprintf("  %d/%d", j.Done, j.Total)
+	}
+	return line + "  " + elapsed
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBatchTestJob(t *testing.T) {
+	m := NewModel(nil)
+	m.demo = true
+	m.configs = []models.APIConfig{
+		{Alias: "relay-fast", APIKey: "sk-fast"},
+		{Alias: "deepseek", APIKey: "sk-deepseek"},
+	}
+
+	m, cmd := m.startBatchTest()
+	if len(m.jobs) != 1 || !m.jobs[0].Running() || m.jobs[0].Total != 2 {
+		t.Fatalf("jobs = %+v, want one running batch job of 2 configs", m.jobs)
+	}
+	for done := false; !done; {
+		switch msg := cmd().(type) {
+		case JobProgressMsg:
+			m, _ = m.handleJobProgress(msg)
+			cmd = waitForJob(msg.ID, msg.updates)
+		case JobFinishedMsg:
+			m = m.handleJobFinished(msg)
+			done = true
+		default:
+			t.Fatalf("batch job sent %T", msg)
+		}
+	}
+
+	j := m.jobs[0]
+	if j.Running() || j.Done != 2 || j.Failed != 1 {
+		t.Fatalf("job = %+v, want it finished with 1 of 2 configs failing", j)
+	}
+	if !strings.HasPrefix(j.Lines[0], "✓ relay-fast") || !strings.HasPrefix(j.Lines[1], "✗ deepseek") {
+		t.Errorf("lines = %q, want relay-fast passing and deepseek failing", j.Lines)
+	}
+	if !strings.Contains(m.message, "1/2 通过") {
+		t.Errorf("message = %q, want the batch summary", m.message)
+	}
+	m.viewState = ViewJobs
+	if view := m.RenderJobsView(); !strings.Contains(view, "2/2") || !strings.Contains(view, "deepseek") {
+		t.Errorf("RenderJobsView() = %q, want the progress and the results", view)
+	}
+}
+
+func TestBackgroundTest(t *testing.T) {
+	cfg := models.APIConfig{Alias: "relay-fast", APIKey: "sk-fast"}
+	// Tested from the detail view of a config other than the one under
+	// the cursor
+	newTesting := func() (Model, tea.Cmd) {
+		m := NewModel(nil)
+		m.demo = true
+		m.configs = []models.APIConfig{{Alias: "deepseek", APIKey: "sk-deepseek"}, cfg}
+		m.selected = 1
+		m, cmd := m.startPing(cfg)
+		return m, cmd().(tea.BatchMsg)[1]
+	}
+	press := func(m Model, r rune) Model {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		return next.(Model)
+	}
+
+	m, cmd := newTesting()
+	m = press(m, 'b')
+	if m.viewState != ViewMain || m.testing || m.runningJobs() != 1 {
+		t.Fatalf("view = %v, testing = %v, running jobs = %d, want the test in the background", m.viewState, m.testing, m.runningJobs())
+	}
+	if m.jobs[0].Alias != "relay-fast" {
+		t.Errorf("job alias = %q, want the tested config relay-fast", m.jobs[0].Alias)
+	}
+	if view := m.RenderMainView(); !strings.Contains(view, "后台任务: 1 个进行中") {
+		t.Errorf("RenderMainView() does not show the running job:\n%s", view)
+	}
+
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if m.viewState != ViewMain || m.runningJobs() != 0 || m.jobs[0].pingResult == nil {
+		t.Fatalf("view = %v, jobs = %+v, want the result kept by the job", m.viewState, m.jobs)
+	}
+
+	m = press(m, 'J')
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(Model); m.viewState != ViewPingResult || m.testResult == nil || !m.testResult.Success {
+		t.Errorf("view = %v, result = %+v, want the ping result opened from the panel", m.viewState, m.testResult)
+	}
+
+	// A canceled job drops its result
+	m, cmd = newTesting()
+	m = press(m, 'b')
+	m = press(m, 'J')
+	m = press(m, 'x')
+	if !m.jobs[0].Canceled || m.runningJobs() != 0 {
+		t.Fatalf("jobs = %+v, want the job canceled", m.jobs)
+	}
+	next, _ = m.Update(cmd())
+	if m = next.(Model); m.jobs[0].pingResult != nil || m.viewState != ViewJobs {
+		t.Errorf("view = %v, job = %+v, want the result of the canceled test dropped", m.viewState, m.jobs[0])
+	}
+}
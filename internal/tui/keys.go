@@ -1,6 +1,12 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 // KeyMap defines all keyboard shortcuts
 type KeyMap struct {
@@ -8,6 +14,10 @@ type KeyMap struct {
 	Down         key.Binding // j - move down
 	Top          key.Binding // g - jump to top
 	Bottom       key.Binding // G - jump to bottom
+	PageUp       key.Binding // PgUp - page up in lists
+	PageDown     key.Binding // PgDn/Space - page down in lists
+	HalfPageUp   key.Binding // Ctrl+U - half a page up in the config list
+	HalfPageDown key.Binding // Ctrl+D - half a page down in the config list
 	Select       key.Binding // Enter - select
 	SwitchLocal  key.Binding // s - switch local (Claude Code only)
 	SwitchGlobal key.Binding // S - switch global active config
@@ -19,8 +29,31 @@ type KeyMap struct {
 	Model        key.Binding // m - switch model
 	Help         key.Binding // ? - help
 	Quit         key.Binding // q - quit
+	QuickSwitch  key.Binding // 1-9 - switch the Nth config locally
+	Previous     key.Binding // - - switch back to the previous config
+	EnvFilter    key.Binding // f - cycle environment filter
+	Preview      key.Binding // v - preview the generated exports
+	OpenEditor   key.Binding // E - edit the config file in $EDITOR
+	Compare      key.Binding // c - compare two configs
+	Messages     key.Binding // M - message history
+	Undo         key.Binding // u - restore the config deleted last
+	TestAll      key.Binding // T - test every listed config in the background
+	Jobs         key.Binding // J - background jobs
 	Cancel       key.Binding // Esc - cancel
 	Confirm      key.Binding // Enter - confirm (in form)
+
+	// View-specific bindings, only shown in the footer of the matching view
+	Back          key.Binding // Esc - return to the previous view
+	NextField     key.Binding // Tab - next form field
+	PrevField     key.Binding // Shift+Tab - previous form field
+	Scroll        key.Binding // j/k - scroll content
+	Retry         key.Binding // r - retry a test
+	ConfirmDelete key.Binding // y - confirm deletion
+	ForceQuit     key.Binding // Ctrl+C - quit while a test is running
+	ImportClaude  key.Binding // i - import Claude Code's settings in the first-run guide
+	InstallShell  key.Binding // s - set up shell integration in the first-run guide
+	Background    key.Binding // b - send the running test to the background
+	StopJob       key.Binding // x - cancel the selected background job
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -42,6 +75,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("G"),
 			key.WithHelp("G", "跳到底部"),
 		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("PgUp", "上一页"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", " "),
+			key.WithHelp("PgDn/空格", "下一页"),
+		),
+		HalfPageUp: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+			key.WithHelp("Ctrl+U", "上翻半页"),
+		),
+		HalfPageDown: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("Ctrl+D", "下翻半页"),
+		),
 		Select: key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("Enter", "选择"),
@@ -86,6 +135,46 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "退出"),
 		),
+		QuickSwitch: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "快速切换"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "切回上一个"),
+		),
+		EnvFilter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "筛选环境"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "导出预览"),
+		),
+		OpenEditor: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "编辑配置文件"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "对比配置"),
+		),
+		Messages: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "消息历史"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "恢复删除"),
+		),
+		TestAll: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "批量测试"),
+		),
+		Jobs: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "后台任务"),
+		),
 		Cancel: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("Esc", "取消"),
@@ -94,6 +183,50 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("Enter", "确认"),
 		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "返回"),
+		),
+		NextField: key.NewBinding(
+			key.WithKeys("tab", "down"),
+			key.WithHelp("Tab/↓", "下一项"),
+		),
+		PrevField: key.NewBinding(
+			key.WithKeys("shift+tab", "up"),
+			key.WithHelp("Shift+Tab/↑", "上一项"),
+		),
+		Scroll: key.NewBinding(
+			key.WithKeys("j", "k", "down", "up"),
+			key.WithHelp("j/k", "上下移动"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "重试"),
+		),
+		ConfirmDelete: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "确认删除"),
+		),
+		ForceQuit: key.NewBinding(
+			key.WithKeys("ctrl+c"),
+			key.WithHelp("Ctrl+C", "退出"),
+		),
+		ImportClaude: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "导入 Claude 设置"),
+		),
+		InstallShell: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "Shell 集成"),
+		),
+		Background: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "转入后台"),
+		),
+		StopJob: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "取消任务"),
+		),
 	}
 }
 
@@ -102,12 +235,152 @@ func (k KeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{k.Up, k.Down, k.Select, k.SwitchLocal, k.SwitchGlobal, k.Quit}
 }
 
+// ShortHelpFor returns the footer bindings relevant to the given view, so each
+// screen only advertises the keys it actually handles.
+func (k KeyMap) ShortHelpFor(state ViewState) []key.Binding {
+	switch state {
+	case ViewDetail:
+		return []key.Binding{k.SwitchLocal, k.SwitchGlobal, k.Edit, k.Delete, k.Ping, k.Test, k.Model, k.Preview, k.Back}
+	case ViewAdd, ViewEdit:
+		return []key.Binding{k.NextField, k.PrevField, k.Confirm, k.Cancel}
+	case ViewDelete:
+		cancel := k.Cancel
+		cancel.SetHelp("n/"+k.Cancel.Help().Key, k.Cancel.Help().Desc)
+		return []key.Binding{k.ConfirmDelete, cancel}
+	case ViewHelp:
+		back := k.Back
+		back.SetHelp("q/"+k.Back.Help().Key, k.Back.Help().Desc)
+		return []key.Binding{k.Scroll, back}
+	case ViewModelSelect:
+		confirm := k.Confirm
+		confirm.SetHelp(k.Confirm.Help().Key, "确认切换")
+		pageDown := k.PageDown
+		pageDown.SetHelp(k.PageDown.Help().Key, "翻页")
+		return []key.Binding{k.Scroll, pageDown, confirm, k.Cancel}
+	case ViewPingTesting, ViewCompatTesting:
+		cancel := k.Cancel
+		cancel.SetHelp(k.Cancel.Help().Key, "取消测试")
+		return []key.Binding{cancel, k.Background, k.ForceQuit}
+	case ViewExportPreview, ViewMessageLog:
+		back := k.Back
+		back.SetHelp("q/"+k.Back.Help().Key, k.Back.Help().Desc)
+		return []key.Binding{back}
+	case ViewJobs:
+		selectKey := k.Select
+		selectKey.SetHelp(k.Select.Help().Key, "查看结果")
+		back := k.Back
+		back.SetHelp("q/"+k.Back.Help().Key, k.Back.Help().Desc)
+		return []key.Binding{k.Scroll, selectKey, k.StopJob, back}
+	case ViewCompare:
+		test := k.Test
+		test.SetHelp(k.Test.Help().Key, "测试两者")
+		back := k.Back
+		back.SetHelp("q/"+k.Back.Help().Key, k.Back.Help().Desc)
+		return []key.Binding{test, back}
+	case ViewOnboarding:
+		back := k.Back
+		back.SetHelp(k.Back.Help().Key, "跳过")
+		return []key.Binding{k.ImportClaude, k.Add, k.InstallShell, back, k.Quit}
+	case ViewPingResult, ViewCompatResult:
+		back := k.Back
+		back.SetHelp("Enter/"+k.Back.Help().Key, k.Back.Help().Desc)
+		return []key.Binding{k.Retry, back}
+	default:
+		return k.ShortHelp()
+	}
+}
+
 // FullHelp returns full help text
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Top, k.Bottom},
-		{k.Select, k.SwitchLocal, k.SwitchGlobal, k.Add},
-		{k.Edit, k.Delete, k.Ping, k.Test},
-		{k.Model, k.Help, k.Quit, k.Cancel},
+		{k.Up, k.Down, k.Top, k.Bottom, k.PageUp, k.PageDown, k.HalfPageUp, k.HalfPageDown},
+		{k.Select, k.SwitchLocal, k.SwitchGlobal, k.Previous, k.Add},
+		{k.Edit, k.Delete, k.Undo, k.Ping, k.Test, k.Compare, k.Preview, k.OpenEditor},
+		{k.Model, k.QuickSwitch, k.EnvFilter, k.Messages, k.TestAll, k.Jobs, k.Help, k.Quit, k.Cancel},
+	}
+}
+
+// bindings returns the remappable bindings keyed by their config file name
+func (k *KeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":             &k.Up,
+		"down":           &k.Down,
+		"top":            &k.Top,
+		"bottom":         &k.Bottom,
+		"page_up":        &k.PageUp,
+		"page_down":      &k.PageDown,
+		"half_page_up":   &k.HalfPageUp,
+		"half_page_down": &k.HalfPageDown,
+		"select":         &k.Select,
+		"switch_local":   &k.SwitchLocal,
+		"switch_global":  &k.SwitchGlobal,
+		"add":            &k.Add,
+		"edit":           &k.Edit,
+		"delete":         &k.Delete,
+		"ping":           &k.Ping,
+		"test":           &k.Test,
+		"model":          &k.Model,
+		"help":           &k.Help,
+		"quit":           &k.Quit,
+		"quick_switch":   &k.QuickSwitch,
+		"previous":       &k.Previous,
+		"env_filter":     &k.EnvFilter,
+		"preview":        &k.Preview,
+		"open_editor":    &k.OpenEditor,
+		"compare":        &k.Compare,
+		"messages":       &k.Messages,
+		"undo":           &k.Undo,
+		"test_all":       &k.TestAll,
+		"jobs":           &k.Jobs,
+		"back":           &k.Back,
+	}
+}
+
+// conflictGroups lists the actions that are handled by the same view and
+// therefore must not share a key
+var conflictGroups = [][]string{
+	{"up", "down", "top", "bottom", "page_up", "page_down", "half_page_up", "half_page_down", "select", "switch_local", "switch_global", "add", "edit", "delete", "ping", "test", "model", "help", "quit", "quick_switch", "previous", "env_filter", "preview", "open_editor", "compare", "messages", "undo", "test_all", "jobs"},
+	{"back", "switch_local", "switch_global", "edit", "delete", "ping", "test", "model", "preview", "help", "quit"},
+}
+
+// ApplyOverrides remaps bindings from the keybindings config section.
+// An empty key list disables the action.
+func (k *KeyMap) ApplyOverrides(overrides map[string][]string) error {
+	bindings := k.bindings()
+	for action, keys := range overrides {
+		b, ok := bindings[action]
+		if !ok {
+			return fmt.Errorf("unknown keybinding action '%s'", action)
+		}
+		if len(keys) == 0 {
+			b.SetEnabled(false)
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+	return k.Validate()
+}
+
+// Validate reports keys bound to more than one action within the same view
+func (k *KeyMap) Validate() error {
+	bindings := k.bindings()
+	for _, group := range conflictGroups {
+		owner := make(map[string]string)
+		for _, action := range group {
+			b := bindings[action]
+			if !b.Enabled() {
+				continue
+			}
+			for _, kk := range b.Keys() {
+				if other, exists := owner[kk]; exists {
+					names := []string{other, action}
+					sort.Strings(names)
+					return fmt.Errorf("key '%s' is bound to both '%s' and '%s'", kk, names[0], names[1])
+				}
+				owner[kk] = action
+			}
+		}
 	}
+	return nil
 }
@@ -0,0 +1,322 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"apimgr/config/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestShortHelpFor tests that each view advertises only its own keys
+func TestShortHelpFor(t *testing.T) {
+	keys := DefaultKeyMap()
+
+	tests := []struct {
+		name       string
+		state      ViewState
+		wantKeys   []string
+		absentKeys []string
+	}{
+		{
+			name:       "main view uses default short help",
+			state:      ViewMain,
+			wantKeys:   []string{"Enter", "q"},
+			absentKeys: []string{"Tab/↓"},
+		},
+		{
+			name:       "form view shows field navigation",
+			state:      ViewAdd,
+			wantKeys:   []string{"Tab/↓", "Shift+Tab/↑", "Enter", "Esc"},
+			absentKeys: []string{"a", "q"},
+		},
+		{
+			name:       "edit view shares form keys",
+			state:      ViewEdit,
+			wantKeys:   []string{"Tab/↓", "Enter", "Esc"},
+			absentKeys: []string{"d"},
+		},
+		{
+			name:       "delete confirm shows confirm and cancel",
+			state:      ViewDelete,
+			wantKeys:   []string{"y", "n/Esc"},
+			absentKeys: []string{"a"},
+		},
+		{
+			name:       "model select shows paging",
+			state:      ViewModelSelect,
+			wantKeys:   []string{"j/k", "PgDn/空格", "Enter", "Esc"},
+			absentKeys: []string{"d"},
+		},
+		{
+			name:       "ping result shows retry",
+			state:      ViewPingResult,
+			wantKeys:   []string{"r", "Enter/Esc"},
+			absentKeys: []string{"a"},
+		},
+		{
+			name:       "compat result shows retry",
+			state:      ViewCompatResult,
+			wantKeys:   []string{"r", "Enter/Esc"},
+			absentKeys: []string{"a"},
+		},
+		{
+			name:       "compare view shows test and back",
+			state:      ViewCompare,
+			wantKeys:   []string{"t", "q/Esc"},
+			absentKeys: []string{"a", "c"},
+		},
+		{
+			name:       "testing view allows cancel and quit",
+			state:      ViewPingTesting,
+			wantKeys:   []string{"Esc", "Ctrl+C"},
+			absentKeys: []string{"r"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]bool)
+			for _, b := range keys.ShortHelpFor(tt.state) {
+				got[b.Help().Key] = true
+			}
+			for _, k := range tt.wantKeys {
+				if !got[k] {
+					t.Errorf("ShortHelpFor(%v) missing key %q", tt.state, k)
+				}
+			}
+			for _, k := range tt.absentKeys {
+				if got[k] {
+					t.Errorf("ShortHelpFor(%v) should not contain key %q", tt.state, k)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderStatusBarFollowsViewState tests the status bar renders view-specific hints
+func TestRenderStatusBarFollowsViewState(t *testing.T) {
+	m := Model{viewState: ViewModelSelect}
+	output := m.RenderStatusBar()
+	if !strings.Contains(output, "确认切换") {
+		t.Errorf("RenderStatusBar() in model select should contain '确认切换', got %q", output)
+	}
+
+	m.viewState = ViewMain
+	output = m.RenderStatusBar()
+	if strings.Contains(output, "确认切换") {
+		t.Errorf("RenderStatusBar() in main view should not contain '确认切换', got %q", output)
+	}
+}
+
+// TestApplyOverrides tests remapping, disabling and conflict detection
+func TestApplyOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   bool
+	}{
+		{name: "no overrides", overrides: nil},
+		{name: "remap switch keys", overrides: map[string][]string{"switch_local": {"l"}, "switch_global": {"L"}}},
+		{name: "disable delete", overrides: map[string][]string{"delete": {}}},
+		{name: "swap keys between actions", overrides: map[string][]string{"add": {"d"}, "delete": {"a"}}},
+		{name: "unknown action", overrides: map[string][]string{"launch": {"x"}}, wantErr: true},
+		{name: "conflict in main view", overrides: map[string][]string{"edit": {"s"}}, wantErr: true},
+		{name: "conflict in detail view", overrides: map[string][]string{"back": {"p"}}, wantErr: true},
+		{name: "conflict resolved by disabling", overrides: map[string][]string{"edit": {"d"}, "delete": {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := DefaultKeyMap()
+			err := keys.ApplyOverrides(tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRemappedKeysInMainView tests that handlers follow the configured bindings
+func TestRemappedKeysInMainView(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.ApplyOverrides(map[string][]string{"delete": {}, "edit": {"E"}, "open_editor": {"ctrl+e"}}); err != nil {
+		t.Fatalf("ApplyOverrides() unexpected error: %v", err)
+	}
+
+	m := Model{
+		configs:   []models.APIConfig{{Alias: "test-config", APIKey: "sk-test-key"}},
+		viewState: ViewMain,
+		keys:      &keys,
+	}
+
+	// Disabled delete key does nothing
+	newModel, _ := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if newModel.(Model).viewState != ViewMain {
+		t.Errorf("disabled delete key changed viewState to %v", newModel.(Model).viewState)
+	}
+
+	// Old edit key no longer matches
+	newModel, _ = m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if newModel.(Model).viewState != ViewMain {
+		t.Errorf("old edit key changed viewState to %v", newModel.(Model).viewState)
+	}
+
+	// Remapped edit key opens the form
+	newModel, _ = m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	if newModel.(Model).viewState != ViewEdit {
+		t.Errorf("remapped edit key viewState = %v, want ViewEdit", newModel.(Model).viewState)
+	}
+
+	// Footer hides the disabled action
+	m.viewState = ViewDetail
+	if strings.Contains(m.RenderStatusBar(), "删除配置") {
+		t.Error("RenderStatusBar() should not advertise a disabled action")
+	}
+}
+
+// TestViewKeyHintsFollowKeyMap tests that view footers advertise the
+// configured bindings rather than the defaults
+func TestViewKeyHintsFollowKeyMap(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.ApplyOverrides(map[string][]string{"test": {"z"}, "back": {"backspace"}}); err != nil {
+		t.Fatalf("ApplyOverrides() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		view ViewState
+		want []string
+	}{
+		{view: ViewDetail, want: []string{"z", "backspace"}},
+		{view: ViewCompare, want: []string{"z", "q/backspace"}},
+		{view: ViewPingResult, want: []string{"Enter/backspace"}},
+		{view: ViewHelp, want: []string{"q/backspace"}},
+	}
+	for _, tt := range tests {
+		m := Model{viewState: tt.view, keys: &keys}
+		hints := m.renderViewKeyHints()
+		for _, want := range tt.want {
+			if !strings.Contains(hints, want) {
+				t.Errorf("view %v footer = %q, want it to show %q", tt.view, hints, want)
+			}
+		}
+		if strings.Contains(hints, "Esc") {
+			t.Errorf("view %v footer = %q, want no default Esc binding", tt.view, hints)
+		}
+	}
+}
+
+// TestCtrlCAlwaysQuits tests that remapping or disabling quit keeps Ctrl+C
+// as a way out
+func TestCtrlCAlwaysQuits(t *testing.T) {
+	for _, override := range [][]string{{"x"}, {}} {
+		keys := DefaultKeyMap()
+		if err := keys.ApplyOverrides(map[string][]string{"quit": override}); err != nil {
+			t.Fatalf("ApplyOverrides() unexpected error: %v", err)
+		}
+		for _, view := range []ViewState{ViewMain, ViewDetail, ViewAdd} {
+			m := Model{
+				configs:    []models.APIConfig{{Alias: "test-config", APIKey: "sk-test-key"}},
+				viewState:  view,
+				keys:       &keys,
+				formInputs: FormInputs(),
+			}
+			_, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlC})
+			if cmd == nil {
+				t.Fatalf("quit = %q, view %v: Ctrl+C returned no command", override, view)
+			}
+			if _, ok := cmd().(tea.QuitMsg); !ok {
+				t.Errorf("quit = %q, view %v: Ctrl+C did not quit", override, view)
+			}
+		}
+	}
+}
+
+// TestModelSelectPagingFollowsKeyMap tests that the model list pages with
+// the configured page keys
+func TestModelSelectPagingFollowsKeyMap(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.ApplyOverrides(map[string][]string{"page_down": {"n"}}); err != nil {
+		t.Fatalf("ApplyOverrides() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		key   tea.KeyMsg
+		paged bool
+	}{
+		{name: "remapped key", key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}, paged: true},
+		{name: "default space", key: tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, paged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				configs:   []models.APIConfig{{Alias: "relay"}},
+				modelList: make([]string, 50),
+				viewState: ViewModelSelect,
+				height:    20,
+				keys:      &keys,
+			}
+			next, _ := m.handleModelSelectViewKeys(tt.key)
+			if got := next.(Model).modelCursor > 0; got != tt.paged {
+				t.Errorf("modelCursor = %d, want paged: %v", next.(Model).modelCursor, tt.paged)
+			}
+		})
+	}
+
+	m := Model{viewState: ViewModelSelect, keys: &keys}
+	if hints := m.renderViewKeyHints(); !strings.Contains(hints, "n") || strings.Contains(hints, "空格") {
+		t.Errorf("footer = %q, want the remapped page key", hints)
+	}
+}
+
+// TestResultViewsFollowKeyMap tests that the help, model and result views
+// act on the remapped keys their footers show instead of the defaults
+func TestResultViewsFollowKeyMap(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.ApplyOverrides(map[string][]string{"back": {"backspace"}, "down": {"o"}}); err != nil {
+		t.Fatalf("ApplyOverrides() unexpected error: %v", err)
+	}
+	newModel := func(view ViewState) Model {
+		return Model{
+			configs:   []models.APIConfig{{Alias: "relay"}},
+			modelList: []string{"a", "b", "c"},
+			viewState: view,
+			height:    10,
+			keys:      &keys,
+		}
+	}
+	press := func(m Model, k tea.KeyMsg) Model {
+		next, _ := m.handleKeyMsg(k)
+		return next.(Model)
+	}
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+
+	for _, view := range []ViewState{ViewHelp, ViewModelSelect, ViewPingResult, ViewCompatResult} {
+		if m := press(newModel(view), backspace); m.viewState != ViewMain {
+			t.Errorf("view %v: remapped back left view %v, want the main view", view, m.viewState)
+		}
+	}
+	// Esc stays the cancel key of the model list
+	for _, view := range []ViewState{ViewHelp, ViewPingResult, ViewCompatResult} {
+		if m := press(newModel(view), esc); m.viewState != view {
+			t.Errorf("view %v: default Esc left it for view %v after back was remapped", view, m.viewState)
+		}
+	}
+
+	o := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}}
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}
+	if m := press(newModel(ViewHelp), o); m.helpScrollOffset != 1 {
+		t.Errorf("help scroll offset = %d after remapped down, want 1", m.helpScrollOffset)
+	}
+	if m := press(newModel(ViewHelp), j); m.helpScrollOffset != 0 {
+		t.Errorf("help scroll offset = %d after default j, want 0", m.helpScrollOffset)
+	}
+	if m := press(newModel(ViewModelSelect), o); m.modelCursor != 1 {
+		t.Errorf("model cursor = %d after remapped down, want 1", m.modelCursor)
+	}
+	if m := press(newModel(ViewModelSelect), j); m.modelCursor != 0 {
+		t.Errorf("model cursor = %d after default j, want 0", m.modelCursor)
+	}
+}
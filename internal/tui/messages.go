@@ -3,14 +3,36 @@ package tui
 import (
 	"time"
 
+	"apimgr/config/history"
 	"apimgr/config/models"
 	"apimgr/internal/compatibility"
+	"apimgr/internal/probe"
 )
 
 // ConfigsLoadedMsg is sent when configs are loaded
 type ConfigsLoadedMsg struct {
 	Configs     []models.APIConfig
 	ActiveAlias string
+	Err         string // Configurations left out of Configs, see config.ResolveError
+}
+
+// SyncStatus tells where a config is currently in effect
+type SyncStatus struct {
+	Global  bool // Claude Code's user settings.json points Claude Code at it
+	Project bool // .claude settings in the current directory point Claude Code at it
+	Session bool // Local session of this terminal (switch -l)
+}
+
+// SyncStatusLoadedMsg is sent when the sync status of the configs has been computed
+type SyncStatusLoadedMsg struct {
+	Status map[string]SyncStatus // Keyed by alias
+}
+
+// ConfigFileEditedMsg is sent when the editor opened on the config file exits
+type ConfigFileEditedMsg struct {
+	Path      string // Copy that was edited
+	EditorErr error  // The editor could not be run
+	Err       error  // The edit was rejected, Path can be re-opened
 }
 
 // ConfigSwitchedMsg is sent when active config is switched
@@ -18,6 +40,7 @@ type ConfigSwitchedMsg struct {
 	Alias   string
 	IsLocal bool // true for local switch, false for global switch
 	Err     error
+	HookErr error // A post_switch hook failed after the switch
 }
 
 // ConfigAddedMsg is sent when a config is added
@@ -34,6 +57,13 @@ type ConfigUpdatedMsg struct {
 
 // ConfigDeletedMsg is sent when a config is deleted
 type ConfigDeletedMsg struct {
+	Alias      string
+	SwitchedTo string // Config made active before the deletion, if any
+	Err        error
+}
+
+// ConfigRestoredMsg is sent when a deleted config is restored from the trash
+type ConfigRestoredMsg struct {
 	Alias string
 	Err   error
 }
@@ -42,13 +72,33 @@ type ConfigDeletedMsg struct {
 type PingResultMsg struct {
 	Success  bool
 	Duration time.Duration
+	Timings  probe.Timings // Per-phase breakdown of the last sample
+	Stats    probe.Stats   // Latency summary across samples
 	Err      error
+	Run      int // Test run the result belongs to
+}
+
+// HistoryLoadedMsg is sent when a config's health history has been loaded
+type HistoryLoadedMsg struct {
+	Alias   string
+	Entries []history.Entry
 }
 
 // CompatResultMsg is sent when compatibility test completes
 type CompatResultMsg struct {
 	Result *compatibility.TestResult
 	Err    error
+	Run    int // Test run the result belongs to
+}
+
+// ResultsLoadedMsg is sent when the cached test results have been loaded
+type ResultsLoadedMsg struct {
+	Results map[string]map[string]history.CachedResult // Keyed by alias then kind
+}
+
+// CompareResultMsg is sent when the tests of the compare view complete
+type CompareResultMsg struct {
+	Results [2]*compatibility.TestResult // In the order of the compared configs
 }
 
 // ModelSwitchedMsg is sent when model is switched
@@ -59,3 +109,18 @@ type ModelSwitchedMsg struct {
 	Activate bool
 	Err      error
 }
+
+// OnboardingDetectedMsg is sent when the first-run guide has looked for an
+// existing setup
+type OnboardingDetectedMsg struct {
+	ClaudeEnv map[string]string // Env of Claude Code's user settings
+	ShellRC   string            // Shell rc file that already loads the active config
+}
+
+// ShellInstalledMsg is sent when apimgr install, run from the first-run
+// guide, exits
+type ShellInstalledMsg struct {
+	Output  string
+	ShellRC string // Shell rc file that now loads the active config
+	Err     error
+}
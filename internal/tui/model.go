@@ -2,16 +2,34 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"apimgr/config"
+	"apimgr/config/history"
 	"apimgr/config/models"
+	"apimgr/config/session"
+	syncpkg "apimgr/config/sync"
 	"apimgr/internal/compatibility"
-
+	"apimgr/internal/hooks"
+	"apimgr/internal/modelinfo"
+	"apimgr/internal/preflight"
+	"apimgr/internal/probe"
+	"apimgr/internal/utils"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -40,11 +58,18 @@ const (
 	ViewPingResult                     // Ping test result
 	ViewCompatTesting                  // Compatibility test in progress
 	ViewCompatResult                   // Compatibility test result
+	ViewExportPreview                  // Preview of the generated exports
+	ViewCompare                        // Two configs side by side
+	ViewOnboarding                     // First-run guide
+	ViewMessageLog                     // History of status messages
+	ViewJobs                           // Background jobs
 )
 
 // Model is the core state model for TUI
 type Model struct {
-	configs       []models.APIConfig // Config list
+	configs       []models.APIConfig // Config list, filtered by envFilter
+	allConfigs    []models.APIConfig // Unfiltered config list
+	envFilter     string             // Environment filter, empty shows all
 	activeAlias   string             // Current active config alias
 	cursor        int                // Current cursor position
 	selected      int                // Currently selected config index
@@ -59,6 +84,13 @@ type Model struct {
 	message  string // Status message
 	errorMsg string // Error message
 
+	// When the messages were set, numbered so that clearing them after
+	// messageTimeout spares newer ones, and the messages shown so far
+	messageAt      time.Time
+	messageSeq     int
+	messageTimeout time.Duration
+	messageLog     []loggedMessage
+
 	// Window size
 	width  int
 	height int
@@ -67,13 +99,70 @@ type Model struct {
 	scrollOffset      int // Scroll offset for main list view
 	modelScrollOffset int // Scroll offset for model selection list
 
+	// Styled config list lines, shared by the copies of the model so that
+	// lines that did not change are not styled again on every render
+	lineCache map[configLineKey]string
+
 	// Test state
 	testing    bool        // Whether testing is in progress
 	testResult *TestResult // Test result
 
+	// Running test: cancels it, when it started, the tested config and its
+	// spinner. testRun numbers the runs so that the result of a canceled one
+	// is dropped.
+	cancelTest  context.CancelFunc
+	testStarted time.Time
+	testAlias   string
+	testRun     int
+	spinner     spinner.Model
+
+	// Last test results of each config, keyed by alias then kind, reused
+	// for resultTTL. resultCachedAt is when the shown result was obtained,
+	// zero when it was just tested.
+	results        map[string]map[string]history.CachedResult
+	resultTTL      time.Duration
+	resultCachedAt time.Time
+
 	// Compatibility test state
 	compatResult *CompatTestResult // Compatibility test result
 
+	// Health history of the config shown in the detail view
+	healthHistory []history.Entry
+
+	// Config shown in the detail pane of the two-pane layout
+	previewAlias string
+
+	// View the export preview returns to
+	previewReturn ViewState
+
+	// Where each config is in effect, computed after configs load
+	syncStatus map[string]SyncStatus
+
+	// Compare state: the base marked by the first c, then the two configs
+	// shown side by side and their test results once tested
+	compareBase    string
+	compareConfigs [2]models.APIConfig
+	compareResults [2]*compatibility.TestResult
+	compareTesting bool
+
+	// Copy of the config file with a rejected edit, re-opened by E
+	editPath string
+
+	// Deleting a config in use asks to type its alias, and for the active
+	// one offers a config to switch to first
+	deleteUse      config.ConfigUse
+	deleteInput    textinput.Model
+	deleteSwitchTo string
+
+	// Config deleted last, restored from the trash with u
+	lastDeleted string
+
+	// Background jobs listed by the jobs panel, the last job ID and the
+	// job under the panel's cursor
+	jobs      []job
+	jobSeq    int
+	jobCursor int
+
 	// Model selection state
 	modelCursor int        // Cursor position in model selection list
 	modelList   []string   // Available models for current config
@@ -81,6 +170,26 @@ type Model struct {
 
 	// Help view scroll state
 	helpScrollOffset int // Scroll offset for help view
+
+	// Key bindings, nil means DefaultKeyMap
+	keys *KeyMap
+
+	// Model capability and pricing metadata, nil means the bundled table
+	modelInfo modelinfo.Table
+
+	// Replace box-drawing characters, arrows and emoji with ASCII
+	ascii bool
+
+	// Tests return the synthetic results of demo mode
+	demo bool
+
+	// Screen reader mode: state changes are printed as text and the layout
+	// stays linear
+	accessible bool
+
+	// Configs have been loaded once, the first-run guide is only shown then
+	loaded     bool
+	onboarding onboardingState
 }
 
 // CompatTestResult holds compatibility test result data
@@ -90,6 +199,7 @@ type CompatTestResult struct {
 	Checks             []CompatCheck
 	ResponseTime       string
 	Error              string
+	RateLimits         []compatibility.RateLimit
 }
 
 // CompatCheck represents a single compatibility check result
@@ -105,6 +215,8 @@ type TestResult struct {
 	Success  bool
 	Message  string
 	Duration string
+	Timings  probe.Timings
+	Stats    probe.Stats
 }
 
 // NewModel creates a new TUI model
@@ -122,34 +234,81 @@ func NewModel(cm *config.Manager) Model {
 		scrollOffset:      0,
 		modelScrollOffset: 0,
 		switchType:        SwitchTypeNone,
+		lineCache:         make(map[configLineKey]string),
+		messageTimeout:    defaultMessageTimeout,
+		resultTTL:         history.DefaultResultTTL,
 	}
 }
 
+// keyMap returns the active key bindings, falling back to the defaults
+func (m Model) keyMap() KeyMap {
+	if m.keys == nil {
+		return DefaultKeyMap()
+	}
+	return *m.keys
+}
+
+// modelMeta returns the model metadata table, falling back to the bundled one
+func (m Model) modelMeta() modelinfo.Table {
+	if m.modelInfo == nil {
+		return modelinfo.Bundled()
+	}
+	return m.modelInfo
+}
+
 // Init initializes the model and returns initial commands
 func (m Model) Init() tea.Cmd {
 	return loadConfigs(m.configManager)
 }
 
-// Update handles messages and updates the model
+// Update handles messages and updates the model. In accessible mode the
+// changes are also printed as text above the view.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(Model); ok {
+		var clear tea.Cmd
+		next, clear = trackMessages(m, n)
+		cmd = tea.Batch(cmd, clear)
+	}
+	if !m.accessible {
+		return next, cmd
+	}
+	if n, ok := next.(Model); ok {
+		if text := announce(m, n); text != "" {
+			cmd = tea.Batch(cmd, tea.Println(text))
+		}
+	}
+	return next, cmd
+}
+
+// update handles messages and updates the model
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		return m.handleKeyMsg(msg)
+		next, cmd := m.handleKeyMsg(msg)
+		return previewCursor(next, cmd)
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		// Adjust scroll offset if needed after window resize - Requirements: 11.1
 		m.adjustScrollOffset()
-		return m, nil
+		return previewCursor(m, nil)
 
 	case ConfigsLoadedMsg:
+		m.allConfigs = msg.Configs
 		m.configs = msg.Configs
+		if msg.Err != "" {
+			m.errorMsg = msg.Err
+		}
+		if m.envFilter != "" {
+			m.configs = filterByEnvironment(msg.Configs, m.envFilter)
+		}
 
 		// Check if current active alias still exists in the new config list
 		activeExists := false
 		if m.activeAlias != "" {
-			for _, cfg := range m.configs {
+			for _, cfg := range msg.Configs {
 				if cfg.Alias == m.activeAlias {
 					activeExists = true
 					break
@@ -170,6 +329,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.selected >= len(m.configs) {
 			m.selected = -1
 		}
+
+		// Guide a first run instead of showing an empty list
+		if !m.loaded {
+			m.loaded = true
+			if len(msg.Configs) == 0 && msg.Err == "" && m.viewState == ViewMain {
+				m.onboarding = onboardingState{active: true}
+				m.viewState = ViewOnboarding
+				return m, detectOnboarding()
+			}
+		}
+		return previewCursor(m, tea.Batch(loadSyncStatus(m.configManager, msg.Configs), loadResults(m.configManager)))
+
+	case OnboardingDetectedMsg:
+		m.onboarding.detected = true
+		m.onboarding.claudeEnv = msg.ClaudeEnv
+		m.onboarding.shellRC = msg.ShellRC
+		return m, nil
+
+	case ShellInstalledMsg:
+		m.onboarding.installing = false
+		m.onboarding.installLog = msg.Output
+		if msg.Err != nil {
+			m.errorMsg = msg.Err.Error()
+			return m, nil
+		}
+		m.onboarding.shellRC = msg.ShellRC
+		return m, nil
+
+	case SyncStatusLoadedMsg:
+		m.syncStatus = msg.Status
+		return m, nil
+
+	case ResultsLoadedMsg:
+		m.results = msg.Results
+		return m, nil
+
+	case ConfigFileEditedMsg:
+		switch {
+		case msg.EditorErr != nil:
+			os.Remove(msg.Path)
+			m.editPath = ""
+			m.errorMsg = "无法运行编辑器: " + msg.EditorErr.Error()
+		case errors.Is(msg.Err, config.ErrConflict):
+			m.editPath = msg.Path
+			m.errorMsg = "编辑期间配置文件已被其他进程修改，未保存 (按 E 重新编辑，再次保存将覆盖那些修改，Esc 放弃)"
+		case msg.Err != nil:
+			m.editPath = msg.Path
+			m.errorMsg = "配置无效，未保存: " + msg.Err.Error() + " (按 E 重新编辑，Esc 放弃)"
+		default:
+			m.editPath = ""
+			m.message = "配置文件已保存"
+			return m, loadConfigs(m.configManager)
+		}
 		return m, nil
 
 	case ConfigSwitchedMsg:
@@ -183,6 +395,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.message = "已全局切换到: " + msg.Alias
 			}
+			if msg.HookErr != nil {
+				m.errorMsg = msg.HookErr.Error()
+			}
+			return m, loadSyncStatus(m.configManager, m.allConfigs)
 		}
 		return m, nil
 
@@ -194,6 +410,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewState = ViewMain
 			m.formInputs = []textinput.Model{}
 			m.formFocus = 0
+			// Back to the guide while shell integration is left to set up
+			if m.onboarding.active && m.onboarding.shellRC == "" {
+				m.viewState = ViewOnboarding
+			} else {
+				m.onboarding = onboardingState{}
+			}
 			// Reload configs
 			return m, loadConfigs(m.configManager)
 		}
@@ -221,12 +443,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.activeAlias == msg.Alias {
 				m.activeAlias = ""
 			}
+			if msg.SwitchedTo != "" {
+				m.activeAlias = msg.SwitchedTo
+				m.message += "，已切换到: " + msg.SwitchedTo
+			}
+			m.lastDeleted = msg.Alias
+			if undo := m.keyMap().Undo; undo.Enabled() {
+				m.message += "，按 " + undo.Help().Key + " 恢复"
+			}
 			// Reload configs
 			return m, loadConfigs(m.configManager)
 		}
 		m.viewState = ViewMain
 		return m, nil
 
+	case ConfigRestoredMsg:
+		if msg.Err != nil {
+			m.errorMsg = msg.Err.Error()
+			return m, nil
+		}
+		m.lastDeleted = ""
+		m.message = "配置已恢复: " + msg.Alias
+		return m, loadConfigs(m.configManager)
+
 	case ModelSwitchedMsg:
 		if msg.Err != nil {
 			m.errorMsg = msg.Err.Error()
@@ -251,51 +490,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case PingResultMsg:
-		m.testing = false
-		if msg.Err != nil {
-			m.testResult = &TestResult{
-				Success:  false,
-				Message:  msg.Err.Error(),
-				Duration: "",
-			}
-		} else {
-			m.testResult = &TestResult{
-				Success:  msg.Success,
-				Message:  "连接成功",
-				Duration: msg.Duration.String(),
-			}
+		if m.finishBackgroundTest(msg.Run, newPingTestResult(msg), nil) {
+			return m, loadResults(m.configManager)
 		}
+		if msg.Run != m.testRun {
+			return m, nil
+		}
+		m.finishTest()
+		m.testResult = newPingTestResult(msg)
 		m.viewState = ViewPingResult
+		return m, loadResults(m.configManager)
+
+	case HistoryLoadedMsg:
+		// Ignore results for a config that is no longer shown
+		if m.selected >= 0 && m.selected < len(m.configs) && m.configs[m.selected].Alias == msg.Alias {
+			m.healthHistory = msg.Entries
+		}
 		return m, nil
 
 	case CompatResultMsg:
-		m.testing = false
-		if msg.Err != nil {
-			m.compatResult = &CompatTestResult{
-				Success:            false,
-				CompatibilityLevel: compatibility.CompatibilityNone,
-				Error:              msg.Err.Error(),
-			}
-		} else if msg.Result != nil {
-			// Convert compatibility.TestResult to CompatTestResult
-			checks := make([]CompatCheck, len(msg.Result.Checks))
-			for i, c := range msg.Result.Checks {
-				checks[i] = CompatCheck{
-					Name:     c.Name,
-					Passed:   c.Passed,
-					Message:  c.Message,
-					Critical: c.Critical,
-				}
-			}
-			m.compatResult = &CompatTestResult{
-				Success:            msg.Result.Success,
-				CompatibilityLevel: msg.Result.CompatibilityLevel,
-				Checks:             checks,
-				ResponseTime:       msg.Result.ResponseTime.String(),
-				Error:              msg.Result.Error,
-			}
+		if m.finishBackgroundTest(msg.Run, nil, newCompatTestResult(msg)) {
+			return m, loadResults(m.configManager)
+		}
+		if msg.Run != m.testRun {
+			return m, nil
+		}
+		m.finishTest()
+		if result := newCompatTestResult(msg); result != nil {
+			m.compatResult = result
 		}
 		m.viewState = ViewCompatResult
+		return m, loadResults(m.configManager)
+
+	case clearMessageMsg:
+		return m.clearMessages(msg), nil
+
+	case spinner.TickMsg:
+		// The spinner stops once the test is over
+		if !m.testing {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case JobProgressMsg:
+		return m.handleJobProgress(msg)
+
+	case JobFinishedMsg:
+		return m.handleJobFinished(msg), loadResults(m.configManager)
+
+	case CompareResultMsg:
+		m.compareTesting = false
+		m.compareResults = msg.Results
 		return m, nil
 
 	case errMsg:
@@ -321,22 +568,22 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleHelpViewKeys(msg)
 	case ViewModelSelect:
 		return m.handleModelSelectViewKeys(msg)
-	case ViewPingTesting:
-		// During testing, only allow quit
-		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
-		}
-		return m, nil
-	case ViewCompatTesting:
-		// During compatibility testing, only allow quit
-		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
-		}
-		return m, nil
+	case ViewPingTesting, ViewCompatTesting:
+		return m.handleTestingViewKeys(msg)
 	case ViewPingResult:
 		return m.handlePingResultViewKeys(msg)
 	case ViewCompatResult:
 		return m.handleCompatResultViewKeys(msg)
+	case ViewExportPreview:
+		return m.handleExportPreviewKeys(msg)
+	case ViewCompare:
+		return m.handleCompareViewKeys(msg)
+	case ViewOnboarding:
+		return m.handleOnboardingKeys(msg)
+	case ViewMessageLog:
+		return m.handleMessageLogKeys(msg)
+	case ViewJobs:
+		return m.handleJobsViewKeys(msg)
 	default:
 		return m, nil
 	}
@@ -344,75 +591,121 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleMainViewKeys handles keyboard input in main view
 func (m Model) handleMainViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c":
+	keys := m.keyMap()
+	if m.refuseReadOnly(msg, mutatingKeys(keys)...) {
+		return m, nil
+	}
+	switch {
+	// Ctrl+C quits even when quit is remapped or disabled
+	case key.Matches(msg, keys.Quit), key.Matches(msg, keys.ForceQuit):
 		return m, tea.Quit
 
-	case "j", "down":
+	case key.Matches(msg, keys.Down):
 		m.moveDown()
 		// Clear messages on navigation
 		m.message = ""
 		m.errorMsg = ""
 		return m, nil
 
-	case "k", "up":
+	case key.Matches(msg, keys.Up):
 		m.moveUp()
 		// Clear messages on navigation
 		m.message = ""
 		m.errorMsg = ""
 		return m, nil
 
-	case "g":
+	case key.Matches(msg, keys.Top):
 		m.moveToTop()
 		// Clear messages on navigation
 		m.message = ""
 		m.errorMsg = ""
 		return m, nil
 
-	case "G":
+	case key.Matches(msg, keys.Bottom):
 		m.moveToBottom()
 		// Clear messages on navigation
 		m.message = ""
 		m.errorMsg = ""
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, keys.PageDown):
+		m.moveBy(m.getVisibleListHeight())
+		m.message = ""
+		m.errorMsg = ""
+		return m, nil
+
+	case key.Matches(msg, keys.PageUp):
+		m.moveBy(-m.getVisibleListHeight())
+		m.message = ""
+		m.errorMsg = ""
+		return m, nil
+
+	case key.Matches(msg, keys.HalfPageDown):
+		m.moveBy(max(m.getVisibleListHeight()/2, 1))
+		m.message = ""
+		m.errorMsg = ""
+		return m, nil
+
+	case key.Matches(msg, keys.HalfPageUp):
+		m.moveBy(-max(m.getVisibleListHeight()/2, 1))
+		m.message = ""
+		m.errorMsg = ""
+		return m, nil
+
+	case key.Matches(msg, keys.Select):
 		if len(m.configs) > 0 {
 			m.selected = m.cursor
 			m.viewState = ViewDetail
+			m.healthHistory = nil
+			return m, loadHistory(m.configManager, m.configs[m.cursor].Alias)
 		}
 		return m, nil
 
-	case "s":
+	case key.Matches(msg, keys.SwitchLocal):
 		// Switch local (Claude Code only) - sync to Claude Code settings without changing global active
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
-			cfg := m.configs[m.cursor]
-			// Clear previous messages
-			m.message = ""
-			m.errorMsg = ""
+			return m.startLocalSwitch(m.configs[m.cursor])
+		}
+		return m, nil
 
-			// Check if config supports multiple models
-			if len(cfg.Models) > 1 {
-				// Initialize model selection for local switch
-				m.initModelSelect(cfg)
-				m.modelCursor = 0
-				// Find current active model position
-				for i, model := range cfg.Models {
-					if model == cfg.Model {
-						m.modelCursor = i
-						break
-					}
-				}
-				m.switchType = SwitchTypeLocal
-				return m, nil
-			} else {
-				// Single model config, switch directly
-				return m, switchLocalConfig(m.configManager, &cfg)
-			}
+	case key.Matches(msg, keys.Previous):
+		// Switch back to the previously active config globally
+		m.message = ""
+		m.errorMsg = ""
+		return m, switchToPreviousConfig(m.configManager)
+
+	case key.Matches(msg, keys.EnvFilter):
+		// Cycle the environment filter through all environments and back to all
+		m.message = ""
+		m.errorMsg = ""
+		environments := listEnvironments(m.allConfigs)
+		if len(environments) == 0 {
+			m.errorMsg = "没有配置设置环境，可通过 apimgr edit <alias> --env <环境> 设置"
+			return m, nil
 		}
+		m.envFilter = nextEnvironment(environments, m.envFilter)
+		if m.envFilter == "" {
+			m.configs = m.allConfigs
+			m.message = "显示全部环境"
+		} else {
+			m.configs = filterByEnvironment(m.allConfigs, m.envFilter)
+			m.message = "环境筛选: " + m.envFilter
+		}
+		m.cursor = 0
+		m.scrollOffset = 0
 		return m, nil
 
-	case "S":
+	case key.Matches(msg, keys.QuickSwitch):
+		// Quick switch - jump to the Nth config and switch locally
+		index := quickSwitchIndex(keys.QuickSwitch, msg.String())
+		if index >= 0 && index < len(m.configs) {
+			m.cursor = index
+			m.adjustScrollOffset()
+			return m.startLocalSwitch(m.configs[index])
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.SwitchGlobal):
 		// Switch global active config - Requirements: 4.1, 4.2, 4.3, 4.4
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
@@ -441,33 +734,76 @@ func (m Model) handleMainViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "a":
+	case key.Matches(msg, keys.Add):
 		// Add new config - Requirements: 5.1
 		m.initAddForm()
 		return m, nil
 
-	case "e":
+	case key.Matches(msg, keys.Edit):
 		// Edit selected config - Requirements: 6.1
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
 			m.initEditForm()
 		}
 		return m, nil
 
-	case "d":
+	case key.Matches(msg, keys.Delete):
 		// Delete selected config - Requirements: 7.1
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
-			m.viewState = ViewDelete
-			m.message = ""
-			m.errorMsg = ""
+			m.startDelete()
 		}
 		return m, nil
 
-	case "?":
+	case key.Matches(msg, keys.Help):
 		m.viewState = ViewHelp
 		m.helpScrollOffset = 0 // Reset scroll when opening help
 		return m, nil
 
-	case "m":
+	case key.Matches(msg, keys.Preview):
+		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
+			m.previewReturn = ViewMain
+			m.viewState = ViewExportPreview
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.OpenEditor):
+		return m.openEditor()
+
+	case key.Matches(msg, keys.Messages):
+		m.viewState = ViewMessageLog
+		return m, nil
+
+	case key.Matches(msg, keys.Jobs):
+		m.viewState = ViewJobs
+		m.jobCursor = max(len(m.jobs)-1, 0)
+		return m, nil
+
+	case key.Matches(msg, keys.TestAll):
+		m.errorMsg = ""
+		return m.startBatchTest()
+
+	case key.Matches(msg, keys.Undo):
+		if m.lastDeleted == "" {
+			return m, nil
+		}
+		return m, restoreConfig(m.configManager, m.lastDeleted)
+
+	case key.Matches(msg, keys.Compare):
+		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
+			return m.markCompare(m.configs[m.cursor]), nil
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Cancel):
+		// Discard a rejected edit of the config file
+		if m.editPath != "" {
+			os.Remove(m.editPath)
+			m.editPath = ""
+			m.errorMsg = ""
+			m.message = "已放弃对配置文件的修改"
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Model):
 		// Switch model - Requirements: 12.1, 12.2, 12.4
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
@@ -481,28 +817,24 @@ func (m Model) handleMainViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "p":
+	case key.Matches(msg, keys.Ping):
 		// Ping test - Requirements: 8.1, 8.2, 8.3, 8.4
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
-			m.testing = true
-			m.viewState = ViewPingTesting
 			m.message = ""
 			m.errorMsg = ""
-			return m, pingConfig(&cfg)
+			return m.showPing(cfg)
 		}
 		return m, nil
 
-	case "t":
+	case key.Matches(msg, keys.Test):
 		// Compatibility test - Requirements: 9.1, 9.2, 9.3, 9.4
 		if len(m.configs) > 0 && m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
-			m.testing = true
-			m.viewState = ViewCompatTesting
 			m.message = ""
 			m.errorMsg = ""
 			m.compatResult = nil
-			return m, runCompatibilityTest(&cfg)
+			return m.showCompatTest(cfg)
 		}
 		return m, nil
 	}
@@ -510,17 +842,60 @@ func (m Model) handleMainViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// startLocalSwitch switches the given config locally, opening the model
+// selection first when the config supports multiple models
+func (m Model) startLocalSwitch(cfg models.APIConfig) (tea.Model, tea.Cmd) {
+	// Clear previous messages
+	m.message = ""
+	m.errorMsg = ""
+
+	// Check if config supports multiple models
+	if len(cfg.Models) > 1 {
+		// Initialize model selection for local switch
+		m.initModelSelect(cfg)
+		m.modelCursor = 0
+		// Find current active model position
+		for i, model := range cfg.Models {
+			if model == cfg.Model {
+				m.modelCursor = i
+				break
+			}
+		}
+		m.switchType = SwitchTypeLocal
+		return m, nil
+	}
+
+	// Single model config, switch directly
+	return m, switchLocalConfig(m.configManager, &cfg)
+}
+
+// quickSwitchIndex returns the config index selected by a quick switch key,
+// which is the position of the key in the binding
+func quickSwitchIndex(binding key.Binding, pressed string) int {
+	for i, k := range binding.Keys() {
+		if k == pressed {
+			return i
+		}
+	}
+	return -1
+}
+
 // handleDetailViewKeys handles keyboard input in detail view
 func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c":
+	keys := m.keyMap()
+	if m.refuseReadOnly(msg, mutatingKeys(keys)...) {
+		return m, nil
+	}
+	switch {
+	// Ctrl+C quits even when quit is remapped or disabled
+	case key.Matches(msg, keys.Quit), key.Matches(msg, keys.ForceQuit):
 		return m, tea.Quit
 
-	case "esc":
+	case key.Matches(msg, keys.Back):
 		m.viewState = ViewMain
 		return m, nil
 
-	case "s":
+	case key.Matches(msg, keys.SwitchLocal):
 		// Switch local (Claude Code only) from detail view
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			// Set cursor to selected for proper handling
@@ -551,7 +926,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "S":
+	case key.Matches(msg, keys.SwitchGlobal):
 		// Switch global active config from detail view - Requirements: 4.1, 4.2, 4.3, 4.4
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			// Set cursor to selected for proper handling
@@ -582,7 +957,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "e":
+	case key.Matches(msg, keys.Edit):
 		// Edit selected config from detail view - Requirements: 6.1
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			// Set cursor to selected for initEditForm to work correctly
@@ -591,23 +966,30 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "d":
+	case key.Matches(msg, keys.Delete):
 		// Delete selected config from detail view - Requirements: 7.1
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			// Set cursor to selected for delete to work correctly
 			m.cursor = m.selected
-			m.viewState = ViewDelete
-			m.message = ""
-			m.errorMsg = ""
+			m.startDelete()
 		}
 		return m, nil
 
-	case "?":
+	case key.Matches(msg, keys.Help):
 		m.viewState = ViewHelp
 		m.helpScrollOffset = 0 // Reset scroll when opening help
 		return m, nil
 
-	case "m":
+	case key.Matches(msg, keys.Preview):
+		if m.selected >= 0 && m.selected < len(m.configs) {
+			// Set cursor to selected for the preview to show it
+			m.cursor = m.selected
+			m.previewReturn = ViewDetail
+			m.viewState = ViewExportPreview
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Model):
 		// Switch model from detail view - Requirements: 12.1, 12.2, 12.4
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			cfg := m.configs[m.selected]
@@ -623,28 +1005,24 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "p":
+	case key.Matches(msg, keys.Ping):
 		// Ping test from detail view - Requirements: 8.1, 8.2, 8.3, 8.4
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			cfg := m.configs[m.selected]
-			m.testing = true
-			m.viewState = ViewPingTesting
 			m.message = ""
 			m.errorMsg = ""
-			return m, pingConfig(&cfg)
+			return m.showPing(cfg)
 		}
 		return m, nil
 
-	case "t":
+	case key.Matches(msg, keys.Test):
 		// Compatibility test from detail view - Requirements: 9.1, 9.2, 9.3, 9.4
 		if m.selected >= 0 && m.selected < len(m.configs) {
 			cfg := m.configs[m.selected]
-			m.testing = true
-			m.viewState = ViewCompatTesting
 			m.message = ""
 			m.errorMsg = ""
 			m.compatResult = nil
-			return m, runCompatibilityTest(&cfg)
+			return m.showCompatTest(cfg)
 		}
 		return m, nil
 	}
@@ -670,6 +1048,15 @@ func (m *Model) moveDown() {
 	}
 }
 
+// moveBy moves the cursor by n configs, stopping at either end of the list
+func (m *Model) moveBy(n int) {
+	if len(m.configs) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+n, 0), len(m.configs)-1)
+	m.adjustScrollOffset()
+}
+
 // moveToTop moves cursor to top
 // Requirements: 2.3, 11.3
 func (m *Model) moveToTop() {
@@ -738,6 +1125,14 @@ func (m *Model) adjustScrollOffset() {
 
 // View renders the UI
 func (m Model) View() string {
+	if m.ascii {
+		return asciiReplacer.Replace(m.renderView())
+	}
+	return m.renderView()
+}
+
+// renderView renders the current view state
+func (m Model) renderView() string {
 	switch m.viewState {
 	case ViewHelp:
 		return m.RenderHelpView()
@@ -757,25 +1152,119 @@ func (m Model) View() string {
 		return m.RenderCompatTestingView()
 	case ViewCompatResult:
 		return m.RenderCompatResultView()
+	case ViewExportPreview:
+		return m.RenderExportPreviewView()
+	case ViewCompare:
+		return m.RenderCompareView()
+	case ViewOnboarding:
+		return m.RenderOnboardingView()
+	case ViewMessageLog:
+		return m.RenderMessageLogView()
+	case ViewJobs:
+		return m.RenderJobsView()
 	default:
 		return m.RenderMainView()
 	}
 }
 
+// previewCursor keeps the detail pane of the two-pane layout on the config
+// under the cursor, loading its health history when the cursor moves to
+// another config
+func previewCursor(next tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m, ok := next.(Model)
+	if !ok || m.viewState != ViewMain || !m.twoPane() || m.cursor < 0 || m.cursor >= len(m.configs) {
+		return next, cmd
+	}
+	alias := m.configs[m.cursor].Alias
+	if m.selected == m.cursor && m.previewAlias == alias {
+		return m, cmd
+	}
+	m.selected = m.cursor
+	m.previewAlias = alias
+	m.healthHistory = nil
+	return m, tea.Batch(cmd, loadHistory(m.configManager, alias))
+}
+
+// openEditor suspends the TUI and opens a copy of the config file in the
+// user's editor, re-opening a rejected edit when there is one. The copy is
+// saved over the config file only when it is valid.
+func (m Model) openEditor() (tea.Model, tea.Cmd) {
+	if m.configManager == nil {
+		return m, nil
+	}
+	path := m.editPath
+	if path == "" {
+		var err error
+		if path, err = m.configManager.EditableCopy(); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+	}
+	m.message = ""
+	m.errorMsg = ""
+
+	cm := m.configManager
+	return m, tea.ExecProcess(utils.EditorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			return ConfigFileEditedMsg{Path: path, EditorErr: err}
+		}
+		return ConfigFileEditedMsg{Path: path, Err: cm.ApplyEditedCopy(path)}
+	})
+}
+
+// loadSyncStatus creates a command that computes where each config is in
+// effect: Claude Code's user and project settings and this terminal's local
+// session
+func loadSyncStatus(cm *config.Manager, configs []models.APIConfig) tea.Cmd {
+	return func() tea.Msg {
+		global := syncpkg.ReadSettingsEnv(config.ClaudeSettingsPath())
+		projectLocal := syncpkg.ReadSettingsEnv(filepath.Join(".claude", "settings.local.json"))
+		project := syncpkg.ReadSettingsEnv(filepath.Join(".claude", "settings.json"))
+
+		var sessionAlias, keylessAlias string
+		if cm != nil {
+			if marker, _ := session.ReadSessionMarker(cm.SessionDir(), strconv.Itoa(session.ShellPID())); marker != nil {
+				sessionAlias = marker.Alias
+			}
+			// Without a key in the user settings (helper or shell key mode),
+			// the global config is matched by its base URL
+			if global["ANTHROPIC_API_KEY"] == "" && global["ANTHROPIC_AUTH_TOKEN"] == "" {
+				keylessAlias, _ = cm.GetActiveName(config.ScopeGlobal)
+			}
+		}
+
+		status := make(map[string]SyncStatus, len(configs))
+		for i := range configs {
+			cfg := &configs[i]
+			status[cfg.Alias] = SyncStatus{
+				Global:  syncpkg.SettingsEnvMatches(cfg, global) || (cfg.Alias == keylessAlias && global["ANTHROPIC_BASE_URL"] == cfg.BaseURL),
+				Project: syncpkg.SettingsEnvMatches(cfg, projectLocal) || syncpkg.SettingsEnvMatches(cfg, project),
+				Session: cfg.Alias == sessionAlias,
+			}
+		}
+		return SyncStatusLoadedMsg{Status: status}
+	}
+}
+
 // loadConfigs creates a command to load configs
 func loadConfigs(cm *config.Manager) tea.Cmd {
 	return func() tea.Msg {
 		configs, err := cm.List()
-		if err != nil {
+		var resolveErr *config.ResolveError
+		if err != nil && !errors.As(err, &resolveErr) {
 			return errMsg(err.Error())
 		}
 
-		activeName, _ := cm.GetActiveName()
+		activeName, _ := cm.GetActiveName(config.ScopeShell)
 
-		return ConfigsLoadedMsg{
+		msg := ConfigsLoadedMsg{
 			Configs:     configs,
 			ActiveAlias: activeName,
 		}
+		if resolveErr != nil {
+			msg.Err = resolveErr.Error()
+		}
+		return msg
 	}
 }
 
@@ -785,81 +1274,179 @@ type errMsg string
 // switchLocalConfig creates a command to switch config locally (Claude Code only)
 func switchLocalConfig(cm *config.Manager, cfg *models.APIConfig) tea.Cmd {
 	return func() tea.Msg {
-		err := cm.SyncClaudeSettingsOnly(cfg)
-		if err != nil {
-			return ConfigSwitchedMsg{
-				Alias:   cfg.Alias,
-				IsLocal: true,
-				Err:     err,
+		return switchWithHooks(cm, cfg.Alias, true, func() error {
+			_ = cm.MarkUsed(cfg.Alias) // Usage is informational only
+			if len(cfg.APIKeys) > 0 {
+				// Sync the key just picked from the pool
+				picked, err := cm.Get(cfg.Alias)
+				if err != nil {
+					return err
+				}
+				return cm.SyncClaudeSettingsOnly(picked)
 			}
-		}
+			return cm.SyncClaudeSettingsOnly(cfg)
+		})
+	}
+}
 
-		return ConfigSwitchedMsg{
-			Alias:   cfg.Alias,
-			IsLocal: true,
-			Err:     nil,
-		}
+// switchWithHooks verifies the switch when defaults.verify_switch is set,
+// then runs the pre_switch hooks, the switch and the post_switch hooks. A
+// failing verification or pre_switch hook cancels the switch.
+func switchWithHooks(cm *config.Manager, alias string, isLocal bool, apply func() error) ConfigSwitchedMsg {
+	msg := ConfigSwitchedMsg{Alias: alias, IsLocal: isLocal}
+	if msg.Err = verifySwitch(cm, alias); msg.Err != nil {
+		return msg
+	}
+
+	event := hooks.Event{To: alias, Scope: hooks.ScopeGlobal}
+	if isLocal {
+		event.Scope = hooks.ScopeLocal
+	}
+	event.From, _ = cm.GetActiveName(config.ScopeShell)
+
+	switchHooks, err := cm.SwitchHooks(alias)
+	if err != nil {
+		msg.Err = err
+		return msg
+	}
+	event.Phase = hooks.PreSwitch
+	if err := hooks.Failed(hooks.Run(switchHooks.PreSwitch, event)); err != nil {
+		msg.Err = fmt.Errorf("%w，已取消切换", err)
+		return msg
+	}
+	if msg.Err = cm.PickKey(alias); msg.Err != nil {
+		return msg
+	}
+
+	if msg.Err = apply(); msg.Err != nil {
+		return msg
+	}
+	event.Phase = hooks.PostSwitch
+	msg.HookErr = hooks.Failed(hooks.Run(switchHooks.PostSwitch, event))
+	return msg
+}
+
+// verifySwitch tests alias with the key it is about to be switched to when
+// defaults.verify_switch is set, before anything changes, returning an
+// error cancelling the switch when it fails
+func verifySwitch(cm *config.Manager, alias string) error {
+	if verify, err := preflight.Enabled(cm); err != nil || !verify {
+		return err
+	}
+	target, err := preflight.Target(cm, alias, "")
+	if err != nil {
+		return err
+	}
+	result, err := preflight.Check(cm, target)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%s 未通过切换前验证，已取消切换: %s", alias, preflight.Failure(result))
 	}
+	return nil
 }
 
 // switchGlobalConfig creates a command to switch the global active configuration
 // Requirements: 4.1, 4.2, 4.3, 4.4
 func switchGlobalConfig(cm *config.Manager, alias string) tea.Cmd {
 	return func() tea.Msg {
-		err := cm.SetActive(alias)
-		if err != nil {
-			return ConfigSwitchedMsg{
-				Alias:   alias,
-				IsLocal: false,
-				Err:     err,
+		return switchWithHooks(cm, alias, false, func() error {
+			if err := cm.SetActive(alias); err != nil {
+				return err
 			}
+			// Generate active script after successful switch, a failure
+			// doesn't fail the switch
+			_ = cm.GenerateActiveScript()
+			return nil
+		})
+	}
+}
+
+// listEnvironments returns the distinct environments of the configs, sorted
+func listEnvironments(configs []models.APIConfig) []string {
+	seen := make(map[string]bool)
+	var environments []string
+	for _, cfg := range configs {
+		if cfg.Environment != "" && !seen[cfg.Environment] {
+			seen[cfg.Environment] = true
+			environments = append(environments, cfg.Environment)
 		}
+	}
+	sort.Strings(environments)
+	return environments
+}
 
-		// Generate active script after successful switch
-		if genErr := cm.GenerateActiveScript(); genErr != nil {
-			// Log the error but don't fail the switch
-			return ConfigSwitchedMsg{
-				Alias:   alias,
-				IsLocal: false,
-				Err:     nil,
-			}
+// nextEnvironment returns the filter following current, cycling back to
+// "" (all environments) after the last one
+func nextEnvironment(environments []string, current string) string {
+	if current == "" {
+		return environments[0]
+	}
+	for i, env := range environments {
+		if env == current && i+1 < len(environments) {
+			return environments[i+1]
+		}
+	}
+	return ""
+}
+
+// filterByEnvironment returns the configs belonging to an environment
+func filterByEnvironment(configs []models.APIConfig, environment string) []models.APIConfig {
+	filtered := make([]models.APIConfig, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Environment == environment {
+			filtered = append(filtered, cfg)
 		}
+	}
+	return filtered
+}
 
-		return ConfigSwitchedMsg{
-			Alias:   alias,
-			IsLocal: false,
-			Err:     nil,
+// switchToPreviousConfig switches globally to the previously active config
+func switchToPreviousConfig(cm *config.Manager) tea.Cmd {
+	return func() tea.Msg {
+		alias, err := cm.GetPrevious()
+		if err != nil {
+			return ConfigSwitchedMsg{IsLocal: false, Err: err}
 		}
+		return switchGlobalConfig(cm, alias)()
 	}
 }
 
 // handleFormViewKeys handles keyboard input in form view (add/edit)
 // Requirements: 5.2, 5.5, 6.2, 6.5
 func (m Model) handleFormViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	keys := m.keyMap()
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
 		return m, tea.Quit
 
-	case "esc":
+	case key.Matches(msg, keys.Cancel):
 		// Cancel form and return to previous view
 		m.viewState = ViewMain
+		if m.onboarding.active {
+			m.viewState = ViewOnboarding
+		}
 		m.errorMsg = ""
 		m.formInputs = []textinput.Model{}
 		m.formFocus = 0
 		return m, nil
 
-	case "tab", "down":
+	case key.Matches(msg, keys.NextField):
 		// Move to next field
+		m.detectFormDefaults()
 		m.formFocus = NextFormField(m.formInputs, m.formFocus)
 		return m, nil
 
-	case "shift+tab", "up":
+	case key.Matches(msg, keys.PrevField):
 		// Move to previous field
+		m.detectFormDefaults()
 		m.formFocus = PrevFormField(m.formInputs, m.formFocus)
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, keys.Confirm):
 		// Submit form
+		m.detectFormDefaults()
 		formData := GetFormData(m.formInputs)
 		if err := formData.Validate(); err != nil {
 			m.errorMsg = err.Error()
@@ -884,6 +1471,14 @@ func (m Model) handleFormViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// detectFormDefaults fills the provider and models of a new config from
+// its base URL when the URL field is left
+func (m *Model) detectFormDefaults() {
+	if m.viewState == ViewAdd && m.formFocus == FormFieldBaseURL {
+		DetectFormDefaults(m.formInputs)
+	}
+}
+
 // initAddForm initializes the form for adding a new config
 // Requirements: 5.1, 5.2
 func (m *Model) initAddForm() {
@@ -912,6 +1507,7 @@ func (m *Model) initEditForm() {
 		APIKey:    cfg.APIKey,
 		AuthToken: cfg.AuthToken,
 		BaseURL:   cfg.BaseURL,
+		Provider:  cfg.Provider,
 		Model:     cfg.Model,
 		Models:    strings.Join(cfg.Models, ", "),
 	}
@@ -927,6 +1523,7 @@ func (m *Model) submitAddForm(data FormData) tea.Cmd {
 			APIKey:    strings.TrimSpace(data.APIKey),
 			AuthToken: strings.TrimSpace(data.AuthToken),
 			BaseURL:   strings.TrimSpace(data.BaseURL),
+			Provider:  strings.TrimSpace(data.Provider),
 			Model:     strings.TrimSpace(data.Model),
 			Models:    data.ParseModels(),
 		}
@@ -954,6 +1551,7 @@ func (m *Model) submitEditForm(data FormData) tea.Cmd {
 			"api_key":    strings.TrimSpace(data.APIKey),
 			"auth_token": strings.TrimSpace(data.AuthToken),
 			"base_url":   strings.TrimSpace(data.BaseURL),
+			"provider":   strings.TrimSpace(data.Provider),
 			"model":      strings.TrimSpace(data.Model),
 		}
 
@@ -972,12 +1570,55 @@ func (m Model) RenderFormViewFull() string {
 	if m.viewState == ViewEdit {
 		title = "编辑配置"
 	}
-	return RenderForm(m.formInputs, m.formFocus, title, m.errorMsg)
+	return RenderForm(m.formInputs, m.formFocus, title, m.errorMsg, m.keyMap())
+}
+
+// startDelete opens the delete confirmation of the config under the cursor.
+// A config that is active or has live local sessions must have its alias
+// typed.
+func (m *Model) startDelete() {
+	m.viewState = ViewDelete
+	m.message = ""
+	m.errorMsg = ""
+	m.deleteUse = config.ConfigUse{}
+	m.deleteSwitchTo = ""
+	if m.configManager != nil {
+		if use, err := m.configManager.UseOf(m.configs[m.cursor].Alias); err == nil {
+			m.deleteUse = use
+		}
+	}
+	if m.deleteUse.InUse() {
+		m.deleteInput = textinput.New()
+		m.deleteInput.Placeholder = m.configs[m.cursor].Alias
+		m.deleteInput.CharLimit = 64
+		m.deleteInput.Width = 40
+		m.deleteInput.Prompt = ""
+		m.deleteInput.Focus()
+	}
+}
+
+// deleteSwitchTargets returns the configs the active one can be switched to
+// before it is deleted
+func (m Model) deleteSwitchTargets() []string {
+	if !m.deleteUse.Active || m.cursor < 0 || m.cursor >= len(m.configs) {
+		return nil
+	}
+	var targets []string
+	for _, cfg := range m.configs {
+		if cfg.Alias != m.configs[m.cursor].Alias {
+			targets = append(targets, cfg.Alias)
+		}
+	}
+	return targets
 }
 
 // handleDeleteViewKeys handles keyboard input in delete confirmation view
 // Requirements: 7.1, 7.2, 7.4
 func (m Model) handleDeleteViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.deleteUse.InUse() {
+		return m.handleGuardedDeleteKeys(msg)
+	}
+
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
@@ -986,7 +1627,7 @@ func (m Model) handleDeleteViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Confirm delete - Requirements: 7.3
 		if m.cursor >= 0 && m.cursor < len(m.configs) {
 			alias := m.configs[m.cursor].Alias
-			return m, deleteConfig(m.configManager, alias)
+			return m, deleteConfig(m.configManager, alias, "")
 		}
 		m.viewState = ViewMain
 		return m, nil
@@ -1002,49 +1643,185 @@ func (m Model) handleDeleteViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// deleteConfig creates a command to delete a configuration
+// handleGuardedDeleteKeys handles the delete confirmation of a config in
+// use: the alias is typed and confirmed with enter, tab picks the config to
+// switch to
+func (m Model) handleGuardedDeleteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.viewState = ViewMain
+		m.message = ""
+		m.errorMsg = ""
+		return m, nil
+
+	case "enter":
+		if m.cursor < 0 || m.cursor >= len(m.configs) {
+			m.viewState = ViewMain
+			return m, nil
+		}
+		alias := m.configs[m.cursor].Alias
+		if strings.TrimSpace(m.deleteInput.Value()) != alias {
+			m.errorMsg = "请输入别名 " + alias + " 以确认删除"
+			return m, nil
+		}
+		m.errorMsg = ""
+		return m, deleteConfig(m.configManager, alias, m.deleteSwitchTo)
+
+	case "tab":
+		targets := m.deleteSwitchTargets()
+		if len(targets) == 0 {
+			return m, nil
+		}
+		// Cycle through no switch and each other config
+		next := 0
+		if i := slices.Index(targets, m.deleteSwitchTo); i >= 0 {
+			next = i + 2
+		} else if m.deleteSwitchTo == "" {
+			next = 1
+		}
+		m.deleteSwitchTo = ""
+		if next > 0 && next <= len(targets) {
+			m.deleteSwitchTo = targets[next-1]
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.deleteInput, cmd = m.deleteInput.Update(msg)
+	return m, cmd
+}
+
+// deleteConfig creates a command to delete a configuration, switching to
+// switchTo first when it is set
 // Requirements: 7.3, 7.5
-func deleteConfig(cm *config.Manager, alias string) tea.Cmd {
+func deleteConfig(cm *config.Manager, alias, switchTo string) tea.Cmd {
 	return func() tea.Msg {
+		if switchTo != "" {
+			if err := cm.SetActive(switchTo); err != nil {
+				return ConfigDeletedMsg{Alias: alias, Err: err}
+			}
+			// A failure to generate the active script doesn't fail the
+			// deletion
+			_ = cm.GenerateActiveScript()
+		}
 		err := cm.Remove(alias)
 		return ConfigDeletedMsg{
-			Alias: alias,
-			Err:   err,
+			Alias:      alias,
+			SwitchedTo: switchTo,
+			Err:        err,
+		}
+	}
+}
+
+// restoreConfig creates a command to restore a deleted configuration from
+// the trash
+func restoreConfig(cm *config.Manager, alias string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := cm.Restore(alias, "")
+		return ConfigRestoredMsg{Alias: alias, Err: err}
+	}
+}
+
+// handleExportPreviewKeys handles keyboard input in the export preview
+func (m Model) handleExportPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap().Back), key.Matches(msg, m.keyMap().Preview), msg.String() == "q":
+		m.viewState = m.previewReturn
+	}
+	return m, nil
+}
+
+// markCompare handles c on a config: the first press marks it as the base of
+// a comparison, pressing it again on the base cancels, and pressing it on
+// another config opens the compare view
+func (m Model) markCompare(cfg models.APIConfig) Model {
+	m.message = ""
+	m.errorMsg = ""
+	if m.compareBase == "" {
+		m.compareBase = cfg.Alias
+		m.message = fmt.Sprintf("已选择 %s 作为对比基准，移到另一个配置后按 %s", cfg.Alias, m.keyMap().Compare.Help().Key)
+		return m
+	}
+	if m.compareBase == cfg.Alias {
+		m.compareBase = ""
+		m.message = "已取消对比"
+		return m
+	}
+
+	base, found := models.APIConfig{}, false
+	for _, c := range m.allConfigs {
+		if c.Alias == m.compareBase {
+			base, found = c, true
+			break
 		}
 	}
+	if !found {
+		m.errorMsg = fmt.Sprintf("对比基准 %s 已不存在", m.compareBase)
+		m.compareBase = ""
+		return m
+	}
+	m.compareBase = ""
+	m.compareConfigs = [2]models.APIConfig{base, cfg}
+	m.compareResults = [2]*compatibility.TestResult{}
+	m.viewState = ViewCompare
+	return m
+}
+
+// handleCompareViewKeys handles keyboard input in the compare view
+func (m Model) handleCompareViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case m.compareTesting:
+		// Wait for the running tests
+		return m, nil
+	case key.Matches(msg, m.keyMap().Test):
+		m.compareTesting = true
+		m.compareResults = [2]*compatibility.TestResult{}
+		return m, m.compareTestCmd(m.compareConfigs)
+	case key.Matches(msg, m.keyMap().Back), msg.String() == "q":
+		m.viewState = ViewMain
+	}
+	return m, nil
 }
 
 // handleHelpViewKeys handles keyboard input in help view
 func (m Model) handleHelpViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	keys := m.keyMap()
+	switch {
+	case msg.String() == "ctrl+c":
 		return m, tea.Quit
 
-	case "esc", "q":
+	case key.Matches(msg, keys.Back), msg.String() == "q":
 		// Close help and return to main view
 		m.viewState = ViewMain
 		m.helpScrollOffset = 0
 		return m, nil
 
-	case "j", "down":
+	case key.Matches(msg, keys.Down):
 		// Scroll down
 		m.helpScrollOffset++
 		m.adjustHelpScrollOffset()
 		return m, nil
 
-	case "k", "up":
+	case key.Matches(msg, keys.Up):
 		// Scroll up
 		if m.helpScrollOffset > 0 {
 			m.helpScrollOffset--
 		}
 		return m, nil
 
-	case "g":
+	case key.Matches(msg, keys.Top):
 		// Jump to top
 		m.helpScrollOffset = 0
 		return m, nil
 
-	case "G":
+	case key.Matches(msg, keys.Bottom):
 		// Jump to bottom
 		m.helpScrollOffset = m.getHelpContentHeight() - m.getVisibleHelpHeight()
 		m.adjustHelpScrollOffset()
@@ -1059,12 +1836,13 @@ func (m *Model) getHelpContentHeight() int {
 	// Count all help content lines:
 	// Title (1) + Separator (1) + Empty (1)
 	// Navigation section: header (1) + 5 items + empty (1) = 7
-	// Config management section: header (1) + 5 items + empty (1) = 7
+	// Config management section: header (1) + 10 items + empty (1) = 12
 	// Model management section: header (1) + 1 item + empty (1) = 3
 	// Testing section: header (1) + 2 items + empty (1) = 4
+	// List markers section: header (1) + 2 items + empty (1) = 4
 	// General section: header (1) + 4 items + empty (1) = 6
 	// Footer separator (1) + help text (1) = 2
-	return 3 + 7 + 7 + 3 + 4 + 6 + 2
+	return 3 + 7 + 12 + 3 + 4 + 4 + 6 + 2
 }
 
 // getVisibleHelpHeight returns the number of lines available for help content
@@ -1116,19 +1894,9 @@ func (m *Model) initModelSelect(cfg models.APIConfig) {
 // handleModelSelectViewKeys handles keyboard input in model selection view
 // Requirements: 12.1, 12.2, 12.3
 func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
-		return m, tea.Quit
-
-	case "esc":
-		// Cancel model selection and return to main view
-		m.viewState = ViewMain
-		m.modelList = nil
-		m.modelCursor = 0
-		m.modelScrollOffset = 0
-		return m, nil
-
-	case " ":
+	keys := m.keyMap()
+	switch {
+	case key.Matches(msg, keys.PageDown):
 		// Scroll down by page in model selection view
 		if m.cursor >= 0 && m.cursor < len(m.configs) && m.modelCursor >= 0 && m.modelCursor < len(m.modelList) {
 			visibleHeight := m.getVisibleModelListHeight()
@@ -1140,7 +1908,7 @@ func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "shift+ ":
+	case key.Matches(msg, keys.PageUp):
 		// Scroll up by page in model selection view
 		if m.cursor >= 0 && m.cursor < len(m.configs) && m.modelCursor >= 0 && m.modelCursor < len(m.modelList) {
 			visibleHeight := m.getVisibleModelListHeight()
@@ -1152,7 +1920,18 @@ func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "j", "down":
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.Cancel), key.Matches(msg, keys.Back):
+		// Cancel model selection and return to main view
+		m.viewState = ViewMain
+		m.modelList = nil
+		m.modelCursor = 0
+		m.modelScrollOffset = 0
+		return m, nil
+
+	case key.Matches(msg, keys.Down):
 		// Move cursor down in model list
 		if len(m.modelList) > 0 && m.modelCursor < len(m.modelList)-1 {
 			m.modelCursor++
@@ -1160,7 +1939,7 @@ func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "k", "up":
+	case key.Matches(msg, keys.Up):
 		// Move cursor up in model list
 		if m.modelCursor > 0 {
 			m.modelCursor--
@@ -1168,13 +1947,13 @@ func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "g":
+	case key.Matches(msg, keys.Top):
 		// Jump to top of model list
 		m.modelCursor = 0
 		m.modelScrollOffset = 0
 		return m, nil
 
-	case "G":
+	case key.Matches(msg, keys.Bottom):
 		// Jump to bottom of model list
 		if len(m.modelList) > 0 {
 			m.modelCursor = len(m.modelList) - 1
@@ -1182,7 +1961,7 @@ func (m Model) handleModelSelectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, keys.Confirm):
 		// Confirm model selection - Requirements: 12.3
 		if m.cursor >= 0 && m.cursor < len(m.configs) && m.modelCursor >= 0 && m.modelCursor < len(m.modelList) {
 			alias := m.configs[m.cursor].Alias
@@ -1278,7 +2057,7 @@ func switchModel(cm *config.Manager, alias string, model string) tea.Cmd {
 		}
 
 		// Regenerate active script if this is the active config
-		activeName, _ := cm.GetActiveName()
+		activeName, _ := cm.GetActiveName(config.ScopeShell)
 		if activeName == alias {
 			if genErr := cm.GenerateActiveScript(); genErr != nil {
 				// Log the error but don't fail the switch
@@ -1367,21 +2146,200 @@ func switchModelAndSync(cm *config.Manager, alias string, model string, isLocal
 	}
 }
 
+// startPing shows the ping progress view and starts pinging cfg
+func (m Model) startPing(cfg models.APIConfig) (Model, tea.Cmd) {
+	ctx := m.beginTest(ViewPingTesting)
+	m.testAlias = cfg.Alias
+	return m, tea.Batch(m.spinner.Tick, m.pingCmd(ctx, &cfg))
+}
+
+// startCompatTest shows the compatibility progress view and starts testing cfg
+func (m Model) startCompatTest(cfg models.APIConfig) (Model, tea.Cmd) {
+	ctx := m.beginTest(ViewCompatTesting)
+	m.testAlias = cfg.Alias
+	return m, tea.Batch(m.spinner.Tick, m.compatTestCmd(ctx, &cfg))
+}
+
+// beginTest switches to the progress view of a new test run and returns
+// the context that cancels it
+func (m *Model) beginTest(view ViewState) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.testing = true
+	m.viewState = view
+	m.cancelTest = cancel
+	m.testStarted = time.Now()
+	m.testAlias = ""
+	m.resultCachedAt = time.Time{}
+	m.testRun++
+	m.spinner = newSpinner(m.ascii)
+	return ctx
+}
+
+// finishTest ends the running test once its result arrived
+func (m *Model) finishTest() {
+	m.testing = false
+	if m.cancelTest != nil {
+		m.cancelTest()
+		m.cancelTest = nil
+	}
+}
+
+// cancelRunningTest aborts the running test, whose result is then dropped
+func (m *Model) cancelRunningTest() {
+	m.finishTest()
+	m.testRun++
+}
+
+// handleTestingViewKeys handles keyboard input while a test runs: Esc
+// cancels it, Ctrl+C quits
+func (m Model) handleTestingViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keyMap()
+	switch {
+	case key.Matches(msg, keys.ForceQuit):
+		m.cancelRunningTest()
+		return m, tea.Quit
+	case key.Matches(msg, keys.Cancel):
+		m.cancelRunningTest()
+		m.viewState = ViewMain
+		m.message = "测试已取消"
+		return m, nil
+	case key.Matches(msg, keys.Background):
+		return m.backgroundTest(), nil
+	}
+	return m, nil
+}
+
+// newSpinner creates the spinner of the progress views, drawn with ASCII
+// characters in ASCII mode
+func newSpinner(ascii bool) spinner.Model {
+	if ascii {
+		return spinner.New(spinner.WithSpinner(spinner.Line), spinner.WithStyle(messageStyle))
+	}
+	return spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(messageStyle))
+}
+
+// pingCmd creates a command to ping cfg, or to fake it in demo mode. The
+// result is tagged with the current test run and cached unless canceled.
+func (m Model) pingCmd(ctx context.Context, cfg *models.APIConfig) tea.Cmd {
+	run := m.testRun
+	return func() tea.Msg {
+		var msg PingResultMsg
+		if m.demo {
+			msg = demoPing(m.configManager, cfg)().(PingResultMsg)
+		} else {
+			msg = pingConfig(ctx, m.configManager, cfg)().(PingResultMsg)
+		}
+		if ctx.Err() == nil {
+			summary := ""
+			if msg.Err == nil && msg.Success {
+				summary = fmt.Sprintf("%dms", msg.Duration.Milliseconds())
+			}
+			cacheResult(m.configManager, cfg, history.KindPing, msg.Err == nil && msg.Success, summary, newPingTestResult(msg))
+		}
+		msg.Run = run
+		return msg
+	}
+}
+
+// compatTestCmd creates a command to run the full compatibility test on
+// cfg, or to fake it in demo mode. The result is tagged with the current
+// test run and cached unless canceled.
+func (m Model) compatTestCmd(ctx context.Context, cfg *models.APIConfig) tea.Cmd {
+	run := m.testRun
+	return func() tea.Msg {
+		var msg CompatResultMsg
+		if m.demo {
+			msg = demoCompatibilityTest(m.configManager, cfg)().(CompatResultMsg)
+		} else {
+			msg = runCompatibilityTest(ctx, m.configManager, cfg)().(CompatResultMsg)
+		}
+		if result := newCompatTestResult(msg); result != nil && ctx.Err() == nil {
+			summary := ""
+			if result.Success {
+				summary = fmt.Sprintf("%dms", msg.Result.ResponseTime.Milliseconds())
+			}
+			cacheResult(m.configManager, cfg, history.KindTest, result.Success, summary, result)
+		}
+		msg.Run = run
+		return msg
+	}
+}
+
+// compareTestCmd creates a command to test both compared configs, or to
+// fake it in demo mode
+func (m Model) compareTestCmd(cfgs [2]models.APIConfig) tea.Cmd {
+	if m.demo {
+		return demoCompareTest(m.configManager, cfgs)
+	}
+	return runCompareTest(m.configManager, cfgs)
+}
+
 // pingConfig creates a command to perform a ping test on a configuration
 // Requirements: 8.1, 8.2, 8.3, 8.4
-func pingConfig(cfg *models.APIConfig) tea.Cmd {
+func pingConfig(ctx context.Context, cm *config.Manager, cfg *models.APIConfig) tea.Cmd {
 	return func() tea.Msg {
-		return performPingTest(cfg)
+		// Use the configured sample count when set
+		samples := probe.DefaultSamples
+		if cm != nil {
+			if settings, err := cm.GetTestSettings(); err == nil && settings != nil && settings.PingCount > 0 {
+				samples = settings.PingCount
+			}
+		}
+		result := performPingTest(ctx, cfg, samples)
+		// A canceled ping says nothing about the config's health
+		if cm != nil && ctx.Err() == nil {
+			entry := history.Entry{Kind: history.KindPing, Success: result.Success, LatencyMs: result.Duration.Milliseconds()}
+			if result.Err != nil {
+				entry.Detail = result.Err.Error()
+			}
+			recordHistory(cm, cfg.Alias, entry)
+		}
+		return result
 	}
 }
 
-// performPingTest performs the actual ping test
+// loadHistory creates a command to load a config's health history
+func loadHistory(cm *config.Manager, alias string) tea.Cmd {
+	return func() tea.Msg {
+		if cm == nil {
+			return HistoryLoadedMsg{Alias: alias}
+		}
+		entries, _ := history.Load(cm.StateDir(), alias)
+		return HistoryLoadedMsg{Alias: alias, Entries: entries}
+	}
+}
+
+// recordHistory stores a test result in the health history, ignoring failures
+func recordHistory(cm *config.Manager, alias string, entry history.Entry) {
+	entry.Time = time.Now()
+	_ = history.Record(cm.StateDir(), alias, entry)
+}
+
+// performPingTest performs the actual ping test, sending the given number of
+// samples and summarizing their latency. Canceling ctx stops the samples.
 // Requirements: 8.1, 8.2, 8.3, 8.4
-func performPingTest(cfg *models.APIConfig) PingResultMsg {
+func performPingTest(ctx context.Context, cfg *models.APIConfig, samples int) PingResultMsg {
 	baseURL := cfg.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com"
 	}
+	if err := utils.CheckPlaceholders(baseURL); err != nil {
+		return PingResultMsg{
+			Success:  false,
+			Duration: 0,
+			Err:      err,
+		}
+	}
+
+	// Honor the configuration's TLS options
+	tlsConfig, err := utils.NewTLSConfig(cfg)
+	if err != nil {
+		return PingResultMsg{
+			Success:  false,
+			Duration: 0,
+			Err:      fmt.Errorf("TLS 配置无效: %v", err),
+		}
+	}
 
 	// Create HTTP client with timeout
 	client := &http.Client{
@@ -1391,11 +2349,12 @@ func performPingTest(cfg *models.APIConfig) PingResultMsg {
 			IdleConnTimeout:       30 * time.Second,
 			TLSHandshakeTimeout:   5 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig:       tlsConfig,
 		},
 	}
 
 	// Create request
-	req, err := http.NewRequest("HEAD", baseURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", baseURL, nil)
 	if err != nil {
 		return PingResultMsg{
 			Success:  false,
@@ -1412,17 +2371,39 @@ func performPingTest(cfg *models.APIConfig) PingResultMsg {
 		req.Header.Set("API-Key", cfg.APIKey)
 	}
 
-	// Perform request
-	start := time.Now()
-	resp, err := client.Do(req)
-	duration := time.Since(start)
+	// Perform the samples, timing each phase
+	var (
+		durations  []time.Duration
+		succeeded  []probe.Timings // Phases of the samples that got a response
+		failures   int
+		lastErr    error
+		timings    probe.Timings
+		statusCode int
+	)
+	for i := 0; i < samples && ctx.Err() == nil; i++ {
+		resp, sampleTimings, err := probe.Do(client, req)
+		timings = sampleTimings
+		if err != nil {
+			failures++
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		durations = append(durations, sampleTimings.Total)
+		succeeded = append(succeeded, sampleTimings)
+		statusCode = resp.StatusCode
+	}
+	stats := probe.Summarize(durations, failures)
+	if err := ctx.Err(); err != nil {
+		return PingResultMsg{Success: false, Err: err}
+	}
 
-	if err != nil {
+	if len(durations) == 0 {
 		// Categorize errors
 		var errMsg string
-		errStr := err.Error()
+		errStr := lastErr.Error()
 
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		if netErr, ok := lastErr.(net.Error); ok && netErr.Timeout() {
 			errMsg = "请求超时 (超过10秒)"
 		} else if strings.Contains(errStr, "connection refused") {
 			errMsg = "连接被拒绝 (服务器未监听此端口)"
@@ -1433,23 +2414,27 @@ func performPingTest(cfg *models.APIConfig) PingResultMsg {
 		} else if strings.Contains(errStr, "no such host") || strings.Contains(errStr, "NXDOMAIN") {
 			errMsg = "DNS 解析失败 (域名不存在)"
 		} else {
-			errMsg = fmt.Sprintf("连接失败: %v", err)
+			errMsg = fmt.Sprintf("连接失败: %v", lastErr)
 		}
 
 		return PingResultMsg{
 			Success:  false,
-			Duration: duration,
+			Duration: timings.Total,
+			Timings:  timings,
+			Stats:    stats,
 			Err:      fmt.Errorf("%s", errMsg),
 		}
 	}
-	defer resp.Body.Close()
 
 	// Check response status
-	isSuccess := resp.StatusCode >= 200 && resp.StatusCode < 500
+	isSuccess := statusCode >= 200 && statusCode < 500
 
+	// Phases are averaged like latency, over the samples that got a response
 	return PingResultMsg{
 		Success:  isSuccess,
-		Duration: duration,
+		Duration: stats.Avg,
+		Timings:  probe.MeanTimings(succeeded),
+		Stats:    stats,
 		Err:      nil,
 	}
 }
@@ -1457,25 +2442,24 @@ func performPingTest(cfg *models.APIConfig) PingResultMsg {
 // handlePingResultViewKeys handles keyboard input in ping result view
 // Requirements: 8.3, 8.4
 func (m Model) handlePingResultViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	keys := m.keyMap()
+	switch {
+	case msg.String() == "ctrl+c":
 		return m, tea.Quit
 
-	case "esc", "enter", "q":
+	case key.Matches(msg, keys.Back), key.Matches(msg, keys.Confirm), msg.String() == "q":
 		// Return to main view
 		m.viewState = ViewMain
 		m.testResult = nil
 		m.testing = false
 		return m, nil
 
-	case "r":
+	case key.Matches(msg, keys.Retry):
 		// Retry ping test
 		if m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
-			m.testing = true
-			m.viewState = ViewPingTesting
 			m.testResult = nil
-			return m, pingConfig(&cfg)
+			return m.startPing(cfg)
 		}
 		return m, nil
 	}
@@ -1485,9 +2469,31 @@ func (m Model) handlePingResultViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // runCompatibilityTest creates a command to perform a compatibility test on a configuration
 // Requirements: 9.1, 9.2, 9.3, 9.4
-func runCompatibilityTest(cfg *models.APIConfig) tea.Cmd {
+func runCompatibilityTest(ctx context.Context, cm *config.Manager, cfg *models.APIConfig) tea.Cmd {
 	return func() tea.Msg {
-		tester, err := compatibility.NewTester(cfg)
+		// Apply the timeout and retry defaults from the config file
+		opts := []compatibility.TesterOption{compatibility.WithContext(ctx)}
+		if cm != nil {
+			settings, err := cm.GetTestSettings()
+			if err == nil {
+				var settingsOpts []compatibility.TesterOption
+				settingsOpts, err = compatibility.OptionsFromSettings(settings)
+				opts = append(opts, settingsOpts...)
+			}
+			if err != nil {
+				return CompatResultMsg{
+					Result: nil,
+					Err:    fmt.Errorf("读取测试设置失败: %v", err),
+				}
+			}
+			if len(cfg.APIKeys) > 0 {
+				opts = append(opts, compatibility.WithKeyRotation(func(key string, status int) (string, bool) {
+					return cm.NextKey(cfg.Alias, key, status)
+				}))
+			}
+		}
+
+		tester, err := compatibility.NewTester(cfg, opts...)
 		if err != nil {
 			return CompatResultMsg{
 				Result: nil,
@@ -1497,6 +2503,14 @@ func runCompatibilityTest(cfg *models.APIConfig) tea.Cmd {
 
 		// Run full test including streaming
 		result, err := tester.RunFullTest(true)
+		if cm != nil && result != nil && ctx.Err() == nil {
+			recordHistory(cm, cfg.Alias, history.Entry{
+				Kind:      history.KindTest,
+				Success:   result.Success,
+				LatencyMs: result.ResponseTime.Milliseconds(),
+				Detail:    result.CompatibilityLevel,
+			})
+		}
 		if err != nil {
 			return CompatResultMsg{
 				Result: result,
@@ -1514,28 +2528,92 @@ func runCompatibilityTest(cfg *models.APIConfig) tea.Cmd {
 // handleCompatResultViewKeys handles keyboard input in compatibility result view
 // Requirements: 9.3, 9.4
 func (m Model) handleCompatResultViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	keys := m.keyMap()
+	switch {
+	case msg.String() == "ctrl+c":
 		return m, tea.Quit
 
-	case "esc", "enter", "q":
+	case key.Matches(msg, keys.Back), key.Matches(msg, keys.Confirm), msg.String() == "q":
 		// Return to main view
 		m.viewState = ViewMain
 		m.compatResult = nil
 		m.testing = false
 		return m, nil
 
-	case "r":
+	case key.Matches(msg, keys.Retry):
 		// Retry compatibility test
 		if m.cursor >= 0 && m.cursor < len(m.configs) {
 			cfg := m.configs[m.cursor]
-			m.testing = true
-			m.viewState = ViewCompatTesting
 			m.compatResult = nil
-			return m, runCompatibilityTest(&cfg)
+			return m.startCompatTest(cfg)
 		}
 		return m, nil
 	}
 
 	return m, nil
 }
+
+// runCompareTest creates a command running the basic compatibility test on
+// two configs at once
+func runCompareTest(cm *config.Manager, cfgs [2]models.APIConfig) tea.Cmd {
+	return func() tea.Msg {
+		var opts []compatibility.TesterOption
+		if cm != nil {
+			settings, err := cm.GetTestSettings()
+			if err == nil {
+				opts, err = compatibility.OptionsFromSettings(settings)
+			}
+			if err != nil {
+				failed := &compatibility.TestResult{
+					CompatibilityLevel: compatibility.CompatibilityNone,
+					Error:              fmt.Sprintf("读取测试设置失败: %v", err),
+				}
+				return CompareResultMsg{Results: [2]*compatibility.TestResult{failed, failed}}
+			}
+		}
+
+		var msg CompareResultMsg
+		var wg sync.WaitGroup
+		for i := range cfgs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				msg.Results[i] = runBasicTest(&cfgs[i], opts)
+			}()
+		}
+		wg.Wait()
+
+		// Recorded once both finished, not from the test goroutines
+		if cm != nil {
+			for i, result := range msg.Results {
+				recordHistory(cm, cfgs[i].Alias, history.Entry{
+					Kind:      history.KindTest,
+					Success:   result.Success,
+					LatencyMs: result.ResponseTime.Milliseconds(),
+					Detail:    result.CompatibilityLevel,
+				})
+			}
+		}
+		return msg
+	}
+}
+
+// runBasicTest runs the basic compatibility test on a config, reporting a
+// tester error as a failed result
+func runBasicTest(cfg *models.APIConfig, opts []compatibility.TesterOption) *compatibility.TestResult {
+	tester, err := compatibility.NewTester(cfg, opts...)
+	if err != nil {
+		return &compatibility.TestResult{
+			CompatibilityLevel: compatibility.CompatibilityNone,
+			Error:              fmt.Sprintf("创建测试器失败: %v", err),
+		}
+	}
+	result, err := tester.TestBasic()
+	if err != nil {
+		return &compatibility.TestResult{
+			CompatibilityLevel: compatibility.CompatibilityNone,
+			Error:              fmt.Sprintf("测试执行失败: %v", err),
+		}
+	}
+	return result
+}
@@ -2,11 +2,19 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"apimgr/config/history"
 	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/modelinfo"
+	"apimgr/internal/probe"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // TestInitEditForm tests the initEditForm method
@@ -733,6 +741,34 @@ func TestRenderModelSelectView(t *testing.T) {
 }
 
 
+// TestModelInfoRendering tests that known models show their metadata
+func TestModelInfoRendering(t *testing.T) {
+	m := Model{
+		configs: []models.APIConfig{
+			{Alias: "work", Model: "claude-sonnet-4-20250514", Models: []string{"claude-sonnet-4-20250514", "custom-model"}},
+		},
+		selected:  0,
+		viewState: ViewModelSelect,
+		modelList: []string{"claude-sonnet-4-20250514", "custom-model"},
+		height:    30,
+		width:     120,
+		modelInfo: modelinfo.Table{"claude-sonnet-4": {ContextWindow: 200000, Vision: true, InputPrice: 3, OutputPrice: 15}},
+	}
+
+	output := m.RenderModelSelectView()
+	if !strings.Contains(output, "200K 上下文 · 视觉 · $3/$15 每百万 tokens") {
+		t.Errorf("RenderModelSelectView() should show model metadata, got:\n%s", output)
+	}
+	if strings.Count(output, "上下文") != 1 {
+		t.Error("RenderModelSelectView() should not show metadata for unknown models")
+	}
+
+	detail := m.RenderDetailView()
+	if !strings.Contains(detail, "模型信息:") || !strings.Contains(detail, "200K 上下文") {
+		t.Errorf("RenderDetailView() should show model metadata, got:\n%s", detail)
+	}
+}
+
 // TestHandleMainViewKeysPing tests the 'p' key handling in main view
 // Requirements: 8.1
 func TestHandleMainViewKeysPing(t *testing.T) {
@@ -1059,6 +1095,18 @@ func TestRenderPingResultView(t *testing.T) {
 			expectSuccess:  false,
 			expectContains: []string{"连接测试结果", "连接失败", "连接被拒绝"},
 		},
+		{
+			name: "multi-sample result with phases",
+			testResult: &TestResult{
+				Success:  true,
+				Message:  "连接成功",
+				Duration: "120ms",
+				Timings:  probe.Timings{Connect: 15 * time.Millisecond, TLS: 30 * time.Millisecond, FirstByte: 70 * time.Millisecond},
+				Stats:    probe.Stats{Samples: 5, Failures: 1, Min: 90 * time.Millisecond, Avg: 120 * time.Millisecond, P95: 200 * time.Millisecond, Max: 200 * time.Millisecond},
+			},
+			expectSuccess:  true,
+			expectContains: []string{"样本: 5 次, 失败 1 次 (20%)", "p95 200ms", "TCP 连接: 15ms", "TLS 握手: 30ms", "首字节: 70ms"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1105,6 +1153,37 @@ func TestHandlePingTestingViewKeys(t *testing.T) {
 }
 
 
+// TestCancelTest tests that Esc cancels a running test and drops its result
+func TestCancelTest(t *testing.T) {
+	m := Model{
+		configs: []models.APIConfig{{Alias: "slow", BaseURL: "https://slow.example.com"}},
+	}
+	ctx := m.beginTest(ViewPingTesting)
+	run := m.testRun
+
+	newModel, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.viewState != ViewMain || m.testing {
+		t.Errorf("after Esc viewState = %v, testing = %v, want main view and no test", m.viewState, m.testing)
+	}
+	if ctx.Err() == nil {
+		t.Error("Esc should cancel the test's context")
+	}
+
+	newModel, _ = m.Update(PingResultMsg{Success: true, Duration: time.Second, Run: run})
+	m = newModel.(Model)
+	if m.viewState != ViewMain || m.testResult != nil {
+		t.Error("the result of a canceled test should be dropped")
+	}
+
+	// A test started after the cancel still gets its result
+	m.beginTest(ViewPingTesting)
+	newModel, _ = m.Update(PingResultMsg{Success: true, Duration: time.Second, Run: m.testRun})
+	if got := newModel.(Model).viewState; got != ViewPingResult {
+		t.Errorf("viewState = %v, want %v", got, ViewPingResult)
+	}
+}
+
 // TestWindowSizeMsg tests the WindowSizeMsg handling in Update
 // Requirements: 11.1
 func TestWindowSizeMsg(t *testing.T) {
@@ -1460,3 +1539,555 @@ func makeConfigs(count int) []models.APIConfig {
 	}
 	return configs
 }
+
+// TestHandleMainViewKeysQuickSwitch tests switching the Nth config with number keys
+func TestHandleMainViewKeysQuickSwitch(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "first", APIKey: "sk-1"},
+		{Alias: "second", APIKey: "sk-2"},
+		{Alias: "multi", APIKey: "sk-3", Model: "b", Models: []string{"a", "b"}},
+	}
+
+	tests := []struct {
+		name            string
+		key             rune
+		expectCursor    int
+		expectCmd       bool
+		expectViewState ViewState
+	}{
+		{name: "press 2 switches second config", key: '2', expectCursor: 1, expectCmd: true, expectViewState: ViewMain},
+		{name: "press 3 opens model select for multi-model config", key: '3', expectCursor: 2, expectCmd: false, expectViewState: ViewModelSelect},
+		{name: "press 9 beyond list does nothing", key: '9', expectCursor: 0, expectCmd: false, expectViewState: ViewMain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{configs: configs, viewState: ViewMain, height: 24}
+
+			newModel, cmd := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{tt.key}})
+			updated := newModel.(Model)
+
+			if updated.cursor != tt.expectCursor {
+				t.Errorf("cursor = %d, want %d", updated.cursor, tt.expectCursor)
+			}
+			if (cmd != nil) != tt.expectCmd {
+				t.Errorf("cmd returned = %v, want %v", cmd != nil, tt.expectCmd)
+			}
+			if updated.viewState != tt.expectViewState {
+				t.Errorf("viewState = %v, want %v", updated.viewState, tt.expectViewState)
+			}
+			if tt.expectViewState == ViewModelSelect && updated.switchType != SwitchTypeLocal {
+				t.Errorf("switchType = %v, want SwitchTypeLocal", updated.switchType)
+			}
+		})
+	}
+}
+
+// TestRenderConfigLineQuickIndex tests that the quick switch index is shown per row
+func TestRenderConfigLineQuickIndex(t *testing.T) {
+	configs := make([]models.APIConfig, 10)
+	for i := range configs {
+		configs[i] = models.APIConfig{Alias: fmt.Sprintf("cfg-%d", i)}
+	}
+	m := Model{configs: configs}
+
+	if line := m.renderConfigLine(0, configs[0]); !strings.Contains(line, "1. ") {
+		t.Errorf("renderConfigLine(0) = %q, want index '1. '", line)
+	}
+	if line := m.renderConfigLine(8, configs[8]); !strings.Contains(line, "9. ") {
+		t.Errorf("renderConfigLine(8) = %q, want index '9. '", line)
+	}
+	if line := m.renderConfigLine(9, configs[9]); strings.Contains(line, ". ") {
+		t.Errorf("renderConfigLine(9) = %q, should not have a quick index", line)
+	}
+}
+
+// TestHandleMainViewKeysPrevious tests the switch back key
+func TestHandleMainViewKeysPrevious(t *testing.T) {
+	m := Model{
+		configs:   []models.APIConfig{{Alias: "test-config", APIKey: "sk-test-key"}},
+		viewState: ViewMain,
+		errorMsg:  "old error",
+	}
+
+	newModel, cmd := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	updated := newModel.(Model)
+
+	if cmd == nil {
+		t.Error("handleMainViewKeys('-') should return a switch command")
+	}
+	if updated.errorMsg != "" {
+		t.Errorf("handleMainViewKeys('-') errorMsg = %q, want cleared", updated.errorMsg)
+	}
+}
+
+// TestHandleMainViewKeysEnvFilter tests cycling the environment filter
+func TestHandleMainViewKeysEnvFilter(t *testing.T) {
+	all := []models.APIConfig{
+		{Alias: "a-prod", Environment: "prod"},
+		{Alias: "a-dev", Environment: "dev"},
+		{Alias: "b-prod", Environment: "prod"},
+		{Alias: "plain"},
+	}
+	m := Model{configs: all, allConfigs: all, viewState: ViewMain, cursor: 3}
+	press := func(m Model) Model {
+		newModel, _ := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+		return newModel.(Model)
+	}
+
+	// Environments cycle in sorted order, then back to all
+	m = press(m)
+	if m.envFilter != "dev" || len(m.configs) != 1 || m.cursor != 0 {
+		t.Errorf("first press: filter=%q configs=%d cursor=%d, want dev/1/0", m.envFilter, len(m.configs), m.cursor)
+	}
+	m = press(m)
+	if m.envFilter != "prod" || len(m.configs) != 2 {
+		t.Errorf("second press: filter=%q configs=%d, want prod/2", m.envFilter, len(m.configs))
+	}
+	if !strings.Contains(m.RenderMainView(), "环境: prod") {
+		t.Error("RenderMainView() should show the active environment filter")
+	}
+	m = press(m)
+	if m.envFilter != "" || len(m.configs) != len(all) {
+		t.Errorf("third press: filter=%q configs=%d, want all", m.envFilter, len(m.configs))
+	}
+
+	// Reloading keeps the filter applied
+	m.envFilter = "prod"
+	newModel, _ := m.Update(ConfigsLoadedMsg{Configs: all})
+	if got := len(newModel.(Model).configs); got != 2 {
+		t.Errorf("ConfigsLoadedMsg with filter: configs=%d, want 2", got)
+	}
+
+	// Without environments the filter reports an error and keeps the list
+	plain := []models.APIConfig{{Alias: "plain"}}
+	m = press(Model{configs: plain, allConfigs: plain, viewState: ViewMain})
+	if m.errorMsg == "" || len(m.configs) != 1 {
+		t.Errorf("no environments: errorMsg=%q configs=%d, want error and unchanged list", m.errorMsg, len(m.configs))
+	}
+}
+
+// TestHistoryPanel tests loading and rendering the health history in the detail view
+func TestHistoryPanel(t *testing.T) {
+	m := Model{
+		configs:   []models.APIConfig{{Alias: "work", APIKey: "sk-work"}, {Alias: "home", APIKey: "sk-home"}},
+		viewState: ViewMain,
+	}
+
+	// Entering the detail view requests the history
+	newModel, cmd := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.viewState != ViewDetail || cmd == nil {
+		t.Fatalf("Enter should open the detail view and load history, got viewState %v, cmd %v", m.viewState, cmd)
+	}
+	if !strings.Contains(m.RenderDetailView(), "暂无记录") {
+		t.Error("RenderDetailView() without history should show the empty placeholder")
+	}
+
+	// History for another config is ignored
+	newModel, _ = m.Update(HistoryLoadedMsg{Alias: "home", Entries: []history.Entry{{Kind: history.KindPing, Success: true}}})
+	if len(newModel.(Model).healthHistory) != 0 {
+		t.Error("HistoryLoadedMsg for another alias should be ignored")
+	}
+
+	entries := []history.Entry{
+		{Time: time.Now(), Kind: history.KindPing, Success: true, LatencyMs: 120, Detail: "HTTP 200"},
+		{Time: time.Now(), Kind: history.KindTest, Success: false, LatencyMs: 900, Detail: "none"},
+	}
+	newModel, _ = m.Update(HistoryLoadedMsg{Alias: "work", Entries: entries})
+	m = newModel.(Model)
+
+	output := m.RenderDetailView()
+	for _, want := range []string{"健康历史", "趋势:", "50% (2 次)", "120ms", "HTTP 200", "兼容"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("RenderDetailView() should contain %q", want)
+		}
+	}
+}
+
+// TestASCIIView tests that ASCII mode leaves no box-drawing characters,
+// arrows or emoji in any view
+func TestASCIIView(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "first", APIKey: "sk-test-key-1234567890", Model: "model1", Models: []string{"model1", "model2"}},
+		{Alias: "second", AuthToken: "token-1234567890"},
+	}
+	states := []ViewState{ViewMain, ViewDetail, ViewHelp, ViewDelete, ViewModelSelect, ViewPingTesting, ViewCompatTesting, ViewExportPreview, ViewCompare, ViewMessageLog}
+
+	for _, state := range states {
+		m := Model{
+			configs:     configs,
+			activeAlias: "first",
+			selected:    0,
+			viewState:   state,
+			modelList:   configs[0].Models,
+			width:       80,
+			height:      30,
+			message:     "ok",
+			ascii:       true,
+			healthHistory: []history.Entry{
+				{Time: time.Now(), Kind: history.KindPing, Success: true, LatencyMs: 100},
+				{Time: time.Now(), Kind: history.KindPing, Success: true, LatencyMs: 900},
+				{Time: time.Now(), Kind: history.KindPing, Success: false},
+			},
+		}
+		for _, r := range m.View() {
+			if strings.ContainsRune("─│↑↓★•·✓✗⚠✅❌⏳️×▁▂▃▄▅▆▇█", r) {
+				t.Errorf("View() in state %v contains %q in ASCII mode", state, r)
+				break
+			}
+		}
+	}
+}
+
+// TestASCIIReplacerKeepsWidths tests that every ASCII replacement takes the
+// display width of its symbol, so tables stay aligned
+func TestASCIIReplacerKeepsWidths(t *testing.T) {
+	for i := 0; i < len(asciiSymbols); i += 2 {
+		symbol := asciiSymbols[i]
+		got := asciiReplacer.Replace(symbol)
+		if lipgloss.Width(got) != lipgloss.Width(symbol) {
+			t.Errorf("%q is replaced with %q of width %d, want %d", symbol, got, lipgloss.Width(got), lipgloss.Width(symbol))
+		}
+		for _, r := range got {
+			if r > 127 {
+				t.Errorf("%q is replaced with non-ASCII %q", symbol, got)
+				break
+			}
+		}
+	}
+}
+
+// TestRenderMainViewTwoPane tests that wide windows show the detail of the
+// config under the cursor beside the list
+func TestRenderMainViewTwoPane(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "first", APIKey: "sk-test-key-1234567890", BaseURL: "https://first.example.com"},
+		{Alias: "second", APIKey: "sk-test-key-0987654321", BaseURL: "https://second.example.com"},
+	}
+
+	tests := []struct {
+		name       string
+		width      int
+		wantDetail bool
+	}{
+		{name: "narrow window keeps single pane", width: 80, wantDetail: false},
+		{name: "wide window shows detail pane", width: 120, wantDetail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				configs:   configs,
+				cursor:    1,
+				selected:  -1,
+				viewState: ViewMain,
+				width:     tt.width,
+				height:    30,
+			}
+
+			next, cmd := previewCursor(m, nil)
+			m = next.(Model)
+			if got := cmd != nil; got != tt.wantDetail {
+				t.Errorf("previewCursor() returned a command = %v, want %v", got, tt.wantDetail)
+			}
+
+			output := m.RenderMainView()
+			if got := strings.Contains(output, "基本信息"); got != tt.wantDetail {
+				t.Errorf("RenderMainView() shows the detail of the config under the cursor = %v, want %v", got, tt.wantDetail)
+			}
+			if !strings.Contains(output, "first") || !strings.Contains(output, "second") {
+				t.Error("RenderMainView() should list every config")
+			}
+			for _, line := range strings.Split(output, "\n") {
+				if w := lipgloss.Width(line); w > tt.width {
+					t.Errorf("RenderMainView() line is %d columns wide, want at most %d: %q", w, tt.width, line)
+				}
+			}
+		})
+	}
+}
+
+// TestExportPreview tests that v previews the generated exports with the key
+// masked and returns to the view it was opened from
+func TestExportPreview(t *testing.T) {
+	cfg := models.APIConfig{Alias: "relay", APIKey: "sk-secret-key-1234567890", BaseURL: "https://relay.example.com", Model: "claude-sonnet-4"}
+
+	for _, from := range []ViewState{ViewMain, ViewDetail} {
+		m := Model{
+			configs:   []models.APIConfig{cfg},
+			selected:  0,
+			viewState: from,
+			width:     80,
+			height:    30,
+		}
+
+		next, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+		m = next.(Model)
+		if m.viewState != ViewExportPreview {
+			t.Fatalf("v from %v: viewState = %v, want ViewExportPreview", from, m.viewState)
+		}
+
+		output := m.View()
+		for _, want := range []string{
+			`export ANTHROPIC_BASE_URL="https://relay.example.com"`,
+			`export ANTHROPIC_API_KEY="sk-s****7890"`,
+			`export APIMGR_ACTIVE="relay"`,
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("export preview should contain %q", want)
+			}
+		}
+		if strings.Contains(output, cfg.APIKey) {
+			t.Error("export preview should not contain the plaintext key")
+		}
+
+		next, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+		if got := next.(Model).viewState; got != from {
+			t.Errorf("Esc from the export preview: viewState = %v, want %v", got, from)
+		}
+	}
+}
+
+// TestLoadSyncStatus tests that configs are tagged with the Claude Code
+// settings that point at them
+func TestLoadSyncStatus(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(project)
+
+	writeSettings := func(dir, env string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".claude", "settings.json"), []byte(`{"env": `+env+`}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSettings(home, `{"ANTHROPIC_API_KEY": "sk-global"}`)
+	writeSettings(project, `{"ANTHROPIC_API_KEY": "sk-project", "ANTHROPIC_BASE_URL": "https://relay.example.com"}`)
+
+	configs := []models.APIConfig{
+		{Alias: "global", APIKey: "sk-global"},
+		{Alias: "project", APIKey: "sk-project", BaseURL: "https://relay.example.com"},
+		{Alias: "unused", APIKey: "sk-unused"},
+	}
+	msg, ok := loadSyncStatus(nil, configs)().(SyncStatusLoadedMsg)
+	if !ok {
+		t.Fatal("loadSyncStatus() should return a SyncStatusLoadedMsg")
+	}
+
+	want := map[string]SyncStatus{
+		"global":  {Global: true},
+		"project": {Project: true},
+		"unused":  {},
+	}
+	for alias, status := range want {
+		if got := msg.Status[alias]; got != status {
+			t.Errorf("status of %s = %+v, want %+v", alias, got, status)
+		}
+	}
+
+	m := Model{configs: configs, syncStatus: msg.Status, width: 80, height: 30}
+	if line := m.renderConfigLine(0, configs[0]); !strings.Contains(line, "@全局") {
+		t.Errorf("renderConfigLine() = %q, should contain @全局", line)
+	}
+	if line := m.renderConfigLine(2, configs[2]); strings.Contains(line, "@") {
+		t.Errorf("renderConfigLine() = %q, should not be tagged", line)
+	}
+}
+
+// TestConfigFileEditedMsgHandling tests that a rejected edit is kept for
+// re-opening until it is discarded
+func TestConfigFileEditedMsgHandling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.edit.json")
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{viewState: ViewMain}
+
+	next, _ := m.Update(ConfigFileEditedMsg{Path: path, Err: &testError{msg: "invalid"}})
+	m = next.(Model)
+	if m.editPath != path || !strings.Contains(m.errorMsg, "invalid") {
+		t.Fatalf("rejected edit: editPath = %q, errorMsg = %q", m.editPath, m.errorMsg)
+	}
+
+	next, _ = m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.editPath != "" {
+		t.Error("Esc should discard the rejected edit")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Esc should remove the copy of the rejected edit")
+	}
+
+	next, cmd := m.Update(ConfigFileEditedMsg{Path: path})
+	if next.(Model).message == "" || cmd == nil {
+		t.Error("a saved edit should report success and reload the configs")
+	}
+}
+
+// TestCompareMode tests that c on one config and then another opens the
+// compare view with the differing fields marked and keys masked
+func TestCompareMode(t *testing.T) {
+	configs := []models.APIConfig{
+		{Alias: "relay-a", APIKey: "sk-aaaa-key-1234567890", BaseURL: "https://relay.example.com", Model: "claude-sonnet-4"},
+		{Alias: "relay-b", APIKey: "sk-bbbb-key-1234567890", BaseURL: "https://relay.example.com", Model: "claude-opus-4"},
+	}
+	m := Model{configs: configs, allConfigs: configs, width: 100, height: 40}
+	c := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}}
+
+	next, _ := m.handleKeyMsg(c)
+	m = next.(Model)
+	if m.compareBase != "relay-a" || m.viewState != ViewMain {
+		t.Fatalf("first c: compareBase = %q, viewState = %v", m.compareBase, m.viewState)
+	}
+	next, _ = m.handleKeyMsg(c)
+	if got := next.(Model).compareBase; got != "" {
+		t.Errorf("c on the base should cancel, compareBase = %q", got)
+	}
+
+	m.cursor = 1
+	next, _ = m.handleKeyMsg(c)
+	m = next.(Model)
+	if m.viewState != ViewCompare || m.compareBase != "" {
+		t.Fatalf("second c: viewState = %v, compareBase = %q, want ViewCompare", m.viewState, m.compareBase)
+	}
+
+	output := m.View()
+	for _, want := range []string{"* model", "claude-sonnet-4", "claude-opus-4", "sk-a****7890", "sk-b****7890"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("compare view should contain %q", want)
+		}
+	}
+	if strings.Contains(output, "* base_url") {
+		t.Error("compare view should not mark an equal field")
+	}
+	if strings.Contains(output, configs[0].APIKey) {
+		t.Error("compare view should not contain the plaintext key")
+	}
+
+	next, _ = m.Update(CompareResultMsg{Results: [2]*compatibility.TestResult{
+		{Success: true, CompatibilityLevel: "full", ResponseTime: 300 * time.Millisecond},
+		{Success: true, CompatibilityLevel: "full", ResponseTime: 100 * time.Millisecond},
+	}})
+	m = next.(Model)
+	if output := m.View(); !strings.Contains(output, "relay-b 快 200ms") {
+		t.Errorf("compare view should name the faster config, got:\n%s", output)
+	}
+
+	next, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := next.(Model).viewState; got != ViewMain {
+		t.Errorf("Esc from the compare view: viewState = %v, want ViewMain", got)
+	}
+}
+
+func TestHandleMainViewKeysPaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        tea.KeyMsg
+		cursor     int
+		wantCursor int
+	}{
+		{"page down", tea.KeyMsg{Type: tea.KeyPgDown}, 0, 8},
+		{"space pages down", tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, 0, 8},
+		{"page down stops at the end", tea.KeyMsg{Type: tea.KeyPgDown}, 95, 99},
+		{"page up", tea.KeyMsg{Type: tea.KeyPgUp}, 50, 42},
+		{"page up stops at the start", tea.KeyMsg{Type: tea.KeyPgUp}, 3, 0},
+		{"half page down", tea.KeyMsg{Type: tea.KeyCtrlD}, 10, 14},
+		{"half page up", tea.KeyMsg{Type: tea.KeyCtrlU}, 10, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{configs: makeConfigs(100), cursor: tt.cursor, height: 15}
+			m.adjustScrollOffset()
+			updated, _ := m.handleMainViewKeys(tt.key)
+			got := updated.(Model)
+			if got.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d", got.cursor, tt.wantCursor)
+			}
+			if visible := got.getVisibleListHeight(); got.cursor < got.scrollOffset || got.cursor >= got.scrollOffset+visible {
+				t.Errorf("cursor %d is not visible (scrollOffset=%d)", got.cursor, got.scrollOffset)
+			}
+		})
+	}
+}
+
+func TestRenderConfigLineCache(t *testing.T) {
+	m := NewModel(nil)
+	m.configs = makeConfigs(500)
+	m.height = 15
+
+	first := m.RenderMainView()
+	if n := len(m.lineCache); n == 0 || n > m.getVisibleListHeight() {
+		t.Errorf("lineCache has %d lines after one render, want only the visible ones", n)
+	}
+	if second := m.RenderMainView(); second != first {
+		t.Error("RenderMainView() from cached lines differs from the first render")
+	}
+
+	// Lines whose cursor marker changed are styled again
+	m.moveDown()
+	if line := m.renderConfigLine(1, m.configs[1]); !strings.HasPrefix(line, "> ") {
+		t.Errorf("renderConfigLine(1) after moving = %q, want the cursor marker", line)
+	}
+	if line := m.renderConfigLine(0, m.configs[0]); strings.HasPrefix(line, "> ") {
+		t.Errorf("renderConfigLine(0) after moving = %q, should not have the cursor marker", line)
+	}
+}
+
+func TestOnboarding(t *testing.T) {
+	m := Model{width: 100, height: 40}
+	next, cmd := m.Update(ConfigsLoadedMsg{})
+	m = next.(Model)
+	if m.viewState != ViewOnboarding || cmd == nil {
+		t.Fatalf("first empty load: viewState = %v, want ViewOnboarding with detection", m.viewState)
+	}
+
+	next, _ = m.Update(OnboardingDetectedMsg{ClaudeEnv: map[string]string{
+		"ANTHROPIC_AUTH_TOKEN": "sk-relay-token-1234567890",
+		"ANTHROPIC_BASE_URL":   "https://relay.example.com",
+	}})
+	m = next.(Model)
+	output := m.View()
+	for _, want := range []string{"https://relay.example.com", "sk-r****7890", "按 s 运行 apimgr install"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("onboarding view should contain %q, got:\n%s", want, output)
+		}
+	}
+
+	next, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = next.(Model)
+	if m.viewState != ViewAdd {
+		t.Fatalf("i: viewState = %v, want ViewAdd", m.viewState)
+	}
+	if got := GetFormData(m.formInputs); got.AuthToken != "sk-relay-token-1234567890" || got.BaseURL != "https://relay.example.com" || got.Alias != "claude" {
+		t.Errorf("i: form data = %+v, want it filled from the Claude settings", got)
+	}
+	next, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.viewState != ViewOnboarding {
+		t.Fatalf("Esc from the form: viewState = %v, want ViewOnboarding", m.viewState)
+	}
+
+	next, _ = m.Update(ShellInstalledMsg{Output: "✓ Installed", ShellRC: "/home/u/.zshrc"})
+	m = next.(Model)
+	if output := m.View(); !strings.Contains(output, "已在 /home/u/.zshrc 中启用") {
+		t.Errorf("onboarding view should show the installed shell integration, got:\n%s", output)
+	}
+
+	next, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.viewState != ViewMain || m.onboarding.active {
+		t.Errorf("Esc: viewState = %v, active = %v, want the main view", m.viewState, m.onboarding.active)
+	}
+
+	// Only the first load shows the guide, not deleting the last config
+	next, _ = m.Update(ConfigsLoadedMsg{})
+	if got := next.(Model).viewState; got != ViewMain {
+		t.Errorf("later empty load: viewState = %v, want ViewMain", got)
+	}
+}
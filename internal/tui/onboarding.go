@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"apimgr/config"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/utils"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// onboardingState is the state of the first-run guide, shown instead of the
+// empty list when there is no config yet
+type onboardingState struct {
+	active     bool
+	detected   bool              // Detection has completed
+	claudeEnv  map[string]string // Env of Claude Code's user settings
+	shellRC    string            // Shell rc file that already loads the active config
+	installing bool              // apimgr install is running
+	installLog string            // Output of apimgr install
+}
+
+// shellRCFiles lists the rc files apimgr install and enable look at
+var shellRCFiles = []string{".zshrc", ".bashrc", ".bash_profile"}
+
+// detectOnboarding looks for an existing Claude Code API setup and shell
+// integration
+func detectOnboarding() tea.Cmd {
+	return func() tea.Msg {
+		return OnboardingDetectedMsg{
+			ClaudeEnv: syncpkg.ReadSettingsEnv(config.ClaudeSettingsPath()),
+			ShellRC:   detectShellRC(),
+		}
+	}
+}
+
+// detectShellRC returns the rc file that loads the active config on shell
+// startup, empty when none does
+func detectShellRC() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range shellRCFiles {
+		path := filepath.Join(home, name)
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "apimgr load-active") {
+			return path
+		}
+	}
+	return ""
+}
+
+// installShell runs apimgr install, which adds the shell integration to the
+// rc file of the user's shell
+func installShell() tea.Cmd {
+	return func() tea.Msg {
+		executable, err := os.Executable()
+		if err != nil {
+			return ShellInstalledMsg{Err: err}
+		}
+		output, err := exec.Command(executable, "install").CombinedOutput()
+		if err != nil {
+			return ShellInstalledMsg{Output: string(output), Err: fmt.Errorf("apimgr install 失败: %s", strings.TrimSpace(string(output)))}
+		}
+		return ShellInstalledMsg{Output: string(output), ShellRC: detectShellRC()}
+	}
+}
+
+// claudeFormData returns the add form pre-filled from the env of Claude
+// Code's settings, and whether it holds a key or token to import
+func claudeFormData(env map[string]string) (FormData, bool) {
+	data := FormData{
+		Alias:     "claude",
+		APIKey:    env["ANTHROPIC_API_KEY"],
+		AuthToken: env["ANTHROPIC_AUTH_TOKEN"],
+		BaseURL:   env["ANTHROPIC_BASE_URL"],
+		Provider:  "anthropic",
+		Model:     env["ANTHROPIC_MODEL"],
+	}
+	return data, data.APIKey != "" || data.AuthToken != ""
+}
+
+// handleOnboardingKeys handles keyboard input in the first-run guide
+func (m Model) handleOnboardingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keyMap()
+	if m.refuseReadOnly(msg, keys.Add, keys.ImportClaude) {
+		return m, nil
+	}
+	switch {
+	case msg.String() == "ctrl+c" || key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.ImportClaude):
+		data, ok := claudeFormData(m.onboarding.claudeEnv)
+		if !ok {
+			m.errorMsg = "未在 Claude Code 设置中发现 API key 或 auth token"
+			return m, nil
+		}
+		m.initAddForm()
+		SetFormData(m.formInputs, data)
+		return m, nil
+
+	case key.Matches(msg, keys.Add):
+		m.initAddForm()
+		return m, nil
+
+	case key.Matches(msg, keys.InstallShell):
+		if m.onboarding.shellRC != "" || m.onboarding.installing {
+			return m, nil
+		}
+		m.onboarding.installing = true
+		m.errorMsg = ""
+		return m, installShell()
+
+	case key.Matches(msg, keys.Back), key.Matches(msg, keys.Confirm):
+		m.onboarding = onboardingState{}
+		m.viewState = ViewMain
+		m.errorMsg = ""
+		return m, nil
+	}
+	return m, nil
+}
+
+// RenderOnboardingView renders the first-run guide
+func (m Model) RenderOnboardingView() string {
+	var b strings.Builder
+	effectiveWidth := m.getEffectiveWidth(50)
+	keys := m.keyMap()
+	done := func(ok bool) string {
+		if ok {
+			return activeStyle.Render("✓")
+		}
+		return dimStyle.Render("·")
+	}
+
+	b.WriteString(titleStyle.Render("欢迎使用 apimgr"))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n\n")
+	b.WriteString("还没有任何配置，按以下步骤完成初始设置:\n\n")
+
+	// Step 1: existing Claude Code setup
+	_, importable := claudeFormData(m.onboarding.claudeEnv)
+	b.WriteString(fmt.Sprintf("%s %s\n", done(importable), detailSectionStyle.Render("1. 检测 Claude Code 设置")))
+	switch {
+	case !m.onboarding.detected:
+		b.WriteString(dimStyle.Render("   ⏳ 正在检测..."))
+	case importable:
+		env := m.onboarding.claudeEnv
+		credential := env["ANTHROPIC_API_KEY"]
+		if credential == "" {
+			credential = env["ANTHROPIC_AUTH_TOKEN"]
+		}
+		baseURL := env["ANTHROPIC_BASE_URL"]
+		if baseURL == "" {
+			baseURL = "(默认)"
+		}
+		b.WriteString(fmt.Sprintf("   在 %s 中发现: %s  %s\n", config.ClaudeSettingsPath(), baseURL, utils.MaskAPIKey(credential)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf("   按 %s 导入为配置", keys.ImportClaude.Help().Key)))
+	default:
+		b.WriteString(dimStyle.Render("   未在 Claude Code 设置中发现 API key 或 auth token"))
+	}
+	b.WriteString("\n\n")
+
+	// Step 2: first config
+	created := len(m.allConfigs) > 0
+	b.WriteString(fmt.Sprintf("%s %s\n", done(created), detailSectionStyle.Render("2. 创建第一个配置")))
+	if created {
+		b.WriteString(fmt.Sprintf("   已创建: %s", m.allConfigs[0].Alias))
+	} else {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("   按 %s 填写表单添加配置", keys.Add.Help().Key)))
+	}
+	b.WriteString("\n\n")
+
+	// Step 3: shell integration
+	shellReady := m.onboarding.shellRC != ""
+	b.WriteString(fmt.Sprintf("%s %s\n", done(shellReady), detailSectionStyle.Render("3. Shell 集成")))
+	switch {
+	case shellReady:
+		b.WriteString(fmt.Sprintf("   已在 %s 中启用，新终端自动加载当前配置", m.onboarding.shellRC))
+	case m.onboarding.installing:
+		b.WriteString(dimStyle.Render("   ⏳ 正在运行 apimgr install..."))
+	default:
+		b.WriteString(dimStyle.Render(fmt.Sprintf("   按 %s 运行 apimgr install，新终端自动加载当前配置", keys.InstallShell.Help().Key)))
+	}
+	b.WriteString("\n")
+	if log := strings.TrimSpace(m.onboarding.installLog); log != "" {
+		for _, line := range strings.Split(log, "\n") {
+			b.WriteString(dimStyle.Render("   " + line))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n")
+	b.WriteString(m.RenderStatusBar())
+
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"apimgr/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// readOnlyMessage tells why a change was refused in read-only mode
+const readOnlyMessage = "只读模式，不能修改配置 (--read-only 或 " + config.ReadOnlyEnvVar + ")"
+
+// mutatingKeys returns the bindings of the list and detail views that
+// change the configuration or the synced settings
+func mutatingKeys(keys KeyMap) []key.Binding {
+	return []key.Binding{
+		keys.SwitchLocal, keys.SwitchGlobal, keys.QuickSwitch, keys.Previous,
+		keys.Add, keys.Edit, keys.Delete, keys.Undo, keys.OpenEditor, keys.Model,
+	}
+}
+
+// refuseReadOnly reports whether msg matches one of bindings while
+// read-only mode is on, showing why nothing happens when it does
+func (m *Model) refuseReadOnly(msg tea.KeyMsg, bindings ...key.Binding) bool {
+	if !config.ReadOnly() || !key.Matches(msg, bindings...) {
+		return false
+	}
+	m.message = ""
+	m.errorMsg = readOnlyMessage
+	return true
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReadOnlyKeys(t *testing.T) {
+	t.Setenv(config.ReadOnlyEnvVar, "1")
+	m := Model{
+		configs:   []models.APIConfig{{Alias: "work", APIKey: "sk-test-key", Models: []string{"m1", "m2"}}},
+		viewState: ViewMain,
+	}
+
+	for _, r := range []rune{'a', 'e', 'd', 's', 'S', 'm', '1', '-'} {
+		newModel, cmd := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		got := newModel.(Model)
+		if got.viewState != ViewMain || cmd != nil || got.errorMsg != readOnlyMessage {
+			t.Errorf("key %q in read-only mode: viewState %v, errorMsg %q, want refused", r, got.viewState, got.errorMsg)
+		}
+	}
+
+	// Looking around still works
+	newModel, _ := m.handleMainViewKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := newModel.(Model); got.viewState != ViewDetail || got.errorMsg != "" {
+		t.Errorf("enter in read-only mode: viewState %v, errorMsg %q, want the detail view", got.viewState, got.errorMsg)
+	}
+	m.viewState = ViewDetail
+	newModel, _ = m.handleDetailViewKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if got := newModel.(Model); got.viewState != ViewDetail || got.errorMsg != readOnlyMessage {
+		t.Errorf("delete in the detail view: viewState %v, errorMsg %q, want refused", got.viewState, got.errorMsg)
+	}
+}
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newPingTestResult converts a ping result for the result view
+func newPingTestResult(msg PingResultMsg) *TestResult {
+	if msg.Err != nil {
+		return &TestResult{
+			Success: false,
+			Message: msg.Err.Error(),
+			Timings: msg.Timings,
+			Stats:   msg.Stats,
+		}
+	}
+	return &TestResult{
+		Success:  msg.Success,
+		Message:  "连接成功",
+		Duration: msg.Duration.String(),
+		Timings:  msg.Timings,
+		Stats:    msg.Stats,
+	}
+}
+
+// newCompatTestResult converts a compatibility test result for the result
+// view, or returns nil when the message carries none
+func newCompatTestResult(msg CompatResultMsg) *CompatTestResult {
+	if msg.Err != nil {
+		return &CompatTestResult{
+			Success:            false,
+			CompatibilityLevel: compatibility.CompatibilityNone,
+			Error:              msg.Err.Error(),
+		}
+	}
+	if msg.Result == nil {
+		return nil
+	}
+	checks := make([]CompatCheck, len(msg.Result.Checks))
+	for i, c := range msg.Result.Checks {
+		checks[i] = CompatCheck{
+			Name:     c.Name,
+			Passed:   c.Passed,
+			Message:  c.Message,
+			Critical: c.Critical,
+		}
+	}
+	return &CompatTestResult{
+		Success:            msg.Result.Success,
+		CompatibilityLevel: msg.Result.CompatibilityLevel,
+		Checks:             checks,
+		ResponseTime:       msg.Result.ResponseTime.String(),
+		Error:              msg.Result.Error,
+		RateLimits:         msg.Result.RateLimits,
+	}
+}
+
+// loadResultTTL returns how long test results are reused, from
+// test_settings.cache_ttl, falling back to the default when it is unset or
+// invalid
+func loadResultTTL(cm *config.Manager) time.Duration {
+	settings, err := cm.GetTestSettings()
+	if err != nil || settings == nil || settings.CacheTTL == "" {
+		return history.DefaultResultTTL
+	}
+	ttl, err := time.ParseDuration(settings.CacheTTL)
+	if err != nil || ttl < 0 {
+		return history.DefaultResultTTL
+	}
+	return ttl
+}
+
+// loadResults creates a command to load the cached test results
+func loadResults(cm *config.Manager) tea.Cmd {
+	return func() tea.Msg {
+		if cm == nil {
+			return ResultsLoadedMsg{}
+		}
+		results, _ := history.LoadResults(cm.StateDir())
+		return ResultsLoadedMsg{Results: results}
+	}
+}
+
+// cacheResult stores the result of a test on cfg, ignoring failures
+func cacheResult(cm *config.Manager, cfg *models.APIConfig, kind string, success bool, summary string, result any) {
+	if cm == nil {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	_ = history.StoreResult(cm.StateDir(), cfg.Alias, kind, history.CachedResult{
+		Time:        time.Now(),
+		Success:     success,
+		Summary:     summary,
+		Fingerprint: history.Fingerprint(cfg),
+		Result:      data,
+	})
+}
+
+// freshResult returns the cached result of a kind of test on cfg while it
+// is fresh
+func (m Model) freshResult(cfg *models.APIConfig, kind string) (history.CachedResult, bool) {
+	cached, ok := m.results[cfg.Alias][kind]
+	if !ok || !cached.Fresh(cfg, m.resultTTL, time.Now()) {
+		return history.CachedResult{}, false
+	}
+	return cached, true
+}
+
+// showPing shows the cached ping result of cfg while it is fresh, and pings
+// it otherwise
+func (m Model) showPing(cfg models.APIConfig) (Model, tea.Cmd) {
+	if cached, ok := m.freshResult(&cfg, history.KindPing); ok {
+		var result TestResult
+		if json.Unmarshal(cached.Result, &result) == nil {
+			m.testResult = &result
+			m.resultCachedAt = cached.Time
+			m.viewState = ViewPingResult
+			return m, nil
+		}
+	}
+	return m.startPing(cfg)
+}
+
+// showCompatTest shows the cached compatibility test result of cfg while it
+// is fresh, and tests it otherwise
+func (m Model) showCompatTest(cfg models.APIConfig) (Model, tea.Cmd) {
+	if cached, ok := m.freshResult(&cfg, history.KindTest); ok {
+		var result CompatTestResult
+		if json.Unmarshal(cached.Result, &result) == nil {
+			m.compatResult = &result
+			m.resultCachedAt = cached.Time
+			m.viewState = ViewCompatResult
+			return m, nil
+		}
+	}
+	return m.startCompatTest(cfg)
+}
+
+// resultBadge summarizes the newest fresh result of cfg for the config
+// list, such as " ✓412ms", or returns an empty string without one
+func (m Model) resultBadge(cfg *models.APIConfig) string {
+	var latest *history.CachedResult
+	for _, kind := range []string{history.KindPing, history.KindTest} {
+		if cached, ok := m.freshResult(cfg, kind); ok && (latest == nil || cached.Time.After(latest.Time)) {
+			latest = &cached
+		}
+	}
+	switch {
+	case latest == nil:
+		return ""
+	case !latest.Success:
+		return " ✗"
+	default:
+		return " ✓" + latest.Summary
+	}
+}
+
+// renderCachedNote tells that the shown result was cached and how old it
+// is, or returns an empty string for a result that was just tested
+func (m Model) renderCachedNote() string {
+	if m.resultCachedAt.IsZero() {
+		return ""
+	}
+	age := time.Since(m.resultCachedAt)
+	return dimStyle.Render(fmt.Sprintf("缓存结果，%s (按 %s 重新测试)", formatAge(age), m.keyMap().Retry.Help().Key)) + "\n"
+}
+
+// formatAge describes how long ago something happened, such as "3 分钟前"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "刚刚"
+	case age < time.Hour:
+		return fmt.Sprintf("%d 分钟前", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%d 小时前", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d 天前", int(age.Hours()/24))
+	}
+}
+
+// rateLimitNames names the resources rate limits apply to
+var rateLimitNames = map[string]string{
+	"requests":      "请求",
+	"tokens":        "token",
+	"input-tokens":  "输入 token",
+	"output-tokens": "输出 token",
+}
+
+// renderRateLimits lists the rate limit budgets reported during a test, such
+// as "请求: 剩余 49/50，1s 后重置", or returns an empty string without any
+func renderRateLimits(limits []compatibility.RateLimit, now time.Time) string {
+	if len(limits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(detailSectionStyle.Render("速率限制"))
+	b.WriteString("\n")
+	for _, limit := range limits {
+		name := rateLimitNames[limit.Resource]
+		if name == "" {
+			name = limit.Resource
+		}
+		line := fmt.Sprintf("  %s: 剩余 %d", name, limit.Remaining)
+		if limit.Limit > 0 {
+			line += fmt.Sprintf("/%d", limit.Limit)
+		}
+		if in := limit.ResetIn(now); in > 0 {
+			line += fmt.Sprintf("，%s 后重置", in)
+		}
+		// An exhausted budget explains throttled requests
+		style := dimStyle
+		if limit.Remaining == 0 {
+			style = checkFailedStyle
+		}
+		b.WriteString(style.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"apimgr/config/history"
+	"apimgr/config/models"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/probe"
+)
+
+func TestShowPingUsesCache(t *testing.T) {
+	cfg := models.APIConfig{Alias: "work", APIKey: "sk-work", BaseURL: "https://work.example.com"}
+	data, _ := json.Marshal(TestResult{Success: true, Message: "连接成功", Duration: "120ms"})
+	cached := history.CachedResult{Time: time.Now().Add(-3 * time.Minute), Success: true, Summary: "120ms", Fingerprint: history.Fingerprint(&cfg), Result: data}
+	m := Model{
+		configs:   []models.APIConfig{cfg},
+		results:   map[string]map[string]history.CachedResult{"work": {history.KindPing: cached}},
+		resultTTL: 10 * time.Minute,
+	}
+
+	if got := m.resultBadge(&cfg); got != " ✓120ms" {
+		t.Errorf("resultBadge() = %q, want %q", got, " ✓120ms")
+	}
+
+	shown, cmd := m.showPing(cfg)
+	if cmd != nil || shown.viewState != ViewPingResult || shown.testing {
+		t.Fatalf("showPing() with a fresh result should show it without testing")
+	}
+	if shown.testResult == nil || shown.testResult.Duration != "120ms" {
+		t.Errorf("testResult = %+v, want the cached one", shown.testResult)
+	}
+	if note := shown.renderCachedNote(); !strings.Contains(note, "3 分钟前") {
+		t.Errorf("renderCachedNote() = %q, want the result's age", note)
+	}
+
+	// A stale result is tested again
+	m.resultTTL = time.Minute
+	shown, cmd = m.showPing(cfg)
+	if cmd == nil || shown.viewState != ViewPingTesting || !shown.resultCachedAt.IsZero() {
+		t.Errorf("showPing() with a stale result should start a test")
+	}
+	if got := m.resultBadge(&cfg); got != "" {
+		t.Errorf("resultBadge() of a stale result = %q, want none", got)
+	}
+}
+
+func TestRenderRateLimits(t *testing.T) {
+	if got := renderRateLimits(nil, time.Now()); got != "" {
+		t.Errorf("renderRateLimits(nil) = %q, want nothing", got)
+	}
+
+	now := time.Now()
+	out := renderRateLimits([]compatibility.RateLimit{
+		{Resource: "requests", Limit: 50, Remaining: 49, Reset: now.Add(30 * time.Second)},
+		{Resource: "output-tokens", Remaining: 0},
+	}, now)
+	for _, want := range []string{"速率限制", "请求: 剩余 49/50，30s 后重置", "输出 token: 剩余 0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("renderRateLimits() should contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestPingTestAveragesPhases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Samples after the first reuse its connection, which the phases of the
+	// result must not show
+	cfg := &models.APIConfig{Alias: "local", APIKey: "sk-local", BaseURL: server.URL}
+	result := performPingTest(context.Background(), cfg, probe.DefaultSamples)
+	if !result.Success || result.Stats.Samples != probe.DefaultSamples {
+		t.Fatalf("performPingTest() = %+v, want %d successful samples", result, probe.DefaultSamples)
+	}
+	if result.Timings.Connect <= 0 || result.Timings.FirstByte <= 0 {
+		t.Errorf("Timings = %+v, want the connect phase of the first sample and the mean first byte", result.Timings)
+	}
+}
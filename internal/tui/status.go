@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"time"
+
+	"apimgr/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultMessageTimeout is how long status messages stay when the config
+// file sets no defaults.message_timeout
+const defaultMessageTimeout = 5 * time.Second
+
+// maxMessageLog is how many status messages the message history keeps
+const maxMessageLog = 50
+
+// loggedMessage is a status message kept in the message history
+type loggedMessage struct {
+	Time  time.Time
+	Text  string
+	Error bool
+}
+
+// clearMessageMsg clears the status messages shown since seq, unless newer
+// ones replaced them
+type clearMessageMsg struct {
+	seq int
+}
+
+// loadMessageTimeout returns the status message timeout of the config file,
+// falling back to the default when it is unset or invalid
+func loadMessageTimeout(cm *config.Manager) time.Duration {
+	value, err := cm.GetSetting("defaults.message_timeout")
+	if err != nil || value == "" {
+		return defaultMessageTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return defaultMessageTimeout
+	}
+	return timeout
+}
+
+// trackMessages stamps and logs the status messages that changed from prev
+// to next, and schedules clearing them after the message timeout
+func trackMessages(prev, next Model) (Model, tea.Cmd) {
+	newError := next.errorMsg != "" && next.errorMsg != prev.errorMsg
+	newMessage := next.message != "" && next.message != prev.message
+	if !newError && !newMessage {
+		return next, nil
+	}
+
+	next.messageAt = time.Now()
+	next.messageSeq++
+	if newError {
+		next.messageLog = append(next.messageLog, loggedMessage{Time: next.messageAt, Text: next.errorMsg, Error: true})
+	}
+	if newMessage {
+		next.messageLog = append(next.messageLog, loggedMessage{Time: next.messageAt, Text: next.message})
+	}
+	if len(next.messageLog) > maxMessageLog {
+		next.messageLog = next.messageLog[len(next.messageLog)-maxMessageLog:]
+	}
+
+	if next.messageTimeout <= 0 {
+		return next, nil
+	}
+	seq := next.messageSeq
+	return next, tea.Tick(next.messageTimeout, func(time.Time) tea.Msg {
+		return clearMessageMsg{seq: seq}
+	})
+}
+
+// clearMessages clears the status messages, except form errors, which stay
+// until the form is fixed
+func (m Model) clearMessages(msg clearMessageMsg) Model {
+	if msg.seq != m.messageSeq || m.viewState == ViewAdd || m.viewState == ViewEdit {
+		return m
+	}
+	m.message = ""
+	m.errorMsg = ""
+	return m
+}
+
+// handleMessageLogKeys handles keyboard input in the message history
+func (m Model) handleMessageLogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keyMap()
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, keys.Back), key.Matches(msg, keys.Messages), msg.String() == "q":
+		m.viewState = ViewMain
+	}
+	return m, nil
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackMessages(t *testing.T) {
+	prev := Model{messageTimeout: time.Second}
+	next := prev
+	next.message = "配置已删除: work"
+
+	next, cmd := trackMessages(prev, next)
+	if next.messageAt.IsZero() || len(next.messageLog) != 1 || next.messageLog[0].Text != "配置已删除: work" {
+		t.Fatalf("trackMessages() did not stamp and log the message: %+v", next.messageLog)
+	}
+	if cmd == nil {
+		t.Fatal("trackMessages() should schedule clearing the message")
+	}
+
+	// A newer message is not cleared by the tick of the older one
+	stale := clearMessageMsg{seq: next.messageSeq}
+	newer := next
+	newer.errorMsg = "连接失败"
+	newer, _ = trackMessages(next, newer)
+	if got := newer.clearMessages(stale); got.errorMsg == "" {
+		t.Error("clearMessages() cleared a newer message")
+	}
+	if got := newer.clearMessages(clearMessageMsg{seq: newer.messageSeq}); got.message != "" || got.errorMsg != "" {
+		t.Errorf("clearMessages() left %q, %q", got.message, got.errorMsg)
+	}
+	if len(newer.messageLog) != 2 || !newer.messageLog[1].Error {
+		t.Errorf("messageLog = %+v, want the error logged second", newer.messageLog)
+	}
+
+	// Unchanged messages are not logged again, and a zero timeout keeps them
+	same, cmd := trackMessages(newer, newer)
+	if len(same.messageLog) != 2 || cmd != nil {
+		t.Error("trackMessages() logged an unchanged message")
+	}
+	next.messageTimeout = 0
+	next.message = "已取消对比"
+	if _, cmd := trackMessages(prev, next); cmd != nil {
+		t.Error("trackMessages() should keep messages when the timeout is 0")
+	}
+}
+
+func TestRenderMessageLogView(t *testing.T) {
+	m := Model{width: 80, height: 24}
+	if out := m.RenderMessageLogView(); !strings.Contains(out, "暂无消息") {
+		t.Errorf("RenderMessageLogView() without messages = %q", out)
+	}
+
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local)
+	m.messageLog = []loggedMessage{
+		{Time: at, Text: "旧消息"},
+		{Time: at.Add(time.Minute), Text: "新错误", Error: true},
+	}
+	out := m.RenderMessageLogView()
+	for _, want := range []string{"15:04:05", "15:05:05", "旧消息", "新错误"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RenderMessageLogView() should contain %q", want)
+		}
+	}
+	if strings.Index(out, "新错误") > strings.Index(out, "旧消息") {
+		t.Error("RenderMessageLogView() should list the newest message first")
+	}
+}
@@ -5,23 +5,62 @@ import (
 	"os"
 
 	"apimgr/config"
+	"apimgr/internal/modelinfo"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Options configures the TUI
+type Options struct {
+	ASCII bool // Render with ASCII symbols only
+	Demo  bool // Synthetic configs and test results on a temporary store
+
+	// Screen reader mode: no alternate screen, ASCII symbols and state
+	// changes printed as text
+	Accessible bool
+}
+
 // Run starts the TUI interface
-func Run() error {
+func Run(options Options) error {
 	// Check if we're running in a terminal
 	if !isTerminal() {
 		return fmt.Errorf("apimgr TUI requires a terminal. Use subcommands for non-interactive mode")
 	}
 
-	configManager, err := config.NewConfigManager()
+	var configManager *config.Manager
+	var err error
+	if options.Demo {
+		var cleanup func()
+		configManager, cleanup, err = NewDemoManager()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else {
+		configManager, err = config.NewConfigManager()
+		if err != nil {
+			return err
+		}
+	}
+
+	keys, err := loadKeyMap(configManager)
+	if err != nil {
+		return err
+	}
+
+	modelInfo, err := modelinfo.Load(configManager.GetConfigPath())
 	if err != nil {
 		return err
 	}
 
 	m := NewModel(configManager)
+	m.keys = &keys
+	m.modelInfo = modelInfo
+	m.ascii = options.ASCII || options.Accessible
+	m.demo = options.Demo
+	m.accessible = options.Accessible
+	m.messageTimeout = loadMessageTimeout(configManager)
+	m.resultTTL = loadResultTTL(configManager)
 	
 	// Create program with options that work better across different terminals
 	opts := []tea.ProgramOption{
@@ -32,6 +71,10 @@ func Run() error {
 	if os.Getenv("TERM") != "" {
 		opts = append(opts, tea.WithMouseCellMotion())
 	}
+	// Screen readers follow the scrollback, which the alternate screen hides
+	if options.Accessible {
+		opts = nil
+	}
 	
 	p := tea.NewProgram(m, opts...)
 
@@ -39,6 +82,19 @@ func Run() error {
 	return err
 }
 
+// loadKeyMap builds the key bindings, applying overrides from the config file
+func loadKeyMap(cm *config.Manager) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	overrides, err := cm.GetKeybindings()
+	if err != nil {
+		return keys, err
+	}
+	if err := keys.ApplyOverrides(overrides); err != nil {
+		return keys, fmt.Errorf("invalid keybindings in config: %w", err)
+	}
+	return keys, nil
+}
+
 // isTerminal checks if stdin is a terminal
 func isTerminal() bool {
 	fileInfo, err := os.Stdin.Stat()
@@ -2,10 +2,21 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"apimgr/config"
+	"apimgr/config/history"
+	"apimgr/config/keypool"
 	"apimgr/config/models"
+	syncpkg "apimgr/config/sync"
+	"apimgr/internal/compatibility"
+	"apimgr/internal/modelinfo"
+	"apimgr/internal/probe"
+	"apimgr/internal/utils"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -55,6 +66,55 @@ var (
 			Foreground(lipgloss.Color("238"))
 )
 
+// asciiSymbols maps the symbols the views draw to ASCII, for terminals and
+// fonts that mangle them. Emoji with a variation selector come first so the
+// selector is replaced along with them. A replacement is no wider than its
+// symbol.
+var asciiSymbols = []string{
+	"⚠️", "!",
+	"⚠", "!",
+	"✅", "OK",
+	"❌", "X",
+	"⏳", "..",
+	"✓", "+",
+	"✗", "x",
+	"★", "*",
+	"•", "*",
+	"·", "-",
+	"─", "-",
+	"│", "|",
+	"↑", "^",
+	"↓", "v",
+	"×", "x",
+	// Sparkline bars, lowest first
+	"▁", "_",
+	"▂", ".",
+	"▃", "-",
+	"▄", ":",
+	"▅", "=",
+	"▆", "+",
+	"▇", "*",
+	"█", "#",
+}
+
+// asciiReplacer replaces asciiSymbols, padding each replacement to the
+// display width of its symbol so that columns stay aligned
+var asciiReplacer = newASCIIReplacer(asciiSymbols)
+
+// newASCIIReplacer returns a replacer of the symbol and replacement pairs
+// of symbols, with the replacements padded to the width of their symbol
+func newASCIIReplacer(symbols []string) *strings.Replacer {
+	pairs := make([]string, 0, len(symbols))
+	for i := 0; i+1 < len(symbols); i += 2 {
+		symbol, replacement := symbols[i], symbols[i+1]
+		if pad := lipgloss.Width(symbol) - lipgloss.Width(replacement); pad > 0 {
+			replacement += strings.Repeat(" ", pad)
+		}
+		pairs = append(pairs, symbol, replacement)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
 // RenderMainView renders the main list view
 // Requirements: 11.1, 11.2, 11.3
 func (m Model) RenderMainView() string {
@@ -62,11 +122,41 @@ func (m Model) RenderMainView() string {
 
 	// Title
 	b.WriteString(titleStyle.Render("API 配置管理器"))
+	if m.envFilter != "" {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  环境: %s", m.envFilter)))
+	}
+	if config.ReadOnly() {
+		b.WriteString(dimStyle.Render("  只读"))
+	}
+	if running := m.runningJobs(); running > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  后台任务: %d 个进行中", running)))
+	}
 	b.WriteString("\n")
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.getEffectiveWidth(40))))
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.separatorWidth())))
 	b.WriteString("\n\n")
 
-	// Config list with scrolling
+	// Config list, with the detail of the config under the cursor beside it
+	// on wide terminals
+	if m.twoPane() && len(m.configs) > 0 {
+		b.WriteString(m.renderTwoPane())
+	} else {
+		b.WriteString(m.renderConfigList())
+	}
+
+	// Add some spacing before status bar
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.separatorWidth())))
+	b.WriteString("\n")
+
+	// Status bar
+	b.WriteString(m.RenderStatusBar())
+
+	return b.String()
+}
+
+// renderConfigList renders the config list with scrolling
+func (m Model) renderConfigList() string {
+	var b strings.Builder
 	if len(m.configs) == 0 {
 		b.WriteString(dimStyle.Render("暂无配置，按 'a' 添加新配置"))
 		b.WriteString("\n")
@@ -98,16 +188,62 @@ func (m Model) RenderMainView() string {
 			b.WriteString("\n")
 		}
 	}
+	return b.String()
+}
 
-	// Add some spacing before status bar
-	b.WriteString("\n")
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.getEffectiveWidth(40))))
-	b.WriteString("\n")
+// twoPaneMinWidth is the narrowest window that shows the list and the detail
+// side by side
+const twoPaneMinWidth = 100
 
-	// Status bar
-	b.WriteString(m.RenderStatusBar())
+// detailPaneStyle separates the detail pane from the list
+var detailPaneStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), false, false, false, true).
+	BorderForeground(lipgloss.Color("238")).
+	PaddingLeft(1)
 
-	return b.String()
+// twoPane reports whether the main view uses the two-pane layout, never in
+// accessible mode
+func (m Model) twoPane() bool {
+	return !m.accessible && m.width >= twoPaneMinWidth
+}
+
+// separatorWidth returns the width of the main view separators
+func (m Model) separatorWidth() int {
+	if m.twoPane() {
+		return m.width - 2
+	}
+	return m.getEffectiveWidth(40)
+}
+
+// paneWidths returns the widths of the list pane and of the detail pane
+// content, leaving room for the border and padding between them
+func (m Model) paneWidths() (int, int) {
+	listWidth := m.width * 2 / 5
+	detailWidth := m.width - listWidth - 4
+	if detailWidth > 80 {
+		detailWidth = 80
+	}
+	return listWidth, detailWidth
+}
+
+// renderTwoPane renders the config list on the left and the detail of the
+// config under the cursor on the right
+func (m Model) renderTwoPane() string {
+	listWidth, detailWidth := m.paneWidths()
+	list := lipgloss.NewStyle().MaxWidth(listWidth).Render(strings.TrimSuffix(m.renderConfigList(), "\n"))
+	list = lipgloss.PlaceHorizontal(listWidth, lipgloss.Left, list)
+
+	var detail string
+	if m.cursor >= 0 && m.cursor < len(m.configs) {
+		// Health history belongs to the selected config until the preview catches up
+		if m.selected != m.cursor {
+			m.healthHistory = nil
+		}
+		detail = m.renderConfigDetail(m.configs[m.cursor], detailWidth)
+	}
+	detail = detailPaneStyle.MaxHeight(m.getVisibleListHeight()).Render(strings.TrimSuffix(detail, "\n"))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, detail) + "\n"
 }
 
 // getEffectiveWidth returns the effective width for rendering, with a minimum and maximum
@@ -139,11 +275,26 @@ func (m Model) renderConfigLine(index int, cfg models.APIConfig) string {
 	activeMarker := "  "
 	if isActive {
 		activeMarker = "* "
+		if m.accessible {
+			activeMarker = "[使用中] "
+		}
+	}
+
+	// Build quick switch index for the first configs
+	quickIndex := "   "
+	quickKeys := m.keyMap().QuickSwitch
+	if quickKeys.Enabled() && index < len(quickKeys.Keys()) {
+		quickIndex = fmt.Sprintf("%s. ", quickKeys.Keys()[index])
 	}
 
 	// Build the main line content
 	alias := cfg.Alias
 	
+	// Add environment tag if set
+	if cfg.Environment != "" {
+		alias = fmt.Sprintf("%s {%s}", alias, cfg.Environment)
+	}
+
 	// Add model info if available
 	modelInfo := ""
 	if cfg.Model != "" {
@@ -161,17 +312,59 @@ func (m Model) renderConfigLine(index int, cfg models.APIConfig) string {
 	}
 
 	// Combine all parts
-	content := fmt.Sprintf("%s%s%s%s%s", cursor, activeMarker, alias, modelInfo, urlInfo)
+	content := fmt.Sprintf("%s%s%s%s%s%s%s%s", cursor, quickIndex, activeMarker, alias, modelInfo, urlInfo, m.syncTags(cfg.Alias), m.resultBadge(&cfg))
+
+	lineKey := configLineKey{content: content, selected: isSelected, active: isActive}
+	if line, ok := m.lineCache[lineKey]; ok {
+		return line
+	}
 
 	// Apply appropriate style based on selection and active state
+	var line string
 	if isSelected && isActive {
-		return activeSelectedStyle.Render(content)
+		line = activeSelectedStyle.Render(content)
 	} else if isSelected {
-		return selectedStyle.Render(content)
+		line = selectedStyle.Render(content)
 	} else if isActive {
-		return activeStyle.Render(content)
+		line = activeStyle.Render(content)
+	} else {
+		line = normalStyle.Render(content)
 	}
-	return normalStyle.Render(content)
+	if m.lineCache != nil {
+		if len(m.lineCache) >= maxCachedLines {
+			clear(m.lineCache)
+		}
+		m.lineCache[lineKey] = line
+	}
+	return line
+}
+
+// maxCachedLines bounds the styled config list lines kept between renders
+const maxCachedLines = 1024
+
+// configLineKey identifies a styled config list line
+type configLineKey struct {
+	content          string
+	selected, active bool
+}
+
+// syncTags lists where a config is in effect, such as " @全局/会话"
+func (m Model) syncTags(alias string) string {
+	status := m.syncStatus[alias]
+	var tags []string
+	if status.Global {
+		tags = append(tags, "全局")
+	}
+	if status.Project {
+		tags = append(tags, "项目")
+	}
+	if status.Session {
+		tags = append(tags, "会话")
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return " @" + strings.Join(tags, "/")
 }
 
 // Detail view styles
@@ -209,6 +402,22 @@ func (m Model) RenderDetailView() string {
 	cfg := m.configs[m.selected]
 	effectiveWidth := m.getEffectiveWidth(40)
 
+	b.WriteString(m.renderConfigDetail(cfg, effectiveWidth))
+
+	// Footer with available actions
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
+
+	return b.String()
+}
+
+// renderConfigDetail renders the fields and health history of cfg, shared by
+// the detail view and the detail pane of the two-pane layout
+func (m Model) renderConfigDetail(cfg models.APIConfig, effectiveWidth int) string {
+	var b strings.Builder
+
 	// Title with active status indicator
 	b.WriteString(titleStyle.Render("配置详情"))
 	if cfg.Alias == m.activeAlias {
@@ -235,6 +444,20 @@ func (m Model) RenderDetailView() string {
 		b.WriteString("\n")
 	}
 
+	// Base config (if set)
+	if cfg.Extends != "" {
+		b.WriteString(detailLabelStyle.Render("继承自:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.Extends, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+
+	// Environment (if set)
+	if cfg.Environment != "" {
+		b.WriteString(detailLabelStyle.Render("环境:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.Environment, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+
 	// Base URL
 	b.WriteString(detailLabelStyle.Render("Base URL:"))
 	if cfg.BaseURL != "" {
@@ -243,6 +466,55 @@ func (m Model) RenderDetailView() string {
 		b.WriteString(dimStyle.Render("(默认)"))
 	}
 	b.WriteString("\n")
+	if cfg.Region != "" {
+		b.WriteString(detailLabelStyle.Render("区域:"))
+		b.WriteString(detailValueStyle.Render(cfg.Region))
+		b.WriteString("\n")
+	}
+
+	// TLS options (if set)
+	if cfg.InsecureSkipVerify {
+		b.WriteString(detailLabelStyle.Render("TLS:"))
+		b.WriteString(compatPartialStyle.Render("跳过证书验证"))
+		b.WriteString("\n")
+	}
+	if cfg.CABundle != "" {
+		b.WriteString(detailLabelStyle.Render("CA 证书:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.CABundle, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+	if cfg.ClientCert != "" {
+		b.WriteString(detailLabelStyle.Render("客户证书:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.ClientCert, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+
+	// Key scope and request headers (if set)
+	if cfg.OrgID != "" {
+		b.WriteString(detailLabelStyle.Render("组织 ID:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.OrgID, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+	if cfg.ProjectID != "" {
+		b.WriteString(detailLabelStyle.Render("项目 ID:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.ProjectID, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+	if cfg.ChatPath != "" {
+		b.WriteString(detailLabelStyle.Render("对话路径:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.ChatPath, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+	if cfg.APIVersion != "" {
+		b.WriteString(detailLabelStyle.Render("API 版本:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(cfg.APIVersion, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+	if len(cfg.BetaFeatures) > 0 {
+		b.WriteString(detailLabelStyle.Render("Beta 功能:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(strings.Join(cfg.BetaFeatures, ","), effectiveWidth-14)))
+		b.WriteString("\n")
+	}
 
 	b.WriteString("\n")
 
@@ -259,6 +531,13 @@ func (m Model) RenderDetailView() string {
 	}
 	b.WriteString("\n")
 
+	// Capabilities and price of the current model, when known
+	if info, ok := m.modelMeta().Lookup(cfg.Model); ok && cfg.Model != "" {
+		b.WriteString(detailLabelStyle.Render("模型信息:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(formatModelInfo(info), effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+
 	// Supported models list
 	b.WriteString(detailLabelStyle.Render("模型列表:"))
 	if len(cfg.Models) > 0 {
@@ -269,6 +548,27 @@ func (m Model) RenderDetailView() string {
 	}
 	b.WriteString("\n")
 
+	// Generation parameters of test requests (if set)
+	if params := formatGeneration(&cfg); params != "" {
+		b.WriteString(detailLabelStyle.Render("生成参数:"))
+		b.WriteString(detailValueStyle.Render(m.truncateText(params, effectiveWidth-14)))
+		b.WriteString("\n")
+	}
+
+	// Request timeout and retries of Claude Code (if set)
+	if cfg.APITimeout != "" || cfg.MaxRetries != nil {
+		var params []string
+		if cfg.APITimeout != "" {
+			params = append(params, "超时 "+cfg.APITimeout)
+		}
+		if cfg.MaxRetries != nil {
+			params = append(params, "重试 "+strconv.Itoa(*cfg.MaxRetries)+" 次")
+		}
+		b.WriteString(detailLabelStyle.Render("请求设置:"))
+		b.WriteString(detailValueStyle.Render(strings.Join(params, ", ")))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 
 	// Authentication section (masked sensitive info)
@@ -278,26 +578,34 @@ func (m Model) RenderDetailView() string {
 	// API Key (masked)
 	b.WriteString(detailLabelStyle.Render("API Key:"))
 	if cfg.APIKey != "" {
-		b.WriteString(detailMaskedStyle.Render(maskString(cfg.APIKey)))
+		b.WriteString(detailMaskedStyle.Render(utils.MaskAPIKey(cfg.APIKey)))
 	} else {
 		b.WriteString(dimStyle.Render("(未设置)"))
 	}
 	b.WriteString("\n")
 
+	// Key pool (if set), api_key above is the key picked from it
+	if len(cfg.APIKeys) > 0 {
+		strategy := cfg.KeyStrategy
+		if strategy == "" {
+			strategy = keypool.RoundRobin
+		}
+		b.WriteString(detailLabelStyle.Render("密钥池:"))
+		b.WriteString(detailValueStyle.Render(fmt.Sprintf("%d 个密钥，%s", len(cfg.APIKeys), strategy)))
+		b.WriteString("\n")
+	}
+
 	// Auth Token (masked)
 	b.WriteString(detailLabelStyle.Render("Auth Token:"))
 	if cfg.AuthToken != "" {
-		b.WriteString(detailMaskedStyle.Render(maskString(cfg.AuthToken)))
+		b.WriteString(detailMaskedStyle.Render(utils.MaskAPIKey(cfg.AuthToken)))
 	} else {
 		b.WriteString(dimStyle.Render("(未设置)"))
 	}
 	b.WriteString("\n")
 
-	// Footer with available actions
 	b.WriteString("\n")
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("s: 本地切换 │ S: 全局切换 │ e: 编辑 │ d: 删除 │ p: 测试 │ Esc: 返回"))
+	b.WriteString(m.renderHistoryPanel(effectiveWidth))
 
 	return b.String()
 }
@@ -330,8 +638,227 @@ func (m Model) RenderFormView() string {
 	b.WriteString("表单输入区域\n")
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Enter: 确认 | Esc: 取消"))
+	b.WriteString(m.renderViewKeyHints())
+
+	return b.String()
+}
+
+// RenderExportPreviewView renders the export lines active.env and switch
+// would generate for the config under the cursor, with keys masked
+func (m Model) RenderExportPreviewView() string {
+	var b strings.Builder
+	effectiveWidth := m.getEffectiveWidth(40)
+
+	if m.cursor < 0 || m.cursor >= len(m.configs) {
+		return dimStyle.Render("未选择配置")
+	}
+	cfg := m.configs[m.cursor]
+
+	b.WriteString(titleStyle.Render("导出预览"))
+	b.WriteString(dimStyle.Render("  " + cfg.Alias))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n\n")
+
+	// Mask keys before generating so quoting is shown as generated
+	masked := cfg
+	if masked.APIKey != "" {
+		masked.APIKey = utils.MaskAPIKey(masked.APIKey)
+	}
+	if masked.AuthToken != "" {
+		masked.AuthToken = utils.MaskAPIKey(masked.AuthToken)
+	}
+	unsetNote := dimStyle.Render(fmt.Sprintf("  (之前先 unset %d 个变量)", len(syncpkg.EnvUnsetNames())))
+
+	b.WriteString(detailSectionStyle.Render("active.env"))
+	b.WriteString(dimStyle.Render("  全局切换 (S) 后新终端加载"))
+	b.WriteString("\n")
+	b.WriteString(unsetNote)
+	b.WriteString("\n")
+	b.WriteString(renderExportLines(syncpkg.GenerateEnvScript(&masked)))
+	b.WriteString("\n")
+
+	b.WriteString(detailSectionStyle.Render("switch 输出"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf(`  eval "$(apimgr switch %s)"`, cfg.Alias)))
+	b.WriteString("\n")
+	b.WriteString(unsetNote)
+	b.WriteString("\n")
+	b.WriteString(renderExportLines(syncpkg.GenerateEnvCommands(&masked)))
+
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
+
+	return b.String()
+}
+
+// RenderCompareView renders two configs side by side, differing fields
+// highlighted and keys masked, followed by their test results
+func (m Model) RenderCompareView() string {
+	var b strings.Builder
+	effectiveWidth := m.getEffectiveWidth(50)
+	a, c := m.compareConfigs[0], m.compareConfigs[1]
+
+	b.WriteString(titleStyle.Render("配置对比"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("  %s vs %s", a.Alias, c.Alias)))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n\n")
+
+	diffs := config.CompareFields(&a, &c)
+	nameWidth, differing := 0, 0
+	for _, d := range diffs {
+		nameWidth = max(nameWidth, len(d.Name))
+		if d.Differs() {
+			differing++
+		}
+	}
+	valueWidth := max((effectiveWidth-nameWidth-6)/2, 8)
+
+	for _, d := range diffs {
+		marker := " "
+		if d.Differs() && d.Name != "alias" {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s %-*s  %-*s  %s", marker, nameWidth, d.Name,
+			valueWidth, m.truncateText(compareValue(d.A, d.Secret), valueWidth),
+			m.truncateText(compareValue(d.B, d.Secret), valueWidth))
+		switch {
+		case d.Name == "alias":
+			b.WriteString(detailSectionStyle.Render(line))
+		case d.Differs():
+			b.WriteString(compatPartialStyle.Render(line))
+		default:
+			b.WriteString(dimStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if differing <= 1 {
+		b.WriteString(dimStyle.Render("两个配置的字段相同"))
+	} else {
+		// The aliases always differ
+		b.WriteString(dimStyle.Render(fmt.Sprintf("%d 个字段不同", differing-1)))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(detailSectionStyle.Render("测试结果"))
+	b.WriteString("\n")
+	switch {
+	case m.compareTesting:
+		b.WriteString(dimStyle.Render("⏳ 正在测试两个配置..."))
+		b.WriteString("\n")
+	case m.compareResults[0] == nil || m.compareResults[1] == nil:
+		b.WriteString(dimStyle.Render(fmt.Sprintf("按 %s 测试两个配置", m.keyMap().Test.Help().Key)))
+		b.WriteString("\n")
+	default:
+		b.WriteString(m.renderCompareResults(effectiveWidth))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
+
+	return b.String()
+}
+
+// compareValue returns a compared field value as shown, masked when secret
+func compareValue(value string, secret bool) string {
+	switch {
+	case value == "":
+		return "(未设置)"
+	case secret:
+		return utils.MaskAPIKeys(value)
+	default:
+		return value
+	}
+}
+
+// formatGeneration formats the generation parameters a config sets, empty
+// when it sets none
+func formatGeneration(cfg *models.APIConfig) string {
+	var params []string
+	if cfg.Temperature != nil {
+		params = append(params, "temperature="+strconv.FormatFloat(*cfg.Temperature, 'f', -1, 64))
+	}
+	if cfg.MaxTokens > 0 {
+		params = append(params, "max_tokens="+strconv.Itoa(cfg.MaxTokens))
+	}
+	if cfg.TopP != nil {
+		params = append(params, "top_p="+strconv.FormatFloat(*cfg.TopP, 'f', -1, 64))
+	}
+	return strings.Join(params, " ")
+}
+
+// renderCompareResults renders the test results of the compared configs,
+// naming the faster one when both passed
+func (m Model) renderCompareResults(effectiveWidth int) string {
+	var b strings.Builder
+	results := m.compareResults
+	aliasWidth := max(len(m.compareConfigs[0].Alias), len(m.compareConfigs[1].Alias))
+
+	for i, r := range results {
+		icon, style := "✅", compatFullStyle
+		switch r.CompatibilityLevel {
+		case "partial":
+			icon, style = "⚠️", compatPartialStyle
+		case "none":
+			icon, style = "❌", compatNoneStyle
+		}
+		b.WriteString(style.Render(fmt.Sprintf("  %s %-*s", icon, aliasWidth, m.compareConfigs[i].Alias)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  %-7s %6dms", r.CompatibilityLevel, r.ResponseTime.Milliseconds())))
+		b.WriteString("\n")
+		if reason := testFailure(r); reason != "" {
+			b.WriteString(dimStyle.Render("    " + m.truncateText(utils.Redact(reason), effectiveWidth-6)))
+			b.WriteString("\n")
+		}
+	}
+
+	a, c := results[0], results[1]
+	switch {
+	case a.Success && c.Success && a.ResponseTime != c.ResponseTime:
+		fast, slow := 0, 1
+		if c.ResponseTime < a.ResponseTime {
+			fast, slow = 1, 0
+		}
+		gap := (results[slow].ResponseTime - results[fast].ResponseTime).Milliseconds()
+		b.WriteString(messageStyle.Render(fmt.Sprintf("%s 快 %dms", m.compareConfigs[fast].Alias, gap)))
+		b.WriteString("\n")
+	case a.Success != c.Success:
+		passed := 0
+		if c.Success {
+			passed = 1
+		}
+		b.WriteString(messageStyle.Render(fmt.Sprintf("只有 %s 通过测试", m.compareConfigs[passed].Alias)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
 
+// testFailure returns why a test failed: its error or the first failed check
+func testFailure(r *compatibility.TestResult) string {
+	if r.Error != "" {
+		return r.Error
+	}
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return check.Message
+		}
+	}
+	return ""
+}
+
+// renderExportLines renders the export lines of a generated script
+func renderExportLines(script string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, "export ") {
+			b.WriteString(detailValueStyle.Render("  " + line))
+			b.WriteString("\n")
+		}
+	}
 	return b.String()
 }
 
@@ -363,6 +890,10 @@ func (m Model) RenderDeleteConfirm() string {
 			b.WriteString(errorStyle.Render("注意: 这是当前活跃的配置！"))
 			b.WriteString("\n\n")
 		}
+		if len(m.deleteUse.Sessions) > 0 {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("注意: %d 个终端的本地会话正在使用此配置 (PID %s)", len(m.deleteUse.Sessions), strings.Join(m.deleteUse.Sessions, ", "))))
+			b.WriteString("\n\n")
+		}
 		
 		// Show config details
 		if cfg.BaseURL != "" {
@@ -373,6 +904,27 @@ func (m Model) RenderDeleteConfirm() string {
 			b.WriteString(dimStyle.Render(fmt.Sprintf("Model: %s", m.truncateText(cfg.Model, effectiveWidth-8))))
 			b.WriteString("\n")
 		}
+
+		if m.deleteUse.InUse() {
+			b.WriteString("\n")
+			b.WriteString(normalStyle.Render(fmt.Sprintf("输入别名 %s 以确认删除: ", cfg.Alias)))
+			b.WriteString(m.deleteInput.View())
+			b.WriteString("\n")
+			if len(m.deleteSwitchTargets()) > 0 {
+				target := "不切换"
+				if m.deleteSwitchTo != "" {
+					target = m.deleteSwitchTo
+				}
+				b.WriteString(normalStyle.Render("删除后切换到: "))
+				b.WriteString(selectedStyle.Render(target))
+				b.WriteString(dimStyle.Render(" (Tab 切换)"))
+				b.WriteString("\n")
+			}
+			if m.errorMsg != "" {
+				b.WriteString(errorStyle.Render(m.errorMsg))
+				b.WriteString("\n")
+			}
+		}
 	} else {
 		b.WriteString(errorStyle.Render("错误: 未选择有效的配置"))
 		b.WriteString("\n")
@@ -381,11 +933,27 @@ func (m Model) RenderDeleteConfirm() string {
 	b.WriteString("\n")
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("y: 确认删除 │ n/Esc: 取消"))
+	if m.deleteUse.InUse() {
+		b.WriteString(m.renderGuardedDeleteHints())
+	} else {
+		b.WriteString(m.renderViewKeyHints())
+	}
 
 	return b.String()
 }
 
+// renderGuardedDeleteHints renders the footer of the typed delete confirmation
+func (m Model) renderGuardedDeleteHints() string {
+	keys := m.keyMap()
+	confirm := keys.Confirm
+	confirm.SetHelp(keys.Confirm.Help().Key, "确认删除")
+	bindings := []key.Binding{confirm}
+	if len(m.deleteSwitchTargets()) > 0 {
+		bindings = append(bindings, key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "切换目标")))
+	}
+	return renderKeyHints(append(bindings, keys.Cancel))
+}
+
 // RenderHelpView renders the help panel with scrolling support
 // Requirements: 10.2, 10.3, 10.4, 11.2
 func (m Model) RenderHelpView() string {
@@ -431,13 +999,14 @@ func (m Model) RenderHelpView() string {
 	// Footer
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: 上下滚动 │ q/Esc: 返回"))
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
 
 // buildHelpLines builds all help content lines for scrolling
 func (m Model) buildHelpLines() []string {
+	keys := m.keyMap()
 	var lines []string
 
 	// Navigation section
@@ -446,6 +1015,8 @@ func (m Model) buildHelpLines() []string {
 	lines = append(lines, renderHelpLine("k / ↑", "向上移动光标"))
 	lines = append(lines, renderHelpLine("g", "跳转到列表顶部"))
 	lines = append(lines, renderHelpLine("G", "跳转到列表底部"))
+	lines = append(lines, renderHelpLine(keys.PageUp.Help().Key+" / "+keys.PageDown.Help().Key, "上一页/下一页"))
+	lines = append(lines, renderHelpLine("Ctrl+U / D", "上翻/下翻半页"))
 	lines = append(lines, renderHelpLine("Enter", "选择/查看配置详情"))
 	lines = append(lines, "\n")
 
@@ -453,9 +1024,14 @@ func (m Model) buildHelpLines() []string {
 	lines = append(lines, detailSectionStyle.Render("配置管理")+"\n")
 	lines = append(lines, renderHelpLine("s", "本地切换 (仅当前终端)"))
 	lines = append(lines, renderHelpLine("S", "全局切换 (设为活跃配置)"))
+	lines = append(lines, renderHelpLine("-", "全局切回上一个配置"))
 	lines = append(lines, renderHelpLine("a", "添加新配置"))
 	lines = append(lines, renderHelpLine("e", "编辑当前配置"))
 	lines = append(lines, renderHelpLine("d", "删除当前配置"))
+	lines = append(lines, renderHelpLine("E", "在 $EDITOR 中编辑配置文件"))
+	lines = append(lines, renderHelpLine("1-9", "快速本地切换第 N 个配置"))
+	lines = append(lines, renderHelpLine("f", "按环境筛选 (循环切换)"))
+	lines = append(lines, renderHelpLine("v", "预览生成的环境变量导出"))
 	lines = append(lines, "\n")
 
 	// Model management section
@@ -467,11 +1043,22 @@ func (m Model) buildHelpLines() []string {
 	lines = append(lines, detailSectionStyle.Render("测试")+"\n")
 	lines = append(lines, renderHelpLine("p", "连接测试 (Ping)"))
 	lines = append(lines, renderHelpLine("t", "API 兼容性测试"))
+	lines = append(lines, renderHelpLine("c", "对比配置: 在一个配置上按 c，再在另一个上按 c"))
+	lines = append(lines, renderHelpLine("T", "在后台测试列表中的全部配置"))
+	lines = append(lines, renderHelpLine("b", "测试进行中: 转入后台"))
+	lines = append(lines, renderHelpLine("J", "后台任务: 查看进度和结果，x 取消"))
+	lines = append(lines, "\n")
+
+	// List markers section
+	lines = append(lines, detailSectionStyle.Render("列表标记")+"\n")
+	lines = append(lines, renderHelpLine("*", "活跃配置"))
+	lines = append(lines, renderHelpLine("@", "生效位置: 全局/项目 Claude 设置, 本终端会话"))
 	lines = append(lines, "\n")
 
 	// General section
 	lines = append(lines, detailSectionStyle.Render("通用")+"\n")
 	lines = append(lines, renderHelpLine("?", "显示此帮助面板"))
+	lines = append(lines, renderHelpLine("M", "消息历史"))
 	lines = append(lines, renderHelpLine("Esc", "返回/取消"))
 	lines = append(lines, renderHelpLine("q", "退出程序"))
 	lines = append(lines, "\n")
@@ -490,15 +1077,21 @@ func renderHelpLine(key, desc string) string {
 func (m Model) RenderStatusBar() string {
 	var b strings.Builder
 
+	// When the messages were shown
+	stamp := ""
+	if !m.messageAt.IsZero() {
+		stamp = dimStyle.Render("  " + m.messageAt.Format("15:04:05"))
+	}
+
 	// Error message (displayed prominently)
 	if m.errorMsg != "" {
-		b.WriteString(errorStyle.Render("✗ 错误: " + m.errorMsg))
+		b.WriteString(errorStyle.Render("✗ 错误: "+utils.Redact(m.errorMsg)) + stamp)
 		b.WriteString("\n")
 	}
 
 	// Status message (success/info messages)
 	if m.message != "" {
-		b.WriteString(messageStyle.Render("✓ " + m.message))
+		b.WriteString(messageStyle.Render("✓ "+utils.Redact(m.message)) + stamp)
 		b.WriteString("\n")
 	}
 
@@ -507,26 +1100,29 @@ func (m Model) RenderStatusBar() string {
 		b.WriteString("\n")
 	}
 
-	// Shortcut hints - formatted nicely
-	keys := DefaultKeyMap()
-	shortHelp := keys.ShortHelp()
-	hints := make([]string, 0, len(shortHelp))
-	for _, k := range shortHelp {
-		keyStr := helpKeyStyle.Render(k.Help().Key)
-		descStr := helpStyle.Render(k.Help().Desc)
-		hints = append(hints, fmt.Sprintf("%s %s", keyStr, descStr))
-	}
-	b.WriteString(strings.Join(hints, helpStyle.Render(" │ ")))
+	// Shortcut hints for the current view
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
 
-// maskString masks sensitive string, showing only first and last few characters
-func maskString(s string) string {
-	if len(s) <= 8 {
-		return "****"
+// renderViewKeyHints renders the footer key hints relevant to the current view
+func (m Model) renderViewKeyHints() string {
+	return renderKeyHints(m.keyMap().ShortHelpFor(m.viewState))
+}
+
+// renderKeyHints renders a list of key bindings as a single footer line
+func renderKeyHints(bindings []key.Binding) string {
+	hints := make([]string, 0, len(bindings))
+	for _, k := range bindings {
+		if !k.Enabled() {
+			continue
+		}
+		keyStr := helpKeyStyle.Render(k.Help().Key)
+		descStr := helpStyle.Render(": " + k.Help().Desc)
+		hints = append(hints, keyStr+descStr)
 	}
-	return s[:4] + "****" + s[len(s)-4:]
+	return strings.Join(hints, helpStyle.Render(" │ "))
 }
 
 // RenderModelSelectView renders the model selection view
@@ -592,9 +1188,9 @@ func (m Model) RenderModelSelectView() string {
 	b.WriteString("\n")
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.getEffectiveWidth(40))))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: 上下移动 │ 空格: 翻页 │ Enter: 确认切换 │ Esc: 取消"))
+	b.WriteString(m.renderViewKeyHints())
 	b.WriteString("\n\n")
-	b.WriteString(dimStyle.Render("提示: 使用空格键可以在模型列表中快速滚动"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("提示: 使用 %s 可以在模型列表中快速滚动", m.keyMap().PageDown.Help().Key)))
 
 	return b.String()
 }
@@ -615,10 +1211,16 @@ func (m Model) renderModelLine(index int, model string, activeModel string) stri
 	activeMarker := "  "
 	if isActive {
 		activeMarker = "* "
+		if m.accessible {
+			activeMarker = "[使用中] "
+		}
 	}
 
 	// Combine all parts
 	content := fmt.Sprintf("%s%s%s", cursor, activeMarker, model)
+	if info, ok := m.modelMeta().Lookup(model); ok {
+		content += "  " + formatModelInfo(info)
+	}
 
 	// Apply appropriate style based on selection and active state
 	if isSelected && isActive {
@@ -631,6 +1233,57 @@ func (m Model) renderModelLine(index int, model string, activeModel string) stri
 	return normalStyle.Render(content)
 }
 
+// formatModelInfo summarizes model metadata, e.g.
+// "200K 上下文 · 视觉 · 工具 · $3/$15 每百万 tokens"
+func formatModelInfo(info modelinfo.Info) string {
+	parts := []string{modelinfo.FormatTokens(info.ContextWindow) + " 上下文"}
+	if info.Vision {
+		parts = append(parts, "视觉")
+	}
+	if info.Tools {
+		parts = append(parts, "工具")
+	}
+	if info.InputPrice > 0 || info.OutputPrice > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s 每百万 tokens", modelinfo.FormatPrice(info.InputPrice), modelinfo.FormatPrice(info.OutputPrice)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// RenderMessageLogView renders the status messages shown so far, newest
+// first, as many as fit the window
+func (m Model) RenderMessageLogView() string {
+	var b strings.Builder
+	effectiveWidth := m.getEffectiveWidth(40)
+
+	b.WriteString(titleStyle.Render("消息历史"))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
+	b.WriteString("\n\n")
+
+	if len(m.messageLog) == 0 {
+		b.WriteString(dimStyle.Render("暂无消息"))
+		b.WriteString("\n")
+	}
+	// Leave room for the title and the key hints
+	limit := max(m.height-6, 1)
+	for i := len(m.messageLog) - 1; i >= 0 && len(m.messageLog)-i <= limit; i-- {
+		entry := m.messageLog[i]
+		text := m.truncateText(utils.Redact(entry.Text), effectiveWidth-12)
+		b.WriteString(dimStyle.Render(entry.Time.Format("15:04:05")))
+		b.WriteString(" ")
+		if entry.Error {
+			b.WriteString(errorStyle.Render("✗ " + text))
+		} else {
+			b.WriteString(messageStyle.Render("✓ " + text))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
+	return b.String()
+}
+
 // RenderPingTestingView renders the ping testing in progress view
 // Requirements: 8.2, 11.2
 func (m Model) RenderPingTestingView() string {
@@ -657,12 +1310,28 @@ func (m Model) RenderPingTestingView() string {
 	}
 
 	// Testing indicator
-	b.WriteString(messageStyle.Render("⏳ 正在测试连接..."))
+	b.WriteString(m.renderTestProgress("正在测试连接..."))
 	b.WriteString("\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
 
+// renderTestProgress renders the spinner of the running test, what it does
+// and how long it has been running
+func (m Model) renderTestProgress(text string) string {
+	indicator := messageStyle.Render("⏳")
+	if len(m.spinner.Spinner.Frames) > 0 {
+		indicator = m.spinner.View()
+	}
+	line := indicator + " " + messageStyle.Render(text)
+	if !m.testStarted.IsZero() {
+		line += dimStyle.Render(fmt.Sprintf("  已用时 %.1fs", time.Since(m.testStarted).Seconds()))
+	}
+	return line
+}
+
 // RenderPingResultView renders the ping test result view
 // Requirements: 8.3, 8.4, 11.2
 func (m Model) RenderPingResultView() string {
@@ -685,7 +1354,10 @@ func (m Model) RenderPingResultView() string {
 		} else {
 			b.WriteString(dimStyle.Render("URL: https://api.anthropic.com (默认)"))
 		}
-		b.WriteString("\n\n")
+		b.WriteString("\n")
+		b.WriteString(renderTLSWarning(cfg))
+		b.WriteString(m.renderCachedNote())
+		b.WriteString("\n")
 	}
 
 	// Show result
@@ -697,17 +1369,21 @@ func (m Model) RenderPingResultView() string {
 				b.WriteString(normalStyle.Render(fmt.Sprintf("响应时间: %s", m.testResult.Duration)))
 				b.WriteString("\n")
 			}
+			b.WriteString(renderPingStats(m.testResult.Stats))
+			b.WriteString(renderPhaseTimings(m.testResult.Timings))
 		} else {
 			b.WriteString(errorStyle.Render("❌ 连接失败"))
 			b.WriteString("\n\n")
 			if m.testResult.Message != "" {
-				b.WriteString(errorStyle.Render(fmt.Sprintf("错误: %s", m.truncateText(m.testResult.Message, effectiveWidth-6))))
+				b.WriteString(errorStyle.Render(fmt.Sprintf("错误: %s", m.truncateText(utils.Redact(m.testResult.Message), effectiveWidth-6))))
 				b.WriteString("\n")
 			}
 			if m.testResult.Duration != "" {
 				b.WriteString(dimStyle.Render(fmt.Sprintf("耗时: %s", m.testResult.Duration)))
 				b.WriteString("\n")
 			}
+			b.WriteString(renderPingStats(m.testResult.Stats))
+			b.WriteString(renderPhaseTimings(m.testResult.Timings))
 		}
 	}
 
@@ -715,11 +1391,112 @@ func (m Model) RenderPingResultView() string {
 	b.WriteString("\n")
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("r: 重试 │ Enter/Esc: 返回"))
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
 
+// historyPanelSize is the number of recent results listed in the detail view
+const historyPanelSize = 10
+
+// renderHistoryPanel renders the latency trend and recent results of the
+// config shown in the detail view
+func (m Model) renderHistoryPanel(effectiveWidth int) string {
+	var b strings.Builder
+	b.WriteString(detailSectionStyle.Render("健康历史"))
+	b.WriteString("\n")
+
+	if len(m.healthHistory) == 0 {
+		b.WriteString(dimStyle.Render("(暂无记录，运行连接测试或兼容性测试后显示)"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	recent := history.Last(m.healthHistory, historyPanelSize)
+	b.WriteString(detailLabelStyle.Render("趋势:"))
+	b.WriteString(detailValueStyle.Render(history.Sparkline(history.Last(m.healthHistory, effectiveWidth-14))))
+	b.WriteString("\n")
+	b.WriteString(detailLabelStyle.Render("成功率:"))
+	b.WriteString(detailValueStyle.Render(fmt.Sprintf("%.0f%% (%d 次)", history.SuccessRate(m.healthHistory)*100, len(m.healthHistory))))
+	b.WriteString("\n")
+
+	// Most recent first
+	for i := len(recent) - 1; i >= 0; i-- {
+		e := recent[i]
+		kind := "连接"
+		if e.Kind == history.KindTest {
+			kind = "兼容"
+		}
+		line := fmt.Sprintf("%s  %s  %dms", e.Time.Local().Format("01-02 15:04"), kind, e.LatencyMs)
+		if e.Detail != "" {
+			line += "  " + e.Detail
+		}
+		line = m.truncateText(line, effectiveWidth-4)
+		if e.Success {
+			b.WriteString(checkPassedStyle.Render("✓ ") + dimStyle.Render(line))
+		} else {
+			b.WriteString(checkFailedStyle.Render("✗ ") + dimStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderPingStats renders the latency summary of a multi-sample ping
+func renderPingStats(stats probe.Stats) string {
+	if stats.Samples <= 1 {
+		return ""
+	}
+
+	var b strings.Builder
+	sampleLine := fmt.Sprintf("样本: %d 次, 失败 %d 次 (%.0f%%)", stats.Samples, stats.Failures, stats.FailureRate()*100)
+	if stats.Failures > 0 {
+		b.WriteString(compatPartialStyle.Render(sampleLine))
+	} else {
+		b.WriteString(dimStyle.Render(sampleLine))
+	}
+	b.WriteString("\n")
+	if stats.Failures < stats.Samples {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("延迟: 最小 %dms / 平均 %dms / p95 %dms / 最大 %dms",
+			stats.Min.Milliseconds(), stats.Avg.Milliseconds(), stats.P95.Milliseconds(), stats.Max.Milliseconds())))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderPhaseTimings renders the DNS/TCP/TLS/first-byte breakdown of a ping,
+// skipping phases that did not happen
+func renderPhaseTimings(timings probe.Timings) string {
+	phases := []struct {
+		label    string
+		duration time.Duration
+	}{
+		{"DNS 解析", timings.DNS},
+		{"TCP 连接", timings.Connect},
+		{"TLS 握手", timings.TLS},
+		{"首字节", timings.FirstByte},
+	}
+
+	var b strings.Builder
+	for _, p := range phases {
+		if p.duration <= 0 {
+			continue
+		}
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  %s: %dms", p.label, p.duration.Milliseconds())))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderTLSWarning returns a warning line when the configuration weakens TLS
+// verification, or an empty string otherwise
+func renderTLSWarning(cfg models.APIConfig) string {
+	if !cfg.InsecureSkipVerify {
+		return ""
+	}
+	return compatPartialStyle.Render("⚠️ 已跳过 TLS 证书验证") + "\n"
+}
+
 // RenderCompatTestingView renders the compatibility testing in progress view
 // Requirements: 9.2, 11.2
 func (m Model) RenderCompatTestingView() string {
@@ -750,7 +1527,7 @@ func (m Model) RenderCompatTestingView() string {
 	}
 
 	// Testing indicator
-	b.WriteString(messageStyle.Render("⏳ 正在执行兼容性测试..."))
+	b.WriteString(m.renderTestProgress("正在执行兼容性测试..."))
 	b.WriteString("\n\n")
 	b.WriteString(dimStyle.Render("测试内容包括:"))
 	b.WriteString("\n")
@@ -762,6 +1539,8 @@ func (m Model) RenderCompatTestingView() string {
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render("  • 流式响应测试"))
 	b.WriteString("\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
@@ -813,7 +1592,10 @@ func (m Model) RenderCompatResultView() string {
 		} else {
 			b.WriteString(dimStyle.Render("URL: https://api.anthropic.com (默认)"))
 		}
-		b.WriteString("\n\n")
+		b.WriteString("\n")
+		b.WriteString(renderTLSWarning(cfg))
+		b.WriteString(m.renderCachedNote())
+		b.WriteString("\n")
 	}
 
 	// Show result
@@ -870,17 +1652,19 @@ func (m Model) RenderCompatResultView() string {
 
 				// Check message (indented)
 				if check.Message != "" {
-					msgLine := fmt.Sprintf("    %s", m.truncateText(check.Message, effectiveWidth-6))
+					msgLine := fmt.Sprintf("    %s", m.truncateText(utils.Redact(check.Message), effectiveWidth-6))
 					b.WriteString(dimStyle.Render(msgLine))
 					b.WriteString("\n")
 				}
 			}
 		}
 
+		b.WriteString(renderRateLimits(m.compatResult.RateLimits, time.Now()))
+
 		// Error message if any
 		if m.compatResult.Error != "" {
 			b.WriteString("\n")
-			b.WriteString(errorStyle.Render(fmt.Sprintf("错误: %s", m.truncateText(m.compatResult.Error, effectiveWidth-6))))
+			b.WriteString(errorStyle.Render(fmt.Sprintf("错误: %s", m.truncateText(utils.Redact(m.compatResult.Error), effectiveWidth-6))))
 			b.WriteString("\n")
 		}
 	}
@@ -889,7 +1673,7 @@ func (m Model) RenderCompatResultView() string {
 	b.WriteString("\n")
 	b.WriteString(separatorStyle.Render(strings.Repeat("─", effectiveWidth)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("r: 重试 │ Enter/Esc: 返回"))
+	b.WriteString(m.renderViewKeyHints())
 
 	return b.String()
 }
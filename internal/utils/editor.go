@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditorCommand returns the command that opens path in the user's editor:
+// $VISUAL, then $EDITOR, then vi (notepad on Windows). The variables may
+// include arguments, such as "code --wait".
+func EditorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		}
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// minSecretLength is the shortest registered value that is redacted, so
+// placeholder values such as "x" don't mangle unrelated output
+const minSecretLength = 6
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string // Known key values, longest first
+
+	// secretPatterns match well-known key formats and key=value pairs. The
+	// last group of each match is the secret.
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(sk-[A-Za-z0-9_\-]{12,})`),
+		regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{30,})`),
+		regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=\-]{12,})`),
+		regexp.MustCompile(`(?i)(?:x-api-key|x-goog-api-key|api[_-]?key|auth[_-]?token|access[_-]?token)["']?\s*[:=]\s*["']?([A-Za-z0-9._~+/\-]{8,})`),
+	}
+)
+
+// RegisterSecrets adds key values that Redact masks wherever they appear
+func RegisterSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, value := range values {
+		if len(value) >= minSecretLength && !slices.Contains(secrets, value) {
+			secrets = append(secrets, value)
+		}
+	}
+	// Longest first so a key containing another is masked whole
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+}
+
+// Redact masks API keys and tokens in s: the given values, values passed
+// to RegisterSecrets and anything that looks like a key. Use it on every
+// error message or text that may echo a request or a config.
+func Redact(s string, values ...string) string {
+	secretsMu.RLock()
+	known := append(slices.Clone(secrets), values...)
+	secretsMu.RUnlock()
+
+	for _, value := range known {
+		if len(value) >= minSecretLength && strings.Contains(s, value) {
+			s = strings.ReplaceAll(s, value, MaskAPIKey(value))
+		}
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+			groups := pattern.FindStringSubmatch(match)
+			secret := groups[len(groups)-1]
+			if strings.Contains(secret, "****") {
+				return match // Already masked
+			}
+			return strings.Replace(match, secret, MaskAPIKey(secret), 1)
+		})
+	}
+	return s
+}
+
+// IsSecretName reports whether an environment variable or field name
+// holds a secret, e.g. ANTHROPIC_API_KEY or auth_token
+func IsSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactWriter redacts everything written through it
+type redactWriter struct {
+	w io.Writer
+}
+
+// NewRedactWriter returns a writer that passes text to w through Redact.
+// Each write is redacted on its own, so write whole lines.
+func NewRedactWriter(w io.Writer) io.Writer {
+	return redactWriter{w: w}
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"apimgr/config/models"
+)
+
+// NewTLSConfig builds the TLS settings for requests to a configuration's
+// endpoint. It returns nil when the system defaults apply.
+func NewTLSConfig(cfg *models.APIConfig) (*tls.Config, error) {
+	if cfg == nil || (!cfg.InsecureSkipVerify && cfg.CABundle == "" && cfg.ClientCert == "") {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CABundle != "" {
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		// Trust the private CA in addition to the system roots
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle '%s'", cfg.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Present a client certificate to gateways requiring mutual TLS
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
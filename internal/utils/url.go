@@ -2,9 +2,20 @@ package utils
 
 import (
 	"net/url"
+	"os"
+	"regexp"
 	"strings"
+
+	"apimgr/internal/exitcode"
 )
 
+// VarEnvPrefix prefixes the environment variables that override base URL
+// placeholders, e.g. APIMGR_VAR_REGION for {region}
+const VarEnvPrefix = "APIMGR_VAR_"
+
+// placeholderPattern matches a {name} placeholder in a base URL
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
 // ValidateURL validates that a URL has a valid scheme and host
 func ValidateURL(rawURL string) bool {
 	if rawURL == "" {
@@ -41,6 +52,12 @@ func NormalizeURL(rawURL string) string {
 	return rawURL
 }
 
+// NormalizeBaseURL trims surrounding whitespace and trailing slashes, so a
+// base URL joins with endpoint paths without a double slash
+func NormalizeBaseURL(rawURL string) string {
+	return strings.TrimRight(strings.TrimSpace(rawURL), "/")
+}
+
 // ExtractHost extracts the host from a URL
 func ExtractHost(rawURL string) string {
 	parsed, err := url.ParseRequestURI(rawURL)
@@ -49,3 +66,41 @@ func ExtractHost(rawURL string) string {
 	}
 	return parsed.Host
 }
+
+// VarEnvName returns the environment variable that overrides a placeholder
+func VarEnvName(name string) string {
+	return VarEnvPrefix + strings.ToUpper(name)
+}
+
+// ExpandPlaceholders replaces the {name} placeholders in a base URL with
+// $APIMGR_VAR_NAME, or vars[name] when it is unset. Placeholders without a
+// value are left in place.
+func ExpandPlaceholders(baseURL string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(baseURL, func(match string) string {
+		name := match[1 : len(match)-1]
+		if value := os.Getenv(VarEnvName(name)); value != "" {
+			return value
+		}
+		if value := vars[name]; value != "" {
+			return value
+		}
+		return match
+	})
+}
+
+// CheckPlaceholders returns an error naming the placeholders left in a base
+// URL after ExpandPlaceholders
+func CheckPlaceholders(baseURL string) error {
+	match := placeholderPattern.FindStringSubmatch(baseURL)
+	if match == nil {
+		return nil
+	}
+	return exitcode.New(exitcode.Validation, "base URL %s has no value for %s: set vars.%s in the configuration or %s",
+		baseURL, match[0], match[1], VarEnvName(match[1]))
+}
+
+// SamplePlaceholders replaces every placeholder in a base URL with a sample
+// value, so a template can be checked with ValidateURL
+func SamplePlaceholders(baseURL string) string {
+	return placeholderPattern.ReplaceAllString(baseURL, "placeholder")
+}
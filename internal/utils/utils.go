@@ -1,5 +1,7 @@
 package utils
 
+import "strings"
+
 // MaskAPIKey masks the API key for display
 func MaskAPIKey(key string) string {
 	if len(key) <= 8 {
@@ -7,3 +9,12 @@ func MaskAPIKey(key string) string {
 	}
 	return key[:4] + "****" + key[len(key)-4:]
 }
+
+// MaskAPIKeys masks each key of a comma-separated list, e.g. a key pool
+func MaskAPIKeys(keys string) string {
+	masked := strings.Split(keys, ",")
+	for i, key := range masked {
+		masked[i] = MaskAPIKey(key)
+	}
+	return strings.Join(masked, ",")
+}
@@ -1,8 +1,18 @@
 package utils
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"apimgr/internal/exitcode"
+	"apimgr/config/models"
 )
 
 func TestMaskAPIKey(t *testing.T) {
@@ -253,6 +263,25 @@ func TestNormalizeURL(t *testing.T) {
 	}
 }
 
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://api.example.com", "https://api.example.com"},
+		{"https://api.example.com/", "https://api.example.com"},
+		{"https://api.example.com/v1//", "https://api.example.com/v1"},
+		{"  https://api.example.com/api/ \n", "https://api.example.com/api"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeBaseURL(tt.url); got != tt.expected {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", tt.url, got, tt.expected)
+		}
+	}
+}
+
 // TestExtractHost tests the ExtractHost function
 func TestExtractHost(t *testing.T) {
 	tests := []struct {
@@ -317,3 +346,140 @@ func TestExtractHost(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	invalidPath := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidPath, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write invalid bundle: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *models.APIConfig
+		wantNil     bool
+		wantErr     bool
+		wantConnect bool
+	}{
+		{name: "nil config", cfg: nil, wantNil: true},
+		{name: "system defaults", cfg: &models.APIConfig{}, wantNil: true},
+		{name: "insecure skip verify", cfg: &models.APIConfig{InsecureSkipVerify: true}, wantConnect: true},
+		{name: "custom CA bundle", cfg: &models.APIConfig{CABundle: caPath}, wantConnect: true},
+		{name: "missing CA bundle", cfg: &models.APIConfig{CABundle: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "invalid CA bundle", cfg: &models.APIConfig{CABundle: invalidPath}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := NewTLSConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (tlsConfig == nil) != (tt.wantNil || tt.wantErr) {
+				t.Fatalf("NewTLSConfig() = %v, wantNil %v", tlsConfig, tt.wantNil)
+			}
+			if !tt.wantConnect {
+				return
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("request with TLS config failed: %v", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestNewTLSConfigClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// Reuse the test server's own key pair as the client certificate
+	dir := t.TempDir()
+	serverCert := server.TLS.Certificates[0]
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]}), 0600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	tlsConfig, err := NewTLSConfig(&models.APIConfig{InsecureSkipVerify: true, ClientCert: certPath, ClientKey: keyPath})
+	if err != nil {
+		t.Fatalf("NewTLSConfig() unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Without the certificate the handshake is rejected
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("request without client certificate should fail")
+	}
+
+	if _, err := NewTLSConfig(&models.APIConfig{ClientCert: certPath, ClientKey: filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Error("NewTLSConfig() with a missing key should fail")
+	}
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	t.Setenv("APIMGR_VAR_REGION", "")
+	t.Setenv("APIMGR_VAR_TENANT", "globex")
+	vars := map[string]string{"region": "eu", "tenant": "acme"}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.example.com", "https://api.example.com"},
+		{"https://{region}.example.com", "https://eu.example.com"},
+		{"https://{region}.example.com/{tenant}", "https://eu.example.com/globex"},
+		{"https://{zone}.example.com", "https://{zone}.example.com"},
+	}
+	for _, tt := range tests {
+		if got := ExpandPlaceholders(tt.url, vars); got != tt.want {
+			t.Errorf("ExpandPlaceholders(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPlaceholders(t *testing.T) {
+	if err := CheckPlaceholders("https://eu.example.com"); err != nil {
+		t.Errorf("CheckPlaceholders() without placeholders = %v, want nil", err)
+	}
+	err := CheckPlaceholders("https://{zone}.example.com")
+	if exitcode.Of(err) != exitcode.Validation || !strings.Contains(err.Error(), "APIMGR_VAR_ZONE") {
+		t.Errorf("CheckPlaceholders() = %v, want a validation error naming APIMGR_VAR_ZONE", err)
+	}
+	if !ValidateURL(SamplePlaceholders("https://{zone}.example.com/{tenant}")) {
+		t.Error("SamplePlaceholders() result should be a valid URL")
+	}
+}
+
+func TestMaskAPIKeys(t *testing.T) {
+	if got, want := MaskAPIKeys("sk-first-key-1,sk-second-key-2"), "sk-f****ey-1,sk-s****ey-2"; got != want {
+		t.Errorf("MaskAPIKeys() = %q, want %q", got, want)
+	}
+	if got := MaskAPIKeys("sk-only-one-key"); got != MaskAPIKey("sk-only-one-key") {
+		t.Errorf("MaskAPIKeys() of a single key = %q, want it masked like MaskAPIKey", got)
+	}
+}
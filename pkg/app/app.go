@@ -0,0 +1,39 @@
+// Package app runs apimgr commands in-process, for integration tests and
+// programs that embed apimgr.
+package app
+
+import (
+	"io"
+
+	"apimgr/cmd"
+	"apimgr/config"
+	"apimgr/internal/exitcode"
+)
+
+// Options configures a run
+type Options struct {
+	// ConfigPath selects the config file, as --config does. Empty uses the
+	// file apimgr would: APIMGR_CONFIG, the workspace's or the global one.
+	ConfigPath string
+	// Manager, when set, is used by commands instead of opening a config
+	// file. ConfigPath is ignored then.
+	Manager *config.Manager
+
+	Stdin  io.Reader // Nil reads nothing
+	Stdout io.Writer // Nil discards output
+	Stderr io.Writer // Nil discards warnings and notices
+}
+
+// Run runs the apimgr command line args, such as "switch", "-l", "work",
+// and returns its exit code with the error the command failed with. The
+// error is not printed, as the apimgr binary would.
+//
+// Runs are serialized, since commands share package state. Commands that
+// need a terminal, such as the TUI or 'edit', still do.
+func Run(opts Options, args ...string) (int, error) {
+	if opts.ConfigPath != "" && opts.Manager == nil {
+		args = append([]string{"--config", opts.ConfigPath}, args...)
+	}
+	err := cmd.Run(args, cmd.IO{In: opts.Stdin, Out: opts.Stdout, Err: opts.Stderr}, opts.Manager)
+	return exitcode.Of(err), err
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"apimgr/config"
+	"apimgr/config/models"
+	"apimgr/internal/exitcode"
+)
+
+func TestRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv(config.ConfigEnvVar, "")
+
+	configPath := filepath.Join(home, "apimgr.json")
+	data, _ := json.Marshal(models.File{Active: "work", Configs: []models.APIConfig{
+		{Alias: "work", Provider: "anthropic", APIKey: "sk-work-key", BaseURL: "https://work.example.com"},
+	}})
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string // Text stdout must contain
+	}{
+		{"list", []string{"list"}, exitcode.Success, "work"},
+		{"switch", []string{"switch", "-l", "work", "--no-prompt"}, exitcode.Success, `export ANTHROPIC_BASE_URL='https://work.example.com'`},
+		{"missing alias", []string{"switch", "-l", "nope", "--no-prompt"}, exitcode.NotFound, ""},
+		{"unknown flag", []string{"list", "--nope"}, exitcode.Usage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			code, err := Run(Options{ConfigPath: configPath, Stdout: &stdout}, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("Run() code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+			if (err != nil) != (tt.wantCode != exitcode.Success) {
+				t.Errorf("Run() error = %v", err)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}